@@ -10,16 +10,14 @@ import (
 )
 
 type ScheduleReminderJob struct {
-	app                 *app.App
-	notificationService services.NotificationService
-	scheduleRuleRepo    *repositories.ScheduleRuleRepository
+	app         *app.App
+	reminderSvc *services.ClassReminderService
 }
 
 func NewScheduleReminderJob(app *app.App) *ScheduleReminderJob {
 	return &ScheduleReminderJob{
-		app:                 app,
-		notificationService: services.NewNotificationService(app),
-		scheduleRuleRepo:    repositories.NewScheduleRuleRepository(app),
+		app:         app,
+		reminderSvc: services.NewClassReminderService(app),
 	}
 }
 
@@ -28,60 +26,45 @@ func (j *ScheduleReminderJob) Name() string {
 }
 
 func (j *ScheduleReminderJob) Description() string {
-	return "Send schedule reminders to teachers for tomorrow's classes"
+	return "Send class reminders to teachers at the offsets configured per center and per teacher"
 }
 
 func (j *ScheduleReminderJob) Repositories() {
-	j.notificationService = services.NewNotificationService(j.app)
-	j.scheduleRuleRepo = repositories.NewScheduleRuleRepository(j.app)
+	j.reminderSvc = services.NewClassReminderService(j.app)
 }
 
 func (j *ScheduleReminderJob) Handle(cronExpr string) error {
-	now := time.Now()
-	tomorrow := now.AddDate(0, 0, 1)
+	_, err := j.reminderSvc.SendDue(context.Background(), time.Now())
+	return err
+}
 
-	weekday := int(tomorrow.Weekday())
-	if weekday == 0 {
-		weekday = 7
-	}
+type BroadcastJob struct {
+	app             *app.App
+	notificationSvc *services.AdminNotificationService
+}
 
-	weekStartDate := tomorrow.Truncate(24 * time.Hour)
-	weekEndDate := weekStartDate.Add(24 * time.Hour)
+func NewBroadcastJob(app *app.App) *BroadcastJob {
+	return &BroadcastJob{
+		app:             app,
+		notificationSvc: services.NewAdminNotificationService(app),
+	}
+}
 
-	var rules []models.ScheduleRule
-	query := j.app.MySQL.RDB.
-		Preload("Exceptions").
-		Where("weekday = ? AND effective_range_start_date <= ? AND effective_range_end_date >= ?",
-			weekday, weekStartDate, weekEndDate)
+func (j *BroadcastJob) Name() string {
+	return "BroadcastJob"
+}
 
-	if err := query.Find(&rules).Error; err != nil {
-		return err
-	}
+func (j *BroadcastJob) Description() string {
+	return "Send scheduled broadcasts whose send time has arrived"
+}
 
-	for _, rule := range rules {
-		if rule.TeacherID == nil {
-			continue
-		}
-
-		hasCancellation := false
-		for _, exc := range rule.Exceptions {
-			if exc.ExceptionType == "CANCEL" && exc.Status == "APPROVED" {
-				if exc.OriginalDate.Format("2006-01-02") == tomorrow.Format("2006-01-02") {
-					hasCancellation = true
-					break
-				}
-			}
-		}
-
-		if !hasCancellation {
-			ctx := context.Background()
-			if err := j.notificationService.SendScheduleReminder(ctx, rule.ID, tomorrow); err != nil {
-				continue
-			}
-		}
-	}
+func (j *BroadcastJob) Repositories() {
+	j.notificationSvc = services.NewAdminNotificationService(j.app)
+}
 
-	return nil
+func (j *BroadcastJob) Handle(cronExpr string) error {
+	_, err := j.notificationSvc.SendDueBroadcasts(context.Background(), time.Now())
+	return err
 }
 
 type ExceptionReviewJob struct {
@@ -159,3 +142,118 @@ func (j *CleanupOldNotificationsJob) Handle(cronExpr string) error {
 
 	return nil
 }
+
+type DashboardKPIJob struct {
+	app        *app.App
+	kpiService *services.DashboardKPIService
+}
+
+func NewDashboardKPIJob(app *app.App) *DashboardKPIJob {
+	return &DashboardKPIJob{
+		app:        app,
+		kpiService: services.NewDashboardKPIService(app),
+	}
+}
+
+func (j *DashboardKPIJob) Name() string {
+	return "DashboardKPIJob"
+}
+
+func (j *DashboardKPIJob) Description() string {
+	return "Pre-aggregate daily center KPIs for the recent days"
+}
+
+func (j *DashboardKPIJob) Repositories() {
+	j.kpiService = services.NewDashboardKPIService(j.app)
+}
+
+func (j *DashboardKPIJob) Handle(cronExpr string) error {
+	return j.kpiService.AggregateRecentDays(context.Background())
+}
+
+type HeldNotificationJob struct {
+	app           *app.App
+	preferenceSvc *services.NotificationPreferenceService
+}
+
+func NewHeldNotificationJob(app *app.App) *HeldNotificationJob {
+	return &HeldNotificationJob{
+		app:           app,
+		preferenceSvc: services.NewNotificationPreferenceService(app),
+	}
+}
+
+func (j *HeldNotificationJob) Name() string {
+	return "HeldNotificationJob"
+}
+
+func (j *HeldNotificationJob) Description() string {
+	return "Deliver notifications deferred by quiet hours and daily digests that are due"
+}
+
+func (j *HeldNotificationJob) Repositories() {
+	j.preferenceSvc = services.NewNotificationPreferenceService(j.app)
+}
+
+func (j *HeldNotificationJob) Handle(cronExpr string) error {
+	_, err := j.preferenceSvc.DeliverHeld(context.Background(), time.Now())
+	return err
+}
+
+type LineGroupSummaryJob struct {
+	app          *app.App
+	lineGroupSvc *services.LineGroupService
+}
+
+func NewLineGroupSummaryJob(app *app.App) *LineGroupSummaryJob {
+	return &LineGroupSummaryJob{
+		app:          app,
+		lineGroupSvc: services.NewLineGroupService(app),
+	}
+}
+
+func (j *LineGroupSummaryJob) Name() string {
+	return "LineGroupSummaryJob"
+}
+
+func (j *LineGroupSummaryJob) Description() string {
+	return "Post the daily schedule summary to linked center LINE groups at their configured time"
+}
+
+func (j *LineGroupSummaryJob) Repositories() {
+	j.lineGroupSvc = services.NewLineGroupService(j.app)
+}
+
+func (j *LineGroupSummaryJob) Handle(cronExpr string) error {
+	_, err := j.lineGroupSvc.SendDueDailySummaries(context.Background(), time.Now())
+	return err
+}
+
+type LineWebhookEventPurgeJob struct {
+	app      *app.App
+	eventSvc *services.LineWebhookEventService
+}
+
+func NewLineWebhookEventPurgeJob(app *app.App) *LineWebhookEventPurgeJob {
+	return &LineWebhookEventPurgeJob{
+		app:      app,
+		eventSvc: services.NewLineWebhookEventService(app),
+	}
+}
+
+func (j *LineWebhookEventPurgeJob) Name() string {
+	return "LineWebhookEventPurgeJob"
+}
+
+func (j *LineWebhookEventPurgeJob) Description() string {
+	return "Delete LINE webhook event records older than 30 days"
+}
+
+func (j *LineWebhookEventPurgeJob) Repositories() {
+	j.eventSvc = services.NewLineWebhookEventService(j.app)
+}
+
+func (j *LineWebhookEventPurgeJob) Handle(cronExpr string) error {
+	_, err := j.eventSvc.Purge(context.Background(), time.Now())
+	return err
+}
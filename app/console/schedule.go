@@ -26,7 +26,6 @@ func Initialize(app *app.App) *Scheduler {
 }
 
 // 註冊任務
-// nolint:unused // 保留以供未來擴展
 func (s *Scheduler) addJob(spec string, job Job) {
 	_, err := s.cron.AddFunc(spec, func() {
 		s.wg.Add(1)
@@ -70,7 +69,18 @@ func (s *Scheduler) addJob(spec string, job Job) {
 
 // 秒 分 時 日 月 星期 * * * * * *
 func (s *Scheduler) loadJobs() {
-
+	// 每天 02:30 彙總中心 KPI
+	s.addJob("0 30 2 * * *", NewDashboardKPIJob(s.app))
+	// 每分鐘發送到期的勿擾延後通知與每日彙整
+	s.addJob("0 * * * * *", NewHeldNotificationJob(s.app))
+	// 每分鐘發送到期的課前提醒
+	s.addJob("30 * * * * *", NewScheduleReminderJob(s.app))
+	// 每分鐘發送到期的排程廣播
+	s.addJob("15 * * * * *", NewBroadcastJob(s.app))
+	// 每分鐘發送到期的 LINE 群組每日課表摘要
+	s.addJob("45 * * * * *", NewLineGroupSummaryJob(s.app))
+	// 每天 03:10 刪除超過保留期限的 LINE webhook 事件紀錄
+	s.addJob("0 10 3 * * *", NewLineWebhookEventPurgeJob(s.app))
 }
 
 // 啟動排程
@@ -1,9 +1,11 @@
 package controllers
 
 import (
+	"errors"
 	"strconv"
 
 	"timeLedger/app"
+	"timeLedger/app/models"
 	"timeLedger/app/resources"
 	"timeLedger/app/services"
 
@@ -32,6 +34,13 @@ type UpdateSettingsRequest struct {
 	ExceptionLeadDays     *int64 `json:"exception_lead_days"`
 	OperatingStartTime    string `json:"operating_start_time"`
 	OperatingEndTime      string `json:"operating_end_time"`
+	FollowMakeupWorkdays  *bool  `json:"follow_makeup_workdays"`
+	// WeeklyOperatingHours 每週各日營業時間，傳入空陣列表示清除並沿用預設營業時間
+	WeeklyOperatingHours *[]models.WeekdayOperatingHours `json:"weekly_operating_hours"`
+	// ReminderOffsets 課前提醒提前分鐘數（例如 [1440, 60]），傳入空陣列表示不發送課前提醒
+	ReminderOffsets *[]int `json:"reminder_offsets"`
+	// Version 讀取時的 settings_version，亦可改用 If-Match header；未提供時不比對
+	Version *uint `json:"version"`
 }
 
 // UpdateSettings 更新中心設定
@@ -58,6 +67,11 @@ func (ctl *AdminCenterController) UpdateSettings(ctx *gin.Context) {
 		return
 	}
 
+	expectedVersion, ok := helper.MustExpectedVersion(req.Version)
+	if !ok {
+		return
+	}
+
 	// 取得現有設定
 	settings, errInfo, err := ctl.centerService.GetCenterSettings(ctx.Request.Context(), uint(centerID))
 	if err != nil {
@@ -79,6 +93,38 @@ func (ctl *AdminCenterController) UpdateSettings(ctx *gin.Context) {
 	if req.OperatingEndTime != "" {
 		settings.OperatingEndTime = req.OperatingEndTime
 	}
+	if req.OperatingStartTime != "" || req.OperatingEndTime != "" {
+		// 未設定的一端視為 00:00 / 24:00
+		openTime, closeTime := settings.OperatingStartTime, settings.OperatingEndTime
+		if openTime == "" {
+			openTime = "00:00"
+		}
+		if closeTime == "" {
+			closeTime = "24:00"
+		}
+		if err := services.ValidateOpeningWindow(openTime, closeTime); err != nil {
+			helper.BadRequest(err.Error())
+			return
+		}
+	}
+	if req.FollowMakeupWorkdays != nil {
+		settings.FollowMakeupWorkdays = *req.FollowMakeupWorkdays
+	}
+	if req.WeeklyOperatingHours != nil {
+		if err := services.ValidateWeeklyOperatingHours(*req.WeeklyOperatingHours); err != nil {
+			helper.BadRequest(err.Error())
+			return
+		}
+		settings.WeeklyOperatingHours = *req.WeeklyOperatingHours
+	}
+	if req.ReminderOffsets != nil {
+		offsets, err := services.NormalizeReminderOffsets(*req.ReminderOffsets)
+		if err != nil {
+			helper.BadRequest(err.Error())
+			return
+		}
+		settings.ReminderOffsets = offsets
+	}
 
 	// 取得管理員 ID
 	adminID := helper.MustUserID()
@@ -87,13 +133,24 @@ func (ctl *AdminCenterController) UpdateSettings(ctx *gin.Context) {
 	}
 
 	// 儲存變更
-	updatedCenter, errInfo, err := ctl.centerService.UpdateCenterSettings(ctx.Request.Context(), uint(centerID), adminID, settings)
+	updatedCenter, errInfo, err := ctl.centerService.UpdateCenterSettings(ctx.Request.Context(), uint(centerID), adminID, settings, expectedVersion)
 	if err != nil {
+		var conflictErr *services.VersionConflictError
+		if errors.As(err, &conflictErr) {
+			// 回傳伺服器目前的設定，供前端顯示差異
+			conflict := conflictErr.Conflict
+			if current, ok := conflict.Current.(models.Center); ok {
+				conflict.Current = ctl.centerResource.ToCenterResponse(current)
+			}
+			helper.ConflictWithData(errInfo, conflict)
+			return
+		}
 		helper.ErrorWithInfo(errInfo)
 		return
 	}
 
 	response := ctl.centerResource.ToCenterResponse(*updatedCenter)
+	helper.SetVersionETag(updatedCenter.SettingsVersion)
 	helper.Success(response)
 }
 
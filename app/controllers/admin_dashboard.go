@@ -0,0 +1,55 @@
+package controllers
+
+import (
+	"timeLedger/app"
+	"timeLedger/app/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminDashboardController 中心 KPI 儀表板控制器
+type AdminDashboardController struct {
+	BaseController
+	app        *app.App
+	kpiService *services.DashboardKPIService
+}
+
+// NewAdminDashboardController 建立 AdminDashboardController 實例
+func NewAdminDashboardController(app *app.App) *AdminDashboardController {
+	return &AdminDashboardController{
+		app:        app,
+		kpiService: services.NewDashboardKPIService(app),
+	}
+}
+
+// GetKPIs 取得期間 KPI
+// @Summary 取得期間 KPI（場次達成、停課與改期比例、審核時效、老師授課時數、代課、新進老師）
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param start_date query string true "開始日期"
+// @Param end_date query string true "結束日期（最晚統計到昨天）"
+// @Success 200 {object} global.ApiResponse{data=services.DashboardKPIReport}
+// @Router /api/v1/admin/dashboard/kpis [get]
+func (ctl *AdminDashboardController) GetKPIs(ctx *gin.Context) {
+	helper := NewContextHelper(ctx)
+
+	centerID := helper.MustCenterID()
+	if centerID == 0 {
+		return
+	}
+
+	startDate, endDate := helper.MustQueryDateRange("start_date", "end_date")
+	if startDate.IsZero() || endDate.IsZero() {
+		return
+	}
+
+	report, errInfo, err := ctl.kpiService.GetKPIs(ctx.Request.Context(), centerID, startDate, endDate)
+	if err != nil {
+		helper.ErrorWithInfo(errInfo)
+		return
+	}
+
+	helper.Success(report)
+}
@@ -164,3 +164,277 @@ func (ctl *AdminHolidayController) DeleteHoliday(ctx *gin.Context) {
 
 	helper.Success(nil)
 }
+
+// GetTaiwanCalendar 預覽內建台灣國定假日與補班日
+// @Summary 預覽內建台灣國定假日與補班日
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Center ID"
+// @Param year query int true "年度"
+// @Success 200 {object} global.ApiResponse{data=twcalendar.YearCalendar}
+// @Router /api/v1/admin/centers/{id}/holidays/tw-calendar [get]
+func (ctl *AdminHolidayController) GetTaiwanCalendar(ctx *gin.Context) {
+	helper := NewContextHelper(ctx)
+
+	centerID := helper.MustParamUint("id")
+	if centerID == 0 {
+		return
+	}
+
+	year := helper.QueryIntOrDefault("year", 0)
+	if year == 0 {
+		helper.BadRequest("year is required")
+		return
+	}
+
+	calendar, errInfo, err := ctl.holidayService.GetTaiwanCalendar(year)
+	if err != nil {
+		helper.ErrorWithInfo(errInfo)
+		return
+	}
+
+	helper.Success(calendar)
+}
+
+// ImportTaiwanHolidays 匯入台灣國定假日與補班日
+// @Summary 匯入指定年度的台灣國定假日與補班日
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Center ID"
+// @Param request body services.ImportTaiwanHolidaysRequest true "匯入年度"
+// @Success 200 {object} global.ApiResponse{data=services.ImportTaiwanHolidaysResponse}
+// @Router /api/v1/admin/centers/{id}/holidays/import-tw [post]
+func (ctl *AdminHolidayController) ImportTaiwanHolidays(ctx *gin.Context) {
+	helper := NewContextHelper(ctx)
+
+	centerID := helper.MustParamUint("id")
+	if centerID == 0 {
+		return
+	}
+
+	var req services.ImportTaiwanHolidaysRequest
+	if !helper.MustBindJSON(&req) {
+		return
+	}
+
+	adminID := helper.MustUserID()
+	if adminID == 0 {
+		return
+	}
+
+	response, errInfo, err := ctl.holidayService.ImportTaiwanHolidays(ctx.Request.Context(), centerID, adminID, &req)
+	if err != nil {
+		helper.ErrorWithInfo(errInfo)
+		return
+	}
+
+	helper.Success(response)
+}
+
+// GetMakeupWorkdays 取得補班日列表
+// @Summary 取得補班日列表
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Center ID"
+// @Param start_date query string false "開始日期"
+// @Param end_date query string false "結束日期"
+// @Success 200 {object} global.ApiResponse{data=[]models.CenterMakeupWorkday}
+// @Router /api/v1/admin/centers/{id}/makeup-workdays [get]
+func (ctl *AdminHolidayController) GetMakeupWorkdays(ctx *gin.Context) {
+	helper := NewContextHelper(ctx)
+
+	centerID := helper.MustParamUint("id")
+	if centerID == 0 {
+		return
+	}
+
+	var req services.GetHolidaysRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		helper.BadRequest("Invalid query parameters")
+		return
+	}
+
+	workdays, errInfo, err := ctl.holidayService.GetMakeupWorkdays(ctx.Request.Context(), centerID, &req)
+	if err != nil {
+		helper.ErrorWithInfo(errInfo)
+		return
+	}
+
+	helper.Success(workdays)
+}
+
+// DeleteMakeupWorkday 刪除補班日
+// @Summary 刪除補班日
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Center ID"
+// @Param workday_id path int true "Makeup Workday ID"
+// @Success 200 {object} global.ApiResponse
+// @Router /api/v1/admin/centers/{id}/makeup-workdays/{workday_id} [delete]
+func (ctl *AdminHolidayController) DeleteMakeupWorkday(ctx *gin.Context) {
+	helper := NewContextHelper(ctx)
+
+	centerID := helper.MustParamUint("id")
+	if centerID == 0 {
+		return
+	}
+
+	workdayID := helper.MustParamUint("workday_id")
+	if workdayID == 0 {
+		return
+	}
+
+	adminID := helper.MustUserID()
+	if adminID == 0 {
+		return
+	}
+
+	errInfo, err := ctl.holidayService.DeleteMakeupWorkday(ctx.Request.Context(), centerID, adminID, workdayID)
+	if err != nil {
+		helper.ErrorWithInfo(errInfo)
+		return
+	}
+
+	helper.Success(nil)
+}
+
+// GetRecurringHolidays 取得週期假日定義
+// @Summary 取得週期假日定義
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Center ID"
+// @Success 200 {object} global.ApiResponse{data=[]models.RecurringHoliday}
+// @Router /api/v1/admin/centers/{id}/recurring-holidays [get]
+func (ctl *AdminHolidayController) GetRecurringHolidays(ctx *gin.Context) {
+	helper := NewContextHelper(ctx)
+
+	centerID := helper.MustParamUint("id")
+	if centerID == 0 {
+		return
+	}
+
+	defs, errInfo, err := ctl.holidayService.GetRecurringHolidays(ctx.Request.Context(), centerID)
+	if err != nil {
+		helper.ErrorWithInfo(errInfo)
+		return
+	}
+
+	helper.Success(defs)
+}
+
+// CreateRecurringHoliday 新增週期假日定義
+// @Summary 新增週期假日定義（固定月日、每月第 N 個星期幾、農曆節日）
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Center ID"
+// @Param request body services.CreateRecurringHolidayRequest true "週期假日定義"
+// @Success 200 {object} global.ApiResponse{data=models.RecurringHoliday}
+// @Router /api/v1/admin/centers/{id}/recurring-holidays [post]
+func (ctl *AdminHolidayController) CreateRecurringHoliday(ctx *gin.Context) {
+	helper := NewContextHelper(ctx)
+
+	centerID := helper.MustParamUint("id")
+	if centerID == 0 {
+		return
+	}
+
+	var req services.CreateRecurringHolidayRequest
+	if !helper.MustBindJSON(&req) {
+		return
+	}
+
+	adminID := helper.MustUserID()
+	if adminID == 0 {
+		return
+	}
+
+	def, errInfo, err := ctl.holidayService.CreateRecurringHoliday(ctx.Request.Context(), centerID, adminID, &req)
+	if err != nil {
+		helper.ErrorWithInfo(errInfo)
+		return
+	}
+
+	helper.Success(def)
+}
+
+// DeleteRecurringHoliday 刪除週期假日定義
+// @Summary 刪除週期假日定義
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Center ID"
+// @Param recurring_id path int true "Recurring Holiday ID"
+// @Success 200 {object} global.ApiResponse
+// @Router /api/v1/admin/centers/{id}/recurring-holidays/{recurring_id} [delete]
+func (ctl *AdminHolidayController) DeleteRecurringHoliday(ctx *gin.Context) {
+	helper := NewContextHelper(ctx)
+
+	centerID := helper.MustParamUint("id")
+	if centerID == 0 {
+		return
+	}
+
+	recurringID := helper.MustParamUint("recurring_id")
+	if recurringID == 0 {
+		return
+	}
+
+	adminID := helper.MustUserID()
+	if adminID == 0 {
+		return
+	}
+
+	errInfo, err := ctl.holidayService.DeleteRecurringHoliday(ctx.Request.Context(), centerID, adminID, recurringID)
+	if err != nil {
+		helper.ErrorWithInfo(errInfo)
+		return
+	}
+
+	helper.Success(nil)
+}
+
+// PreviewRecurringHolidays 預覽週期假日展開結果
+// @Summary 預覽週期假日在指定年度的具體日期
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Center ID"
+// @Param year query int true "年度"
+// @Success 200 {object} global.ApiResponse{data=[]models.CenterHoliday}
+// @Router /api/v1/admin/centers/{id}/recurring-holidays/preview [get]
+func (ctl *AdminHolidayController) PreviewRecurringHolidays(ctx *gin.Context) {
+	helper := NewContextHelper(ctx)
+
+	centerID := helper.MustParamUint("id")
+	if centerID == 0 {
+		return
+	}
+
+	year := helper.QueryIntOrDefault("year", 0)
+	if year == 0 {
+		helper.BadRequest("year is required")
+		return
+	}
+
+	holidays, errInfo, err := ctl.holidayService.PreviewRecurringHolidays(ctx.Request.Context(), centerID, year)
+	if err != nil {
+		helper.ErrorWithInfo(errInfo)
+		return
+	}
+
+	helper.Success(holidays)
+}
@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"timeLedger/app"
+	"timeLedger/app/resources"
+	"timeLedger/app/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminLineEventController LINE webhook 事件紀錄
+type AdminLineEventController struct {
+	app    *app.App
+	events *services.LineWebhookEventService
+}
+
+// NewAdminLineEventController 建立 LINE webhook 事件紀錄控制器
+func NewAdminLineEventController(app *app.App) *AdminLineEventController {
+	return &AdminLineEventController{
+		app:    app,
+		events: services.NewLineWebhookEventService(app),
+	}
+}
+
+// ListEvents 取得 LINE webhook 事件紀錄
+// @Summary 取得 LINE webhook 事件紀錄
+// @Description 僅列出來源為中心管理員、在職老師或中心連結群組的事件
+// @Tags Admin - LINE Events
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "pending / processed / failed"
+// @Param page query int false "頁碼"
+// @Param limit query int false "每頁筆數"
+// @Success 200 {object} global.ApiResponse{data=resources.PaginationResponse}
+// @Router /api/v1/admin/line-events [get]
+func (c *AdminLineEventController) ListEvents(ctx *gin.Context) {
+	helper := NewContextHelper(ctx)
+
+	centerID := helper.MustCenterID()
+	if centerID == 0 {
+		return
+	}
+
+	page := helper.QueryIntOrDefault("page", 1)
+	limit := helper.QueryIntOrDefault("limit", 20)
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	events, total, errInfo, err := c.events.ListEvents(ctx.Request.Context(), centerID,
+		helper.QueryStringOrDefault("status", ""), page, limit)
+	if err != nil {
+		helper.ErrorWithInfo(errInfo)
+		return
+	}
+
+	helper.Success(resources.NewPaginationResponse(events, total, page, limit))
+}
+
+// GetEvent 取得 LINE webhook 事件詳情
+// @Summary 取得 LINE webhook 事件詳情
+// @Description 含 LINE 送來的原始事件內容與最後一次錯誤
+// @Tags Admin - LINE Events
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "事件紀錄 ID"
+// @Success 200 {object} global.ApiResponse{data=services.LineWebhookEventDetail}
+// @Router /api/v1/admin/line-events/{id} [get]
+func (c *AdminLineEventController) GetEvent(ctx *gin.Context) {
+	helper := NewContextHelper(ctx)
+
+	centerID := helper.MustCenterID()
+	if centerID == 0 {
+		return
+	}
+	id := helper.MustParamUint("id")
+	if id == 0 {
+		return
+	}
+
+	event, errInfo, err := c.events.GetEvent(ctx.Request.Context(), centerID, id)
+	if err != nil {
+		helper.ErrorWithInfo(errInfo)
+		return
+	}
+
+	helper.Success(event)
+}
+
+// ReplayEvent 重新處理失敗的 LINE webhook 事件
+// @Summary 重新處理失敗的 LINE webhook 事件
+// @Description 排入佇列後由背景 worker 處理；原 replyToken 已失效，回覆改以推播送給事件來源
+// @Tags Admin - LINE Events
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "事件紀錄 ID"
+// @Success 200 {object} global.ApiResponse{data=services.LineWebhookEventDetail}
+// @Router /api/v1/admin/line-events/{id}/replay [post]
+func (c *AdminLineEventController) ReplayEvent(ctx *gin.Context) {
+	helper := NewContextHelper(ctx)
+
+	centerID := helper.MustCenterID()
+	if centerID == 0 {
+		return
+	}
+	id := helper.MustParamUint("id")
+	if id == 0 {
+		return
+	}
+
+	event, errInfo, err := c.events.Replay(ctx.Request.Context(), centerID, id)
+	if err != nil {
+		helper.ErrorWithInfo(errInfo)
+		return
+	}
+
+	helper.Success(event)
+}
@@ -0,0 +1,145 @@
+package controllers
+
+import (
+	"timeLedger/app"
+	"timeLedger/app/services"
+	"timeLedger/global/errInfos"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminLineGroupController 中心 LINE 群組管理
+type AdminLineGroupController struct {
+	app    *app.App
+	groups *services.LineGroupService
+}
+
+// NewAdminLineGroupController 建立 LINE 群組管理控制器
+func NewAdminLineGroupController(app *app.App) *AdminLineGroupController {
+	return &AdminLineGroupController{
+		app:    app,
+		groups: services.NewLineGroupService(app),
+	}
+}
+
+// ListGroups 取得中心連結的 LINE 群組
+// @Summary 取得中心連結的 LINE 群組
+// @Tags Admin - LINE Groups
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} global.ApiResponse{data=[]models.LineGroup}
+// @Router /api/v1/admin/line-groups [get]
+func (c *AdminLineGroupController) ListGroups(ctx *gin.Context) {
+	helper := NewContextHelper(ctx)
+
+	centerID := helper.MustCenterID()
+	if centerID == 0 {
+		return
+	}
+
+	groups, errInfo, err := c.groups.ListGroups(ctx.Request.Context(), centerID)
+	if err != nil {
+		helper.ErrorWithInfo(errInfo)
+		return
+	}
+
+	helper.Success(groups)
+}
+
+// GenerateLinkCode 產生群組驗證碼
+// @Summary 產生 LINE 群組驗證碼
+// @Description 將官方帳號加入群組後，於群組內貼上驗證碼即完成連結；驗證碼 10 分鐘內有效且僅能使用一次
+// @Tags Admin - LINE Groups
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} global.ApiResponse{data=services.LineGroupLinkCode}
+// @Router /api/v1/admin/line-groups/link-code [post]
+func (c *AdminLineGroupController) GenerateLinkCode(ctx *gin.Context) {
+	helper := NewContextHelper(ctx)
+
+	centerID := helper.MustCenterID()
+	if centerID == 0 {
+		return
+	}
+	adminID := helper.MustUserID()
+	if adminID == 0 {
+		return
+	}
+
+	code, errInfo, err := c.groups.GenerateLinkCode(ctx.Request.Context(), centerID, adminID)
+	if err != nil {
+		helper.ErrorWithInfo(errInfo)
+		return
+	}
+
+	helper.Success(code)
+}
+
+// UpdateGroup 更新群組通知設定
+// @Summary 更新 LINE 群組通知設定
+// @Tags Admin - LINE Groups
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "群組 ID"
+// @Param request body services.LineGroupSettingsRequest true "通知設定"
+// @Success 200 {object} global.ApiResponse{data=models.LineGroup}
+// @Router /api/v1/admin/line-groups/{id} [patch]
+func (c *AdminLineGroupController) UpdateGroup(ctx *gin.Context) {
+	helper := NewContextHelper(ctx)
+
+	centerID := helper.MustCenterID()
+	if centerID == 0 {
+		return
+	}
+	id := helper.MustParamUint("id")
+	if id == 0 {
+		return
+	}
+
+	var req services.LineGroupSettingsRequest
+	if !helper.MustBindJSON(&req) {
+		return
+	}
+
+	group, errInfo, err := c.groups.UpdateGroup(ctx.Request.Context(), centerID, id, &req)
+	if err != nil {
+		if errInfo.Is(errInfos.PARAMS_VALIDATE_ERROR) {
+			helper.BadRequest(err.Error())
+			return
+		}
+		helper.ErrorWithInfo(errInfo)
+		return
+	}
+
+	helper.Success(group)
+}
+
+// UnlinkGroup 解除群組連結
+// @Summary 解除 LINE 群組連結
+// @Description 解除後官方帳號會離開該群組
+// @Tags Admin - LINE Groups
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "群組 ID"
+// @Success 200 {object} global.ApiResponse
+// @Router /api/v1/admin/line-groups/{id} [delete]
+func (c *AdminLineGroupController) UnlinkGroup(ctx *gin.Context) {
+	helper := NewContextHelper(ctx)
+
+	centerID := helper.MustCenterID()
+	if centerID == 0 {
+		return
+	}
+	id := helper.MustParamUint("id")
+	if id == 0 {
+		return
+	}
+
+	if errInfo, err := c.groups.UnlinkGroup(ctx.Request.Context(), centerID, id); err != nil {
+		helper.ErrorWithInfo(errInfo)
+		return
+	}
+
+	helper.Success(gin.H{"message": "已解除連結"})
+}
@@ -0,0 +1,260 @@
+package controllers
+
+import (
+	"net/http"
+	"timeLedger/app"
+	"timeLedger/app/services"
+	"timeLedger/global"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminMakeupController 補課管理控制器
+type AdminMakeupController struct {
+	BaseController
+	app           *app.App
+	makeupService *services.MakeupService
+}
+
+// NewAdminMakeupController 建立 AdminMakeupController 實例
+func NewAdminMakeupController(app *app.App) *AdminMakeupController {
+	return &AdminMakeupController{
+		app:           app,
+		makeupService: services.NewMakeupService(app),
+	}
+}
+
+// GetSessionQuota 取得班別學期堂數統計
+// @Summary 取得班別學期堂數統計（已上、取消、尚欠補課）
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param offering_id path int true "Offering ID"
+// @Param term_id path int true "Term ID"
+// @Success 200 {object} global.ApiResponse{data=services.SessionQuotaSummary}
+// @Router /api/v1/admin/offerings/{offering_id}/terms/{term_id}/quota [get]
+func (ctl *AdminMakeupController) GetSessionQuota(ctx *gin.Context) {
+	helper := NewContextHelper(ctx)
+
+	centerID := helper.MustCenterID()
+	if centerID == 0 {
+		return
+	}
+
+	offeringID := helper.MustParamUint("offering_id")
+	if offeringID == 0 {
+		return
+	}
+
+	termID := helper.MustParamUint("term_id")
+	if termID == 0 {
+		return
+	}
+
+	summary, errInfo, err := ctl.makeupService.GetSessionQuota(ctx.Request.Context(), centerID, offeringID, termID)
+	if err != nil {
+		helper.ErrorWithInfo(errInfo)
+		return
+	}
+
+	helper.Success(summary)
+}
+
+// SetPlannedSessions 設定班別學期預計堂數
+// @Summary 設定班別學期預計堂數
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param offering_id path int true "Offering ID"
+// @Param term_id path int true "Term ID"
+// @Param request body services.SetPlannedSessionsRequest true "預計堂數"
+// @Success 200 {object} global.ApiResponse{data=services.SessionQuotaSummary}
+// @Router /api/v1/admin/offerings/{offering_id}/terms/{term_id}/quota [put]
+func (ctl *AdminMakeupController) SetPlannedSessions(ctx *gin.Context) {
+	helper := NewContextHelper(ctx)
+
+	centerID := helper.MustCenterID()
+	if centerID == 0 {
+		return
+	}
+
+	offeringID := helper.MustParamUint("offering_id")
+	if offeringID == 0 {
+		return
+	}
+
+	termID := helper.MustParamUint("term_id")
+	if termID == 0 {
+		return
+	}
+
+	var req services.SetPlannedSessionsRequest
+	if !helper.MustBindJSON(&req) {
+		return
+	}
+
+	adminID := helper.MustUserID()
+	if adminID == 0 {
+		return
+	}
+
+	summary, errInfo, err := ctl.makeupService.SetPlannedSessions(ctx.Request.Context(), centerID, adminID, offeringID, termID, &req)
+	if err != nil {
+		helper.ErrorWithInfo(errInfo)
+		return
+	}
+
+	helper.Success(summary)
+}
+
+// GetMakeups 取得班別學期補課列表
+// @Summary 取得班別學期補課列表
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param offering_id path int true "Offering ID"
+// @Param term_id path int true "Term ID"
+// @Success 200 {object} global.ApiResponse{data=[]models.MakeupSession}
+// @Router /api/v1/admin/offerings/{offering_id}/terms/{term_id}/makeups [get]
+func (ctl *AdminMakeupController) GetMakeups(ctx *gin.Context) {
+	helper := NewContextHelper(ctx)
+
+	centerID := helper.MustCenterID()
+	if centerID == 0 {
+		return
+	}
+
+	offeringID := helper.MustParamUint("offering_id")
+	if offeringID == 0 {
+		return
+	}
+
+	termID := helper.MustParamUint("term_id")
+	if termID == 0 {
+		return
+	}
+
+	makeups, errInfo, err := ctl.makeupService.ListMakeups(ctx.Request.Context(), centerID, offeringID, termID)
+	if err != nil {
+		helper.ErrorWithInfo(errInfo)
+		return
+	}
+
+	helper.Success(makeups)
+}
+
+// ScheduleMakeup 安排補課
+// @Summary 為被取消的場次安排補課
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body services.ScheduleMakeupRequest true "補課資訊"
+// @Success 200 {object} global.ApiResponse{data=models.MakeupSession}
+// @Failure 409 {object} global.ApiResponse{data=services.ValidationResult}
+// @Router /api/v1/admin/makeups [post]
+func (ctl *AdminMakeupController) ScheduleMakeup(ctx *gin.Context) {
+	helper := NewContextHelper(ctx)
+
+	centerID := helper.MustCenterID()
+	if centerID == 0 {
+		return
+	}
+
+	var req services.ScheduleMakeupRequest
+	if !helper.MustBindJSON(&req) {
+		return
+	}
+
+	adminID := helper.MustUserID()
+	if adminID == 0 {
+		return
+	}
+
+	makeup, conflicts, errInfo, err := ctl.makeupService.ScheduleMakeup(ctx.Request.Context(), centerID, adminID, &req)
+	if err != nil {
+		if conflicts != nil {
+			// 補課時段衝突時回傳衝突明細，讓前端可以提示改選時段
+			ctx.JSON(http.StatusConflict, global.ApiResponse{
+				Code:    errInfo.Code,
+				Message: errInfo.Msg,
+				Datas:   conflicts,
+			})
+			return
+		}
+		helper.ErrorWithInfo(errInfo)
+		return
+	}
+
+	helper.Success(makeup)
+}
+
+// CancelMakeup 取消補課
+// @Summary 取消已安排的補課
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param makeup_id path int true "Makeup ID"
+// @Success 200 {object} global.ApiResponse
+// @Router /api/v1/admin/makeups/{makeup_id} [delete]
+func (ctl *AdminMakeupController) CancelMakeup(ctx *gin.Context) {
+	helper := NewContextHelper(ctx)
+
+	centerID := helper.MustCenterID()
+	if centerID == 0 {
+		return
+	}
+
+	makeupID := helper.MustParamUint("makeup_id")
+	if makeupID == 0 {
+		return
+	}
+
+	adminID := helper.MustUserID()
+	if adminID == 0 {
+		return
+	}
+
+	errInfo, err := ctl.makeupService.CancelMakeup(ctx.Request.Context(), centerID, adminID, makeupID)
+	if err != nil {
+		helper.ErrorWithInfo(errInfo)
+		return
+	}
+
+	helper.Success(nil)
+}
+
+// GetOutstandingMakeups 取得學期尚欠補課報表
+// @Summary 取得學期內各班別尚欠補課報表
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param term_id path int true "Term ID"
+// @Success 200 {object} global.ApiResponse{data=[]services.SessionQuotaSummary}
+// @Router /api/v1/admin/terms/{term_id}/makeup-report [get]
+func (ctl *AdminMakeupController) GetOutstandingMakeups(ctx *gin.Context) {
+	helper := NewContextHelper(ctx)
+
+	centerID := helper.MustCenterID()
+	if centerID == 0 {
+		return
+	}
+
+	termID := helper.MustParamUint("term_id")
+	if termID == 0 {
+		return
+	}
+
+	report, errInfo, err := ctl.makeupService.GetOutstandingMakeupReport(ctx.Request.Context(), centerID, termID)
+	if err != nil {
+		helper.ErrorWithInfo(errInfo)
+		return
+	}
+
+	helper.Success(report)
+}
@@ -2,7 +2,10 @@ package controllers
 
 import (
 	"net/http"
+	"time"
 	"timeLedger/app"
+	"timeLedger/app/models"
+	"timeLedger/app/resources"
 	"timeLedger/app/services"
 	"timeLedger/global"
 	"timeLedger/global/errInfos"
@@ -16,6 +19,7 @@ type AdminNotificationController struct {
 	logger              *services.ServiceLogger
 	lineBotService      services.LineBotService
 	notificationService *services.AdminNotificationService
+	dispatcher          *services.NotificationDispatcher
 }
 
 // NewAdminNotificationController 建立管理員通知控制器
@@ -25,6 +29,7 @@ func NewAdminNotificationController(app *app.App) *AdminNotificationController {
 		logger:              services.NewServiceLogger("AdminNotificationController"),
 		lineBotService:      services.NewLineBotService(app),
 		notificationService: services.NewAdminNotificationService(app),
+		dispatcher:          services.NewNotificationDispatcher(app),
 	}
 }
 
@@ -42,17 +47,39 @@ type BroadcastRequest struct {
 	ActionLabel string `json:"action_label,omitempty" binding:"max=20"`
 	// 按鈕連結（可選）
 	ActionURL string `json:"action_url,omitempty"`
-	// 接收對象過濾條件（可選）
-	// 若為空則發送給中心所有已綁定 LINE 的老師
+	// 接收對象過濾條件（可選），各條件同時成立才會收到，佔位老師一律排除
+	// 若為空則發送給中心所有老師
 	TeacherIDs []uint `json:"teacher_ids,omitempty"`
+	// 教授指定班別的老師
+	OfferingIDs []uint `json:"offering_ids,omitempty"`
+	// 指定日期有課的老師（YYYY-MM-DD）
+	SessionDate string `json:"session_date,omitempty"`
+	// 中心角色（TEACHER、SUBSTITUTE）
+	Roles []string `json:"roles,omitempty"`
+	// 排程發送時間（可選），為空表示立即發送
+	ScheduledAt *time.Time `json:"scheduled_at,omitempty"`
 }
 
 // BroadcastResponse 廣播訊息回應結構
 type BroadcastResponse struct {
+	// 廣播 ID，可用於查詢發送報告
+	BroadcastID uint `json:"broadcast_id,omitempty"`
+	// 廣播狀態（SCHEDULED、SENT、FAILED）
+	Status string `json:"status,omitempty"`
+	// 排程發送時間
+	ScheduledAt *time.Time `json:"scheduled_at,omitempty"`
 	// 成功發送數量
 	SuccessCount int `json:"success_count"`
 	// 失敗發送數量
 	FailedCount int `json:"failed_count"`
+	// 已封鎖官方帳號或尚未加入好友的數量
+	BlockedCount int `json:"blocked_count"`
+	// 依通知偏好延後發送數量（勿擾時段或每日彙整）
+	DeferredCount int `json:"deferred_count"`
+	// 未綁定 LINE 或已關閉廣播 LINE 通知的數量
+	SkippedCount int `json:"skipped_count"`
+	// 已送出的 Email 通知數量
+	EmailCount int `json:"email_count"`
 	// 總共發送數量
 	TotalCount int `json:"total_count"`
 	// 訊息
@@ -61,7 +88,7 @@ type BroadcastResponse struct {
 
 // Broadcast 廣播訊息給中心老師
 // @Summary 廣播訊息給中心老師
-// @Description 管理員發送 LINE 廣播訊息給所屬中心的老師
+// @Description 管理員發送 LINE 廣播訊息給所屬中心的老師，可依班別、上課日期與角色篩選，並可指定 scheduled_at 排程發送
 // @Tags Admin Notification
 // @Accept json
 // @Produce json
@@ -103,20 +130,27 @@ func (c *AdminNotificationController) Broadcast(ctx *gin.Context) {
 	}
 
 	// 呼叫 Service 層處理廣播
-	result, eInfo, err := c.notificationService.BroadcastToTeachers(
-		ctx.Request.Context(),
-		centerID,
-		adminID,
-		req.MessageType,
-		req.Title,
-		req.Message,
-		req.Warning,
-		req.ActionLabel,
-		req.ActionURL,
-		req.TeacherIDs,
-	)
+	result, eInfo, err := c.notificationService.Broadcast(ctx.Request.Context(), centerID, adminID, services.BroadcastInput{
+		MessageType: req.MessageType,
+		Title:       req.Title,
+		Message:     req.Message,
+		Warning:     req.Warning,
+		ActionLabel: req.ActionLabel,
+		ActionURL:   req.ActionURL,
+		Segment: models.BroadcastSegment{
+			TeacherIDs:  req.TeacherIDs,
+			OfferingIDs: req.OfferingIDs,
+			SessionDate: req.SessionDate,
+			Roles:       req.Roles,
+		},
+		ScheduledAt: req.ScheduledAt,
+	})
 
 	if err != nil {
+		if eInfo.Is(errInfos.PARAMS_VALIDATE_ERROR) {
+			helper.BadRequest(err.Error())
+			return
+		}
 		c.logger.Error("broadcast failed", "error", err, "center_id", centerID, "admin_id", adminID)
 		if eInfo != nil {
 			ctx.JSON(http.StatusInternalServerError, global.ApiResponse{
@@ -134,10 +168,17 @@ func (c *AdminNotificationController) Broadcast(ctx *gin.Context) {
 
 	// 回傳結果
 	response := BroadcastResponse{
-		SuccessCount: result.SuccessCount,
-		FailedCount:  result.FailedCount,
-		TotalCount:   result.TotalCount,
-		Message:      result.Message,
+		BroadcastID:   result.BroadcastID,
+		Status:        result.Status,
+		ScheduledAt:   result.ScheduledAt,
+		SuccessCount:  result.SuccessCount,
+		FailedCount:   result.FailedCount,
+		BlockedCount:  result.BlockedCount,
+		DeferredCount: result.DeferredCount,
+		SkippedCount:  result.SkippedCount,
+		EmailCount:    result.EmailCount,
+		TotalCount:    result.TotalCount,
+		Message:       result.Message,
 	}
 
 	ctx.JSON(http.StatusOK, global.ApiResponse{
@@ -146,3 +187,278 @@ func (c *AdminNotificationController) Broadcast(ctx *gin.Context) {
 		Datas:   response,
 	})
 }
+
+// ListBroadcasts 取得廣播列表
+// @Summary 取得廣播列表
+// @Description 含排程中、已發送與已取消的廣播及發送統計
+// @Tags Admin - Notifications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "廣播狀態（SCHEDULED / SENDING / SENT / CANCELLED / FAILED）"
+// @Param page query int false "頁碼，預設 1"
+// @Param limit query int false "每頁筆數，預設 20"
+// @Success 200 {object} global.ApiResponse{data=resources.PaginationResponse}
+// @Router /api/v1/admin/notifications/broadcasts [get]
+func (c *AdminNotificationController) ListBroadcasts(ctx *gin.Context) {
+	helper := NewContextHelper(ctx)
+
+	centerID := helper.MustCenterID()
+	if centerID == 0 {
+		return
+	}
+
+	page := helper.QueryIntOrDefault("page", 1)
+	limit := helper.QueryIntOrDefault("limit", 20)
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	broadcasts, total, errInfo, err := c.notificationService.ListBroadcasts(ctx.Request.Context(), centerID,
+		helper.QueryStringOrDefault("status", ""), page, limit)
+	if err != nil {
+		helper.ErrorWithInfo(errInfo)
+		return
+	}
+
+	helper.Success(resources.NewPaginationResponse(broadcasts, total, page, limit))
+}
+
+// GetBroadcast 取得廣播詳情
+// @Summary 取得廣播詳情
+// @Tags Admin - Notifications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "廣播 ID"
+// @Success 200 {object} global.ApiResponse{data=models.Broadcast}
+// @Router /api/v1/admin/notifications/broadcasts/{id} [get]
+func (c *AdminNotificationController) GetBroadcast(ctx *gin.Context) {
+	helper := NewContextHelper(ctx)
+
+	centerID := helper.MustCenterID()
+	if centerID == 0 {
+		return
+	}
+	id := helper.MustParamUint("id")
+	if id == 0 {
+		return
+	}
+
+	broadcast, errInfo, err := c.notificationService.GetBroadcast(ctx.Request.Context(), centerID, id)
+	if err != nil {
+		helper.ErrorWithInfo(errInfo)
+		return
+	}
+
+	helper.Success(broadcast)
+}
+
+// CancelBroadcast 取消排程廣播
+// @Summary 取消排程廣播
+// @Description 僅能取消尚未開始發送的排程廣播
+// @Tags Admin - Notifications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "廣播 ID"
+// @Success 200 {object} global.ApiResponse{data=models.Broadcast}
+// @Router /api/v1/admin/notifications/broadcasts/{id}/cancel [post]
+func (c *AdminNotificationController) CancelBroadcast(ctx *gin.Context) {
+	helper := NewContextHelper(ctx)
+
+	centerID := helper.MustCenterID()
+	if centerID == 0 {
+		return
+	}
+	id := helper.MustParamUint("id")
+	if id == 0 {
+		return
+	}
+
+	broadcast, errInfo, err := c.notificationService.CancelBroadcast(ctx.Request.Context(), centerID, id)
+	if err != nil {
+		helper.ErrorWithInfo(errInfo)
+		return
+	}
+
+	helper.Success(broadcast)
+}
+
+// ListBroadcastRecipients 取得廣播發送報告
+// @Summary 取得廣播發送報告
+// @Description 每位收件老師的發送結果（SENT / FAILED / BLOCKED / SKIPPED / DEFERRED）與 LINE API 狀態碼
+// @Tags Admin - Notifications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "廣播 ID"
+// @Param status query string false "發送結果"
+// @Param page query int false "頁碼，預設 1"
+// @Param limit query int false "每頁筆數，預設 20"
+// @Success 200 {object} global.ApiResponse{data=resources.PaginationResponse}
+// @Router /api/v1/admin/notifications/broadcasts/{id}/recipients [get]
+func (c *AdminNotificationController) ListBroadcastRecipients(ctx *gin.Context) {
+	helper := NewContextHelper(ctx)
+
+	centerID := helper.MustCenterID()
+	if centerID == 0 {
+		return
+	}
+	id := helper.MustParamUint("id")
+	if id == 0 {
+		return
+	}
+
+	page := helper.QueryIntOrDefault("page", 1)
+	limit := helper.QueryIntOrDefault("limit", 20)
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	recipients, total, errInfo, err := c.notificationService.ListBroadcastRecipients(ctx.Request.Context(), centerID, id,
+		helper.QueryStringOrDefault("status", ""), page, limit)
+	if err != nil {
+		helper.ErrorWithInfo(errInfo)
+		return
+	}
+
+	helper.Success(resources.NewPaginationResponse(recipients, total, page, limit))
+}
+
+// ListDeadLetters 取得死信通知列表
+// @Summary 取得死信通知列表
+// @Description 列出本中心超過重試次數仍發送失敗的通知
+// @Tags Admin - Notifications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param type query string false "通知類型"
+// @Param channel query string false "通知管道（LINE / EMAIL）"
+// @Param page query int false "頁碼，預設 1"
+// @Param limit query int false "每頁筆數，預設 20"
+// @Success 200 {object} global.ApiResponse{data=resources.PaginationResponse}
+// @Router /api/v1/admin/notifications/dead-letters [get]
+func (c *AdminNotificationController) ListDeadLetters(ctx *gin.Context) {
+	helper := NewContextHelper(ctx)
+
+	centerID := helper.MustCenterID()
+	if centerID == 0 {
+		return
+	}
+
+	page := helper.QueryIntOrDefault("page", 1)
+	limit := helper.QueryIntOrDefault("limit", 20)
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	letters, total, errInfo, err := c.dispatcher.ListDeadLetters(ctx.Request.Context(), centerID,
+		helper.QueryStringOrDefault("type", ""), helper.QueryStringOrDefault("channel", ""), page, limit)
+	if err != nil {
+		helper.ErrorWithInfo(errInfo)
+		return
+	}
+
+	helper.Success(resources.NewPaginationResponse(letters, total, page, limit))
+}
+
+// GetDeadLetter 取得死信通知詳情
+// @Summary 取得死信通知詳情
+// @Description 含最後一次錯誤訊息與原始通知內容
+// @Tags Admin - Notifications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "通知 ID"
+// @Success 200 {object} global.ApiResponse{data=services.NotificationDeadLetter}
+// @Router /api/v1/admin/notifications/dead-letters/{id} [get]
+func (c *AdminNotificationController) GetDeadLetter(ctx *gin.Context) {
+	helper := NewContextHelper(ctx)
+
+	centerID := helper.MustCenterID()
+	if centerID == 0 {
+		return
+	}
+	id := helper.MustParamUint("id")
+	if id == 0 {
+		return
+	}
+
+	letter, errInfo, err := c.dispatcher.GetDeadLetter(ctx.Request.Context(), centerID, id)
+	if err != nil {
+		helper.ErrorWithInfo(errInfo)
+		return
+	}
+
+	helper.Success(letter)
+}
+
+// ReplayDeadLetter 重送死信通知
+// @Summary 重送死信通知
+// @Description 重設重試次數並重新派送
+// @Tags Admin - Notifications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "通知 ID"
+// @Success 200 {object} global.ApiResponse{data=services.NotificationDeadLetter}
+// @Router /api/v1/admin/notifications/dead-letters/{id}/replay [post]
+func (c *AdminNotificationController) ReplayDeadLetter(ctx *gin.Context) {
+	helper := NewContextHelper(ctx)
+
+	centerID := helper.MustCenterID()
+	if centerID == 0 {
+		return
+	}
+	id := helper.MustParamUint("id")
+	if id == 0 {
+		return
+	}
+
+	letter, errInfo, err := c.dispatcher.ReplayDeadLetter(ctx.Request.Context(), centerID, id)
+	if err != nil {
+		helper.ErrorWithInfo(errInfo)
+		return
+	}
+
+	helper.Success(letter)
+}
+
+// DiscardDeadLetter 捨棄死信通知
+// @Summary 捨棄死信通知
+// @Tags Admin - Notifications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "通知 ID"
+// @Success 200 {object} global.ApiResponse
+// @Router /api/v1/admin/notifications/dead-letters/{id} [delete]
+func (c *AdminNotificationController) DiscardDeadLetter(ctx *gin.Context) {
+	helper := NewContextHelper(ctx)
+
+	centerID := helper.MustCenterID()
+	if centerID == 0 {
+		return
+	}
+	id := helper.MustParamUint("id")
+	if id == 0 {
+		return
+	}
+
+	if errInfo, err := c.dispatcher.DiscardDeadLetter(ctx.Request.Context(), centerID, id); err != nil {
+		helper.ErrorWithInfo(errInfo)
+		return
+	}
+
+	helper.Success(gin.H{"message": "已捨棄"})
+}
@@ -0,0 +1,158 @@
+package controllers
+
+import (
+	"timeLedger/app"
+	"timeLedger/app/services"
+	"timeLedger/global/errInfos"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminNotificationTemplateController 中心通知範本管理
+type AdminNotificationTemplateController struct {
+	app       *app.App
+	templates *services.NotificationTemplateService
+}
+
+// NewAdminNotificationTemplateController 建立通知範本管理控制器
+func NewAdminNotificationTemplateController(app *app.App) *AdminNotificationTemplateController {
+	return &AdminNotificationTemplateController{
+		app:       app,
+		templates: services.NewNotificationTemplateService(app),
+	}
+}
+
+// respondError 參數錯誤時回傳具體原因，其餘依錯誤碼回應
+func (c *AdminNotificationTemplateController) respondError(helper *ContextHelper, errInfo *errInfos.Res, err error) {
+	if errInfo.Is(errInfos.PARAMS_VALIDATE_ERROR) {
+		helper.BadRequest(err.Error())
+		return
+	}
+	helper.ErrorWithInfo(errInfo)
+}
+
+// ListTemplates 取得通知範本列表
+// @Summary 取得通知範本列表
+// @Description 列出可自訂的通知範本、可用變數、內建預設與本中心的自訂內容；未指定語系時使用中心預設語言
+// @Tags Admin - Notification Templates
+// @Produce json
+// @Security BearerAuth
+// @Param locale query string false "語系（zh-TW / en）"
+// @Success 200 {object} global.ApiResponse{data=[]services.NotificationTemplateView}
+// @Router /api/v1/admin/notification-templates [get]
+func (c *AdminNotificationTemplateController) ListTemplates(ctx *gin.Context) {
+	helper := NewContextHelper(ctx)
+
+	centerID := helper.MustCenterID()
+	if centerID == 0 {
+		return
+	}
+
+	views, errInfo, err := c.templates.ListTemplates(ctx.Request.Context(), centerID, helper.QueryStringOrDefault("locale", ""))
+	if err != nil {
+		c.respondError(helper, errInfo, err)
+		return
+	}
+
+	helper.Success(views)
+}
+
+// SaveTemplate 儲存自訂通知範本
+// @Summary 儲存自訂通知範本
+// @Description 僅需填寫要覆寫的文字與顏色欄位，未填寫的欄位使用內建預設。文字可使用 {{變數}}，顏色格式為 #RRGGBB
+// @Tags Admin - Notification Templates
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param channel path string true "通知管道（LINE）"
+// @Param type path string true "範本類型"
+// @Param locale path string true "語系（zh-TW / en）"
+// @Param request body services.NotificationTemplateRequest true "自訂內容"
+// @Success 200 {object} global.ApiResponse{data=services.NotificationTemplateView}
+// @Router /api/v1/admin/notification-templates/{channel}/{type}/{locale} [put]
+func (c *AdminNotificationTemplateController) SaveTemplate(ctx *gin.Context) {
+	helper := NewContextHelper(ctx)
+
+	centerID := helper.MustCenterID()
+	if centerID == 0 {
+		return
+	}
+	adminID := helper.MustUserID()
+	if adminID == 0 {
+		return
+	}
+
+	var req services.NotificationTemplateRequest
+	if !helper.MustBindJSON(&req) {
+		return
+	}
+
+	view, errInfo, err := c.templates.SaveTemplate(ctx.Request.Context(), centerID, adminID,
+		ctx.Param("type"), ctx.Param("channel"), ctx.Param("locale"), &req)
+	if err != nil {
+		c.respondError(helper, errInfo, err)
+		return
+	}
+
+	helper.Success(view)
+}
+
+// ResetTemplate 恢復內建通知範本
+// @Summary 恢復內建通知範本
+// @Description 刪除本中心的自訂內容
+// @Tags Admin - Notification Templates
+// @Produce json
+// @Security BearerAuth
+// @Param channel path string true "通知管道（LINE）"
+// @Param type path string true "範本類型"
+// @Param locale path string true "語系（zh-TW / en）"
+// @Success 200 {object} global.ApiResponse
+// @Router /api/v1/admin/notification-templates/{channel}/{type}/{locale} [delete]
+func (c *AdminNotificationTemplateController) ResetTemplate(ctx *gin.Context) {
+	helper := NewContextHelper(ctx)
+
+	centerID := helper.MustCenterID()
+	if centerID == 0 {
+		return
+	}
+
+	if errInfo, err := c.templates.ResetTemplate(ctx.Request.Context(), centerID,
+		ctx.Param("type"), ctx.Param("channel"), ctx.Param("locale")); err != nil {
+		c.respondError(helper, errInfo, err)
+		return
+	}
+
+	helper.Success(gin.H{"message": "已恢復預設範本"})
+}
+
+// PreviewTemplate 預覽通知範本
+// @Summary 預覽通知範本
+// @Description 以範例資料產生 Flex Message，可帶入尚未儲存的草稿
+// @Tags Admin - Notification Templates
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body services.NotificationTemplatePreviewRequest true "預覽內容"
+// @Success 200 {object} global.ApiResponse{data=services.NotificationTemplatePreview}
+// @Router /api/v1/admin/notification-templates/preview [post]
+func (c *AdminNotificationTemplateController) PreviewTemplate(ctx *gin.Context) {
+	helper := NewContextHelper(ctx)
+
+	centerID := helper.MustCenterID()
+	if centerID == 0 {
+		return
+	}
+
+	var req services.NotificationTemplatePreviewRequest
+	if !helper.MustBindJSON(&req) {
+		return
+	}
+
+	preview, errInfo, err := c.templates.Preview(ctx.Request.Context(), centerID, &req)
+	if err != nil {
+		c.respondError(helper, errInfo, err)
+		return
+	}
+
+	helper.Success(preview)
+}
@@ -0,0 +1,152 @@
+package controllers
+
+import (
+	"timeLedger/app"
+	"timeLedger/app/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminOperatingHoursController 營業時間管理控制器
+type AdminOperatingHoursController struct {
+	BaseController
+	app                   *app.App
+	operatingHoursService *services.OperatingHoursService
+}
+
+// NewAdminOperatingHoursController 建立 AdminOperatingHoursController 實例
+func NewAdminOperatingHoursController(app *app.App) *AdminOperatingHoursController {
+	return &AdminOperatingHoursController{
+		app:                   app,
+		operatingHoursService: services.NewOperatingHoursService(app),
+	}
+}
+
+// GetOperatingHours 取得每日實際營業時間
+// @Summary 取得日期區間內每天的實際營業時間（含每週設定與日期調整）
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param start_date query string true "開始日期"
+// @Param end_date query string true "結束日期"
+// @Success 200 {object} global.ApiResponse{data=[]services.DailyOperatingHours}
+// @Router /api/v1/admin/operating-hours [get]
+func (ctl *AdminOperatingHoursController) GetOperatingHours(ctx *gin.Context) {
+	helper := NewContextHelper(ctx)
+
+	centerID := helper.MustCenterID()
+	if centerID == 0 {
+		return
+	}
+
+	startDate, endDate := helper.MustQueryDateRange("start_date", "end_date")
+	if startDate.IsZero() || endDate.IsZero() {
+		return
+	}
+
+	hours, errInfo, err := ctl.operatingHoursService.GetOperatingHours(ctx.Request.Context(), centerID, startDate, endDate)
+	if err != nil {
+		helper.ErrorWithInfo(errInfo)
+		return
+	}
+
+	helper.Success(hours)
+}
+
+// GetOverrides 取得營業時間調整列表
+// @Summary 取得營業時間調整列表
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} global.ApiResponse{data=[]models.CenterOperatingHoursOverride}
+// @Router /api/v1/admin/operating-hours/overrides [get]
+func (ctl *AdminOperatingHoursController) GetOverrides(ctx *gin.Context) {
+	helper := NewContextHelper(ctx)
+
+	centerID := helper.MustCenterID()
+	if centerID == 0 {
+		return
+	}
+
+	overrides, errInfo, err := ctl.operatingHoursService.ListOverrides(ctx.Request.Context(), centerID)
+	if err != nil {
+		helper.ErrorWithInfo(errInfo)
+		return
+	}
+
+	helper.Success(overrides)
+}
+
+// CreateOverride 新增營業時間調整
+// @Summary 新增營業時間調整（縮短營業、考試週、臨時公休）
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body services.CreateOperatingHoursOverrideRequest true "營業時間調整"
+// @Success 200 {object} global.ApiResponse{data=models.CenterOperatingHoursOverride}
+// @Router /api/v1/admin/operating-hours/overrides [post]
+func (ctl *AdminOperatingHoursController) CreateOverride(ctx *gin.Context) {
+	helper := NewContextHelper(ctx)
+
+	centerID := helper.MustCenterID()
+	if centerID == 0 {
+		return
+	}
+
+	var req services.CreateOperatingHoursOverrideRequest
+	if !helper.MustBindJSON(&req) {
+		return
+	}
+
+	adminID := helper.MustUserID()
+	if adminID == 0 {
+		return
+	}
+
+	override, errInfo, err := ctl.operatingHoursService.CreateOverride(ctx.Request.Context(), centerID, adminID, &req)
+	if err != nil {
+		helper.ErrorWithInfo(errInfo)
+		return
+	}
+
+	helper.Success(override)
+}
+
+// DeleteOverride 刪除營業時間調整
+// @Summary 刪除營業時間調整
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param override_id path int true "Override ID"
+// @Success 200 {object} global.ApiResponse
+// @Router /api/v1/admin/operating-hours/overrides/{override_id} [delete]
+func (ctl *AdminOperatingHoursController) DeleteOverride(ctx *gin.Context) {
+	helper := NewContextHelper(ctx)
+
+	centerID := helper.MustCenterID()
+	if centerID == 0 {
+		return
+	}
+
+	overrideID := helper.MustParamUint("override_id")
+	if overrideID == 0 {
+		return
+	}
+
+	adminID := helper.MustUserID()
+	if adminID == 0 {
+		return
+	}
+
+	errInfo, err := ctl.operatingHoursService.DeleteOverride(ctx.Request.Context(), centerID, adminID, overrideID)
+	if err != nil {
+		helper.ErrorWithInfo(errInfo)
+		return
+	}
+
+	helper.Success(nil)
+}
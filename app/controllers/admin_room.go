@@ -10,16 +10,18 @@ import (
 
 type AdminRoomController struct {
 	BaseController
-	app          *app.App
-	roomService  *services.RoomService
-	roomResource *resources.RoomResource
+	app                *app.App
+	roomService        *services.RoomService
+	utilizationService *services.RoomUtilizationService
+	roomResource       *resources.RoomResource
 }
 
 func NewAdminRoomController(app *app.App) *AdminRoomController {
 	return &AdminRoomController{
-		app:          app,
-		roomService:  services.NewRoomService(app),
-		roomResource: resources.NewRoomResource(app),
+		app:                app,
+		roomService:        services.NewRoomService(app),
+		utilizationService: services.NewRoomUtilizationService(app),
+		roomResource:       resources.NewRoomResource(app),
 	}
 }
 
@@ -218,3 +220,47 @@ func (ctl *AdminRoomController) ToggleRoomActive(ctx *gin.Context) {
 
 	helper.Success(nil)
 }
+
+// GetRoomUtilization 取得教室使用率分析
+// @Summary 取得教室與時段的使用率、熱度圖，以及與前一學期的比較
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param term_id query int false "學期 ID（指定時以學期起訖日為區間）"
+// @Param start_date query string false "開始日期（未指定學期時必填）"
+// @Param end_date query string false "結束日期（未指定學期時必填）"
+// @Param band_minutes query int false "時段長度（分鐘），預設 60"
+// @Success 200 {object} global.ApiResponse{data=services.RoomUtilizationReport}
+// @Router /api/v1/admin/rooms/utilization [get]
+func (ctl *AdminRoomController) GetRoomUtilization(ctx *gin.Context) {
+	helper := NewContextHelper(ctx)
+
+	centerID := helper.MustCenterID()
+	if centerID == 0 {
+		return
+	}
+
+	query := services.RoomUtilizationQuery{
+		BandMinutes: helper.QueryIntOrDefault("band_minutes", services.DefaultUtilizationBandMinutes),
+	}
+	if _, ok := helper.QueryString("term_id"); ok {
+		query.TermID = helper.MustQueryUint("term_id")
+		if query.TermID == 0 {
+			return
+		}
+	} else {
+		query.StartDate, query.EndDate = helper.MustQueryDateRange("start_date", "end_date")
+		if query.StartDate.IsZero() || query.EndDate.IsZero() {
+			return
+		}
+	}
+
+	report, errInfo, err := ctl.utilizationService.GetRoomUtilization(ctx.Request.Context(), centerID, query)
+	if err != nil {
+		helper.ErrorWithInfo(errInfo)
+		return
+	}
+
+	helper.Success(report)
+}
@@ -0,0 +1,215 @@
+package controllers
+
+import (
+	"net/http"
+	"timeLedger/app"
+	"timeLedger/app/services"
+	"timeLedger/global"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminRoomBookingController 教室預約管理控制器
+type AdminRoomBookingController struct {
+	BaseController
+	app                *app.App
+	roomBookingService *services.RoomBookingService
+}
+
+// NewAdminRoomBookingController 建立 AdminRoomBookingController 實例
+func NewAdminRoomBookingController(app *app.App) *AdminRoomBookingController {
+	return &AdminRoomBookingController{
+		app:                app,
+		roomBookingService: services.NewRoomBookingService(app),
+	}
+}
+
+// GetBookings 取得教室預約列表
+// @Summary 取得中心的教室預約（會議、租借、維修）
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} global.ApiResponse{data=[]models.RoomBooking}
+// @Router /api/v1/admin/room-bookings [get]
+func (ctl *AdminRoomBookingController) GetBookings(ctx *gin.Context) {
+	helper := NewContextHelper(ctx)
+
+	centerID := helper.MustCenterID()
+	if centerID == 0 {
+		return
+	}
+
+	bookings, errInfo, err := ctl.roomBookingService.ListBookings(ctx.Request.Context(), centerID)
+	if err != nil {
+		helper.ErrorWithInfo(errInfo)
+		return
+	}
+
+	helper.Success(bookings)
+}
+
+// GetOccurrences 取得日期區間內的教室預約
+// @Summary 取得日期區間內每天的教室預約（每週預約會展開）
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param start_date query string true "開始日期"
+// @Param end_date query string true "結束日期"
+// @Success 200 {object} global.ApiResponse{data=[]services.RoomBookingOccurrence}
+// @Router /api/v1/admin/room-bookings/occurrences [get]
+func (ctl *AdminRoomBookingController) GetOccurrences(ctx *gin.Context) {
+	helper := NewContextHelper(ctx)
+
+	centerID := helper.MustCenterID()
+	if centerID == 0 {
+		return
+	}
+
+	startDate, endDate := helper.MustQueryDateRange("start_date", "end_date")
+	if startDate.IsZero() || endDate.IsZero() {
+		return
+	}
+
+	occurrences, errInfo, err := ctl.roomBookingService.ListOccurrences(ctx.Request.Context(), centerID, startDate, endDate)
+	if err != nil {
+		helper.ErrorWithInfo(errInfo)
+		return
+	}
+
+	helper.Success(occurrences)
+}
+
+// CreateBooking 建立教室預約
+// @Summary 建立教室預約（單次或每週）
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body services.RoomBookingRequest true "預約資訊"
+// @Success 200 {object} global.ApiResponse{data=models.RoomBooking}
+// @Failure 409 {object} global.ApiResponse{data=services.ValidationResult}
+// @Router /api/v1/admin/room-bookings [post]
+func (ctl *AdminRoomBookingController) CreateBooking(ctx *gin.Context) {
+	helper := NewContextHelper(ctx)
+
+	centerID := helper.MustCenterID()
+	if centerID == 0 {
+		return
+	}
+
+	var req services.RoomBookingRequest
+	if !helper.MustBindJSON(&req) {
+		return
+	}
+
+	adminID := helper.MustUserID()
+	if adminID == 0 {
+		return
+	}
+
+	booking, conflicts, errInfo, err := ctl.roomBookingService.CreateBooking(ctx.Request.Context(), centerID, adminID, &req)
+	if err != nil {
+		if conflicts != nil {
+			// 與課程或其他預約衝突時回傳衝突明細
+			ctx.JSON(http.StatusConflict, global.ApiResponse{
+				Code:    errInfo.Code,
+				Message: errInfo.Msg,
+				Datas:   conflicts,
+			})
+			return
+		}
+		helper.ErrorWithInfo(errInfo)
+		return
+	}
+
+	helper.Success(booking)
+}
+
+// UpdateBooking 更新教室預約
+// @Summary 更新教室預約
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param booking_id path int true "預約 ID"
+// @Param request body services.RoomBookingRequest true "預約資訊"
+// @Success 200 {object} global.ApiResponse{data=models.RoomBooking}
+// @Failure 409 {object} global.ApiResponse{data=services.ValidationResult}
+// @Router /api/v1/admin/room-bookings/{booking_id} [put]
+func (ctl *AdminRoomBookingController) UpdateBooking(ctx *gin.Context) {
+	helper := NewContextHelper(ctx)
+
+	centerID := helper.MustCenterID()
+	if centerID == 0 {
+		return
+	}
+
+	bookingID := helper.MustParamUint("booking_id")
+	if bookingID == 0 {
+		return
+	}
+
+	var req services.RoomBookingRequest
+	if !helper.MustBindJSON(&req) {
+		return
+	}
+
+	adminID := helper.MustUserID()
+	if adminID == 0 {
+		return
+	}
+
+	booking, conflicts, errInfo, err := ctl.roomBookingService.UpdateBooking(ctx.Request.Context(), centerID, adminID, bookingID, &req)
+	if err != nil {
+		if conflicts != nil {
+			ctx.JSON(http.StatusConflict, global.ApiResponse{
+				Code:    errInfo.Code,
+				Message: errInfo.Msg,
+				Datas:   conflicts,
+			})
+			return
+		}
+		helper.ErrorWithInfo(errInfo)
+		return
+	}
+
+	helper.Success(booking)
+}
+
+// DeleteBooking 刪除教室預約
+// @Summary 刪除教室預約
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param booking_id path int true "預約 ID"
+// @Success 200 {object} global.ApiResponse
+// @Router /api/v1/admin/room-bookings/{booking_id} [delete]
+func (ctl *AdminRoomBookingController) DeleteBooking(ctx *gin.Context) {
+	helper := NewContextHelper(ctx)
+
+	centerID := helper.MustCenterID()
+	if centerID == 0 {
+		return
+	}
+
+	bookingID := helper.MustParamUint("booking_id")
+	if bookingID == 0 {
+		return
+	}
+
+	adminID := helper.MustUserID()
+	if adminID == 0 {
+		return
+	}
+
+	errInfo, err := ctl.roomBookingService.DeleteBooking(ctx.Request.Context(), centerID, adminID, bookingID)
+	if err != nil {
+		helper.ErrorWithInfo(errInfo)
+		return
+	}
+
+	helper.Success(nil)
+}
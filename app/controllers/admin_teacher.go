@@ -25,6 +25,7 @@ type AdminTeacherController struct {
 	adminTeacherResource  *resources.AdminTeacherResource
 	teacherNoteResource   *resources.TeacherNoteResource
 	teacherMergeService    *services.TeacherMergeService
+	domainEvents           *services.DomainEventService
 }
 
 func NewAdminTeacherController(app *app.App) *AdminTeacherController {
@@ -37,6 +38,7 @@ func NewAdminTeacherController(app *app.App) *AdminTeacherController {
 		adminTeacherResource:  resources.NewAdminTeacherResource(),
 		teacherNoteResource:   resources.NewTeacherNoteResource(),
 		teacherMergeService:   services.NewTeacherMergeService(app),
+		domainEvents:          services.NewDomainEventService(app),
 	}
 }
 
@@ -312,6 +314,13 @@ func (ctl *AdminTeacherController) DeleteTeacher(ctx *gin.Context) {
 		return
 	}
 
+	// 會籍刪除前先取得所屬中心，供稽核與離開中心事件使用
+	memberships, _ := ctl.membershipRepo.GetActiveByTeacherID(ctx, teacherID)
+	var centerID uint
+	if len(memberships) > 0 {
+		centerID = memberships[0].CenterID
+	}
+
 	if err := ctl.teacherRepository.DeleteByID(ctx, teacherID); err != nil {
 		helper.InternalError("Failed to delete teacher")
 		return
@@ -323,10 +332,10 @@ func (ctl *AdminTeacherController) DeleteTeacher(ctx *gin.Context) {
 		logger.GetLogger().Errorw("Failed to cleanup memberships after deleting teacher", "teacher_id", teacherID, "error", err)
 	}
 
-	memberships, _ := ctl.membershipRepo.GetActiveByTeacherID(ctx, teacherID)
-	var centerID uint
-	if len(memberships) > 0 {
-		centerID = memberships[0].CenterID
+	for _, m := range memberships {
+		if err := ctl.domainEvents.Record(ctx, services.TeacherLeftEvent(m.CenterID, adminID, teacher, "DELETED")); err != nil {
+			logger.GetLogger().Errorw("Failed to record teacher left event", "teacher_id", teacherID, "center_id", m.CenterID, "error", err)
+		}
 	}
 
 	ctl.auditLogRepo.Create(ctx, models.AuditLog{
@@ -407,6 +416,12 @@ func (ctl *AdminTeacherController) RemoveFromCenter(ctx *gin.Context) {
 		},
 	})
 
+	teacher, _ := ctl.teacherRepository.GetByID(ctx, teacherID)
+	teacher.ID = teacherID
+	if err := ctl.domainEvents.Record(ctx, services.TeacherLeftEvent(centerID, adminID, teacher, "REMOVED")); err != nil {
+		logger.GetLogger().Errorw("Failed to record teacher left event", "teacher_id", teacherID, "center_id", centerID, "error", err)
+	}
+
 	helper.Success(nil)
 }
 
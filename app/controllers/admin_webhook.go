@@ -0,0 +1,332 @@
+package controllers
+
+import (
+	"timeLedger/app"
+	"timeLedger/app/models"
+	"timeLedger/app/resources"
+	"timeLedger/app/services"
+	"timeLedger/global/errInfos"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminWebhookController 中心對外 webhook 管理
+type AdminWebhookController struct {
+	app      *app.App
+	webhooks *services.WebhookService
+}
+
+// NewAdminWebhookController 建立 webhook 管理控制器
+func NewAdminWebhookController(app *app.App) *AdminWebhookController {
+	return &AdminWebhookController{
+		app:      app,
+		webhooks: services.NewWebhookService(app),
+	}
+}
+
+// respondError 參數錯誤時回傳具體原因，其餘依錯誤碼回應
+func (c *AdminWebhookController) respondError(helper *ContextHelper, errInfo *errInfos.Res, err error) {
+	if errInfo.Is(errInfos.PARAMS_VALIDATE_ERROR) {
+		helper.BadRequest(err.Error())
+		return
+	}
+	helper.ErrorWithInfo(errInfo)
+}
+
+// ListEventTypes 取得可訂閱的事件類型
+// @Summary 取得可訂閱的 webhook 事件類型
+// @Tags Admin - Webhooks
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} global.ApiResponse{data=[]string}
+// @Router /api/v1/admin/webhooks/event-types [get]
+func (c *AdminWebhookController) ListEventTypes(ctx *gin.Context) {
+	helper := NewContextHelper(ctx)
+	helper.Success(models.WebhookEvents)
+}
+
+// ListSubscriptions 取得 webhook 訂閱列表
+// @Summary 取得 webhook 訂閱列表
+// @Tags Admin - Webhooks
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} global.ApiResponse{data=[]models.WebhookSubscription}
+// @Router /api/v1/admin/webhooks [get]
+func (c *AdminWebhookController) ListSubscriptions(ctx *gin.Context) {
+	helper := NewContextHelper(ctx)
+
+	centerID := helper.MustCenterID()
+	if centerID == 0 {
+		return
+	}
+
+	subscriptions, errInfo, err := c.webhooks.ListSubscriptions(ctx.Request.Context(), centerID)
+	if err != nil {
+		helper.ErrorWithInfo(errInfo)
+		return
+	}
+
+	helper.Success(subscriptions)
+}
+
+// CreateSubscription 新增 webhook 訂閱
+// @Summary 新增 webhook 訂閱
+// @Description 回應中的 secret 僅顯示這一次，用於驗證 X-TimeLedger-Signature；event_types 為空表示訂閱全部事件
+// @Tags Admin - Webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body services.WebhookSubscriptionRequest true "訂閱內容"
+// @Success 201 {object} global.ApiResponse{data=services.WebhookSubscriptionWithSecret}
+// @Router /api/v1/admin/webhooks [post]
+func (c *AdminWebhookController) CreateSubscription(ctx *gin.Context) {
+	helper := NewContextHelper(ctx)
+
+	centerID := helper.MustCenterID()
+	if centerID == 0 {
+		return
+	}
+	adminID := helper.MustUserID()
+	if adminID == 0 {
+		return
+	}
+
+	var req services.WebhookSubscriptionRequest
+	if !helper.MustBindJSON(&req) {
+		return
+	}
+
+	subscription, errInfo, err := c.webhooks.CreateSubscription(ctx.Request.Context(), centerID, adminID, &req)
+	if err != nil {
+		c.respondError(helper, errInfo, err)
+		return
+	}
+
+	helper.Created(subscription)
+}
+
+// UpdateSubscription 更新 webhook 訂閱
+// @Summary 更新 webhook 訂閱
+// @Tags Admin - Webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "訂閱 ID"
+// @Param request body services.WebhookSubscriptionRequest true "訂閱內容"
+// @Success 200 {object} global.ApiResponse{data=models.WebhookSubscription}
+// @Router /api/v1/admin/webhooks/{id} [put]
+func (c *AdminWebhookController) UpdateSubscription(ctx *gin.Context) {
+	helper := NewContextHelper(ctx)
+
+	centerID := helper.MustCenterID()
+	if centerID == 0 {
+		return
+	}
+	id := helper.MustParamUint("id")
+	if id == 0 {
+		return
+	}
+
+	var req services.WebhookSubscriptionRequest
+	if !helper.MustBindJSON(&req) {
+		return
+	}
+
+	subscription, errInfo, err := c.webhooks.UpdateSubscription(ctx.Request.Context(), centerID, id, &req)
+	if err != nil {
+		c.respondError(helper, errInfo, err)
+		return
+	}
+
+	helper.Success(subscription)
+}
+
+// DeleteSubscription 刪除 webhook 訂閱
+// @Summary 刪除 webhook 訂閱
+// @Tags Admin - Webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "訂閱 ID"
+// @Success 200 {object} global.ApiResponse
+// @Router /api/v1/admin/webhooks/{id} [delete]
+func (c *AdminWebhookController) DeleteSubscription(ctx *gin.Context) {
+	helper := NewContextHelper(ctx)
+
+	centerID := helper.MustCenterID()
+	if centerID == 0 {
+		return
+	}
+	id := helper.MustParamUint("id")
+	if id == 0 {
+		return
+	}
+
+	if errInfo, err := c.webhooks.DeleteSubscription(ctx.Request.Context(), centerID, id); err != nil {
+		helper.ErrorWithInfo(errInfo)
+		return
+	}
+
+	helper.Success(gin.H{"message": "已刪除"})
+}
+
+// RotateSecret 重設 webhook 簽章密鑰
+// @Summary 重設 webhook 簽章密鑰
+// @Description 舊密鑰立即失效，新密鑰僅顯示這一次
+// @Tags Admin - Webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "訂閱 ID"
+// @Success 200 {object} global.ApiResponse{data=services.WebhookSubscriptionWithSecret}
+// @Router /api/v1/admin/webhooks/{id}/rotate-secret [post]
+func (c *AdminWebhookController) RotateSecret(ctx *gin.Context) {
+	helper := NewContextHelper(ctx)
+
+	centerID := helper.MustCenterID()
+	if centerID == 0 {
+		return
+	}
+	id := helper.MustParamUint("id")
+	if id == 0 {
+		return
+	}
+
+	subscription, errInfo, err := c.webhooks.RotateSecret(ctx.Request.Context(), centerID, id)
+	if err != nil {
+		helper.ErrorWithInfo(errInfo)
+		return
+	}
+
+	helper.Success(subscription)
+}
+
+// SendTestEvent 發送測試事件
+// @Summary 發送 webhook 測試事件
+// @Description 立即送出 webhook.test 事件並回傳投遞結果（HTTP 狀態碼、回應內容與耗時）
+// @Tags Admin - Webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "訂閱 ID"
+// @Success 200 {object} global.ApiResponse{data=services.WebhookDeliveryDetail}
+// @Router /api/v1/admin/webhooks/{id}/test [post]
+func (c *AdminWebhookController) SendTestEvent(ctx *gin.Context) {
+	helper := NewContextHelper(ctx)
+
+	centerID := helper.MustCenterID()
+	if centerID == 0 {
+		return
+	}
+	id := helper.MustParamUint("id")
+	if id == 0 {
+		return
+	}
+
+	delivery, errInfo, err := c.webhooks.SendTestEvent(ctx.Request.Context(), centerID, id)
+	if err != nil {
+		helper.ErrorWithInfo(errInfo)
+		return
+	}
+
+	helper.Success(delivery)
+}
+
+// ListDeliveries 取得 webhook 投遞紀錄
+// @Summary 取得 webhook 投遞紀錄
+// @Tags Admin - Webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "訂閱 ID"
+// @Param status query string false "投遞狀態（pending / delivered / failed）"
+// @Param page query int false "頁碼，預設 1"
+// @Param limit query int false "每頁筆數，預設 20"
+// @Success 200 {object} global.ApiResponse{data=resources.PaginationResponse}
+// @Router /api/v1/admin/webhooks/{id}/deliveries [get]
+func (c *AdminWebhookController) ListDeliveries(ctx *gin.Context) {
+	helper := NewContextHelper(ctx)
+
+	centerID := helper.MustCenterID()
+	if centerID == 0 {
+		return
+	}
+	id := helper.MustParamUint("id")
+	if id == 0 {
+		return
+	}
+
+	page := helper.QueryIntOrDefault("page", 1)
+	limit := helper.QueryIntOrDefault("limit", 20)
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	deliveries, total, errInfo, err := c.webhooks.ListDeliveries(ctx.Request.Context(), centerID, id,
+		helper.QueryStringOrDefault("status", ""), page, limit)
+	if err != nil {
+		helper.ErrorWithInfo(errInfo)
+		return
+	}
+
+	helper.Success(resources.NewPaginationResponse(deliveries, total, page, limit))
+}
+
+// GetDelivery 取得 webhook 投遞紀錄詳情
+// @Summary 取得 webhook 投遞紀錄詳情
+// @Description 含送出的內容與最後一次回應
+// @Tags Admin - Webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param delivery_id path int true "投遞紀錄 ID"
+// @Success 200 {object} global.ApiResponse{data=services.WebhookDeliveryDetail}
+// @Router /api/v1/admin/webhooks/deliveries/{delivery_id} [get]
+func (c *AdminWebhookController) GetDelivery(ctx *gin.Context) {
+	helper := NewContextHelper(ctx)
+
+	centerID := helper.MustCenterID()
+	if centerID == 0 {
+		return
+	}
+	id := helper.MustParamUint("delivery_id")
+	if id == 0 {
+		return
+	}
+
+	delivery, errInfo, err := c.webhooks.GetDelivery(ctx.Request.Context(), centerID, id)
+	if err != nil {
+		helper.ErrorWithInfo(errInfo)
+		return
+	}
+
+	helper.Success(delivery)
+}
+
+// Redeliver 重送失敗的 webhook
+// @Summary 重送失敗的 webhook
+// @Description 重設重試次數並立即送出一次，之後依退避策略重試
+// @Tags Admin - Webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param delivery_id path int true "投遞紀錄 ID"
+// @Success 200 {object} global.ApiResponse{data=services.WebhookDeliveryDetail}
+// @Router /api/v1/admin/webhooks/deliveries/{delivery_id}/redeliver [post]
+func (c *AdminWebhookController) Redeliver(ctx *gin.Context) {
+	helper := NewContextHelper(ctx)
+
+	centerID := helper.MustCenterID()
+	if centerID == 0 {
+		return
+	}
+	id := helper.MustParamUint("delivery_id")
+	if id == 0 {
+		return
+	}
+
+	delivery, errInfo, err := c.webhooks.Redeliver(ctx.Request.Context(), centerID, id)
+	if err != nil {
+		helper.ErrorWithInfo(errInfo)
+		return
+	}
+
+	helper.Success(delivery)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 	"timeLedger/global"
 	"timeLedger/global/errInfos"
@@ -245,6 +246,32 @@ func (h *ContextHelper) MustBindJSON(ptr any) bool {
 	return true
 }
 
+// ParseIfMatchVersion 解析 If-Match header 的版本號，接受 3、"3"、W/"3"
+func ParseIfMatchVersion(header string) (uint, error) {
+	value := strings.TrimSpace(header)
+	value = strings.TrimPrefix(value, "W/")
+	value = strings.Trim(value, `"`)
+	version, err := strconv.ParseUint(value, 10, 32)
+	if err != nil || version == 0 {
+		return 0, fmt.Errorf("invalid If-Match version: %s", header)
+	}
+	return uint(version), nil
+}
+
+// MustExpectedVersion 取得樂觀鎖版本，優先使用 If-Match header，其次為請求體的 version
+// 兩者皆未提供時回傳 nil；格式錯誤時回傳 400 錯誤
+func (h *ContextHelper) MustExpectedVersion(bodyVersion *uint) (*uint, bool) {
+	if header := h.ctx.GetHeader("If-Match"); header != "" {
+		version, err := ParseIfMatchVersion(header)
+		if err != nil {
+			h.BadRequest(err.Error())
+			return nil, false
+		}
+		return &version, true
+	}
+	return bodyVersion, true
+}
+
 // GinContext 回傳原始 gin.Context
 func (h *ContextHelper) GinContext() *gin.Context {
 	return h.ctx
@@ -275,6 +302,11 @@ func (h *ContextHelper) Created(data any) {
 	})
 }
 
+// SetVersionETag 以版本號設定 ETag，前端可直接作為下次更新的 If-Match
+func (h *ContextHelper) SetVersionETag(version uint) {
+	h.ctx.Header("ETag", fmt.Sprintf(`"%d"`, version))
+}
+
 // NoContent 回傳無內容響應
 func (h *ContextHelper) NoContent() {
 	h.ctx.Status(http.StatusNoContent)
@@ -320,6 +352,15 @@ func (h *ContextHelper) Conflict(message string) {
 	})
 }
 
+// ConflictWithData 回傳 409 錯誤並附上衝突明細
+func (h *ContextHelper) ConflictWithData(errInfo *errInfos.Res, data any) {
+	h.ctx.JSON(http.StatusConflict, global.ApiResponse{
+		Code:    errInfo.Code,
+		Message: errInfo.Msg,
+		Datas:   data,
+	})
+}
+
 // InternalError 回傳 500 錯誤
 func (h *ContextHelper) InternalError(message string) {
 	h.ctx.JSON(http.StatusInternalServerError, global.ApiResponse{
@@ -347,20 +388,22 @@ func (h *ContextHelper) ErrorWithInfo(errInfo *errInfos.Res) {
 			string(h.ctx.Request.URL.RawQuery)))
 	}
 
-	// 根據錯誤碼決定 HTTP 狀態碼
+	// 根據錯誤碼決定 HTTP 狀態碼（Code 帶有 app ID 前綴，以原始錯誤碼判斷）
 	status := http.StatusInternalServerError
-	switch errInfo.Code {
+	switch errInfo.BaseCode() {
 	case global.UNAUTHORIZED:
 		status = http.StatusUnauthorized
 	case global.FORBIDDEN:
 		status = http.StatusForbidden
-	case errInfos.NOT_FOUND:
+	case errInfos.NOT_FOUND, errInfos.NOTIFICATION_NOT_FOUND, errInfos.WEBHOOK_NOT_FOUND,
+		errInfos.BROADCAST_NOT_FOUND, errInfos.NOTIFICATION_TEMPLATE_NOT_FOUND:
 		status = http.StatusNotFound
 	case global.BAD_REQUEST, errInfos.PARAMS_VALIDATE_ERROR:
 		status = http.StatusBadRequest
 	case errInfos.INVALID_STATUS, errInfos.SCHED_OVERLAP, errInfos.SCHED_BUFFER,
 		errInfos.SCHED_RULE_CONFLICT, errInfos.ERR_RESOURCE_LOCKED,
-		errInfos.ERR_CONCURRENT_MODIFIED, errInfos.ERR_TX_FAILED:
+		errInfos.ERR_CONCURRENT_MODIFIED, errInfos.ERR_TX_FAILED, errInfos.WEBHOOK_DELIVERY_NOT_FAILED,
+		errInfos.BROADCAST_NOT_CANCELLABLE:
 		status = http.StatusConflict
 	}
 	h.ctx.JSON(status, global.ApiResponse{
@@ -12,6 +12,7 @@ type NotificationController struct {
 	BaseController
 	app                 *app.App
 	notificationService services.NotificationService
+	preferenceService   *services.NotificationPreferenceService
 }
 
 type ListNotificationsRequest struct {
@@ -34,6 +35,7 @@ func NewNotificationController(app *app.App) *NotificationController {
 	return &NotificationController{
 		app:                 app,
 		notificationService: services.NewNotificationService(app),
+		preferenceService:   services.NewNotificationPreferenceService(app),
 	}
 }
 
@@ -208,6 +210,72 @@ func (ctl *NotificationController) SetNotifyToken(ctx *gin.Context) {
 	helper.Success(gin.H{"message": "Notify token updated successfully"})
 }
 
+// preferenceUserType 通知偏好依老師 / 管理員區分，OWNER 與 ADMIN 共用管理員設定
+func (ctl *NotificationController) preferenceUserType(helper *ContextHelper) string {
+	if helper.IsAdmin() {
+		return "ADMIN"
+	}
+	return "TEACHER"
+}
+
+// GetPreferences 取得通知偏好
+// @Summary 取得通知偏好
+// @Description 取得目前使用者各事件的通知管道、勿擾時段與每日彙整設定
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Success 200 {object} global.ApiResponse{data=models.NotificationPreference}
+// @Router /notifications/preferences [get]
+func (ctl *NotificationController) GetPreferences(ctx *gin.Context) {
+	helper := NewContextHelper(ctx)
+	userID := ctl.requireUserID(helper)
+	if userID == 0 {
+		return
+	}
+
+	pref, err := ctl.preferenceService.GetPreference(ctx.Request.Context(), ctl.preferenceUserType(helper), userID)
+	if err != nil {
+		helper.InternalError(err.Error())
+		return
+	}
+
+	helper.Success(pref)
+}
+
+// UpdatePreferences 更新通知偏好
+// @Summary 更新通知偏好
+// @Description 設定各事件（REMINDER、EXCEPTION_SUBMIT、EXCEPTION_RESULT、BROADCAST、INVITATION）的通知管道（LINE、IN_APP、EMAIL），以及勿擾時段與每日彙整；未帶的欄位維持原設定
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Param request body services.UpdateNotificationPreferenceRequest true "通知偏好"
+// @Success 200 {object} global.ApiResponse{data=models.NotificationPreference}
+// @Router /notifications/preferences [put]
+func (ctl *NotificationController) UpdatePreferences(ctx *gin.Context) {
+	helper := NewContextHelper(ctx)
+	userID := ctl.requireUserID(helper)
+	if userID == 0 {
+		return
+	}
+
+	var req services.UpdateNotificationPreferenceRequest
+	if !helper.MustBindJSON(&req) {
+		return
+	}
+
+	pref, errInfo, err := ctl.preferenceService.UpdatePreference(ctx.Request.Context(), ctl.preferenceUserType(helper), userID, &req)
+	if err != nil {
+		if errInfo != nil {
+			helper.ErrorWithInfo(errInfo)
+			return
+		}
+		helper.InternalError(err.Error())
+		return
+	}
+
+	helper.Success(pref)
+}
+
 // SendTestNotification 發送測試通知
 // @Summary 發送測試通知
 // @Description 發送一條測試通知給目前使用者
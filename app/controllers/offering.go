@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"errors"
 	"fmt"
 	"timeLedger/app"
 	"timeLedger/app/resources"
@@ -124,6 +125,8 @@ type UpdateOfferingRequest struct {
 	DefaultRoomID       *uint   `json:"default_room_id"`
 	DefaultTeacherID    *uint   `json:"default_teacher_id"`
 	AllowBufferOverride bool    `json:"allow_buffer_override"`
+	// Version 讀取時的班別版本，亦可改用 If-Match header；未提供時不比對
+	Version *uint `json:"version"`
 }
 
 // UpdateOffering 更新班別
@@ -159,6 +162,11 @@ func (c *OfferingController) UpdateOffering(ctx *gin.Context) {
 		return
 	}
 
+	expectedVersion, ok := helper.MustExpectedVersion(req.Version)
+	if !ok {
+		return
+	}
+
 	result, errInfo, err := c.offeringService.UpdateOffering(ctx.Request.Context(), &services.UpdateOfferingInput{
 		CenterID:            centerID,
 		AdminID:             adminID,
@@ -167,12 +175,19 @@ func (c *OfferingController) UpdateOffering(ctx *gin.Context) {
 		DefaultRoomID:       req.DefaultRoomID,
 		DefaultTeacherID:    req.DefaultTeacherID,
 		AllowBufferOverride: req.AllowBufferOverride,
+		Version:             expectedVersion,
 	})
 	if err != nil {
+		var conflictErr *services.VersionConflictError
+		if errors.As(err, &conflictErr) {
+			helper.ConflictWithData(errInfo, conflictErr.Conflict)
+			return
+		}
 		helper.ErrorWithInfo(errInfo)
 		return
 	}
 
+	helper.SetVersionETag(result.Version)
 	helper.Success(result)
 }
 
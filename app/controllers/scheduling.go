@@ -1,12 +1,14 @@
 package controllers
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
 	"time"
 
 	"timeLedger/app"
+	"timeLedger/app/models"
 	"timeLedger/app/requests"
 	"timeLedger/app/resources"
 	"timeLedger/app/services"
@@ -377,6 +379,9 @@ func (ctl *SchedulingController) CreateRule(ctx *gin.Context) {
 		StartDate:      req.StartDate,
 		EndDate:        req.EndDate,
 		OverrideBuffer: req.OverrideBuffer,
+
+		AdditionalTeachers: req.AdditionalTeachers,
+		AdditionalRoomIDs:  req.AdditionalRoomIDs,
 	}
 
 	rules, errInfo, err := ctl.scheduleSvc.CreateRule(ctx.Request.Context(), centerID, adminID, svcReq)
@@ -425,6 +430,11 @@ func (ctl *SchedulingController) UpdateRule(ctx *gin.Context) {
 		return
 	}
 
+	expectedVersion, ok := helper.MustExpectedVersion(req.Version)
+	if !ok {
+		return
+	}
+
 	svcReq := &services.UpdateScheduleRuleRequest{
 		Name:            req.Name,
 		OfferingID:      req.OfferingID,
@@ -440,10 +450,24 @@ func (ctl *SchedulingController) UpdateRule(ctx *gin.Context) {
 		Status:          req.Status,
 		UpdateMode:      req.UpdateMode,
 		ExcludeRuleID:   &ruleID, // 排除自己，避免與自己衝突
+
+		AdditionalTeachers: req.AdditionalTeachers,
+		AdditionalRoomIDs:  req.AdditionalRoomIDs,
+		Version:            expectedVersion,
 	}
 
 	rules, errInfo, err := ctl.scheduleSvc.UpdateRule(ctx.Request.Context(), centerID, adminID, ruleID, svcReq)
 	if err != nil {
+		var conflictErr *services.VersionConflictError
+		if errors.As(err, &conflictErr) {
+			// 回傳伺服器目前的規則，供前端顯示差異
+			conflict := conflictErr.Conflict
+			if current, ok := conflict.Current.(models.ScheduleRule); ok {
+				conflict.Current = ctl.scheduleResource.ToRuleResponse(current)
+			}
+			helper.ConflictWithData(errInfo, conflict)
+			return
+		}
 		if errInfo != nil {
 			helper.ErrorWithInfo(errInfo)
 		} else {
@@ -456,6 +480,9 @@ func (ctl *SchedulingController) UpdateRule(ctx *gin.Context) {
 	var ruleResponses []*resources.ScheduleRuleResponse
 	for _, rule := range rules {
 		ruleResponses = append(ruleResponses, ctl.scheduleResource.ToRuleResponse(rule))
+		if rule.ID == ruleID {
+			helper.SetVersionETag(rule.Version)
+		}
 	}
 
 	helper.Success(ruleResponses)
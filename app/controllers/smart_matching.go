@@ -365,6 +365,7 @@ type GetAlternativeSlotsRequest struct {
 	OriginalStart time.Time `json:"original_start" binding:"required"`
 	OriginalEnd   time.Time `json:"original_end" binding:"required"`
 	Duration     int       `json:"duration"`
+	CourseID     uint      `json:"course_id"` // 可選，指定後只建議符合課程需求的教室
 }
 
 // GetAlternativeSlots - 取得替代時段建議
@@ -388,7 +389,7 @@ func (ctl *SmartMatchingController) GetAlternativeSlots(ctx *gin.Context) {
 		return
 	}
 
-	alternatives, err := ctl.smartMatchingSvc.GetAlternativeSlots(ctx.Request.Context(), centerID, req.TeacherID, req.OriginalStart, req.OriginalEnd, req.Duration)
+	alternatives, err := ctl.smartMatchingSvc.GetAlternativeSlots(ctx.Request.Context(), centerID, req.TeacherID, req.OriginalStart, req.OriginalEnd, req.Duration, req.CourseID)
 	if err != nil {
 		helper.InternalError(err.Error())
 		return
@@ -110,13 +110,14 @@ func (ctl *TeacherScheduleController) GetCenterScheduleRules(ctx *gin.Context) {
 		EndTime            string `json:"end_time"`
 		EffectiveStartDate string `json:"effective_start_date"`
 		EffectiveEndDate   string `json:"effective_end_date"`
+		Role               string `json:"role"` // 授課角色：LEAD、ASSISTANT、TA
 	}
 
 	weekdayTexts := []string{"週日", "週一", "週二", "週三", "週四", "週五", "週六"}
 
 	var teacherRules []RuleResponse
 	for _, rule := range rules {
-		if rule.TeacherID != nil && *rule.TeacherID == teacherID {
+		if rule.HasTeacher(teacherID) {
 			title := rule.Offering.Name
 			if title == "" {
 				title = rule.Name
@@ -150,6 +151,7 @@ func (ctl *TeacherScheduleController) GetCenterScheduleRules(ctx *gin.Context) {
 				EndTime:            rule.EndTime,
 				EffectiveStartDate: effectiveStartDate,
 				EffectiveEndDate:   effectiveEndDate,
+				Role:               rule.TeacherRole(teacherID),
 			})
 		}
 	}
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
 	"time"
 	"timeLedger/app"
@@ -8,6 +9,7 @@ import (
 	"timeLedger/app/repositories"
 	"timeLedger/app/services"
 	"timeLedger/global"
+	"timeLedger/global/errInfos"
 
 	"github.com/gin-gonic/gin"
 )
@@ -125,6 +127,8 @@ func (c *TimetableTemplateController) CreateTemplate(ctx *gin.Context) {
 // UpdateTemplateRequest 更新模板請求
 type UpdateTemplateRequest struct {
 	Name string `json:"name"`
+	// Version 讀取時的模板版本，亦可改用 If-Match header；未提供時不比對
+	Version *uint `json:"version"`
 }
 
 // UpdateTemplate 更新課表模板
@@ -160,32 +164,24 @@ func (c *TimetableTemplateController) UpdateTemplate(ctx *gin.Context) {
 		return
 	}
 
-	template := models.TimetableTemplate{
-		ID:        templateID,
-		CenterID:  centerID,
-		Name:      req.Name,
-		UpdatedAt: time.Now(),
-	}
-
-	if err := c.templateRepo.Update(ctx.Request.Context(), template); err != nil {
-		helper.InternalError("Failed to update template")
+	expectedVersion, ok := helper.MustExpectedVersion(req.Version)
+	if !ok {
 		return
 	}
 
-	c.auditLogRepo.Create(ctx, models.AuditLog{
+	template, errInfo, err := c.templateService.UpdateTemplate(ctx.Request.Context(), &services.UpdateTemplateInput{
 		CenterID:   centerID,
-		ActorType:  "ADMIN",
-		ActorID:    adminID,
-		Action:     "TEMPLATE_UPDATE",
-		TargetType: "TimetableTemplate",
-		TargetID:   templateID,
-		Payload: models.AuditPayload{
-			After: map[string]interface{}{
-				"name": req.Name,
-			},
-		},
+		AdminID:    adminID,
+		TemplateID: templateID,
+		Name:       req.Name,
+		Version:    expectedVersion,
 	})
+	if err != nil {
+		c.respondTemplateError(helper, errInfo, err)
+		return
+	}
 
+	helper.SetVersionETag(template.Version)
 	helper.Success(template)
 }
 
@@ -258,48 +254,36 @@ func (c *TimetableTemplateController) CreateCells(ctx *gin.Context) {
 		return
 	}
 
-	var cells []models.TimetableCell
-	for _, cellReq := range req {
-		cell := models.TimetableCell{
-			TemplateID: templateID,
-			RowNo:      cellReq.RowNo,
-			ColNo:      cellReq.ColNo,
-			StartTime:  cellReq.StartTime,
-			EndTime:    cellReq.EndTime,
-			RoomID:     cellReq.RoomID,
-			TeacherID:  cellReq.TeacherID,
-			CreatedAt:  time.Now(),
-			UpdatedAt:  time.Now(),
-		}
-		cells = append(cells, cell)
+	expectedVersion, ok := helper.MustExpectedVersion(nil)
+	if !ok {
+		return
 	}
 
-	// 批次建立所有格子
-	var createdCells []models.TimetableCell
-	for _, cell := range cells {
-		createdCell, err := c.cellRepo.Create(ctx.Request.Context(), cell)
-		if err != nil {
-			helper.InternalError("Failed to create cell: " + err.Error())
-			return
-		}
-		createdCells = append(createdCells, createdCell)
+	var cells []models.TimetableCell
+	for _, cellReq := range req {
+		cells = append(cells, models.TimetableCell{
+			RowNo:     cellReq.RowNo,
+			ColNo:     cellReq.ColNo,
+			StartTime: cellReq.StartTime,
+			EndTime:   cellReq.EndTime,
+			RoomID:    cellReq.RoomID,
+			TeacherID: cellReq.TeacherID,
+		})
 	}
 
-	c.auditLogRepo.Create(ctx, models.AuditLog{
+	createdCells, template, errInfo, err := c.templateService.CreateCells(ctx.Request.Context(), &services.CreateCellsInput{
 		CenterID:   centerID,
-		ActorType:  "ADMIN",
-		ActorID:    adminID,
-		Action:     "TIMETABLE_CELLS_CREATE",
-		TargetType: "TimetableCell",
-		TargetID:   0,
-		Payload: models.AuditPayload{
-			After: map[string]interface{}{
-				"template_id": templateID,
-				"cells_count": len(createdCells),
-			},
-		},
+		AdminID:    adminID,
+		TemplateID: templateID,
+		Cells:      cells,
+		Version:    expectedVersion,
 	})
+	if err != nil {
+		c.respondTemplateError(helper, errInfo, err)
+		return
+	}
 
+	helper.SetVersionETag(template.Version)
 	helper.Created(createdCells)
 }
 
@@ -330,35 +314,23 @@ func (c *TimetableTemplateController) DeleteCell(ctx *gin.Context) {
 		return
 	}
 
-	// 取得 cell 來確認存在
-	cell, err := c.cellRepo.GetByID(ctx.Request.Context(), cellID)
-	if err != nil {
-		helper.NotFound("Cell not found")
+	expectedVersion, ok := helper.MustExpectedVersion(nil)
+	if !ok {
 		return
 	}
 
-	// 刪除格子
-	if err := c.cellRepo.Delete(ctx.Request.Context(), cellID); err != nil {
-		helper.InternalError("Failed to delete cell: " + err.Error())
+	template, errInfo, err := c.templateService.DeleteCell(ctx.Request.Context(), &services.DeleteCellInput{
+		CenterID: centerID,
+		AdminID:  adminID,
+		CellID:   cellID,
+		Version:  expectedVersion,
+	})
+	if err != nil {
+		c.respondTemplateError(helper, errInfo, err)
 		return
 	}
 
-	c.auditLogRepo.Create(ctx, models.AuditLog{
-		CenterID:   centerID,
-		ActorType:  "ADMIN",
-		ActorID:    adminID,
-		Action:     "TIMETABLE_CELL_DELETE",
-		TargetType: "TimetableCell",
-		TargetID:   cellID,
-		Payload: models.AuditPayload{
-			Before: map[string]interface{}{
-				"template_id": cell.TemplateID,
-				"row_no":      cell.RowNo,
-				"col_no":      cell.ColNo,
-			},
-		},
-	})
-
+	helper.SetVersionETag(template.Version)
 	helper.Success(nil)
 }
 
@@ -611,43 +583,40 @@ func (c *TimetableTemplateController) ReorderCells(ctx *gin.Context) {
 	// 解析請求體為帶 cells 鍵的物件
 	var reqBody struct {
 		Cells []repositories.ReorderCellRequest `json:"cells" binding:"required,dive"`
+		// Version 讀取時的模板版本，亦可改用 If-Match header；未提供時不比對
+		Version *uint `json:"version"`
 	}
 	if !helper.MustBindJSON(&reqBody) {
 		return
 	}
 
-	// 驗證模板是否存在且屬於該中心
-	template, err := c.templateRepo.GetByID(ctx.Request.Context(), templateID)
-	if err != nil {
-		helper.NotFound("Template not found")
+	expectedVersion, ok := helper.MustExpectedVersion(reqBody.Version)
+	if !ok {
 		return
 	}
 
-	if template.CenterID != centerID {
-		helper.Forbidden("Template does not belong to this center")
+	template, errInfo, err := c.templateService.ReorderCells(ctx.Request.Context(), &services.ReorderCellsInput{
+		CenterID:   centerID,
+		AdminID:    adminID,
+		TemplateID: templateID,
+		Cells:      reqBody.Cells,
+		Version:    expectedVersion,
+	})
+	if err != nil {
+		c.respondTemplateError(helper, errInfo, err)
 		return
 	}
 
-	// 批次更新所有 cells 的 sort_order
-	if err := c.cellRepo.BatchUpdateSortOrder(ctx.Request.Context(), reqBody.Cells); err != nil {
-		helper.InternalError("Failed to reorder cells: " + err.Error())
+	helper.SetVersionETag(template.Version)
+	helper.Success(nil)
+}
+
+// respondTemplateError 版本衝突時附上伺服器目前的模板，其餘依錯誤碼回應
+func (c *TimetableTemplateController) respondTemplateError(helper *ContextHelper, errInfo *errInfos.Res, err error) {
+	var conflictErr *services.VersionConflictError
+	if errors.As(err, &conflictErr) {
+		helper.ConflictWithData(errInfo, conflictErr.Conflict)
 		return
 	}
-
-	c.auditLogRepo.Create(ctx, models.AuditLog{
-		CenterID:   centerID,
-		ActorType:  "ADMIN",
-		ActorID:    adminID,
-		Action:     "TIMETABLE_CELLS_REORDER",
-		TargetType: "TimetableCell",
-		TargetID:   templateID,
-		Payload: models.AuditPayload{
-			After: map[string]interface{}{
-				"template_id": templateID,
-				"cells_count": len(reqBody.Cells),
-			},
-		},
-	})
-
-	helper.Success(nil)
+	helper.ErrorWithInfo(errInfo)
 }
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// 廣播狀態
+const (
+	BroadcastStatusScheduled = "SCHEDULED" // 等待排程發送，可取消
+	BroadcastStatusSending   = "SENDING"
+	BroadcastStatusSent      = "SENT"
+	BroadcastStatusCancelled = "CANCELLED"
+	BroadcastStatusFailed    = "FAILED" // 無法取得收件者等整體性失敗
+)
+
+// 廣播收件者的發送結果
+const (
+	BroadcastRecipientPending  = "PENDING"
+	BroadcastRecipientSent     = "SENT"
+	BroadcastRecipientFailed   = "FAILED"
+	BroadcastRecipientBlocked  = "BLOCKED"  // 已封鎖官方帳號或尚未加入好友
+	BroadcastRecipientSkipped  = "SKIPPED"  // 未綁定 LINE 或已關閉廣播通知
+	BroadcastRecipientDeferred = "DEFERRED" // 依通知偏好延後（勿擾時段或每日彙整）
+)
+
+// BroadcastSegment 廣播對象條件，各條件同時成立才會收到；全部為空表示中心所有老師
+// 佔位老師一律排除
+type BroadcastSegment struct {
+	TeacherIDs  []uint   `json:"teacher_ids,omitempty"`
+	OfferingIDs []uint   `json:"offering_ids,omitempty"` // 教授指定班別的老師
+	SessionDate string   `json:"session_date,omitempty"` // 當天有課的老師（YYYY-MM-DD）
+	Roles       []string `json:"roles,omitempty"`        // 中心角色：TEACHER、SUBSTITUTE
+}
+
+func (s *BroadcastSegment) Scan(value interface{}) error {
+	if value == nil {
+		*s = BroadcastSegment{}
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("failed to unmarshal BroadcastSegment value")
+	}
+	return json.Unmarshal(bytes, s)
+}
+
+func (s BroadcastSegment) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+// Broadcast 中心廣播，立即發送或排程發送
+type Broadcast struct {
+	ID            uint             `gorm:"primaryKey" json:"id"`
+	CenterID      uint             `gorm:"type:bigint unsigned;not null;index" json:"center_id"`
+	CreatedBy     uint             `gorm:"type:bigint unsigned;not null" json:"created_by"`
+	MessageType   string           `gorm:"type:varchar(20);not null" json:"message_type"` // GENERAL、URGENT
+	Title         string           `gorm:"type:varchar(100);not null" json:"title"`
+	Message       string           `gorm:"type:text;not null" json:"message"`
+	Warning       string           `gorm:"type:varchar(255)" json:"warning,omitempty"`
+	ActionLabel   string           `gorm:"type:varchar(50)" json:"action_label,omitempty"`
+	ActionURL     string           `gorm:"type:varchar(500)" json:"action_url,omitempty"`
+	Segment       BroadcastSegment `gorm:"type:json" json:"segment"`
+	Status        string           `gorm:"type:varchar(20);not null;index:idx_broadcast_due" json:"status"`
+	ScheduledAt   time.Time        `gorm:"type:datetime;not null;index:idx_broadcast_due" json:"scheduled_at"`
+	SentAt        *time.Time       `gorm:"type:datetime" json:"sent_at"`
+	CancelledAt   *time.Time       `gorm:"type:datetime" json:"cancelled_at"`
+	TotalCount    int              `gorm:"type:int;not null;default:0" json:"total_count"`
+	SentCount     int              `gorm:"type:int;not null;default:0" json:"sent_count"`
+	FailedCount   int              `gorm:"type:int;not null;default:0" json:"failed_count"`
+	BlockedCount  int              `gorm:"type:int;not null;default:0" json:"blocked_count"`
+	SkippedCount  int              `gorm:"type:int;not null;default:0" json:"skipped_count"`
+	DeferredCount int              `gorm:"type:int;not null;default:0" json:"deferred_count"`
+	EmailCount    int              `gorm:"type:int;not null;default:0" json:"email_count"`
+	LastError     string           `gorm:"type:text" json:"last_error,omitempty"`
+	CreatedAt     time.Time        `gorm:"type:datetime;not null" json:"created_at"`
+	UpdatedAt     time.Time        `gorm:"type:datetime;not null" json:"updated_at"`
+}
+
+func (Broadcast) TableName() string {
+	return "broadcasts"
+}
+
+// BroadcastRecipient 廣播的單一收件者與 LINE 發送結果
+type BroadcastRecipient struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	BroadcastID uint       `gorm:"type:bigint unsigned;not null;uniqueIndex:idx_broadcast_recipient" json:"broadcast_id"`
+	TeacherID   uint       `gorm:"type:bigint unsigned;not null;uniqueIndex:idx_broadcast_recipient" json:"teacher_id"`
+	TeacherName string     `gorm:"type:varchar(100)" json:"teacher_name"`
+	Status      string     `gorm:"type:varchar(20);not null;index" json:"status"`
+	StatusCode  int        `gorm:"type:int;not null;default:0" json:"status_code"` // LINE API 的 HTTP 狀態碼，0 表示未呼叫或未取得回應
+	ErrorMsg    string     `gorm:"type:text" json:"error_msg,omitempty"`
+	SentAt      *time.Time `gorm:"type:datetime" json:"sent_at"`
+	CreatedAt   time.Time  `gorm:"type:datetime;not null" json:"created_at"`
+	UpdatedAt   time.Time  `gorm:"type:datetime;not null" json:"updated_at"`
+}
+
+func (BroadcastRecipient) TableName() string {
+	return "broadcast_recipients"
+}
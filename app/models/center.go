@@ -5,23 +5,51 @@ import (
 	"encoding/json"
 	"errors"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 type Center struct {
-	ID        uint           `gorm:"primaryKey" json:"id"`
-	Name      string         `gorm:"type:varchar(255);not null" json:"name"`
-	PlanLevel string         `gorm:"type:varchar(20);default:'FREE';not null" json:"plan_level"`
-	Settings  CenterSettings `gorm:"type:json;not null" json:"settings"`
-	CreatedAt time.Time      `gorm:"type:datetime;not null;autoCreateTime" json:"created_at"`
+	ID              uint           `gorm:"primaryKey" json:"id"`
+	Name            string         `gorm:"type:varchar(255);not null" json:"name"`
+	PlanLevel       string         `gorm:"type:varchar(20);default:'FREE';not null" json:"plan_level"`
+	Settings        CenterSettings `gorm:"type:json;not null" json:"settings"`
+	SettingsVersion uint           `gorm:"type:int unsigned;not null;default:1" json:"settings_version"` // 中心設定的樂觀鎖版本，每次更新設定遞增
+	CreatedAt       time.Time      `gorm:"type:datetime;not null;autoCreateTime" json:"created_at"`
 }
 
 type CenterSettings struct {
-	AllowPublicRegister      bool   `json:"allow_public_register"`
-	DefaultLanguage         string `json:"default_language"`
-	ExceptionLeadDays       int    `json:"exception_lead_days"`
-	DefaultCourseDuration   int    `json:"default_course_duration"`
-	OperatingStartTime      string `json:"operating_start_time"`
-	OperatingEndTime        string `json:"operating_end_time"`
+	AllowPublicRegister   bool   `json:"allow_public_register"`
+	DefaultLanguage       string `json:"default_language"`
+	ExceptionLeadDays     int    `json:"exception_lead_days"`
+	DefaultCourseDuration int    `json:"default_course_duration"`
+	OperatingStartTime    string `json:"operating_start_time"`
+	OperatingEndTime      string `json:"operating_end_time"`
+	// FollowMakeupWorkdays 補班日是否比照指定星期的課表上課
+	FollowMakeupWorkdays bool `json:"follow_makeup_workdays"`
+	// WeeklyOperatingHours 每週各日營業時間，未設定的星期沿用 OperatingStartTime/OperatingEndTime
+	WeeklyOperatingHours []WeekdayOperatingHours `json:"weekly_operating_hours"`
+	// ReminderOffsets 課前提醒的提前分鐘數，未設定時為前一天（1440），空陣列表示不發送
+	ReminderOffsets []int `json:"reminder_offsets"`
+}
+
+// WeekdayOperatingHours 單一星期的營業時間（Weekday：1=週一 … 7=週日）
+type WeekdayOperatingHours struct {
+	Weekday   int    `json:"weekday"`
+	OpenTime  string `json:"open_time"`
+	CloseTime string `json:"close_time"`
+	Closed    bool   `json:"closed"`
+}
+
+// DefaultReminderOffsets 中心未設定時的課前提醒：前一天
+var DefaultReminderOffsets = []int{24 * 60}
+
+// EffectiveReminderOffsets 中心的課前提醒提前分鐘數，未設定時使用 DefaultReminderOffsets
+func (cs CenterSettings) EffectiveReminderOffsets() []int {
+	if cs.ReminderOffsets == nil {
+		return DefaultReminderOffsets
+	}
+	return cs.ReminderOffsets
 }
 
 func (cs *CenterSettings) Scan(value interface{}) error {
@@ -39,3 +67,11 @@ func (cs CenterSettings) Value() (driver.Value, error) {
 func (Center) TableName() string {
 	return "centers"
 }
+
+// BeforeCreate 新建資料的版本號從 1 開始，與資料庫預設值一致
+func (c *Center) BeforeCreate(tx *gorm.DB) error {
+	if c.SettingsVersion == 0 {
+		c.SettingsVersion = 1
+	}
+	return nil
+}
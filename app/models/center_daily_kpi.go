@@ -0,0 +1,63 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// KPICounter 依分類計數（停課原因、例外單類型、老師 ID 對應的授課分鐘等）
+type KPICounter map[string]int
+
+func (c *KPICounter) Scan(value interface{}) error {
+	if value == nil {
+		*c = KPICounter{}
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("failed to unmarshal KPICounter value")
+	}
+	return json.Unmarshal(bytes, c)
+}
+
+func (c KPICounter) Value() (driver.Value, error) {
+	if c == nil {
+		return json.Marshal(map[string]int{})
+	}
+	return json.Marshal(map[string]int(c))
+}
+
+// Add 累加另一組計數
+func (c KPICounter) Add(other KPICounter) {
+	for k, v := range other {
+		c[k] += v
+	}
+}
+
+// CenterDailyKPI 中心每日 KPI 預先彙總（由夜間排程寫入）
+type CenterDailyKPI struct {
+	ID                  uint       `gorm:"primaryKey" json:"id"`
+	CenterID            uint       `gorm:"type:bigint unsigned;not null;uniqueIndex:idx_center_kpi_date" json:"center_id"`
+	Date                time.Time  `gorm:"type:date;not null;uniqueIndex:idx_center_kpi_date" json:"date"`
+	PlannedSessions     int        `gorm:"type:int;not null;default:0" json:"planned_sessions"`     // 已開課規則展開的場次（含被取消）
+	DeliveredSessions   int        `gorm:"type:int;not null;default:0" json:"delivered_sessions"`   // 實際上課場次（含補課）
+	CancelledSessions   int        `gorm:"type:int;not null;default:0" json:"cancelled_sessions"`   // 被取消的場次
+	RescheduledSessions int        `gorm:"type:int;not null;default:0" json:"rescheduled_sessions"` // 已核准改期的場次
+	SubstituteSessions  int        `gorm:"type:int;not null;default:0" json:"substitute_sessions"`  // 由代課老師上課的場次
+	MakeupSessions      int        `gorm:"type:int;not null;default:0" json:"makeup_sessions"`      // 當天的補課
+	CancelReasons       KPICounter `gorm:"type:json" json:"cancel_reasons"`                         // HOLIDAY, SUSPENDED, EXCEPTION
+	ExceptionTypes      KPICounter `gorm:"type:json" json:"exception_types"`                        // 當天場次已核准的例外單類型
+	ReviewedExceptions  int        `gorm:"type:int;not null;default:0" json:"reviewed_exceptions"`  // 當天完成審核的例外單
+	ApprovalLeadMinutes int64      `gorm:"type:bigint;not null;default:0" json:"approval_lead_minutes"`
+	TeacherMinutes      KPICounter `gorm:"type:json" json:"teacher_minutes"` // 老師 ID -> 授課分鐘
+	NewTeachers         int        `gorm:"type:int;not null;default:0" json:"new_teachers"`
+	AggregatedAt        time.Time  `gorm:"type:datetime;not null" json:"aggregated_at"`
+	CreatedAt           time.Time  `gorm:"type:datetime;not null" json:"created_at"`
+	UpdatedAt           time.Time  `gorm:"type:datetime;not null" json:"updated_at"`
+}
+
+func (CenterDailyKPI) TableName() string {
+	return "center_daily_kpis"
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CenterMakeupWorkday 補行上班日（補班）
+// 當天比照 FollowsWeekday 的課表上課，需中心開啟 FollowMakeupWorkdays 設定才會套用到排課展開
+type CenterMakeupWorkday struct {
+	ID             uint           `gorm:"primaryKey" json:"id"`
+	CenterID       uint           `gorm:"type:bigint unsigned;not null;index:idx_center_makeup_date" json:"center_id"`
+	Date           time.Time      `gorm:"type:date;not null;index:idx_center_makeup_date" json:"date"`
+	Name           string         `gorm:"type:varchar(255);not null" json:"name"`
+	FollowsWeekday int            `gorm:"type:tinyint;not null" json:"follows_weekday"`
+	CreatedAt      time.Time      `gorm:"type:datetime;not null" json:"created_at"`
+	UpdatedAt      time.Time      `gorm:"type:datetime;not null" json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (CenterMakeupWorkday) TableName() string {
+	return "center_makeup_workdays"
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CenterOperatingHoursOverride 特定日期區間的營業時間調整（縮短營業、考試週、臨時公休）
+// 區間內優先於每週營業時間，多筆重疊時以最新建立者為準
+type CenterOperatingHoursOverride struct {
+	ID        uint           `gorm:"primaryKey" json:"id"`
+	CenterID  uint           `gorm:"type:bigint unsigned;not null;index:idx_center_override_range" json:"center_id"`
+	StartDate time.Time      `gorm:"type:date;not null;index:idx_center_override_range" json:"start_date"`
+	EndDate   time.Time      `gorm:"type:date;not null" json:"end_date"`
+	OpenTime  string         `gorm:"type:varchar(5)" json:"open_time"`
+	CloseTime string         `gorm:"type:varchar(5)" json:"close_time"`
+	Closed    bool           `gorm:"type:boolean;default:false;not null" json:"closed"`
+	Reason    string         `gorm:"type:varchar(255)" json:"reason"`
+	CreatedAt time.Time      `gorm:"type:datetime;not null" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"type:datetime;not null" json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (CenterOperatingHoursOverride) TableName() string {
+	return "center_operating_hours_overrides"
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+)
+
+// ClassReminder 課前提醒發送紀錄
+// 同一場次、提前時間與老師只會寫入一筆（唯一索引），多台排程同時執行或重啟後重跑都不會重複發送
+type ClassReminder struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	CenterID       uint      `gorm:"type:bigint unsigned;not null;index" json:"center_id"`
+	RuleID         uint      `gorm:"type:bigint unsigned;not null;uniqueIndex:idx_class_reminder_once" json:"rule_id"`
+	SessionStartAt time.Time `gorm:"type:datetime;not null;uniqueIndex:idx_class_reminder_once" json:"session_start_at"`
+	OffsetMinutes  int       `gorm:"type:int;not null;uniqueIndex:idx_class_reminder_once" json:"offset_minutes"` // 課前幾分鐘提醒
+	TeacherID      uint      `gorm:"type:bigint unsigned;not null;uniqueIndex:idx_class_reminder_once" json:"teacher_id"`
+	CreatedAt      time.Time `gorm:"type:datetime;not null" json:"created_at"`
+}
+
+func (ClassReminder) TableName() string {
+	return "class_reminders"
+}
@@ -15,6 +15,8 @@ type Course struct {
 	ColorHex         string         `gorm:"type:varchar(7);not null" json:"color_hex"`
 	RoomBufferMin    int            `gorm:"type:int;not null;default:0" json:"room_buffer_min"`
 	TeacherBufferMin int            `gorm:"type:int;not null;default:0" json:"teacher_buffer_min"`
+	RequiredFeatures RoomFeatures   `gorm:"type:json" json:"required_features"` // 上課所需的教室設備
+	MinCapacity      int            `gorm:"type:int;not null;default:0" json:"min_capacity"`
 	IsActive         bool           `gorm:"type:boolean;default:true;not null" json:"is_active"`
 	CreatedAt        time.Time      `gorm:"type:datetime;not null" json:"created_at"`
 	UpdatedAt        time.Time      `gorm:"type:datetime;not null" json:"updated_at"`
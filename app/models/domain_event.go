@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+)
+
+// DomainEvent 排課領域事件（transactional outbox）
+// 與業務資料在同一交易寫入，由 relay 轉送至 RabbitMQ 後標記為已發佈
+type DomainEvent struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	EventID       string     `gorm:"type:varchar(36);not null;uniqueIndex" json:"event_id"` // 訊息 ID，供消費端去重
+	CenterID      uint       `gorm:"type:bigint unsigned;not null;index" json:"center_id"`
+	EventType     string     `gorm:"type:varchar(40);not null;index" json:"event_type"`
+	AggregateType string     `gorm:"type:varchar(40);not null" json:"aggregate_type"`
+	AggregateID   uint       `gorm:"type:bigint unsigned;not null" json:"aggregate_id"`
+	ActorType     string     `gorm:"type:varchar(20)" json:"actor_type"`
+	ActorID       uint       `gorm:"type:bigint unsigned" json:"actor_id"`
+	Payload       string     `gorm:"type:json;not null" json:"payload"`
+	Status        string     `gorm:"type:varchar(16);not null;default:'PENDING';index:idx_domain_event_relay" json:"status"`
+	Attempts      int        `gorm:"type:int;not null;default:0" json:"attempts"`
+	LastError     string     `gorm:"type:text" json:"last_error,omitempty"`
+	AvailableAt   time.Time  `gorm:"type:datetime;not null;index:idx_domain_event_relay" json:"available_at"` // 下次可轉送時間（重試退避）
+	PublishedAt   *time.Time `gorm:"type:datetime" json:"published_at"`
+	OccurredAt    time.Time  `gorm:"type:datetime;not null" json:"occurred_at"`
+	CreatedAt     time.Time  `gorm:"type:datetime;not null" json:"created_at"`
+	UpdatedAt     time.Time  `gorm:"type:datetime;not null" json:"updated_at"`
+}
+
+func (DomainEvent) TableName() string {
+	return "domain_events"
+}
+
+// DomainEvent 類型
+const (
+	DomainEventRuleChanged        = "RuleChanged"
+	DomainEventExceptionSubmitted = "ExceptionSubmitted"
+	DomainEventExceptionReviewed  = "ExceptionReviewed"
+	DomainEventTeacherMerged      = "TeacherMerged"
+	DomainEventTeacherJoined      = "TeacherJoined"
+	DomainEventTeacherLeft        = "TeacherLeft"
+	DomainEventHolidayCreated     = "HolidayCreated"
+)
+
+// DomainEvent 轉送狀態
+const (
+	DomainEventStatusPending   = "PENDING"
+	DomainEventStatusPublished = "PUBLISHED"
+	DomainEventStatusFailed    = "FAILED" // 超過轉送重試次數，需人工處理
+)
@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// LINE 群組可接收的通知類型
+const (
+	LineGroupNotifyExceptionSubmit  = "exception_submit"  // 老師提交例外申請
+	LineGroupNotifyDailySummary     = "daily_summary"     // 每日課表摘要
+	LineGroupNotifyEmergencyClosure = "emergency_closure" // 近日的強制停課假日
+)
+
+// LineGroup 連結至中心的 LINE 群組（中心工作人員群組）
+// 機器人被加入群組後，由管理員在群組內貼上後台產生的驗證碼完成連結
+type LineGroup struct {
+	ID                     uint      `gorm:"primaryKey" json:"id"`
+	CenterID               uint      `gorm:"type:bigint unsigned;not null;index" json:"center_id"`
+	GroupID                string    `gorm:"type:varchar(64);not null;uniqueIndex" json:"group_id"`
+	Name                   string    `gorm:"type:varchar(255)" json:"name"`
+	NotifyExceptionSubmit  bool      `gorm:"type:tinyint(1);not null;default:1" json:"notify_exception_submit"`
+	NotifyDailySummary     bool      `gorm:"type:tinyint(1);not null;default:1" json:"notify_daily_summary"`
+	NotifyEmergencyClosure bool      `gorm:"type:tinyint(1);not null;default:1" json:"notify_emergency_closure"`
+	DailySummaryTime       string    `gorm:"type:varchar(5);not null;default:'07:30'" json:"daily_summary_time"` // HH:MM，台灣時間
+	LastSummaryDate        string    `gorm:"type:varchar(10)" json:"last_summary_date,omitempty"`                // 最近一次發送摘要的日期，避免重複發送
+	LinkedByAdminID        uint      `gorm:"type:bigint unsigned;not null" json:"linked_by_admin_id"`
+	LinkedAt               time.Time `gorm:"type:datetime;not null" json:"linked_at"`
+	CreatedAt              time.Time `gorm:"type:datetime;not null" json:"created_at"`
+	UpdatedAt              time.Time `gorm:"type:datetime;not null" json:"updated_at"`
+}
+
+func (LineGroup) TableName() string {
+	return "line_groups"
+}
+
+// Notifies 群組是否接收指定類型的通知
+func (g LineGroup) Notifies(notifyType string) bool {
+	switch notifyType {
+	case LineGroupNotifyExceptionSubmit:
+		return g.NotifyExceptionSubmit
+	case LineGroupNotifyDailySummary:
+		return g.NotifyDailySummary
+	case LineGroupNotifyEmergencyClosure:
+		return g.NotifyEmergencyClosure
+	}
+	return false
+}
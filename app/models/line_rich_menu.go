@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// LineRichMenu 已上傳至 LINE 的 Rich Menu，依設定 key 記錄 LINE 回傳的 richMenuId
+// ConfigHash 為設定與圖片的雜湊，未變動時同步不會重新上傳
+// ReplacedRichMenuID 為被替換的舊選單，重新連結使用者成功後才刪除；非空時下次同步會再次重新連結
+type LineRichMenu struct {
+	ID                 uint      `gorm:"primaryKey" json:"id"`
+	MenuKey            string    `gorm:"type:varchar(50);not null;uniqueIndex" json:"menu_key"`
+	RichMenuID         string    `gorm:"type:varchar(100);not null" json:"rich_menu_id"`
+	ReplacedRichMenuID string    `gorm:"type:varchar(100);not null;default:''" json:"replaced_rich_menu_id"`
+	ConfigHash         string    `gorm:"type:varchar(64);not null" json:"config_hash"`
+	IsDefault          bool      `gorm:"type:tinyint(1);not null;default:0" json:"is_default"`
+	CreatedAt          time.Time `gorm:"type:datetime;not null" json:"created_at"`
+	UpdatedAt          time.Time `gorm:"type:datetime;not null" json:"updated_at"`
+}
+
+func (LineRichMenu) TableName() string {
+	return "line_rich_menus"
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// LINE webhook 事件處理狀態
+const (
+	LineWebhookEventPending   = "pending"
+	LineWebhookEventProcessed = "processed"
+	LineWebhookEventFailed    = "failed" // 處理失敗，可由管理員重新處理
+)
+
+// LineWebhookEvent LINE webhook 事件紀錄；以 webhookEventId 去除 LINE 重送的重複事件
+type LineWebhookEvent struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	WebhookEventID string     `gorm:"type:varchar(64);not null;uniqueIndex" json:"webhook_event_id"`
+	EventType      string     `gorm:"type:varchar(32);not null" json:"event_type"`
+	SourceType     string     `gorm:"type:varchar(16);not null" json:"source_type"` // user, group
+	SourceUserID   string     `gorm:"type:varchar(64);not null;default:'';index" json:"source_user_id"`
+	SourceGroupID  string     `gorm:"type:varchar(64);not null;default:'';index" json:"source_group_id"`
+	IsRedelivery   bool       `gorm:"type:boolean;not null;default:false" json:"is_redelivery"` // LINE 標示為重送的事件
+	Payload        string     `gorm:"type:json;not null" json:"-"`                              // LINE 送來的原始事件 JSON
+	Status         string     `gorm:"type:varchar(16);not null;default:'pending';index:idx_line_webhook_event_due" json:"status"`
+	Attempts       int        `gorm:"type:int;not null;default:0" json:"attempts"`
+	LastError      string     `gorm:"type:text" json:"last_error,omitempty"`
+	DurationMs     int        `gorm:"type:int;not null;default:0" json:"duration_ms"`
+	NextAttemptAt  time.Time  `gorm:"type:datetime;not null;index:idx_line_webhook_event_due" json:"next_attempt_at"`
+	OccurredAt     time.Time  `gorm:"type:datetime;not null" json:"occurred_at"`
+	ProcessedAt    *time.Time `gorm:"type:datetime" json:"processed_at"`
+	CreatedAt      time.Time  `gorm:"type:datetime;not null;index" json:"created_at"`
+	UpdatedAt      time.Time  `gorm:"type:datetime;not null" json:"updated_at"`
+}
+
+func (LineWebhookEvent) TableName() string {
+	return "line_webhook_events"
+}
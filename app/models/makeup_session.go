@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	MakeupStatusScheduled = "SCHEDULED" // 已安排
+	MakeupStatusCancelled = "CANCELLED" // 已取消
+)
+
+// 被取消場次的原因
+const (
+	SessionCancelReasonHoliday   = "HOLIDAY"   // 假日停課
+	SessionCancelReasonSuspended = "SUSPENDED" // 規則暫停日
+	SessionCancelReasonException = "EXCEPTION" // 停課例外單
+)
+
+// MakeupSession 補課場次，連結到被取消的原場次（規則 + 原日期）
+type MakeupSession struct {
+	ID             uint           `gorm:"primaryKey" json:"id"`
+	CenterID       uint           `gorm:"type:bigint unsigned;not null;index" json:"center_id"`
+	OfferingID     uint           `gorm:"type:bigint unsigned;not null;index:idx_offering_term" json:"offering_id"`
+	TermID         uint           `gorm:"type:bigint unsigned;not null;index:idx_offering_term" json:"term_id"`
+	OriginalRuleID uint           `gorm:"type:bigint unsigned;not null;index:idx_original_session" json:"original_rule_id"`
+	OriginalDate   time.Time      `gorm:"type:date;not null;index:idx_original_session" json:"original_date"`
+	CancelReason   string         `gorm:"type:varchar(20);not null" json:"cancel_reason"` // HOLIDAY, SUSPENDED, EXCEPTION
+	ExceptionID    *uint          `gorm:"type:bigint unsigned" json:"exception_id"`
+	MakeupDate     time.Time      `gorm:"type:date;not null;index" json:"makeup_date"`
+	StartTime      string         `gorm:"type:varchar(5);not null" json:"start_time"`
+	EndTime        string         `gorm:"type:varchar(5);not null" json:"end_time"`
+	TeacherID      *uint          `gorm:"type:bigint unsigned;index" json:"teacher_id"`
+	RoomID         uint           `gorm:"type:bigint unsigned;not null;index" json:"room_id"`
+	Status         string         `gorm:"type:varchar(20);default:'SCHEDULED';not null" json:"status"`
+	Note           string         `gorm:"type:text" json:"note"`
+	CreatedBy      uint           `gorm:"type:bigint unsigned;not null" json:"created_by"`
+	CreatedAt      time.Time      `gorm:"type:datetime;not null" json:"created_at"`
+	UpdatedAt      time.Time      `gorm:"type:datetime;not null" json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// 關聯
+	Offering Offering `gorm:"foreignKey:OfferingID" json:"offering,omitempty"`
+}
+
+func (MakeupSession) TableName() string {
+	return "makeup_sessions"
+}
@@ -0,0 +1,121 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// 通知偏好的事件類別
+const (
+	NotificationEventReminder        = "REMINDER"         // 上課提醒
+	NotificationEventExceptionSubmit = "EXCEPTION_SUBMIT" // 例外申請（管理員）
+	NotificationEventExceptionResult = "EXCEPTION_RESULT" // 例外審核結果（老師）
+	NotificationEventBroadcast       = "BROADCAST"        // 中心廣播
+	NotificationEventInvitation      = "INVITATION"       // 人才庫 / 中心邀請
+)
+
+// 通知管道
+const (
+	NotificationChannelLine  = "LINE"
+	NotificationChannelInApp = "IN_APP"
+	NotificationChannelEmail = "EMAIL"
+)
+
+// NotificationEvents 可設定偏好的事件類別
+var NotificationEvents = []string{
+	NotificationEventReminder,
+	NotificationEventExceptionSubmit,
+	NotificationEventExceptionResult,
+	NotificationEventBroadcast,
+	NotificationEventInvitation,
+}
+
+// NotificationChannels 支援的通知管道
+var NotificationChannels = []string{
+	NotificationChannelLine,
+	NotificationChannelInApp,
+	NotificationChannelEmail,
+}
+
+// NotificationChannelMap 事件類別 -> 啟用的管道
+type NotificationChannelMap map[string][]string
+
+func (m *NotificationChannelMap) Scan(value interface{}) error {
+	if value == nil {
+		*m = NotificationChannelMap{}
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("failed to unmarshal NotificationChannelMap value")
+	}
+	return json.Unmarshal(bytes, m)
+}
+
+func (m NotificationChannelMap) Value() (driver.Value, error) {
+	if m == nil {
+		return json.Marshal(map[string][]string{})
+	}
+	return json.Marshal(map[string][]string(m))
+}
+
+// ReminderOffsets 課前提醒的提前分鐘數（例如 1440 為前一天、60 為一小時前）
+// 老師偏好為 NULL 時沿用中心設定，空陣列表示不接收課前提醒
+type ReminderOffsets []int
+
+func (o *ReminderOffsets) Scan(value interface{}) error {
+	if value == nil {
+		*o = nil
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("failed to unmarshal ReminderOffsets value")
+	}
+	return json.Unmarshal(bytes, o)
+}
+
+func (o ReminderOffsets) Value() (driver.Value, error) {
+	if o == nil {
+		return nil, nil
+	}
+	return json.Marshal([]int(o))
+}
+
+// NotificationPreference 使用者通知偏好（老師與管理員共用，依 user_type 區分）
+// 未建立紀錄的使用者視為預設值：所有事件皆透過 LINE 與站內通知、無勿擾時段、不彙整
+type NotificationPreference struct {
+	ID                uint                   `gorm:"primaryKey" json:"id"`
+	UserType          string                 `gorm:"type:varchar(20);not null;uniqueIndex:idx_notification_pref_user" json:"user_type"`
+	UserID            uint                   `gorm:"type:bigint unsigned;not null;uniqueIndex:idx_notification_pref_user" json:"user_id"`
+	Channels          NotificationChannelMap `gorm:"type:json" json:"channels"`
+	QuietHoursEnabled bool                   `gorm:"type:boolean;not null;default:false" json:"quiet_hours_enabled"`
+	QuietHoursStart   string                 `gorm:"type:varchar(5);not null;default:'22:00'" json:"quiet_hours_start"` // HH:MM
+	QuietHoursEnd     string                 `gorm:"type:varchar(5);not null;default:'08:00'" json:"quiet_hours_end"`   // HH:MM，早於開始時間表示跨日
+	Timezone          string                 `gorm:"type:varchar(64);not null;default:'Asia/Taipei'" json:"timezone"`
+	DigestEnabled     bool                   `gorm:"type:boolean;not null;default:false" json:"digest_enabled"`
+	DigestTime        string                 `gorm:"type:varchar(5);not null;default:'08:00'" json:"digest_time"` // 每日彙整發送時間 HH:MM
+	ReminderOffsets   ReminderOffsets        `gorm:"type:json" json:"reminder_offsets"`                           // 老師自訂課前提醒，NULL 沿用中心設定
+	CreatedAt         time.Time              `gorm:"type:datetime;not null" json:"created_at"`
+	UpdatedAt         time.Time              `gorm:"type:datetime;not null" json:"updated_at"`
+}
+
+func (NotificationPreference) TableName() string {
+	return "notification_preferences"
+}
+
+// ChannelEnabled 事件是否啟用指定管道，未設定的事件沿用預設（LINE 與站內通知）
+func (p NotificationPreference) ChannelEnabled(event, channel string) bool {
+	channels, ok := p.Channels[event]
+	if !ok {
+		return channel == NotificationChannelLine || channel == NotificationChannelInApp
+	}
+	for _, c := range channels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
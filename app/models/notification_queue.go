@@ -6,9 +6,12 @@ import (
 
 type NotificationQueue struct {
 	ID          uint           `gorm:"primaryKey" json:"id"`
+	CenterID    uint           `gorm:"type:bigint unsigned;not null;default:0;index" json:"center_id"`  // 來源中心，用於死信佇列管理
 	Type        string         `gorm:"type:varchar(32);not null;index" json:"type"`                     // exception_submit, exception_result, welcome, etc.
 	RecipientID uint           `gorm:"not null;index" json:"recipient_id"`                              // admin_id 或 teacher_id
 	RecipientType string       `gorm:"type:varchar(20);not null" json:"recipient_type"`                 // ADMIN, TEACHER
+	Channel     string         `gorm:"type:varchar(16);not null;default:'LINE';index" json:"channel"`   // LINE, EMAIL
+	RecipientAddress string    `gorm:"type:varchar(255);index" json:"recipient_address"`                // Email 收件地址
 	Payload     string         `gorm:"type:text;not null" json:"-"`                                    // JSON 訊息內容
 	Status      string         `gorm:"type:varchar(16);default:'pending';not null;index" json:"status"` // pending, sent, failed
 	RetryCount  int            `gorm:"default:0" json:"retry_count"`
@@ -16,6 +19,9 @@ type NotificationQueue struct {
 	ScheduledAt time.Time      `gorm:"type:datetime;not null" json:"scheduled_at"`                     // 預定發送時間
 	SentAt      *time.Time     `json:"sent_at"`
 	FailedAt    *time.Time     `json:"failed_at"`
+	BouncedAt   *time.Time     `json:"bounced_at"`                                                        // 收件伺服器永久拒收（SMTP 5xx）
+	DeadAt      *time.Time     `json:"dead_at"`                                                           // 超過重試次數移入死信佇列
+	ReplayStatus string        `gorm:"type:varchar(16)" json:"-"`                                        // 重送時恢復的狀態（pending 或 deferred）
 	CreatedAt   time.Time      `gorm:"type:datetime;not null" json:"created_at"`
 	UpdatedAt   time.Time      `gorm:"type:datetime;not null" json:"updated_at"`
 }
@@ -31,6 +37,22 @@ const (
 	NotificationStatusFailed  = "failed"
 )
 
+// 依通知偏好暫緩發送的狀態（存於資料表，由排程到期後發送）
+const (
+	NotificationStatusDeferred = "deferred" // 勿擾時段延後，scheduled_at 為勿擾結束時間
+	NotificationStatusDigest   = "digest"   // 等待每日彙整，scheduled_at 為彙整發送時間
+)
+
+// NotificationStatusBounced 郵件被收件伺服器永久拒收，同一地址暫停發送
+const NotificationStatusBounced = "bounced"
+
+// 死信佇列狀態
+const (
+	NotificationStatusDead      = "dead"      // 超過重試次數，等待管理員重送或捨棄
+	NotificationStatusDiscarded = "discarded" // 管理員已捨棄
+	NotificationStatusSkipped   = "skipped"   // 收件者未綁定或已關閉通知，不再重試
+)
+
 // NotificationType constants
 const (
 	NotificationTypeExceptionSubmit = "exception_submit" // 老師提交例外申請
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// 可由中心自訂的通知範本類型
+const (
+	NotificationTemplateExceptionSubmit    = "EXCEPTION_SUBMIT"    // 例外申請（發給管理員）
+	NotificationTemplateExceptionApproved  = "EXCEPTION_APPROVED"  // 例外核准（發給老師）
+	NotificationTemplateExceptionRejected  = "EXCEPTION_REJECTED"  // 例外拒絕（發給老師）
+	NotificationTemplateInvitationAccepted = "INVITATION_ACCEPTED" // 新成員加入（發給管理員）
+	NotificationTemplateBroadcast          = "BROADCAST"           // 中心廣播
+	NotificationTemplateWelcomeAdmin       = "WELCOME_ADMIN"       // 管理員歡迎訊息
+)
+
+// 通知語系
+const (
+	LocaleZhTW = "zh-TW"
+	LocaleEn   = "en"
+)
+
+// NotificationLocales 支援的通知語系
+var NotificationLocales = []string{LocaleZhTW, LocaleEn}
+
+// NotificationTemplateValues 範本欄位（文字或顏色），key 為欄位名稱
+type NotificationTemplateValues map[string]string
+
+func (v *NotificationTemplateValues) Scan(value interface{}) error {
+	if value == nil {
+		*v = NotificationTemplateValues{}
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("failed to unmarshal NotificationTemplateValues value")
+	}
+	return json.Unmarshal(bytes, v)
+}
+
+func (v NotificationTemplateValues) Value() (driver.Value, error) {
+	if v == nil {
+		return json.Marshal(map[string]string{})
+	}
+	return json.Marshal(map[string]string(v))
+}
+
+// NotificationTemplate 中心自訂的通知範本，僅需填寫要覆寫的欄位，其餘使用內建預設
+type NotificationTemplate struct {
+	ID        uint                       `gorm:"primaryKey" json:"id"`
+	CenterID  uint                       `gorm:"type:bigint unsigned;not null;uniqueIndex:idx_notification_template" json:"center_id"`
+	Type      string                     `gorm:"type:varchar(40);not null;uniqueIndex:idx_notification_template" json:"type"`
+	Channel   string                     `gorm:"type:varchar(20);not null;uniqueIndex:idx_notification_template" json:"channel"`
+	Locale    string                     `gorm:"type:varchar(10);not null;uniqueIndex:idx_notification_template" json:"locale"`
+	Texts     NotificationTemplateValues `gorm:"type:json" json:"texts"`
+	Colors    NotificationTemplateValues `gorm:"type:json" json:"colors"`
+	IsActive  bool                       `gorm:"type:tinyint(1);not null;default:1" json:"is_active"`
+	UpdatedBy uint                       `gorm:"type:bigint unsigned" json:"updated_by"`
+	CreatedAt time.Time                  `gorm:"type:datetime;not null" json:"created_at"`
+	UpdatedAt time.Time                  `gorm:"type:datetime;not null" json:"updated_at"`
+}
+
+func (NotificationTemplate) TableName() string {
+	return "notification_templates"
+}
@@ -17,6 +17,7 @@ type Offering struct {
 	DefaultEndTime      string         `gorm:"type:varchar(5);not null;default:'10:00'" json:"default_end_time"`   // 預設結束時間 (HH:MM)
 	AllowBufferOverride bool           `gorm:"type:boolean;default:false;not null" json:"allow_buffer_override"`
 	IsActive            bool           `gorm:"type:boolean;default:true;not null" json:"is_active"`
+	Version             uint           `gorm:"type:int unsigned;not null;default:1" json:"version"` // 樂觀鎖版本，每次更新遞增
 	CreatedAt           time.Time      `gorm:"type:datetime;not null" json:"created_at"`
 	UpdatedAt           time.Time      `gorm:"type:datetime;not null" json:"updated_at"`
 	DeletedAt           gorm.DeletedAt `gorm:"index" json:"-"`
@@ -28,3 +29,11 @@ type Offering struct {
 func (Offering) TableName() string {
 	return "offerings"
 }
+
+// BeforeCreate 新建資料的版本號從 1 開始，與資料庫預設值一致
+func (o *Offering) BeforeCreate(tx *gorm.DB) error {
+	if o.Version == 0 {
+		o.Version = 1
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+)
+
+// OfferingTermQuota 班別在學期內的預計堂數
+type OfferingTermQuota struct {
+	ID              uint      `gorm:"primaryKey" json:"id"`
+	CenterID        uint      `gorm:"type:bigint unsigned;not null;index" json:"center_id"`
+	OfferingID      uint      `gorm:"type:bigint unsigned;not null;uniqueIndex:idx_offering_term" json:"offering_id"`
+	TermID          uint      `gorm:"type:bigint unsigned;not null;uniqueIndex:idx_offering_term" json:"term_id"`
+	PlannedSessions int       `gorm:"type:int;not null;default:0" json:"planned_sessions"` // 學期應上堂數
+	CreatedAt       time.Time `gorm:"type:datetime;not null" json:"created_at"`
+	UpdatedAt       time.Time `gorm:"type:datetime;not null" json:"updated_at"`
+}
+
+func (OfferingTermQuota) TableName() string {
+	return "offering_term_quotas"
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 週期假日規則類型
+const (
+	RecurringHolidayTypeFixedDate  = "FIXED_DATE"  // 每年固定月日
+	RecurringHolidayTypeNthWeekday = "NTH_WEEKDAY" // 每年某月第 N 個星期幾（-1 表示最後一個）
+	RecurringHolidayTypeLunar      = "LUNAR"       // 農曆節日（春節、端午、中秋）
+)
+
+// RecurringHoliday 每年重複的假日定義
+// 展開時依年度換算為具體日期，OffsetDays 可用來表示節日前後的日期（如除夕 = 春節 -1），DurationDays 為連續放假天數
+type RecurringHoliday struct {
+	ID            uint           `gorm:"primaryKey" json:"id"`
+	CenterID      uint           `gorm:"type:bigint unsigned;not null;index" json:"center_id"`
+	Name          string         `gorm:"type:varchar(255);not null" json:"name"`
+	RuleType      string         `gorm:"type:varchar(20);not null" json:"rule_type"`
+	Month         int            `gorm:"type:tinyint;not null;default:0" json:"month"`
+	Day           int            `gorm:"type:tinyint;not null;default:0" json:"day"`
+	Weekday       int            `gorm:"type:tinyint;not null;default:0" json:"weekday"`
+	WeekOfMonth   int            `gorm:"type:tinyint;not null;default:0" json:"week_of_month"`
+	LunarFestival string         `gorm:"type:varchar(30)" json:"lunar_festival"`
+	OffsetDays    int            `gorm:"type:int;not null;default:0" json:"offset_days"`
+	DurationDays  int            `gorm:"type:int;not null;default:1" json:"duration_days"`
+	ForceCancel   bool           `gorm:"type:boolean;default:false;not null" json:"force_cancel"`
+	CreatedAt     time.Time      `gorm:"type:datetime;not null" json:"created_at"`
+	UpdatedAt     time.Time      `gorm:"type:datetime;not null" json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (RecurringHoliday) TableName() string {
+	return "recurring_holidays"
+}
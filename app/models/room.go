@@ -1,6 +1,9 @@
 package models
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
 	"time"
 
 	"gorm.io/gorm"
@@ -11,12 +14,52 @@ type Room struct {
 	CenterID  uint           `gorm:"type:bigint unsigned;not null;index" json:"center_id"`
 	Name      string         `gorm:"type:varchar(255);not null" json:"name"`
 	Capacity  int            `gorm:"type:int;not null;default:1" json:"capacity"`
+	Features  RoomFeatures   `gorm:"type:json" json:"features"`
 	IsActive  bool           `gorm:"type:boolean;default:true;not null" json:"is_active"`
 	CreatedAt time.Time      `gorm:"type:datetime;not null" json:"created_at"`
 	UpdatedAt time.Time      `gorm:"type:datetime;not null" json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
+// RoomFeature 教室設備標籤與數量（例如 piano x1、mirror_wall x1、mat x20）
+type RoomFeature struct {
+	Tag      string `json:"tag"`
+	Quantity int    `json:"quantity"`
+}
+
+// RoomFeatures 教室設備列表，同時用於課程的設備需求
+type RoomFeatures []RoomFeature
+
+// Quantity 取得指定標籤的數量，未設定時回傳 0
+func (rf RoomFeatures) Quantity(tag string) int {
+	total := 0
+	for _, f := range rf {
+		if f.Tag == tag {
+			total += f.Quantity
+		}
+	}
+	return total
+}
+
+func (rf *RoomFeatures) Scan(value interface{}) error {
+	if value == nil {
+		*rf = nil
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("failed to unmarshal RoomFeatures value")
+	}
+	return json.Unmarshal(bytes, rf)
+}
+
+func (rf RoomFeatures) Value() (driver.Value, error) {
+	if rf == nil {
+		return json.Marshal([]RoomFeature{})
+	}
+	return json.Marshal([]RoomFeature(rf))
+}
+
 func (Room) TableName() string {
 	return "rooms"
 }
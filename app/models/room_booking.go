@@ -0,0 +1,81 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 教室預約類型
+const (
+	RoomBookingTypeMeeting     = "MEETING"     // 內部會議
+	RoomBookingTypeRental      = "RENTAL"      // 對外租借
+	RoomBookingTypeMaintenance = "MAINTENANCE" // 清潔、維修
+	RoomBookingTypeOther       = "OTHER"       // 其他
+)
+
+// 教室預約重複方式
+const (
+	RoomBookingRecurrenceNone   = "NONE"   // 單次
+	RoomBookingRecurrenceWeekly = "WEEKLY" // 每週
+)
+
+// IsValidRoomBookingType 檢查預約類型是否有效
+func IsValidRoomBookingType(bookingType string) bool {
+	switch bookingType {
+	case RoomBookingTypeMeeting, RoomBookingTypeRental, RoomBookingTypeMaintenance, RoomBookingTypeOther:
+		return true
+	}
+	return false
+}
+
+// RoomBooking 非課程的教室佔用（會議、租借、維修）
+// 單次預約只在 StartDate 當天；每週預約從 StartDate 起每逢 Weekday 重複，EndDate 為空表示沒有結束日
+type RoomBooking struct {
+	ID            uint           `gorm:"primaryKey" json:"id"`
+	CenterID      uint           `gorm:"type:bigint unsigned;not null;index:idx_center_room" json:"center_id"`
+	RoomID        uint           `gorm:"type:bigint unsigned;not null;index:idx_center_room" json:"room_id"`
+	BookingType   string         `gorm:"type:varchar(20);not null" json:"booking_type"`
+	Title         string         `gorm:"type:varchar(255);not null" json:"title"`
+	RenterName    string         `gorm:"type:varchar(255)" json:"renter_name"`
+	RenterContact string         `gorm:"type:varchar(255)" json:"renter_contact"`
+	Recurrence    string         `gorm:"type:varchar(10);default:'NONE';not null" json:"recurrence"`
+	StartDate     time.Time      `gorm:"type:date;not null;index" json:"start_date"`
+	EndDate       *time.Time     `gorm:"type:date" json:"end_date"`
+	Weekday       int            `gorm:"type:tinyint;not null;default:0" json:"weekday"`
+	StartTime     string         `gorm:"type:varchar(5);not null" json:"start_time"`
+	EndTime       string         `gorm:"type:varchar(5);not null" json:"end_time"`
+	Note          string         `gorm:"type:text" json:"note"`
+	CreatedBy     uint           `gorm:"type:bigint unsigned;not null" json:"created_by"`
+	CreatedAt     time.Time      `gorm:"type:datetime;not null" json:"created_at"`
+	UpdatedAt     time.Time      `gorm:"type:datetime;not null" json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// 關聯
+	Room Room `gorm:"foreignKey:RoomID" json:"room,omitempty"`
+}
+
+func (RoomBooking) TableName() string {
+	return "room_bookings"
+}
+
+// OccursOn 檢查預約是否佔用指定日期
+func (b RoomBooking) OccursOn(date time.Time) bool {
+	dateStr := date.Format("2006-01-02")
+	if dateStr < b.StartDate.Format("2006-01-02") {
+		return false
+	}
+
+	if b.Recurrence != RoomBookingRecurrenceWeekly {
+		return dateStr == b.StartDate.Format("2006-01-02")
+	}
+
+	if b.EndDate != nil && dateStr > b.EndDate.Format("2006-01-02") {
+		return false
+	}
+	weekday := int(date.Weekday())
+	if weekday == 0 {
+		weekday = 7
+	}
+	return weekday == b.Weekday
+}
@@ -49,6 +49,7 @@ type ScheduleRule struct {
 	SuspendedDates SuspendedDates `gorm:"type:json" json:"suspended_dates"`
 	Status         string         `gorm:"type:varchar(20);default:'CONFIRMED';not null" json:"status"`
 	LockAt         *time.Time     `gorm:"type:datetime;index" json:"lock_at"`
+	Version        uint           `gorm:"type:int unsigned;not null;default:1" json:"version"` // 樂觀鎖版本，每次更新遞增
 	CreatedAt      time.Time      `gorm:"type:datetime;not null" json:"created_at"`
 	UpdatedAt      time.Time      `gorm:"type:datetime;not null" json:"updated_at"`
 	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
@@ -58,6 +59,10 @@ type ScheduleRule struct {
 	Teacher  Teacher  `gorm:"foreignKey:TeacherID" json:"teacher,omitempty"`
 	Room     Room     `gorm:"foreignKey:RoomID" json:"room,omitempty"`
 
+	// 協同老師與其他教室（主教老師與主要教室仍使用 TeacherID、RoomID）
+	AdditionalTeachers []ScheduleRuleTeacher `gorm:"foreignKey:RuleID" json:"additional_teachers,omitempty"`
+	AdditionalRooms    []ScheduleRuleRoom    `gorm:"foreignKey:RuleID" json:"additional_rooms,omitempty"`
+
 	Exceptions []ScheduleException `gorm:"foreignKey:RuleID" json:"exceptions,omitempty"`
 }
 
@@ -242,3 +247,11 @@ func (sd SuspendedDates) Value() (driver.Value, error) {
 func (ScheduleRule) TableName() string {
 	return "schedule_rules"
 }
+
+// BeforeCreate 新建資料的版本號從 1 開始，與資料庫預設值一致
+func (r *ScheduleRule) BeforeCreate(tx *gorm.DB) error {
+	if r.Version == 0 {
+		r.Version = 1
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package models
+
+import "time"
+
+// 授課角色
+const (
+	TeacherRoleLead      = "LEAD"      // 主教
+	TeacherRoleAssistant = "ASSISTANT" // 助教
+	TeacherRoleTA        = "TA"        // 課堂助理
+)
+
+// IsValidTeacherRole 檢查授課角色是否有效
+func IsValidTeacherRole(role string) bool {
+	switch role {
+	case TeacherRoleLead, TeacherRoleAssistant, TeacherRoleTA:
+		return true
+	}
+	return false
+}
+
+// TeacherRoleLabel 取得授課角色的中文名稱
+func TeacherRoleLabel(role string) string {
+	switch role {
+	case TeacherRoleLead:
+		return "主教"
+	case TeacherRoleAssistant:
+		return "助教"
+	case TeacherRoleTA:
+		return "課堂助理"
+	}
+	return role
+}
+
+// ScheduleRuleTeacher 排課規則的協同老師
+// 主教老師仍記錄在 ScheduleRule.TeacherID，這裡只存放其他一同授課的老師
+type ScheduleRuleTeacher struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CenterID  uint      `gorm:"type:bigint unsigned;not null;index" json:"center_id"`
+	RuleID    uint      `gorm:"type:bigint unsigned;not null;uniqueIndex:idx_rule_teacher" json:"rule_id"`
+	TeacherID uint      `gorm:"type:bigint unsigned;not null;uniqueIndex:idx_rule_teacher;index" json:"teacher_id"`
+	Role      string    `gorm:"type:varchar(20);not null;default:'ASSISTANT'" json:"role"`
+	CreatedAt time.Time `gorm:"type:datetime;not null" json:"created_at"`
+	UpdatedAt time.Time `gorm:"type:datetime;not null" json:"updated_at"`
+}
+
+func (ScheduleRuleTeacher) TableName() string {
+	return "schedule_rule_teachers"
+}
+
+// ScheduleRuleRoom 排課規則同時使用的其他教室
+// 主要教室仍記錄在 ScheduleRule.RoomID
+type ScheduleRuleRoom struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CenterID  uint      `gorm:"type:bigint unsigned;not null;index" json:"center_id"`
+	RuleID    uint      `gorm:"type:bigint unsigned;not null;uniqueIndex:idx_rule_room" json:"rule_id"`
+	RoomID    uint      `gorm:"type:bigint unsigned;not null;uniqueIndex:idx_rule_room;index" json:"room_id"`
+	CreatedAt time.Time `gorm:"type:datetime;not null" json:"created_at"`
+	UpdatedAt time.Time `gorm:"type:datetime;not null" json:"updated_at"`
+}
+
+func (ScheduleRuleRoom) TableName() string {
+	return "schedule_rule_rooms"
+}
+
+// TeacherAssignment 老師在課堂中的角色
+type TeacherAssignment struct {
+	TeacherID uint   `json:"teacher_id"`
+	Role      string `json:"role"`
+}
+
+// TeacherAssignments 取得規則的所有授課老師，主教老師排在第一位
+func (r ScheduleRule) TeacherAssignments() []TeacherAssignment {
+	assignments := make([]TeacherAssignment, 0, len(r.AdditionalTeachers)+1)
+	if r.TeacherID != nil && *r.TeacherID > 0 {
+		assignments = append(assignments, TeacherAssignment{TeacherID: *r.TeacherID, Role: TeacherRoleLead})
+	}
+	for _, t := range r.AdditionalTeachers {
+		if r.TeacherID != nil && t.TeacherID == *r.TeacherID {
+			continue
+		}
+		assignments = append(assignments, TeacherAssignment{TeacherID: t.TeacherID, Role: t.Role})
+	}
+	return assignments
+}
+
+// AllTeacherIDs 取得規則的所有授課老師 ID
+func (r ScheduleRule) AllTeacherIDs() []uint {
+	assignments := r.TeacherAssignments()
+	ids := make([]uint, 0, len(assignments))
+	for _, a := range assignments {
+		ids = append(ids, a.TeacherID)
+	}
+	return ids
+}
+
+// AllRoomIDs 取得規則使用的所有教室 ID，主要教室排在第一位
+func (r ScheduleRule) AllRoomIDs() []uint {
+	ids := make([]uint, 0, len(r.AdditionalRooms)+1)
+	if r.RoomID > 0 {
+		ids = append(ids, r.RoomID)
+	}
+	for _, room := range r.AdditionalRooms {
+		if room.RoomID == r.RoomID {
+			continue
+		}
+		ids = append(ids, room.RoomID)
+	}
+	return ids
+}
+
+// HasTeacher 檢查老師是否參與此規則的授課
+func (r ScheduleRule) HasTeacher(teacherID uint) bool {
+	for _, id := range r.AllTeacherIDs() {
+		if id == teacherID {
+			return true
+		}
+	}
+	return false
+}
+
+// HasRoom 檢查規則是否使用指定教室
+func (r ScheduleRule) HasRoom(roomID uint) bool {
+	for _, id := range r.AllRoomIDs() {
+		if id == roomID {
+			return true
+		}
+	}
+	return false
+}
+
+// TeacherRole 取得老師在規則中的角色，未參與時回傳空字串
+func (r ScheduleRule) TeacherRole(teacherID uint) string {
+	for _, a := range r.TeacherAssignments() {
+		if a.TeacherID == teacherID {
+			return a.Role
+		}
+	}
+	return ""
+}
@@ -12,6 +12,7 @@ type TimetableTemplate struct {
 	Name      string         `gorm:"type:varchar(255);not null" json:"name"`
 	RowType   string         `gorm:"type:varchar(20);not null" json:"row_type"`
 	IsActive  bool           `gorm:"type:boolean;default:true;not null" json:"is_active"`
+	Version   uint           `gorm:"type:int unsigned;not null;default:1" json:"version"` // 樂觀鎖版本，每次更新遞增
 	CreatedAt time.Time      `gorm:"type:datetime;not null" json:"created_at"`
 	UpdatedAt time.Time      `gorm:"type:datetime;not null" json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
@@ -22,3 +23,11 @@ type TimetableTemplate struct {
 func (TimetableTemplate) TableName() string {
 	return "timetable_templates"
 }
+
+// BeforeCreate 新建資料的版本號從 1 開始，與資料庫預設值一致
+func (t *TimetableTemplate) BeforeCreate(tx *gorm.DB) error {
+	if t.Version == 0 {
+		t.Version = 1
+	}
+	return nil
+}
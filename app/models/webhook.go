@@ -0,0 +1,115 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Webhook 事件類型（對外公開的名稱）
+const (
+	WebhookEventRuleChanged        = "rule.changed"
+	WebhookEventExceptionSubmitted = "exception.submitted"
+	WebhookEventExceptionReviewed  = "exception.reviewed"
+	WebhookEventTeacherJoined      = "teacher.joined"
+	WebhookEventTeacherLeft        = "teacher.left"
+	WebhookEventHolidayCreated     = "holiday.created"
+	WebhookEventTest               = "webhook.test" // 「發送測試事件」API，不受訂閱篩選影響
+)
+
+// WebhookEvents 可訂閱的事件類型
+var WebhookEvents = []string{
+	WebhookEventRuleChanged,
+	WebhookEventExceptionSubmitted,
+	WebhookEventExceptionReviewed,
+	WebhookEventTeacherJoined,
+	WebhookEventTeacherLeft,
+	WebhookEventHolidayCreated,
+}
+
+// Webhook 投遞狀態
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryFailed    = "failed" // 超過重試次數，可由管理員手動重送
+)
+
+// WebhookEventTypes 訂閱的事件類型，空陣列表示全部
+type WebhookEventTypes []string
+
+func (t *WebhookEventTypes) Scan(value interface{}) error {
+	if value == nil {
+		*t = WebhookEventTypes{}
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("failed to unmarshal WebhookEventTypes value")
+	}
+	return json.Unmarshal(bytes, t)
+}
+
+func (t WebhookEventTypes) Value() (driver.Value, error) {
+	if t == nil {
+		return json.Marshal([]string{})
+	}
+	return json.Marshal([]string(t))
+}
+
+// WebhookSubscription 中心的 webhook 訂閱（CRM、官網、會計系統等外部整合）
+type WebhookSubscription struct {
+	ID         uint              `gorm:"primaryKey" json:"id"`
+	CenterID   uint              `gorm:"type:bigint unsigned;not null;index" json:"center_id"`
+	Name       string            `gorm:"type:varchar(100);not null" json:"name"`
+	URL        string            `gorm:"type:varchar(500);not null" json:"url"`
+	Secret     string            `gorm:"type:varchar(64);not null" json:"-"` // HMAC-SHA256 簽章密鑰
+	EventTypes WebhookEventTypes `gorm:"type:json" json:"event_types"`
+	IsActive   bool              `gorm:"type:boolean;not null;default:true" json:"is_active"`
+	CreatedBy  uint              `gorm:"type:bigint unsigned" json:"created_by"`
+	CreatedAt  time.Time         `gorm:"type:datetime;not null" json:"created_at"`
+	UpdatedAt  time.Time         `gorm:"type:datetime;not null" json:"updated_at"`
+	DeletedAt  gorm.DeletedAt    `gorm:"index" json:"-"`
+}
+
+func (WebhookSubscription) TableName() string {
+	return "webhook_subscriptions"
+}
+
+// Subscribes 是否訂閱指定事件
+func (s WebhookSubscription) Subscribes(eventType string) bool {
+	if len(s.EventTypes) == 0 {
+		return true
+	}
+	for _, t := range s.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery webhook 投遞紀錄；同一訂閱同一事件只有一筆，重試更新同一筆
+type WebhookDelivery struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	CenterID       uint       `gorm:"type:bigint unsigned;not null;index" json:"center_id"`
+	SubscriptionID uint       `gorm:"type:bigint unsigned;not null;uniqueIndex:idx_webhook_delivery_event" json:"subscription_id"`
+	EventID        string     `gorm:"type:varchar(36);not null;uniqueIndex:idx_webhook_delivery_event" json:"event_id"`
+	EventType      string     `gorm:"type:varchar(40);not null" json:"event_type"`
+	Payload        string     `gorm:"type:json;not null" json:"-"` // 送出的 JSON 內容（重試時原樣送出）
+	Status         string     `gorm:"type:varchar(16);not null;default:'pending';index:idx_webhook_delivery_due" json:"status"`
+	Attempts       int        `gorm:"type:int;not null;default:0" json:"attempts"`
+	ResponseStatus int        `gorm:"type:int;not null;default:0" json:"response_status"` // 最後一次回應的 HTTP 狀態碼，0 表示未取得回應
+	LastError      string     `gorm:"type:text" json:"last_error,omitempty"`
+	DurationMs     int        `gorm:"type:int;not null;default:0" json:"duration_ms"`
+	NextAttemptAt  time.Time  `gorm:"type:datetime;not null;index:idx_webhook_delivery_due" json:"next_attempt_at"`
+	DeliveredAt    *time.Time `gorm:"type:datetime" json:"delivered_at"`
+	CreatedAt      time.Time  `gorm:"type:datetime;not null" json:"created_at"`
+	UpdatedAt      time.Time  `gorm:"type:datetime;not null" json:"updated_at"`
+}
+
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
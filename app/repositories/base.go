@@ -1,3 +1,18 @@
 package repositories
 
+import (
+	"errors"
+
+	"github.com/go-sql-driver/mysql"
+)
+
 type BaseRepository struct{}
+
+// mysqlErrDuplicateEntry 違反唯一索引的錯誤碼
+const mysqlErrDuplicateEntry = 1062
+
+// IsDuplicateKeyError 判斷錯誤是否為違反唯一索引
+func IsDuplicateKeyError(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrDuplicateEntry
+}
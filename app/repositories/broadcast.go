@@ -0,0 +1,127 @@
+package repositories
+
+import (
+	"context"
+	"time"
+	"timeLedger/app"
+	"timeLedger/app/models"
+)
+
+type BroadcastRepository struct {
+	GenericRepository[models.Broadcast]
+	app *app.App
+}
+
+func NewBroadcastRepository(app *app.App) *BroadcastRepository {
+	return &BroadcastRepository{
+		GenericRepository: NewGenericRepository[models.Broadcast](app.MySQL.RDB, app.MySQL.WDB),
+		app:               app,
+	}
+}
+
+// GetByIDAndCenterID 取得中心的廣播
+func (rp *BroadcastRepository) GetByIDAndCenterID(ctx context.Context, id, centerID uint) (models.Broadcast, error) {
+	var data models.Broadcast
+	err := rp.dbWrite.WithContext(ctx).
+		Where("id = ? AND center_id = ?", id, centerID).
+		First(&data).Error
+	return data, err
+}
+
+// ListByCenter 分頁取得中心的廣播，status 為空表示全部
+func (rp *BroadcastRepository) ListByCenter(ctx context.Context, centerID uint, status string, page, limit int) ([]models.Broadcast, int64, error) {
+	if status != "" {
+		return rp.FindPaged(ctx, page, limit, "id DESC", "center_id = ? AND status = ?", centerID, status)
+	}
+	return rp.FindPaged(ctx, page, limit, "id DESC", "center_id = ?", centerID)
+}
+
+// ListDue 取得到期待發送的排程廣播
+func (rp *BroadcastRepository) ListDue(ctx context.Context, now time.Time, limit int) ([]models.Broadcast, error) {
+	var data []models.Broadcast
+	err := rp.dbWrite.WithContext(ctx).
+		Where("status = ? AND scheduled_at <= ?", models.BroadcastStatusScheduled, now).
+		Order("scheduled_at ASC").
+		Limit(limit).
+		Find(&data).Error
+	return data, err
+}
+
+// Claim 以條件更新將排程廣播改為發送中，避免重複發送或與取消衝突
+func (rp *BroadcastRepository) Claim(ctx context.Context, id uint, now time.Time) (bool, error) {
+	result := rp.dbWrite.WithContext(ctx).Model(&models.Broadcast{}).
+		Where("id = ? AND status = ? AND scheduled_at <= ?", id, models.BroadcastStatusScheduled, now).
+		Updates(map[string]interface{}{
+			"status":     models.BroadcastStatusSending,
+			"updated_at": now,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// Cancel 取消尚未發送的排程廣播
+func (rp *BroadcastRepository) Cancel(ctx context.Context, id, centerID uint, now time.Time) (bool, error) {
+	result := rp.dbWrite.WithContext(ctx).Model(&models.Broadcast{}).
+		Where("id = ? AND center_id = ? AND status = ?", id, centerID, models.BroadcastStatusScheduled).
+		Updates(map[string]interface{}{
+			"status":       models.BroadcastStatusCancelled,
+			"cancelled_at": now,
+			"updated_at":   now,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// Finish 記錄發送結果
+func (rp *BroadcastRepository) Finish(ctx context.Context, broadcast models.Broadcast) error {
+	return rp.UpdateFields(ctx, broadcast.ID, map[string]interface{}{
+		"status":         broadcast.Status,
+		"sent_at":        broadcast.SentAt,
+		"total_count":    broadcast.TotalCount,
+		"sent_count":     broadcast.SentCount,
+		"failed_count":   broadcast.FailedCount,
+		"blocked_count":  broadcast.BlockedCount,
+		"skipped_count":  broadcast.SkippedCount,
+		"deferred_count": broadcast.DeferredCount,
+		"email_count":    broadcast.EmailCount,
+		"last_error":     broadcast.LastError,
+		"updated_at":     broadcast.UpdatedAt,
+	})
+}
+
+type BroadcastRecipientRepository struct {
+	GenericRepository[models.BroadcastRecipient]
+	app *app.App
+}
+
+func NewBroadcastRecipientRepository(app *app.App) *BroadcastRecipientRepository {
+	return &BroadcastRecipientRepository{
+		GenericRepository: NewGenericRepository[models.BroadcastRecipient](app.MySQL.RDB, app.MySQL.WDB),
+		app:               app,
+	}
+}
+
+// CreateBatch 寫入廣播的收件者
+func (rp *BroadcastRecipientRepository) CreateBatch(ctx context.Context, recipients []models.BroadcastRecipient) error {
+	if len(recipients) == 0 {
+		return nil
+	}
+	return rp.dbWrite.WithContext(ctx).CreateInBatches(&recipients, 200).Error
+}
+
+// RecordResult 記錄單一收件者的發送結果
+func (rp *BroadcastRecipientRepository) RecordResult(ctx context.Context, recipient models.BroadcastRecipient) error {
+	return rp.UpdateFields(ctx, recipient.ID, map[string]interface{}{
+		"status":      recipient.Status,
+		"status_code": recipient.StatusCode,
+		"error_msg":   recipient.ErrorMsg,
+		"sent_at":     recipient.SentAt,
+		"updated_at":  recipient.UpdatedAt,
+	})
+}
+
+// ListByBroadcast 分頁取得廣播的收件者，status 為空表示全部
+func (rp *BroadcastRecipientRepository) ListByBroadcast(ctx context.Context, broadcastID uint, status string, page, limit int) ([]models.BroadcastRecipient, int64, error) {
+	if status != "" {
+		return rp.FindPaged(ctx, page, limit, "id ASC", "broadcast_id = ? AND status = ?", broadcastID, status)
+	}
+	return rp.FindPaged(ctx, page, limit, "id ASC", "broadcast_id = ?", broadcastID)
+}
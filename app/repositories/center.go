@@ -4,6 +4,8 @@ import (
 	"context"
 	"timeLedger/app"
 	"timeLedger/app/models"
+
+	"gorm.io/gorm"
 )
 
 type CenterRepository struct {
@@ -76,3 +78,15 @@ func (rp *CenterRepository) GetSettings(ctx context.Context, id uint) (models.Ce
 	}
 	return center.Settings, nil
 }
+
+// UpdateSettingsWithVersion saves center settings only while settings_version still
+// equals expectedVersion, and bumps the version. Returns false on a concurrent modification.
+func (rp *CenterRepository) UpdateSettingsWithVersion(ctx context.Context, centerID uint, settings models.CenterSettings, expectedVersion uint) (bool, error) {
+	result := rp.app.MySQL.WDB.WithContext(ctx).Model(&models.Center{}).
+		Where("id = ? AND settings_version = ?", centerID, expectedVersion).
+		Updates(map[string]interface{}{
+			"settings":         settings,
+			"settings_version": gorm.Expr("settings_version + 1"),
+		})
+	return result.RowsAffected == 1, result.Error
+}
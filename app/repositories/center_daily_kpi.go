@@ -0,0 +1,51 @@
+package repositories
+
+import (
+	"context"
+	"time"
+	"timeLedger/app"
+	"timeLedger/app/models"
+
+	"gorm.io/gorm/clause"
+)
+
+type CenterDailyKPIRepository struct {
+	GenericRepository[models.CenterDailyKPI]
+	app *app.App
+}
+
+func NewCenterDailyKPIRepository(app *app.App) *CenterDailyKPIRepository {
+	return &CenterDailyKPIRepository{
+		GenericRepository: NewGenericRepository[models.CenterDailyKPI](app.MySQL.RDB, app.MySQL.WDB),
+		app:               app,
+	}
+}
+
+// ListByDateRange 取得日期區間內的每日 KPI
+func (rp *CenterDailyKPIRepository) ListByDateRange(ctx context.Context, centerID uint, start, end time.Time) ([]models.CenterDailyKPI, error) {
+	var data []models.CenterDailyKPI
+	err := rp.dbRead.WithContext(ctx).
+		Where("center_id = ?", centerID).
+		Where("date >= ? AND date <= ?", start.Format("2006-01-02"), end.Format("2006-01-02")).
+		Order("date ASC").
+		Find(&data).Error
+	return data, err
+}
+
+// UpsertDays 寫入每日 KPI，同中心同日期已存在時覆寫
+func (rp *CenterDailyKPIRepository) UpsertDays(ctx context.Context, rows []models.CenterDailyKPI) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	return rp.dbWrite.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "center_id"}, {Name: "date"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"planned_sessions", "delivered_sessions", "cancelled_sessions", "rescheduled_sessions",
+				"substitute_sessions", "makeup_sessions", "cancel_reasons", "exception_types",
+				"reviewed_exceptions", "approval_lead_minutes", "teacher_minutes", "new_teachers",
+				"aggregated_at", "updated_at",
+			}),
+		}).
+		CreateInBatches(&rows, 200).Error
+}
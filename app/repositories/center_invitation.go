@@ -114,3 +114,13 @@ func (rp *CenterInvitationRepository) ListByTeacherWithStatus(ctx context.Contex
 func (rp *CenterInvitationRepository) GetGeneralByCenterID(ctx context.Context, centerID uint) (models.CenterInvitation, error) {
 	return rp.First(ctx, "center_id = ? AND invite_type = ?", centerID, models.InvitationTypeGeneral)
 }
+
+// ListAcceptedByDateRange 取得回覆時間落在區間內且已接受的邀請
+func (rp *CenterInvitationRepository) ListAcceptedByDateRange(ctx context.Context, centerID uint, start, end time.Time) ([]models.CenterInvitation, error) {
+	var data []models.CenterInvitation
+	err := rp.dbRead.WithContext(ctx).
+		Where("center_id = ? AND status = ?", centerID, models.InvitationStatusAccepted).
+		Where("responded_at >= ? AND responded_at < ?", start, end).
+		Find(&data).Error
+	return data, err
+}
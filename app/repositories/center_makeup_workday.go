@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"context"
+	"time"
+	"timeLedger/app"
+	"timeLedger/app/models"
+
+	"gorm.io/gorm"
+)
+
+type CenterMakeupWorkdayRepository struct {
+	GenericRepository[models.CenterMakeupWorkday]
+	app *app.App
+}
+
+func NewCenterMakeupWorkdayRepository(app *app.App) *CenterMakeupWorkdayRepository {
+	return &CenterMakeupWorkdayRepository{
+		GenericRepository: NewGenericRepository[models.CenterMakeupWorkday](app.MySQL.RDB, app.MySQL.WDB),
+		app:               app,
+	}
+}
+
+func (r *CenterMakeupWorkdayRepository) ListByCenterID(ctx context.Context, centerID uint) ([]models.CenterMakeupWorkday, error) {
+	return r.FindWithCenterScope(ctx, centerID)
+}
+
+func (r *CenterMakeupWorkdayRepository) ListByDateRange(ctx context.Context, centerID uint, start, end time.Time) ([]models.CenterMakeupWorkday, error) {
+	return r.Find(ctx, "center_id = ? AND date >= ? AND date <= ?", centerID, start.Format("2006-01-02"), end.Format("2006-01-02"))
+}
+
+// BulkCreateWithSkipDuplicate 批次建立補班日，同中心同日期已存在者略過
+func (r *CenterMakeupWorkdayRepository) BulkCreateWithSkipDuplicate(ctx context.Context, workdays []models.CenterMakeupWorkday) ([]models.CenterMakeupWorkday, int64, error) {
+	if len(workdays) == 0 {
+		return workdays, 0, nil
+	}
+
+	var created int64
+	var createdWorkdays []models.CenterMakeupWorkday
+
+	err := r.dbWrite.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i := range workdays {
+			var existing int64
+			tx.Model(&models.CenterMakeupWorkday{}).
+				Where("center_id = ? AND date = ?", workdays[i].CenterID, workdays[i].Date.Format("2006-01-02")).
+				Count(&existing)
+
+			if existing == 0 {
+				if err := tx.Create(&workdays[i]).Error; err != nil {
+					return err
+				}
+				createdWorkdays = append(createdWorkdays, workdays[i])
+				created++
+			}
+		}
+		return nil
+	})
+
+	return createdWorkdays, created, err
+}
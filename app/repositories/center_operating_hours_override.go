@@ -0,0 +1,29 @@
+package repositories
+
+import (
+	"context"
+	"time"
+	"timeLedger/app"
+	"timeLedger/app/models"
+)
+
+type CenterOperatingHoursOverrideRepository struct {
+	GenericRepository[models.CenterOperatingHoursOverride]
+	app *app.App
+}
+
+func NewCenterOperatingHoursOverrideRepository(app *app.App) *CenterOperatingHoursOverrideRepository {
+	return &CenterOperatingHoursOverrideRepository{
+		GenericRepository: NewGenericRepository[models.CenterOperatingHoursOverride](app.MySQL.RDB, app.MySQL.WDB),
+		app:               app,
+	}
+}
+
+func (r *CenterOperatingHoursOverrideRepository) ListByCenterID(ctx context.Context, centerID uint) ([]models.CenterOperatingHoursOverride, error) {
+	return r.FindWithCenterScope(ctx, centerID)
+}
+
+// ListOverlapping 取得與日期區間重疊的營業時間調整
+func (r *CenterOperatingHoursOverrideRepository) ListOverlapping(ctx context.Context, centerID uint, start, end time.Time) ([]models.CenterOperatingHoursOverride, error) {
+	return r.Find(ctx, "center_id = ? AND start_date <= ? AND end_date >= ?", centerID, end.Format("2006-01-02"), start.Format("2006-01-02"))
+}
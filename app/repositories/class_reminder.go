@@ -0,0 +1,30 @@
+package repositories
+
+import (
+	"context"
+	"timeLedger/app"
+	"timeLedger/app/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ClassReminderRepository struct {
+	GenericRepository[models.ClassReminder]
+	app *app.App
+}
+
+func NewClassReminderRepository(app *app.App) *ClassReminderRepository {
+	return &ClassReminderRepository{
+		GenericRepository: NewGenericRepository[models.ClassReminder](app.MySQL.RDB, app.MySQL.WDB),
+		app:               app,
+	}
+}
+
+// ReserveWithDB 在外部交易中登記提醒，已有相同場次、提前時間與老師的紀錄時回傳 false
+func (rp *ClassReminderRepository) ReserveWithDB(ctx context.Context, tx *gorm.DB, reminder *models.ClassReminder) (bool, error) {
+	result := tx.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(reminder)
+	return result.RowsAffected == 1, result.Error
+}
@@ -0,0 +1,72 @@
+package repositories
+
+import (
+	"context"
+	"time"
+	"timeLedger/app"
+	"timeLedger/app/models"
+
+	"gorm.io/gorm"
+)
+
+type DomainEventRepository struct {
+	GenericRepository[models.DomainEvent]
+	app *app.App
+}
+
+func NewDomainEventRepository(app *app.App) *DomainEventRepository {
+	return &DomainEventRepository{
+		GenericRepository: NewGenericRepository[models.DomainEvent](app.MySQL.RDB, app.MySQL.WDB),
+		app:               app,
+	}
+}
+
+// CreateWithDB 在外部交易中寫入事件
+func (rp *DomainEventRepository) CreateWithDB(ctx context.Context, tx *gorm.DB, event models.DomainEvent) (models.DomainEvent, error) {
+	err := tx.WithContext(ctx).Create(&event).Error
+	return event, err
+}
+
+// ListDue 取得到期待轉送的事件（依寫入順序）
+func (rp *DomainEventRepository) ListDue(ctx context.Context, now time.Time, limit int) ([]models.DomainEvent, error) {
+	var data []models.DomainEvent
+	err := rp.dbWrite.WithContext(ctx).
+		Where("status = ? AND available_at <= ?", models.DomainEventStatusPending, now).
+		Order("id ASC").
+		Limit(limit).
+		Find(&data).Error
+	return data, err
+}
+
+// Claim 以條件更新搶占事件，避免多個 relay 同時轉送同一筆
+func (rp *DomainEventRepository) Claim(ctx context.Context, id uint, now, leaseUntil time.Time) (bool, error) {
+	result := rp.dbWrite.WithContext(ctx).Model(&models.DomainEvent{}).
+		Where("id = ? AND status = ? AND available_at <= ?", id, models.DomainEventStatusPending, now).
+		Updates(map[string]interface{}{
+			"available_at": leaseUntil,
+			"updated_at":   now,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// MarkPublished 標記已轉送
+func (rp *DomainEventRepository) MarkPublished(ctx context.Context, id uint, now time.Time) error {
+	return rp.UpdateFields(ctx, id, map[string]interface{}{
+		"status":       models.DomainEventStatusPublished,
+		"attempts":     gorm.Expr("attempts + 1"),
+		"last_error":   "",
+		"published_at": now,
+		"updated_at":   now,
+	})
+}
+
+// MarkRetry 轉送失敗，記錄錯誤並安排下次重試；超過次數時 status 為 FAILED
+func (rp *DomainEventRepository) MarkRetry(ctx context.Context, id uint, status string, attempts int, lastErr string, availableAt, now time.Time) error {
+	return rp.UpdateFields(ctx, id, map[string]interface{}{
+		"status":       status,
+		"attempts":     attempts,
+		"last_error":   lastErr,
+		"available_at": availableAt,
+		"updated_at":   now,
+	})
+}
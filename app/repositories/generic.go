@@ -271,6 +271,25 @@ func (rp *GenericRepository[T]) Update(ctx context.Context, data T) error {
 	return rp.withContext(ctx).dbWrite.Table(rp.table).Model(&data).Updates(data).Error
 }
 
+// BumpVersionWithDB increments the optimistic-lock version of a record inside
+// the given transaction, but only while it still equals expectedVersion.
+// The model must have a `version` column.
+//
+// Parameters:
+//   - tx: Transaction connection
+//   - id: Record ID
+//   - expectedVersion: Version the caller read before modifying the record
+//
+// Returns:
+//   - true if the version was bumped, false if another request modified the record first
+//   - error: Any error encountered
+func (rp *GenericRepository[T]) BumpVersionWithDB(ctx context.Context, tx *gorm.DB, id uint, expectedVersion uint) (bool, error) {
+	result := tx.WithContext(ctx).Table(rp.table).
+		Where("id = ? AND version = ?", id, expectedVersion).
+		UpdateColumn("version", gorm.Expr("version + 1"))
+	return result.RowsAffected == 1, result.Error
+}
+
 // UpdateFields updates specific fields of a record identified by ID.
 //
 // Parameters:
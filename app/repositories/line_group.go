@@ -0,0 +1,42 @@
+package repositories
+
+import (
+	"context"
+	"timeLedger/app"
+	"timeLedger/app/models"
+)
+
+type LineGroupRepository struct {
+	GenericRepository[models.LineGroup]
+	app *app.App
+}
+
+func NewLineGroupRepository(app *app.App) *LineGroupRepository {
+	return &LineGroupRepository{
+		GenericRepository: NewGenericRepository[models.LineGroup](app.MySQL.RDB, app.MySQL.WDB),
+		app:               app,
+	}
+}
+
+// GetByGroupID 依 LINE groupId 取得連結
+func (rp *LineGroupRepository) GetByGroupID(ctx context.Context, groupID string) (models.LineGroup, error) {
+	return rp.First(ctx, "group_id = ?", groupID)
+}
+
+// ListByCenterID 取得中心連結的所有群組
+func (rp *LineGroupRepository) ListByCenterID(ctx context.Context, centerID uint) ([]models.LineGroup, error) {
+	return rp.Find(ctx, "center_id = ?", centerID)
+}
+
+// ListDailySummaryPending 取得啟用每日摘要且今日尚未發送的群組
+func (rp *LineGroupRepository) ListDailySummaryPending(ctx context.Context, date string) ([]models.LineGroup, error) {
+	return rp.Find(ctx, "notify_daily_summary = ? AND (last_summary_date IS NULL OR last_summary_date <> ?)", true, date)
+}
+
+// ClaimDailySummary 以條件更新標記今日摘要已發送，多個實例同時執行時僅一個會取得
+func (rp *LineGroupRepository) ClaimDailySummary(ctx context.Context, id uint, date string) (bool, error) {
+	result := rp.dbWrite.WithContext(ctx).Model(&models.LineGroup{}).
+		Where("id = ? AND (last_summary_date IS NULL OR last_summary_date <> ?)", id, date).
+		Updates(map[string]interface{}{"last_summary_date": date})
+	return result.RowsAffected == 1, result.Error
+}
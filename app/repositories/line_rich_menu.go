@@ -0,0 +1,24 @@
+package repositories
+
+import (
+	"context"
+	"timeLedger/app"
+	"timeLedger/app/models"
+)
+
+type LineRichMenuRepository struct {
+	GenericRepository[models.LineRichMenu]
+	app *app.App
+}
+
+func NewLineRichMenuRepository(app *app.App) *LineRichMenuRepository {
+	return &LineRichMenuRepository{
+		GenericRepository: NewGenericRepository[models.LineRichMenu](app.MySQL.RDB, app.MySQL.WDB),
+		app:               app,
+	}
+}
+
+// GetByKey 依設定 key 取得已上傳的選單
+func (rp *LineRichMenuRepository) GetByKey(ctx context.Context, menuKey string) (models.LineRichMenu, error) {
+	return rp.First(ctx, "menu_key = ?", menuKey)
+}
@@ -0,0 +1,121 @@
+package repositories
+
+import (
+	"context"
+	"time"
+	"timeLedger/app"
+	"timeLedger/app/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LineWebhookEventRepository struct {
+	GenericRepository[models.LineWebhookEvent]
+	app *app.App
+}
+
+func NewLineWebhookEventRepository(app *app.App) *LineWebhookEventRepository {
+	return &LineWebhookEventRepository{
+		GenericRepository: NewGenericRepository[models.LineWebhookEvent](app.MySQL.RDB, app.MySQL.WDB),
+		app:               app,
+	}
+}
+
+// CreateIfAbsent 寫入事件紀錄，同一 webhookEventId 已存在時不重複建立
+func (rp *LineWebhookEventRepository) CreateIfAbsent(ctx context.Context, event *models.LineWebhookEvent) (bool, error) {
+	result := rp.dbWrite.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(event)
+	return result.RowsAffected == 1, result.Error
+}
+
+// ListDue 取得待處理的事件（含搶占期滿未完成者）
+func (rp *LineWebhookEventRepository) ListDue(ctx context.Context, now time.Time, limit int) ([]models.LineWebhookEvent, error) {
+	var data []models.LineWebhookEvent
+	err := rp.dbWrite.WithContext(ctx).
+		Where("status = ? AND next_attempt_at <= ?", models.LineWebhookEventPending, now).
+		Order("id ASC").
+		Limit(limit).
+		Find(&data).Error
+	return data, err
+}
+
+// Claim 以條件更新搶占事件，避免多個 worker 重複處理同一筆
+func (rp *LineWebhookEventRepository) Claim(ctx context.Context, id uint, now, leaseUntil time.Time) (bool, error) {
+	result := rp.dbWrite.WithContext(ctx).Model(&models.LineWebhookEvent{}).
+		Where("id = ? AND status = ? AND next_attempt_at <= ?", id, models.LineWebhookEventPending, now).
+		Updates(map[string]interface{}{
+			"attempts":        gorm.Expr("attempts + 1"),
+			"next_attempt_at": leaseUntil,
+			"updated_at":      now,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// RecordResult 記錄處理結果
+func (rp *LineWebhookEventRepository) RecordResult(ctx context.Context, event models.LineWebhookEvent) error {
+	return rp.UpdateFields(ctx, event.ID, map[string]interface{}{
+		"status":       event.Status,
+		"last_error":   event.LastError,
+		"duration_ms":  event.DurationMs,
+		"processed_at": event.ProcessedAt,
+		"updated_at":   event.UpdatedAt,
+	})
+}
+
+// Requeue 將失敗的事件重新排入佇列
+func (rp *LineWebhookEventRepository) Requeue(ctx context.Context, id uint, now time.Time) (bool, error) {
+	result := rp.dbWrite.WithContext(ctx).Model(&models.LineWebhookEvent{}).
+		Where("id = ? AND status = ?", id, models.LineWebhookEventFailed).
+		Updates(map[string]interface{}{
+			"status":          models.LineWebhookEventPending,
+			"next_attempt_at": now,
+			"updated_at":      now,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// centerScope 與中心相關的事件：來源為中心管理員、在職老師或中心連結的群組
+func (rp *LineWebhookEventRepository) centerScope(centerID uint) []interface{} {
+	admins := rp.dbRead.Model(&models.AdminUser{}).
+		Select("line_user_id").
+		Where("center_id = ? AND line_user_id <> ''", centerID)
+	teachers := rp.dbRead.Model(&models.Teacher{}).
+		Select("teachers.line_user_id").
+		Joins("INNER JOIN center_memberships ON center_memberships.teacher_id = teachers.id AND center_memberships.center_id = ? AND center_memberships.status = ?", centerID, "ACTIVE").
+		Where("teachers.line_user_id <> ''")
+	groups := rp.dbRead.Model(&models.LineGroup{}).
+		Select("group_id").
+		Where("center_id = ?", centerID)
+	return []interface{}{admins, teachers, groups}
+}
+
+// ListByCenter 分頁取得與中心相關的事件，status 為空表示全部
+func (rp *LineWebhookEventRepository) ListByCenter(ctx context.Context, centerID uint, status string, page, limit int) ([]models.LineWebhookEvent, int64, error) {
+	query := "(source_user_id IN (?) OR source_user_id IN (?) OR source_group_id IN (?))"
+	args := rp.centerScope(centerID)
+	if status != "" {
+		query += " AND status = ?"
+		args = append(args, status)
+	}
+	return rp.FindPaged(ctx, page, limit, "id DESC", append([]interface{}{query}, args...)...)
+}
+
+// GetByIDAndCenter 取得與中心相關的單筆事件
+func (rp *LineWebhookEventRepository) GetByIDAndCenter(ctx context.Context, id, centerID uint) (models.LineWebhookEvent, error) {
+	var data models.LineWebhookEvent
+	err := rp.dbWrite.WithContext(ctx).
+		Where("id = ?", id).
+		Where("(source_user_id IN (?) OR source_user_id IN (?) OR source_group_id IN (?))", rp.centerScope(centerID)...).
+		First(&data).Error
+	return data, err
+}
+
+// DeleteBefore 刪除建立時間早於 cutoff 的事件紀錄
+func (rp *LineWebhookEventRepository) DeleteBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	result := rp.dbWrite.WithContext(ctx).
+		Where("created_at < ?", cutoff).
+		Delete(&models.LineWebhookEvent{})
+	return result.RowsAffected, result.Error
+}
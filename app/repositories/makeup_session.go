@@ -0,0 +1,98 @@
+package repositories
+
+import (
+	"context"
+	"time"
+	"timeLedger/app"
+	"timeLedger/app/models"
+)
+
+type MakeupSessionRepository struct {
+	GenericRepository[models.MakeupSession]
+	app *app.App
+}
+
+func NewMakeupSessionRepository(app *app.App) *MakeupSessionRepository {
+	return &MakeupSessionRepository{
+		GenericRepository: NewGenericRepository[models.MakeupSession](app.MySQL.RDB, app.MySQL.WDB),
+		app:               app,
+	}
+}
+
+// ListByOfferingAndTerm 取得班別在學期內的所有補課（含已取消）
+func (rp *MakeupSessionRepository) ListByOfferingAndTerm(ctx context.Context, offeringID, termID uint) ([]models.MakeupSession, error) {
+	var data []models.MakeupSession
+	err := rp.dbRead.WithContext(ctx).
+		Where("offering_id = ? AND term_id = ?", offeringID, termID).
+		Order("makeup_date ASC, start_time ASC").
+		Find(&data).Error
+	return data, err
+}
+
+// ListByTerm 取得學期內所有補課
+func (rp *MakeupSessionRepository) ListByTerm(ctx context.Context, centerID, termID uint) ([]models.MakeupSession, error) {
+	return rp.FindWithCenterScope(ctx, centerID, "term_id = ?", termID)
+}
+
+// ListScheduledByDateRange 取得日期範圍內已安排的補課
+func (rp *MakeupSessionRepository) ListScheduledByDateRange(ctx context.Context, centerID uint, start, end time.Time) ([]models.MakeupSession, error) {
+	var data []models.MakeupSession
+	err := rp.dbRead.WithContext(ctx).
+		Preload("Offering").
+		Where("center_id = ? AND status = ?", centerID, models.MakeupStatusScheduled).
+		Where("makeup_date >= ? AND makeup_date <= ?", start.Format("2006-01-02"), end.Format("2006-01-02")).
+		Order("makeup_date ASC, start_time ASC").
+		Find(&data).Error
+	return data, err
+}
+
+// ExistsScheduledForSession 檢查原場次是否已有安排中的補課
+func (rp *MakeupSessionRepository) ExistsScheduledForSession(ctx context.Context, ruleID uint, originalDate time.Time) (bool, error) {
+	return rp.Exists(ctx, "original_rule_id = ? AND original_date = ? AND status = ?", ruleID, originalDate.Format("2006-01-02"), models.MakeupStatusScheduled)
+}
+
+// ListScheduledByRulesOrTeachersAndDateRange 取得日期範圍內屬於規則、或由指定老師授課的已安排補課
+func (rp *MakeupSessionRepository) ListScheduledByRulesOrTeachersAndDateRange(ctx context.Context, centerID uint, ruleIDs, teacherIDs []uint, start, end time.Time) ([]models.MakeupSession, error) {
+	var data []models.MakeupSession
+	if len(ruleIDs) == 0 && len(teacherIDs) == 0 {
+		return data, nil
+	}
+	// 空的 IN 條件由 gorm 轉為 IN (NULL)，不會匹配
+	err := rp.dbRead.WithContext(ctx).
+		Where("center_id = ? AND status = ?", centerID, models.MakeupStatusScheduled).
+		Where("(original_rule_id IN ? OR teacher_id IN ?)", ruleIDs, teacherIDs).
+		Where("makeup_date >= ? AND makeup_date <= ?", start.Format("2006-01-02"), end.Format("2006-01-02")).
+		Order("makeup_date ASC, start_time ASC").
+		Find(&data).Error
+	return data, err
+}
+
+// ListScheduledByRoomAndDateRange 取得教室在日期範圍內已安排的補課
+func (rp *MakeupSessionRepository) ListScheduledByRoomAndDateRange(ctx context.Context, centerID, roomID uint, start, end time.Time) ([]models.MakeupSession, error) {
+	var data []models.MakeupSession
+	err := rp.dbRead.WithContext(ctx).
+		Where("center_id = ? AND room_id = ? AND status = ?", centerID, roomID, models.MakeupStatusScheduled).
+		Where("makeup_date >= ? AND makeup_date <= ?", start.Format("2006-01-02"), end.Format("2006-01-02")).
+		Order("makeup_date ASC, start_time ASC").
+		Find(&data).Error
+	return data, err
+}
+
+// ListScheduledOverlapping 取得與時段重疊的已安排補課
+// date 為 nil 時比對 from 之後所有落在 weekday（1=週一 … 7=週日）的補課；excludeRuleID 的補課不列入
+func (rp *MakeupSessionRepository) ListScheduledOverlapping(ctx context.Context, centerID uint, date *time.Time, weekday int, from time.Time, startClock, endClock string, excludeRuleID *uint) ([]models.MakeupSession, error) {
+	var data []models.MakeupSession
+	query := rp.dbRead.WithContext(ctx).
+		Where("center_id = ? AND status = ?", centerID, models.MakeupStatusScheduled).
+		Where("start_time < ? AND end_time > ?", endClock, startClock)
+	if excludeRuleID != nil {
+		query = query.Where("original_rule_id <> ?", *excludeRuleID)
+	}
+	if date != nil {
+		query = query.Where("makeup_date = ?", date.Format("2006-01-02"))
+	} else {
+		query = query.Where("makeup_date >= ? AND WEEKDAY(makeup_date) = ?", from.Format("2006-01-02"), weekday-1)
+	}
+	err := query.Order("makeup_date ASC, start_time ASC").Find(&data).Error
+	return data, err
+}
@@ -5,6 +5,8 @@ import (
 	"time"
 	"timeLedger/app"
 	"timeLedger/app/models"
+
+	"gorm.io/gorm"
 )
 
 type NotificationRepository struct {
@@ -19,6 +21,11 @@ func NewNotificationRepository(app *app.App) *NotificationRepository {
 	}
 }
 
+// CreateWithDB 在外部交易中寫入站內通知
+func (r *NotificationRepository) CreateWithDB(ctx context.Context, tx *gorm.DB, notification *models.Notification) error {
+	return tx.WithContext(ctx).Create(notification).Error
+}
+
 func (r *NotificationRepository) ListUnread(ctx context.Context, userID uint, userType string) ([]models.Notification, error) {
 	return r.Find(ctx, "user_id = ? AND user_type = ? AND is_read = ?", userID, userType, false)
 }
@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"context"
+	"timeLedger/app"
+	"timeLedger/app/models"
+
+	"gorm.io/gorm/clause"
+)
+
+type NotificationPreferenceRepository struct {
+	GenericRepository[models.NotificationPreference]
+	app *app.App
+}
+
+func NewNotificationPreferenceRepository(app *app.App) *NotificationPreferenceRepository {
+	return &NotificationPreferenceRepository{
+		GenericRepository: NewGenericRepository[models.NotificationPreference](app.MySQL.RDB, app.MySQL.WDB),
+		app:               app,
+	}
+}
+
+// GetByUser 取得使用者的通知偏好，尚未設定時回傳 gorm.ErrRecordNotFound
+func (rp *NotificationPreferenceRepository) GetByUser(ctx context.Context, userType string, userID uint) (models.NotificationPreference, error) {
+	var data models.NotificationPreference
+	err := rp.dbRead.WithContext(ctx).
+		Where("user_type = ? AND user_id = ?", userType, userID).
+		First(&data).Error
+	return data, err
+}
+
+// ListByUsers 批次取得多位使用者的通知偏好（使用者 ID -> 偏好），未設定者不在結果中
+func (rp *NotificationPreferenceRepository) ListByUsers(ctx context.Context, userType string, userIDs []uint) (map[uint]models.NotificationPreference, error) {
+	result := make(map[uint]models.NotificationPreference, len(userIDs))
+	if len(userIDs) == 0 {
+		return result, nil
+	}
+	var data []models.NotificationPreference
+	err := rp.dbRead.WithContext(ctx).
+		Where("user_type = ? AND user_id IN ?", userType, userIDs).
+		Find(&data).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, pref := range data {
+		result[pref.UserID] = pref
+	}
+	return result, nil
+}
+
+// Save 寫入通知偏好，同一使用者已存在時覆寫
+func (rp *NotificationPreferenceRepository) Save(ctx context.Context, pref *models.NotificationPreference) error {
+	return rp.dbWrite.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "user_type"}, {Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"channels", "quiet_hours_enabled", "quiet_hours_start", "quiet_hours_end",
+				"timezone", "digest_enabled", "digest_time", "reminder_offsets", "updated_at",
+			}),
+		}).
+		Create(pref).Error
+}
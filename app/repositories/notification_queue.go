@@ -21,6 +21,11 @@ func NewNotificationQueueRepository(app *app.App) *NotificationQueueRepository {
 	}
 }
 
+// CreateWithDB 在外部交易中寫入通知
+func (r *NotificationQueueRepository) CreateWithDB(ctx context.Context, tx *gorm.DB, item *models.NotificationQueue) error {
+	return tx.WithContext(ctx).Create(item).Error
+}
+
 func (r *NotificationQueueRepository) GetPending(ctx context.Context, limit int) ([]models.NotificationQueue, error) {
 	var items []models.NotificationQueue
 	err := r.app.MySQL.RDB.WithContext(ctx).
@@ -61,3 +66,227 @@ func (r *NotificationQueueRepository) GetByRecipient(ctx context.Context, recipi
 	err := query.Find(&items).Error
 	return items, err
 }
+
+// ListHeldDue 取得到期的暫緩通知（勿擾延後或每日彙整）
+func (r *NotificationQueueRepository) ListHeldDue(ctx context.Context, status string, now time.Time, limit int) ([]models.NotificationQueue, error) {
+	var items []models.NotificationQueue
+	err := r.app.MySQL.WDB.WithContext(ctx).
+		Where("status = ? AND scheduled_at <= ?", status, now).
+		Order("recipient_type ASC, recipient_id ASC, channel ASC, id ASC").
+		Limit(limit).
+		Find(&items).Error
+	return items, err
+}
+
+// ClaimHeld 以條件更新搶占暫緩通知，避免多個實例重複發送
+func (r *NotificationQueueRepository) ClaimHeld(ctx context.Context, id uint, status string, now time.Time) (bool, error) {
+	result := r.app.MySQL.WDB.WithContext(ctx).
+		Model(&models.NotificationQueue{}).
+		Where("id = ? AND status = ?", id, status).
+		Updates(map[string]interface{}{
+			"status":     models.NotificationStatusSent,
+			"sent_at":    now,
+			"updated_at": now,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// ReleaseHeld 發送失敗時放回原狀態並延後重試；超過重試次數時移入死信佇列
+func (r *NotificationQueueRepository) ReleaseHeld(ctx context.Context, ids []uint, status string, retryAt time.Time, errMsg string, maxRetry int, now time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := r.app.MySQL.WDB.WithContext(ctx).Model(&models.NotificationQueue{}).
+		Where("id IN ? AND status = ? AND retry_count + 1 >= ?", ids, models.NotificationStatusSent, maxRetry).
+		Updates(map[string]interface{}{
+			"status":        models.NotificationStatusDead,
+			"replay_status": status,
+			"retry_count":   gorm.Expr("retry_count + 1"),
+			"error_msg":     errMsg,
+			"sent_at":       nil,
+			"failed_at":     now,
+			"dead_at":       now,
+			"updated_at":    now,
+		}).Error; err != nil {
+		return err
+	}
+	return r.app.MySQL.WDB.WithContext(ctx).Model(&models.NotificationQueue{}).
+		Where("id IN ? AND status = ?", ids, models.NotificationStatusSent).
+		Updates(map[string]interface{}{
+			"status":       status,
+			"retry_count":  gorm.Expr("retry_count + 1"),
+			"error_msg":    errMsg,
+			"sent_at":      nil,
+			"scheduled_at": retryAt,
+			"updated_at":   now,
+		}).Error
+}
+
+// MarkSent 標記已發送
+func (r *NotificationQueueRepository) MarkSent(ctx context.Context, id uint, now time.Time) error {
+	return r.UpdateFields(ctx, id, map[string]interface{}{
+		"status":     models.NotificationStatusSent,
+		"error_msg":  "",
+		"sent_at":    now,
+		"updated_at": now,
+	})
+}
+
+// MarkFailed 記錄發送失敗
+func (r *NotificationQueueRepository) MarkFailed(ctx context.Context, id uint, errMsg string, now time.Time) error {
+	return r.UpdateFields(ctx, id, map[string]interface{}{
+		"status":      models.NotificationStatusFailed,
+		"retry_count": gorm.Expr("retry_count + 1"),
+		"error_msg":   errMsg,
+		"failed_at":   now,
+		"updated_at":  now,
+	})
+}
+
+// MarkBounced 記錄退信
+func (r *NotificationQueueRepository) MarkBounced(ctx context.Context, id uint, reason string, now time.Time) error {
+	return r.UpdateFields(ctx, id, map[string]interface{}{
+		"status":     models.NotificationStatusBounced,
+		"error_msg":  reason,
+		"bounced_at": now,
+		"updated_at": now,
+	})
+}
+
+// HasRecentBounce 收件地址在指定時間後是否曾退信
+func (r *NotificationQueueRepository) HasRecentBounce(ctx context.Context, channel, address string, since time.Time) (bool, error) {
+	var count int64
+	err := r.app.MySQL.RDB.WithContext(ctx).
+		Model(&models.NotificationQueue{}).
+		Where("channel = ? AND recipient_address = ? AND status = ? AND bounced_at >= ?",
+			channel, address, models.NotificationStatusBounced, since).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// ListDue 取得到期待派送的通知，createdBefore 之後寫入的通知留給佇列後端
+func (r *NotificationQueueRepository) ListDue(ctx context.Context, now, createdBefore time.Time, limit int) ([]models.NotificationQueue, error) {
+	var items []models.NotificationQueue
+	err := r.app.MySQL.WDB.WithContext(ctx).
+		Where("status = ? AND scheduled_at <= ? AND created_at <= ?", models.NotificationStatusPending, now, createdBefore).
+		Order("scheduled_at ASC, id ASC").
+		Limit(limit).
+		Find(&items).Error
+	return items, err
+}
+
+// Claim 以條件更新搶占待派送通知，將 scheduled_at 延後為租約期限，派送中途停止時期滿後會重新派送
+func (r *NotificationQueueRepository) Claim(ctx context.Context, id uint, now, leaseUntil time.Time) (bool, error) {
+	result := r.app.MySQL.WDB.WithContext(ctx).
+		Model(&models.NotificationQueue{}).
+		Where("id = ? AND status = ? AND scheduled_at <= ?", id, models.NotificationStatusPending, now).
+		Updates(map[string]interface{}{
+			"scheduled_at": leaseUntil,
+			"updated_at":   now,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// ScheduleRetry 記錄失敗並安排下次派送
+func (r *NotificationQueueRepository) ScheduleRetry(ctx context.Context, id uint, attempts int, errMsg string, retryAt, now time.Time) error {
+	return r.UpdateFields(ctx, id, map[string]interface{}{
+		"status":       models.NotificationStatusPending,
+		"retry_count":  attempts,
+		"error_msg":    errMsg,
+		"failed_at":    now,
+		"scheduled_at": retryAt,
+		"updated_at":   now,
+	})
+}
+
+// MarkDead 超過重試次數，移入死信佇列
+func (r *NotificationQueueRepository) MarkDead(ctx context.Context, id uint, attempts int, replayStatus, errMsg string, now time.Time) error {
+	return r.UpdateFields(ctx, id, map[string]interface{}{
+		"status":        models.NotificationStatusDead,
+		"replay_status": replayStatus,
+		"retry_count":   attempts,
+		"error_msg":     errMsg,
+		"failed_at":     now,
+		"dead_at":       now,
+		"updated_at":    now,
+	})
+}
+
+// MarkSkipped 收件者無法接收（未綁定或已關閉通知），不再重試
+func (r *NotificationQueueRepository) MarkSkipped(ctx context.Context, id uint, reason string, now time.Time) error {
+	return r.UpdateFields(ctx, id, map[string]interface{}{
+		"status":     models.NotificationStatusSkipped,
+		"error_msg":  reason,
+		"updated_at": now,
+	})
+}
+
+// ListDeadLetters 分頁取得中心的死信通知
+func (r *NotificationQueueRepository) ListDeadLetters(ctx context.Context, centerID uint, notificationType, channel string, page, limit int) ([]models.NotificationQueue, int64, error) {
+	query := r.app.MySQL.RDB.WithContext(ctx).
+		Model(&models.NotificationQueue{}).
+		Where("center_id = ? AND status = ?", centerID, models.NotificationStatusDead)
+	if notificationType != "" {
+		query = query.Where("type = ?", notificationType)
+	}
+	if channel != "" {
+		query = query.Where("channel = ?", channel)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var items []models.NotificationQueue
+	err := query.Order("dead_at DESC, id DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&items).Error
+	return items, total, err
+}
+
+// Replay 將死信通知恢復為待派送（或暫緩）狀態並重設重試次數
+func (r *NotificationQueueRepository) Replay(ctx context.Context, id, centerID uint, status string, now time.Time) (bool, error) {
+	result := r.app.MySQL.WDB.WithContext(ctx).
+		Model(&models.NotificationQueue{}).
+		Where("id = ? AND center_id = ? AND status = ?", id, centerID, models.NotificationStatusDead).
+		Updates(map[string]interface{}{
+			"status":       status,
+			"retry_count":  0,
+			"scheduled_at": now,
+			"dead_at":      nil,
+			"updated_at":   now,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// Discard 捨棄死信通知
+func (r *NotificationQueueRepository) Discard(ctx context.Context, id, centerID uint, now time.Time) (bool, error) {
+	result := r.app.MySQL.WDB.WithContext(ctx).
+		Model(&models.NotificationQueue{}).
+		Where("id = ? AND center_id = ? AND status = ?", id, centerID, models.NotificationStatusDead).
+		Updates(map[string]interface{}{
+			"status":     models.NotificationStatusDiscarded,
+			"updated_at": now,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// CountByStatus 各狀態的通知數量
+func (r *NotificationQueueRepository) CountByStatus(ctx context.Context) (map[string]int64, error) {
+	var rows []struct {
+		Status string
+		Count  int64
+	}
+	err := r.app.MySQL.RDB.WithContext(ctx).
+		Model(&models.NotificationQueue{}).
+		Select("status, COUNT(*) AS count").
+		Group("status").
+		Scan(&rows).Error
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, err
+}
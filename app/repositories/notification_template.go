@@ -0,0 +1,34 @@
+package repositories
+
+import (
+	"context"
+	"timeLedger/app"
+	"timeLedger/app/models"
+)
+
+type NotificationTemplateRepository struct {
+	GenericRepository[models.NotificationTemplate]
+	app *app.App
+}
+
+func NewNotificationTemplateRepository(app *app.App) *NotificationTemplateRepository {
+	return &NotificationTemplateRepository{
+		GenericRepository: NewGenericRepository[models.NotificationTemplate](app.MySQL.RDB, app.MySQL.WDB),
+		app:               app,
+	}
+}
+
+// ListByCenterID 取得中心所有自訂範本
+func (rp *NotificationTemplateRepository) ListByCenterID(ctx context.Context, centerID uint) ([]models.NotificationTemplate, error) {
+	return rp.Find(ctx, "center_id = ?", centerID)
+}
+
+// ListActive 取得中心指定管道與語系啟用中的自訂範本
+func (rp *NotificationTemplateRepository) ListActive(ctx context.Context, centerID uint, channel, locale string) ([]models.NotificationTemplate, error) {
+	return rp.Find(ctx, "center_id = ? AND channel = ? AND locale = ? AND is_active = ?", centerID, channel, locale, true)
+}
+
+// GetByKey 依類型、管道與語系取得中心的自訂範本
+func (rp *NotificationTemplateRepository) GetByKey(ctx context.Context, centerID uint, templateType, channel, locale string) (models.NotificationTemplate, error) {
+	return rp.First(ctx, "center_id = ? AND type = ? AND channel = ? AND locale = ?", centerID, templateType, channel, locale)
+}
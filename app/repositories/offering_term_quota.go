@@ -0,0 +1,34 @@
+package repositories
+
+import (
+	"context"
+	"timeLedger/app"
+	"timeLedger/app/models"
+)
+
+type OfferingTermQuotaRepository struct {
+	GenericRepository[models.OfferingTermQuota]
+	app *app.App
+}
+
+func NewOfferingTermQuotaRepository(app *app.App) *OfferingTermQuotaRepository {
+	return &OfferingTermQuotaRepository{
+		GenericRepository: NewGenericRepository[models.OfferingTermQuota](app.MySQL.RDB, app.MySQL.WDB),
+		app:               app,
+	}
+}
+
+// GetByOfferingAndTerm 取得班別在指定學期的堂數設定
+func (rp *OfferingTermQuotaRepository) GetByOfferingAndTerm(ctx context.Context, offeringID, termID uint) (models.OfferingTermQuota, error) {
+	return rp.First(ctx, "offering_id = ? AND term_id = ?", offeringID, termID)
+}
+
+// ListByTerm 取得學期內所有班別的堂數設定
+func (rp *OfferingTermQuotaRepository) ListByTerm(ctx context.Context, centerID, termID uint) ([]models.OfferingTermQuota, error) {
+	return rp.FindWithCenterScope(ctx, centerID, "term_id = ?", termID)
+}
+
+// UpsertPlannedSessions 新增或更新班別在學期的預計堂數
+func (rp *OfferingTermQuotaRepository) UpsertPlannedSessions(ctx context.Context, quota models.OfferingTermQuota) error {
+	return rp.Upsert(ctx, quota, "offering_id,term_id", []string{"planned_sessions", "updated_at"})
+}
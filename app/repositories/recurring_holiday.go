@@ -0,0 +1,23 @@
+package repositories
+
+import (
+	"context"
+	"timeLedger/app"
+	"timeLedger/app/models"
+)
+
+type RecurringHolidayRepository struct {
+	GenericRepository[models.RecurringHoliday]
+	app *app.App
+}
+
+func NewRecurringHolidayRepository(app *app.App) *RecurringHolidayRepository {
+	return &RecurringHolidayRepository{
+		GenericRepository: NewGenericRepository[models.RecurringHoliday](app.MySQL.RDB, app.MySQL.WDB),
+		app:               app,
+	}
+}
+
+func (r *RecurringHolidayRepository) ListByCenterID(ctx context.Context, centerID uint) ([]models.RecurringHoliday, error) {
+	return r.FindWithCenterScope(ctx, centerID)
+}
@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"context"
+	"time"
+	"timeLedger/app"
+	"timeLedger/app/models"
+)
+
+type RoomBookingRepository struct {
+	GenericRepository[models.RoomBooking]
+	app *app.App
+}
+
+func NewRoomBookingRepository(app *app.App) *RoomBookingRepository {
+	return &RoomBookingRepository{
+		GenericRepository: NewGenericRepository[models.RoomBooking](app.MySQL.RDB, app.MySQL.WDB),
+		app:               app,
+	}
+}
+
+// ListByCenterID 取得中心所有教室預約
+func (rp *RoomBookingRepository) ListByCenterID(ctx context.Context, centerID uint) ([]models.RoomBooking, error) {
+	var data []models.RoomBooking
+	err := rp.dbRead.WithContext(ctx).
+		Preload("Room").
+		Where("center_id = ?", centerID).
+		Order("start_date ASC, start_time ASC").
+		Find(&data).Error
+	return data, err
+}
+
+// ListOverlapping 取得可能佔用日期區間的預約（單次預約落在區間內，或每週預約的有效期間與區間重疊）
+// roomIDs 為空時不限教室
+func (rp *RoomBookingRepository) ListOverlapping(ctx context.Context, centerID uint, roomIDs []uint, start, end time.Time) ([]models.RoomBooking, error) {
+	startStr := start.Format("2006-01-02")
+	endStr := end.Format("2006-01-02")
+
+	query := rp.dbRead.WithContext(ctx).
+		Where("center_id = ?", centerID).
+		Where("start_date <= ?", endStr).
+		Where("((recurrence = ? AND start_date >= ?) OR (recurrence = ? AND (end_date IS NULL OR end_date >= ?)))",
+			models.RoomBookingRecurrenceNone, startStr, models.RoomBookingRecurrenceWeekly, startStr)
+	if len(roomIDs) > 0 {
+		query = query.Where("room_id IN ?", roomIDs)
+	}
+
+	var data []models.RoomBooking
+	err := query.Order("start_date ASC, start_time ASC").Find(&data).Error
+	return data, err
+}
+
+// ListWeeklyByRooms 取得教室在指定星期仍有效的每週預約（用於排課規則的衝突檢查）
+func (rp *RoomBookingRepository) ListWeeklyByRooms(ctx context.Context, centerID uint, roomIDs []uint, weekday int, from time.Time) ([]models.RoomBooking, error) {
+	var data []models.RoomBooking
+	err := rp.dbRead.WithContext(ctx).
+		Where("center_id = ? AND room_id IN ?", centerID, roomIDs).
+		Where("recurrence = ? AND weekday = ?", models.RoomBookingRecurrenceWeekly, weekday).
+		Where("(end_date IS NULL OR end_date >= ?)", from.Format("2006-01-02")).
+		Find(&data).Error
+	return data, err
+}
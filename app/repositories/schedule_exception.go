@@ -125,3 +125,29 @@ func (rp *ScheduleExceptionRepository) GetByRuleIDsAndDateRange(ctx context.Cont
 func (rp *ScheduleExceptionRepository) ListByCenterID(ctx context.Context, centerID uint) ([]models.ScheduleException, error) {
 	return rp.FindWithCenterScope(ctx, centerID)
 }
+
+// ListReviewedByDateRange 取得審核時間落在區間內的例外單（用於審核時效統計）
+func (rp *ScheduleExceptionRepository) ListReviewedByDateRange(ctx context.Context, centerID uint, start, end time.Time) ([]models.ScheduleException, error) {
+	var data []models.ScheduleException
+	err := rp.dbRead.WithContext(ctx).
+		Where("center_id = ? AND reviewed_at IS NOT NULL", centerID).
+		Where("reviewed_at >= ? AND reviewed_at < ?", start, end).
+		Find(&data).Error
+	return data, err
+}
+
+// ListApprovedReschedulesByRoom 取得調課後時段落在日期範圍內、使用該教室的已核准調課
+// 未指定新教室的調課沿用原規則的教室
+func (rp *ScheduleExceptionRepository) ListApprovedReschedulesByRoom(ctx context.Context, centerID, roomID uint, start, end time.Time) ([]models.ScheduleException, error) {
+	var data []models.ScheduleException
+	err := rp.dbRead.WithContext(ctx).
+		Preload("Rule").
+		Where("center_id = ? AND exception_type = ? AND status = ?", centerID, "RESCHEDULE", "APPROVED").
+		Where("new_start_at IS NOT NULL AND new_end_at IS NOT NULL").
+		Where("DATE(new_start_at) >= ? AND DATE(new_start_at) <= ?", start.Format("2006-01-02"), end.Format("2006-01-02")).
+		Where("(new_room_id = ? OR (new_room_id IS NULL AND rule_id IN (?)))", roomID,
+			rp.dbRead.Model(&models.ScheduleRule{}).Select("id").Where("room_id = ?", roomID)).
+		Order("new_start_at ASC").
+		Find(&data).Error
+	return data, err
+}
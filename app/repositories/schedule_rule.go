@@ -54,7 +54,12 @@ func (rp *ScheduleRuleRepository) Transaction(ctx context.Context, fn func(txRep
 }
 
 func (rp *ScheduleRuleRepository) GetByIDAndCenterID(ctx context.Context, id uint, centerID uint) (models.ScheduleRule, error) {
-	return rp.GetByIDWithCenterScope(ctx, id, centerID)
+	var data models.ScheduleRule
+	err := rp.dbRead.WithContext(ctx).
+		Scopes(PreloadRuleAssignees).
+		Where("id = ? AND center_id = ?", id, centerID).
+		First(&data).Error
+	return data, err
 }
 
 func (rp *ScheduleRuleRepository) ListByTeacherID(ctx context.Context, teacherID uint, centerID uint) ([]models.ScheduleRule, error) {
@@ -63,14 +68,20 @@ func (rp *ScheduleRuleRepository) ListByTeacherID(ctx context.Context, teacherID
 		Preload("Offering").
 		Preload("Room").
 		Preload("Teacher").
-		Where("teacher_id = ? AND center_id = ?", teacherID, centerID).
+		Scopes(PreloadRuleAssignees, RuleHasTeacher(teacherID)).
+		Where("center_id = ?", centerID).
 		Order("weekday ASC, start_time ASC").
 		Find(&data).Error
 	return data, err
 }
 
 func (rp *ScheduleRuleRepository) ListByRoomID(ctx context.Context, roomID uint, centerID uint) ([]models.ScheduleRule, error) {
-	return rp.FindWithCenterScope(ctx, centerID, "room_id = ?", roomID)
+	var data []models.ScheduleRule
+	err := rp.app.MySQL.RDB.WithContext(ctx).
+		Scopes(PreloadRuleAssignees, RuleHasRoom(roomID)).
+		Where("center_id = ?", centerID).
+		Find(&data).Error
+	return data, err
 }
 
 func (rp *ScheduleRuleRepository) ListByOfferingID(ctx context.Context, offeringID uint) ([]models.ScheduleRule, error) {
@@ -83,6 +94,7 @@ func (rp *ScheduleRuleRepository) ListByOfferingIDWithPreload(ctx context.Contex
 		Preload("Offering").
 		Preload("Room").
 		Preload("Teacher").
+		Scopes(PreloadRuleAssignees).
 		Where("offering_id = ?", offeringID).
 		Order("effective_range ASC").
 		Find(&data).Error
@@ -98,7 +110,7 @@ func (rp *ScheduleRuleRepository) GetLastSessionByTeacherAndWeekday(ctx context.
 	var rule models.ScheduleRule
 	err := rp.app.MySQL.RDB.WithContext(ctx).
 		Where("center_id = ?", centerID).
-		Where("teacher_id = ?", teacherID).
+		Scopes(RuleHasTeacher(teacherID)).
 		Where("weekday = ?", weekdayVal).
 		Where("end_time <= ?", beforeTimeStr).
 		Order("end_time DESC").
@@ -119,7 +131,7 @@ func (rp *ScheduleRuleRepository) GetLastSessionByRoomAndWeekday(ctx context.Con
 	var rule models.ScheduleRule
 	err := rp.app.MySQL.RDB.WithContext(ctx).
 		Where("center_id = ?", centerID).
-		Where("room_id = ?", roomID).
+		Scopes(RuleHasRoom(roomID)).
 		Where("weekday = ?", weekdayVal).
 		Where("end_time <= ?", beforeTimeStr).
 		Order("end_time DESC").
@@ -148,12 +160,42 @@ func (rp *ScheduleRuleRepository) ListByCenterID(ctx context.Context, centerID u
 		Preload("Offering").
 		Preload("Room").
 		Preload("Teacher").
+		Scopes(PreloadRuleAssignees).
+		Where("center_id = ?", centerID).
+		Order("weekday ASC, start_time ASC").
+		Find(&data).Error
+	return data, err
+}
+
+// ListByCenterIDWithDeleted 取得中心所有規則（含已刪除），供歷史統計回補使用
+func (rp *ScheduleRuleRepository) ListByCenterIDWithDeleted(ctx context.Context, centerID uint) ([]models.ScheduleRule, error) {
+	var data []models.ScheduleRule
+	err := rp.app.MySQL.RDB.WithContext(ctx).
+		Unscoped().
+		Scopes(PreloadRuleAssignees).
 		Where("center_id = ?", centerID).
 		Order("weekday ASC, start_time ASC").
 		Find(&data).Error
 	return data, err
 }
 
+// ListByIDsWithDeleted 依 ID 取得規則（含已刪除），供補課沿用原規則的班別資訊
+func (rp *ScheduleRuleRepository) ListByIDsWithDeleted(ctx context.Context, ids []uint) ([]models.ScheduleRule, error) {
+	var data []models.ScheduleRule
+	if len(ids) == 0 {
+		return data, nil
+	}
+	err := rp.app.MySQL.RDB.WithContext(ctx).
+		Unscoped().
+		Preload("Offering").
+		Preload("Room").
+		Preload("Teacher").
+		Scopes(PreloadRuleAssignees).
+		Where("id IN ?", ids).
+		Find(&data).Error
+	return data, err
+}
+
 // CheckPersonalEventConflict 檢查個人行程是否與排課規則衝突
 func (rp *ScheduleRuleRepository) CheckPersonalEventConflict(ctx context.Context, teacherID, centerID uint, startAt, endAt time.Time) ([]models.ScheduleRule, error) {
 	// 取得教師在該中心的所有規則
@@ -189,6 +231,72 @@ func (rp *ScheduleRuleRepository) timesOverlap(start1, end1, start2, end2 string
 	return start1 < end2 && end1 > start2
 }
 
+// ReplaceAssignees 以新的協同老師與其他教室取代規則現有的設定
+// 主教老師與主要教室不會寫入，避免與 TeacherID、RoomID 重複；寫入後同步更新 rule 的關聯欄位
+func (rp *ScheduleRuleRepository) ReplaceAssignees(ctx context.Context, rule *models.ScheduleRule, teachers []models.TeacherAssignment, roomIDs []uint) error {
+	if err := rp.DeleteAssignees(ctx, rule.ID); err != nil {
+		return err
+	}
+
+	db := rp.dbWrite.WithContext(ctx)
+	now := time.Now()
+	ruleTeachers := make([]models.ScheduleRuleTeacher, 0, len(teachers))
+	seenTeachers := make(map[uint]bool, len(teachers))
+	for _, t := range teachers {
+		if seenTeachers[t.TeacherID] || (rule.TeacherID != nil && t.TeacherID == *rule.TeacherID) {
+			continue
+		}
+		seenTeachers[t.TeacherID] = true
+		ruleTeachers = append(ruleTeachers, models.ScheduleRuleTeacher{
+			CenterID:  rule.CenterID,
+			RuleID:    rule.ID,
+			TeacherID: t.TeacherID,
+			Role:      t.Role,
+			CreatedAt: now,
+			UpdatedAt: now,
+		})
+	}
+	if len(ruleTeachers) > 0 {
+		if err := db.Create(&ruleTeachers).Error; err != nil {
+			return err
+		}
+	}
+
+	ruleRooms := make([]models.ScheduleRuleRoom, 0, len(roomIDs))
+	seenRooms := make(map[uint]bool, len(roomIDs))
+	for _, roomID := range roomIDs {
+		if seenRooms[roomID] || roomID == rule.RoomID {
+			continue
+		}
+		seenRooms[roomID] = true
+		ruleRooms = append(ruleRooms, models.ScheduleRuleRoom{
+			CenterID:  rule.CenterID,
+			RuleID:    rule.ID,
+			RoomID:    roomID,
+			CreatedAt: now,
+			UpdatedAt: now,
+		})
+	}
+	if len(ruleRooms) > 0 {
+		if err := db.Create(&ruleRooms).Error; err != nil {
+			return err
+		}
+	}
+
+	rule.AdditionalTeachers = ruleTeachers
+	rule.AdditionalRooms = ruleRooms
+	return nil
+}
+
+// DeleteAssignees 刪除規則的協同老師與其他教室
+func (rp *ScheduleRuleRepository) DeleteAssignees(ctx context.Context, ruleID uint) error {
+	db := rp.dbWrite.WithContext(ctx)
+	if err := db.Where("rule_id = ?", ruleID).Delete(&models.ScheduleRuleTeacher{}).Error; err != nil {
+		return err
+	}
+	return db.Where("rule_id = ?", ruleID).Delete(&models.ScheduleRuleRoom{}).Error
+}
+
 // DeleteByIDAndCenterID 刪除規則（帶 center_id 檢查）
 func (rp *ScheduleRuleRepository) DeleteByIDAndCenterID(ctx context.Context, id, centerID uint) error {
 	return rp.DeleteByIDWithCenterScope(ctx, id, centerID)
@@ -0,0 +1,92 @@
+package repositories
+
+import (
+	"context"
+	"strings"
+	"timeLedger/app"
+	"timeLedger/app/models"
+
+	"gorm.io/gorm"
+)
+
+// RuleHasTeacher 篩選老師擔任主教或協同授課的排課規則
+func RuleHasTeacher(teacherID uint) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("(schedule_rules.teacher_id = ? OR schedule_rules.id IN (SELECT rule_id FROM schedule_rule_teachers WHERE teacher_id = ?))", teacherID, teacherID)
+	}
+}
+
+// RuleHasRoom 篩選使用指定教室（主要教室或其他教室）的排課規則
+func RuleHasRoom(roomID uint) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("(schedule_rules.room_id = ? OR schedule_rules.id IN (SELECT rule_id FROM schedule_rule_rooms WHERE room_id = ?))", roomID, roomID)
+	}
+}
+
+// RuleHasAnyAssignee 篩選任一老師或任一教室有參與的排課規則
+func RuleHasAnyAssignee(teacherIDs, roomIDs []uint) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		var conditions []string
+		var args []interface{}
+		if len(teacherIDs) > 0 {
+			conditions = append(conditions,
+				"schedule_rules.teacher_id IN ?",
+				"schedule_rules.id IN (SELECT rule_id FROM schedule_rule_teachers WHERE teacher_id IN ?)")
+			args = append(args, teacherIDs, teacherIDs)
+		}
+		if len(roomIDs) > 0 {
+			conditions = append(conditions,
+				"schedule_rules.room_id IN ?",
+				"schedule_rules.id IN (SELECT rule_id FROM schedule_rule_rooms WHERE room_id IN ?)")
+			args = append(args, roomIDs, roomIDs)
+		}
+		if len(conditions) == 0 {
+			return db.Where("1 = 0")
+		}
+		return db.Where("("+strings.Join(conditions, " OR ")+")", args...)
+	}
+}
+
+// PreloadRuleAssignees 預載規則的協同老師與其他教室
+func PreloadRuleAssignees(db *gorm.DB) *gorm.DB {
+	return db.Preload("AdditionalTeachers").Preload("AdditionalRooms")
+}
+
+type ScheduleRuleTeacherRepository struct {
+	GenericRepository[models.ScheduleRuleTeacher]
+	app *app.App
+}
+
+func NewScheduleRuleTeacherRepository(app *app.App) *ScheduleRuleTeacherRepository {
+	return &ScheduleRuleTeacherRepository{
+		GenericRepository: NewGenericRepository[models.ScheduleRuleTeacher](app.MySQL.RDB, app.MySQL.WDB),
+		app:               app,
+	}
+}
+
+// ListByRuleID 取得規則的協同老師
+func (rp *ScheduleRuleTeacherRepository) ListByRuleID(ctx context.Context, ruleID uint) ([]models.ScheduleRuleTeacher, error) {
+	return rp.Find(ctx, "rule_id = ?", ruleID)
+}
+
+// ListByTeacherID 取得老師擔任協同授課的紀錄
+func (rp *ScheduleRuleTeacherRepository) ListByTeacherID(ctx context.Context, teacherID, centerID uint) ([]models.ScheduleRuleTeacher, error) {
+	return rp.FindWithCenterScope(ctx, centerID, "teacher_id = ?", teacherID)
+}
+
+type ScheduleRuleRoomRepository struct {
+	GenericRepository[models.ScheduleRuleRoom]
+	app *app.App
+}
+
+func NewScheduleRuleRoomRepository(app *app.App) *ScheduleRuleRoomRepository {
+	return &ScheduleRuleRoomRepository{
+		GenericRepository: NewGenericRepository[models.ScheduleRuleRoom](app.MySQL.RDB, app.MySQL.WDB),
+		app:               app,
+	}
+}
+
+// ListByRuleID 取得規則的其他教室
+func (rp *ScheduleRuleRoomRepository) ListByRuleID(ctx context.Context, ruleID uint) ([]models.ScheduleRuleRoom, error) {
+	return rp.Find(ctx, "rule_id = ?", ruleID)
+}
@@ -2,7 +2,6 @@ package repositories
 
 import (
 	"context"
-	"time"
 	"timeLedger/app"
 	"timeLedger/app/models"
 )
@@ -37,17 +36,3 @@ func (rp *TimetableCellRepository) DeleteByTemplateID(ctx context.Context, templ
 func (rp *TimetableCellRepository) Delete(ctx context.Context, id uint) error {
 	return rp.DeleteByID(ctx, id)
 }
-
-// BatchUpdateSortOrder 批次更新格子的排序順序
-func (rp *TimetableCellRepository) BatchUpdateSortOrder(ctx context.Context, cells []ReorderCellRequest) error {
-	for _, cell := range cells {
-		updates := map[string]interface{}{
-			"sort_order": cell.SortOrder,
-			"updated_at": time.Now(),
-		}
-		if err := rp.UpdateFields(ctx, cell.ID, updates); err != nil {
-			return err
-		}
-	}
-	return nil
-}
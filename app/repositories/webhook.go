@@ -0,0 +1,123 @@
+package repositories
+
+import (
+	"context"
+	"time"
+	"timeLedger/app"
+	"timeLedger/app/models"
+
+	"gorm.io/gorm/clause"
+)
+
+type WebhookSubscriptionRepository struct {
+	GenericRepository[models.WebhookSubscription]
+	app *app.App
+}
+
+func NewWebhookSubscriptionRepository(app *app.App) *WebhookSubscriptionRepository {
+	return &WebhookSubscriptionRepository{
+		GenericRepository: NewGenericRepository[models.WebhookSubscription](app.MySQL.RDB, app.MySQL.WDB),
+		app:               app,
+	}
+}
+
+// ListByCenterID 取得中心的所有訂閱
+func (rp *WebhookSubscriptionRepository) ListByCenterID(ctx context.Context, centerID uint) ([]models.WebhookSubscription, error) {
+	return rp.Find(ctx, "center_id = ?", centerID)
+}
+
+// ListActiveByCenterID 取得中心啟用中的訂閱
+func (rp *WebhookSubscriptionRepository) ListActiveByCenterID(ctx context.Context, centerID uint) ([]models.WebhookSubscription, error) {
+	return rp.Find(ctx, "center_id = ? AND is_active = ?", centerID, true)
+}
+
+// GetByIDAndCenterID 取得中心的訂閱
+func (rp *WebhookSubscriptionRepository) GetByIDAndCenterID(ctx context.Context, id, centerID uint) (models.WebhookSubscription, error) {
+	return rp.GetByIDWithCenterScope(ctx, id, centerID)
+}
+
+type WebhookDeliveryRepository struct {
+	GenericRepository[models.WebhookDelivery]
+	app *app.App
+}
+
+func NewWebhookDeliveryRepository(app *app.App) *WebhookDeliveryRepository {
+	return &WebhookDeliveryRepository{
+		GenericRepository: NewGenericRepository[models.WebhookDelivery](app.MySQL.RDB, app.MySQL.WDB),
+		app:               app,
+	}
+}
+
+// CreateIfAbsent 寫入投遞紀錄，同一訂閱同一事件已存在時不重複建立
+func (rp *WebhookDeliveryRepository) CreateIfAbsent(ctx context.Context, delivery *models.WebhookDelivery) (bool, error) {
+	result := rp.dbWrite.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(delivery)
+	return result.RowsAffected == 1, result.Error
+}
+
+// ListDue 取得到期待投遞的紀錄
+func (rp *WebhookDeliveryRepository) ListDue(ctx context.Context, now time.Time, limit int) ([]models.WebhookDelivery, error) {
+	var data []models.WebhookDelivery
+	err := rp.dbWrite.WithContext(ctx).
+		Where("status = ? AND next_attempt_at <= ?", models.WebhookDeliveryPending, now).
+		Order("id ASC").
+		Limit(limit).
+		Find(&data).Error
+	return data, err
+}
+
+// Claim 以條件更新搶占投遞，避免多個 worker 同時送出同一筆
+func (rp *WebhookDeliveryRepository) Claim(ctx context.Context, id uint, now, leaseUntil time.Time) (bool, error) {
+	result := rp.dbWrite.WithContext(ctx).Model(&models.WebhookDelivery{}).
+		Where("id = ? AND status = ? AND next_attempt_at <= ?", id, models.WebhookDeliveryPending, now).
+		Updates(map[string]interface{}{
+			"next_attempt_at": leaseUntil,
+			"updated_at":      now,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// RecordAttempt 記錄一次投遞結果與下一步狀態
+func (rp *WebhookDeliveryRepository) RecordAttempt(ctx context.Context, delivery models.WebhookDelivery) error {
+	return rp.UpdateFields(ctx, delivery.ID, map[string]interface{}{
+		"status":          delivery.Status,
+		"attempts":        delivery.Attempts,
+		"response_status": delivery.ResponseStatus,
+		"last_error":      delivery.LastError,
+		"duration_ms":     delivery.DurationMs,
+		"next_attempt_at": delivery.NextAttemptAt,
+		"delivered_at":    delivery.DeliveredAt,
+		"updated_at":      delivery.UpdatedAt,
+	})
+}
+
+// Requeue 將失敗的投遞重新排入佇列並重算重試次數
+func (rp *WebhookDeliveryRepository) Requeue(ctx context.Context, id, centerID uint, now time.Time) (bool, error) {
+	result := rp.dbWrite.WithContext(ctx).Model(&models.WebhookDelivery{}).
+		Where("id = ? AND center_id = ? AND status = ?", id, centerID, models.WebhookDeliveryFailed).
+		Updates(map[string]interface{}{
+			"status":          models.WebhookDeliveryPending,
+			"attempts":        0,
+			"next_attempt_at": now,
+			"updated_at":      now,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// ListBySubscription 分頁取得訂閱的投遞紀錄，status 為空表示全部
+func (rp *WebhookDeliveryRepository) ListBySubscription(ctx context.Context, centerID, subscriptionID uint, status string, page, limit int) ([]models.WebhookDelivery, int64, error) {
+	if status != "" {
+		return rp.FindPaged(ctx, page, limit, "id DESC", "center_id = ? AND subscription_id = ? AND status = ?", centerID, subscriptionID, status)
+	}
+	return rp.FindPaged(ctx, page, limit, "id DESC", "center_id = ? AND subscription_id = ?", centerID, subscriptionID)
+}
+
+// GetByIDAndCenterID 取得中心的投遞紀錄
+func (rp *WebhookDeliveryRepository) GetByIDAndCenterID(ctx context.Context, id, centerID uint) (models.WebhookDelivery, error) {
+	var data models.WebhookDelivery
+	err := rp.dbWrite.WithContext(ctx).
+		Where("id = ? AND center_id = ?", id, centerID).
+		First(&data).Error
+	return data, err
+}
@@ -80,6 +80,9 @@ type CreateRuleRequest struct {
 	EndDate        *string `json:"end_date"`
 	Status         string  `json:"status"` // 預設為 CONFIRMED
 	OverrideBuffer bool    `json:"override_buffer"`
+	// 協同授課老師與同時使用的其他教室，主教老師與主要教室仍使用 TeacherID、RoomID
+	AdditionalTeachers []models.TeacherAssignment `json:"additional_teachers"`
+	AdditionalRoomIDs  []uint                     `json:"additional_room_ids"`
 }
 
 // Validate 建立規則時的額外驗證
@@ -109,6 +112,11 @@ type UpdateRuleRequest struct {
 	Status         string   `json:"status"`
 	// 更新模式：SINGLE - 只修改這一天，FUTURE - 修改這天及之後，ALL - 修改所有
 	UpdateMode string `json:"update_mode"`
+	// 協同授課老師與其他教室，未提供時維持原設定，提供空陣列表示清除
+	AdditionalTeachers *[]models.TeacherAssignment `json:"additional_teachers"`
+	AdditionalRoomIDs  *[]uint                     `json:"additional_room_ids"`
+	// 讀取時的規則版本，亦可改用 If-Match header；未提供時不比對
+	Version *uint `json:"version"`
 }
 
 // Validate 更新規則時的額外驗證
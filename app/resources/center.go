@@ -20,31 +20,38 @@ func NewCenterResource(appInstance *app.App) *CenterResource {
 
 // CenterResponse 中心響應結構
 type CenterResponse struct {
-	ID        uint           `json:"id"`
-	Name      string         `json:"name"`
-	PlanLevel string         `json:"plan_level"`
-	Settings  CenterSettings `json:"settings"`
-	CreatedAt time.Time      `json:"created_at"`
+	ID              uint           `json:"id"`
+	Name            string         `json:"name"`
+	PlanLevel       string         `json:"plan_level"`
+	Settings        CenterSettings `json:"settings"`
+	SettingsVersion uint           `json:"settings_version"` // 中心設定版本，更新設定時以 If-Match 帶回
+	CreatedAt       time.Time      `json:"created_at"`
 }
 
 // CenterSettings 中心設置響應
 type CenterSettings struct {
-	AllowPublicRegister   bool   `json:"allow_public_register"`
-	DefaultLanguage       string `json:"default_language"`
-	ExceptionLeadDays     int    `json:"exception_lead_days"`
-	DefaultCourseDuration int    `json:"default_course_duration"`
-	OperatingStartTime    string `json:"operating_start_time"`
-	OperatingEndTime      string `json:"operating_end_time"`
+	AllowPublicRegister   bool                           `json:"allow_public_register"`
+	DefaultLanguage       string                         `json:"default_language"`
+	ExceptionLeadDays     int                            `json:"exception_lead_days"`
+	DefaultCourseDuration int                            `json:"default_course_duration"`
+	OperatingStartTime    string                         `json:"operating_start_time"`
+	OperatingEndTime      string                         `json:"operating_end_time"`
+	FollowMakeupWorkdays  bool                           `json:"follow_makeup_workdays"`
+	WeeklyOperatingHours  []models.WeekdayOperatingHours `json:"weekly_operating_hours"`
+	ReminderOffsets       []int                          `json:"reminder_offsets"` // 課前提醒提前分鐘數
 }
 
 // CenterSettingsResponse 僅包含設置的響應
 type CenterSettingsResponse struct {
-	AllowPublicRegister   bool   `json:"allow_public_register"`
-	DefaultLanguage       string `json:"default_language"`
-	ExceptionLeadDays     int    `json:"exception_lead_days"`
-	DefaultCourseDuration int    `json:"default_course_duration"`
-	OperatingStartTime    string `json:"operating_start_time"`
-	OperatingEndTime      string `json:"operating_end_time"`
+	AllowPublicRegister   bool                           `json:"allow_public_register"`
+	DefaultLanguage       string                         `json:"default_language"`
+	ExceptionLeadDays     int                            `json:"exception_lead_days"`
+	DefaultCourseDuration int                            `json:"default_course_duration"`
+	OperatingStartTime    string                         `json:"operating_start_time"`
+	OperatingEndTime      string                         `json:"operating_end_time"`
+	FollowMakeupWorkdays  bool                           `json:"follow_makeup_workdays"`
+	WeeklyOperatingHours  []models.WeekdayOperatingHours `json:"weekly_operating_hours"`
+	ReminderOffsets       []int                          `json:"reminder_offsets"` // 課前提醒提前分鐘數
 }
 
 // ToCenterResponse 將中心模型轉換為響應格式
@@ -60,8 +67,12 @@ func (r *CenterResource) ToCenterResponse(center models.Center) *CenterResponse
 			DefaultCourseDuration: center.Settings.DefaultCourseDuration,
 			OperatingStartTime:    center.Settings.OperatingStartTime,
 			OperatingEndTime:      center.Settings.OperatingEndTime,
+			FollowMakeupWorkdays:  center.Settings.FollowMakeupWorkdays,
+			WeeklyOperatingHours:  center.Settings.WeeklyOperatingHours,
+			ReminderOffsets:       center.Settings.EffectiveReminderOffsets(),
 		},
-		CreatedAt: center.CreatedAt,
+		SettingsVersion: center.SettingsVersion,
+		CreatedAt:       center.CreatedAt,
 	}
 }
 
@@ -87,5 +98,8 @@ func (r *CenterResource) ToSettingsResponse(settings models.CenterSettings) *Cen
 		DefaultCourseDuration: settings.DefaultCourseDuration,
 		OperatingStartTime:    settings.OperatingStartTime,
 		OperatingEndTime:      settings.OperatingEndTime,
+		FollowMakeupWorkdays:  settings.FollowMakeupWorkdays,
+		WeeklyOperatingHours:  settings.WeeklyOperatingHours,
+		ReminderOffsets:       settings.EffectiveReminderOffsets(),
 	}
 }
@@ -20,15 +20,17 @@ func NewCourseResource(appInstance *app.App) *CourseResource {
 
 // CourseResponse 課程響應結構
 type CourseResponse struct {
-	ID               uint      `json:"id"`
-	CenterID         uint      `json:"center_id"`
-	Name             string    `json:"name"`
-	DefaultDuration  int       `json:"default_duration"`
-	ColorHex         string    `json:"color_hex"`
-	RoomBufferMin    int       `json:"room_buffer_min"`
-	TeacherBufferMin int       `json:"teacher_buffer_min"`
-	IsActive         bool      `json:"is_active"`
-	CreatedAt        time.Time `json:"created_at"`
+	ID               uint                `json:"id"`
+	CenterID         uint                `json:"center_id"`
+	Name             string              `json:"name"`
+	DefaultDuration  int                 `json:"default_duration"`
+	ColorHex         string              `json:"color_hex"`
+	RoomBufferMin    int                 `json:"room_buffer_min"`
+	TeacherBufferMin int                 `json:"teacher_buffer_min"`
+	RequiredFeatures models.RoomFeatures `json:"required_features"`
+	MinCapacity      int                 `json:"min_capacity"`
+	IsActive         bool                `json:"is_active"`
+	CreatedAt        time.Time           `json:"created_at"`
 }
 
 // ToCourseResponse 將課程模型轉換為響應格式
@@ -41,6 +43,8 @@ func (r *CourseResource) ToCourseResponse(course models.Course) *CourseResponse
 		ColorHex:         course.ColorHex,
 		RoomBufferMin:    course.RoomBufferMin,
 		TeacherBufferMin: course.TeacherBufferMin,
+		RequiredFeatures: course.RequiredFeatures,
+		MinCapacity:      course.MinCapacity,
 		IsActive:         course.IsActive,
 		CreatedAt:        course.CreatedAt,
 	}
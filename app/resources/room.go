@@ -20,12 +20,13 @@ func NewRoomResource(appInstance *app.App) *RoomResource {
 
 // RoomResponse 教室響應結構
 type RoomResponse struct {
-	ID        uint      `json:"id"`
-	CenterID  uint      `json:"center_id"`
-	Name      string    `json:"name"`
-	Capacity  int       `json:"capacity"`
-	IsActive  bool      `json:"is_active"`
-	CreatedAt time.Time `json:"created_at"`
+	ID        uint                `json:"id"`
+	CenterID  uint                `json:"center_id"`
+	Name      string              `json:"name"`
+	Capacity  int                 `json:"capacity"`
+	Features  models.RoomFeatures `json:"features"`
+	IsActive  bool                `json:"is_active"`
+	CreatedAt time.Time           `json:"created_at"`
 }
 
 // ToRoomResponse 將教室模型轉換為響應格式
@@ -35,6 +36,7 @@ func (r *RoomResource) ToRoomResponse(room models.Room) *RoomResponse {
 		CenterID:  room.CenterID,
 		Name:      room.Name,
 		Capacity:  room.Capacity,
+		Features:  room.Features,
 		IsActive:  room.IsActive,
 		CreatedAt: room.CreatedAt,
 	}
@@ -21,8 +21,12 @@ type ScheduleRuleResponse struct {
 	EffectiveTo    string    `json:"effective_to"`
 	SuspendedDates []string  `json:"suspended_dates"` // 停課日期列表
 	Status         string    `json:"status"`          // 狀態: PLANNED(預計), CONFIRMED(已開課), SUSPENDED(停課), ARCHIVED(歸檔)
+	Version        uint      `json:"version"`         // 樂觀鎖版本，更新時以 If-Match 帶回
 	CreatedAt      time.Time `json:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at"`
+	// 協同授課老師（含主教）與使用的所有教室（含主要教室）
+	Teachers []models.TeacherAssignment `json:"teachers,omitempty"`
+	RoomIDs  []uint                     `json:"room_ids,omitempty"`
 }
 
 // ExpandedScheduleResponse 展開後的課表響應
@@ -210,6 +214,7 @@ func (r *ScheduleResource) ToRuleResponse(rule models.ScheduleRule) *ScheduleRul
 		EffectiveTo:    rule.EffectiveRange.EndDate.Format("2006-01-02"),
 		SuspendedDates: suspendedDatesStr,
 		Status:         rule.Status,
+		Version:        rule.Version,
 		CreatedAt:      rule.CreatedAt,
 		UpdatedAt:      rule.UpdatedAt,
 	}
@@ -243,9 +248,19 @@ func (r *ScheduleResource) ToExceptionResponse(exception models.ScheduleExceptio
 
 // MatrixViewResponse 矩陣視圖響應
 type MatrixViewResponse struct {
-	TimeSlots []int            `json:"time_slots"` // 橫軸時段，如 [9, 10, 11, ...]
-	Resources []MatrixResource `json:"resources"`  // 縱軸資源（老師或教室）
-	DateRange MatrixDateRange  `json:"date_range"`
+	TimeSlots  []int              `json:"time_slots"`  // 橫軸時段，如 [9, 10, 11, ...]（區間內各營業日的聯集）
+	DailyHours []MatrixDailyHours `json:"daily_hours"` // 每天的實際營業時間與時段
+	Resources  []MatrixResource   `json:"resources"`   // 縱軸資源（老師或教室）
+	DateRange  MatrixDateRange    `json:"date_range"`
+}
+
+// MatrixDailyHours 單日營業時間
+type MatrixDailyHours struct {
+	Date      string `json:"date"` // YYYY-MM-DD
+	OpenTime  string `json:"open_time"`
+	CloseTime string `json:"close_time"`
+	Closed    bool   `json:"closed"`
+	TimeSlots []int  `json:"time_slots"`
 }
 
 // MatrixDateRange 日期範圍
@@ -284,11 +299,21 @@ type MatrixItem struct {
 	IsHoliday     bool    `json:"is_holiday"`
 	HasException  bool    `json:"has_exception"`
 	ExceptionType string  `json:"exception_type,omitempty"`
-	IsSuspended   bool    `json:"is_suspended"`        // 是否為停課
-	Color         string  `json:"color,omitempty"`     // 課程顏色
-	Status        string  `json:"status"`              // 課程狀態: PLANNED(預計), CONFIRMED(已開課), SUSPENDED(停課), ARCHIVED(歸檔)
+	IsSuspended   bool    `json:"is_suspended"`           // 是否為停課
+	Color         string  `json:"color,omitempty"`        // 課程顏色
+	Status        string  `json:"status"`                 // 課程狀態: PLANNED(預計), CONFIRMED(已開課), SUSPENDED(停課), ARCHIVED(歸檔)
+	ItemType      string  `json:"item_type"`              // SESSION(課程), BOOKING(教室預約)
+	BookingID     uint    `json:"booking_id,omitempty"`   // 教室預約 ID
+	BookingType   string  `json:"booking_type,omitempty"` // MEETING, RENTAL, MAINTENANCE, OTHER
+	RenterName    string  `json:"renter_name,omitempty"`  // 對外租借的租借人
 }
 
+// 矩陣項目類型
+const (
+	MatrixItemTypeSession = "SESSION"
+	MatrixItemTypeBooking = "BOOKING"
+)
+
 // MatrixViewResource 矩陣視圖資源轉換
 type MatrixViewResource struct {
 	app *app.App
@@ -47,6 +47,11 @@ func (s *Server) RateLimitMiddleware() gin.HandlerFunc {
 	return services.RateLimitMiddleware(rateLimiter)
 }
 
+// IdempotencyMiddleware 冪等請求中介層（Idempotency-Key）
+func (s *Server) IdempotencyMiddleware() gin.HandlerFunc {
+	return services.IdempotencyMiddleware(services.NewIdempotencyService(s.app))
+}
+
 // 主要中介層
 func (s *Server) MainMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
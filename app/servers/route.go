@@ -20,32 +20,40 @@ type route struct {
 }
 
 type actions struct {
-	auth              *controllers.AuthController
-	teacher           *controllers.TeacherController
-	adminTeacher      *controllers.AdminTeacherController
-	adminCenter       *controllers.AdminCenterController
-	adminRoom         *controllers.AdminRoomController
-	adminCourse       *controllers.AdminCourseController
-	adminHoliday      *controllers.AdminHolidayController
-	adminTerm         *controllers.AdminTermController
-	teacherProfile    *controllers.TeacherProfileController
-	teacherSchedule   *controllers.TeacherScheduleController
-	teacherSession    *controllers.TeacherSessionController
-	teacherEvent      *controllers.TeacherEventController
-	teacherException  *controllers.TeacherExceptionController
-	teacherInvitation *controllers.TeacherInvitationController
-	geo               *controllers.GeoController
-	adminResource     *controllers.AdminResourceController
-	offering          *controllers.OfferingController
-	timetableTemplate *controllers.TimetableTemplateController
-	adminUser         *controllers.AdminUserController
-	scheduling        *controllers.SchedulingController
-	smartMatching     *controllers.SmartMatchingController
-	notification      *controllers.NotificationController
-	adminNotification *controllers.AdminNotificationController
-	export            *controllers.ExportController
-	lineBot           *controllers.LineBotController
-	r2Test            *controllers.R2TestController
+	auth                *controllers.AuthController
+	teacher             *controllers.TeacherController
+	adminTeacher        *controllers.AdminTeacherController
+	adminCenter         *controllers.AdminCenterController
+	adminRoom           *controllers.AdminRoomController
+	adminCourse         *controllers.AdminCourseController
+	adminHoliday        *controllers.AdminHolidayController
+	adminTerm           *controllers.AdminTermController
+	adminMakeup         *controllers.AdminMakeupController
+	adminOperatingHours *controllers.AdminOperatingHoursController
+	adminRoomBooking    *controllers.AdminRoomBookingController
+	adminDashboard      *controllers.AdminDashboardController
+	teacherProfile      *controllers.TeacherProfileController
+	teacherSchedule     *controllers.TeacherScheduleController
+	teacherSession      *controllers.TeacherSessionController
+	teacherEvent        *controllers.TeacherEventController
+	teacherException    *controllers.TeacherExceptionController
+	teacherInvitation   *controllers.TeacherInvitationController
+	geo                 *controllers.GeoController
+	adminResource       *controllers.AdminResourceController
+	offering            *controllers.OfferingController
+	timetableTemplate   *controllers.TimetableTemplateController
+	adminUser           *controllers.AdminUserController
+	scheduling          *controllers.SchedulingController
+	smartMatching       *controllers.SmartMatchingController
+	notification        *controllers.NotificationController
+	adminNotification   *controllers.AdminNotificationController
+	adminWebhook        *controllers.AdminWebhookController
+	adminNotifTemplate  *controllers.AdminNotificationTemplateController
+	adminLineGroup      *controllers.AdminLineGroupController
+	adminLineEvent      *controllers.AdminLineEventController
+	export              *controllers.ExportController
+	lineBot             *controllers.LineBotController
+	r2Test              *controllers.R2TestController
 }
 
 // 載入路由
@@ -182,6 +190,7 @@ func (s *Server) LoadRoutes() {
 		{http.MethodPost, "/api/v1/admin/scheduling/validate", s.action.scheduling.ValidateFull, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
 		// Dashboard
 		{http.MethodGet, "/api/v1/admin/dashboard/today-summary", s.action.scheduling.GetTodaySummary, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
+		{http.MethodGet, "/api/v1/admin/dashboard/kpis", s.action.adminDashboard.GetKPIs, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
 		{http.MethodGet, "/api/v1/admin/rules", s.action.scheduling.GetRules, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
 		{http.MethodPost, "/api/v1/admin/rules", s.action.scheduling.CreateRule, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
 		{http.MethodPut, "/api/v1/admin/rules/:ruleId", s.action.scheduling.UpdateRule, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
@@ -203,6 +212,7 @@ func (s *Server) LoadRoutes() {
 		{http.MethodPost, "/api/v1/admin/rooms", s.action.adminRoom.CreateRoom, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
 		{http.MethodPut, "/api/v1/admin/rooms/:room_id", s.action.adminRoom.UpdateRoom, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
 		{http.MethodGet, "/api/v1/admin/rooms/active", s.action.adminRoom.GetActiveRooms, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
+		{http.MethodGet, "/api/v1/admin/rooms/utilization", s.action.adminRoom.GetRoomUtilization, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
 		{http.MethodPatch, "/api/v1/admin/rooms/:room_id/toggle-active", s.action.adminRoom.ToggleRoomActive, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
 		// Admin - Resources (非 Room/Course 路由)
 		{http.MethodGet, "/api/v1/admin/courses", s.action.adminCourse.GetCourses, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
@@ -235,6 +245,14 @@ func (s *Server) LoadRoutes() {
 		{http.MethodPost, "/api/v1/admin/centers/:id/holidays", s.action.adminHoliday.CreateHoliday, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
 		{http.MethodDelete, "/api/v1/admin/centers/:id/holidays/:holiday_id", s.action.adminHoliday.DeleteHoliday, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
 		{http.MethodPost, "/api/v1/admin/centers/:id/holidays/bulk", s.action.adminHoliday.BulkCreateHolidays, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
+		{http.MethodGet, "/api/v1/admin/centers/:id/holidays/tw-calendar", s.action.adminHoliday.GetTaiwanCalendar, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
+		{http.MethodPost, "/api/v1/admin/centers/:id/holidays/import-tw", s.action.adminHoliday.ImportTaiwanHolidays, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
+		{http.MethodGet, "/api/v1/admin/centers/:id/makeup-workdays", s.action.adminHoliday.GetMakeupWorkdays, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
+		{http.MethodDelete, "/api/v1/admin/centers/:id/makeup-workdays/:workday_id", s.action.adminHoliday.DeleteMakeupWorkday, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
+		{http.MethodGet, "/api/v1/admin/centers/:id/recurring-holidays", s.action.adminHoliday.GetRecurringHolidays, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
+		{http.MethodPost, "/api/v1/admin/centers/:id/recurring-holidays", s.action.adminHoliday.CreateRecurringHoliday, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
+		{http.MethodGet, "/api/v1/admin/centers/:id/recurring-holidays/preview", s.action.adminHoliday.PreviewRecurringHolidays, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
+		{http.MethodDelete, "/api/v1/admin/centers/:id/recurring-holidays/:recurring_id", s.action.adminHoliday.DeleteRecurringHoliday, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
 
 		// Admin - Terms
 		{http.MethodGet, "/api/v1/admin/terms", s.action.adminTerm.GetTerms, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
@@ -245,6 +263,26 @@ func (s *Server) LoadRoutes() {
 		// Admin - Occupancy & Copy Rules
 		{http.MethodGet, "/api/v1/admin/occupancy/rules", s.action.adminTerm.GetOccupancyRules, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
 		{http.MethodPost, "/api/v1/admin/terms/copy-rules", s.action.adminTerm.CopyRules, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
+		// Admin - Makeup Sessions (補課)
+		{http.MethodGet, "/api/v1/admin/offerings/:offering_id/terms/:term_id/quota", s.action.adminMakeup.GetSessionQuota, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
+		{http.MethodPut, "/api/v1/admin/offerings/:offering_id/terms/:term_id/quota", s.action.adminMakeup.SetPlannedSessions, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
+		{http.MethodGet, "/api/v1/admin/offerings/:offering_id/terms/:term_id/makeups", s.action.adminMakeup.GetMakeups, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
+		{http.MethodPost, "/api/v1/admin/makeups", s.action.adminMakeup.ScheduleMakeup, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
+		{http.MethodDelete, "/api/v1/admin/makeups/:makeup_id", s.action.adminMakeup.CancelMakeup, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
+		{http.MethodGet, "/api/v1/admin/terms/:term_id/makeup-report", s.action.adminMakeup.GetOutstandingMakeups, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
+
+		// Admin - Operating Hours
+		{http.MethodGet, "/api/v1/admin/operating-hours", s.action.adminOperatingHours.GetOperatingHours, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
+		{http.MethodGet, "/api/v1/admin/operating-hours/overrides", s.action.adminOperatingHours.GetOverrides, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
+		{http.MethodPost, "/api/v1/admin/operating-hours/overrides", s.action.adminOperatingHours.CreateOverride, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
+		{http.MethodDelete, "/api/v1/admin/operating-hours/overrides/:override_id", s.action.adminOperatingHours.DeleteOverride, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
+
+		// Admin - Room Bookings (會議、租借、維修)
+		{http.MethodGet, "/api/v1/admin/room-bookings", s.action.adminRoomBooking.GetBookings, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
+		{http.MethodGet, "/api/v1/admin/room-bookings/occurrences", s.action.adminRoomBooking.GetOccurrences, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
+		{http.MethodPost, "/api/v1/admin/room-bookings", s.action.adminRoomBooking.CreateBooking, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
+		{http.MethodPut, "/api/v1/admin/room-bookings/:booking_id", s.action.adminRoomBooking.UpdateBooking, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
+		{http.MethodDelete, "/api/v1/admin/room-bookings/:booking_id", s.action.adminRoomBooking.DeleteBooking, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
 
 		// Admin - Teacher Notes (評分與備註)
 		{http.MethodGet, "/api/v1/admin/teachers/:teacher_id/note", s.action.adminTeacher.GetTeacherNote, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
@@ -266,11 +304,47 @@ func (s *Server) LoadRoutes() {
 		{http.MethodPost, "/api/v1/notifications/:id/read", s.action.notification.MarkAsRead, []gin.HandlerFunc{authMiddleware.Authenticate()}},
 		{http.MethodPost, "/api/v1/notifications/read-all", s.action.notification.MarkAllAsRead, []gin.HandlerFunc{authMiddleware.Authenticate()}},
 		{http.MethodPost, "/api/v1/notifications/token", s.action.notification.SetNotifyToken, []gin.HandlerFunc{authMiddleware.Authenticate()}},
+		{http.MethodGet, "/api/v1/notifications/preferences", s.action.notification.GetPreferences, []gin.HandlerFunc{authMiddleware.Authenticate()}},
+		{http.MethodPut, "/api/v1/notifications/preferences", s.action.notification.UpdatePreferences, []gin.HandlerFunc{authMiddleware.Authenticate()}},
 		{http.MethodPost, "/api/v1/notifications/test", s.action.notification.SendTestNotification, []gin.HandlerFunc{authMiddleware.Authenticate()}},
 		// Notification Queue Stats (Admin only)
 		{http.MethodGet, "/api/v1/admin/notifications/queue-stats", s.action.notification.GetQueueStats, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
 		// Admin Notification - Broadcast (Admin only)
 		{http.MethodPost, "/api/v1/admin/notifications/broadcast", s.action.adminNotification.Broadcast, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
+		{http.MethodGet, "/api/v1/admin/notifications/broadcasts", s.action.adminNotification.ListBroadcasts, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
+		{http.MethodGet, "/api/v1/admin/notifications/broadcasts/:id", s.action.adminNotification.GetBroadcast, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
+		{http.MethodPost, "/api/v1/admin/notifications/broadcasts/:id/cancel", s.action.adminNotification.CancelBroadcast, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
+		{http.MethodGet, "/api/v1/admin/notifications/broadcasts/:id/recipients", s.action.adminNotification.ListBroadcastRecipients, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
+		// Admin - Notification Dead Letters
+		{http.MethodGet, "/api/v1/admin/notifications/dead-letters", s.action.adminNotification.ListDeadLetters, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
+		{http.MethodGet, "/api/v1/admin/notifications/dead-letters/:id", s.action.adminNotification.GetDeadLetter, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
+		{http.MethodPost, "/api/v1/admin/notifications/dead-letters/:id/replay", s.action.adminNotification.ReplayDeadLetter, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
+		{http.MethodDelete, "/api/v1/admin/notifications/dead-letters/:id", s.action.adminNotification.DiscardDeadLetter, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
+		// Admin - Notification Templates
+		{http.MethodGet, "/api/v1/admin/notification-templates", s.action.adminNotifTemplate.ListTemplates, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
+		{http.MethodPost, "/api/v1/admin/notification-templates/preview", s.action.adminNotifTemplate.PreviewTemplate, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
+		{http.MethodPut, "/api/v1/admin/notification-templates/:channel/:type/:locale", s.action.adminNotifTemplate.SaveTemplate, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
+		{http.MethodDelete, "/api/v1/admin/notification-templates/:channel/:type/:locale", s.action.adminNotifTemplate.ResetTemplate, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
+		// Admin - Webhooks
+		{http.MethodGet, "/api/v1/admin/webhooks", s.action.adminWebhook.ListSubscriptions, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
+		{http.MethodPost, "/api/v1/admin/webhooks", s.action.adminWebhook.CreateSubscription, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
+		{http.MethodGet, "/api/v1/admin/webhooks/event-types", s.action.adminWebhook.ListEventTypes, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
+		{http.MethodGet, "/api/v1/admin/webhooks/deliveries/:delivery_id", s.action.adminWebhook.GetDelivery, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
+		{http.MethodPost, "/api/v1/admin/webhooks/deliveries/:delivery_id/redeliver", s.action.adminWebhook.Redeliver, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
+		{http.MethodPut, "/api/v1/admin/webhooks/:id", s.action.adminWebhook.UpdateSubscription, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
+		{http.MethodDelete, "/api/v1/admin/webhooks/:id", s.action.adminWebhook.DeleteSubscription, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
+		{http.MethodPost, "/api/v1/admin/webhooks/:id/rotate-secret", s.action.adminWebhook.RotateSecret, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
+		{http.MethodPost, "/api/v1/admin/webhooks/:id/test", s.action.adminWebhook.SendTestEvent, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
+		{http.MethodGet, "/api/v1/admin/webhooks/:id/deliveries", s.action.adminWebhook.ListDeliveries, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
+		// Admin - LINE Groups
+		{http.MethodGet, "/api/v1/admin/line-groups", s.action.adminLineGroup.ListGroups, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
+		{http.MethodPost, "/api/v1/admin/line-groups/link-code", s.action.adminLineGroup.GenerateLinkCode, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
+		{http.MethodPatch, "/api/v1/admin/line-groups/:id", s.action.adminLineGroup.UpdateGroup, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
+		{http.MethodDelete, "/api/v1/admin/line-groups/:id", s.action.adminLineGroup.UnlinkGroup, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
+		// Admin - LINE Webhook 事件紀錄
+		{http.MethodGet, "/api/v1/admin/line-events", s.action.adminLineEvent.ListEvents, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
+		{http.MethodGet, "/api/v1/admin/line-events/:id", s.action.adminLineEvent.GetEvent, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
+		{http.MethodPost, "/api/v1/admin/line-events/:id/replay", s.action.adminLineEvent.ReplayEvent, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
 		// Health Check
 		{http.MethodGet, "/api/v1/admin/health/redis", s.action.notification.CheckRedisHealth, []gin.HandlerFunc{authMiddleware.Authenticate(), authMiddleware.RequireCenterAdmin()}},
 
@@ -327,17 +401,25 @@ func (s *Server) registerRoutes(r *gin.Engine) {
 	responseSanitizer := middleware.NewResponseSanitizer()
 	r.Use(responseSanitizer.Sanitize())
 
+	// 寫入類請求支援 Idempotency-Key，放在認證之後以便依使用者區分
+	idempotency := s.IdempotencyMiddleware()
+	withIdempotency := func(rt route) []gin.HandlerFunc {
+		handlers := make([]gin.HandlerFunc, 0, len(rt.Middlewares)+2)
+		handlers = append(handlers, rt.Middlewares...)
+		return append(handlers, idempotency, rt.Controller)
+	}
+
 	// 註冊所有路由
 	for _, rt := range s.routes {
 		switch rt.Method {
 		case http.MethodGet:
 			r.GET(rt.Path, append(rt.Middlewares, rt.Controller)...)
 		case http.MethodPost:
-			r.POST(rt.Path, append(rt.Middlewares, rt.Controller)...)
+			r.POST(rt.Path, withIdempotency(rt)...)
 		case http.MethodPut:
-			r.PUT(rt.Path, append(rt.Middlewares, rt.Controller)...)
+			r.PUT(rt.Path, withIdempotency(rt)...)
 		case http.MethodPatch:
-			r.PATCH(rt.Path, append(rt.Middlewares, rt.Controller)...)
+			r.PATCH(rt.Path, withIdempotency(rt)...)
 		case http.MethodDelete:
 			r.DELETE(rt.Path, append(rt.Middlewares, rt.Controller)...)
 		}
@@ -359,6 +441,10 @@ func (s *Server) NewControllers() {
 	s.action.adminCourse = controllers.NewAdminCourseController(s.app)
 	s.action.adminHoliday = controllers.NewAdminHolidayController(s.app)
 	s.action.adminTerm = controllers.NewAdminTermController(s.app)
+	s.action.adminMakeup = controllers.NewAdminMakeupController(s.app)
+	s.action.adminOperatingHours = controllers.NewAdminOperatingHoursController(s.app)
+	s.action.adminRoomBooking = controllers.NewAdminRoomBookingController(s.app)
+	s.action.adminDashboard = controllers.NewAdminDashboardController(s.app)
 	s.action.teacherProfile = controllers.NewTeacherProfileController(s.app)
 	s.action.teacherSchedule = controllers.NewTeacherScheduleController(s.app)
 	s.action.teacherSession = controllers.NewTeacherSessionController(s.app)
@@ -374,6 +460,10 @@ func (s *Server) NewControllers() {
 	s.action.smartMatching = controllers.NewSmartMatchingController(s.app)
 	s.action.notification = controllers.NewNotificationController(s.app)
 	s.action.adminNotification = controllers.NewAdminNotificationController(s.app)
+	s.action.adminWebhook = controllers.NewAdminWebhookController(s.app)
+	s.action.adminNotifTemplate = controllers.NewAdminNotificationTemplateController(s.app)
+	s.action.adminLineGroup = controllers.NewAdminLineGroupController(s.app)
+	s.action.adminLineEvent = controllers.NewAdminLineEventController(s.app)
 	s.action.export = controllers.NewExportController(s.app)
 	s.action.lineBot = controllers.NewLineBotController(s.app)
 	s.action.r2Test = controllers.NewR2TestController(s.app)
@@ -2,8 +2,13 @@ package services
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"slices"
 	"strconv"
+	"sync"
 	"time"
 	"timeLedger/app"
 	"timeLedger/app/models"
@@ -11,6 +16,7 @@ import (
 	"timeLedger/global/errInfos"
 
 	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
 )
 
 // BroadcastRateLimitConfig 廣播速率限制配置
@@ -28,8 +34,15 @@ type AdminNotificationService struct {
 	membershipRepo   *repositories.CenterMembershipRepository
 	centerRepo       *repositories.CenterRepository
 	lineBotService   LineBotService
-	templateService   LineBotTemplateService
+	templates        *NotificationTemplateService
 	rateLimiter      *BroadcastRateLimiter
+	notificationRepo *repositories.NotificationRepository
+	preferenceSvc    *NotificationPreferenceService
+	emailSvc         *EmailNotificationService
+	ruleRepo         *repositories.ScheduleRuleRepository
+	expansionSvc     ScheduleExpansionService
+	broadcastRepo    *repositories.BroadcastRepository
+	recipientRepo    *repositories.BroadcastRecipientRepository
 }
 
 // BroadcastRateLimiter 廣播專用速率限制器
@@ -122,8 +135,6 @@ func (r *BroadcastRateLimiter) Record(ctx context.Context, adminID uint, centerI
 
 // NewAdminNotificationService 建立管理員通知服務
 func NewAdminNotificationService(app *app.App) *AdminNotificationService {
-	templateService := NewLineBotTemplateService(app.Env.FrontendBaseURL)
-
 	return &AdminNotificationService{
 		BaseService:      *NewBaseService(app, "AdminNotificationService"),
 		app:              app,
@@ -131,18 +142,32 @@ func NewAdminNotificationService(app *app.App) *AdminNotificationService {
 		membershipRepo:   repositories.NewCenterMembershipRepository(app),
 		centerRepo:       repositories.NewCenterRepository(app),
 		lineBotService:   NewLineBotService(app),
-		templateService:  templateService,
+		templates:        NewNotificationTemplateService(app),
 		rateLimiter:      NewBroadcastRateLimiter(app),
+		notificationRepo: repositories.NewNotificationRepository(app),
+		preferenceSvc:    NewNotificationPreferenceService(app),
+		emailSvc:         NewEmailNotificationService(app),
+		ruleRepo:         repositories.NewScheduleRuleRepository(app),
+		expansionSvc:     NewScheduleExpansionService(app),
+		broadcastRepo:    repositories.NewBroadcastRepository(app),
+		recipientRepo:    repositories.NewBroadcastRecipientRepository(app),
 	}
 }
 
 // BroadcastResult 廣播結果
 type BroadcastResult struct {
-	SuccessCount int              `json:"success_count"`
-	FailedCount  int              `json:"failed_count"`
-	TotalCount   int              `json:"total_count"`
-	Message      string           `json:"message"`
-	RateLimit    *RateLimitInfo   `json:"rate_limit,omitempty"`
+	BroadcastID   uint           `json:"broadcast_id,omitempty"`
+	Status        string         `json:"status,omitempty"`
+	ScheduledAt   *time.Time     `json:"scheduled_at,omitempty"`
+	SuccessCount  int            `json:"success_count"`
+	FailedCount   int            `json:"failed_count"`
+	BlockedCount  int            `json:"blocked_count"`  // 已封鎖官方帳號或尚未加入好友
+	DeferredCount int            `json:"deferred_count"` // 依通知偏好延後（勿擾時段或每日彙整）
+	SkippedCount  int            `json:"skipped_count"`  // 未綁定 LINE 或已關閉廣播 LINE 通知
+	EmailCount    int            `json:"email_count"`    // 已送出的 Email 通知
+	TotalCount    int            `json:"total_count"`
+	Message       string         `json:"message"`
+	RateLimit     *RateLimitInfo `json:"rate_limit,omitempty"`
 }
 
 // RateLimitInfo 速率限制資訊
@@ -154,28 +179,74 @@ type RateLimitInfo struct {
 	WindowSec   int       `json:"window_seconds"`
 }
 
-// BroadcastToTeachers 廣播訊息給中心老師
+// BroadcastInput 廣播內容與對象
+type BroadcastInput struct {
+	MessageType string // GENERAL 或 URGENT
+	Title       string
+	Message     string
+	Warning     string // 警告提示（可選）
+	ActionLabel string // 按鈕文字（可選）
+	ActionURL   string // 按鈕連結（可選）
+	Segment     models.BroadcastSegment
+	ScheduledAt *time.Time // 為空或已過表示立即發送
+}
+
+// broadcastPushConcurrency 逐一推播時的同時連線數
+const broadcastPushConcurrency = 5
+
+// broadcastDueBatchSize 每次排程處理的到期廣播數
+const broadcastDueBatchSize = 20
+
+// ValidateBroadcastSegment 檢查廣播對象條件
+func ValidateBroadcastSegment(segment models.BroadcastSegment) error {
+	if segment.SessionDate != "" {
+		if _, err := time.Parse("2006-01-02", segment.SessionDate); err != nil {
+			return fmt.Errorf("session_date 格式錯誤，應為 YYYY-MM-DD")
+		}
+	}
+	for _, role := range segment.Roles {
+		if role != "TEACHER" && role != "SUBSTITUTE" {
+			return fmt.Errorf("不支援的角色：%s", role)
+		}
+	}
+	return nil
+}
+
+// SelectBroadcastRecipients 依條件篩選廣播對象，佔位老師一律排除
+// roles 為老師在中心的角色；offeringTeacherIDs、sessionTeacherIDs 僅在對應條件有設定時使用
+func SelectBroadcastRecipients(
+	teachers []models.Teacher,
+	roles map[uint]string,
+	segment models.BroadcastSegment,
+	offeringTeacherIDs []uint,
+	sessionTeacherIDs []uint,
+) []models.Teacher {
+	var selected []models.Teacher
+	for _, teacher := range teachers {
+		if teacher.IsPlaceholder {
+			continue
+		}
+		if len(segment.TeacherIDs) > 0 && !slices.Contains(segment.TeacherIDs, teacher.ID) {
+			continue
+		}
+		if len(segment.Roles) > 0 && !slices.Contains(segment.Roles, roles[teacher.ID]) {
+			continue
+		}
+		if len(segment.OfferingIDs) > 0 && !slices.Contains(offeringTeacherIDs, teacher.ID) {
+			continue
+		}
+		if segment.SessionDate != "" && !slices.Contains(sessionTeacherIDs, teacher.ID) {
+			continue
+		}
+		selected = append(selected, teacher)
+	}
+	return selected
+}
+
+// Broadcast 建立廣播，未指定排程時間則立即發送
 // centerID: 中心 ID（從 JWT 取得，確保資料隔離）
 // adminID: 管理員 ID（用於記錄）
-// messageType: 訊息類型（GENERAL 或 URGENT）
-// title: 標題
-// message: 訊息內容
-// warning: 警告提示（可選）
-// actionLabel: 按鈕文字（可選）
-// actionURL: 按鈕連結（可選）
-// teacherIDs: 指定老師 ID 清單（空白表示發送給所有老師）
-func (s *AdminNotificationService) BroadcastToTeachers(
-	ctx context.Context,
-	centerID uint,
-	adminID uint,
-	messageType string,
-	title string,
-	message string,
-	warning string,
-	actionLabel string,
-	actionURL string,
-	teacherIDs []uint,
-) (*BroadcastResult, *errInfos.Res, error) {
+func (s *AdminNotificationService) Broadcast(ctx context.Context, centerID, adminID uint, input BroadcastInput) (*BroadcastResult, *errInfos.Res, error) {
 	// 【速率限制檢查】防止管理員連點
 	allowed, remaining, resetAt, err := s.rateLimiter.Check(ctx, adminID, centerID)
 	if err != nil {
@@ -199,54 +270,195 @@ func (s *AdminNotificationService) BroadcastToTeachers(
 			"reset_at", resetAt,
 		)
 		return &BroadcastResult{
-			SuccessCount: 0,
-			FailedCount:  0,
-			TotalCount:   0,
-			Message:      fmt.Sprintf("廣播頻率過高，請在 %d 秒後再試", int(time.Until(resetAt).Seconds())),
-			RateLimit:    rateLimitInfo,
+			Message:   fmt.Sprintf("廣播頻率過高，請在 %d 秒後再試", int(time.Until(resetAt).Seconds())),
+			RateLimit: rateLimitInfo,
 		}, s.App.Err.New(errInfos.RATE_LIMIT_EXCEEDED), nil
 	}
 
+	if err := ValidateBroadcastSegment(input.Segment); err != nil {
+		return nil, s.App.Err.New(errInfos.PARAMS_VALIDATE_ERROR), err
+	}
+
+	now := time.Now()
+	broadcast := models.Broadcast{
+		CenterID:    centerID,
+		CreatedBy:   adminID,
+		MessageType: input.MessageType,
+		Title:       input.Title,
+		Message:     input.Message,
+		Warning:     input.Warning,
+		ActionLabel: input.ActionLabel,
+		ActionURL:   input.ActionURL,
+		Segment:     input.Segment,
+		Status:      models.BroadcastStatusSending,
+		ScheduledAt: now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	scheduled := input.ScheduledAt != nil && input.ScheduledAt.After(now)
+	if scheduled {
+		broadcast.Status = models.BroadcastStatusScheduled
+		broadcast.ScheduledAt = *input.ScheduledAt
+	}
+	broadcast, err = s.broadcastRepo.Create(ctx, broadcast)
+	if err != nil {
+		s.Logger.Error("failed to create broadcast", "error", err)
+		return nil, s.App.Err.New(errInfos.SQL_ERROR), err
+	}
+
+	// 【記錄廣播請求】
+	if err := s.rateLimiter.Record(ctx, adminID, centerID); err != nil {
+		s.Logger.Error("failed to record broadcast", "error", err)
+	}
+
+	if scheduled {
+		s.Logger.Info("broadcast scheduled",
+			"broadcast_id", broadcast.ID,
+			"center_id", centerID,
+			"admin_id", adminID,
+			"scheduled_at", broadcast.ScheduledAt,
+		)
+		result := newBroadcastResult(broadcast)
+		result.Message = fmt.Sprintf("已排程於 %s 發送", broadcast.ScheduledAt.In(app.GetTaiwanLocation()).Format("2006-01-02 15:04"))
+		result.RateLimit = rateLimitInfo
+		return result, nil, nil
+	}
+
 	s.Logger.Info("broadcasting message to teachers",
+		"broadcast_id", broadcast.ID,
 		"center_id", centerID,
 		"admin_id", adminID,
-		"specified_teachers", len(teacherIDs),
 		"rate_limit_remaining", remaining,
 	)
 
-	// 取得目標老師清單
-	teachers, err := s.getTargetTeachers(ctx, centerID, teacherIDs)
+	err = s.deliver(ctx, &broadcast)
+	result := newBroadcastResult(broadcast)
+	result.RateLimit = rateLimitInfo
 	if err != nil {
-		s.Logger.Error("failed to get target teachers", "error", err)
-		return nil, s.App.Err.New(errInfos.SQL_ERROR), err
+		result.Message = fmt.Sprintf("發送失敗：%s", err.Error())
+		return result, s.App.Err.New(errInfos.SQL_ERROR), err
+	}
+	result.Message = broadcastResultMessage(broadcast)
+	return result, nil, nil
+}
+
+// SendDueBroadcasts 發送到期的排程廣播，回傳發送的廣播數
+func (s *AdminNotificationService) SendDueBroadcasts(ctx context.Context, now time.Time) (int, error) {
+	broadcasts, err := s.broadcastRepo.ListDue(ctx, now, broadcastDueBatchSize)
+	if err != nil {
+		return 0, err
 	}
 
+	sent := 0
+	for i := range broadcasts {
+		broadcast := broadcasts[i]
+		// 以條件更新取得發送權，已被取消或其他排程處理時略過
+		ok, err := s.broadcastRepo.Claim(ctx, broadcast.ID, now)
+		if err != nil {
+			s.Logger.Error("failed to claim broadcast", "broadcast_id", broadcast.ID, "error", err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		broadcast.Status = models.BroadcastStatusSending
+		if err := s.deliver(ctx, &broadcast); err != nil {
+			s.Logger.Error("failed to send scheduled broadcast", "broadcast_id", broadcast.ID, "error", err)
+			continue
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+// deliver 依對象條件取得收件者並發送，逐一記錄 LINE 發送結果
+func (s *AdminNotificationService) deliver(ctx context.Context, broadcast *models.Broadcast) error {
+	centerID := broadcast.CenterID
+
+	// 收件者於發送時才決定，排程期間新加入或離開的老師會反映在結果中
+	teachers, err := s.resolveSegment(ctx, centerID, broadcast.Segment)
+	if err != nil {
+		s.finishBroadcast(ctx, broadcast, models.BroadcastStatusFailed, err.Error())
+		return err
+	}
+	broadcast.TotalCount = len(teachers)
 	if len(teachers) == 0 {
-		return &BroadcastResult{
-			SuccessCount: 0,
-			FailedCount:  0,
-			TotalCount:   0,
-			Message:      "沒有符合條件的老師",
-			RateLimit:    rateLimitInfo,
-		}, nil, nil
+		s.finishBroadcast(ctx, broadcast, models.BroadcastStatusSent, "")
+		return nil
 	}
 
-	// 過濾出有綁定 LINE 的老師
-	var lineUserIDs []string
+	// 依老師的通知偏好分流：立即發送、延後（勿擾時段 / 每日彙整）或略過
+	ids := make([]uint, 0, len(teachers))
 	for _, teacher := range teachers {
-		if teacher.LineUserID != "" {
-			lineUserIDs = append(lineUserIDs, teacher.LineUserID)
-		}
+		ids = append(ids, teacher.ID)
+	}
+	prefs, err := s.preferenceSvc.GetPreferences(ctx, "TEACHER", ids)
+	if err != nil {
+		s.Logger.Warn("failed to load notification preferences, using defaults", "error", err)
+		prefs = map[uint]models.NotificationPreference{}
 	}
 
-	if len(lineUserIDs) == 0 {
-		return &BroadcastResult{
-			SuccessCount: 0,
-			FailedCount:  0,
-			TotalCount:   len(teachers),
-			Message:      "目標老師都尚未綁定 LINE",
-			RateLimit:    rateLimitInfo,
-		}, nil, nil
+	urgent := broadcast.MessageType == "URGENT"
+	now := time.Now()
+	recipients := make([]models.BroadcastRecipient, 0, len(teachers))
+	lineUserIDs := make(map[uint]string)
+	var heldTargets []broadcastHeldTarget
+	var emailTargets []models.Teacher
+	var inAppNotifications []models.Notification
+	for _, teacher := range teachers {
+		pref, ok := prefs[teacher.ID]
+		if !ok {
+			pref = DefaultNotificationPreference("TEACHER", teacher.ID, s.app.Env.AppTimezone)
+		}
+
+		if PlanNotificationDelivery(pref, models.NotificationEventBroadcast, models.NotificationChannelInApp, now, urgent).Action == NotificationDeliverNow {
+			inAppNotifications = append(inAppNotifications, models.Notification{
+				UserID:    teacher.ID,
+				UserType:  "TEACHER",
+				CenterID:  centerID,
+				Title:     broadcast.Title,
+				Message:   broadcast.Message,
+				Type:      "BROADCAST",
+				CreatedAt: now,
+			})
+		}
+
+		if teacher.Email != "" && s.emailSvc.Enabled() {
+			plan := PlanNotificationDelivery(pref, models.NotificationEventBroadcast, models.NotificationChannelEmail, now, urgent)
+			switch {
+			case plan.Held():
+				heldTargets = append(heldTargets, broadcastHeldTarget{teacherID: teacher.ID, channel: models.NotificationChannelEmail, plan: plan})
+			case plan.Action == NotificationDeliverNow:
+				emailTargets = append(emailTargets, teacher)
+			}
+		}
+
+		recipient := models.BroadcastRecipient{
+			BroadcastID: broadcast.ID,
+			TeacherID:   teacher.ID,
+			TeacherName: teacher.Name,
+			Status:      models.BroadcastRecipientPending,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+		if teacher.LineUserID == "" {
+			recipient.Status = models.BroadcastRecipientSkipped
+			recipient.ErrorMsg = "未綁定 LINE"
+			recipients = append(recipients, recipient)
+			continue
+		}
+		plan := PlanNotificationDelivery(pref, models.NotificationEventBroadcast, models.NotificationChannelLine, now, urgent)
+		switch {
+		case plan.Held():
+			heldTargets = append(heldTargets, broadcastHeldTarget{teacherID: teacher.ID, channel: models.NotificationChannelLine, plan: plan})
+			recipient.Status = models.BroadcastRecipientDeferred
+		case plan.Action == NotificationDeliverSkip:
+			recipient.Status = models.BroadcastRecipientSkipped
+			recipient.ErrorMsg = "已關閉廣播通知"
+		default:
+			lineUserIDs[teacher.ID] = teacher.LineUserID
+		}
+		recipients = append(recipients, recipient)
 	}
 
 	// 取得中心名稱
@@ -256,86 +468,344 @@ func (s *AdminNotificationService) BroadcastToTeachers(
 		centerName = center.Name
 	}
 
-	// 建立 Flex Message 結構
-	flexMessage := s.templateService.GetBroadcastTemplate(
+	// 建立 Flex Message 結構，套用中心語系與自訂範本
+	templates := s.templates.LineTemplates(ctx, centerID)
+	flexMessage := templates.GetBroadcastTemplate(
 		centerName,
-		title,
-		message,
-		warning,
-		actionLabel,
-		actionURL,
+		broadcast.Title,
+		broadcast.Message,
+		broadcast.Warning,
+		broadcast.ActionLabel,
+		broadcast.ActionURL,
 	)
 
 	// 設定 altText
-	altTextPrefix := "🔔 廣播通知"
-	if messageType == "URGENT" {
-		altTextPrefix = "🚨 緊急通知"
+	altTextField := "alt_text"
+	if urgent {
+		altTextField = "alt_text_urgent"
 	}
+	altText := templates.GetText(models.NotificationTemplateBroadcast, altTextField,
+		map[string]string{"center_name": centerName, "title": broadcast.Title, "warning": broadcast.Warning})
 
 	// 包裝為 Flex Message 格式
 	lineMessage := map[string]interface{}{
 		"type":     "flex",
-		"altText":  fmt.Sprintf("%s - %s", altTextPrefix, title),
+		"altText":  altText,
 		"contents": flexMessage,
 	}
 
-	// 【記錄廣播請求】
-	if err := s.rateLimiter.Record(ctx, adminID, centerID); err != nil {
-		s.Logger.Error("failed to record broadcast", "error", err)
+	if err := s.recipientRepo.CreateBatch(ctx, recipients); err != nil {
+		s.finishBroadcast(ctx, broadcast, models.BroadcastStatusFailed, err.Error())
+		return err
 	}
 
-	// 使用 Multicast 發送訊息
-	err = s.lineBotService.Multicast(ctx, lineUserIDs, lineMessage)
-	if err != nil {
-		s.Logger.Error("multicast failed", "error", err, "user_count", len(lineUserIDs))
-		return &BroadcastResult{
-			SuccessCount: 0,
-			FailedCount:  len(lineUserIDs),
-			TotalCount:   len(teachers),
-			Message:      fmt.Sprintf("發送失敗：%s", err.Error()),
-			RateLimit:    rateLimitInfo,
-		}, nil, err
+	// 站內通知
+	if _, err := s.notificationRepo.CreateBatch(ctx, inAppNotifications); err != nil {
+		s.Logger.Error("failed to create broadcast notifications", "error", err)
 	}
 
+	emailData := EmailTemplateData{
+		CenterName:  centerName,
+		Title:       broadcast.Title,
+		Message:     broadcast.Message,
+		ActionLabel: broadcast.ActionLabel,
+		ActionURL:   broadcast.ActionURL,
+	}
+	if broadcast.Warning != "" {
+		emailData.Details = []EmailDetail{{Label: "注意事項", Value: broadcast.Warning}}
+	}
+
+	// 延後發送的老師暫存至排程
+	if len(heldTargets) > 0 {
+		flex, _ := json.Marshal(flexMessage)
+		for _, target := range heldTargets {
+			payload := HeldNotificationPayload{
+				CenterID: centerID,
+				Channel:  models.NotificationChannelLine,
+				Title:    altText,
+				AltText:  altText,
+				Flex:     flex,
+			}
+			if target.channel == models.NotificationChannelEmail {
+				data := emailData
+				payload = HeldNotificationPayload{
+					CenterID: centerID,
+					Channel:  models.NotificationChannelEmail,
+					Title:    altText,
+					Message:  broadcast.Message,
+					Email:    &data,
+				}
+			}
+			if err := s.preferenceSvc.Hold(ctx, "TEACHER", target.teacherID, models.NotificationEventBroadcast, target.plan, payload); err != nil {
+				s.Logger.Error("failed to hold broadcast", "error", err, "teacher_id", target.teacherID)
+			}
+		}
+	}
+
+	// Email 於背景逐封發送，發送結果記錄於 notification_queues
+	for _, teacher := range emailTargets {
+		data := emailData
+		data.RecipientName = teacher.Name
+		s.emailSvc.SendAsync(ctx, EmailNotification{
+			CenterID:      centerID,
+			RecipientType: "TEACHER",
+			RecipientID:   teacher.ID,
+			Address:       teacher.Email,
+			TemplateType:  models.NotificationEventBroadcast,
+			Data:          data,
+		})
+	}
+	broadcast.EmailCount = len(emailTargets)
+
+	// 逐一推播以取得每位老師的發送結果（Multicast 僅回傳整批成功或失敗）
+	sem := make(chan struct{}, broadcastPushConcurrency)
+	var wg sync.WaitGroup
+	for i := range recipients {
+		if recipients[i].Status != models.BroadcastRecipientPending {
+			continue
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(recipient *models.BroadcastRecipient) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			s.pushToRecipient(ctx, recipient, lineUserIDs[recipient.TeacherID], lineMessage)
+		}(&recipients[i])
+	}
+	wg.Wait()
+
+	for _, recipient := range recipients {
+		switch recipient.Status {
+		case models.BroadcastRecipientSent:
+			broadcast.SentCount++
+		case models.BroadcastRecipientFailed:
+			broadcast.FailedCount++
+		case models.BroadcastRecipientBlocked:
+			broadcast.BlockedCount++
+		case models.BroadcastRecipientSkipped:
+			broadcast.SkippedCount++
+		case models.BroadcastRecipientDeferred:
+			broadcast.DeferredCount++
+		}
+	}
+
+	s.finishBroadcast(ctx, broadcast, models.BroadcastStatusSent, "")
+
 	s.Logger.Info("broadcast completed",
-		"success_count", len(lineUserIDs),
-		"total_teachers", len(teachers),
+		"broadcast_id", broadcast.ID,
+		"success_count", broadcast.SentCount,
+		"failed_count", broadcast.FailedCount,
+		"blocked_count", broadcast.BlockedCount,
+		"deferred_count", broadcast.DeferredCount,
+		"skipped_count", broadcast.SkippedCount,
+		"email_count", broadcast.EmailCount,
+		"total_teachers", broadcast.TotalCount,
 	)
+	return nil
+}
+
+// pushToRecipient 推播給單一老師並記錄結果
+func (s *AdminNotificationService) pushToRecipient(ctx context.Context, recipient *models.BroadcastRecipient, lineUserID string, message interface{}) {
+	err := s.lineBotService.PushMessage(ctx, lineUserID, message)
+	now := time.Now()
+	recipient.UpdatedAt = now
+
+	var apiErr *LineAPIError
+	if errors.As(err, &apiErr) {
+		recipient.StatusCode = apiErr.StatusCode
+	}
+	switch {
+	case err == nil:
+		recipient.Status = models.BroadcastRecipientSent
+		recipient.StatusCode = http.StatusOK
+		recipient.SentAt = &now
+	case IsLineRecipientBlocked(err):
+		recipient.Status = models.BroadcastRecipientBlocked
+		recipient.ErrorMsg = err.Error()
+	default:
+		recipient.Status = models.BroadcastRecipientFailed
+		recipient.ErrorMsg = err.Error()
+		s.Logger.Warn("broadcast push failed", "broadcast_id", recipient.BroadcastID, "teacher_id", recipient.TeacherID, "error", err)
+	}
 
-	return &BroadcastResult{
-		SuccessCount: len(lineUserIDs),
-		FailedCount:  0,
-		TotalCount:   len(teachers),
-		Message:      fmt.Sprintf("成功發送給 %d 位老師", len(lineUserIDs)),
-		RateLimit:    rateLimitInfo,
-	}, nil, nil
+	if err := s.recipientRepo.RecordResult(ctx, *recipient); err != nil {
+		s.Logger.Error("failed to record broadcast recipient", "recipient_id", recipient.ID, "error", err)
+	}
 }
 
-// getTargetTeachers 取得目標老師清單
-// 若 teacherIDs 為空，返回中心所有老師；否則返回指定的老師（需驗證屬於該中心）
-func (s *AdminNotificationService) getTargetTeachers(
-	ctx context.Context,
-	centerID uint,
-	teacherIDs []uint,
-) ([]models.Teacher, error) {
-	if len(teacherIDs) == 0 {
-		// 取得中心所有老師
-		return s.teacherRepo.ListByCenter(ctx, centerID)
-	}
-
-	// 取得指定的老師，並驗證屬於該中心
-	var teachers []models.Teacher
-	err := s.app.MySQL.RDB.WithContext(ctx).
-		Table("teachers").
-		Joins("INNER JOIN center_memberships ON center_memberships.teacher_id = teachers.id").
-		Where("center_memberships.center_id = ?", centerID).
-		Where("center_memberships.status = ?", "ACTIVE").
-		Where("teachers.id IN ?", teacherIDs).
-		Find(&teachers).Error
+// finishBroadcast 寫回廣播的最終狀態與統計
+func (s *AdminNotificationService) finishBroadcast(ctx context.Context, broadcast *models.Broadcast, status, lastError string) {
+	now := time.Now()
+	broadcast.Status = status
+	broadcast.LastError = lastError
+	broadcast.UpdatedAt = now
+	if status == models.BroadcastStatusSent {
+		broadcast.SentAt = &now
+	}
+	if err := s.broadcastRepo.Finish(ctx, *broadcast); err != nil {
+		s.Logger.Error("failed to finish broadcast", "broadcast_id", broadcast.ID, "error", err)
+	}
+}
 
+// resolveSegment 依對象條件取得中心的收件老師
+func (s *AdminNotificationService) resolveSegment(ctx context.Context, centerID uint, segment models.BroadcastSegment) ([]models.Teacher, error) {
+	teachers, err := s.teacherRepo.ListByCenter(ctx, centerID)
 	if err != nil {
 		return nil, err
 	}
 
-	return teachers, nil
+	roles := make(map[uint]string)
+	if len(segment.Roles) > 0 {
+		memberships, err := s.membershipRepo.ListActiveByCenterID(ctx, centerID)
+		if err != nil {
+			return nil, err
+		}
+		for _, membership := range memberships {
+			roles[membership.TeacherID] = membership.Role
+		}
+	}
+
+	var offeringTeacherIDs, sessionTeacherIDs []uint
+	if len(segment.OfferingIDs) > 0 || segment.SessionDate != "" {
+		rules, err := s.ruleRepo.ListByCenterID(ctx, centerID)
+		if err != nil {
+			return nil, err
+		}
+
+		loc := app.GetTaiwanLocation()
+		local := time.Now().In(loc)
+		today := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+		if len(segment.OfferingIDs) > 0 {
+			// 班別的授課老師以尚未結束的規則為準
+			for _, rule := range rules {
+				if !slices.Contains(segment.OfferingIDs, rule.OfferingID) {
+					continue
+				}
+				if !rule.EffectiveRange.EndDate.IsZero() && rule.EffectiveRange.EndDate.Before(today) {
+					continue
+				}
+				offeringTeacherIDs = append(offeringTeacherIDs, rule.AllTeacherIDs()...)
+			}
+		}
+
+		if segment.SessionDate != "" {
+			date, err := time.ParseInLocation("2006-01-02", segment.SessionDate, loc)
+			if err != nil {
+				return nil, err
+			}
+			// 展開結果已套用代課與取消，假日與停課不算有課
+			for _, session := range s.expansionSvc.ExpandRules(ctx, rules, date, date, centerID) {
+				if session.IsHoliday || session.Status == "SUSPENDED" {
+					continue
+				}
+				sessionTeacherIDs = append(sessionTeacherIDs, session.AllTeacherIDs()...)
+			}
+		}
+	}
+
+	return SelectBroadcastRecipients(teachers, roles, segment, offeringTeacherIDs, sessionTeacherIDs), nil
+}
+
+// broadcastHeldTarget 依通知偏好延後收到廣播的老師
+type broadcastHeldTarget struct {
+	teacherID uint
+	channel   string
+	plan      NotificationDeliveryPlan
+}
+
+// CancelBroadcast 取消尚未發送的排程廣播
+func (s *AdminNotificationService) CancelBroadcast(ctx context.Context, centerID, id uint) (*models.Broadcast, *errInfos.Res, error) {
+	broadcast, errInfo, err := s.GetBroadcast(ctx, centerID, id)
+	if err != nil {
+		return nil, errInfo, err
+	}
+
+	now := time.Now()
+	ok, err := s.broadcastRepo.Cancel(ctx, id, centerID, now)
+	if err != nil {
+		return nil, s.App.Err.New(errInfos.SQL_ERROR), err
+	}
+	if !ok {
+		return nil, s.App.Err.New(errInfos.BROADCAST_NOT_CANCELLABLE), fmt.Errorf("broadcast %d is %s", id, broadcast.Status)
+	}
+
+	broadcast.Status = models.BroadcastStatusCancelled
+	broadcast.CancelledAt = &now
+	broadcast.UpdatedAt = now
+	return broadcast, nil, nil
+}
+
+// ListBroadcasts 分頁取得中心的廣播
+func (s *AdminNotificationService) ListBroadcasts(ctx context.Context, centerID uint, status string, page, limit int) ([]models.Broadcast, int64, *errInfos.Res, error) {
+	broadcasts, total, err := s.broadcastRepo.ListByCenter(ctx, centerID, status, page, limit)
+	if err != nil {
+		return nil, 0, s.App.Err.New(errInfos.SQL_ERROR), err
+	}
+	return broadcasts, total, nil, nil
+}
+
+// GetBroadcast 取得中心的廣播
+func (s *AdminNotificationService) GetBroadcast(ctx context.Context, centerID, id uint) (*models.Broadcast, *errInfos.Res, error) {
+	broadcast, err := s.broadcastRepo.GetByIDAndCenterID(ctx, id, centerID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, s.App.Err.New(errInfos.BROADCAST_NOT_FOUND), err
+		}
+		return nil, s.App.Err.New(errInfos.SQL_ERROR), err
+	}
+	return &broadcast, nil, nil
+}
+
+// ListBroadcastRecipients 分頁取得廣播的收件者發送結果
+func (s *AdminNotificationService) ListBroadcastRecipients(ctx context.Context, centerID, id uint, status string, page, limit int) ([]models.BroadcastRecipient, int64, *errInfos.Res, error) {
+	if _, errInfo, err := s.GetBroadcast(ctx, centerID, id); err != nil {
+		return nil, 0, errInfo, err
+	}
+
+	recipients, total, err := s.recipientRepo.ListByBroadcast(ctx, id, status, page, limit)
+	if err != nil {
+		return nil, 0, s.App.Err.New(errInfos.SQL_ERROR), err
+	}
+	return recipients, total, nil, nil
+}
+
+// newBroadcastResult 由廣播紀錄建立回應
+func newBroadcastResult(broadcast models.Broadcast) *BroadcastResult {
+	result := &BroadcastResult{
+		BroadcastID:   broadcast.ID,
+		Status:        broadcast.Status,
+		SuccessCount:  broadcast.SentCount,
+		FailedCount:   broadcast.FailedCount,
+		BlockedCount:  broadcast.BlockedCount,
+		DeferredCount: broadcast.DeferredCount,
+		SkippedCount:  broadcast.SkippedCount,
+		EmailCount:    broadcast.EmailCount,
+		TotalCount:    broadcast.TotalCount,
+	}
+	if broadcast.Status == models.BroadcastStatusScheduled {
+		scheduledAt := broadcast.ScheduledAt
+		result.ScheduledAt = &scheduledAt
+	}
+	return result
+}
+
+// broadcastResultMessage 立即發送完成後的摘要
+func broadcastResultMessage(broadcast models.Broadcast) string {
+	if broadcast.TotalCount == 0 {
+		return "沒有符合條件的老師"
+	}
+	message := fmt.Sprintf("成功發送給 %d 位老師", broadcast.SentCount)
+	if broadcast.FailedCount > 0 {
+		message += fmt.Sprintf("，%d 位發送失敗", broadcast.FailedCount)
+	}
+	if broadcast.BlockedCount > 0 {
+		message += fmt.Sprintf("，%d 位已封鎖官方帳號", broadcast.BlockedCount)
+	}
+	if broadcast.EmailCount > 0 {
+		message += fmt.Sprintf("，%d 封 Email", broadcast.EmailCount)
+	}
+	if broadcast.DeferredCount > 0 {
+		message += fmt.Sprintf("，%d 位老師依通知偏好延後發送", broadcast.DeferredCount)
+	}
+	return message
 }
@@ -2,8 +2,8 @@ package services
 
 import (
 	"context"
+	"crypto/rand"
 	"fmt"
-	"math/rand"
 	"strings"
 	"time"
 	"timeLedger/app"
@@ -21,6 +21,7 @@ type AdminUserService struct {
 	adminRepo      *repositories.AdminUserRepository
 	centerRepo     *repositories.CenterRepository
 	lineBotService LineBotService
+	richMenuSvc    *LineRichMenuService
 }
 
 // NewAdminUserService 建立管理員服務
@@ -30,6 +31,7 @@ func NewAdminUserService(app *app.App) *AdminUserService {
 		adminRepo:      repositories.NewAdminUserRepository(app),
 		centerRepo:     repositories.NewCenterRepository(app),
 		lineBotService: NewLineBotService(app),
+		richMenuSvc:    NewLineRichMenuService(app),
 	}
 }
 
@@ -63,10 +65,11 @@ type AdminProfileResponse struct {
 func GenerateBindingCode() string {
 	const charset = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
 	const length = 6
-	rand.Seed(time.Now().UnixNano())
+	// 使用 crypto/rand，字元集為 32 個字元，取餘數不會有偏差
 	b := make([]byte, length)
+	_, _ = rand.Read(b)
 	for i := range b {
-		b[i] = charset[rand.Intn(len(charset))]
+		b[i] = charset[int(b[i])%len(charset)]
 	}
 	return string(b)
 }
@@ -174,6 +177,8 @@ func (s *AdminUserService) VerifyLINEBinding(ctx context.Context, code string, l
 		return 0, s.app.Err.New(errInfos.SQL_ERROR), err
 	}
 
+	s.richMenuSvc.LinkUserAsync(lineUserID)
+
 	return admin.ID, nil, nil
 }
 
@@ -200,6 +205,9 @@ func (s *AdminUserService) UnbindLINE(ctx context.Context, adminID uint) (*errIn
 		return s.app.Err.New(errInfos.SQL_ERROR), err
 	}
 
+	// 改回訪客選單（若同時為老師則為老師選單）
+	s.richMenuSvc.LinkUserAsync(admin.LineUserID)
+
 	return nil, nil
 }
 
@@ -396,6 +404,8 @@ func (s *AdminUserService) ToggleAdminStatus(ctx context.Context, adminID uint,
 		return s.app.Err.New(errInfos.SQL_ERROR), err
 	}
 
+	s.richMenuSvc.LinkUserAsync(target.LineUserID)
+
 	return nil, nil
 }
 
@@ -494,6 +504,8 @@ func (s *AdminUserService) ChangeAdminRole(ctx context.Context, adminID uint, ta
 		return s.app.Err.New(errInfos.SQL_ERROR), err
 	}
 
+	s.richMenuSvc.LinkUserAsync(target.LineUserID)
+
 	return nil, nil
 }
 
@@ -96,8 +96,35 @@ const (
 	TaskTypeExceptionResult = "notification:exception_result"
 	TaskTypeWelcomeTeacher  = "notification:welcome_teacher"
 	TaskTypeWelcomeAdmin    = "notification:welcome_admin"
+	TaskTypeDispatch        = "notification:dispatch" // 通知派送器，payload 只帶 notification_queues ID
 )
 
+// DispatchTaskPayload 通知派送任務負載
+type DispatchTaskPayload struct {
+	NotificationID uint `json:"notification_id"`
+}
+
+// EnqueueDispatch 將 notification_queues 中的通知交給 Asynq worker 派送
+// 重試由通知派送器依類型策略處理，任務本身不重試
+func (s *AsynqNotificationService) EnqueueDispatch(ctx context.Context, notificationID uint) error {
+	payload, err := json.Marshal(DispatchTaskPayload{NotificationID: notificationID})
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	task := asynq.NewTask(
+		TaskTypeDispatch,
+		payload,
+		asynq.MaxRetry(0),
+		asynq.Timeout(30*time.Second),
+		asynq.Queue(s.config.QueueName),
+	)
+	if _, err := s.client.EnqueueContext(ctx, task); err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+	return nil
+}
+
 // EnqueueNotification 將通知加入佇列
 func (s *AsynqNotificationService) EnqueueNotification(ctx context.Context, item *models.NotificationQueue) error {
 	payload := &TaskPayload{
@@ -407,6 +434,15 @@ func (s *AsynqNotificationService) StartWorker(ctx context.Context) error {
 	mux.HandleFunc(TaskTypeWelcomeTeacher, processor.ProcessNotificationTask)
 	mux.HandleFunc(TaskTypeWelcomeAdmin, processor.ProcessNotificationTask)
 
+	dispatcher := NewNotificationDispatcher(s.app)
+	mux.HandleFunc(TaskTypeDispatch, func(ctx context.Context, t *asynq.Task) error {
+		var payload DispatchTaskPayload
+		if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+			return fmt.Errorf("failed to unmarshal dispatch payload: %w", err)
+		}
+		return dispatcher.Deliver(ctx, payload.NotificationID)
+	})
+
 	// 啟動 worker
 	server := asynq.NewServer(
 		asynq.RedisClientOpt{Addr: s.config.RedisAddr},
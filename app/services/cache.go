@@ -411,13 +411,15 @@ func (s *CacheService) InvalidateCourseList(ctx context.Context, centerID uint)
 
 // CourseCacheItem 課程快取項目
 type CourseCacheItem struct {
-	ID               uint   `json:"id"`
-	Name             string `json:"name"`
-	DefaultDuration  int    `json:"default_duration"`
-	ColorHex         string `json:"color_hex"`
-	RoomBufferMin    int    `json:"room_buffer_min"`
-	TeacherBufferMin int    `json:"teacher_buffer_min"`
-	IsActive         bool   `json:"is_active"`
+	ID               uint                `json:"id"`
+	Name             string              `json:"name"`
+	DefaultDuration  int                 `json:"default_duration"`
+	ColorHex         string              `json:"color_hex"`
+	RoomBufferMin    int                 `json:"room_buffer_min"`
+	TeacherBufferMin int                 `json:"teacher_buffer_min"`
+	RequiredFeatures models.RoomFeatures `json:"required_features"`
+	MinCapacity      int                 `json:"min_capacity"`
+	IsActive         bool                `json:"is_active"`
 }
 
 // =====================================================
@@ -446,10 +448,11 @@ func (s *CacheService) InvalidateRoomList(ctx context.Context, centerID uint) er
 
 // RoomCacheItem 教室快取項目
 type RoomCacheItem struct {
-	ID       uint   `json:"id"`
-	Name     string `json:"name"`
-	Capacity int    `json:"capacity"`
-	IsActive bool   `json:"is_active"`
+	ID       uint                `json:"id"`
+	Name     string              `json:"name"`
+	Capacity int                 `json:"capacity"`
+	Features models.RoomFeatures `json:"features"`
+	IsActive bool                `json:"is_active"`
 }
 
 // =====================================================
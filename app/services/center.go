@@ -88,19 +88,35 @@ func (s *CenterService) GetCenterSettings(ctx context.Context, centerID uint) (*
 }
 
 // UpdateCenterSettings 更新中心設置並清除快取
-func (s *CenterService) UpdateCenterSettings(ctx context.Context, centerID uint, adminID uint, settings *models.CenterSettings) (*models.Center, *errInfos.Res, error) {
-	// 更新資料庫
+// expectedVersion 為前端讀取時的 settings_version，nil 表示不比對
+func (s *CenterService) UpdateCenterSettings(ctx context.Context, centerID uint, adminID uint, settings *models.CenterSettings, expectedVersion *uint) (*models.Center, *errInfos.Res, error) {
 	center, err := s.centerRepo.GetByID(ctx, centerID)
 	if err != nil {
 		return nil, s.app.Err.New(errInfos.NOT_FOUND), err
 	}
 
-	center.Settings = *settings
+	version := expectedVersionOf(expectedVersion, center.SettingsVersion)
+	if version != center.SettingsVersion {
+		return nil, s.app.Err.New(errInfos.ERR_CONCURRENT_MODIFIED),
+			NewVersionConflictError("CenterSettings", centerID, version, center.SettingsVersion, center)
+	}
 
-	err = s.centerRepo.Update(ctx, center)
+	// 更新資料庫（以版本號條件更新，避免覆寫其他管理員同時送出的設定）
+	before := center.Settings
+	updated, err := s.centerRepo.UpdateSettingsWithVersion(ctx, centerID, *settings, version)
 	if err != nil {
 		return nil, s.app.Err.New(errInfos.SQL_ERROR), err
 	}
+	if !updated {
+		current, err := s.centerRepo.GetByID(ctx, centerID)
+		if err != nil {
+			return nil, s.app.Err.New(errInfos.NOT_FOUND), err
+		}
+		return nil, s.app.Err.New(errInfos.ERR_CONCURRENT_MODIFIED),
+			NewVersionConflictError("CenterSettings", centerID, version, current.SettingsVersion, current)
+	}
+	center.Settings = *settings
+	center.SettingsVersion = version + 1
 
 	// 清除快取（Cache Invalidation）
 	_ = s.cacheService.InvalidateCenterSettings(ctx, centerID)
@@ -115,7 +131,7 @@ func (s *CenterService) UpdateCenterSettings(ctx context.Context, centerID uint,
 		TargetType: "Center",
 		TargetID:   centerID,
 		Payload: models.AuditPayload{
-			Before: center.Settings,
+			Before: before,
 			After:  *settings,
 		},
 	})
@@ -0,0 +1,372 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"timeLedger/app"
+	"timeLedger/app/models"
+	"timeLedger/app/repositories"
+
+	"gorm.io/gorm"
+)
+
+// 課前提醒設定限制
+const (
+	MaxReminderOffsets       = 5           // 最多設定幾個提醒時間
+	MaxReminderOffsetMinutes = 7 * 24 * 60 // 最早課前七天
+)
+
+// classReminderCatchUp 排程中斷後補發的期限：提醒時間已過超過此期間則不再補發
+// （例如課程在上課前三小時才建立，不補發「前一天」提醒，只發「一小時前」提醒）
+const classReminderCatchUp = 30 * time.Minute
+
+// NormalizeReminderOffsets 驗證提前分鐘數，去除重複並由早到晚排序
+func NormalizeReminderOffsets(offsets []int) ([]int, error) {
+	if len(offsets) > MaxReminderOffsets {
+		return nil, fmt.Errorf("at most %d reminder offsets are allowed", MaxReminderOffsets)
+	}
+	normalized := make([]int, 0, len(offsets))
+	for _, offset := range offsets {
+		if offset < 1 || offset > MaxReminderOffsetMinutes {
+			return nil, fmt.Errorf("reminder offset must be between 1 and %d minutes", MaxReminderOffsetMinutes)
+		}
+		if !slices.Contains(normalized, offset) {
+			normalized = append(normalized, offset)
+		}
+	}
+	slices.SortFunc(normalized, func(a, b int) int { return b - a })
+	return normalized, nil
+}
+
+// TeacherReminderOffsets 老師實際套用的提前分鐘數：有自訂時以老師為準，否則沿用中心設定
+func TeacherReminderOffsets(settings models.CenterSettings, pref models.NotificationPreference) []int {
+	if pref.ReminderOffsets != nil {
+		return pref.ReminderOffsets
+	}
+	return settings.EffectiveReminderOffsets()
+}
+
+// ReminderOffsetLabel 提前時間的顯示文字
+func ReminderOffsetLabel(offset int) string {
+	switch {
+	case offset%(24*60) == 0:
+		if offset == 24*60 {
+			return "明天"
+		}
+		return fmt.Sprintf("%d天後", offset/(24*60))
+	case offset%60 == 0:
+		return fmt.Sprintf("%d小時後", offset/60)
+	default:
+		return fmt.Sprintf("%d分鐘後", offset)
+	}
+}
+
+// DueClassReminder 到期的單則課前提醒
+type DueClassReminder struct {
+	Session       ExpandedSchedule
+	StartAt       time.Time
+	OffsetMinutes int
+	TeacherID     uint
+}
+
+// SessionStartAt 場次的開始時間（課表時間以 loc 解讀）
+func SessionStartAt(session ExpandedSchedule, loc *time.Location) (time.Time, bool) {
+	parts := splitTime(session.StartTime)
+	if len(parts) < 2 {
+		return time.Time{}, false
+	}
+	return time.Date(session.Date.Year(), session.Date.Month(), session.Date.Day(), parts[0], parts[1], 0, 0, loc), true
+}
+
+// PlanDueClassReminders 從展開後的場次（已套用例外、假日與代課）找出此刻到期的提醒
+// 只提醒已開課的場次；跨日課程只以開始的那一段計算；提醒時間已過超過補發期限的不再發送
+func PlanDueClassReminders(sessions []ExpandedSchedule, offsetsFor func(teacherID uint) []int, now time.Time, loc *time.Location) []DueClassReminder {
+	var due []DueClassReminder
+	for _, session := range sessions {
+		if session.Status != "" && session.Status != models.RuleStatusConfirmed {
+			continue
+		}
+		if session.IsCrossDayPart && session.StartTime == "00:00" {
+			continue
+		}
+		startAt, ok := SessionStartAt(session, loc)
+		if !ok || !startAt.After(now) {
+			continue
+		}
+
+		for _, teacherID := range session.AllTeacherIDs() {
+			for _, offset := range offsetsFor(teacherID) {
+				remindAt := startAt.Add(-time.Duration(offset) * time.Minute)
+				if remindAt.After(now) || now.Sub(remindAt) > classReminderCatchUp {
+					continue
+				}
+				due = append(due, DueClassReminder{
+					Session:       session,
+					StartAt:       startAt,
+					OffsetMinutes: offset,
+					TeacherID:     teacherID,
+				})
+			}
+		}
+	}
+	return due
+}
+
+// ClassReminderService 課前提醒
+// 依中心與老師設定的提前時間，從完整展開的課表產生提醒；每則提醒先登記於 class_reminders
+// （唯一索引），與站內通知、通知佇列寫入同一交易，再由通知派送器發送 LINE 與 Email
+type ClassReminderService struct {
+	BaseService
+	app              *app.App
+	centerRepo       *repositories.CenterRepository
+	ruleRepo         *repositories.ScheduleRuleRepository
+	reminderRepo     *repositories.ClassReminderRepository
+	teacherRepo      *repositories.TeacherRepository
+	notificationRepo *repositories.NotificationRepository
+	queueRepo        *repositories.NotificationQueueRepository
+	expansionSvc     ScheduleExpansionService
+	preferenceSvc    *NotificationPreferenceService
+	emailService     *EmailNotificationService
+	dispatcher       *NotificationDispatcher
+}
+
+// NewClassReminderService 建立課前提醒服務
+func NewClassReminderService(app *app.App) *ClassReminderService {
+	return &ClassReminderService{
+		BaseService:      *NewBaseService(app, "ClassReminderService"),
+		app:              app,
+		centerRepo:       repositories.NewCenterRepository(app),
+		ruleRepo:         repositories.NewScheduleRuleRepository(app),
+		reminderRepo:     repositories.NewClassReminderRepository(app),
+		teacherRepo:      repositories.NewTeacherRepository(app),
+		notificationRepo: repositories.NewNotificationRepository(app),
+		queueRepo:        repositories.NewNotificationQueueRepository(app),
+		expansionSvc:     NewScheduleExpansionService(app),
+		preferenceSvc:    NewNotificationPreferenceService(app),
+		emailService:     NewEmailNotificationService(app),
+		dispatcher:       NewNotificationDispatcher(app),
+	}
+}
+
+// SendDue 發送所有中心此刻到期的課前提醒，回傳新發出的則數
+func (s *ClassReminderService) SendDue(ctx context.Context, now time.Time) (int, error) {
+	centers, err := s.centerRepo.ListActive(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, center := range centers {
+		n, err := s.sendCenterReminders(ctx, center, now)
+		sent += n
+		if err != nil {
+			s.Logger.Error("failed to send class reminders", "center_id", center.ID, "error", err)
+		}
+	}
+	return sent, nil
+}
+
+func (s *ClassReminderService) sendCenterReminders(ctx context.Context, center models.Center, now time.Time) (int, error) {
+	rules, err := s.ruleRepo.ListByCenterID(ctx, center.ID)
+	if err != nil {
+		return 0, err
+	}
+	if len(rules) == 0 {
+		return 0, nil
+	}
+
+	var teacherIDs []uint
+	for _, rule := range rules {
+		for _, id := range rule.AllTeacherIDs() {
+			if !slices.Contains(teacherIDs, id) {
+				teacherIDs = append(teacherIDs, id)
+			}
+		}
+	}
+	prefs, err := s.preferenceSvc.GetPreferences(ctx, "TEACHER", teacherIDs)
+	if err != nil {
+		return 0, err
+	}
+
+	// 展開範圍涵蓋中心與老師設定中最早的提醒時間
+	horizon := slices.Max(append([]int{0}, center.Settings.EffectiveReminderOffsets()...))
+	for _, pref := range prefs {
+		horizon = max(horizon, slices.Max(append([]int{0}, pref.ReminderOffsets...)))
+	}
+	if horizon == 0 {
+		return 0, nil
+	}
+
+	loc := app.GetTaiwanLocation()
+	local := now.In(loc)
+	startDate := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	last := local.Add(time.Duration(horizon) * time.Minute)
+	endDate := time.Date(last.Year(), last.Month(), last.Day(), 0, 0, 0, 0, loc)
+	sessions := s.expansionSvc.ExpandRules(ctx, rules, startDate, endDate, center.ID)
+
+	offsetsFor := func(teacherID uint) []int {
+		pref, ok := prefs[teacherID]
+		if !ok {
+			// 代課老師不在原規則中，另外讀取偏好
+			pref = s.preferenceSvc.PreferenceOrDefault(ctx, "TEACHER", teacherID)
+			prefs[teacherID] = pref
+		}
+		return TeacherReminderOffsets(center.Settings, pref)
+	}
+
+	sent := 0
+	for _, reminder := range PlanDueClassReminders(sessions, offsetsFor, now, loc) {
+		ok, err := s.deliver(ctx, center, reminder, prefs[reminder.TeacherID], now)
+		if err != nil {
+			s.Logger.Warn("failed to send class reminder",
+				"center_id", center.ID, "rule_id", reminder.Session.RuleID, "teacher_id", reminder.TeacherID,
+				"offset_minutes", reminder.OffsetMinutes, "error", err)
+			continue
+		}
+		if ok {
+			sent++
+		}
+	}
+	return sent, nil
+}
+
+// deliver 登記並發送單則提醒；已由其他排程或先前執行登記過時回傳 false
+func (s *ClassReminderService) deliver(ctx context.Context, center models.Center, reminder DueClassReminder, pref models.NotificationPreference, now time.Time) (bool, error) {
+	teacher, err := s.teacherRepo.GetByID(ctx, reminder.TeacherID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get teacher: %w", err)
+	}
+
+	title, message := classReminderText(reminder)
+	event := models.NotificationEventReminder
+	var queued []*models.NotificationQueue
+
+	// queue 依偏好建立佇列通知；勿擾時段延後到上課之後的提醒不再發送
+	queue := func(channel, address string, payload any) {
+		plan := PlanNotificationDelivery(pref, event, channel, now, false)
+		if plan.Action == NotificationDeliverSkip {
+			return
+		}
+		scheduledAt := now
+		if plan.Held() {
+			if !plan.DeliverAt.Before(reminder.StartAt) {
+				return
+			}
+			scheduledAt = plan.DeliverAt
+		}
+		body, _ := json.Marshal(payload)
+		queued = append(queued, &models.NotificationQueue{
+			CenterID:         center.ID,
+			Type:             event,
+			Channel:          channel,
+			RecipientID:      teacher.ID,
+			RecipientType:    "TEACHER",
+			RecipientAddress: address,
+			Payload:          string(body),
+			Status:           models.NotificationStatusPending,
+			ScheduledAt:      scheduledAt,
+			CreatedAt:        now,
+			UpdatedAt:        now,
+		})
+	}
+
+	if teacher.LineUserID != "" {
+		queue(models.NotificationChannelLine, "", map[string]interface{}{
+			"type": "text",
+			"text": title + "\n\n" + message,
+		})
+	}
+	if teacher.Email != "" && s.emailService.Enabled() {
+		bounced, err := s.queueRepo.HasRecentBounce(ctx, models.NotificationChannelEmail, teacher.Email, now.Add(-emailBounceSuppressWindow))
+		if err != nil {
+			return false, err
+		}
+		if !bounced {
+			data := EmailTemplateData{
+				RecipientName: teacher.Name,
+				CenterName:    center.Name,
+				Title:         reminder.Session.OfferingName,
+				Message:       message,
+			}
+			rendered, err := RenderEmailTemplate(models.NotificationEventReminder, data)
+			if err != nil {
+				return false, fmt.Errorf("failed to render email: %w", err)
+			}
+			queue(models.NotificationChannelEmail, teacher.Email, EmailQueuePayload{
+				TemplateType: models.NotificationEventReminder,
+				Subject:      rendered.Subject,
+				Data:         data,
+			})
+		}
+	}
+
+	reserved := false
+	err = s.app.MySQL.WDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		reserved, err = s.reminderRepo.ReserveWithDB(ctx, tx, &models.ClassReminder{
+			CenterID:       center.ID,
+			RuleID:         reminder.Session.RuleID,
+			SessionStartAt: reminder.StartAt,
+			OffsetMinutes:  reminder.OffsetMinutes,
+			TeacherID:      teacher.ID,
+			CreatedAt:      now,
+		})
+		if err != nil || !reserved {
+			return err
+		}
+
+		if PlanNotificationDelivery(pref, event, models.NotificationChannelInApp, now, false).Action != NotificationDeliverSkip {
+			if err := s.notificationRepo.CreateWithDB(ctx, tx, &models.Notification{
+				UserID:    teacher.ID,
+				UserType:  "TEACHER",
+				CenterID:  center.ID,
+				Title:     title,
+				Message:   message,
+				Type:      "REMINDER",
+				CreatedAt: now,
+			}); err != nil {
+				return err
+			}
+		}
+		for _, item := range queued {
+			if err := s.queueRepo.CreateWithDB(ctx, tx, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil || !reserved {
+		return false, err
+	}
+
+	for _, item := range queued {
+		s.dispatcher.Enqueue(ctx, item)
+	}
+	return true, nil
+}
+
+// classReminderText 提醒的標題與內容
+func classReminderText(reminder DueClassReminder) (string, string) {
+	session := reminder.Session
+	weekdays := []string{"日", "一", "二", "三", "四", "五", "六"}
+
+	name := session.OfferingName
+	if name == "" {
+		name = "課程"
+	}
+	lines := []string{
+		fmt.Sprintf("%s 將於%s開始", name, ReminderOffsetLabel(reminder.OffsetMinutes)),
+		fmt.Sprintf("時間：%s（%s）%s-%s", reminder.StartAt.Format("2006-01-02"), weekdays[reminder.StartAt.Weekday()], session.StartTime, session.EndTime),
+	}
+	if session.RoomName != "" {
+		lines = append(lines, "教室："+session.RoomName)
+	}
+	if session.ExceptionInfo != nil && session.ExceptionInfo.Status == "PENDING" {
+		lines = append(lines, "※ 此堂課有待審核的異動申請")
+	}
+	return "⏰ 課程提醒", strings.Join(lines, "\n")
+}
@@ -30,22 +30,26 @@ func NewCourseService(app *app.App) *CourseService {
 }
 
 type CreateCourseRequest struct {
-	Code             string `json:"code" binding:"required"`
-	Name             string `json:"name" binding:"required"`
-	Duration         int    `json:"duration" binding:"required"`
-	ColorHex         string `json:"color_hex" binding:"required"`
-	RoomBufferMin    int    `json:"room_buffer_min" binding:"gte=0"`
-	TeacherBufferMin int    `json:"teacher_buffer_min" binding:"gte=0"`
+	Code             string              `json:"code" binding:"required"`
+	Name             string              `json:"name" binding:"required"`
+	Duration         int                 `json:"duration" binding:"required"`
+	ColorHex         string              `json:"color_hex" binding:"required"`
+	RoomBufferMin    int                 `json:"room_buffer_min" binding:"gte=0"`
+	TeacherBufferMin int                 `json:"teacher_buffer_min" binding:"gte=0"`
+	RequiredFeatures models.RoomFeatures `json:"required_features"`
+	MinCapacity      int                 `json:"min_capacity" binding:"gte=0"`
 }
 
 type UpdateCourseRequest struct {
-	Code             string `json:"code" binding:"required"`
-	Name             string `json:"name" binding:"required"`
-	Duration         int    `json:"duration" binding:"required"`
-	ColorHex         string `json:"color_hex" binding:"required"`
-	RoomBufferMin    *int   `json:"room_buffer_min"`    // 可選指標，如果為 nil 不更新
-	TeacherBufferMin *int   `json:"teacher_buffer_min"` // 可選指標，如果為 nil 不更新
-	IsActive         *bool  `json:"is_active"`          // 可選，如果提供則更新啟用狀態
+	Code             string               `json:"code" binding:"required"`
+	Name             string               `json:"name" binding:"required"`
+	Duration         int                  `json:"duration" binding:"required"`
+	ColorHex         string               `json:"color_hex" binding:"required"`
+	RoomBufferMin    *int                 `json:"room_buffer_min"`    // 可選指標，如果為 nil 不更新
+	TeacherBufferMin *int                 `json:"teacher_buffer_min"` // 可選指標，如果為 nil 不更新
+	RequiredFeatures *models.RoomFeatures `json:"required_features"`  // 可選，如果為 nil 不更新
+	MinCapacity      *int                 `json:"min_capacity"`       // 可選指標，如果為 nil 不更新
+	IsActive         *bool                `json:"is_active"`          // 可選，如果提供則更新啟用狀態
 }
 
 func (s *CourseService) GetCourses(ctx context.Context, centerID uint, query string, page, limit int) ([]models.Course, int64, *errInfos.Res, error) {
@@ -81,6 +85,8 @@ func (s *CourseService) GetCourses(ctx context.Context, centerID uint, query str
 				ColorHex:         item.ColorHex,
 				RoomBufferMin:    item.RoomBufferMin,
 				TeacherBufferMin: item.TeacherBufferMin,
+				RequiredFeatures: item.RequiredFeatures,
+				MinCapacity:      item.MinCapacity,
 				IsActive:         item.IsActive,
 			})
 		}
@@ -113,6 +119,8 @@ func (s *CourseService) GetCourses(ctx context.Context, centerID uint, query str
 			ColorHex:         c.ColorHex,
 			RoomBufferMin:    c.RoomBufferMin,
 			TeacherBufferMin: c.TeacherBufferMin,
+			RequiredFeatures: c.RequiredFeatures,
+			MinCapacity:      c.MinCapacity,
 			IsActive:         c.IsActive,
 		})
 	}
@@ -132,6 +140,10 @@ func (s *CourseService) GetActiveCourses(ctx context.Context, centerID uint) ([]
 }
 
 func (s *CourseService) CreateCourse(ctx context.Context, centerID, adminID uint, req *CreateCourseRequest) (*models.Course, *errInfos.Res, error) {
+	if err := ValidateRoomFeatures(req.RequiredFeatures); err != nil {
+		return nil, s.app.Err.New(errInfos.PARAMS_VALIDATE_ERROR), err
+	}
+
 	course := models.Course{
 		CenterID:         centerID,
 		Code:             req.Code,
@@ -140,6 +152,8 @@ func (s *CourseService) CreateCourse(ctx context.Context, centerID, adminID uint
 		ColorHex:         req.ColorHex,
 		RoomBufferMin:    req.RoomBufferMin,
 		TeacherBufferMin: req.TeacherBufferMin,
+		RequiredFeatures: req.RequiredFeatures,
+		MinCapacity:      req.MinCapacity,
 		IsActive:         true,
 		CreatedAt:        time.Now(),
 	}
@@ -185,6 +199,15 @@ func (s *CourseService) CreateCourse(ctx context.Context, centerID, adminID uint
 }
 
 func (s *CourseService) UpdateCourse(ctx context.Context, centerID, adminID, courseID uint, req *UpdateCourseRequest) (*models.Course, *errInfos.Res, error) {
+	if req.RequiredFeatures != nil {
+		if err := ValidateRoomFeatures(*req.RequiredFeatures); err != nil {
+			return nil, s.app.Err.New(errInfos.PARAMS_VALIDATE_ERROR), err
+		}
+	}
+	if req.MinCapacity != nil && *req.MinCapacity < 0 {
+		return nil, s.app.Err.New(errInfos.PARAMS_VALIDATE_ERROR), fmt.Errorf("min_capacity must not be negative")
+	}
+
 	var updatedCourse models.Course
 	err := s.courseRepo.Transaction(ctx, func(txRepo *repositories.CourseRepository) error {
 		// 查詢現有課程
@@ -215,6 +238,14 @@ func (s *CourseService) UpdateCourse(ctx context.Context, centerID, adminID, cou
 			course.TeacherBufferMin = *req.TeacherBufferMin
 		}
 
+		// 只有提供了才更新教室需求
+		if req.RequiredFeatures != nil {
+			course.RequiredFeatures = *req.RequiredFeatures
+		}
+		if req.MinCapacity != nil {
+			course.MinCapacity = *req.MinCapacity
+		}
+
 		// 如果提供了 IsActive，則更新啟用狀態
 		if req.IsActive != nil {
 			course.IsActive = *req.IsActive
@@ -0,0 +1,592 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+	"timeLedger/app"
+	"timeLedger/app/models"
+	"timeLedger/app/repositories"
+	"timeLedger/global/errInfos"
+	"timeLedger/libs"
+)
+
+// KPI 查詢與彙總限制
+const (
+	MaxKPIRangeDays        = 3660 // 約十年
+	KPIRecomputeWindowDays = 7    // 夜間排程重算最近幾天（涵蓋事後核准的例外單）
+)
+
+// DashboardKPIService 中心 KPI 儀表板（每日預先彙總）
+type DashboardKPIService struct {
+	BaseService
+	app            *app.App
+	kpiRepo        *repositories.CenterDailyKPIRepository
+	centerRepo     *repositories.CenterRepository
+	ruleRepo       *repositories.ScheduleRuleRepository
+	exceptionRepo  *repositories.ScheduleExceptionRepository
+	makeupRepo     *repositories.MakeupSessionRepository
+	invitationRepo *repositories.CenterInvitationRepository
+	teacherRepo    *repositories.TeacherRepository
+	expansionSvc   ScheduleExpansionService
+}
+
+// NewDashboardKPIService 建立 DashboardKPIService 實例
+func NewDashboardKPIService(app *app.App) *DashboardKPIService {
+	return &DashboardKPIService{
+		BaseService:    *NewBaseService(app, "DashboardKPIService"),
+		app:            app,
+		kpiRepo:        repositories.NewCenterDailyKPIRepository(app),
+		centerRepo:     repositories.NewCenterRepository(app),
+		ruleRepo:       repositories.NewScheduleRuleRepository(app),
+		exceptionRepo:  repositories.NewScheduleExceptionRepository(app),
+		makeupRepo:     repositories.NewMakeupSessionRepository(app),
+		invitationRepo: repositories.NewCenterInvitationRepository(app),
+		teacherRepo:    repositories.NewTeacherRepository(app),
+		expansionSvc:   NewScheduleExpansionService(app),
+	}
+}
+
+// DailyKPIInput 每日 KPI 計算所需資料
+type DailyKPIInput struct {
+	CenterID  uint
+	StartDate time.Time
+	EndDate   time.Time
+	Rules     []models.ScheduleRule
+	Holidays  []models.CenterHoliday
+	// MakeupWorkdays 補班日（日期 -> 比照星期），中心未開啟補班設定時為 nil
+	MakeupWorkdays map[string]int
+	// Exceptions 規則 ID -> 日期 -> 例外單
+	Exceptions  map[uint]map[string][]models.ScheduleException
+	Reviewed    []models.ScheduleException // 審核時間落在區間內的例外單
+	Makeups     []models.MakeupSession
+	Invitations []models.CenterInvitation // 區間內已接受的邀請
+	Location    *time.Location            // 審核、回覆時間換算日期使用的時區
+	Now         time.Time
+}
+
+// KPIBreakdown 分類統計
+type KPIBreakdown struct {
+	Key   string  `json:"key"`
+	Count int     `json:"count"`
+	Rate  float64 `json:"rate"` // 佔已排場次比例
+}
+
+// SessionKPI 場次統計
+type SessionKPI struct {
+	Planned          int     `json:"planned"`
+	Delivered        int     `json:"delivered"`
+	Cancelled        int     `json:"cancelled"`
+	Rescheduled      int     `json:"rescheduled"`
+	Makeup           int     `json:"makeup"`
+	DeliveryRate     float64 `json:"delivery_rate"`
+	CancellationRate float64 `json:"cancellation_rate"`
+	RescheduleRate   float64 `json:"reschedule_rate"`
+}
+
+// ApprovalLeadTimeKPI 例外單審核時效
+type ApprovalLeadTimeKPI struct {
+	Reviewed     int     `json:"reviewed"`
+	AverageHours float64 `json:"average_hours"`
+}
+
+// TeacherKPI 老師授課時數
+type TeacherKPI struct {
+	TeacherID   uint   `json:"teacher_id"`
+	TeacherName string `json:"teacher_name"`
+	Minutes     int    `json:"minutes"`
+}
+
+// TeacherUtilizationKPI 老師授課統計
+type TeacherUtilizationKPI struct {
+	ActiveTeachers int          `json:"active_teachers"`
+	TotalMinutes   int          `json:"total_minutes"`
+	AverageMinutes float64      `json:"average_minutes"` // 每位有授課老師的平均分鐘數
+	Teachers       []TeacherKPI `json:"teachers"`
+}
+
+// SubstituteKPI 代課統計
+type SubstituteKPI struct {
+	Sessions int     `json:"sessions"`
+	Rate     float64 `json:"rate"` // 佔實際上課場次比例
+}
+
+// DailyKPIPoint 每日趨勢
+type DailyKPIPoint struct {
+	Date      string `json:"date"`
+	Planned   int    `json:"planned"`
+	Delivered int    `json:"delivered"`
+	Cancelled int    `json:"cancelled"`
+}
+
+// DashboardKPIReport 期間 KPI 報表
+type DashboardKPIReport struct {
+	StartDate           string                `json:"start_date"`
+	EndDate             string                `json:"end_date"`
+	Sessions            SessionKPI            `json:"sessions"`
+	CancellationReasons []KPIBreakdown        `json:"cancellation_reasons"`
+	ExceptionTypes      []KPIBreakdown        `json:"exception_types"`
+	ApprovalLeadTime    ApprovalLeadTimeKPI   `json:"approval_lead_time"`
+	TeacherUtilization  TeacherUtilizationKPI `json:"teacher_utilization"`
+	Substitutes         SubstituteKPI         `json:"substitutes"`
+	NewTeachers         int                   `json:"new_teachers"`
+	Daily               []DailyKPIPoint       `json:"daily"`
+}
+
+// kpiRate 計算比例，取到小數第四位
+func kpiRate(count, total int) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return math.Round(float64(count)/float64(total)*10000) / 10000
+}
+
+// sessionMinutes 場次分鐘數，跨日課程以隔天結束計算
+func sessionMinutes(startTime, endTime string) int {
+	start := ParseTimeToMinutes(startTime)
+	end := ParseTimeToMinutes(endTime)
+	if end <= start {
+		end += 24 * 60
+	}
+	return end - start
+}
+
+// BuildDailyKPIs 依規則、假日、例外單與補課計算區間內每天的 KPI
+// 場次判斷與 CalculateSessionQuota 一致：只計入已開課（CONFIRMED）的規則，假日、暫停日與已核准的停課例外單視為取消
+func BuildDailyKPIs(input DailyKPIInput) []models.CenterDailyKPI {
+	loc := input.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	start := dateOnly(input.StartDate)
+	end := dateOnly(input.EndDate)
+
+	days := make(map[string]*models.CenterDailyKPI)
+	var order []string
+	for date := start; !date.After(end); date = date.AddDate(0, 0, 1) {
+		dateStr := date.Format("2006-01-02")
+		days[dateStr] = &models.CenterDailyKPI{
+			CenterID:       input.CenterID,
+			Date:           date,
+			CancelReasons:  models.KPICounter{},
+			ExceptionTypes: models.KPICounter{},
+			TeacherMinutes: models.KPICounter{},
+			AggregatedAt:   input.Now,
+		}
+		order = append(order, dateStr)
+	}
+
+	holidayMap := make(map[string]models.CenterHoliday, len(input.Holidays))
+	for _, h := range input.Holidays {
+		holidayMap[h.Date.Format("2006-01-02")] = h
+	}
+
+	for _, rule := range input.Rules {
+		if rule.Weekday == 0 {
+			continue
+		}
+		if rule.Status != "" && rule.Status != models.RuleStatusConfirmed {
+			continue
+		}
+
+		ruleStart := start
+		if !rule.EffectiveRange.StartDate.IsZero() && dateOnly(rule.EffectiveRange.StartDate).After(ruleStart) {
+			ruleStart = dateOnly(rule.EffectiveRange.StartDate)
+		}
+		ruleEnd := end
+		if !rule.EffectiveRange.EndDate.IsZero() && dateOnly(rule.EffectiveRange.EndDate).Before(ruleEnd) {
+			ruleEnd = dateOnly(rule.EffectiveRange.EndDate)
+		}
+		// 已刪除的規則只計入刪除日之前的場次
+		if rule.DeletedAt.Valid {
+			lastDay := dateOnly(rule.DeletedAt.Time.In(loc)).AddDate(0, 0, -1)
+			if lastDay.Before(ruleEnd) {
+				ruleEnd = lastDay
+			}
+		}
+
+		suspended := make(map[string]bool, len(rule.SuspendedDates))
+		for _, d := range rule.SuspendedDates {
+			suspended[d.Format("2006-01-02")] = true
+		}
+		minutes := sessionMinutes(rule.StartTime, rule.EndTime)
+
+		for date := ruleStart; !date.After(ruleEnd); date = date.AddDate(0, 0, 1) {
+			if ResolveEffectiveWeekday(date, input.MakeupWorkdays) != rule.Weekday {
+				continue
+			}
+			dateStr := date.Format("2006-01-02")
+			day := days[dateStr]
+			day.PlannedSessions++
+
+			exceptions := input.Exceptions[rule.ID][dateStr]
+			for _, exc := range exceptions {
+				if exc.Status == "APPROVED" {
+					day.ExceptionTypes[exc.ExceptionType]++
+				}
+			}
+
+			reason := ""
+			if holiday, ok := holidayMap[dateStr]; ok && (holiday.ForceCancel || rule.SkipHoliday) {
+				reason = models.SessionCancelReasonHoliday
+			} else if suspended[dateStr] {
+				reason = models.SessionCancelReasonSuspended
+			} else if findApprovedCancel(exceptions) != nil {
+				reason = models.SessionCancelReasonException
+			}
+			if reason != "" {
+				day.CancelledSessions++
+				day.CancelReasons[reason]++
+				continue
+			}
+
+			day.DeliveredSessions++
+			var substitute *uint
+			for _, exc := range exceptions {
+				if exc.Status != "APPROVED" {
+					continue
+				}
+				switch exc.ExceptionType {
+				case "RESCHEDULE":
+					day.RescheduledSessions++
+				case "REPLACE_TEACHER":
+					if exc.NewTeacherID != nil {
+						substitute = exc.NewTeacherID
+					}
+				}
+			}
+
+			teachers := rule.AllTeacherIDs()
+			if substitute != nil {
+				day.SubstituteSessions++
+				if rule.TeacherID != nil {
+					// 代課老師取代主教
+					for i, id := range teachers {
+						if id == *rule.TeacherID {
+							teachers[i] = *substitute
+						}
+					}
+				} else {
+					teachers = append(teachers, *substitute)
+				}
+			}
+			for _, teacherID := range teachers {
+				day.TeacherMinutes[strconv.FormatUint(uint64(teacherID), 10)] += minutes
+			}
+		}
+	}
+
+	for _, m := range input.Makeups {
+		if m.Status != models.MakeupStatusScheduled {
+			continue
+		}
+		day, ok := days[m.MakeupDate.Format("2006-01-02")]
+		if !ok {
+			continue
+		}
+		day.MakeupSessions++
+		day.DeliveredSessions++
+		if m.TeacherID != nil {
+			day.TeacherMinutes[strconv.FormatUint(uint64(*m.TeacherID), 10)] += sessionMinutes(m.StartTime, m.EndTime)
+		}
+	}
+
+	for _, exc := range input.Reviewed {
+		if exc.ReviewedAt == nil {
+			continue
+		}
+		day, ok := days[exc.ReviewedAt.In(loc).Format("2006-01-02")]
+		if !ok {
+			continue
+		}
+		lead := exc.ReviewedAt.Sub(exc.CreatedAt)
+		if lead < 0 {
+			lead = 0
+		}
+		day.ReviewedExceptions++
+		day.ApprovalLeadMinutes += int64(lead / time.Minute)
+	}
+
+	for _, inv := range input.Invitations {
+		if inv.Status != models.InvitationStatusAccepted || inv.RespondedAt == nil {
+			continue
+		}
+		if day, ok := days[inv.RespondedAt.In(loc).Format("2006-01-02")]; ok {
+			day.NewTeachers++
+		}
+	}
+
+	rows := make([]models.CenterDailyKPI, 0, len(order))
+	for _, dateStr := range order {
+		rows = append(rows, *days[dateStr])
+	}
+	return rows
+}
+
+// SummarizeKPIs 將每日 KPI 彙總為期間報表（老師姓名由呼叫端補上）
+func SummarizeKPIs(rows []models.CenterDailyKPI, startDate, endDate time.Time) DashboardKPIReport {
+	report := DashboardKPIReport{
+		StartDate:           startDate.Format("2006-01-02"),
+		EndDate:             endDate.Format("2006-01-02"),
+		CancellationReasons: []KPIBreakdown{},
+		ExceptionTypes:      []KPIBreakdown{},
+		Daily:               []DailyKPIPoint{},
+	}
+
+	reasons := models.KPICounter{}
+	types := models.KPICounter{}
+	teacherMinutes := models.KPICounter{}
+	var leadMinutes int64
+
+	for _, row := range rows {
+		report.Sessions.Planned += row.PlannedSessions
+		report.Sessions.Delivered += row.DeliveredSessions
+		report.Sessions.Cancelled += row.CancelledSessions
+		report.Sessions.Rescheduled += row.RescheduledSessions
+		report.Sessions.Makeup += row.MakeupSessions
+		report.Substitutes.Sessions += row.SubstituteSessions
+		report.ApprovalLeadTime.Reviewed += row.ReviewedExceptions
+		report.NewTeachers += row.NewTeachers
+		leadMinutes += row.ApprovalLeadMinutes
+		reasons.Add(row.CancelReasons)
+		types.Add(row.ExceptionTypes)
+		teacherMinutes.Add(row.TeacherMinutes)
+
+		report.Daily = append(report.Daily, DailyKPIPoint{
+			Date:      row.Date.Format("2006-01-02"),
+			Planned:   row.PlannedSessions,
+			Delivered: row.DeliveredSessions,
+			Cancelled: row.CancelledSessions,
+		})
+	}
+
+	planned := report.Sessions.Planned
+	report.Sessions.DeliveryRate = kpiRate(report.Sessions.Delivered-report.Sessions.Makeup, planned)
+	report.Sessions.CancellationRate = kpiRate(report.Sessions.Cancelled, planned)
+	report.Sessions.RescheduleRate = kpiRate(report.Sessions.Rescheduled, planned)
+	report.Substitutes.Rate = kpiRate(report.Substitutes.Sessions, report.Sessions.Delivered)
+	if report.ApprovalLeadTime.Reviewed > 0 {
+		report.ApprovalLeadTime.AverageHours = math.Round(float64(leadMinutes)/float64(report.ApprovalLeadTime.Reviewed)/60*100) / 100
+	}
+
+	report.CancellationReasons = kpiBreakdowns(reasons, planned)
+	report.ExceptionTypes = kpiBreakdowns(types, planned)
+
+	teachers := []TeacherKPI{}
+	for key, minutes := range teacherMinutes {
+		id, err := strconv.ParseUint(key, 10, 64)
+		if err != nil || minutes == 0 {
+			continue
+		}
+		teachers = append(teachers, TeacherKPI{TeacherID: uint(id), Minutes: minutes})
+		report.TeacherUtilization.TotalMinutes += minutes
+	}
+	sort.Slice(teachers, func(i, j int) bool {
+		if teachers[i].Minutes != teachers[j].Minutes {
+			return teachers[i].Minutes > teachers[j].Minutes
+		}
+		return teachers[i].TeacherID < teachers[j].TeacherID
+	})
+	report.TeacherUtilization.Teachers = teachers
+	report.TeacherUtilization.ActiveTeachers = len(teachers)
+	if len(teachers) > 0 {
+		report.TeacherUtilization.AverageMinutes = math.Round(float64(report.TeacherUtilization.TotalMinutes)/float64(len(teachers))*100) / 100
+	}
+
+	return report
+}
+
+// kpiBreakdowns 依次數由多到少輸出分類統計
+func kpiBreakdowns(counter models.KPICounter, total int) []KPIBreakdown {
+	result := []KPIBreakdown{}
+	for key, count := range counter {
+		if count == 0 {
+			continue
+		}
+		result = append(result, KPIBreakdown{Key: key, Count: count, Rate: kpiRate(count, total)})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Key < result[j].Key
+	})
+	return result
+}
+
+// GetKPIs 取得期間 KPI，只統計到昨天；尚未彙總的日期會先補算並寫入
+func (s *DashboardKPIService) GetKPIs(ctx context.Context, centerID uint, startDate, endDate time.Time) (*DashboardKPIReport, *errInfos.Res, error) {
+	startDate = dateOnly(startDate)
+	endDate = dateOnly(endDate)
+	yesterday := dateOnly(libs.TodayInTaiwan()).AddDate(0, 0, -1)
+	if endDate.After(yesterday) {
+		endDate = yesterday
+	}
+
+	if endDate.Before(startDate) {
+		return nil, s.app.Err.New(errInfos.PARAMS_VALIDATE_ERROR), fmt.Errorf("date range must end before today and not start after end_date")
+	}
+	if endDate.Sub(startDate) > time.Duration(MaxKPIRangeDays)*24*time.Hour {
+		return nil, s.app.Err.New(errInfos.PARAMS_VALIDATE_ERROR), fmt.Errorf("date range must not exceed %d days", MaxKPIRangeDays)
+	}
+
+	rows, err := s.kpiRepo.ListByDateRange(ctx, centerID, startDate, endDate)
+	if err != nil {
+		return nil, s.app.Err.New(errInfos.SQL_ERROR), err
+	}
+
+	if missingStart, missingEnd, ok := missingKPIRange(rows, startDate, endDate); ok {
+		s.Logger.Info("backfilling center KPIs", "center_id", centerID, "start", missingStart.Format("2006-01-02"), "end", missingEnd.Format("2006-01-02"))
+		if err := s.AggregateRange(ctx, centerID, missingStart, missingEnd); err != nil {
+			return nil, s.app.Err.New(errInfos.SQL_ERROR), err
+		}
+		rows, err = s.kpiRepo.ListByDateRange(ctx, centerID, startDate, endDate)
+		if err != nil {
+			return nil, s.app.Err.New(errInfos.SQL_ERROR), err
+		}
+	}
+
+	report := SummarizeKPIs(rows, startDate, endDate)
+
+	teacherIDs := make([]uint, 0, len(report.TeacherUtilization.Teachers))
+	for _, t := range report.TeacherUtilization.Teachers {
+		teacherIDs = append(teacherIDs, t.TeacherID)
+	}
+	if len(teacherIDs) > 0 {
+		teachers, err := s.teacherRepo.BatchGetByIDs(ctx, teacherIDs)
+		if err == nil {
+			names := make(map[uint]string, len(teachers))
+			for _, t := range teachers {
+				names[t.ID] = t.Name
+			}
+			for i := range report.TeacherUtilization.Teachers {
+				report.TeacherUtilization.Teachers[i].TeacherName = names[report.TeacherUtilization.Teachers[i].TeacherID]
+			}
+		}
+	}
+
+	return &report, nil, nil
+}
+
+// missingKPIRange 找出尚未彙總的最早與最晚日期
+func missingKPIRange(rows []models.CenterDailyKPI, startDate, endDate time.Time) (time.Time, time.Time, bool) {
+	existing := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		existing[row.Date.Format("2006-01-02")] = true
+	}
+
+	var first, last time.Time
+	found := false
+	for date := startDate; !date.After(endDate); date = date.AddDate(0, 0, 1) {
+		if existing[date.Format("2006-01-02")] {
+			continue
+		}
+		if !found {
+			first = date
+			found = true
+		}
+		last = date
+	}
+	return first, last, found
+}
+
+// AggregateRange 重新計算並寫入中心在日期區間內的每日 KPI
+func (s *DashboardKPIService) AggregateRange(ctx context.Context, centerID uint, startDate, endDate time.Time) error {
+	loc := libs.GetTaiwanLocation()
+	start := time.Date(startDate.Year(), startDate.Month(), startDate.Day(), 0, 0, 0, 0, loc)
+	end := time.Date(endDate.Year(), endDate.Month(), endDate.Day(), 0, 0, 0, 0, loc)
+	endExclusive := end.AddDate(0, 0, 1)
+
+	// 回補過去日期時，之後才刪除的規則當時仍有排課
+	rules, err := s.ruleRepo.ListByCenterIDWithDeleted(ctx, centerID)
+	if err != nil {
+		return err
+	}
+	ruleIDs := make([]uint, 0, len(rules))
+	for _, rule := range rules {
+		ruleIDs = append(ruleIDs, rule.ID)
+	}
+
+	holidays, err := s.expansionSvc.GetHolidaysByDateRange(ctx, centerID, start, end)
+	if err != nil {
+		return err
+	}
+	exceptions, err := s.exceptionRepo.GetByRuleIDsAndDateRange(ctx, ruleIDs, start, end)
+	if err != nil {
+		return err
+	}
+	reviewed, err := s.exceptionRepo.ListReviewedByDateRange(ctx, centerID, start, endExclusive)
+	if err != nil {
+		return err
+	}
+	makeups, err := s.makeupRepo.ListScheduledByDateRange(ctx, centerID, start, end)
+	if err != nil {
+		return err
+	}
+	invitations, err := s.invitationRepo.ListAcceptedByDateRange(ctx, centerID, start, endExclusive)
+	if err != nil {
+		return err
+	}
+
+	rows := BuildDailyKPIs(DailyKPIInput{
+		CenterID:       centerID,
+		StartDate:      start,
+		EndDate:        end,
+		Rules:          rules,
+		Holidays:       holidays,
+		MakeupWorkdays: s.expansionSvc.GetMakeupWorkdayMap(ctx, centerID, start, end),
+		Exceptions:     exceptions,
+		Reviewed:       reviewed,
+		Makeups:        makeups,
+		Invitations:    invitations,
+		Location:       loc,
+		Now:            time.Now(),
+	})
+	return s.kpiRepo.UpsertDays(ctx, rows)
+}
+
+// RefreshDates 重算指定日期的 KPI（例外單事後審核時使用），今天以後尚未彙總的日期略過
+func (s *DashboardKPIService) RefreshDates(ctx context.Context, centerID uint, dates ...time.Time) error {
+	loc := libs.GetTaiwanLocation()
+	today := libs.TodayInTaiwan()
+	refreshed := make(map[string]bool, len(dates))
+	for _, date := range dates {
+		date = date.In(loc)
+		day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, loc)
+		key := day.Format("2006-01-02")
+		if !day.Before(today) || refreshed[key] {
+			continue
+		}
+		refreshed[key] = true
+		if err := s.AggregateRange(ctx, centerID, day, day); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AggregateRecentDays 夜間排程：重算所有中心最近幾天的 KPI
+func (s *DashboardKPIService) AggregateRecentDays(ctx context.Context) error {
+	centers, err := s.centerRepo.ListActive(ctx)
+	if err != nil {
+		return err
+	}
+
+	yesterday := dateOnly(libs.TodayInTaiwan()).AddDate(0, 0, -1)
+	start := yesterday.AddDate(0, 0, -(KPIRecomputeWindowDays - 1))
+
+	var firstErr error
+	for _, center := range centers {
+		if err := s.AggregateRange(ctx, center.ID, start, yesterday); err != nil {
+			s.Logger.Error("failed to aggregate center KPIs", "center_id", center.ID, "error", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
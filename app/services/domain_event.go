@@ -0,0 +1,275 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+	"timeLedger/app"
+	"timeLedger/app/models"
+	"timeLedger/app/repositories"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// DomainEventMaxRelayAttempts 轉送至 RabbitMQ 的最大嘗試次數，超過即標記 FAILED
+	DomainEventMaxRelayAttempts = 10
+	// DomainEventRelayBatchSize 每次轉送的事件數
+	DomainEventRelayBatchSize = 100
+	// domainEventRelayLease 搶占後的保留時間，relay 中途停止時事件會在期滿後重新轉送
+	domainEventRelayLease = time.Minute
+)
+
+// 規則異動動作
+const (
+	RuleChangeCreated = "CREATED"
+	RuleChangeUpdated = "UPDATED"
+	RuleChangeDeleted = "DELETED"
+)
+
+// DomainEventInput 寫入 outbox 的事件內容
+type DomainEventInput struct {
+	CenterID      uint
+	EventType     string
+	AggregateType string
+	AggregateID   uint
+	ActorType     string
+	ActorID       uint
+	Payload       any
+}
+
+// RuleChangedPayload 排課規則新增、更新或刪除
+type RuleChangedPayload struct {
+	Action     string `json:"action"`
+	RuleIDs    []uint `json:"rule_ids"`
+	TeacherIDs []uint `json:"teacher_ids"`
+	UpdateMode string `json:"update_mode,omitempty"`
+}
+
+// ExceptionSubmittedPayload 老師提交例外申請
+type ExceptionSubmittedPayload struct {
+	RuleID        uint   `json:"rule_id"`
+	TeacherID     uint   `json:"teacher_id"`
+	ExceptionType string `json:"exception_type"`
+	OriginalDate  string `json:"original_date"`
+}
+
+// ExceptionReviewedPayload 例外申請審核完成
+type ExceptionReviewedPayload struct {
+	RuleID uint   `json:"rule_id"`
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// TeacherMergedPayload 老師資料合併
+type TeacherMergedPayload struct {
+	SourceTeacherID uint `json:"source_teacher_id"`
+	TargetTeacherID uint `json:"target_teacher_id"`
+}
+
+// TeacherMembershipPayload 老師加入或離開中心
+type TeacherMembershipPayload struct {
+	TeacherID   uint   `json:"teacher_id"`
+	TeacherName string `json:"teacher_name"`
+	Source      string `json:"source,omitempty"` // INVITATION、INVITE_LINK、REMOVED、DELETED
+}
+
+// HolidayCreatedPayload 新增中心假日
+type HolidayCreatedPayload struct {
+	HolidayIDs []uint   `json:"holiday_ids"`
+	Dates      []string `json:"dates"`
+	Source     string   `json:"source"` // MANUAL、BULK、IMPORT
+}
+
+// DomainEventMessage 轉送給消費端的事件訊息
+type DomainEventMessage struct {
+	EventID       string          `json:"event_id"`
+	CenterID      uint            `json:"center_id"`
+	EventType     string          `json:"event_type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   uint            `json:"aggregate_id"`
+	ActorType     string          `json:"actor_type,omitempty"`
+	ActorID       uint            `json:"actor_id,omitempty"`
+	Payload       json.RawMessage `json:"payload"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+}
+
+// NewDomainEventMessage 由 outbox 紀錄建立訊息
+func NewDomainEventMessage(event models.DomainEvent) DomainEventMessage {
+	return DomainEventMessage{
+		EventID:       event.EventID,
+		CenterID:      event.CenterID,
+		EventType:     event.EventType,
+		AggregateType: event.AggregateType,
+		AggregateID:   event.AggregateID,
+		ActorType:     event.ActorType,
+		ActorID:       event.ActorID,
+		Payload:       json.RawMessage(event.Payload),
+		OccurredAt:    event.OccurredAt,
+	}
+}
+
+// DecodePayload 解析事件內容
+func (m DomainEventMessage) DecodePayload(v any) error {
+	if len(m.Payload) == 0 {
+		return errors.New("empty domain event payload")
+	}
+	return json.Unmarshal(m.Payload, v)
+}
+
+// DomainEventPublisher 事件轉送目標（RabbitMQ 或程序內處理）
+type DomainEventPublisher interface {
+	Publish(ctx context.Context, msg DomainEventMessage) error
+}
+
+// DomainEventHandler 事件消費端，需可重複執行（at-least-once）
+type DomainEventHandler interface {
+	Name() string
+	Handle(ctx context.Context, msg DomainEventMessage) error
+}
+
+// LocalDomainEventPublisher 未啟用 RabbitMQ 時直接在程序內依序呼叫消費端
+// 每個消費端各自執行，失敗不影響後續消費端；有任一失敗時回傳合併的錯誤，由 outbox 重試整筆事件
+type LocalDomainEventPublisher struct {
+	handlers []DomainEventHandler
+}
+
+// NewLocalDomainEventPublisher 建立程序內事件轉送
+func NewLocalDomainEventPublisher(handlers ...DomainEventHandler) *LocalDomainEventPublisher {
+	return &LocalDomainEventPublisher{handlers: handlers}
+}
+
+func (p *LocalDomainEventPublisher) Publish(ctx context.Context, msg DomainEventMessage) error {
+	var errs []error
+	for _, h := range p.handlers {
+		if err := h.Handle(ctx, msg); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", h.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// DomainEventService 領域事件 outbox
+type DomainEventService struct {
+	BaseService
+	eventRepo *repositories.DomainEventRepository
+}
+
+// NewDomainEventService 建立領域事件服務
+func NewDomainEventService(app *app.App) *DomainEventService {
+	baseSvc := NewBaseService(app, "DomainEventService")
+	svc := &DomainEventService{
+		BaseService: *baseSvc,
+	}
+	if app.MySQL != nil {
+		svc.eventRepo = repositories.NewDomainEventRepository(app)
+	}
+	return svc
+}
+
+// RecordWithTx 在業務交易中寫入事件，交易回滾時事件一併取消
+func (s *DomainEventService) RecordWithTx(ctx context.Context, tx *gorm.DB, input DomainEventInput) error {
+	payload, err := json.Marshal(input.Payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal domain event payload: %w", err)
+	}
+
+	now := time.Now()
+	event := models.DomainEvent{
+		EventID:       uuid.New().String(),
+		CenterID:      input.CenterID,
+		EventType:     input.EventType,
+		AggregateType: input.AggregateType,
+		AggregateID:   input.AggregateID,
+		ActorType:     input.ActorType,
+		ActorID:       input.ActorID,
+		Payload:       string(payload),
+		Status:        models.DomainEventStatusPending,
+		AvailableAt:   now,
+		OccurredAt:    now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if _, err := s.eventRepo.CreateWithDB(ctx, tx, event); err != nil {
+		return fmt.Errorf("failed to record domain event: %w", err)
+	}
+	return nil
+}
+
+// Record 寫入不屬於任何交易的事件（業務資料已提交後才記錄）
+func (s *DomainEventService) Record(ctx context.Context, input DomainEventInput) error {
+	return s.RecordWithTx(ctx, s.App.MySQL.WDB, input)
+}
+
+// RelayPending 轉送到期的事件，回傳成功轉送的筆數
+func (s *DomainEventService) RelayPending(ctx context.Context, publisher DomainEventPublisher, limit int) (int, error) {
+	now := time.Now()
+	events, err := s.eventRepo.ListDue(ctx, now, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	published := 0
+	for _, event := range events {
+		claimed, err := s.eventRepo.Claim(ctx, event.ID, now, now.Add(domainEventRelayLease))
+		if err != nil {
+			return published, err
+		}
+		if !claimed {
+			continue
+		}
+
+		if pubErr := publisher.Publish(ctx, NewDomainEventMessage(event)); pubErr != nil {
+			attempts := event.Attempts + 1
+			status := models.DomainEventStatusPending
+			if attempts >= DomainEventMaxRelayAttempts {
+				status = models.DomainEventStatusFailed
+			}
+			s.Logger.Warn("domain event relay failed",
+				"event_id", event.EventID, "type", event.EventType, "attempts", attempts, "error", pubErr)
+			if err := s.eventRepo.MarkRetry(ctx, event.ID, status, attempts, pubErr.Error(), time.Now().Add(DomainEventRetryDelay(attempts)), time.Now()); err != nil {
+				return published, err
+			}
+			continue
+		}
+
+		if err := s.eventRepo.MarkPublished(ctx, event.ID, time.Now()); err != nil {
+			return published, err
+		}
+		published++
+	}
+
+	return published, nil
+}
+
+// DomainEventRetryDelay 轉送失敗後的退避時間（5 秒起倍增，最多 10 分鐘）
+func DomainEventRetryDelay(attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+	delay := 5 * time.Second
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= 10*time.Minute {
+			return 10 * time.Minute
+		}
+	}
+	return delay
+}
+
+// uniqueIDs 去除重複與零值 ID，保留原順序
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	result := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if id == 0 || seen[id] {
+			continue
+		}
+		seen[id] = true
+		result = append(result, id)
+	}
+	return result
+}
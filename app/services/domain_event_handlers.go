@@ -0,0 +1,197 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"timeLedger/app"
+	"timeLedger/app/models"
+	"timeLedger/app/repositories"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// domainEventHandledTTL 消費端去重紀錄保留時間
+const domainEventHandledTTL = 7 * 24 * time.Hour
+
+// DomainEventCacheHandler 依領域事件清除課表快取
+type DomainEventCacheHandler struct {
+	BaseService
+	ruleRepo *repositories.ScheduleRuleRepository
+	cacheSvc *CacheService
+}
+
+// NewDomainEventCacheHandler 建立快取失效消費端
+func NewDomainEventCacheHandler(app *app.App) *DomainEventCacheHandler {
+	baseSvc := NewBaseService(app, "DomainEventCacheHandler")
+	return &DomainEventCacheHandler{
+		BaseService: *baseSvc,
+		ruleRepo:    repositories.NewScheduleRuleRepository(app),
+		cacheSvc:    NewCacheService(app),
+	}
+}
+
+func (h *DomainEventCacheHandler) Name() string {
+	return "cache"
+}
+
+func (h *DomainEventCacheHandler) Handle(ctx context.Context, msg DomainEventMessage) error {
+	var teacherIDs []uint
+
+	switch msg.EventType {
+	case models.DomainEventRuleChanged:
+		var payload RuleChangedPayload
+		if err := msg.DecodePayload(&payload); err != nil {
+			return err
+		}
+		teacherIDs = payload.TeacherIDs
+	case models.DomainEventExceptionSubmitted, models.DomainEventExceptionReviewed:
+		var payload struct {
+			RuleID uint `json:"rule_id"`
+		}
+		if err := msg.DecodePayload(&payload); err != nil {
+			return err
+		}
+		rule, err := h.ruleRepo.GetByIDAndCenterID(ctx, payload.RuleID, msg.CenterID)
+		if err == nil {
+			teacherIDs = rule.AllTeacherIDs()
+		}
+	case models.DomainEventTeacherMerged:
+		var payload TeacherMergedPayload
+		if err := msg.DecodePayload(&payload); err != nil {
+			return err
+		}
+		teacherIDs = []uint{payload.SourceTeacherID, payload.TargetTeacherID}
+	default:
+		return nil
+	}
+
+	if err := h.cacheSvc.DeleteByPattern(ctx, CacheCategorySchedule, centerScheduleCachePattern(msg.CenterID)); err != nil {
+		return fmt.Errorf("failed to invalidate center schedule cache: %w", err)
+	}
+	for _, teacherID := range teacherIDs {
+		if err := h.cacheSvc.DeleteByPattern(ctx, CacheCategorySchedule, teacherScheduleCachePattern(teacherID, msg.CenterID)); err != nil {
+			return fmt.Errorf("failed to invalidate teacher schedule cache: %w", err)
+		}
+	}
+	return nil
+}
+
+// DomainEventNotificationHandler 依領域事件發送例外申請與審核結果通知，並推播至中心的 LINE 群組
+type DomainEventNotificationHandler struct {
+	BaseService
+	exceptionRepo     *repositories.ScheduleExceptionRepository
+	ruleRepo          *repositories.ScheduleRuleRepository
+	teacherRepo       *repositories.TeacherRepository
+	centerRepo        *repositories.CenterRepository
+	notificationQueue NotificationQueueService
+	lineGroups        *LineGroupService
+	redis             *redis.Client
+}
+
+// NewDomainEventNotificationHandler 建立通知消費端
+func NewDomainEventNotificationHandler(app *app.App) *DomainEventNotificationHandler {
+	baseSvc := NewBaseService(app, "DomainEventNotificationHandler")
+	h := &DomainEventNotificationHandler{
+		BaseService:       *baseSvc,
+		exceptionRepo:     repositories.NewScheduleExceptionRepository(app),
+		ruleRepo:          repositories.NewScheduleRuleRepository(app),
+		teacherRepo:       repositories.NewTeacherRepository(app),
+		centerRepo:        repositories.NewCenterRepository(app),
+		notificationQueue: NewNotificationQueueService(app),
+		lineGroups:        NewLineGroupService(app),
+	}
+	if app.Redis != nil {
+		h.redis = app.Redis.DB0
+	}
+	return h
+}
+
+func (h *DomainEventNotificationHandler) Name() string {
+	return "notification"
+}
+
+func (h *DomainEventNotificationHandler) Handle(ctx context.Context, msg DomainEventMessage) error {
+	switch msg.EventType {
+	case models.DomainEventExceptionSubmitted, models.DomainEventExceptionReviewed, models.DomainEventHolidayCreated:
+	default:
+		return nil
+	}
+
+	return h.notify(ctx, msg, h.deliveryOnce(msg))
+}
+
+// deliveryOnce 同一事件對每個收件者（管理員、老師、群組）只發送一次，發送失敗時釋放以便重試
+// 重試時只補發失敗的收件者，不會重複通知已送達者
+func (h *DomainEventNotificationHandler) deliveryOnce(msg DomainEventMessage) DeliveryOnce {
+	if h.redis == nil {
+		return nil
+	}
+	return func(ctx context.Context, recipient string, send func() error) error {
+		key := fmt.Sprintf("%s:domain_events:handled:%s:%s:%s", CacheKeyPrefix, h.Name(), msg.EventID, recipient)
+		first, err := h.redis.SetNX(ctx, key, 1, domainEventHandledTTL).Result()
+		if err != nil {
+			return err
+		}
+		if !first {
+			return nil
+		}
+		if err := send(); err != nil {
+			h.redis.Del(ctx, key)
+			return err
+		}
+		return nil
+	}
+}
+
+func (h *DomainEventNotificationHandler) notify(ctx context.Context, msg DomainEventMessage, once DeliveryOnce) error {
+	if msg.EventType == models.DomainEventHolidayCreated {
+		var payload HolidayCreatedPayload
+		if err := msg.DecodePayload(&payload); err != nil {
+			return err
+		}
+		return h.lineGroups.NotifyHolidaysCreated(ctx, msg.CenterID, payload.HolidayIDs, once)
+	}
+
+	exception, err := h.exceptionRepo.GetByID(ctx, msg.AggregateID)
+	if err != nil {
+		return fmt.Errorf("failed to get exception: %w", err)
+	}
+
+	switch msg.EventType {
+	case models.DomainEventExceptionSubmitted:
+		var payload ExceptionSubmittedPayload
+		if err := msg.DecodePayload(&payload); err != nil {
+			return err
+		}
+		teacher, _ := h.teacherRepo.GetByID(ctx, payload.TeacherID)
+		teacherName := teacher.Name
+		if teacherName == "" {
+			teacherName = "老師"
+		}
+		center, _ := h.centerRepo.GetByID(ctx, msg.CenterID)
+		// 管理員與群組通知互不影響，任一失敗時整筆重試，已送達者由 once 略過
+		return errors.Join(
+			h.notificationQueue.NotifyExceptionSubmittedSync(ctx, &exception, teacherName, center.Name, once),
+			h.lineGroups.NotifyExceptionSubmitted(ctx, &exception, teacherName, center.Name, once),
+		)
+
+	case models.DomainEventExceptionReviewed:
+		var payload ExceptionReviewedPayload
+		if err := msg.DecodePayload(&payload); err != nil {
+			return err
+		}
+		rule, err := h.ruleRepo.GetByID(ctx, exception.RuleID)
+		if err != nil || rule.TeacherID == nil {
+			return nil
+		}
+		teacher, err := h.teacherRepo.GetByID(ctx, *rule.TeacherID)
+		if err != nil {
+			return nil
+		}
+		return h.notificationQueue.NotifyExceptionResultSync(ctx, &exception, &teacher, payload.Status == "APPROVED", payload.Reason, once)
+	}
+
+	return nil
+}
@@ -0,0 +1,204 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+	"timeLedger/app"
+	"timeLedger/app/models"
+	"timeLedger/app/repositories"
+	"timeLedger/libs"
+)
+
+// emailBounceSuppressWindow 退信後暫停發送同一地址的期間
+const emailBounceSuppressWindow = 30 * 24 * time.Hour
+
+// EmailSender 郵件發送端（SMTP，或測試時指向本機 SMTP catcher）
+type EmailSender interface {
+	Send(ctx context.Context, msg libs.MailMessage) error
+}
+
+// EmailNotification 單封通知郵件
+type EmailNotification struct {
+	CenterID      uint // 來源中心，0 表示不屬於特定中心
+	RecipientType string
+	RecipientID   uint
+	Address       string
+	TemplateType  string // 通知事件或 DIGEST / GENERAL
+	Data          EmailTemplateData
+}
+
+// EmailQueuePayload 寫入 notification_queues 的郵件內容，可重新產生郵件
+type EmailQueuePayload struct {
+	TemplateType string            `json:"template_type"`
+	Subject      string            `json:"subject"`
+	Data         EmailTemplateData `json:"data"`
+}
+
+// EmailNotificationService Email 通知管道，發送結果（含退信）記錄於 notification_queues
+type EmailNotificationService struct {
+	BaseService
+	app       *app.App
+	sender    EmailSender
+	queueRepo *repositories.NotificationQueueRepository
+}
+
+// NewEmailNotificationService 建立 Email 通知服務；未設定 SMTP 時管道停用
+func NewEmailNotificationService(app *app.App) *EmailNotificationService {
+	svc := newEmailNotificationService(app)
+	mailer, err := libs.NewSMTPMailer(app.Env)
+	if err != nil {
+		svc.Logger.Error("invalid SMTP configuration, email channel disabled", "error", err)
+	} else if mailer != nil {
+		svc.sender = mailer
+	}
+	return svc
+}
+
+// NewEmailNotificationServiceWithSender 以指定的發送端建立 Email 通知服務
+func NewEmailNotificationServiceWithSender(app *app.App, sender EmailSender) *EmailNotificationService {
+	svc := newEmailNotificationService(app)
+	svc.sender = sender
+	return svc
+}
+
+func newEmailNotificationService(app *app.App) *EmailNotificationService {
+	svc := &EmailNotificationService{
+		BaseService: *NewBaseService(app, "EmailNotificationService"),
+		app:         app,
+	}
+	if app.MySQL != nil {
+		svc.queueRepo = repositories.NewNotificationQueueRepository(app)
+	}
+	return svc
+}
+
+// Enabled Email 管道是否可用
+func (s *EmailNotificationService) Enabled() bool {
+	return s != nil && s.sender != nil
+}
+
+// Send 發送通知郵件
+// 永久拒收（SMTP 5xx）記為退信並回傳 nil，之後同一地址暫停發送；
+// 暫時性錯誤回傳錯誤，並由通知派送器依重試策略重送
+func (s *EmailNotificationService) Send(ctx context.Context, n EmailNotification) error {
+	if !s.Enabled() || n.Address == "" {
+		return nil
+	}
+
+	rendered, err := RenderEmailTemplate(n.TemplateType, n.Data)
+	if err != nil {
+		return fmt.Errorf("failed to render email: %w", err)
+	}
+
+	now := time.Now()
+	var record *models.NotificationQueue
+	if s.queueRepo != nil {
+		bounced, err := s.queueRepo.HasRecentBounce(ctx, models.NotificationChannelEmail, n.Address, now.Add(-emailBounceSuppressWindow))
+		if err != nil {
+			return err
+		}
+		if bounced {
+			s.Logger.Info("skip email to bounced address", "address", n.Address, "type", n.TemplateType)
+			return nil
+		}
+
+		payload, _ := json.Marshal(EmailQueuePayload{
+			TemplateType: n.TemplateType,
+			Subject:      rendered.Subject,
+			Data:         n.Data,
+		})
+		// scheduled_at 設為租約期限，發送中途停止時由通知派送器接手
+		created, err := s.queueRepo.Create(ctx, models.NotificationQueue{
+			CenterID:         n.CenterID,
+			Type:             n.TemplateType,
+			Channel:          models.NotificationChannelEmail,
+			RecipientID:      n.RecipientID,
+			RecipientType:    n.RecipientType,
+			RecipientAddress: n.Address,
+			Payload:          string(payload),
+			Status:           models.NotificationStatusPending,
+			ScheduledAt:      now.Add(notificationDeliveryLease),
+			CreatedAt:        now,
+			UpdatedAt:        now,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to record email notification: %w", err)
+		}
+		record = &created
+	}
+
+	msg := libs.MailMessage{
+		To:       n.Address,
+		ToName:   n.Data.RecipientName,
+		Subject:  rendered.Subject,
+		HTMLBody: rendered.HTMLBody,
+		TextBody: rendered.TextBody,
+	}
+	if record != nil {
+		msg.Headers = map[string]string{"X-TimeLedger-Notification-ID": fmt.Sprintf("%d", record.ID)}
+	}
+
+	sendErr := s.sender.Send(ctx, msg)
+	if record == nil {
+		return sendErr
+	}
+
+	if libs.IsPermanentMailError(sendErr) {
+		s.Logger.Warn("email bounced", "notification_id", record.ID, "address", n.Address, "error", sendErr)
+	}
+	if err := settleNotification(ctx, s.queueRepo, record, sendErr, time.Now()); err != nil {
+		s.Logger.Error("failed to record email result", "notification_id", record.ID, "error", err)
+	}
+	if libs.IsPermanentMailError(sendErr) {
+		return nil
+	}
+	return sendErr
+}
+
+// deliverQueued 重送已寫入 notification_queues 的郵件（由通知派送器呼叫）
+func (s *EmailNotificationService) deliverQueued(ctx context.Context, item *models.NotificationQueue) error {
+	if !s.Enabled() {
+		return fmt.Errorf("email channel is disabled")
+	}
+
+	var payload EmailQueuePayload
+	if err := json.Unmarshal([]byte(item.Payload), &payload); err != nil {
+		return fmt.Errorf("%w: invalid email payload: %v", ErrNotificationRecipientUnavailable, err)
+	}
+	rendered, err := RenderEmailTemplate(payload.TemplateType, payload.Data)
+	if err != nil {
+		return fmt.Errorf("%w: failed to render email: %v", ErrNotificationRecipientUnavailable, err)
+	}
+
+	return s.sender.Send(ctx, libs.MailMessage{
+		To:       item.RecipientAddress,
+		ToName:   payload.Data.RecipientName,
+		Subject:  rendered.Subject,
+		HTMLBody: rendered.HTMLBody,
+		TextBody: rendered.TextBody,
+		Headers:  map[string]string{"X-TimeLedger-Notification-ID": fmt.Sprintf("%d", item.ID)},
+	})
+}
+
+// SendAsync 於背景發送，不受請求結束影響
+func (s *EmailNotificationService) SendAsync(ctx context.Context, n EmailNotification) {
+	if !s.Enabled() || n.Address == "" {
+		return
+	}
+	go func() {
+		if err := s.Send(context.WithoutCancel(ctx), n); err != nil {
+			s.Logger.Error("failed to send email notification",
+				"recipient_type", n.RecipientType, "recipient_id", n.RecipientID, "type", n.TemplateType, "error", err)
+		}
+	}()
+}
+
+// emailTemplateTypeOf 偏好事件對應的郵件範本，系統通知使用 GENERAL
+func emailTemplateTypeOf(event string) string {
+	if event == "" {
+		return EmailTemplateGeneral
+	}
+	return event
+}
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+	"timeLedger/app/models"
+)
+
+// 非偏好事件的郵件範本類型
+const (
+	EmailTemplateDigest  = "DIGEST"  // 每日彙整
+	EmailTemplateGeneral = "GENERAL" // 系統通知等未指定類型
+)
+
+// EmailDetail 郵件內的欄位列（標籤 / 值）
+type EmailDetail struct {
+	Label string `json:"label"`
+	Value string `json:"value"`
+}
+
+// EmailTemplateData 郵件範本資料
+type EmailTemplateData struct {
+	RecipientName string        `json:"recipient_name,omitempty"`
+	CenterName    string        `json:"center_name,omitempty"`
+	Title         string        `json:"title"`
+	Message       string        `json:"message,omitempty"`
+	Details       []EmailDetail `json:"details,omitempty"`
+	Items         []string      `json:"items,omitempty"` // 每日彙整的通知清單
+	ActionLabel   string        `json:"action_label,omitempty"`
+	ActionURL     string        `json:"action_url,omitempty"`
+}
+
+// RenderedEmail 範本產生的郵件內容
+type RenderedEmail struct {
+	Subject  string
+	HTMLBody string
+	TextBody string
+}
+
+// emailTemplate 單一通知類型的主旨與內文
+type emailTemplate struct {
+	subject string // text/template
+	html    string // 置於共用 HTML 版面的 content 區塊
+	text    string // 置於共用純文字版面的 content 區塊
+}
+
+const emailHTMLLayout = `<!DOCTYPE html>
+<html lang="zh-Hant">
+<head><meta charset="utf-8"><title>{{.Title}}</title></head>
+<body style="margin:0;padding:24px;background:#f5f6f8;font-family:-apple-system,'Noto Sans TC',sans-serif;color:#333;">
+<table role="presentation" width="100%" style="max-width:560px;margin:0 auto;background:#fff;border-radius:8px;">
+<tr><td style="padding:24px;">
+{{if .RecipientName}}<p>{{.RecipientName}} 您好：</p>{{end}}
+{{template "content" .}}
+{{if .Details}}<table role="presentation" style="margin:16px 0;border-collapse:collapse;">
+{{range .Details}}<tr><td style="padding:4px 12px 4px 0;color:#888;white-space:nowrap;">{{.Label}}</td><td style="padding:4px 0;">{{.Value}}</td></tr>
+{{end}}</table>{{end}}
+{{if .ActionURL}}<p style="margin:24px 0;"><a href="{{.ActionURL}}" style="background:#06c755;color:#fff;padding:10px 20px;border-radius:4px;text-decoration:none;">{{or .ActionLabel "查看詳情"}}</a></p>{{end}}
+<p style="margin-top:32px;font-size:12px;color:#999;">{{if .CenterName}}{{.CenterName}} · {{end}}TimeLedger 通知<br>可在「通知設定」調整 Email 通知。</p>
+</td></tr>
+</table>
+</body>
+</html>`
+
+const emailTextLayout = `{{if .RecipientName}}{{.RecipientName}} 您好：
+
+{{end}}{{template "content" .}}
+{{range .Details}}
+{{.Label}}：{{.Value}}{{end}}
+{{if .ActionURL}}
+{{or .ActionLabel "查看詳情"}}：{{.ActionURL}}
+{{end}}
+--
+{{if .CenterName}}{{.CenterName}} · {{end}}TimeLedger 通知
+可在「通知設定」調整 Email 通知。
+`
+
+// emailTemplates 各通知類型的範本
+var emailTemplates = map[string]emailTemplate{
+	models.NotificationEventReminder: {
+		subject: `[TimeLedger] 課程提醒：{{.Title}}`,
+		html:    `{{define "content"}}<h2 style="font-size:18px;">⏰ 課程提醒</h2><p style="white-space:pre-line;">{{.Message}}</p>{{end}}`,
+		text: `{{define "content"}}⏰ 課程提醒
+
+{{.Message}}{{end}}`,
+	},
+	models.NotificationEventExceptionSubmit: {
+		subject: `[TimeLedger] {{.Title}}`,
+		html:    `{{define "content"}}<h2 style="font-size:18px;">🔔 {{.Title}}</h2><p>有新的例外申請等待審核。</p>{{if .Message}}<p style="white-space:pre-line;">{{.Message}}</p>{{end}}{{end}}`,
+		text: `{{define "content"}}🔔 {{.Title}}
+
+有新的例外申請等待審核。{{if .Message}}
+{{.Message}}{{end}}{{end}}`,
+	},
+	models.NotificationEventExceptionResult: {
+		subject: `[TimeLedger] {{.Title}}`,
+		html:    `{{define "content"}}<h2 style="font-size:18px;">{{.Title}}</h2>{{if .Message}}<p style="white-space:pre-line;">{{.Message}}</p>{{end}}{{end}}`,
+		text: `{{define "content"}}{{.Title}}{{if .Message}}
+
+{{.Message}}{{end}}{{end}}`,
+	},
+	models.NotificationEventBroadcast: {
+		subject: `[{{or .CenterName "TimeLedger"}}] {{.Title}}`,
+		html:    `{{define "content"}}<h2 style="font-size:18px;">📢 {{.Title}}</h2><p style="white-space:pre-line;">{{.Message}}</p>{{end}}`,
+		text: `{{define "content"}}📢 {{.Title}}
+
+{{.Message}}{{end}}`,
+	},
+	models.NotificationEventInvitation: {
+		subject: `[TimeLedger] {{.Title}}`,
+		html:    `{{define "content"}}<h2 style="font-size:18px;">🎉 {{.Title}}</h2><p style="white-space:pre-line;">{{.Message}}</p>{{end}}`,
+		text: `{{define "content"}}🎉 {{.Title}}
+
+{{.Message}}{{end}}`,
+	},
+	EmailTemplateDigest: {
+		subject: `[TimeLedger] {{.Title}}`,
+		html:    `{{define "content"}}<h2 style="font-size:18px;">📬 {{.Title}}</h2><ul>{{range .Items}}<li style="margin:4px 0;">{{.}}</li>{{end}}</ul>{{end}}`,
+		text: `{{define "content"}}📬 {{.Title}}
+{{range .Items}}
+• {{.}}{{end}}{{end}}`,
+	},
+	EmailTemplateGeneral: {
+		subject: `[TimeLedger] {{.Title}}`,
+		html:    `{{define "content"}}<h2 style="font-size:18px;">{{.Title}}</h2><p style="white-space:pre-line;">{{.Message}}</p>{{end}}`,
+		text: `{{define "content"}}{{.Title}}
+
+{{.Message}}{{end}}`,
+	},
+}
+
+// RenderEmailTemplate 依通知類型產生郵件主旨、HTML 與純文字內容，未知類型使用 GENERAL
+func RenderEmailTemplate(templateType string, data EmailTemplateData) (*RenderedEmail, error) {
+	tpl, ok := emailTemplates[templateType]
+	if !ok {
+		tpl = emailTemplates[EmailTemplateGeneral]
+	}
+
+	subject, err := renderTextTemplate("subject", tpl.subject, data)
+	if err != nil {
+		return nil, err
+	}
+
+	htmlTpl, err := htmltemplate.New("layout").Parse(emailHTMLLayout)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := htmlTpl.Parse(tpl.html); err != nil {
+		return nil, fmt.Errorf("failed to parse %s html template: %w", templateType, err)
+	}
+	var htmlBuf bytes.Buffer
+	if err := htmlTpl.Execute(&htmlBuf, data); err != nil {
+		return nil, err
+	}
+
+	text, err := renderTextTemplate("layout", emailTextLayout+tpl.text, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render %s text template: %w", templateType, err)
+	}
+
+	return &RenderedEmail{
+		Subject:  strings.Join(strings.Fields(subject), " "),
+		HTMLBody: htmlBuf.String(),
+		TextBody: text,
+	}, nil
+}
+
+func renderTextTemplate(name, body string, data EmailTemplateData) (string, error) {
+	tpl, err := texttemplate.New(name).Parse(body)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
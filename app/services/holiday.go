@@ -3,25 +3,35 @@ package services
 import (
 	"context"
 	"fmt"
+	"sort"
 	"time"
 	"timeLedger/app"
 	"timeLedger/app/models"
 	"timeLedger/app/repositories"
 	"timeLedger/global/errInfos"
+	"timeLedger/libs/twcalendar"
 )
 
 type HolidayService struct {
 	BaseService
-	app          *app.App
-	holidayRepo  *repositories.CenterHolidayRepository
-	auditLogRepo *repositories.AuditLogRepository
+	app               *app.App
+	holidayRepo       *repositories.CenterHolidayRepository
+	makeupWorkdayRepo *repositories.CenterMakeupWorkdayRepository
+	recurringRepo     *repositories.RecurringHolidayRepository
+	auditLogRepo      *repositories.AuditLogRepository
+	liveEvents        *LiveEventService
+	domainEvents      *DomainEventService
 }
 
 func NewHolidayService(app *app.App) *HolidayService {
 	return &HolidayService{
-		app:          app,
-		holidayRepo:  repositories.NewCenterHolidayRepository(app),
-		auditLogRepo: repositories.NewAuditLogRepository(app),
+		app:               app,
+		holidayRepo:       repositories.NewCenterHolidayRepository(app),
+		makeupWorkdayRepo: repositories.NewCenterMakeupWorkdayRepository(app),
+		recurringRepo:     repositories.NewRecurringHolidayRepository(app),
+		auditLogRepo:      repositories.NewAuditLogRepository(app),
+		liveEvents:        NewLiveEventService(app),
+		domainEvents:      NewDomainEventService(app),
 	}
 }
 
@@ -48,9 +58,38 @@ type CreateHolidayRequest struct {
 	ForceCancel bool   `json:"force_cancel"`
 }
 
+type ImportTaiwanHolidaysRequest struct {
+	Year int `json:"year" binding:"required"`
+}
+
+type ImportTaiwanHolidaysResponse struct {
+	Year                  int                          `json:"year"`
+	DatasetVersion        string                       `json:"dataset_version"`
+	HolidaysCreated       int                          `json:"holidays_created"`
+	HolidaysSkipped       int                          `json:"holidays_skipped"`
+	MakeupWorkdaysCreated int                          `json:"makeup_workdays_created"`
+	MakeupWorkdaysSkipped int                          `json:"makeup_workdays_skipped"`
+	Holidays              []models.CenterHoliday       `json:"holidays"`
+	MakeupWorkdays        []models.CenterMakeupWorkday `json:"makeup_workdays"`
+}
+
 type GetHolidaysRequest struct {
-	StartDate string `form:"start_date"`
-	EndDate   string `form:"end_date"`
+	StartDate        string `form:"start_date"`
+	EndDate          string `form:"end_date"`
+	IncludeRecurring bool   `form:"include_recurring"`
+}
+
+type CreateRecurringHolidayRequest struct {
+	Name          string `json:"name" binding:"required"`
+	RuleType      string `json:"rule_type" binding:"required,oneof=FIXED_DATE NTH_WEEKDAY LUNAR"`
+	Month         int    `json:"month"`
+	Day           int    `json:"day"`
+	Weekday       int    `json:"weekday"`
+	WeekOfMonth   int    `json:"week_of_month"`
+	LunarFestival string `json:"lunar_festival"`
+	OffsetDays    int    `json:"offset_days"`
+	DurationDays  int    `json:"duration_days"`
+	ForceCancel   bool   `json:"force_cancel"`
 }
 
 func (s *HolidayService) GetHolidays(ctx context.Context, centerID uint, req *GetHolidaysRequest) ([]models.CenterHoliday, *errInfos.Res, error) {
@@ -61,6 +100,11 @@ func (s *HolidayService) GetHolidays(ctx context.Context, centerID uint, req *Ge
 		startDate, _ := time.Parse("2006-01-02", req.StartDate)
 		endDate, _ := time.Parse("2006-01-02", req.EndDate)
 		holidays, err = s.holidayRepo.ListByDateRange(ctx, centerID, startDate, endDate)
+		if err == nil && req.IncludeRecurring {
+			var defs []models.RecurringHoliday
+			defs, err = s.recurringRepo.ListByCenterID(ctx, centerID)
+			holidays = MergeHolidays(holidays, MaterializeRecurringHolidays(defs, startDate, endDate))
+		}
 	} else {
 		holidays, err = s.holidayRepo.ListByCenterID(ctx, centerID)
 	}
@@ -115,13 +159,20 @@ func (s *HolidayService) CreateHoliday(ctx context.Context, centerID, adminID ui
 		if err := txRepo.GetDBWrite().Create(&auditLog).Error; err != nil {
 			return err
 		}
-		return nil
+
+		// 對外 webhook 由領域事件消費端處理
+		return s.domainEvents.RecordWithTx(ctx, txRepo.GetDBWrite(), holidayCreatedEvent(centerID, adminID, []models.CenterHoliday{created}, "MANUAL"))
 	})
 
 	if err != nil {
 		return nil, s.app.Err.New(errInfos.SQL_ERROR), err
 	}
 
+	s.liveEvents.Publish(ctx, centerID, LiveEventHolidayChanged, "CenterHoliday", created.ID, map[string]any{
+		"action": "CREATE_HOLIDAY",
+		"date":   created.Date.Format("2006-01-02"),
+	})
+
 	return &created, nil, nil
 }
 
@@ -169,6 +220,14 @@ func (s *HolidayService) BulkCreateHolidays(ctx context.Context, centerID, admin
 		},
 	})
 
+	if createdCount > 0 {
+		s.domainEvents.Record(ctx, holidayCreatedEvent(centerID, adminID, createdHolidays, "BULK"))
+		s.liveEvents.Publish(ctx, centerID, LiveEventHolidayChanged, "CenterHoliday", 0, map[string]any{
+			"action":        "BULK_CREATE_HOLIDAYS",
+			"created_count": createdCount,
+		})
+	}
+
 	return &BulkCreateHolidaysResponse{
 		TotalRequested: len(req.Holidays),
 		TotalCreated:   int(createdCount),
@@ -216,5 +275,431 @@ func (s *HolidayService) DeleteHoliday(ctx context.Context, centerID, adminID, h
 		return s.app.Err.New(errInfos.SQL_ERROR), err
 	}
 
+	s.liveEvents.Publish(ctx, centerID, LiveEventHolidayChanged, "CenterHoliday", holidayID, map[string]any{
+		"action": "DELETE_HOLIDAY",
+	})
+
+	return nil, nil
+}
+
+// holidayCreatedEvent 組成新增假日的領域事件
+func holidayCreatedEvent(centerID, adminID uint, holidays []models.CenterHoliday, source string) DomainEventInput {
+	payload := HolidayCreatedPayload{
+		HolidayIDs: make([]uint, 0, len(holidays)),
+		Dates:      make([]string, 0, len(holidays)),
+		Source:     source,
+	}
+	for _, h := range holidays {
+		payload.HolidayIDs = append(payload.HolidayIDs, h.ID)
+		payload.Dates = append(payload.Dates, h.Date.Format("2006-01-02"))
+	}
+	var aggregateID uint
+	if len(payload.HolidayIDs) > 0 {
+		aggregateID = payload.HolidayIDs[0]
+	}
+	return DomainEventInput{
+		CenterID:      centerID,
+		EventType:     models.DomainEventHolidayCreated,
+		AggregateType: "CenterHoliday",
+		AggregateID:   aggregateID,
+		ActorType:     "ADMIN",
+		ActorID:       adminID,
+		Payload:       payload,
+	}
+}
+
+// GetTaiwanCalendar 預覽內建的台灣國定假日與補班日資料
+func (s *HolidayService) GetTaiwanCalendar(year int) (*twcalendar.YearCalendar, *errInfos.Res, error) {
+	cal, ok := twcalendar.ForYear(year)
+	if !ok {
+		return nil, s.app.Err.New(errInfos.HOLIDAY_DATASET_YEAR_UNAVAILABLE), fmt.Errorf("taiwan calendar for %d not available", year)
+	}
+	return &cal, nil, nil
+}
+
+// ImportTaiwanHolidays 匯入指定年度的台灣國定假日與補班日，已存在的日期略過
+func (s *HolidayService) ImportTaiwanHolidays(ctx context.Context, centerID, adminID uint, req *ImportTaiwanHolidaysRequest) (*ImportTaiwanHolidaysResponse, *errInfos.Res, error) {
+	cal, ok := twcalendar.ForYear(req.Year)
+	if !ok {
+		return nil, s.app.Err.New(errInfos.HOLIDAY_DATASET_YEAR_UNAVAILABLE), fmt.Errorf("taiwan calendar for %d not available", req.Year)
+	}
+
+	now := time.Now()
+	holidays := make([]models.CenterHoliday, 0, len(cal.Holidays))
+	for _, h := range cal.Holidays {
+		parsedDate, err := time.Parse("2006-01-02", h.Date)
+		if err != nil {
+			return nil, s.app.Err.New(errInfos.SYSTEM_ERROR), fmt.Errorf("invalid dataset date: %s", h.Date)
+		}
+		holidays = append(holidays, models.CenterHoliday{
+			CenterID:  centerID,
+			Date:      parsedDate,
+			Name:      h.Name,
+			CreatedAt: now,
+			UpdatedAt: now,
+		})
+	}
+
+	workdays := make([]models.CenterMakeupWorkday, 0, len(cal.MakeupWorkdays))
+	for _, w := range cal.MakeupWorkdays {
+		parsedDate, err := time.Parse("2006-01-02", w.Date)
+		if err != nil {
+			return nil, s.app.Err.New(errInfos.SYSTEM_ERROR), fmt.Errorf("invalid dataset date: %s", w.Date)
+		}
+		workdays = append(workdays, models.CenterMakeupWorkday{
+			CenterID:       centerID,
+			Date:           parsedDate,
+			Name:           w.Name,
+			FollowsWeekday: w.FollowsWeekday,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		})
+	}
+
+	createdHolidays, holidayCount, err := s.holidayRepo.BulkCreateWithSkipDuplicate(ctx, holidays)
+	if err != nil {
+		return nil, s.app.Err.New(errInfos.SQL_ERROR), err
+	}
+
+	createdWorkdays, workdayCount, err := s.makeupWorkdayRepo.BulkCreateWithSkipDuplicate(ctx, workdays)
+	if err != nil {
+		return nil, s.app.Err.New(errInfos.SQL_ERROR), err
+	}
+
+	resp := &ImportTaiwanHolidaysResponse{
+		Year:                  cal.Year,
+		DatasetVersion:        cal.Version,
+		HolidaysCreated:       int(holidayCount),
+		HolidaysSkipped:       len(holidays) - int(holidayCount),
+		MakeupWorkdaysCreated: int(workdayCount),
+		MakeupWorkdaysSkipped: len(workdays) - int(workdayCount),
+		Holidays:              createdHolidays,
+		MakeupWorkdays:        createdWorkdays,
+	}
+
+	// 記錄稽核日誌
+	s.auditLogRepo.Create(ctx, models.AuditLog{
+		CenterID:   centerID,
+		ActorType:  "ADMIN",
+		ActorID:    adminID,
+		Action:     "IMPORT_TW_HOLIDAYS",
+		TargetType: "CenterHoliday",
+		Payload: models.AuditPayload{
+			After: map[string]interface{}{
+				"year":                    resp.Year,
+				"dataset_version":         resp.DatasetVersion,
+				"holidays_created":        resp.HolidaysCreated,
+				"holidays_skipped":        resp.HolidaysSkipped,
+				"makeup_workdays_created": resp.MakeupWorkdaysCreated,
+				"makeup_workdays_skipped": resp.MakeupWorkdaysSkipped,
+			},
+		},
+	})
+
+	if resp.HolidaysCreated > 0 {
+		s.domainEvents.Record(ctx, holidayCreatedEvent(centerID, adminID, createdHolidays, "IMPORT"))
+	}
+	if resp.HolidaysCreated > 0 || resp.MakeupWorkdaysCreated > 0 {
+		s.liveEvents.Publish(ctx, centerID, LiveEventHolidayChanged, "CenterHoliday", 0, map[string]any{
+			"action": "IMPORT_TW_HOLIDAYS",
+			"year":   resp.Year,
+		})
+	}
+
+	return resp, nil, nil
+}
+
+func (s *HolidayService) GetMakeupWorkdays(ctx context.Context, centerID uint, req *GetHolidaysRequest) ([]models.CenterMakeupWorkday, *errInfos.Res, error) {
+	var workdays []models.CenterMakeupWorkday
+	var err error
+
+	if req.StartDate != "" && req.EndDate != "" {
+		startDate, _ := time.Parse("2006-01-02", req.StartDate)
+		endDate, _ := time.Parse("2006-01-02", req.EndDate)
+		workdays, err = s.makeupWorkdayRepo.ListByDateRange(ctx, centerID, startDate, endDate)
+	} else {
+		workdays, err = s.makeupWorkdayRepo.ListByCenterID(ctx, centerID)
+	}
+
+	if err != nil {
+		return nil, s.app.Err.New(errInfos.SQL_ERROR), err
+	}
+	return workdays, nil, nil
+}
+
+func (s *HolidayService) DeleteMakeupWorkday(ctx context.Context, centerID, adminID, workdayID uint) (*errInfos.Res, error) {
+	workday, err := s.makeupWorkdayRepo.GetByIDWithCenterScope(ctx, workdayID, centerID)
+	if err != nil {
+		return s.app.Err.New(errInfos.NOT_FOUND), err
+	}
+
+	if err := s.makeupWorkdayRepo.DeleteByIDWithCenterScope(ctx, workdayID, centerID); err != nil {
+		return s.app.Err.New(errInfos.SQL_ERROR), err
+	}
+
+	// 記錄稽核日誌
+	s.auditLogRepo.Create(ctx, models.AuditLog{
+		CenterID:   centerID,
+		ActorType:  "ADMIN",
+		ActorID:    adminID,
+		Action:     "DELETE_MAKEUP_WORKDAY",
+		TargetType: "CenterMakeupWorkday",
+		TargetID:   workdayID,
+		Payload: models.AuditPayload{
+			Before: workday,
+		},
+	})
+
+	s.liveEvents.Publish(ctx, centerID, LiveEventHolidayChanged, "CenterMakeupWorkday", workdayID, map[string]any{
+		"action": "DELETE_MAKEUP_WORKDAY",
+	})
+
+	return nil, nil
+}
+
+func (s *HolidayService) GetRecurringHolidays(ctx context.Context, centerID uint) ([]models.RecurringHoliday, *errInfos.Res, error) {
+	defs, err := s.recurringRepo.ListByCenterID(ctx, centerID)
+	if err != nil {
+		return nil, s.app.Err.New(errInfos.SQL_ERROR), err
+	}
+	return defs, nil, nil
+}
+
+func (s *HolidayService) CreateRecurringHoliday(ctx context.Context, centerID, adminID uint, req *CreateRecurringHolidayRequest) (*models.RecurringHoliday, *errInfos.Res, error) {
+	def := models.RecurringHoliday{
+		CenterID:      centerID,
+		Name:          req.Name,
+		RuleType:      req.RuleType,
+		Month:         req.Month,
+		Day:           req.Day,
+		Weekday:       req.Weekday,
+		WeekOfMonth:   req.WeekOfMonth,
+		LunarFestival: req.LunarFestival,
+		OffsetDays:    req.OffsetDays,
+		DurationDays:  req.DurationDays,
+		ForceCancel:   req.ForceCancel,
+	}
+	if def.DurationDays == 0 {
+		def.DurationDays = 1
+	}
+
+	if err := ValidateRecurringHoliday(def); err != nil {
+		return nil, s.app.Err.New(errInfos.PARAMS_VALIDATE_ERROR), err
+	}
+
+	var created models.RecurringHoliday
+	err := s.recurringRepo.Transaction(ctx, func(txRepo *repositories.GenericRepository[models.RecurringHoliday]) error {
+		var txErr error
+		created, txErr = txRepo.Create(ctx, def)
+		if txErr != nil {
+			return txErr
+		}
+
+		// 記錄稽核日誌
+		auditLog := models.AuditLog{
+			CenterID:   centerID,
+			ActorType:  "ADMIN",
+			ActorID:    adminID,
+			Action:     "CREATE_RECURRING_HOLIDAY",
+			TargetType: "RecurringHoliday",
+			TargetID:   created.ID,
+			Payload: models.AuditPayload{
+				After: created,
+			},
+		}
+		return txRepo.GetDBWrite().Create(&auditLog).Error
+	})
+	if err != nil {
+		return nil, s.app.Err.New(errInfos.SQL_ERROR), err
+	}
+
+	s.liveEvents.Publish(ctx, centerID, LiveEventHolidayChanged, "RecurringHoliday", created.ID, map[string]any{
+		"action": "CREATE_RECURRING_HOLIDAY",
+	})
+
+	return &created, nil, nil
+}
+
+func (s *HolidayService) DeleteRecurringHoliday(ctx context.Context, centerID, adminID, recurringID uint) (*errInfos.Res, error) {
+	def, err := s.recurringRepo.GetByIDWithCenterScope(ctx, recurringID, centerID)
+	if err != nil {
+		return s.app.Err.New(errInfos.NOT_FOUND), err
+	}
+
+	if err := s.recurringRepo.DeleteByIDWithCenterScope(ctx, recurringID, centerID); err != nil {
+		return s.app.Err.New(errInfos.SQL_ERROR), err
+	}
+
+	// 記錄稽核日誌
+	s.auditLogRepo.Create(ctx, models.AuditLog{
+		CenterID:   centerID,
+		ActorType:  "ADMIN",
+		ActorID:    adminID,
+		Action:     "DELETE_RECURRING_HOLIDAY",
+		TargetType: "RecurringHoliday",
+		TargetID:   recurringID,
+		Payload: models.AuditPayload{
+			Before: def,
+		},
+	})
+
+	s.liveEvents.Publish(ctx, centerID, LiveEventHolidayChanged, "RecurringHoliday", recurringID, map[string]any{
+		"action": "DELETE_RECURRING_HOLIDAY",
+	})
+
 	return nil, nil
 }
+
+// PreviewRecurringHolidays 預覽週期假日在指定年度展開後的具體日期
+func (s *HolidayService) PreviewRecurringHolidays(ctx context.Context, centerID uint, year int) ([]models.CenterHoliday, *errInfos.Res, error) {
+	defs, err := s.recurringRepo.ListByCenterID(ctx, centerID)
+	if err != nil {
+		return nil, s.app.Err.New(errInfos.SQL_ERROR), err
+	}
+
+	for _, def := range defs {
+		if def.RuleType == models.RecurringHolidayTypeLunar && !twcalendar.HasLunarYear(year) {
+			return nil, s.app.Err.New(errInfos.HOLIDAY_DATASET_YEAR_UNAVAILABLE), fmt.Errorf("lunar calendar for %d not available", year)
+		}
+	}
+
+	start := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(year, 12, 31, 0, 0, 0, 0, time.UTC)
+	return MaterializeRecurringHolidays(defs, start, end), nil, nil
+}
+
+// ValidateRecurringHoliday 檢查週期假日定義的欄位組合
+func ValidateRecurringHoliday(def models.RecurringHoliday) error {
+	if def.DurationDays < 1 || def.DurationDays > 31 {
+		return fmt.Errorf("duration_days must be between 1 and 31")
+	}
+	// 展開時僅掃描前後一年，偏移過大會落在掃描範圍外而永遠不會產生
+	if def.OffsetDays < -31 || def.OffsetDays > 31 {
+		return fmt.Errorf("offset_days must be between -31 and 31")
+	}
+
+	switch def.RuleType {
+	case models.RecurringHolidayTypeFixedDate:
+		if def.Month < 1 || def.Month > 12 {
+			return fmt.Errorf("month must be between 1 and 12")
+		}
+		// 以閏年檢查，允許 2/29（平年自動略過）
+		if def.Day < 1 || time.Date(2024, time.Month(def.Month), def.Day, 0, 0, 0, 0, time.UTC).Month() != time.Month(def.Month) {
+			return fmt.Errorf("invalid day %d for month %d", def.Day, def.Month)
+		}
+	case models.RecurringHolidayTypeNthWeekday:
+		if def.Month < 1 || def.Month > 12 {
+			return fmt.Errorf("month must be between 1 and 12")
+		}
+		if def.Weekday < 1 || def.Weekday > 7 {
+			return fmt.Errorf("weekday must be between 1 and 7")
+		}
+		if def.WeekOfMonth != -1 && (def.WeekOfMonth < 1 || def.WeekOfMonth > 5) {
+			return fmt.Errorf("week_of_month must be 1-5 or -1")
+		}
+	case models.RecurringHolidayTypeLunar:
+		if !twcalendar.IsLunarFestival(def.LunarFestival) {
+			return fmt.Errorf("unsupported lunar festival: %s", def.LunarFestival)
+		}
+	default:
+		return fmt.Errorf("unsupported rule type: %s", def.RuleType)
+	}
+	return nil
+}
+
+// RecurringHolidayAnchor 取得週期假日在指定年度的基準日期（未套用偏移天數）
+// 該年度不存在此日期（如平年 2/29、第 5 個星期幾不存在、農曆表未收錄）時回傳 false
+func RecurringHolidayAnchor(def models.RecurringHoliday, year int) (time.Time, bool) {
+	switch def.RuleType {
+	case models.RecurringHolidayTypeFixedDate:
+		d := time.Date(year, time.Month(def.Month), def.Day, 0, 0, 0, 0, time.UTC)
+		if d.Month() != time.Month(def.Month) {
+			return time.Time{}, false
+		}
+		return d, true
+	case models.RecurringHolidayTypeNthWeekday:
+		target := time.Weekday(def.Weekday % 7)
+		if def.WeekOfMonth == -1 {
+			d := time.Date(year, time.Month(def.Month)+1, 0, 0, 0, 0, 0, time.UTC)
+			for d.Weekday() != target {
+				d = d.AddDate(0, 0, -1)
+			}
+			return d, true
+		}
+		d := time.Date(year, time.Month(def.Month), 1, 0, 0, 0, 0, time.UTC)
+		for d.Weekday() != target {
+			d = d.AddDate(0, 0, 1)
+		}
+		d = d.AddDate(0, 0, (def.WeekOfMonth-1)*7)
+		if d.Month() != time.Month(def.Month) {
+			return time.Time{}, false
+		}
+		return d, true
+	case models.RecurringHolidayTypeLunar:
+		return twcalendar.LunarFestivalDate(def.LunarFestival, year)
+	}
+	return time.Time{}, false
+}
+
+// MaterializeRecurringHolidays 將週期假日定義展開為區間內的具體假日（不寫入資料庫）
+func MaterializeRecurringHolidays(defs []models.RecurringHoliday, start, end time.Time) []models.CenterHoliday {
+	startDay := dateOnly(start)
+	endDay := dateOnly(end)
+	holidays := []models.CenterHoliday{}
+
+	for _, def := range defs {
+		duration := def.DurationDays
+		if duration < 1 {
+			duration = 1
+		}
+
+		// 前後年度的連假或偏移天數可能落入區間
+		for year := startDay.Year() - 1; year <= endDay.Year()+1; year++ {
+			anchor, ok := RecurringHolidayAnchor(def, year)
+			if !ok {
+				continue
+			}
+			first := anchor.AddDate(0, 0, def.OffsetDays)
+			for i := 0; i < duration; i++ {
+				d := first.AddDate(0, 0, i)
+				if d.Before(startDay) || d.After(endDay) {
+					continue
+				}
+				holidays = append(holidays, models.CenterHoliday{
+					CenterID:    def.CenterID,
+					Date:        d,
+					Name:        def.Name,
+					ForceCancel: def.ForceCancel,
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(holidays, func(i, j int) bool {
+		return holidays[i].Date.Before(holidays[j].Date)
+	})
+	return holidays
+}
+
+// MergeHolidays 合併單日假日與週期假日展開結果，同日期以單日假日為準
+func MergeHolidays(concrete, recurring []models.CenterHoliday) []models.CenterHoliday {
+	merged := make([]models.CenterHoliday, 0, len(concrete)+len(recurring))
+	seen := make(map[string]bool, len(concrete)+len(recurring))
+	for _, h := range concrete {
+		seen[h.Date.Format("2006-01-02")] = true
+		merged = append(merged, h)
+	}
+	for _, h := range recurring {
+		key := h.Date.Format("2006-01-02")
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		merged = append(merged, h)
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Date.Before(merged[j].Date)
+	})
+	return merged
+}
@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 	"timeLedger/app"
+	"timeLedger/app/models"
 	"timeLedger/global/errInfos"
 
 	"github.com/google/uuid"
@@ -219,10 +220,16 @@ func (s *ICSCalendarService) GetTeacherScheduleForICS(ctx context.Context, teach
 				endAt, _ = time.Parse("2006-01-02 15:04", dateStr+" "+endTimeStr)
 			}
 
+			description := fmt.Sprintf("課程：%s\\n時間：%s - %s", item.Title, item.StartTime, item.EndTime)
+			// 協同授課時註明老師在此堂課的角色
+			if item.Role != "" && item.Role != models.TeacherRoleLead {
+				description += fmt.Sprintf("\\n角色：%s", models.TeacherRoleLabel(item.Role))
+			}
+
 			event := ScheduleEvent{
 				ID:          item.ID,
 				Summary:     item.Title,
-				Description: fmt.Sprintf("課程：%s\\n時間：%s - %s", item.Title, item.StartTime, item.EndTime),
+				Description: description,
 				Location:    "",
 				StartTime:   startAt,
 				EndTime:     endAt,
@@ -0,0 +1,287 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"timeLedger/app"
+	"timeLedger/global"
+	"timeLedger/global/errInfos"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// IdempotencyKeyHeader 前端重送時帶上相同的值
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotencyReplayedHeader 回應為重播第一次結果時設為 true
+	IdempotencyReplayedHeader = "Idempotent-Replayed"
+
+	idempotencyMaxKeyLength = 255
+	idempotencyRecordTTL    = 24 * time.Hour
+	idempotencyLockTTL      = time.Minute // 處理中的保留時間，程序中斷時期滿後可重新送出
+	idempotencyLockRefresh  = 20 * time.Second
+	idempotencySaveTimeout  = 3 * time.Second // 請求結束後寫回結果的逾時時間
+)
+
+// 冪等紀錄狀態
+const (
+	IdempotencyStatusProcessing = "PROCESSING"
+	IdempotencyStatusCompleted  = "COMPLETED"
+)
+
+// idempotencyReplayHeaders 重播時一併回傳的 header
+var idempotencyReplayHeaders = []string{"Content-Type", "ETag", "Location"}
+
+// IdempotencyRecord 存於 Redis 的第一次請求結果
+type IdempotencyRecord struct {
+	Status      string            `json:"status"`
+	Fingerprint string            `json:"fingerprint"`
+	StatusCode  int               `json:"status_code,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	Body        []byte            `json:"body,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+}
+
+// IdempotencyService 以 Redis 保存 Idempotency-Key 對應的回應
+type IdempotencyService struct {
+	app *app.App
+}
+
+// NewIdempotencyService 建立冪等請求服務
+func NewIdempotencyService(app *app.App) *IdempotencyService {
+	return &IdempotencyService{app: app}
+}
+
+// IdempotencyStoreKey 依使用者與路由區分，不同使用者或不同資源使用相同 key 互不影響
+func IdempotencyStoreKey(userType string, userID uint, method, path, key string) string {
+	return fmt.Sprintf("%s:idempotency:%s:%d:%s:%s:%s", CacheKeyPrefix, strings.ToLower(userType), userID, method, path, key)
+}
+
+// IdempotencyFingerprint 計算請求內容的指紋
+// JSON 會先正規化（鍵排序、去除空白），欄位順序不同但內容相同視為同一請求
+func IdempotencyFingerprint(body []byte) string {
+	canonical := bytes.TrimSpace(body)
+	decoder := json.NewDecoder(bytes.NewReader(canonical))
+	decoder.UseNumber()
+	var v any
+	if err := decoder.Decode(&v); err == nil && !decoder.More() {
+		if normalized, err := json.Marshal(v); err == nil {
+			canonical = normalized
+		}
+	}
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:])
+}
+
+// Begin 嘗試以處理中狀態佔用 key
+// 佔用成功回傳 (nil, true)；key 已存在時回傳既有紀錄
+func (s *IdempotencyService) Begin(ctx context.Context, storeKey, fingerprint string) (*IdempotencyRecord, bool, error) {
+	payload, err := json.Marshal(IdempotencyRecord{
+		Status:      IdempotencyStatusProcessing,
+		Fingerprint: fingerprint,
+		CreatedAt:   time.Now(),
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	// 既有紀錄剛好過期時重試一次
+	for attempt := 0; attempt < 2; attempt++ {
+		acquired, err := s.app.Redis.DB0.SetNX(ctx, storeKey, payload, idempotencyLockTTL).Result()
+		if err != nil {
+			return nil, false, err
+		}
+		if acquired {
+			return nil, true, nil
+		}
+
+		raw, err := s.app.Redis.DB0.Get(ctx, storeKey).Bytes()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			return nil, false, err
+		}
+
+		var record IdempotencyRecord
+		if err := json.Unmarshal(raw, &record); err != nil {
+			return nil, false, err
+		}
+		return &record, false, nil
+	}
+
+	return nil, false, fmt.Errorf("failed to acquire idempotency key")
+}
+
+// Complete 保存第一次請求的回應，供重送時重播
+func (s *IdempotencyService) Complete(ctx context.Context, storeKey string, record IdempotencyRecord) error {
+	record.Status = IdempotencyStatusCompleted
+	payload, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return s.app.Redis.DB0.Set(ctx, storeKey, payload, idempotencyRecordTTL).Err()
+}
+
+// KeepAlive 處理期間定期延長佔用時間，避免較慢的請求在完成前被重複送出；回傳的函式停止延長
+func (s *IdempotencyService) KeepAlive(ctx context.Context, storeKey string) func() {
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(idempotencyLockRefresh)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				_ = s.app.Redis.DB0.Expire(ctx, storeKey, idempotencyLockTTL).Err()
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// Release 請求失敗時釋放 key，讓前端可以用同一個 key 重試
+func (s *IdempotencyService) Release(ctx context.Context, storeKey string) error {
+	return s.app.Redis.DB0.Del(ctx, storeKey).Err()
+}
+
+// IdempotencyMiddleware 建立冪等請求中介層
+// 需放在 Authenticate 之後；只處理帶有 Idempotency-Key 的 POST/PUT/PATCH
+func IdempotencyMiddleware(svc *IdempotencyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := strings.TrimSpace(c.GetHeader(IdempotencyKeyHeader))
+		if key == "" || svc.app.Redis == nil {
+			c.Next()
+			return
+		}
+		switch c.Request.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch:
+		default:
+			c.Next()
+			return
+		}
+
+		if len(key) > idempotencyMaxKeyLength {
+			abortIdempotency(c, svc, http.StatusBadRequest, errInfos.IDEMPOTENCY_KEY_INVALID)
+			return
+		}
+
+		userID := c.GetUint(global.UserIDKey)
+		if userID == 0 {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			abortIdempotency(c, svc, http.StatusBadRequest, errInfos.PARAMS_VALIDATE_ERROR)
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := c.Request.Context()
+		storeKey := IdempotencyStoreKey(c.GetString(global.UserTypeKey), userID, c.Request.Method, c.Request.URL.Path, key)
+		fingerprint := IdempotencyFingerprint(body)
+
+		existing, acquired, err := svc.Begin(ctx, storeKey, fingerprint)
+		if err != nil {
+			// Redis 異常時不阻擋請求（fail open）
+			c.Next()
+			return
+		}
+
+		if !acquired {
+			switch {
+			case existing.Fingerprint != fingerprint:
+				abortIdempotency(c, svc, http.StatusUnprocessableEntity, errInfos.IDEMPOTENCY_KEY_REUSED)
+			case existing.Status != IdempotencyStatusCompleted:
+				c.Header("Retry-After", "1")
+				abortIdempotency(c, svc, http.StatusConflict, errInfos.IDEMPOTENCY_REQUEST_PENDING)
+			default:
+				for name, value := range existing.Headers {
+					c.Header(name, value)
+				}
+				c.Header(IdempotencyReplayedHeader, "true")
+				c.Status(existing.StatusCode)
+				_, _ = c.Writer.Write(existing.Body)
+				c.Abort()
+			}
+			return
+		}
+
+		writer := &idempotencyWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+
+		stopKeepAlive := svc.KeepAlive(ctx, storeKey)
+		c.Next()
+		stopKeepAlive()
+
+		// 用戶端中斷連線時請求 context 已取消，結果仍須寫回
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), idempotencySaveTimeout)
+		defer cancel()
+
+		// 5xx 視為未完成，釋放 key 讓前端重試；其餘結果（含 4xx 驗證錯誤）都保存重播
+		status := writer.Status()
+		if status >= http.StatusInternalServerError {
+			_ = svc.Release(ctx, storeKey)
+			return
+		}
+
+		headers := make(map[string]string)
+		for _, name := range idempotencyReplayHeaders {
+			if value := writer.Header().Get(name); value != "" {
+				headers[name] = value
+			}
+		}
+		if err := svc.Complete(ctx, storeKey, IdempotencyRecord{
+			Fingerprint: fingerprint,
+			StatusCode:  status,
+			Headers:     headers,
+			Body:        writer.body.Bytes(),
+			CreatedAt:   time.Now(),
+		}); err != nil {
+			_ = svc.Release(ctx, storeKey)
+		}
+	}
+}
+
+func abortIdempotency(c *gin.Context, svc *IdempotencyService, status int, code errInfos.ErrCode) {
+	errInfo := svc.app.Err.New(code)
+	c.JSON(status, global.ApiResponse{
+		Code:    errInfo.Code,
+		Message: errInfo.Msg,
+	})
+	c.Abort()
+}
+
+// idempotencyWriter 記錄回應內容，同時照常寫出
+type idempotencyWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *idempotencyWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *idempotencyWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
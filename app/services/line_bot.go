@@ -39,6 +39,7 @@ type LineBotService interface {
 
 	// 行程聚合
 	GetAggregatedAgenda(lineUserID string, targetDate *time.Time) ([]AgendaItem, error)
+	GetAggregatedAgendaRange(lineUserID string, startDate, endDate time.Time) ([]AgendaDay, error)
 
 	// 範本發送
 	SendWelcomeTeacher(ctx context.Context, teacher *models.Teacher, centerName string) error
@@ -58,6 +59,7 @@ type LineBotServiceImpl struct {
 	multicastURL         string
 	client               *http.Client
 	templateService      LineBotTemplateService
+	templates            *NotificationTemplateService
 	scheduleExpansionSvc ScheduleExpansionService
 	personalEventSvc     *PersonalEventService
 }
@@ -84,6 +86,7 @@ func NewLineBotService(app *app.App) LineBotService {
 	if app.MySQL != nil {
 		svc.scheduleExpansionSvc = NewScheduleExpansionService(app)
 		svc.personalEventSvc = NewPersonalEventService(app)
+		svc.templates = NewNotificationTemplateService(app)
 	}
 
 	return svc
@@ -115,6 +118,66 @@ type LineBotErrorResponse struct {
 	} `json:"details"`
 }
 
+// LineAPIError LINE API 回應非 2xx
+type LineAPIError struct {
+	StatusCode int
+	Message    string // 解析自回應的 message，無法解析時為空
+	Body       string
+}
+
+func (e *LineAPIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("LINE API error (status %d): %s", e.StatusCode, e.Body)
+	}
+	return fmt.Sprintf("LINE API error: %s", e.Message)
+}
+
+// doLineAPIRequest 發送 Messaging API 請求，非 2xx 時回傳 *LineAPIError
+func doLineAPIRequest(ctx context.Context, client *http.Client, token, method, endpoint, contentType string, body []byte) ([]byte, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		apiErr := &LineAPIError{StatusCode: resp.StatusCode, Body: string(respBody)}
+		var errResp LineBotErrorResponse
+		if err := json.Unmarshal(respBody, &errResp); err == nil {
+			apiErr.Message = errResp.Message
+		}
+		return nil, apiErr
+	}
+	return respBody, nil
+}
+
+// IsLineRecipientBlocked 收件者已封鎖官方帳號或尚未加入好友，重試也無法送達
+func IsLineRecipientBlocked(err error) bool {
+	var apiErr *LineAPIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.StatusCode == http.StatusBadRequest && apiErr.Message == "Failed to send messages"
+}
+
 // AdminProfile 管理員簡化資料（用於顯示）
 type AdminProfile struct {
 	ID       uint   `json:"id"`
@@ -166,6 +229,20 @@ type AgendaItem struct {
 	SourceName string `json:"source_name"`
 	// 來源類型
 	SourceType AgendaSourceType `json:"source_type"`
+	// 中心課程對應的排課規則、中心與日期（格式：2006-01-02，個人行程為空）
+	RuleID   uint   `json:"rule_id,omitempty"`
+	CenterID uint   `json:"center_id,omitempty"`
+	Date     string `json:"date,omitempty"`
+	// 該場次已有例外申請
+	HasException bool `json:"has_exception,omitempty"`
+}
+
+// AgendaDay 單日聚合行程
+type AgendaDay struct {
+	// 日期（00:00）
+	Date time.Time `json:"date"`
+	// 當日行程（依時間排序）
+	Items []AgendaItem `json:"items"`
 }
 
 // PushMessage 發送推播訊息給單一用戶
@@ -266,11 +343,12 @@ func (s *LineBotServiceImpl) sendRequest(ctx context.Context, endpoint string, b
 	}
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		apiErr := &LineAPIError{StatusCode: resp.StatusCode, Body: string(respBody)}
 		var errResp LineBotErrorResponse
-		if err := json.Unmarshal(respBody, &errResp); err != nil {
-			return fmt.Errorf("LINE API error (status %d): %s", resp.StatusCode, string(respBody))
+		if err := json.Unmarshal(respBody, &errResp); err == nil {
+			apiErr.Message = errResp.Message
 		}
-		return fmt.Errorf("LINE API error: %s", errResp.Message)
+		return apiErr
 	}
 
 	return nil
@@ -289,6 +367,14 @@ func (s *LineBotServiceImpl) generateSignature(body []byte) string {
 	return base64.StdEncoding.EncodeToString(hash.Sum(nil))
 }
 
+// lineTemplates 取得套用中心語系與自訂內容的 Flex Message 範本
+func (s *LineBotServiceImpl) lineTemplates(ctx context.Context, centerID uint) LineBotTemplateService {
+	if s.templates == nil {
+		return s.templateService
+	}
+	return s.templates.LineTemplates(ctx, centerID)
+}
+
 // SendWelcomeTeacher 發送老師歡迎訊息
 func (s *LineBotServiceImpl) SendWelcomeTeacher(ctx context.Context, teacher *models.Teacher, centerName string) error {
 	if teacher.LineUserID == "" {
@@ -305,8 +391,11 @@ func (s *LineBotServiceImpl) SendWelcomeAdmin(ctx context.Context, admin *models
 		return nil // 沒有 LINE ID，無法發送
 	}
 
-	flexMessage := s.templateService.GetWelcomeAdminTemplate(admin, centerName)
-	return s.PushFlexMessage(ctx, admin.LineUserID, "歡迎使用 TimeLedger！", flexMessage)
+	templates := s.lineTemplates(ctx, admin.CenterID)
+	flexMessage := templates.GetWelcomeAdminTemplate(admin, centerName)
+	altText := templates.GetText(models.NotificationTemplateWelcomeAdmin, "alt_text",
+		map[string]string{"admin_name": admin.Name, "center_name": centerName})
+	return s.PushFlexMessage(ctx, admin.LineUserID, altText, flexMessage)
 }
 
 // SendExceptionNotification 發送例外申請通知給管理員
@@ -315,8 +404,11 @@ func (s *LineBotServiceImpl) SendExceptionNotification(ctx context.Context, admi
 		return nil // 沒有綁定或通知已關閉
 	}
 
-	flexMessage := s.templateService.GetExceptionSubmitTemplate(exception, teacherName, "")
-	return s.PushFlexMessage(ctx, admin.LineUserID, "新的例外申請通知", flexMessage)
+	templates := s.lineTemplates(ctx, exception.CenterID)
+	flexMessage := templates.GetExceptionSubmitTemplate(exception, teacherName, "")
+	altText := templates.GetText(models.NotificationTemplateExceptionSubmit, "alt_text",
+		templates.ExceptionVariables(exception, teacherName, "", exception.Reason))
+	return s.PushFlexMessage(ctx, admin.LineUserID, altText, flexMessage)
 }
 
 // SendInvitationAcceptedNotification 發送邀請接受通知給管理員
@@ -325,7 +417,7 @@ func (s *LineBotServiceImpl) SendInvitationAcceptedNotification(ctx context.Cont
 		return nil // 沒有管理員可通知
 	}
 
-	flexMessage := s.templateService.GetInvitationAcceptedTemplate(teacher, centerName, role)
+	flexMessage := s.lineTemplates(ctx, admins[0].CenterID).GetInvitationAcceptedTemplate(teacher, centerName, role)
 
 	// 收集所有已綁定 LINE 的管理員
 	var lineUserIDs []string
@@ -433,6 +525,31 @@ func (s *LineBotServiceImpl) GetAggregatedAgenda(lineUserID string, targetDate *
 		return nil, fmt.Errorf("取得身份資訊失敗: %w", err)
 	}
 
+	return s.aggregateAgenda(identity, date), nil
+}
+
+// GetAggregatedAgendaRange 取得日期區間內每日的聚合行程（含起訖日），身份只查詢一次
+func (s *LineBotServiceImpl) GetAggregatedAgendaRange(lineUserID string, startDate, endDate time.Time) ([]AgendaDay, error) {
+	identity, err := s.GetCombinedIdentity(lineUserID)
+	if err != nil {
+		return nil, fmt.Errorf("取得身份資訊失敗: %w", err)
+	}
+
+	start := time.Date(startDate.Year(), startDate.Month(), startDate.Day(), 0, 0, 0, 0, startDate.Location())
+	end := time.Date(endDate.Year(), endDate.Month(), endDate.Day(), 0, 0, 0, 0, endDate.Location())
+
+	var days []AgendaDay
+	for date := start; !date.After(end); date = date.AddDate(0, 0, 1) {
+		days = append(days, AgendaDay{
+			Date:  date,
+			Items: s.aggregateAgenda(identity, date),
+		})
+	}
+	return days, nil
+}
+
+// aggregateAgenda 依身份彙整指定日期的中心課表與個人行程
+func (s *LineBotServiceImpl) aggregateAgenda(identity *CombinedIdentity, date time.Time) []AgendaItem {
 	var allItems []AgendaItem
 
 	// 如果是老師角色，取得老師 ID
@@ -467,10 +584,14 @@ func (s *LineBotServiceImpl) GetAggregatedAgenda(lineUserID string, targetDate *
 		// 將課表轉換為 AgendaItem
 		for _, schedule := range schedules {
 			item := AgendaItem{
-				Time:       schedule.StartTime,
-				Title:      schedule.OfferingName,
-				SourceName: centerName,
-				SourceType: AgendaSourceTypeCenter,
+				Time:         schedule.StartTime,
+				Title:        schedule.OfferingName,
+				SourceName:   centerName,
+				SourceType:   AgendaSourceTypeCenter,
+				RuleID:       schedule.RuleID,
+				CenterID:     centerID,
+				Date:         date.Format("2006-01-02"),
+				HasException: schedule.HasException,
 			}
 			allItems = append(allItems, item)
 		}
@@ -499,7 +620,7 @@ func (s *LineBotServiceImpl) GetAggregatedAgenda(lineUserID string, targetDate *
 	// 按時間排序
 	sortAgendaItemsByTime(allItems)
 
-	return allItems, nil
+	return allItems
 }
 
 // getCenterName 取得中心名稱
@@ -526,7 +647,7 @@ func (s *LineBotServiceImpl) getScheduleRulesForDate(ctx context.Context, center
 
 	err := s.app.MySQL.RDB.WithContext(ctx).
 		Where("center_id = ?", centerID).
-		Where("teacher_id = ?", teacherID).
+		Scopes(repositories.RuleHasTeacher(teacherID)).
 		Where("weekday = ?", weekday).
 		Where("COALESCE(NULLIF(NULLIF(JSON_UNQUOTE(JSON_EXTRACT(effective_range, '$.start_date')), ''), 'null'), '0001-01-01') <= ?", startDateStr).
 		Where("COALESCE(NULLIF(NULLIF(NULLIF(JSON_UNQUOTE(JSON_EXTRACT(effective_range, '$.end_date')), ''), 'null'), '0001-01-01 00:00:00'), '9999-12-31') >= ?", startDateStr).
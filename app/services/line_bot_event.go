@@ -0,0 +1,774 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"timeLedger/app"
+	"timeLedger/app/models"
+	"timeLedger/global/errInfos"
+	"timeLedger/libs"
+	"unicode/utf8"
+)
+
+// LINEWebhookEvent LINE Webhook 事件
+type LINEWebhookEvent struct {
+	Type       string            `json:"type"`
+	Mode       string            `json:"mode"`
+	Timestamp  int64             `json:"timestamp"`
+	Source     LINEEventSource   `json:"source"`
+	ReplyToken string            `json:"replyToken,omitempty"`
+	Message    LINEEventMessage  `json:"message,omitempty"`
+	Postback   LINEEventPostback `json:"postback,omitempty"`
+}
+
+// LINEEventSource 事件來源（type 為 user 或 group）
+type LINEEventSource struct {
+	Type    string `json:"type"`
+	UserID  string `json:"userId,omitempty"`
+	GroupID string `json:"groupId,omitempty"`
+}
+
+// LINEEventMessage 事件訊息
+type LINEEventMessage struct {
+	Type       string `json:"type"`
+	ID         string `json:"id"`
+	Text       string `json:"text,omitempty"`
+	QuoteToken string `json:"quoteToken,omitempty"`
+}
+
+// LINEEventPostback Postback 事件資料
+type LINEEventPostback struct {
+	Data string `json:"data"`
+}
+
+// LineBotEventService 處理 LINE webhook 事件內容：指令、綁定、請假對話與群組訊息
+type LineBotEventService struct {
+	BaseService
+	lineBot         LineBotService
+	adminService    *AdminUserService
+	templateService LineBotTemplateService
+	leaveService    *LineLeaveConversationService
+	groupService    *LineGroupService
+	events          *LineWebhookEventService
+}
+
+// NewLineBotEventService 建立 LINE 事件處理服務
+func NewLineBotEventService(app *app.App) *LineBotEventService {
+	svc := &LineBotEventService{
+		BaseService:  *NewBaseService(app, "LineBotEventService"),
+		lineBot:      NewLineBotService(app),
+		adminService: NewAdminUserService(app),
+		leaveService: NewLineLeaveConversationService(app),
+		groupService: NewLineGroupService(app),
+		events:       NewLineWebhookEventService(app),
+	}
+	if app.Env != nil {
+		svc.templateService = NewLineBotTemplateService(app.Env.FrontendBaseURL)
+	}
+	return svc
+}
+
+// ProcessEvents 依序處理本次收到的事件，已被其他 worker 搶占的事件略過
+func (s *LineBotEventService) ProcessEvents(ctx context.Context, ids []uint) {
+	for _, id := range ids {
+		if err := s.events.Process(ctx, id, s.Handle); err != nil {
+			s.Logger.Error("failed to process webhook event", "event_id", id, "error", err)
+		}
+	}
+}
+
+// ProcessPending 處理尚未完成的事件（其他實例中斷或管理員重新處理），回傳處理的筆數
+func (s *LineBotEventService) ProcessPending(ctx context.Context) (int, error) {
+	return s.events.ProcessDue(ctx, LineWebhookEventBatchSize, s.Handle)
+}
+
+// Handle 處理單筆事件（LineWebhookEventHandler）；重新處理時 replyToken 已失效，改推播給事件來源
+func (s *LineBotEventService) Handle(ctx context.Context, payload []byte, replay bool) error {
+	var event LINEWebhookEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return fmt.Errorf("invalid event payload: %w", err)
+	}
+
+	reply := lineEventReply{lineBot: s.lineBot, replyToken: event.ReplyToken}
+	if replay {
+		reply.pushTo = event.Source.GroupID
+		if reply.pushTo == "" {
+			reply.pushTo = event.Source.UserID
+		}
+	}
+	return s.handleEvent(ctx, reply, &event)
+}
+
+// lineEventReply 單筆事件的回覆對象：設定 pushTo 時以推播取代回覆
+type lineEventReply struct {
+	lineBot    LineBotService
+	replyToken string
+	pushTo     string
+}
+
+// Message 回覆訊息
+func (r lineEventReply) Message(ctx context.Context, message interface{}) error {
+	if r.pushTo != "" {
+		return r.lineBot.PushMessage(ctx, r.pushTo, message)
+	}
+	return r.lineBot.ReplyMessage(ctx, r.replyToken, message)
+}
+
+// Text 回覆文字訊息
+func (r lineEventReply) Text(ctx context.Context, text string) error {
+	return r.Message(ctx, map[string]interface{}{"type": "text", "text": text})
+}
+
+// Flex 回覆 Flex Message
+func (r lineEventReply) Flex(ctx context.Context, altText string, flexContent interface{}) error {
+	if r.pushTo != "" {
+		return r.lineBot.PushFlexMessage(ctx, r.pushTo, altText, flexContent)
+	}
+	return r.lineBot.ReplyFlexMessage(ctx, r.replyToken, altText, flexContent)
+}
+
+// isErrCode 比對服務回傳的錯誤代碼（Res.Code 帶有 app ID 前綴）
+func (s *LineBotEventService) isErrCode(eInfo *errInfos.Res, code errInfos.ErrCode) bool {
+	return eInfo != nil && eInfo.Code == s.App.Err.New(code).Code
+}
+
+// handleEvent 處理單個事件
+func (s *LineBotEventService) handleEvent(ctx context.Context, reply lineEventReply, event *LINEWebhookEvent) error {
+	// 群組內僅處理群組連結與唯讀查詢
+	if event.Source.Type == "group" {
+		return s.handleGroupEvent(ctx, reply, event)
+	}
+
+	switch event.Type {
+	case "message":
+		return s.handleMessageEvent(ctx, reply, event)
+	case "postback":
+		return s.handlePostbackEvent(ctx, reply, event)
+	case "follow":
+		return s.handleFollowEvent(ctx, reply, event)
+	case "unfollow":
+		return s.handleUnfollowEvent(ctx, event)
+	default:
+		s.Logger.Debug("unhandled event type", "event_type", event.Type)
+		return nil
+	}
+}
+
+// handleMessageEvent 處理訊息事件
+func (s *LineBotEventService) handleMessageEvent(ctx context.Context, reply lineEventReply, event *LINEWebhookEvent) error {
+	if event.Message.Type != "text" {
+		return nil
+	}
+
+	text := event.Message.Text
+	userID := event.Source.UserID
+
+	// 【監控日誌】記錄用戶互動
+	identity, _ := s.lineBot.GetCombinedIdentity(userID)
+	s.Logger.Info("line_webhook_message",
+		"user_id", userID,
+		"primary_role", identity.PrimaryRole,
+		"message_type", "text",
+		"message_preview", truncateString(text, 50),
+	)
+
+	// 處理驗證碼（6位數大寫字母數字）
+	if len(text) == 6 && isValidBindingCode(text) {
+		return s.processBindingCode(ctx, reply, text, userID)
+	}
+
+	// 請假對話進行中時，優先處理原因輸入與取消
+	answer, handled, err := s.leaveService.HandleText(ctx, userID, text)
+	if err != nil {
+		return s.leaveFailed(ctx, reply, fmt.Errorf("failed to handle leave conversation text: %w", err))
+	}
+	if handled {
+		return reply.Message(ctx, answer)
+	}
+
+	// 處理關鍵字
+	switch text {
+	case "綁定", "bind", "Bind":
+		return s.sendBindingInstructions(ctx, reply)
+	case "幫助", "幫我", "help", "Help":
+		return s.sendHelpMessage(ctx, reply)
+	case "狀態", "status", "Status":
+		return s.sendStatusMessage(ctx, reply, userID)
+	case "解除綁定", "unbind", "Unbind":
+		return s.sendUnbindInstructions(ctx, reply)
+	case "了解更多", "更多", "more", "More":
+		return s.sendMoreInfoMessage(ctx, reply)
+	case "稍後綁定", "稍後再說":
+		return s.sendAckMessage(ctx, reply)
+	case "課表", "我的課表", "今日課表", "schedule", "Schedule":
+		return s.sendScheduleMessage(ctx, reply, userID)
+	case "明天課表", "明日課表":
+		return s.sendScheduleMessage(ctx, reply, userID, true)
+	case "請假", "我要請假", "leave", "Leave":
+		return s.startLeaveConversation(ctx, reply, userID)
+	default:
+		// 日期查詢，例如「下週三課表」、「這週」、「3/15 有課嗎」、「下個月幾堂課」
+		if query, ok := libs.ParseDateQuery(text, libs.NowInTaiwan()); ok {
+			return s.sendDateQueryMessage(ctx, reply, userID, query)
+		}
+		return s.sendDefaultResponse(ctx, reply)
+	}
+}
+
+// handlePostbackEvent 處理 Postback 事件（按鈕回傳）
+func (s *LineBotEventService) handlePostbackEvent(ctx context.Context, reply lineEventReply, event *LINEWebhookEvent) error {
+	userID := event.Source.UserID
+
+	answer, handled, err := s.leaveService.HandlePostback(ctx, userID, event.Postback.Data)
+	if err != nil {
+		return s.leaveFailed(ctx, reply, fmt.Errorf("failed to handle leave conversation postback: %w", err))
+	}
+	if !handled {
+		s.Logger.Debug("unhandled postback", "data", event.Postback.Data)
+		return nil
+	}
+	return reply.Message(ctx, answer)
+}
+
+// startLeaveConversation 開始 LINE 請假對話
+func (s *LineBotEventService) startLeaveConversation(ctx context.Context, reply lineEventReply, userID string) error {
+	answer, err := s.leaveService.Start(ctx, userID)
+	if err != nil {
+		return s.leaveFailed(ctx, reply, fmt.Errorf("failed to start leave conversation: %w", err))
+	}
+	return reply.Message(ctx, answer)
+}
+
+// leaveFailed 請假對話處理失敗：回覆使用者後回傳原錯誤
+func (s *LineBotEventService) leaveFailed(ctx context.Context, reply lineEventReply, err error) error {
+	return errors.Join(err, reply.Text(ctx, "❌ 請假申請處理失敗，請稍後再試。\n\n"+
+		"如有問題，請聯繫系統管理員。"))
+}
+
+// handleFollowEvent 處理加入好友事件
+func (s *LineBotEventService) handleFollowEvent(ctx context.Context, reply lineEventReply, event *LINEWebhookEvent) error {
+	userID := event.Source.UserID
+
+	// 【監控日誌】記錄用戶關注
+	identity, _ := s.lineBot.GetCombinedIdentity(userID)
+	s.Logger.Info("line_webhook_follow",
+		"user_id", userID,
+		"primary_role", identity.PrimaryRole,
+		"is_bound_admin", identity.PrimaryRole == "ADMIN",
+		"is_bound_teacher", identity.PrimaryRole == "TEACHER",
+	)
+
+	// 1. 檢查是否為已綁定的管理員；查詢失敗時仍以老師歡迎訊息回覆
+	adminStatus, _, err := s.adminService.GetLINEBindingStatusByLineUserID(ctx, userID)
+	if err != nil {
+		s.Logger.Warn("failed to get admin LINE binding status", "error", err, "user_id", userID)
+	}
+	if adminStatus != nil && adminStatus.IsBound {
+		// 已綁定的管理員
+		centerName := "TimeLedger"
+
+		welcomeFlex := s.templateService.GetWelcomeAdminTemplate(&models.AdminUser{
+			LineUserID: userID,
+			Role:       adminStatus.Role,
+		}, centerName)
+
+		err := reply.Flex(ctx, "歡迎回來！", welcomeFlex)
+		if err == nil {
+			return nil // 成功發送 Flex Message
+		}
+		s.Logger.Warn("failed to send admin welcome flex, using text", "error", err)
+	}
+
+	// 2. 檢查是否為老師（通過 LINE User ID）
+	// 老師的歡迎訊息
+	welcomeFlex := s.templateService.GetWelcomeTeacherTemplate(&models.Teacher{
+		LineUserID: userID,
+	}, "TimeLedger")
+
+	err = reply.Flex(ctx, "歡迎加入 TimeLedger！", welcomeFlex)
+	if err == nil {
+		return nil // 成功發送老師歡迎訊息
+	}
+
+	// 3. 如果 Flex Message 失敗，發送通用文字訊息
+	s.Logger.Error("failed to send welcome flex message", "error", err)
+	if textErr := reply.Text(ctx, "👋 您好！歡迎加入 TimeLedger！\n\n"+
+		"TimeLedger 是教師中心化多據點排課平台，\n"+
+		"讓您可以輕鬆管理課表、提交例外申請。\n\n"+
+		"如需使用，請透過 LIFF 頁面登入。"); textErr != nil {
+		return errors.Join(err, textErr)
+	}
+	return nil
+}
+
+// handleUnfollowEvent 處理封鎖/取消好友事件
+func (s *LineBotEventService) handleUnfollowEvent(ctx context.Context, event *LINEWebhookEvent) error {
+	userID := event.Source.UserID
+
+	// 【監控日誌】記錄用戶取消關注
+	s.Logger.Info("line_webhook_unfollow",
+		"user_id", userID,
+	)
+	return nil
+}
+
+// handleGroupEvent 處理群組事件：加入、被移出與群組內訊息
+func (s *LineBotEventService) handleGroupEvent(ctx context.Context, reply lineEventReply, event *LINEWebhookEvent) error {
+	groupID := event.Source.GroupID
+
+	switch event.Type {
+	case "join":
+		s.Logger.Info("line_webhook_group_join", "group_id", groupID)
+		group, err := s.groupService.FindLinkedGroup(ctx, groupID)
+		if err != nil {
+			return fmt.Errorf("failed to get linked group %s: %w", groupID, err)
+		}
+		text := "👋 大家好，我是 TimeLedger！\n\n" +
+			"請管理員至後台「設定」→「LINE 群組」產生群組驗證碼，\n" +
+			"並將 6 碼驗證碼貼到此群組，即可接收中心通知。"
+		if group != nil {
+			text = "👋 TimeLedger 已回到群組，將繼續發送中心通知。\n輸入「幫助」查看可用指令。"
+		}
+		return reply.Text(ctx, text)
+	case "leave":
+		s.Logger.Info("line_webhook_group_leave", "group_id", groupID)
+		if err := s.groupService.HandleBotRemoved(ctx, groupID); err != nil {
+			return fmt.Errorf("failed to remove group link %s: %w", groupID, err)
+		}
+		return nil
+	case "message":
+		return s.handleGroupMessageEvent(ctx, reply, event)
+	default:
+		return nil
+	}
+}
+
+// handleGroupMessageEvent 群組內訊息：未連結時只接受驗證碼，已連結時提供唯讀指令，其餘訊息不回應
+func (s *LineBotEventService) handleGroupMessageEvent(ctx context.Context, reply lineEventReply, event *LINEWebhookEvent) error {
+	if event.Message.Type != "text" {
+		return nil
+	}
+	text := strings.TrimSpace(event.Message.Text)
+	groupID := event.Source.GroupID
+
+	group, err := s.groupService.FindLinkedGroup(ctx, groupID)
+	if err != nil {
+		return fmt.Errorf("failed to get linked group %s: %w", groupID, err)
+	}
+
+	if group == nil {
+		if isValidBindingCode(strings.ToUpper(text)) {
+			return s.processGroupLinkCode(ctx, reply, groupID, strings.ToUpper(text))
+		}
+		return nil
+	}
+
+	switch text {
+	case "課表", "今日課表", "今天課表":
+		return s.sendGroupAgendaMessage(ctx, reply, group.CenterID, libs.NowInTaiwan())
+	case "明日課表", "明天課表":
+		return s.sendGroupAgendaMessage(ctx, reply, group.CenterID, libs.NowInTaiwan().AddDate(0, 0, 1))
+	case "幫助", "help", "Help":
+		return reply.Text(ctx, "❓ TimeLedger 群組指令：\n\n"+
+			"• 「今日課表」- 查看中心今日所有課程\n"+
+			"• 「明日課表」- 查看中心明日所有課程\n\n"+
+			"群組會依後台設定收到例外申請、每日摘要與緊急停課通知。")
+	default:
+		return nil
+	}
+}
+
+// processGroupLinkCode 以驗證碼將群組連結至中心；驗證碼錯誤等使用者輸入問題只回覆，不視為處理失敗
+func (s *LineBotEventService) processGroupLinkCode(ctx context.Context, reply lineEventReply, groupID string, code string) error {
+	group, eInfo, err := s.groupService.LinkGroup(ctx, groupID, code)
+	if err != nil {
+		s.Logger.Warn("failed to link LINE group", "error", err, "group_id", groupID)
+		errorMsg := "❌ 群組連結失敗，驗證碼錯誤或已過期，請至後台重新產生。"
+		switch {
+		case s.isErrCode(eInfo, errInfos.LINE_GROUP_LINKED_ELSEWHERE):
+			errorMsg = "❌ 此群組已連結其他中心，請先於原中心後台解除連結。"
+		case s.isErrCode(eInfo, errInfos.RATE_LIMIT_EXCEEDED):
+			errorMsg = "❌ 驗證碼錯誤次數過多，請 30 分鐘後再試。"
+		case !s.isErrCode(eInfo, errInfos.LINE_BINDING_CODE_INVALID):
+			return errors.Join(fmt.Errorf("failed to link group %s: %w", groupID, err), reply.Text(ctx, errorMsg))
+		}
+		return reply.Text(ctx, errorMsg)
+	}
+
+	s.Logger.Info("line_group_linked", "group_id", groupID, "center_id", group.CenterID)
+	return reply.Text(ctx, "✅ 群組連結成功！\n\n"+
+		"此群組將收到：\n"+
+		"🔔 老師提交的例外申請\n"+
+		"☀️ 每日課表摘要（"+group.DailySummaryTime+"）\n"+
+		"🚨 緊急停課通知\n\n"+
+		"可至後台調整通知類型，輸入「幫助」查看群組指令。")
+}
+
+// sendGroupAgendaMessage 回覆中心指定日期的課表
+func (s *LineBotEventService) sendGroupAgendaMessage(ctx context.Context, reply lineEventReply, centerID uint, date time.Time) error {
+	text, err := s.groupService.AgendaText(ctx, centerID, date)
+	if err != nil {
+		return errors.Join(fmt.Errorf("failed to build group agenda for center %d: %w", centerID, err),
+			reply.Text(ctx, "❌ 無法取得課表，請稍後再試。"))
+	}
+	return reply.Text(ctx, text)
+}
+
+// processBindingCode 處理綁定驗證碼；驗證碼錯誤或過期只回覆，不視為處理失敗
+func (s *LineBotEventService) processBindingCode(ctx context.Context, reply lineEventReply, code string, userID string) error {
+	adminID, eInfo, err := s.adminService.VerifyLINEBinding(ctx, code, userID)
+	if err != nil {
+		s.Logger.Warn("failed to verify binding code", "error", err)
+		errorMsg := "❌ 綁定失敗，驗證碼錯誤或已過期。"
+		switch {
+		case s.isErrCode(eInfo, errInfos.LINE_BINDING_EXPIRED):
+			errorMsg = "❌ 驗證碼已過期，請至後台重新產生。"
+		case !s.isErrCode(eInfo, errInfos.LINE_BINDING_CODE_INVALID):
+			return errors.Join(fmt.Errorf("failed to verify binding code: %w", err), reply.Text(ctx, errorMsg))
+		}
+		return reply.Text(ctx, errorMsg)
+	}
+
+	// 綁定成功
+	if err := reply.Text(ctx, "✅ 綁定成功！\n\n"+
+		"您將會收到：\n"+
+		"🔔 老師提交例外申請的通知\n"+
+		"🔔 審核結果通知\n\n"+
+		"如需調整通知設定，請至後台「設定」→「通知設定」。"); err != nil {
+		return err
+	}
+
+	// 發送歡迎訊息（異步）
+	go func() {
+		welcomeCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := s.adminService.SendWelcomeMessageIfNeeded(welcomeCtx, adminID); err != nil {
+			s.Logger.Error("failed to send welcome message after binding", "admin_id", adminID, "error", err)
+		}
+	}()
+	return nil
+}
+
+// sendBindingInstructions 發送綁定說明
+func (s *LineBotEventService) sendBindingInstructions(ctx context.Context, reply lineEventReply) error {
+	message := map[string]interface{}{
+		"type": "text",
+		"text": "🔗 綁定步驟：\n\n" +
+			"1. 登入 TimeLedger 管理後台\n" +
+			"2. 點擊右上角「設定」\n" +
+			"3. 點擊「LINE 通知」\n" +
+			"4. 點擊「開始綁定」\n" +
+			"5. 掃描 QR Code 或輸入顯示的驗證碼\n\n" +
+			"如有問題，請聯繫系統管理員。",
+	}
+	return reply.Message(ctx, message)
+}
+
+// sendHelpMessage 發送幫助訊息
+func (s *LineBotEventService) sendHelpMessage(ctx context.Context, reply lineEventReply) error {
+	message := map[string]interface{}{
+		"type": "text",
+		"text": "❓ TimeLedger 指令說明：\n\n" +
+			"📌 綁定相關：\n" +
+			"• 「綁定」- 開始 LINE 綁定流程\n" +
+			"• 「解除綁定」- 解除 LINE 綁定\n\n" +
+			"📌 查詢相關：\n" +
+			"• 「狀態」- 查看綁定狀態\n" +
+			"• 「課表」- 查看今日課表\n" +
+			"• 「下週三課表」、「這週」、「3/15 有課嗎」- 查詢指定日期\n\n" +
+			"📌 老師專用：\n" +
+			"• 「請假」- 透過對話提出請假申請\n\n" +
+			"📌 其他：\n" +
+			"• 「幫助」- 顯示此說明訊息\n\n" +
+			"如有問題，請聯繫系統管理員。",
+	}
+	return reply.Message(ctx, message)
+}
+
+// sendStatusMessage 發送狀態訊息
+func (s *LineBotEventService) sendStatusMessage(ctx context.Context, reply lineEventReply, userID string) error {
+	message := map[string]interface{}{
+		"type": "text",
+		"text": "📊 狀態查詢：\n\n" +
+			"您的 LINE 帳號已與 TimeLedger 綁定。\n\n" +
+			"如需調整設定，請至管理後台。",
+	}
+	return reply.Message(ctx, message)
+}
+
+// sendUnbindInstructions 發送解除綁定說明
+func (s *LineBotEventService) sendUnbindInstructions(ctx context.Context, reply lineEventReply) error {
+	message := map[string]interface{}{
+		"type": "text",
+		"text": "🔓 解除綁定：\n\n" +
+			"請至 TimeLedger 管理後台：\n" +
+			"1. 點擊右上角「設定」\n" +
+			"2. 點擊「LINE 通知」\n" +
+			"3. 點擊「解除綁定」\n" +
+			"4. 確認解除綁定\n\n" +
+			"⚠️ 解除綁定後將無法收到即時通知。",
+	}
+	return reply.Message(ctx, message)
+}
+
+// sendMoreInfoMessage 發送更多資訊
+func (s *LineBotEventService) sendMoreInfoMessage(ctx context.Context, reply lineEventReply) error {
+	message := map[string]interface{}{
+		"type": "text",
+		"text": "ℹ️ TimeLedger 介紹：\n\n" +
+			"TimeLedger 是教師中心化多據點排課平台，\n" +
+			"讓您可以：\n\n" +
+			"• 接收老師的例外申請通知\n" +
+			"• 即時處理請假、調課等申請\n" +
+			"• 透過手機 LINE 隨時掌握動態\n\n" +
+			"如有問題，請聯繫系統管理員。",
+	}
+	return reply.Message(ctx, message)
+}
+
+// sendAckMessage 發送確認訊息
+func (s *LineBotEventService) sendAckMessage(ctx context.Context, reply lineEventReply) error {
+	message := map[string]interface{}{
+		"type": "text",
+		"text": "ℹ️ 了解！\n\n" +
+			"您可以稍後再進行綁定。\n" +
+			"當您準備好時，輸入「綁定」即可開始流程。",
+	}
+	return reply.Message(ctx, message)
+}
+
+// sendDefaultResponse 發送預設回應
+func (s *LineBotEventService) sendDefaultResponse(ctx context.Context, reply lineEventReply) error {
+	message := map[string]interface{}{
+		"type": "text",
+		"text": "🤔 我不太理解您的意思。\n\n" +
+			"輸入「幫助」查看可用指令。",
+	}
+	return reply.Message(ctx, message)
+}
+
+// sendScheduleMessage 發送課表訊息
+// isTomorrow: true 表示查詢明天課表，false 表示查詢今天課表
+func (s *LineBotEventService) sendScheduleMessage(ctx context.Context, reply lineEventReply, userID string, isTomorrow ...bool) error {
+	// 計算目標日期
+	targetDate := time.Now()
+	if len(isTomorrow) > 0 && isTomorrow[0] {
+		targetDate = targetDate.AddDate(0, 0, 1)
+	}
+
+	// 取得當日課表
+	agendaItems, err := s.lineBot.GetAggregatedAgenda(userID, &targetDate)
+	if err != nil {
+		return s.agendaFailed(ctx, reply, fmt.Errorf("failed to get aggregated agenda: %w", err))
+	}
+
+	// 取得用戶名稱（用於標題顯示）
+	userName := ""
+	identity, err := s.lineBot.GetCombinedIdentity(userID)
+	if err == nil {
+		if identity.TeacherProfile != nil {
+			userName = identity.TeacherProfile.Name
+		} else if len(identity.AdminProfiles) > 0 {
+			userName = identity.AdminProfiles[0].Name
+		}
+	}
+	if userName == "" {
+		userName = "您"
+	}
+
+	// 產生 Flex Message
+	flexContent := s.templateService.GenerateAgendaFlex(agendaItems, targetDate, userName)
+
+	// 發送 Flex Message
+	err = reply.Flex(ctx, "今日課表", flexContent)
+	if err == nil {
+		return nil
+	}
+	s.Logger.Error("failed to send schedule flex message", "error", err)
+	// Flex Message 失敗時，發送文字訊息
+	var fallbackMsg map[string]interface{}
+	if len(agendaItems) == 0 {
+		weekdayStr := getWeekdayChinese(targetDate)
+		dateStr := targetDate.Format("1月2日")
+		fallbackMsg = map[string]interface{}{
+			"type": "text",
+			"text": fmt.Sprintf("📅 %s (%s)\n\n"+
+
+				"目前沒有課表。\n\n"+
+				"💡 您可以透過 LIFF 頁面查看完整課表。", dateStr, weekdayStr),
+		}
+	} else {
+		fallbackMsg = s.buildScheduleFallbackMessage(agendaItems, targetDate)
+	}
+	if fallbackErr := reply.Message(ctx, fallbackMsg); fallbackErr != nil {
+		return errors.Join(err, fallbackErr)
+	}
+	return nil
+}
+
+// sendDateQueryMessage 依日期查詢回覆課表：單日使用當日行程，多日使用每週摘要
+func (s *LineBotEventService) sendDateQueryMessage(ctx context.Context, reply lineEventReply, userID string, query libs.DateQuery) error {
+	days, err := s.lineBot.GetAggregatedAgendaRange(userID, query.Start, query.End)
+	if err != nil {
+		return s.agendaFailed(ctx, reply, fmt.Errorf("failed to get aggregated agenda range: %w", err))
+	}
+
+	userName := "您"
+	if identity, err := s.lineBot.GetCombinedIdentity(userID); err == nil {
+		if identity.TeacherProfile != nil {
+			userName = identity.TeacherProfile.Name
+		} else if len(identity.AdminProfiles) > 0 {
+			userName = identity.AdminProfiles[0].Name
+		}
+	}
+
+	if query.IsSingleDay() && len(days) == 1 {
+		day := days[0]
+		altText := fmt.Sprintf("%s (%s) 課表", day.Date.Format("1月2日"), getWeekdayChinese(day.Date))
+		flexContent := s.templateService.GenerateAgendaFlex(day.Items, day.Date, userName)
+		if err := reply.Flex(ctx, altText, flexContent); err != nil {
+			s.Logger.Error("failed to send date query flex message", "error", err)
+			if fallbackErr := reply.Message(ctx, s.buildScheduleFallbackMessage(day.Items, day.Date)); fallbackErr != nil {
+				return errors.Join(err, fallbackErr)
+			}
+		}
+		return nil
+	}
+
+	title := dateQueryTitle(query)
+	centerCount, _ := CountAgendaItems(days)
+	altText := fmt.Sprintf("%s：共 %d 堂課", title, centerCount)
+	flexContent := s.templateService.GenerateAgendaSummaryFlex(days, title, userName)
+	if err := reply.Flex(ctx, altText, flexContent); err != nil {
+		s.Logger.Error("failed to send agenda summary flex message", "error", err)
+		if fallbackErr := reply.Message(ctx, buildAgendaSummaryFallbackMessage(days, title)); fallbackErr != nil {
+			return errors.Join(err, fallbackErr)
+		}
+	}
+	return nil
+}
+
+// agendaFailed 取得課表失敗：回覆使用者後回傳原錯誤
+func (s *LineBotEventService) agendaFailed(ctx context.Context, reply lineEventReply, err error) error {
+	return errors.Join(err, reply.Text(ctx, "❌ 取得課表失敗，請稍後再試。\n\n"+
+		"如有問題，請聯繫系統管理員。"))
+}
+
+// dateQueryTitle 多日查詢的標題：整月、整週或起訖日期
+func dateQueryTitle(query libs.DateQuery) string {
+	start, end := query.Start, query.End
+	switch {
+	case start.Day() == 1 && end.AddDate(0, 0, 1).Day() == 1 && start.Month() == end.Month():
+		return fmt.Sprintf("%d年%d月行程", start.Year(), start.Month())
+	case start.Weekday() == time.Monday && query.Days() == 7:
+		return fmt.Sprintf("%s 當週行程", start.Format("1/2"))
+	default:
+		return fmt.Sprintf("%s - %s 行程", start.Format("1/2"), end.Format("1/2"))
+	}
+}
+
+// buildAgendaSummaryFallbackMessage 建立多日行程文字回覆（當 Flex Message 失敗時使用）
+func buildAgendaSummaryFallbackMessage(days []AgendaDay, title string) map[string]interface{} {
+	centerCount, _ := CountAgendaItems(days)
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("📅 %s\n📊 共 %d 堂課\n", title, centerCount))
+	for _, day := range days {
+		if len(day.Items) == 0 {
+			continue
+		}
+		// LINE 文字訊息上限 5000 字
+		if utf8.RuneCountInString(sb.String()) > 4500 {
+			sb.WriteString("\n…更多行程請至 LIFF 頁面查看")
+			break
+		}
+		sb.WriteString(fmt.Sprintf("\n%s (%s)\n", day.Date.Format("1/2"), getWeekdayChinese(day.Date)))
+		for _, item := range day.Items {
+			sb.WriteString(fmt.Sprintf("  %s │ %s (%s)\n", item.Time, item.Title, item.SourceName))
+		}
+	}
+
+	return map[string]interface{}{
+		"type": "text",
+		"text": strings.TrimRight(sb.String(), "\n"),
+	}
+}
+
+// buildScheduleFallbackMessage 建立課表文字回覆（當 Flex Message 失敗時使用）
+func (s *LineBotEventService) buildScheduleFallbackMessage(agendaItems []AgendaItem, targetDate time.Time) map[string]interface{} {
+	dateStr := targetDate.Format("1月2日")
+	weekdayStr := getWeekdayChinese(targetDate)
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("📅 %s (%s)\n\n", dateStr, weekdayStr))
+
+	// 分組顯示
+	var centerItems, personalItems []AgendaItem
+	for _, item := range agendaItems {
+		if item.SourceType == AgendaSourceTypeCenter {
+			centerItems = append(centerItems, item)
+		} else {
+			personalItems = append(personalItems, item)
+		}
+	}
+
+	// 顯示中心課表
+	if len(centerItems) > 0 {
+		sb.WriteString("🏢 中心課表\n")
+		for _, item := range centerItems {
+			sb.WriteString(fmt.Sprintf("  %s │ %s (%s)\n", item.Time, item.Title, item.SourceName))
+		}
+		if len(personalItems) > 0 {
+			sb.WriteString("\n")
+		}
+	}
+
+	// 顯示個人行程
+	if len(personalItems) > 0 {
+		sb.WriteString("📌 個人行程\n")
+		for _, item := range personalItems {
+			sb.WriteString(fmt.Sprintf("  %s │ %s\n", item.Time, item.Title))
+		}
+	}
+
+	sb.WriteString("\n💡 輸入「課表」查看明日課表")
+
+	return map[string]interface{}{
+		"type": "text",
+		"text": sb.String(),
+	}
+}
+
+// getWeekdayChinese 取得星期幾的中文名稱
+func getWeekdayChinese(date time.Time) string {
+	weekdays := []string{"週日", "週一", "週二", "週三", "週四", "週五", "週六"}
+	return weekdays[date.Weekday()]
+}
+
+// isValidBindingCode 檢查是否為有效的綁定驗證碼格式
+func isValidBindingCode(code string) bool {
+	if len(code) != 6 {
+		return false
+	}
+	for _, c := range code {
+		if !((c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')) {
+			return false
+		}
+	}
+	return true
+}
+
+// truncateString 截斷字串（用於日誌顯示）
+func truncateString(s string, maxLen int) string {
+	if len(s) <= maxLen {
+		return s
+	}
+	if maxLen <= 3 {
+		return "..."
+	}
+	return s[:maxLen-3] + "..."
+}
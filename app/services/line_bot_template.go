@@ -22,20 +22,75 @@ type LineBotTemplateService interface {
 
 	// 取得行程聚合範本
 	GenerateAgendaFlex(agendaItems []AgendaItem, targetDate time.Time, userName string) interface{}
+	GenerateAgendaSummaryFlex(days []AgendaDay, title string, userName string) interface{}
 
 	// 取得廣播訊息範本
 	GetBroadcastTemplate(centerName string, title string, message string, warning string, actionLabel string, actionURL string) interface{}
+
+	// 取得範本欄位文字（如替代文字 alt_text）
+	GetText(templateType string, field string, vars map[string]string) string
+	// 取得例外通知範本使用的變數
+	ExceptionVariables(exception *models.ScheduleException, teacherName string, centerName string, reason string) map[string]string
 }
 
 // LineBotTemplateServiceImpl Flex Message 範本服務實現
 type LineBotTemplateServiceImpl struct {
-	baseURL string // 前端網站 URL
+	baseURL   string // 前端網站 URL
+	locale    string
+	overrides map[string]*models.NotificationTemplate // 中心自訂範本，key 為範本類型
 }
 
+// NewLineBotTemplateService 建立使用內建繁體中文範本的服務
 func NewLineBotTemplateService(baseURL string) LineBotTemplateService {
-	return &LineBotTemplateServiceImpl{
-		baseURL: baseURL,
+	return NewLocalizedLineBotTemplateService(baseURL, models.LocaleZhTW, nil)
+}
+
+// NewLocalizedLineBotTemplateService 建立指定語系並套用中心自訂範本的服務
+func NewLocalizedLineBotTemplateService(baseURL string, locale string, overrides []models.NotificationTemplate) LineBotTemplateService {
+	svc := &LineBotTemplateServiceImpl{
+		baseURL:   baseURL,
+		locale:    locale,
+		overrides: make(map[string]*models.NotificationTemplate),
+	}
+	for i := range overrides {
+		if overrides[i].Channel == models.NotificationChannelLine {
+			svc.overrides[overrides[i].Type] = &overrides[i]
+		}
 	}
+	return svc
+}
+
+// template 取得套用語系與中心自訂後的範本
+func (s *LineBotTemplateServiceImpl) template(templateType string) *ResolvedNotificationTemplate {
+	return ResolveNotificationTemplate(templateType, models.NotificationChannelLine, s.locale, s.overrides[templateType])
+}
+
+// GetText 取得範本欄位文字
+func (s *LineBotTemplateServiceImpl) GetText(templateType string, field string, vars map[string]string) string {
+	return s.template(templateType).Text(field, vars)
+}
+
+// ExceptionVariables 例外通知範本變數，例外類型依語系顯示
+func (s *LineBotTemplateServiceImpl) ExceptionVariables(exception *models.ScheduleException, teacherName string, centerName string, reason string) map[string]string {
+	if reason == "" {
+		reason = notificationLabel(s.locale, "reason.none")
+	}
+	return map[string]string{
+		"teacher_name":   teacherName,
+		"center_name":    centerName,
+		"exception_type": notificationLabel(s.locale, "exception."+exception.ExceptionType),
+		"date":           exception.GetDate().Format("2006/01/02 (Mon)"),
+		"time_range":     exception.GetTimeRange(),
+		"reason":         reason,
+	}
+}
+
+// withColor 設定元件顏色，空字串表示使用 LINE 預設
+func withColor(component map[string]interface{}, color string) map[string]interface{} {
+	if color != "" {
+		component["color"] = color
+	}
+	return component
 }
 
 // GetWelcomeTeacherTemplate 老師歡迎訊息範本
@@ -128,9 +183,11 @@ func (s *LineBotTemplateServiceImpl) GetWelcomeTeacherTemplate(teacher *models.T
 func (s *LineBotTemplateServiceImpl) GetWelcomeAdminTemplate(admin *models.AdminUser, centerName string) interface{} {
 	bindURL := fmt.Sprintf("%s/admin/line-bind", s.baseURL)
 
-	roleText := "中心管理員"
-	if admin.Role == "OWNER" {
-		roleText = "中心擁有者"
+	t := s.template(models.NotificationTemplateWelcomeAdmin)
+	vars := map[string]string{
+		"admin_name":  admin.Name,
+		"center_name": centerName,
+		"role":        notificationLabel(s.locale, "admin_role."+admin.Role),
 	}
 
 	return map[string]interface{}{
@@ -141,7 +198,7 @@ func (s *LineBotTemplateServiceImpl) GetWelcomeAdminTemplate(admin *models.Admin
 			"contents": []interface{}{
 				map[string]interface{}{
 					"type":   "text",
-					"text":   "🎉 歡迎使用 TimeLedger！",
+					"text":   t.Text("title", vars),
 					"weight": "bold",
 					"size":   "lg",
 				},
@@ -150,57 +207,54 @@ func (s *LineBotTemplateServiceImpl) GetWelcomeAdminTemplate(admin *models.Admin
 					"text": " ",
 					"size": "sm",
 				},
-				map[string]interface{}{
-					"type":  "text",
-					"text":  fmt.Sprintf("您的中心：%s", centerName),
-					"size":  "md",
-					"color": "#666666",
-				},
-				map[string]interface{}{
-					"type":  "text",
-					"text":  fmt.Sprintf("您的角色：%s", roleText),
-					"size":  "md",
-					"color": "#666666",
-				},
+				withColor(map[string]interface{}{
+					"type": "text",
+					"text": t.Text("center", vars),
+					"size": "md",
+				}, t.Color("info")),
+				withColor(map[string]interface{}{
+					"type": "text",
+					"text": t.Text("role", vars),
+					"size": "md",
+				}, t.Color("info")),
 				map[string]interface{}{
 					"type":   "separator",
 					"margin": "md",
 				},
 				map[string]interface{}{
 					"type":   "text",
-					"text":   "🔔 及時通知功能",
+					"text":   t.Text("feature_title", vars),
 					"weight": "bold",
 					"margin": "md",
 				},
-				map[string]interface{}{
-					"type":  "text",
-					"text":  "綁定 LINE 帳號後，當老師提交例外申請時，\n您會立即收到通知！",
-					"size":  "sm",
-					"color": "#999999",
-					"wrap":  true,
-				},
+				withColor(map[string]interface{}{
+					"type": "text",
+					"text": t.Text("feature_body", vars),
+					"size": "sm",
+					"wrap": true,
+				}, t.Color("feature_body")),
 			},
 		},
 		"footer": map[string]interface{}{
 			"type":   "box",
 			"layout": "horizontal",
 			"contents": []interface{}{
-				map[string]interface{}{
+				withColor(map[string]interface{}{
 					"type":  "button",
 					"style": "primary",
 					"action": map[string]interface{}{
 						"type":  "uri",
-						"label": "立即綁定",
+						"label": t.Text("bind_button", vars),
 						"uri":   bindURL,
 					},
-				},
+				}, t.Color("button")),
 				map[string]interface{}{
 					"type":  "button",
 					"style": "secondary",
 					"action": map[string]interface{}{
 						"type":  "message",
-						"label": "稍後再說",
-						"text":  "稍後綁定",
+						"label": t.Text("later_button", vars),
+						"text":  "稍後綁定", // 由 webhook 比對的指令，不隨範本變動
 					},
 				},
 			},
@@ -211,7 +265,7 @@ func (s *LineBotTemplateServiceImpl) GetWelcomeAdminTemplate(admin *models.Admin
 					"type": "action",
 					"action": map[string]interface{}{
 						"type":  "uri",
-						"label": "🔗 前往綁定",
+						"label": t.Text("quick_bind", vars),
 						"uri":   bindURL,
 					},
 				},
@@ -219,7 +273,7 @@ func (s *LineBotTemplateServiceImpl) GetWelcomeAdminTemplate(admin *models.Admin
 					"type": "action",
 					"action": map[string]interface{}{
 						"type":  "message",
-						"label": "❓ 了解更多",
+						"label": t.Text("quick_more", vars),
 						"text":  "了解更多",
 					},
 				},
@@ -232,18 +286,8 @@ func (s *LineBotTemplateServiceImpl) GetWelcomeAdminTemplate(admin *models.Admin
 func (s *LineBotTemplateServiceImpl) GetExceptionSubmitTemplate(exception *models.ScheduleException, teacherName string, centerName string) interface{} {
 	adminURL := fmt.Sprintf("%s/admin/exceptions/%d", s.baseURL, exception.ID)
 
-	// 根據類型顯示不同標題
-	typeTitle := "例外申請"
-	switch exception.ExceptionType {
-	case "LEAVE":
-		typeTitle = "請假申請"
-	case "RESCHEDULE":
-		typeTitle = "調課申請"
-	case "SWAP":
-		typeTitle = "代課申請"
-	case "CANCEL":
-		typeTitle = "取消課程"
-	}
+	t := s.template(models.NotificationTemplateExceptionSubmit)
+	vars := s.ExceptionVariables(exception, teacherName, centerName, exception.Reason)
 
 	return map[string]interface{}{
 		"type": "bubble",
@@ -251,42 +295,32 @@ func (s *LineBotTemplateServiceImpl) GetExceptionSubmitTemplate(exception *model
 			"type":   "box",
 			"layout": "vertical",
 			"contents": []interface{}{
-				map[string]interface{}{
+				withColor(map[string]interface{}{
 					"type":   "text",
-					"text":   "🔔 新的" + typeTitle,
+					"text":   t.Text("title", vars),
 					"weight": "bold",
 					"size":   "lg",
-				},
-				map[string]interface{}{
-					"type":  "text",
-					"text":  "━━━━━━━━━━━━━━",
-					"size":  "xs",
-					"color": "#CCCCCC",
-				},
+				}, t.Color("title")),
+				s.divider(t),
 				map[string]interface{}{
 					"type": "text",
-					"text": fmt.Sprintf("👤 申請人：%s 老師", teacherName),
+					"text": t.Text("applicant", vars),
 					"size": "md",
 				},
 				map[string]interface{}{
 					"type": "text",
-					"text": fmt.Sprintf("📅 日期：%s", exception.GetDate().Format("2006/01/02 (Mon)")),
+					"text": t.Text("date", vars),
 					"size": "md",
 				},
 				map[string]interface{}{
 					"type": "text",
-					"text": fmt.Sprintf("🕐 時間：%s", exception.GetTimeRange()),
+					"text": t.Text("time", vars),
 					"size": "md",
 				},
-				map[string]interface{}{
-					"type":  "text",
-					"text":  "━━━━━━━━━━━━━━",
-					"size":  "xs",
-					"color": "#CCCCCC",
-				},
+				s.divider(t),
 				map[string]interface{}{
 					"type": "text",
-					"text": fmt.Sprintf("📝 原因：%s", exception.Reason),
+					"text": t.Text("reason", vars),
 					"size": "sm",
 					"wrap": true,
 				},
@@ -296,16 +330,16 @@ func (s *LineBotTemplateServiceImpl) GetExceptionSubmitTemplate(exception *model
 			"type":   "box",
 			"layout": "horizontal",
 			"contents": []interface{}{
-				map[string]interface{}{
+				withColor(map[string]interface{}{
 					"type":   "button",
 					"style":  "primary",
 					"height": "sm",
 					"action": map[string]interface{}{
 						"type":  "uri",
-						"label": "前往處理",
+						"label": t.Text("button", vars),
 						"uri":   adminURL,
 					},
-				},
+				}, t.Color("button")),
 			},
 		},
 	}
@@ -315,18 +349,8 @@ func (s *LineBotTemplateServiceImpl) GetExceptionSubmitTemplate(exception *model
 func (s *LineBotTemplateServiceImpl) GetExceptionApproveTemplate(exception *models.ScheduleException, teacherName string) interface{} {
 	teacherURL := fmt.Sprintf("%s/teacher/exceptions/%d", s.baseURL, exception.ID)
 
-	// 根據類型顯示不同標題
-	typeTitle := "例外申請"
-	switch exception.ExceptionType {
-	case "LEAVE":
-		typeTitle = "請假申請"
-	case "RESCHEDULE":
-		typeTitle = "調課申請"
-	case "SWAP":
-		typeTitle = "代課申請"
-	case "CANCEL":
-		typeTitle = "取消課程"
-	}
+	t := s.template(models.NotificationTemplateExceptionApproved)
+	vars := s.ExceptionVariables(exception, teacherName, "", "")
 
 	return map[string]interface{}{
 		"type": "bubble",
@@ -334,27 +358,21 @@ func (s *LineBotTemplateServiceImpl) GetExceptionApproveTemplate(exception *mode
 			"type":   "box",
 			"layout": "vertical",
 			"contents": []interface{}{
-				map[string]interface{}{
+				withColor(map[string]interface{}{
 					"type":   "text",
-					"text":   "✅ " + typeTitle + "已核准",
+					"text":   t.Text("title", vars),
 					"weight": "bold",
 					"size":   "lg",
-					"color":  "#4CAF50",
-				},
-				map[string]interface{}{
-					"type":  "text",
-					"text":  "━━━━━━━━━━━━━━",
-					"size":  "xs",
-					"color": "#CCCCCC",
-				},
+				}, t.Color("title")),
+				s.divider(t),
 				map[string]interface{}{
 					"type": "text",
-					"text": fmt.Sprintf("📅 日期：%s", exception.GetDate().Format("2006/01/02 (Mon)")),
+					"text": t.Text("date", vars),
 					"size": "md",
 				},
 				map[string]interface{}{
 					"type": "text",
-					"text": fmt.Sprintf("🕐 時間：%s", exception.GetTimeRange()),
+					"text": t.Text("time", vars),
 					"size": "md",
 				},
 			},
@@ -363,16 +381,16 @@ func (s *LineBotTemplateServiceImpl) GetExceptionApproveTemplate(exception *mode
 			"type":   "box",
 			"layout": "horizontal",
 			"contents": []interface{}{
-				map[string]interface{}{
+				withColor(map[string]interface{}{
 					"type":   "button",
 					"style":  "primary",
 					"height": "sm",
 					"action": map[string]interface{}{
 						"type":  "uri",
-						"label": "查看詳情",
+						"label": t.Text("button", vars),
 						"uri":   teacherURL,
 					},
-				},
+				}, t.Color("button")),
 			},
 		},
 	}
@@ -382,23 +400,8 @@ func (s *LineBotTemplateServiceImpl) GetExceptionApproveTemplate(exception *mode
 func (s *LineBotTemplateServiceImpl) GetExceptionRejectTemplate(exception *models.ScheduleException, teacherName string, reason string) interface{} {
 	teacherURL := fmt.Sprintf("%s/teacher/exceptions/%d", s.baseURL, exception.ID)
 
-	// 根據類型顯示不同標題
-	typeTitle := "例外申請"
-	switch exception.ExceptionType {
-	case "LEAVE":
-		typeTitle = "請假申請"
-	case "RESCHEDULE":
-		typeTitle = "調課申請"
-	case "SWAP":
-		typeTitle = "代課申請"
-	case "CANCEL":
-		typeTitle = "取消課程"
-	}
-
-	rejectReason := reason
-	if rejectReason == "" {
-		rejectReason = "未說明原因"
-	}
+	t := s.template(models.NotificationTemplateExceptionRejected)
+	vars := s.ExceptionVariables(exception, teacherName, "", reason)
 
 	return map[string]interface{}{
 		"type": "bubble",
@@ -406,38 +409,27 @@ func (s *LineBotTemplateServiceImpl) GetExceptionRejectTemplate(exception *model
 			"type":   "box",
 			"layout": "vertical",
 			"contents": []interface{}{
-				map[string]interface{}{
+				withColor(map[string]interface{}{
 					"type":   "text",
-					"text":   "❌ " + typeTitle + "已拒絕",
+					"text":   t.Text("title", vars),
 					"weight": "bold",
 					"size":   "lg",
-					"color":  "#F44336",
-				},
-				map[string]interface{}{
-					"type":  "text",
-					"text":  "━━━━━━━━━━━━━━",
-					"size":  "xs",
-					"color": "#CCCCCC",
-				},
+				}, t.Color("title")),
+				s.divider(t),
 				map[string]interface{}{
 					"type": "text",
-					"text": fmt.Sprintf("📅 日期：%s", exception.GetDate().Format("2006/01/02 (Mon)")),
+					"text": t.Text("date", vars),
 					"size": "md",
 				},
 				map[string]interface{}{
 					"type": "text",
-					"text": fmt.Sprintf("🕐 時間：%s", exception.GetTimeRange()),
+					"text": t.Text("time", vars),
 					"size": "md",
 				},
-				map[string]interface{}{
-					"type":  "text",
-					"text":  "━━━━━━━━━━━━━━",
-					"size":  "xs",
-					"color": "#CCCCCC",
-				},
+				s.divider(t),
 				map[string]interface{}{
 					"type": "text",
-					"text": fmt.Sprintf("📝 拒絕原因：%s", rejectReason),
+					"text": t.Text("reason", vars),
 					"size": "sm",
 					"wrap": true,
 				},
@@ -447,16 +439,16 @@ func (s *LineBotTemplateServiceImpl) GetExceptionRejectTemplate(exception *model
 			"type":   "box",
 			"layout": "horizontal",
 			"contents": []interface{}{
-				map[string]interface{}{
+				withColor(map[string]interface{}{
 					"type":   "button",
 					"style":  "secondary",
 					"height": "sm",
 					"action": map[string]interface{}{
 						"type":  "uri",
-						"label": "查看詳情",
+						"label": t.Text("button", vars),
 						"uri":   teacherURL,
 					},
-				},
+				}, t.Color("button")),
 			},
 		},
 	}
@@ -466,13 +458,11 @@ func (s *LineBotTemplateServiceImpl) GetExceptionRejectTemplate(exception *model
 func (s *LineBotTemplateServiceImpl) GetInvitationAcceptedTemplate(teacher *models.Teacher, centerName string, role string) interface{} {
 	adminURL := fmt.Sprintf("%s/admin/teachers", s.baseURL)
 
-	// 角色顯示文字
-	roleText := "老師"
-	switch role {
-	case "SUBSTITUTE":
-		roleText = "代課老師"
-	case "TEACHER":
-		roleText = "正職老師"
+	t := s.template(models.NotificationTemplateInvitationAccepted)
+	vars := map[string]string{
+		"teacher_name": teacher.Name,
+		"center_name":  centerName,
+		"role":         notificationLabel(s.locale, "role."+role),
 	}
 
 	return map[string]interface{}{
@@ -481,86 +471,78 @@ func (s *LineBotTemplateServiceImpl) GetInvitationAcceptedTemplate(teacher *mode
 			"type":   "box",
 			"layout": "vertical",
 			"contents": []interface{}{
-				map[string]interface{}{
+				withColor(map[string]interface{}{
 					"type":   "text",
-					"text":   "🎉 新成員加入！",
+					"text":   t.Text("title", vars),
 					"weight": "bold",
 					"size":   "lg",
-					"color":  "#4CAF50",
-				},
-				map[string]interface{}{
-					"type":  "text",
-					"text":  "━━━━━━━━━━━━━━",
-					"size":  "xs",
-					"color": "#CCCCCC",
-				},
+				}, t.Color("title")),
+				s.divider(t),
 				map[string]interface{}{
 					"type":   "text",
-					"text":   fmt.Sprintf("👤 新成員：%s", teacher.Name),
+					"text":   t.Text("member", vars),
 					"size":   "md",
 					"weight": "bold",
 				},
 				map[string]interface{}{
 					"type": "text",
-					"text": fmt.Sprintf("🏢 中心：%s", centerName),
+					"text": t.Text("center", vars),
 					"size": "md",
 				},
 				map[string]interface{}{
 					"type": "text",
-					"text": fmt.Sprintf("📋 角色：%s", roleText),
+					"text": t.Text("role", vars),
 					"size": "md",
 				},
-				map[string]interface{}{
-					"type":  "text",
-					"text":  "━━━━━━━━━━━━━━",
-					"size":  "xs",
-					"color": "#CCCCCC",
-				},
-				map[string]interface{}{
-					"type":  "text",
-					"text":  "✅ 歡迎新老師加入！",
-					"size":  "sm",
-					"color": "#666666",
-				},
+				s.divider(t),
+				withColor(map[string]interface{}{
+					"type": "text",
+					"text": t.Text("closing", vars),
+					"size": "sm",
+				}, t.Color("closing")),
 			},
 		},
 		"footer": map[string]interface{}{
 			"type":   "box",
 			"layout": "horizontal",
 			"contents": []interface{}{
-				map[string]interface{}{
+				withColor(map[string]interface{}{
 					"type":   "button",
 					"style":  "primary",
 					"height": "sm",
 					"action": map[string]interface{}{
 						"type":  "uri",
-						"label": "查看成員",
+						"label": t.Text("button", vars),
 						"uri":   adminURL,
 					},
-				},
+				}, t.Color("button")),
 			},
 		},
 	}
 }
 
+// divider 文字分隔線
+func (s *LineBotTemplateServiceImpl) divider(t *ResolvedNotificationTemplate) map[string]interface{} {
+	return withColor(map[string]interface{}{
+		"type": "text",
+		"text": "━━━━━━━━━━━━━━",
+		"size": "xs",
+	}, t.Color("divider"))
+}
+
 // GenerateAgendaFlex 行程聚合 Flex Message 範本
 // 支援顯示多筆行程列表，中心課程使用藍色系，個人行程使用紫色系
 func (s *LineBotTemplateServiceImpl) GenerateAgendaFlex(agendaItems []AgendaItem, targetDate time.Time, userName string) interface{} {
 	// 格式化日期
 	dateStr := targetDate.Format("2006年1月2日")
-	weekdayStr := targetDate.Format("Mon")
-	weekdayMap := map[string]string{
-		"Monday":    "週一",
-		"Tuesday":   "週二",
-		"Wednesday": "週三",
-		"Thursday":  "週四",
-		"Friday":   "週五",
-		"Saturday":  "週六",
-		"Sunday":    "週日",
-	}
-	weekdayTW := weekdayMap[weekdayStr]
-	if weekdayTW == "" {
-		weekdayTW = weekdayStr
+	weekdayTW := agendaWeekday(targetDate)
+
+	// 非今天的查詢（例如「下週三」）不顯示「今日」
+	now := time.Now().In(targetDate.Location())
+	isToday := targetDate.Year() == now.Year() && targetDate.YearDay() == now.YearDay()
+	headerTitle, emptyText := fmt.Sprintf("👋 %s 的今日行程", userName), "🎉 今天沒有行程"
+	if !isToday {
+		headerTitle, emptyText = fmt.Sprintf("👋 %s 的行程", userName), "🎉 這天沒有行程"
 	}
 
 	// 構建行程列表內容
@@ -579,7 +561,7 @@ func (s *LineBotTemplateServiceImpl) GenerateAgendaFlex(agendaItems []AgendaItem
 	if len(agendaItems) == 0 {
 		agendaContents = append(agendaContents, map[string]interface{}{
 			"type":   "text",
-			"text":   "🎉 今天沒有行程",
+			"text":   emptyText,
 			"size":   "md",
 			"color":  "#666666",
 			"align":  "center",
@@ -688,7 +670,7 @@ func (s *LineBotTemplateServiceImpl) GenerateAgendaFlex(agendaItems []AgendaItem
 				// 用戶歡迎標題
 				map[string]interface{}{
 					"type": "text",
-					"text": fmt.Sprintf("👋 %s 的今日行程", userName),
+					"text": headerTitle,
 					"weight": "bold",
 					"size":   "xl",
 					"align":  "center",
@@ -740,82 +722,255 @@ func (s *LineBotTemplateServiceImpl) GenerateAgendaFlex(agendaItems []AgendaItem
 	}
 }
 
-// GetBroadcastTemplate 廣播訊息 Flex Message 範本
-func (s *LineBotTemplateServiceImpl) GetBroadcastTemplate(centerName string, title string, message string, warning string, actionLabel string, actionURL string) interface{} {
-	// 構建內容列表
+// agendaSummaryItemsPerDay 摘要中每日最多列出的行程數，其餘以「另 N 項」表示
+const agendaSummaryItemsPerDay = 3
+
+// agendaWeekday 取得星期幾的中文名稱
+func agendaWeekday(date time.Time) string {
+	weekdays := []string{"週日", "週一", "週二", "週三", "週四", "週五", "週六"}
+	return weekdays[date.Weekday()]
+}
+
+// GenerateAgendaSummaryFlex 多日行程摘要 Flex Message
+// 每週（週一至週日）一張卡片，超過一週時以輪播呈現
+func (s *LineBotTemplateServiceImpl) GenerateAgendaSummaryFlex(days []AgendaDay, title string, userName string) interface{} {
+	var bubbles []interface{}
+	for start := 0; start < len(days); {
+		end := start + 1
+		for end < len(days) && days[end].Date.Weekday() != time.Monday {
+			end++
+		}
+		bubbles = append(bubbles, s.agendaSummaryBubble(days[start:end], title, userName))
+		start = end
+	}
+
+	if len(bubbles) == 1 {
+		return bubbles[0]
+	}
+	return map[string]interface{}{
+		"type":     "carousel",
+		"contents": bubbles,
+	}
+}
+
+// agendaSummaryBubble 單週行程摘要卡片
+func (s *LineBotTemplateServiceImpl) agendaSummaryBubble(days []AgendaDay, title string, userName string) map[string]interface{} {
+	centerCount, personalCount := CountAgendaItems(days)
+
+	rangeText := days[0].Date.Format("1/2")
+	if len(days) > 1 {
+		rangeText = fmt.Sprintf("%s - %s", rangeText, days[len(days)-1].Date.Format("1/2"))
+	}
+
+	summary := fmt.Sprintf("📊 共 %d 堂課", centerCount)
+	if personalCount > 0 {
+		summary += fmt.Sprintf("・%d 項個人行程", personalCount)
+	}
+
 	contents := []interface{}{
-		// 標題
 		map[string]interface{}{
 			"type":   "text",
-			"text":   title,
+			"text":   fmt.Sprintf("📅 %s", title),
 			"weight": "bold",
 			"size":   "lg",
+			"wrap":   true,
 		},
-		// 分隔線
 		map[string]interface{}{
 			"type":  "text",
-			"text":  "━━━━━━━━━━━━━━━━",
-			"size":  "xs",
-			"color": "#CCCCCC",
+			"text":  fmt.Sprintf("👤 %s・%s", userName, rangeText),
+			"size":  "sm",
+			"color": "#888888",
+		},
+		map[string]interface{}{
+			"type":   "text",
+			"text":   summary,
+			"size":   "sm",
+			"weight": "bold",
+			"color":  "#1E88E5",
+			"margin": "sm",
+		},
+		map[string]interface{}{
+			"type":   "separator",
+			"margin": "md",
 		},
 	}
 
-	// 添加中心名稱
-	contents = append(contents, map[string]interface{}{
-		"type":  "text",
-		"text":  fmt.Sprintf("🏢 來自：%s", centerName),
-		"size":  "md",
-		"color": "#666666",
-	})
+	today := time.Now().In(days[0].Date.Location()).Format("2006-01-02")
+	for _, day := range days {
+		dateColor := "#333333"
+		if day.Date.Format("2006-01-02") == today {
+			dateColor = "#1E88E5"
+		}
 
-	// 添加訊息內容
-	contents = append(contents, map[string]interface{}{
-		"type":  "text",
-		"text":  "━━━━━━━━━━━━━━━━",
-		"size":  "xs",
-		"color": "#CCCCCC",
-	})
-	contents = append(contents, map[string]interface{}{
-		"type":  "text",
-		"text":  message,
-		"size":  "md",
-		"wrap":  true,
-		"margin": "md",
-	})
+		var lines []interface{}
+		for i, item := range day.Items {
+			if i == agendaSummaryItemsPerDay {
+				lines = append(lines, map[string]interface{}{
+					"type":  "text",
+					"text":  fmt.Sprintf("…另 %d 項", len(day.Items)-agendaSummaryItemsPerDay),
+					"size":  "xs",
+					"color": "#999999",
+				})
+				break
+			}
+			icon := "🏢"
+			if item.SourceType != AgendaSourceTypeCenter {
+				icon = "📌"
+			}
+			lines = append(lines, map[string]interface{}{
+				"type":  "text",
+				"text":  fmt.Sprintf("%s %s %s", item.Time, icon, item.Title),
+				"size":  "sm",
+				"color": "#555555",
+				"wrap":  true,
+			})
+		}
+		if len(lines) == 0 {
+			lines = append(lines, map[string]interface{}{
+				"type":  "text",
+				"text":  "—",
+				"size":  "sm",
+				"color": "#CCCCCC",
+			})
+		}
+
+		contents = append(contents, map[string]interface{}{
+			"type":   "box",
+			"layout": "horizontal",
+			"margin": "md",
+			"contents": []interface{}{
+				map[string]interface{}{
+					"type":   "text",
+					"text":   fmt.Sprintf("%s %s", day.Date.Format("1/2"), agendaWeekday(day.Date)),
+					"size":   "sm",
+					"weight": "bold",
+					"color":  dateColor,
+					"flex":   0,
+				},
+				map[string]interface{}{
+					"type":     "box",
+					"layout":   "vertical",
+					"flex":     1,
+					"margin":   "md",
+					"contents": lines,
+				},
+			},
+		})
+	}
+
+	return map[string]interface{}{
+		"type": "bubble",
+		"body": map[string]interface{}{
+			"type":     "box",
+			"layout":   "vertical",
+			"contents": contents,
+		},
+		"footer": map[string]interface{}{
+			"type":   "box",
+			"layout": "vertical",
+			"contents": []interface{}{
+				map[string]interface{}{
+					"type":   "button",
+					"style":  "primary",
+					"height": "sm",
+					"action": map[string]interface{}{
+						"type":  "uri",
+						"label": "📱 查看完整課表",
+						"uri":   s.baseURL,
+					},
+				},
+			},
+		},
+	}
+}
+
+// CountAgendaItems 統計中心課程與個人行程數量
+func CountAgendaItems(days []AgendaDay) (centerCount int, personalCount int) {
+	for _, day := range days {
+		for _, item := range day.Items {
+			if item.SourceType == AgendaSourceTypeCenter {
+				centerCount++
+			} else {
+				personalCount++
+			}
+		}
+	}
+	return centerCount, personalCount
+}
+
+// GetBroadcastTemplate 廣播訊息 Flex Message 範本
+func (s *LineBotTemplateServiceImpl) GetBroadcastTemplate(centerName string, title string, message string, warning string, actionLabel string, actionURL string) interface{} {
+	t := s.template(models.NotificationTemplateBroadcast)
+	vars := map[string]string{
+		"center_name": centerName,
+		"title":       title,
+		"warning":     warning,
+	}
+	divider := withColor(map[string]interface{}{
+		"type": "text",
+		"text": "━━━━━━━━━━━━━━━━",
+		"size": "xs",
+	}, t.Color("divider"))
+
+	// 構建內容列表：標題、來源中心與訊息內容
+	contents := []interface{}{
+		map[string]interface{}{
+			"type":   "text",
+			"text":   title,
+			"weight": "bold",
+			"size":   "lg",
+		},
+		divider,
+		withColor(map[string]interface{}{
+			"type": "text",
+			"text": t.Text("from", vars),
+			"size": "md",
+		}, t.Color("from")),
+		divider,
+		map[string]interface{}{
+			"type":   "text",
+			"text":   message,
+			"size":   "md",
+			"wrap":   true,
+			"margin": "md",
+		},
+	}
 
 	// 如果有警告訊息，添加警告區塊
 	if warning != "" {
-		contents = append(contents, []interface{}{
-			map[string]interface{}{
-				"type":  "separator",
-				"margin": "md",
+		warningBox := map[string]interface{}{
+			"type":         "box",
+			"layout":       "vertical",
+			"margin":       "md",
+			"paddingAll":   "12px",
+			"cornerRadius": "8px",
+			"contents": []interface{}{
+				withColor(map[string]interface{}{
+					"type": "text",
+					"text": t.Text("warning", vars),
+					"size": "sm",
+					"wrap": true,
+				}, t.Color("warning")),
 			},
+		}
+		if bg := t.Color("warning_background"); bg != "" {
+			warningBox["backgroundColor"] = bg
+		}
+		contents = append(contents,
 			map[string]interface{}{
-				"type":   "box",
-				"layout": "vertical",
+				"type":   "separator",
 				"margin": "md",
-				"paddingAll": "12px",
-				"backgroundColor": "#FFF8E1",
-				"cornerRadius": "8px",
-				"contents": []interface{}{
-					map[string]interface{}{
-						"type":  "text",
-						"text":  fmt.Sprintf("⚠️ %s", warning),
-						"size":  "sm",
-						"color": "#F57C00",
-						"wrap":  true,
-					},
-				},
 			},
-		}...)
+			warningBox,
+		)
 	}
 
 	// 構建 Flex Message
 	flexMessage := map[string]interface{}{
 		"type": "bubble",
 		"body": map[string]interface{}{
-			"type":   "box",
-			"layout": "vertical",
+			"type":     "box",
+			"layout":   "vertical",
 			"contents": contents,
 		},
 	}
@@ -826,7 +981,7 @@ func (s *LineBotTemplateServiceImpl) GetBroadcastTemplate(centerName string, tit
 			"type":   "box",
 			"layout": "vertical",
 			"contents": []interface{}{
-				map[string]interface{}{
+				withColor(map[string]interface{}{
 					"type":   "button",
 					"style":  "primary",
 					"height": "sm",
@@ -835,7 +990,7 @@ func (s *LineBotTemplateServiceImpl) GetBroadcastTemplate(centerName string, tit
 						"label": actionLabel,
 						"uri":   actionURL,
 					},
-				},
+				}, t.Color("button")),
 			},
 		}
 	}
@@ -0,0 +1,582 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+	"timeLedger/app"
+	"timeLedger/app/models"
+	"timeLedger/app/repositories"
+	"timeLedger/global/errInfos"
+	"timeLedger/libs"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+const (
+	// lineGroupLinkCodeTTL 群組驗證碼有效時間
+	lineGroupLinkCodeTTL = 10 * time.Minute
+	// LineGroupClosureLookaheadDays 強制停課假日落在今日起幾天內視為緊急停課
+	LineGroupClosureLookaheadDays = 3
+	// lineGroupAgendaMaxSessions 群組課表最多列出的場次，其餘以「另 N 堂」表示
+	lineGroupAgendaMaxSessions = 40
+	// LineGroupLinkMaxFailures 同一群組在 lineGroupLinkFailureWindow 內允許的驗證碼錯誤次數
+	LineGroupLinkMaxFailures = 5
+	// lineGroupLinkFailureWindow 驗證碼錯誤次數的計算區間
+	lineGroupLinkFailureWindow = 30 * time.Minute
+)
+
+// LineGroupLinkCodeKey 群組驗證碼的 Redis key
+func LineGroupLinkCodeKey(code string) string {
+	return fmt.Sprintf("%s:line:group_code:%s", CacheKeyPrefix, strings.ToUpper(code))
+}
+
+// LineGroupLinkFailuresKey 群組驗證碼錯誤次數的 Redis key
+func LineGroupLinkFailuresKey(groupID string) string {
+	return fmt.Sprintf("%s:line:group_code_failures:%s", CacheKeyPrefix, groupID)
+}
+
+// LineGroupLinkCode 後台產生的群組驗證碼
+type LineGroupLinkCode struct {
+	Code      string    `json:"code"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type lineGroupLinkCodeValue struct {
+	CenterID uint `json:"center_id"`
+	AdminID  uint `json:"admin_id"`
+}
+
+// LineGroupSettingsRequest 更新群組通知設定，未帶的欄位維持不變
+type LineGroupSettingsRequest struct {
+	NotifyExceptionSubmit  *bool   `json:"notify_exception_submit"`
+	NotifyDailySummary     *bool   `json:"notify_daily_summary"`
+	NotifyEmergencyClosure *bool   `json:"notify_emergency_closure"`
+	DailySummaryTime       *string `json:"daily_summary_time"`
+}
+
+// LineGroupService 中心工作人員 LINE 群組：連結、通知推播與群組內的唯讀查詢
+type LineGroupService struct {
+	BaseService
+	client         *http.Client
+	apiBaseURL     string
+	channelToken   string
+	lineBotService LineBotService
+	templates      *NotificationTemplateService
+	expansionSvc   ScheduleExpansionService
+	groupRepo      *repositories.LineGroupRepository
+	centerRepo     *repositories.CenterRepository
+	ruleRepo       *repositories.ScheduleRuleRepository
+	exceptionRepo  *repositories.ScheduleExceptionRepository
+	holidayRepo    *repositories.CenterHolidayRepository
+	redis          *redis.Client
+}
+
+// NewLineGroupService 建立 LINE 群組服務
+func NewLineGroupService(app *app.App) *LineGroupService {
+	svc := &LineGroupService{
+		BaseService: *NewBaseService(app, "LineGroupService"),
+		client:      &http.Client{Timeout: 10 * time.Second},
+		apiBaseURL:  "https://api.line.me",
+	}
+	if app.Env != nil {
+		svc.channelToken = app.Env.LineChannelAccessToken
+		if app.Env.LineAPIBaseURL != "" {
+			svc.apiBaseURL = strings.TrimRight(app.Env.LineAPIBaseURL, "/")
+		}
+	}
+	if app.Redis != nil {
+		svc.redis = app.Redis.DB0
+	}
+	if app.MySQL != nil {
+		svc.lineBotService = NewLineBotService(app)
+		svc.templates = NewNotificationTemplateService(app)
+		svc.expansionSvc = NewScheduleExpansionService(app)
+		svc.groupRepo = repositories.NewLineGroupRepository(app)
+		svc.centerRepo = repositories.NewCenterRepository(app)
+		svc.ruleRepo = repositories.NewScheduleRuleRepository(app)
+		svc.exceptionRepo = repositories.NewScheduleExceptionRepository(app)
+		svc.holidayRepo = repositories.NewCenterHolidayRepository(app)
+	}
+	return svc
+}
+
+// GenerateLinkCode 產生群組驗證碼，管理員將機器人加入群組後於群組內貼上即完成連結
+func (s *LineGroupService) GenerateLinkCode(ctx context.Context, centerID, adminID uint) (*LineGroupLinkCode, *errInfos.Res, error) {
+	if s.redis == nil {
+		return nil, s.App.Err.New(errInfos.SYSTEM_ERROR), errors.New("redis is not configured")
+	}
+
+	value, _ := json.Marshal(lineGroupLinkCodeValue{CenterID: centerID, AdminID: adminID})
+	for attempt := 0; attempt < 5; attempt++ {
+		code := GenerateBindingCode()
+		ok, err := s.redis.SetNX(ctx, LineGroupLinkCodeKey(code), value, lineGroupLinkCodeTTL).Result()
+		if err != nil {
+			return nil, s.App.Err.New(errInfos.SYSTEM_ERROR), err
+		}
+		if ok {
+			return &LineGroupLinkCode{Code: code, ExpiresAt: time.Now().Add(lineGroupLinkCodeTTL)}, nil, nil
+		}
+	}
+	return nil, s.App.Err.New(errInfos.SYSTEM_ERROR), errors.New("failed to allocate group link code")
+}
+
+// LinkGroup 以驗證碼將群組連結至中心；驗證碼僅能使用一次，同一中心重複連結時回傳既有紀錄
+func (s *LineGroupService) LinkGroup(ctx context.Context, groupID, code string) (*models.LineGroup, *errInfos.Res, error) {
+	if s.redis == nil {
+		return nil, s.App.Err.New(errInfos.SYSTEM_ERROR), errors.New("redis is not configured")
+	}
+
+	// 錯誤次數過多時暫停嘗試，避免未連結的群組猜測驗證碼
+	failures, err := s.redis.Get(ctx, LineGroupLinkFailuresKey(groupID)).Int()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, s.App.Err.New(errInfos.SYSTEM_ERROR), err
+	}
+	if failures >= LineGroupLinkMaxFailures {
+		return nil, s.App.Err.New(errInfos.RATE_LIMIT_EXCEEDED), fmt.Errorf("too many failed link attempts for group %s", groupID)
+	}
+
+	raw, err := s.redis.GetDel(ctx, LineGroupLinkCodeKey(code)).Result()
+	if errors.Is(err, redis.Nil) {
+		s.recordLinkFailure(ctx, groupID)
+		return nil, s.App.Err.New(errInfos.LINE_BINDING_CODE_INVALID), errors.New("group link code not found")
+	}
+	if err != nil {
+		return nil, s.App.Err.New(errInfos.SYSTEM_ERROR), err
+	}
+	var value lineGroupLinkCodeValue
+	if err := json.Unmarshal([]byte(raw), &value); err != nil {
+		return nil, s.App.Err.New(errInfos.LINE_BINDING_CODE_INVALID), err
+	}
+	s.redis.Del(ctx, LineGroupLinkFailuresKey(groupID))
+
+	existing, err := s.groupRepo.GetByGroupID(ctx, groupID)
+	if err == nil {
+		if existing.CenterID != value.CenterID {
+			return nil, s.App.Err.New(errInfos.LINE_GROUP_LINKED_ELSEWHERE), fmt.Errorf("group already linked to center %d", existing.CenterID)
+		}
+		return &existing, nil, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, s.App.Err.New(errInfos.SQL_ERROR), err
+	}
+
+	now := time.Now()
+	group, err := s.groupRepo.Create(ctx, models.LineGroup{
+		CenterID:               value.CenterID,
+		GroupID:                groupID,
+		Name:                   s.fetchGroupName(ctx, groupID),
+		NotifyExceptionSubmit:  true,
+		NotifyDailySummary:     true,
+		NotifyEmergencyClosure: true,
+		DailySummaryTime:       "07:30",
+		LinkedByAdminID:        value.AdminID,
+		LinkedAt:               now,
+		CreatedAt:              now,
+		UpdatedAt:              now,
+	})
+	if err != nil {
+		return nil, s.App.Err.New(errInfos.SQL_ERROR), err
+	}
+	return &group, nil, nil
+}
+
+// recordLinkFailure 累計群組驗證碼錯誤次數，計算區間自第一次錯誤起算
+func (s *LineGroupService) recordLinkFailure(ctx context.Context, groupID string) {
+	key := LineGroupLinkFailuresKey(groupID)
+	count, err := s.redis.Incr(ctx, key).Result()
+	if err != nil {
+		s.Logger.Warn("failed to record group link failure", "group_id", groupID, "error", err)
+		return
+	}
+	if count == 1 {
+		s.redis.Expire(ctx, key, lineGroupLinkFailureWindow)
+	}
+}
+
+// FindLinkedGroup 取得群組連結，未連結時回傳 nil
+func (s *LineGroupService) FindLinkedGroup(ctx context.Context, groupID string) (*models.LineGroup, error) {
+	group, err := s.groupRepo.GetByGroupID(ctx, groupID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+// ListGroups 取得中心連結的群組
+func (s *LineGroupService) ListGroups(ctx context.Context, centerID uint) ([]models.LineGroup, *errInfos.Res, error) {
+	groups, err := s.groupRepo.ListByCenterID(ctx, centerID)
+	if err != nil {
+		return nil, s.App.Err.New(errInfos.SQL_ERROR), err
+	}
+	return groups, nil, nil
+}
+
+func (s *LineGroupService) getGroup(ctx context.Context, centerID, id uint) (*models.LineGroup, *errInfos.Res, error) {
+	group, err := s.groupRepo.GetByIDWithCenterScope(ctx, id, centerID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, s.App.Err.New(errInfos.LINE_GROUP_NOT_FOUND), err
+	}
+	if err != nil {
+		return nil, s.App.Err.New(errInfos.SQL_ERROR), err
+	}
+	return &group, nil, nil
+}
+
+// UpdateGroup 更新群組接收的通知類型與每日摘要時間
+func (s *LineGroupService) UpdateGroup(ctx context.Context, centerID, id uint, req *LineGroupSettingsRequest) (*models.LineGroup, *errInfos.Res, error) {
+	if req.DailySummaryTime != nil {
+		if _, err := time.Parse("15:04", *req.DailySummaryTime); err != nil {
+			return nil, s.App.Err.New(errInfos.PARAMS_VALIDATE_ERROR), fmt.Errorf("daily_summary_time 格式需為 HH:MM")
+		}
+	}
+
+	group, errInfo, err := s.getGroup(ctx, centerID, id)
+	if err != nil {
+		return nil, errInfo, err
+	}
+
+	if req.NotifyExceptionSubmit != nil {
+		group.NotifyExceptionSubmit = *req.NotifyExceptionSubmit
+	}
+	if req.NotifyDailySummary != nil {
+		group.NotifyDailySummary = *req.NotifyDailySummary
+	}
+	if req.NotifyEmergencyClosure != nil {
+		group.NotifyEmergencyClosure = *req.NotifyEmergencyClosure
+	}
+	if req.DailySummaryTime != nil {
+		group.DailySummaryTime = *req.DailySummaryTime
+	}
+	group.UpdatedAt = time.Now()
+
+	if err := s.groupRepo.UpdateFields(ctx, group.ID, map[string]interface{}{
+		"notify_exception_submit":  group.NotifyExceptionSubmit,
+		"notify_daily_summary":     group.NotifyDailySummary,
+		"notify_emergency_closure": group.NotifyEmergencyClosure,
+		"daily_summary_time":       group.DailySummaryTime,
+		"updated_at":               group.UpdatedAt,
+	}); err != nil {
+		return nil, s.App.Err.New(errInfos.SQL_ERROR), err
+	}
+	return group, nil, nil
+}
+
+// UnlinkGroup 解除群組連結並讓機器人離開群組
+func (s *LineGroupService) UnlinkGroup(ctx context.Context, centerID, id uint) (*errInfos.Res, error) {
+	group, errInfo, err := s.getGroup(ctx, centerID, id)
+	if err != nil {
+		return errInfo, err
+	}
+	if _, err := s.groupRepo.DeleteWhere(ctx, "id = ?", group.ID); err != nil {
+		return s.App.Err.New(errInfos.SQL_ERROR), err
+	}
+
+	endpoint := fmt.Sprintf("%s/v2/bot/group/%s/leave", s.apiBaseURL, url.PathEscape(group.GroupID))
+	if _, err := doLineAPIRequest(ctx, s.client, s.channelToken, http.MethodPost, endpoint, "", nil); ignoreLineNotFound(err) != nil {
+		s.Logger.Warn("failed to leave LINE group", "group_id", group.GroupID, "error", err)
+	}
+	return nil, nil
+}
+
+// HandleBotRemoved 機器人被移出群組時移除連結
+func (s *LineGroupService) HandleBotRemoved(ctx context.Context, groupID string) error {
+	_, err := s.groupRepo.DeleteWhere(ctx, "group_id = ?", groupID)
+	return err
+}
+
+// fetchGroupName 取得群組名稱，失敗時回傳空字串
+func (s *LineGroupService) fetchGroupName(ctx context.Context, groupID string) string {
+	endpoint := fmt.Sprintf("%s/v2/bot/group/%s/summary", s.apiBaseURL, url.PathEscape(groupID))
+	body, err := doLineAPIRequest(ctx, s.client, s.channelToken, http.MethodGet, endpoint, "", nil)
+	if err != nil {
+		s.Logger.Warn("failed to get LINE group summary", "group_id", groupID, "error", err)
+		return ""
+	}
+	var summary struct {
+		GroupName string `json:"groupName"`
+	}
+	_ = json.Unmarshal(body, &summary)
+	return summary.GroupName
+}
+
+// PushToCenter 推播至中心接收該通知類型的所有群組，回傳成功推播的群組數
+// 單一群組失敗不影響其他群組，最後回傳第一個錯誤；once 以 group:<群組>:<key> 依群組去重
+func (s *LineGroupService) PushToCenter(ctx context.Context, centerID uint, notifyType, key string, message interface{}, once DeliveryOnce) (int, error) {
+	groups, err := s.groupRepo.ListByCenterID(ctx, centerID)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	var firstErr error
+	for _, group := range groups {
+		if !group.Notifies(notifyType) {
+			continue
+		}
+		if err := once.Do(ctx, fmt.Sprintf("group:%s:%s", group.GroupID, key), func() error {
+			return s.lineBotService.PushMessage(ctx, group.GroupID, message)
+		}); err != nil {
+			s.Logger.Warn("failed to push to LINE group", "group_id", group.GroupID, "notify_type", notifyType, "error", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		sent++
+	}
+	return sent, firstErr
+}
+
+// NotifyExceptionSubmitted 將新的例外申請推播至中心群組（與管理員個人通知使用相同範本）
+func (s *LineGroupService) NotifyExceptionSubmitted(ctx context.Context, exception *models.ScheduleException, teacherName, centerName string, once DeliveryOnce) error {
+	templates := s.templates.LineTemplates(ctx, exception.CenterID)
+	altText := templates.GetText(models.NotificationTemplateExceptionSubmit, "alt_text",
+		templates.ExceptionVariables(exception, teacherName, centerName, exception.Reason))
+	message := map[string]interface{}{
+		"type":     "flex",
+		"altText":  altText,
+		"contents": templates.GetExceptionSubmitTemplate(exception, teacherName, centerName),
+	}
+	_, err := s.PushToCenter(ctx, exception.CenterID, models.LineGroupNotifyExceptionSubmit, models.LineGroupNotifyExceptionSubmit, message, once)
+	return err
+}
+
+// NotifyHolidaysCreated 新增的假日中，近日內的強制停課視為緊急停課並推播至中心群組
+// 單一假日失敗時繼續處理其餘假日，最後回傳合併的錯誤
+func (s *LineGroupService) NotifyHolidaysCreated(ctx context.Context, centerID uint, holidayIDs []uint, once DeliveryOnce) error {
+	if len(holidayIDs) == 0 {
+		return nil
+	}
+	holidays, err := s.holidayRepo.Find(ctx, "id IN ? AND center_id = ?", holidayIDs, centerID)
+	if err != nil {
+		return err
+	}
+
+	now := libs.NowInTaiwan()
+	var closures []models.CenterHoliday
+	for _, holiday := range holidays {
+		if IsEmergencyClosure(holiday, now) {
+			closures = append(closures, holiday)
+		}
+	}
+	if len(closures) == 0 {
+		return nil
+	}
+	sort.Slice(closures, func(i, j int) bool { return closures[i].Date.Before(closures[j].Date) })
+
+	centerName := s.centerName(ctx, centerID)
+	var errs []error
+	for _, holiday := range closures {
+		date := lineGroupDate(holiday.Date)
+		sessions, err := s.centerSessions(ctx, centerID, date)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to load sessions for holiday %d: %w", holiday.ID, err))
+			continue
+		}
+		message := map[string]interface{}{
+			"type": "text",
+			"text": BuildLineGroupClosureText(centerName, holiday, len(sessions)),
+		}
+		key := fmt.Sprintf("%s:%d", models.LineGroupNotifyEmergencyClosure, holiday.ID)
+		if _, err := s.PushToCenter(ctx, centerID, models.LineGroupNotifyEmergencyClosure, key, message, once); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// IsEmergencyClosure 強制停課且日期落在今日起 LineGroupClosureLookaheadDays 天內
+func IsEmergencyClosure(holiday models.CenterHoliday, now time.Time) bool {
+	if !holiday.ForceCancel {
+		return false
+	}
+	today := lineGroupDate(now)
+	date := lineGroupDate(holiday.Date)
+	return !date.Before(today) && date.Before(today.AddDate(0, 0, LineGroupClosureLookaheadDays))
+}
+
+// AgendaText 群組內查詢的中心課表文字
+func (s *LineGroupService) AgendaText(ctx context.Context, centerID uint, date time.Time) (string, error) {
+	date = lineGroupDate(date)
+	sessions, err := s.centerSessions(ctx, centerID, date)
+	if err != nil {
+		return "", err
+	}
+
+	title := "課表"
+	today := lineGroupDate(libs.NowInTaiwan())
+	switch {
+	case date.Equal(today):
+		title = "今日課表"
+	case date.Equal(today.AddDate(0, 0, 1)):
+		title = "明日課表"
+	}
+	return BuildLineGroupAgendaText(fmt.Sprintf("📋 %s %s", s.centerName(ctx, centerID), title), date, sessions), nil
+}
+
+// SendDueDailySummaries 發送已到摘要時間且今日尚未發送的每日課表摘要，回傳發送的群組數
+func (s *LineGroupService) SendDueDailySummaries(ctx context.Context, now time.Time) (int, error) {
+	if s.groupRepo == nil {
+		return 0, nil
+	}
+	now = now.In(libs.GetTaiwanLocation())
+	today := now.Format("2006-01-02")
+
+	groups, err := s.groupRepo.ListDailySummaryPending(ctx, today)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	texts := map[uint]string{}
+	for _, group := range groups {
+		if !LineGroupSummaryDue(group, now) {
+			continue
+		}
+		claimed, err := s.groupRepo.ClaimDailySummary(ctx, group.ID, today)
+		if err != nil {
+			return sent, err
+		}
+		if !claimed {
+			continue
+		}
+
+		text, ok := texts[group.CenterID]
+		if !ok {
+			text, err = s.dailySummaryText(ctx, group.CenterID, now)
+			if err != nil {
+				s.Logger.Error("failed to build daily summary", "center_id", group.CenterID, "error", err)
+				continue
+			}
+			texts[group.CenterID] = text
+		}
+
+		if err := s.lineBotService.PushMessage(ctx, group.GroupID, map[string]interface{}{"type": "text", "text": text}); err != nil {
+			s.Logger.Warn("failed to push daily summary", "group_id", group.GroupID, "error", err)
+			continue
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+// LineGroupSummaryDue 台灣時間已過群組設定的摘要時間且今日尚未發送
+func LineGroupSummaryDue(group models.LineGroup, now time.Time) bool {
+	now = now.In(libs.GetTaiwanLocation())
+	if !group.NotifyDailySummary || group.LastSummaryDate == now.Format("2006-01-02") {
+		return false
+	}
+	summaryTime := group.DailySummaryTime
+	if summaryTime == "" {
+		summaryTime = "07:30"
+	}
+	return now.Format("15:04") >= summaryTime
+}
+
+func (s *LineGroupService) dailySummaryText(ctx context.Context, centerID uint, now time.Time) (string, error) {
+	date := lineGroupDate(now)
+	sessions, err := s.centerSessions(ctx, centerID, date)
+	if err != nil {
+		return "", err
+	}
+	pending, err := s.exceptionRepo.Count(ctx, "center_id = ? AND status = ?", centerID, "PENDING")
+	if err != nil {
+		return "", err
+	}
+
+	text := BuildLineGroupAgendaText(fmt.Sprintf("☀️ %s 今日課表摘要", s.centerName(ctx, centerID)), date, sessions)
+	if pending > 0 {
+		text += fmt.Sprintf("\n\n⏳ 待審核例外申請：%d 件", pending)
+	}
+	return text, nil
+}
+
+// centerSessions 展開中心指定日期的所有場次，依開始時間排序
+func (s *LineGroupService) centerSessions(ctx context.Context, centerID uint, date time.Time) ([]ExpandedSchedule, error) {
+	rules, err := s.ruleRepo.ListByCenterID(ctx, centerID)
+	if err != nil {
+		return nil, err
+	}
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	sessions := s.expansionSvc.ExpandRules(ctx, rules, date, date, centerID)
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return compareTimeStrings(sessions[i].StartTime, sessions[j].StartTime) < 0
+	})
+	return sessions, nil
+}
+
+func (s *LineGroupService) centerName(ctx context.Context, centerID uint) string {
+	center, err := s.centerRepo.GetByID(ctx, centerID)
+	if err != nil || center.Name == "" {
+		return "中心"
+	}
+	return center.Name
+}
+
+// lineGroupDate 台灣時間的當日 00:00
+func lineGroupDate(t time.Time) time.Time {
+	t = t.In(libs.GetTaiwanLocation())
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// BuildLineGroupAgendaText 中心課表文字，每場次一行：時間、課程、老師、教室
+func BuildLineGroupAgendaText(title string, date time.Time, sessions []ExpandedSchedule) string {
+	var b strings.Builder
+	b.WriteString(title)
+	b.WriteString(fmt.Sprintf("\n📅 %s（%s）", date.Format("2006/01/02"), agendaWeekday(date)))
+
+	if len(sessions) == 0 {
+		b.WriteString("\n\n🎉 今天沒有課程")
+		return b.String()
+	}
+
+	b.WriteString(fmt.Sprintf("\n共 %d 堂課\n", len(sessions)))
+	for i, session := range sessions {
+		if i == lineGroupAgendaMaxSessions {
+			b.WriteString(fmt.Sprintf("\n…另 %d 堂", len(sessions)-i))
+			break
+		}
+		parts := []string{fmt.Sprintf("%s-%s %s", session.StartTime, session.EndTime, session.OfferingName)}
+		if session.TeacherName != "" {
+			parts = append(parts, session.TeacherName)
+		}
+		if session.RoomName != "" {
+			parts = append(parts, session.RoomName)
+		}
+		line := strings.Join(parts, "｜")
+		switch {
+		case session.IsHoliday:
+			line += "（停課）"
+		case session.ExceptionInfo != nil && session.ExceptionInfo.Status == "PENDING":
+			line += "（異動審核中）"
+		}
+		b.WriteString("\n" + line)
+	}
+	return b.String()
+}
+
+// BuildLineGroupClosureText 緊急停課通知文字
+func BuildLineGroupClosureText(centerName string, holiday models.CenterHoliday, sessionCount int) string {
+	date := lineGroupDate(holiday.Date)
+	text := fmt.Sprintf("🚨 緊急停課通知\n%s\n\n📅 %s（%s）\n📝 %s",
+		centerName, date.Format("2006/01/02"), agendaWeekday(date), holiday.Name)
+	if sessionCount > 0 {
+		text += fmt.Sprintf("\n\n當日 %d 堂課程全數停課，請協助通知學員。", sessionCount)
+	} else {
+		text += "\n\n當日無排定課程。"
+	}
+	return text
+}
@@ -0,0 +1,472 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"timeLedger/app"
+	"timeLedger/libs"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	lineLeaveConversationTTL = 10 * time.Minute // 對話逾時，期滿需重新開始
+	lineLeaveLookaheadDays   = 7                // 可選擇的場次範圍（含今天）
+	lineLeaveMaxSessions     = 10               // Quick Reply 最多 13 個按鈕，保留取消按鈕
+	lineLeaveReasonMaxLength = 200
+	lineQuickReplyLabelMax   = 20 // LINE Quick Reply 按鈕文字上限
+)
+
+// 請假對話步驟
+const (
+	LineLeaveStepSelectSession = "SELECT_SESSION"
+	LineLeaveStepSelectType    = "SELECT_TYPE"
+	LineLeaveStepEnterReason   = "ENTER_REASON"
+	LineLeaveStepConfirm       = "CONFIRM"
+)
+
+// 請假對話的 Postback 動作
+const (
+	LineLeavePostbackSession = "leave_session"
+	LineLeavePostbackType    = "leave_type"
+	LineLeavePostbackConfirm = "leave_confirm"
+	LineLeavePostbackCancel  = "leave_cancel"
+)
+
+// LineLeaveTypeOption 可透過 LINE 申請的例外類型
+type LineLeaveTypeOption struct {
+	Type  string
+	Label string
+}
+
+// LineLeaveTypes 改期需指定新時段，仍需至 LIFF 申請
+var LineLeaveTypes = []LineLeaveTypeOption{
+	{Type: "CANCEL", Label: "停課"},
+	{Type: "REPLACE_TEACHER", Label: "找代課"},
+}
+
+// LineLeaveSession 可申請請假的場次
+type LineLeaveSession struct {
+	CenterID   uint   `json:"center_id"`
+	CenterName string `json:"center_name"`
+	RuleID     uint   `json:"rule_id"`
+	Title      string `json:"title"`
+	Date       string `json:"date"` // 2006-01-02
+	Time       string `json:"time"` // 15:04
+}
+
+// Label 場次顯示文字，例如「3/2(週一) 10:00 瑜珈」
+func (s LineLeaveSession) Label() string {
+	date, err := time.Parse("2006-01-02", s.Date)
+	if err != nil {
+		return fmt.Sprintf("%s %s %s", s.Date, s.Time, s.Title)
+	}
+	return fmt.Sprintf("%d/%d(%s) %s %s", date.Month(), date.Day(), agendaWeekday(date), s.Time, s.Title)
+}
+
+// LineLeaveConversation 存於 Redis 的對話狀態
+type LineLeaveConversation struct {
+	Step      string             `json:"step"`
+	TeacherID uint               `json:"teacher_id"`
+	Sessions  []LineLeaveSession `json:"sessions"`
+	Selected  *LineLeaveSession  `json:"selected,omitempty"`
+	Type      string             `json:"type,omitempty"`
+	Reason    string             `json:"reason,omitempty"`
+}
+
+// LineLeaveConversationService 透過 LINE Bot 以對話方式提出請假申請
+// 流程：選擇場次 → 選擇類型 → 輸入原因 → 確認送出
+type LineLeaveConversationService struct {
+	BaseService
+	app            *app.App
+	lineBotService LineBotService
+	exceptionSvc   ScheduleExceptionService
+}
+
+// NewLineLeaveConversationService 建立 LINE 請假對話服務
+func NewLineLeaveConversationService(app *app.App) *LineLeaveConversationService {
+	return &LineLeaveConversationService{
+		BaseService:    *NewBaseService(app, "LineLeaveConversationService"),
+		app:            app,
+		lineBotService: NewLineBotService(app),
+		exceptionSvc:   NewScheduleExceptionService(app),
+	}
+}
+
+// LineLeaveConversationKey 對話狀態的 Redis key
+func LineLeaveConversationKey(lineUserID string) string {
+	return fmt.Sprintf("%s:line:leave:%s", CacheKeyPrefix, lineUserID)
+}
+
+// Start 開始請假對話，回傳近期可申請的場次
+func (s *LineLeaveConversationService) Start(ctx context.Context, lineUserID string) (interface{}, error) {
+	if s.app.Redis == nil {
+		return lineTextMessage("⚠️ 目前無法使用 LINE 請假，請改由 LIFF 頁面申請。"), nil
+	}
+
+	identity, err := s.lineBotService.GetCombinedIdentity(lineUserID)
+	if err != nil {
+		return nil, err
+	}
+	if identity.TeacherProfile == nil {
+		return lineTextMessage("🔒 LINE 請假僅限已綁定的老師使用。\n\n輸入「綁定」開始綁定流程。"), nil
+	}
+
+	now := libs.NowInTaiwan()
+	days, err := s.lineBotService.GetAggregatedAgendaRange(lineUserID, now, now.AddDate(0, 0, lineLeaveLookaheadDays-1))
+	if err != nil {
+		return nil, err
+	}
+	var items []AgendaItem
+	for _, day := range days {
+		items = append(items, day.Items...)
+	}
+
+	sessions := SelectLineLeaveSessions(items, now, lineLeaveMaxSessions)
+	if len(sessions) == 0 {
+		s.clear(ctx, lineUserID)
+		return lineTextMessage(fmt.Sprintf("📅 近 %d 天沒有可申請請假的課程。", lineLeaveLookaheadDays)), nil
+	}
+
+	conv := &LineLeaveConversation{
+		Step:      LineLeaveStepSelectSession,
+		TeacherID: identity.TeacherProfile.ID,
+		Sessions:  sessions,
+	}
+	if err := s.save(ctx, lineUserID, conv); err != nil {
+		return nil, err
+	}
+	return BuildLineLeavePrompt(conv), nil
+}
+
+// HandlePostback 處理請假對話的 Postback，非請假動作時 handled 為 false
+func (s *LineLeaveConversationService) HandlePostback(ctx context.Context, lineUserID, data string) (message interface{}, handled bool, err error) {
+	values, err := url.ParseQuery(data)
+	if err != nil || !strings.HasPrefix(values.Get("action"), "leave_") {
+		return nil, false, nil
+	}
+	if s.app.Redis == nil {
+		return lineTextMessage("⚠️ 目前無法使用 LINE 請假，請改由 LIFF 頁面申請。"), true, nil
+	}
+
+	action := values.Get("action")
+	if action == LineLeavePostbackCancel {
+		s.clear(ctx, lineUserID)
+		return lineTextMessage("👌 已取消請假申請。"), true, nil
+	}
+
+	conv, err := s.load(ctx, lineUserID)
+	if err != nil {
+		return nil, true, err
+	}
+	if conv == nil {
+		return lineTextMessage("⌛ 請假對話已逾時，請重新輸入「請假」。"), true, nil
+	}
+
+	switch {
+	case action == LineLeavePostbackSession && conv.Step == LineLeaveStepSelectSession:
+		index, err := strconv.Atoi(values.Get("index"))
+		if err != nil || index < 0 || index >= len(conv.Sessions) {
+			return BuildLineLeavePrompt(conv), true, nil
+		}
+		session := conv.Sessions[index]
+
+		date, err := libs.ParseDateInTaiwan(session.Date)
+		if err != nil {
+			return BuildLineLeavePrompt(conv), true, nil
+		}
+		allowed, errInfo, err := s.exceptionSvc.CheckExceptionDeadline(ctx, session.CenterID, session.RuleID, date)
+		if err != nil {
+			return nil, true, err
+		}
+		if !allowed {
+			msg := "已超過異動截止日"
+			if errInfo != nil {
+				msg = errInfo.Msg
+			}
+			return withLineLeaveQuickReply(lineTextMessage(fmt.Sprintf("⛔ %s\n%s\n\n請選擇其他場次，或聯繫中心處理。", session.Label(), msg)),
+				lineLeaveSessionItems(conv.Sessions)), true, nil
+		}
+
+		conv.Selected = &session
+		conv.Step = LineLeaveStepSelectType
+
+	case action == LineLeavePostbackType && conv.Step == LineLeaveStepSelectType:
+		leaveType := values.Get("type")
+		if _, ok := findLineLeaveType(leaveType); !ok {
+			return BuildLineLeavePrompt(conv), true, nil
+		}
+		conv.Type = leaveType
+		conv.Step = LineLeaveStepEnterReason
+
+	case action == LineLeavePostbackConfirm && conv.Step == LineLeaveStepConfirm:
+		message, err := s.submit(ctx, lineUserID, conv)
+		return message, true, err
+
+	default:
+		// 點擊舊訊息的按鈕時，重新提示目前步驟
+		return BuildLineLeavePrompt(conv), true, nil
+	}
+
+	if err := s.save(ctx, lineUserID, conv); err != nil {
+		return nil, true, err
+	}
+	return BuildLineLeavePrompt(conv), true, nil
+}
+
+// HandleText 處理對話中的文字輸入（原因、取消），無進行中的對話或非輸入步驟時 handled 為 false
+func (s *LineLeaveConversationService) HandleText(ctx context.Context, lineUserID, text string) (message interface{}, handled bool, err error) {
+	if s.app.Redis == nil {
+		return nil, false, nil
+	}
+
+	conv, err := s.load(ctx, lineUserID)
+	if err != nil || conv == nil {
+		return nil, false, err
+	}
+
+	text = strings.TrimSpace(text)
+	switch text {
+	case "取消", "cancel", "Cancel":
+		s.clear(ctx, lineUserID)
+		return lineTextMessage("👌 已取消請假申請。"), true, nil
+	}
+
+	if conv.Step != LineLeaveStepEnterReason {
+		return nil, false, nil
+	}
+
+	if text == "" || len([]rune(text)) > lineLeaveReasonMaxLength {
+		return withLineLeaveQuickReply(lineTextMessage(fmt.Sprintf("✏️ 原因需為 1～%d 字，請重新輸入。", lineLeaveReasonMaxLength)), nil), true, nil
+	}
+
+	conv.Reason = text
+	conv.Step = LineLeaveStepConfirm
+	if err := s.save(ctx, lineUserID, conv); err != nil {
+		return nil, true, err
+	}
+	return BuildLineLeavePrompt(conv), true, nil
+}
+
+// submit 建立例外申請並結束對話；先以 GETDEL 取走對話，重複點擊「確認」只會送出一次
+func (s *LineLeaveConversationService) submit(ctx context.Context, lineUserID string, conv *LineLeaveConversation) (interface{}, error) {
+	if conv.Selected == nil {
+		return BuildLineLeavePrompt(conv), nil
+	}
+
+	conv, err := s.claim(ctx, lineUserID)
+	if err != nil {
+		return nil, err
+	}
+	if conv == nil || conv.Step != LineLeaveStepConfirm || conv.Selected == nil {
+		return lineTextMessage("⏳ 此申請已在處理中，請勿重複確認。"), nil
+	}
+	session := conv.Selected
+
+	date, err := libs.ParseDateInTaiwan(session.Date)
+	if err != nil {
+		return nil, err
+	}
+
+	exception, errInfo, err := s.exceptionSvc.CreateException(ctx, session.CenterID, conv.TeacherID, session.RuleID, &CreateExceptionRequest{
+		RuleID:       session.RuleID,
+		OriginalDate: date,
+		Type:         conv.Type,
+		Reason:       conv.Reason,
+	})
+	if err != nil {
+		s.Logger.Error("failed to create exception from line conversation", "line_user_id", lineUserID, "rule_id", session.RuleID, "error", err)
+		// 系統錯誤時還原對話，讓老師可以再按一次確認
+		if saveErr := s.save(ctx, lineUserID, conv); saveErr != nil {
+			s.Logger.Warn("failed to restore line leave conversation", "line_user_id", lineUserID, "error", saveErr)
+		}
+		return lineTextMessage("❌ 送出失敗，請稍後再試或改由 LIFF 頁面申請。"), nil
+	}
+
+	if errInfo != nil {
+		return lineTextMessage(fmt.Sprintf("⛔ 無法送出申請：%s\n\n%s", errInfo.Msg, session.Label())), nil
+	}
+
+	typeOption, _ := findLineLeaveType(conv.Type)
+	return lineTextMessage(fmt.Sprintf("✅ 已送出申請（編號 #%d）\n\n📚 %s\n🏢 %s\n📋 類型：%s\n📝 原因：%s\n⏳ 狀態：%s\n\n中心審核後會再通知您。",
+		exception.ID, session.Label(), session.CenterName, typeOption.Label, conv.Reason, lineLeaveStatusLabel(exception.Status))), nil
+}
+
+func (s *LineLeaveConversationService) load(ctx context.Context, lineUserID string) (*LineLeaveConversation, error) {
+	raw, err := s.app.Redis.DB0.Get(ctx, LineLeaveConversationKey(lineUserID)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var conv LineLeaveConversation
+	if err := json.Unmarshal(raw, &conv); err != nil {
+		// 格式錯誤的狀態視為逾時
+		s.clear(ctx, lineUserID)
+		return nil, nil
+	}
+	return &conv, nil
+}
+
+// claim 以 GETDEL 取走對話狀態，同時間只有一個事件能取得；已被取走時回傳 nil
+func (s *LineLeaveConversationService) claim(ctx context.Context, lineUserID string) (*LineLeaveConversation, error) {
+	raw, err := s.app.Redis.DB0.GetDel(ctx, LineLeaveConversationKey(lineUserID)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var conv LineLeaveConversation
+	if err := json.Unmarshal(raw, &conv); err != nil {
+		return nil, nil
+	}
+	return &conv, nil
+}
+
+// save 儲存狀態並重新計算逾時
+func (s *LineLeaveConversationService) save(ctx context.Context, lineUserID string, conv *LineLeaveConversation) error {
+	payload, err := json.Marshal(conv)
+	if err != nil {
+		return err
+	}
+	return s.app.Redis.DB0.Set(ctx, LineLeaveConversationKey(lineUserID), payload, lineLeaveConversationTTL).Err()
+}
+
+func (s *LineLeaveConversationService) clear(ctx context.Context, lineUserID string) {
+	if err := s.app.Redis.DB0.Del(ctx, LineLeaveConversationKey(lineUserID)).Err(); err != nil {
+		s.Logger.Warn("failed to clear line leave conversation", "line_user_id", lineUserID, "error", err)
+	}
+}
+
+// SelectLineLeaveSessions 從行程中挑出尚未開始、未有例外申請的中心課程
+func SelectLineLeaveSessions(items []AgendaItem, now time.Time, limit int) []LineLeaveSession {
+	var sessions []LineLeaveSession
+	for _, item := range items {
+		if item.SourceType != AgendaSourceTypeCenter || item.RuleID == 0 || item.HasException {
+			continue
+		}
+		startAt, err := time.ParseInLocation("2006-01-02 15:04", item.Date+" "+item.Time, now.Location())
+		if err != nil || !startAt.After(now) {
+			continue
+		}
+
+		sessions = append(sessions, LineLeaveSession{
+			CenterID:   item.CenterID,
+			CenterName: item.SourceName,
+			RuleID:     item.RuleID,
+			Title:      item.Title,
+			Date:       item.Date,
+			Time:       item.Time,
+		})
+		if len(sessions) >= limit {
+			break
+		}
+	}
+	return sessions
+}
+
+// BuildLineLeavePrompt 依目前步驟產生提示訊息
+func BuildLineLeavePrompt(conv *LineLeaveConversation) map[string]interface{} {
+	switch conv.Step {
+	case LineLeaveStepSelectType:
+		items := make([]interface{}, 0, len(LineLeaveTypes))
+		for _, option := range LineLeaveTypes {
+			items = append(items, linePostbackQuickReply(option.Label,
+				fmt.Sprintf("action=%s&type=%s", LineLeavePostbackType, option.Type), option.Label))
+		}
+		return withLineLeaveQuickReply(lineTextMessage(fmt.Sprintf("📚 %s\n🏢 %s\n\n請選擇申請類型：\n• 停課：該堂課暫停\n• 找代課：由中心安排代課老師",
+			conv.Selected.Label(), conv.Selected.CenterName)), items)
+
+	case LineLeaveStepEnterReason:
+		return withLineLeaveQuickReply(lineTextMessage("✏️ 請輸入請假原因："), nil)
+
+	case LineLeaveStepConfirm:
+		typeOption, _ := findLineLeaveType(conv.Type)
+		items := []interface{}{
+			linePostbackQuickReply("確認送出", "action="+LineLeavePostbackConfirm, "確認送出"),
+		}
+		return withLineLeaveQuickReply(lineTextMessage(fmt.Sprintf("📋 請確認申請內容：\n\n📚 %s\n🏢 %s\n📋 類型：%s\n📝 原因：%s",
+			conv.Selected.Label(), conv.Selected.CenterName, typeOption.Label, conv.Reason)), items)
+
+	default:
+		var sb strings.Builder
+		sb.WriteString("🙋 請選擇要請假的課程：\n")
+		for i, session := range conv.Sessions {
+			sb.WriteString(fmt.Sprintf("\n%d. %s（%s）", i+1, session.Label(), session.CenterName))
+		}
+		return withLineLeaveQuickReply(lineTextMessage(sb.String()), lineLeaveSessionItems(conv.Sessions))
+	}
+}
+
+// lineLeaveSessionItems 場次選擇按鈕
+func lineLeaveSessionItems(sessions []LineLeaveSession) []interface{} {
+	items := make([]interface{}, 0, len(sessions))
+	for i, session := range sessions {
+		label := fmt.Sprintf("%d. %s", i+1, session.Label())
+		items = append(items, linePostbackQuickReply(label,
+			fmt.Sprintf("action=%s&index=%d", LineLeavePostbackSession, i), label))
+	}
+	return items
+}
+
+// withLineLeaveQuickReply 加上 Quick Reply 按鈕，並固定附上取消按鈕
+func withLineLeaveQuickReply(message map[string]interface{}, items []interface{}) map[string]interface{} {
+	items = append(items, linePostbackQuickReply("取消", "action="+LineLeavePostbackCancel, "取消"))
+	message["quickReply"] = map[string]interface{}{"items": items}
+	return message
+}
+
+func linePostbackQuickReply(label, data, displayText string) map[string]interface{} {
+	runes := []rune(label)
+	if len(runes) > lineQuickReplyLabelMax {
+		label = string(runes[:lineQuickReplyLabelMax-1]) + "…"
+	}
+	return map[string]interface{}{
+		"type": "action",
+		"action": map[string]interface{}{
+			"type":        "postback",
+			"label":       label,
+			"data":        data,
+			"displayText": displayText,
+		},
+	}
+}
+
+func lineTextMessage(text string) map[string]interface{} {
+	return map[string]interface{}{
+		"type": "text",
+		"text": text,
+	}
+}
+
+func findLineLeaveType(leaveType string) (LineLeaveTypeOption, bool) {
+	for _, option := range LineLeaveTypes {
+		if option.Type == leaveType {
+			return option, true
+		}
+	}
+	return LineLeaveTypeOption{}, false
+}
+
+func lineLeaveStatusLabel(status string) string {
+	switch status {
+	case "PENDING":
+		return "待審核"
+	case "APPROVED", "APPROVE":
+		return "已核准"
+	case "REJECTED", "REJECT":
+		return "已拒絕"
+	default:
+		return status
+	}
+}
@@ -0,0 +1,558 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"timeLedger/app"
+	"timeLedger/app/models"
+	"timeLedger/app/repositories"
+	"timeLedger/configs"
+	"timeLedger/libs"
+
+	"gorm.io/gorm"
+)
+
+// Rich Menu 角色：未綁定使用者與老師，管理員沿用 AdminUser.Role
+const (
+	LineRichMenuRoleGuest   = "GUEST"
+	LineRichMenuRoleTeacher = "TEACHER"
+)
+
+// lineRichMenuBulkLinkLimit LINE 批次連結每次最多 500 位使用者
+const lineRichMenuBulkLinkLimit = 500
+
+// lineRichMenuBackground 產生圖片時未被區塊覆蓋的底色
+var lineRichMenuBackground = color.RGBA{R: 0xEE, G: 0xEE, B: 0xEE, A: 0xFF}
+
+// LineRichMenuClient LINE Messaging API 的 Rich Menu 端點
+type LineRichMenuClient struct {
+	baseURL     string
+	dataBaseURL string
+	token       string
+	client      *http.Client
+}
+
+// NewLineRichMenuClient 建立 Rich Menu client，baseURL / dataBaseURL 為空時使用 LINE 正式環境
+func NewLineRichMenuClient(baseURL, dataBaseURL, token string) *LineRichMenuClient {
+	if baseURL == "" {
+		baseURL = "https://api.line.me"
+	}
+	if dataBaseURL == "" {
+		dataBaseURL = "https://api-data.line.me"
+	}
+	return &LineRichMenuClient{
+		baseURL:     strings.TrimRight(baseURL, "/"),
+		dataBaseURL: strings.TrimRight(dataBaseURL, "/"),
+		token:       token,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// LineRichMenuArea 送至 LINE 的點擊區塊
+type LineRichMenuArea struct {
+	Bounds configs.LineRichMenuBounds `json:"bounds"`
+	Action configs.LineRichMenuAction `json:"action"`
+}
+
+// LineRichMenuRequest 建立 Rich Menu 的請求內容
+type LineRichMenuRequest struct {
+	Size        configs.LineRichMenuSize `json:"size"`
+	Selected    bool                     `json:"selected"`
+	Name        string                   `json:"name"`
+	ChatBarText string                   `json:"chatBarText"`
+	Areas       []LineRichMenuArea       `json:"areas"`
+}
+
+// CreateRichMenu 建立選單，回傳 LINE 的 richMenuId
+func (c *LineRichMenuClient) CreateRichMenu(ctx context.Context, menu LineRichMenuRequest) (string, error) {
+	body, err := json.Marshal(menu)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal rich menu: %w", err)
+	}
+
+	respBody, err := c.do(ctx, http.MethodPost, c.baseURL+"/v2/bot/richmenu", "application/json", body)
+	if err != nil {
+		return "", err
+	}
+
+	var resp struct {
+		RichMenuID string `json:"richMenuId"`
+	}
+	if err := json.Unmarshal(respBody, &resp); err != nil || resp.RichMenuID == "" {
+		return "", fmt.Errorf("invalid create rich menu response: %s", string(respBody))
+	}
+	return resp.RichMenuID, nil
+}
+
+// UploadImage 上傳選單圖片（image/png 或 image/jpeg）
+func (c *LineRichMenuClient) UploadImage(ctx context.Context, richMenuID, contentType string, data []byte) error {
+	endpoint := fmt.Sprintf("%s/v2/bot/richmenu/%s/content", c.dataBaseURL, url.PathEscape(richMenuID))
+	_, err := c.do(ctx, http.MethodPost, endpoint, contentType, data)
+	return err
+}
+
+// DeleteRichMenu 刪除選單，選單已不存在時視為成功
+func (c *LineRichMenuClient) DeleteRichMenu(ctx context.Context, richMenuID string) error {
+	endpoint := fmt.Sprintf("%s/v2/bot/richmenu/%s", c.baseURL, url.PathEscape(richMenuID))
+	_, err := c.do(ctx, http.MethodDelete, endpoint, "", nil)
+	return ignoreLineNotFound(err)
+}
+
+// SetDefaultRichMenu 設定頻道預設選單
+func (c *LineRichMenuClient) SetDefaultRichMenu(ctx context.Context, richMenuID string) error {
+	endpoint := fmt.Sprintf("%s/v2/bot/user/all/richmenu/%s", c.baseURL, url.PathEscape(richMenuID))
+	_, err := c.do(ctx, http.MethodPost, endpoint, "", nil)
+	return err
+}
+
+// LinkUser 將選單連結至單一使用者
+func (c *LineRichMenuClient) LinkUser(ctx context.Context, lineUserID, richMenuID string) error {
+	endpoint := fmt.Sprintf("%s/v2/bot/user/%s/richmenu/%s", c.baseURL, url.PathEscape(lineUserID), url.PathEscape(richMenuID))
+	_, err := c.do(ctx, http.MethodPost, endpoint, "", nil)
+	return err
+}
+
+// UnlinkUser 解除使用者的個別選單，改顯示預設選單
+func (c *LineRichMenuClient) UnlinkUser(ctx context.Context, lineUserID string) error {
+	endpoint := fmt.Sprintf("%s/v2/bot/user/%s/richmenu", c.baseURL, url.PathEscape(lineUserID))
+	_, err := c.do(ctx, http.MethodDelete, endpoint, "", nil)
+	return ignoreLineNotFound(err)
+}
+
+// BulkLink 批次連結選單，超過 500 位時分批送出
+func (c *LineRichMenuClient) BulkLink(ctx context.Context, richMenuID string, lineUserIDs []string) error {
+	return c.bulk(ctx, "/v2/bot/richmenu/bulk/link", map[string]interface{}{"richMenuId": richMenuID}, lineUserIDs)
+}
+
+// BulkUnlink 批次解除個別選單，改顯示預設選單，超過 500 位時分批送出
+func (c *LineRichMenuClient) BulkUnlink(ctx context.Context, lineUserIDs []string) error {
+	return c.bulk(ctx, "/v2/bot/richmenu/bulk/unlink", map[string]interface{}{}, lineUserIDs)
+}
+
+// bulk 依 lineRichMenuBulkLinkLimit 分批送出批次請求
+func (c *LineRichMenuClient) bulk(ctx context.Context, path string, fields map[string]interface{}, lineUserIDs []string) error {
+	for start := 0; start < len(lineUserIDs); start += lineRichMenuBulkLinkLimit {
+		end := start + lineRichMenuBulkLinkLimit
+		if end > len(lineUserIDs) {
+			end = len(lineUserIDs)
+		}
+
+		fields["userIds"] = lineUserIDs[start:end]
+		body, err := json.Marshal(fields)
+		if err != nil {
+			return fmt.Errorf("failed to marshal bulk request: %w", err)
+		}
+		if _, err := c.do(ctx, http.MethodPost, c.baseURL+path, "application/json", body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// do 發送請求，非 2xx 時回傳 *LineAPIError
+func (c *LineRichMenuClient) do(ctx context.Context, method, endpoint, contentType string, body []byte) ([]byte, error) {
+	return doLineAPIRequest(ctx, c.client, c.token, method, endpoint, contentType, body)
+}
+
+func ignoreLineNotFound(err error) error {
+	var apiErr *LineAPIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
+
+// BuildLineRichMenuRequest 將設定轉為 LINE 請求內容，uri 動作的變數於此代入
+func BuildLineRichMenuRequest(menu configs.LineRichMenuDefinition, vars map[string]string) LineRichMenuRequest {
+	req := LineRichMenuRequest{
+		Size:        menu.Size,
+		Selected:    menu.Selected,
+		Name:        menu.Name,
+		ChatBarText: menu.ChatBarText,
+		Areas:       make([]LineRichMenuArea, 0, len(menu.Areas)),
+	}
+	for _, area := range menu.Areas {
+		action := area.Action
+		if action.URI != "" {
+			action.URI = libs.Interpolate(action.URI, vars)
+		}
+		req.Areas = append(req.Areas, LineRichMenuArea{Bounds: area.Bounds, Action: action})
+	}
+	return req
+}
+
+// LoadLineRichMenuImage 讀取選單圖片，未設定 Image 時產生圖片
+func LoadLineRichMenuImage(menu configs.LineRichMenuDefinition) ([]byte, string, error) {
+	if menu.Image == "" {
+		data, err := RenderLineRichMenuImage(menu)
+		return data, "image/png", err
+	}
+
+	data, err := os.ReadFile(menu.Image)
+	if err != nil {
+		return nil, "", fmt.Errorf("讀取 rich menu %s 圖片失敗: %w", menu.Key, err)
+	}
+	switch strings.ToLower(filepath.Ext(menu.Image)) {
+	case ".jpg", ".jpeg":
+		return data, "image/jpeg", nil
+	default:
+		return data, "image/png", nil
+	}
+}
+
+// RenderLineRichMenuImage 依各區塊的 Color 產生純色 PNG，作為未提供設計圖時的預設圖片
+func RenderLineRichMenuImage(menu configs.LineRichMenuDefinition) ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, menu.Size.Width, menu.Size.Height))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: lineRichMenuBackground}, image.Point{}, draw.Src)
+
+	for _, area := range menu.Areas {
+		if area.Color == "" {
+			continue
+		}
+		fill, err := parseLineRichMenuColor(area.Color)
+		if err != nil {
+			return nil, err
+		}
+		b := area.Bounds
+		rect := image.Rect(b.X, b.Y, b.X+b.Width, b.Y+b.Height)
+		draw.Draw(img, rect, &image.Uniform{C: fill}, image.Point{}, draw.Src)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode rich menu image: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func parseLineRichMenuColor(hexColor string) (color.RGBA, error) {
+	value, err := strconv.ParseUint(strings.TrimPrefix(hexColor, "#"), 16, 32)
+	if err != nil {
+		return color.RGBA{}, fmt.Errorf("invalid rich menu color: %s", hexColor)
+	}
+	return color.RGBA{R: uint8(value >> 16), G: uint8(value >> 8), B: uint8(value), A: 0xFF}, nil
+}
+
+// LineRichMenuConfigHash 設定內容與圖片的雜湊，用於判斷是否需要重新上傳
+func LineRichMenuConfigHash(req LineRichMenuRequest, image []byte) string {
+	body, _ := json.Marshal(req)
+	hash := sha256.New()
+	hash.Write(body)
+	hash.Write(image)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// LineRichMenuService 依角色管理 LINE Rich Menu
+type LineRichMenuService struct {
+	BaseService
+	client      *LineRichMenuClient
+	config      *configs.LineRichMenuConfig
+	menuRepo    *repositories.LineRichMenuRepository
+	teacherRepo *repositories.TeacherRepository
+	adminRepo   *repositories.AdminUserRepository
+}
+
+// NewLineRichMenuService 建立 Rich Menu 服務，未啟用或設定錯誤時所有操作皆為 no-op
+func NewLineRichMenuService(app *app.App) *LineRichMenuService {
+	svc := &LineRichMenuService{
+		BaseService: *NewBaseService(app, "LineRichMenuService"),
+	}
+	if app.Env == nil || !app.Env.LineRichMenuEnabled || app.Env.LineChannelAccessToken == "" {
+		return svc
+	}
+
+	config, err := configs.LoadLineRichMenuConfig(app.Env.LineRichMenuConfigPath)
+	if err != nil {
+		svc.Logger.Error("failed to load rich menu config", "error", err)
+		return svc
+	}
+	svc.config = config
+	svc.client = NewLineRichMenuClient(app.Env.LineAPIBaseURL, app.Env.LineAPIDataBaseURL, app.Env.LineChannelAccessToken)
+
+	if app.MySQL != nil {
+		svc.menuRepo = repositories.NewLineRichMenuRepository(app)
+		svc.teacherRepo = repositories.NewTeacherRepository(app)
+		svc.adminRepo = repositories.NewAdminUserRepository(app)
+	}
+	return svc
+}
+
+// Enabled 是否已啟用 Rich Menu 管理
+func (s *LineRichMenuService) Enabled() bool {
+	return s != nil && s.config != nil && s.menuRepo != nil
+}
+
+// uriVars uri 動作可使用的變數
+func (s *LineRichMenuService) uriVars() map[string]string {
+	vars := map[string]string{
+		"frontend_base_url": strings.TrimRight(s.App.Env.FrontendBaseURL, "/"),
+		"liff_url":          "",
+	}
+	if s.App.Env.LineLiffID != "" {
+		vars["liff_url"] = "https://liff.line.me/" + s.App.Env.LineLiffID
+	}
+	return vars
+}
+
+// Sync 將設定同步至 LINE：內容有變動的選單重新上傳，移除設定中已不存在的選單，
+// 重新連結已綁定的使用者後才刪除被替換的舊選單；中途失敗時下次同步會補做重新連結
+func (s *LineRichMenuService) Sync(ctx context.Context) error {
+	if !s.Enabled() {
+		return nil
+	}
+
+	vars := s.uriVars()
+	changed := false
+	defaultMenuID := ""
+	keys := make([]string, 0, len(s.config.Menus))
+
+	for _, menu := range s.config.Menus {
+		keys = append(keys, menu.Key)
+
+		req := BuildLineRichMenuRequest(menu, vars)
+		imageData, contentType, err := LoadLineRichMenuImage(menu)
+		if err != nil {
+			return err
+		}
+		hash := LineRichMenuConfigHash(req, imageData)
+
+		existing, err := s.menuRepo.GetByKey(ctx, menu.Key)
+		found := err == nil
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		if found && existing.ConfigHash == hash && existing.IsDefault == menu.Default {
+			if menu.Default {
+				defaultMenuID = existing.RichMenuID
+			}
+			if existing.ReplacedRichMenuID != "" {
+				// 上次同步替換後未完成重新連結
+				changed = true
+			}
+			continue
+		}
+
+		richMenuID := existing.RichMenuID
+		replacedID := existing.ReplacedRichMenuID
+		if !found || existing.ConfigHash != hash {
+			richMenuID, err = s.upload(ctx, req, contentType, imageData)
+			if err != nil {
+				return fmt.Errorf("rich menu %s: %w", menu.Key, err)
+			}
+			if found {
+				if replacedID == "" {
+					replacedID = existing.RichMenuID
+				} else if err := s.client.DeleteRichMenu(ctx, existing.RichMenuID); err != nil {
+					// 已有待刪除的舊選單（使用者仍連結於它），尚未批次連結過的中間版本直接刪除
+					s.Logger.Warn("failed to delete replaced rich menu", "menu_key", menu.Key, "rich_menu_id", existing.RichMenuID, "error", err)
+				}
+			}
+		}
+		changed = true
+
+		now := time.Now()
+		record := models.LineRichMenu{
+			MenuKey:            menu.Key,
+			RichMenuID:         richMenuID,
+			ReplacedRichMenuID: replacedID,
+			ConfigHash:         hash,
+			IsDefault:          menu.Default,
+			CreatedAt:          now,
+			UpdatedAt:          now,
+		}
+		if err := s.menuRepo.Upsert(ctx, record, "menu_key", []string{"rich_menu_id", "replaced_rich_menu_id", "config_hash", "is_default", "updated_at"}); err != nil {
+			return err
+		}
+
+		if menu.Default {
+			defaultMenuID = richMenuID
+		}
+	}
+
+	if defaultMenuID != "" && changed {
+		if err := s.client.SetDefaultRichMenu(ctx, defaultMenuID); err != nil {
+			return err
+		}
+	}
+
+	if err := s.removeStale(ctx, keys); err != nil {
+		return err
+	}
+
+	if !changed {
+		return nil
+	}
+	if err := s.relinkAll(ctx); err != nil {
+		return err
+	}
+	return s.deleteReplaced(ctx)
+}
+
+// upload 建立選單並上傳圖片，圖片上傳失敗時刪除剛建立的選單
+func (s *LineRichMenuService) upload(ctx context.Context, req LineRichMenuRequest, contentType string, imageData []byte) (string, error) {
+	richMenuID, err := s.client.CreateRichMenu(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	if err := s.client.UploadImage(ctx, richMenuID, contentType, imageData); err != nil {
+		_ = s.client.DeleteRichMenu(ctx, richMenuID)
+		return "", err
+	}
+	return richMenuID, nil
+}
+
+// removeStale 刪除設定中已不存在的選單
+func (s *LineRichMenuService) removeStale(ctx context.Context, keys []string) error {
+	stale, err := s.menuRepo.Find(ctx, "menu_key NOT IN ?", keys)
+	if err != nil {
+		return err
+	}
+	for _, record := range stale {
+		if err := s.client.DeleteRichMenu(ctx, record.RichMenuID); err != nil {
+			s.Logger.Warn("failed to delete stale rich menu", "menu_key", record.MenuKey, "error", err)
+			continue
+		}
+		if record.ReplacedRichMenuID != "" {
+			if err := s.client.DeleteRichMenu(ctx, record.ReplacedRichMenuID); err != nil {
+				s.Logger.Warn("failed to delete replaced rich menu", "menu_key", record.MenuKey, "rich_menu_id", record.ReplacedRichMenuID, "error", err)
+				continue
+			}
+		}
+		if _, err := s.menuRepo.DeleteWhere(ctx, "id = ?", record.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteReplaced 重新連結完成後刪除被替換的舊選單，刪除失敗的保留標記待下次同步處理
+func (s *LineRichMenuService) deleteReplaced(ctx context.Context) error {
+	records, err := s.menuRepo.Find(ctx, "replaced_rich_menu_id <> ''")
+	if err != nil {
+		return err
+	}
+	for _, record := range records {
+		if err := s.client.DeleteRichMenu(ctx, record.ReplacedRichMenuID); err != nil {
+			s.Logger.Warn("failed to delete replaced rich menu", "menu_key", record.MenuKey, "rich_menu_id", record.ReplacedRichMenuID, "error", err)
+			continue
+		}
+		if err := s.menuRepo.UpdateFields(ctx, record.ID, map[string]interface{}{"replaced_rich_menu_id": ""}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// relinkAll 重新連結已綁定的老師與管理員，同一 LINE 帳號同時為兩者時以管理員選單為準
+// 角色對應預設選單的使用者解除個別連結，避免仍停留在舊的角色選單
+func (s *LineRichMenuService) relinkAll(ctx context.Context) error {
+	roleByUser := map[string]string{}
+
+	var teacherLineIDs []string
+	if err := s.teacherRepo.Pluck(ctx, "line_user_id", &teacherLineIDs, "line_user_id <> '' AND deleted_at IS NULL"); err != nil {
+		return err
+	}
+	for _, id := range teacherLineIDs {
+		roleByUser[id] = LineRichMenuRoleTeacher
+	}
+
+	admins, err := s.adminRepo.Find(ctx, "status = ? AND line_user_id <> ''", "ACTIVE")
+	if err != nil {
+		return err
+	}
+	for _, admin := range admins {
+		roleByUser[admin.LineUserID] = admin.Role
+	}
+
+	usersByMenu := map[string][]string{}
+	var defaultUsers []string
+	for lineUserID, role := range roleByUser {
+		menu := s.config.MenuForRole(role)
+		if menu == nil || menu.Default {
+			defaultUsers = append(defaultUsers, lineUserID)
+			continue
+		}
+		usersByMenu[menu.Key] = append(usersByMenu[menu.Key], lineUserID)
+	}
+
+	if err := s.client.BulkUnlink(ctx, defaultUsers); err != nil {
+		return fmt.Errorf("rich menu bulk unlink: %w", err)
+	}
+
+	for key, userIDs := range usersByMenu {
+		record, err := s.menuRepo.GetByKey(ctx, key)
+		if err != nil {
+			return err
+		}
+		if err := s.client.BulkLink(ctx, record.RichMenuID, userIDs); err != nil {
+			return fmt.Errorf("rich menu %s bulk link: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// ResolveRole 依目前綁定狀態判斷使用者的選單角色
+func (s *LineRichMenuService) ResolveRole(ctx context.Context, lineUserID string) string {
+	if admin, err := s.adminRepo.GetByLineUserID(ctx, lineUserID); err == nil && admin.Status == "ACTIVE" {
+		return admin.Role
+	}
+	if _, err := s.teacherRepo.GetByLineUserID(ctx, lineUserID); err == nil {
+		return LineRichMenuRoleTeacher
+	}
+	return LineRichMenuRoleGuest
+}
+
+// LinkUser 依使用者目前的角色連結對應選單，角色對應預設選單時解除個別連結
+func (s *LineRichMenuService) LinkUser(ctx context.Context, lineUserID string) error {
+	if !s.Enabled() || lineUserID == "" {
+		return nil
+	}
+
+	menu := s.config.MenuForRole(s.ResolveRole(ctx, lineUserID))
+	if menu == nil || menu.Default {
+		return s.client.UnlinkUser(ctx, lineUserID)
+	}
+
+	record, err := s.menuRepo.GetByKey(ctx, menu.Key)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// 選單尚未同步，先顯示預設選單
+		return s.client.UnlinkUser(ctx, lineUserID)
+	}
+	if err != nil {
+		return err
+	}
+	return s.client.LinkUser(ctx, lineUserID, record.RichMenuID)
+}
+
+// LinkUserAsync 於背景更新使用者選單，失敗時僅記錄，不影響綁定流程
+func (s *LineRichMenuService) LinkUserAsync(lineUserID string) {
+	if !s.Enabled() || lineUserID == "" {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := s.LinkUser(ctx, lineUserID); err != nil {
+			s.Logger.Warn("failed to link rich menu", "line_user_id", lineUserID, "error", err)
+		}
+	}()
+}
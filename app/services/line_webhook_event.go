@@ -0,0 +1,247 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+	"timeLedger/app"
+	"timeLedger/app/models"
+	"timeLedger/app/repositories"
+	"timeLedger/global/errInfos"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// LineWebhookEventBatchSize 每次處理的事件筆數
+	LineWebhookEventBatchSize = 50
+	// LineWebhookEventRetention 事件紀錄保留天數
+	LineWebhookEventRetention = 30 * 24 * time.Hour
+	// lineWebhookEventTimeout 單筆事件的處理逾時時間
+	lineWebhookEventTimeout = 30 * time.Second
+	// lineWebhookEventLease 搶占後的保留時間，worker 中途停止時會在期滿後重新處理
+	lineWebhookEventLease = time.Minute
+)
+
+// LineWebhookEventHandler 處理單筆 LINE 事件；replay 為 true 表示重新處理，replyToken 可能已失效
+type LineWebhookEventHandler func(ctx context.Context, payload []byte, replay bool) error
+
+// LineWebhookEventDetail 事件紀錄（含原始事件內容）
+type LineWebhookEventDetail struct {
+	models.LineWebhookEvent
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+func newLineWebhookEventDetail(event models.LineWebhookEvent) LineWebhookEventDetail {
+	detail := LineWebhookEventDetail{LineWebhookEvent: event}
+	if json.Valid([]byte(event.Payload)) {
+		detail.Payload = json.RawMessage(event.Payload)
+	}
+	return detail
+}
+
+// ParseLineWebhookEvent 由 LINE 原始事件建立待處理紀錄；未帶 webhookEventId 時以內容雜湊識別
+func ParseLineWebhookEvent(raw json.RawMessage, now time.Time) (models.LineWebhookEvent, error) {
+	var envelope struct {
+		WebhookEventID string `json:"webhookEventId"`
+		Type           string `json:"type"`
+		Timestamp      int64  `json:"timestamp"`
+		Source         struct {
+			Type    string `json:"type"`
+			UserID  string `json:"userId"`
+			GroupID string `json:"groupId"`
+		} `json:"source"`
+		DeliveryContext struct {
+			IsRedelivery bool `json:"isRedelivery"`
+		} `json:"deliveryContext"`
+	}
+	if err := json.Unmarshal(raw, &envelope); err != nil {
+		return models.LineWebhookEvent{}, fmt.Errorf("invalid LINE webhook event: %w", err)
+	}
+
+	var payload bytes.Buffer
+	if err := json.Compact(&payload, raw); err != nil {
+		return models.LineWebhookEvent{}, fmt.Errorf("invalid LINE webhook event: %w", err)
+	}
+
+	eventID := envelope.WebhookEventID
+	if eventID == "" {
+		sum := sha256.Sum256(payload.Bytes())
+		eventID = hex.EncodeToString(sum[:])
+	}
+	occurredAt := now
+	if envelope.Timestamp > 0 {
+		occurredAt = time.UnixMilli(envelope.Timestamp)
+	}
+
+	return models.LineWebhookEvent{
+		WebhookEventID: eventID,
+		EventType:      envelope.Type,
+		SourceType:     envelope.Source.Type,
+		SourceUserID:   envelope.Source.UserID,
+		SourceGroupID:  envelope.Source.GroupID,
+		IsRedelivery:   envelope.DeliveryContext.IsRedelivery,
+		Payload:        payload.String(),
+		Status:         models.LineWebhookEventPending,
+		NextAttemptAt:  now,
+		OccurredAt:     occurredAt,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}, nil
+}
+
+// LineWebhookEventService LINE webhook 事件：寫入紀錄、去重、非同步處理與重新處理
+type LineWebhookEventService struct {
+	BaseService
+	eventRepo *repositories.LineWebhookEventRepository
+}
+
+// NewLineWebhookEventService 建立 LINE webhook 事件服務
+func NewLineWebhookEventService(app *app.App) *LineWebhookEventService {
+	svc := &LineWebhookEventService{
+		BaseService: *NewBaseService(app, "LineWebhookEventService"),
+	}
+	if app.MySQL != nil {
+		svc.eventRepo = repositories.NewLineWebhookEventRepository(app)
+	}
+	return svc
+}
+
+// Record 寫入收到的事件，LINE 重送的重複事件不再建立，回傳新建立紀錄的 ID
+func (s *LineWebhookEventService) Record(ctx context.Context, rawEvents []json.RawMessage) ([]uint, error) {
+	now := time.Now()
+	ids := make([]uint, 0, len(rawEvents))
+	for _, raw := range rawEvents {
+		event, err := ParseLineWebhookEvent(raw, now)
+		if err != nil {
+			s.Logger.Warn("skipped malformed LINE webhook event", "error", err)
+			continue
+		}
+		created, err := s.eventRepo.CreateIfAbsent(ctx, &event)
+		if err != nil {
+			return ids, fmt.Errorf("failed to record LINE webhook event: %w", err)
+		}
+		if !created {
+			s.Logger.Info("duplicate LINE webhook event ignored",
+				"webhook_event_id", event.WebhookEventID, "event_type", event.EventType, "is_redelivery", event.IsRedelivery)
+			continue
+		}
+		ids = append(ids, event.ID)
+	}
+	return ids, nil
+}
+
+// ProcessDue 處理待處理的事件，回傳處理的筆數
+func (s *LineWebhookEventService) ProcessDue(ctx context.Context, limit int, handler LineWebhookEventHandler) (int, error) {
+	events, err := s.eventRepo.ListDue(ctx, time.Now(), limit)
+	if err != nil {
+		return 0, err
+	}
+	for i, event := range events {
+		if err := s.Process(ctx, event.ID, handler); err != nil {
+			return i, err
+		}
+	}
+	return len(events), nil
+}
+
+// Process 處理單筆事件，已被其他 worker 搶占或已處理完成時略過
+func (s *LineWebhookEventService) Process(ctx context.Context, id uint, handler LineWebhookEventHandler) error {
+	now := time.Now()
+	claimed, err := s.eventRepo.Claim(ctx, id, now, now.Add(lineWebhookEventLease))
+	if err != nil || !claimed {
+		return err
+	}
+	event, err := s.eventRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// 首次處理以外皆視為重新處理
+	start := time.Now()
+	handleErr := runLineWebhookEventHandler(ctx, handler, event, event.Attempts > 1)
+	settleLineWebhookEvent(&event, handleErr, time.Since(start), time.Now())
+	if handleErr != nil {
+		s.Logger.Warn("LINE webhook event failed",
+			"event_id", event.ID, "webhook_event_id", event.WebhookEventID, "event_type", event.EventType,
+			"attempt", event.Attempts, "error", handleErr)
+	}
+	return s.eventRepo.RecordResult(ctx, event)
+}
+
+// runLineWebhookEventHandler 執行事件處理，panic 視為處理失敗
+func runLineWebhookEventHandler(ctx context.Context, handler LineWebhookEventHandler, event models.LineWebhookEvent, replay bool) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, lineWebhookEventTimeout)
+	defer cancel()
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return handler(ctx, []byte(event.Payload), replay)
+}
+
+// settleLineWebhookEvent 依處理結果更新紀錄；失敗不自動重試，由管理員決定是否重新處理
+func settleLineWebhookEvent(event *models.LineWebhookEvent, handleErr error, duration time.Duration, now time.Time) {
+	event.DurationMs = int(duration.Milliseconds())
+	event.UpdatedAt = now
+	if handleErr != nil {
+		event.Status = models.LineWebhookEventFailed
+		event.LastError = handleErr.Error()
+		return
+	}
+	event.Status = models.LineWebhookEventProcessed
+	event.LastError = ""
+	event.ProcessedAt = &now
+}
+
+// ListEvents 分頁取得與中心相關的事件紀錄
+func (s *LineWebhookEventService) ListEvents(ctx context.Context, centerID uint, status string, page, limit int) ([]models.LineWebhookEvent, int64, *errInfos.Res, error) {
+	events, total, err := s.eventRepo.ListByCenter(ctx, centerID, status, page, limit)
+	if err != nil {
+		return nil, 0, s.App.Err.New(errInfos.SQL_ERROR), err
+	}
+	return events, total, nil, nil
+}
+
+// GetEvent 取得單筆事件紀錄（含原始事件內容）
+func (s *LineWebhookEventService) GetEvent(ctx context.Context, centerID, id uint) (*LineWebhookEventDetail, *errInfos.Res, error) {
+	event, err := s.eventRepo.GetByIDAndCenter(ctx, id, centerID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, s.App.Err.New(errInfos.LINE_EVENT_NOT_FOUND), err
+	}
+	if err != nil {
+		return nil, s.App.Err.New(errInfos.SQL_ERROR), err
+	}
+	detail := newLineWebhookEventDetail(event)
+	return &detail, nil, nil
+}
+
+// Replay 將失敗的事件重新排入佇列，由 worker 以推播取代已失效的回覆
+func (s *LineWebhookEventService) Replay(ctx context.Context, centerID, id uint) (*LineWebhookEventDetail, *errInfos.Res, error) {
+	if _, errInfo, err := s.GetEvent(ctx, centerID, id); err != nil {
+		return nil, errInfo, err
+	}
+	requeued, err := s.eventRepo.Requeue(ctx, id, time.Now())
+	if err != nil {
+		return nil, s.App.Err.New(errInfos.SQL_ERROR), err
+	}
+	if !requeued {
+		return nil, s.App.Err.New(errInfos.LINE_EVENT_NOT_FAILED), fmt.Errorf("LINE webhook event %d is not failed", id)
+	}
+	s.Logger.Info("LINE webhook event requeued", "event_id", id, "center_id", centerID)
+	return s.GetEvent(ctx, centerID, id)
+}
+
+// Purge 刪除超過保留期限的事件紀錄
+func (s *LineWebhookEventService) Purge(ctx context.Context, now time.Time) (int64, error) {
+	if s.eventRepo == nil {
+		return 0, nil
+	}
+	return s.eventRepo.DeleteBefore(ctx, now.Add(-LineWebhookEventRetention))
+}
@@ -0,0 +1,210 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+	"timeLedger/app"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// 即時事件類型（推播給管理後台）
+const (
+	LiveEventRuleCreated        = "RULE_CREATED"
+	LiveEventRuleUpdated        = "RULE_UPDATED"
+	LiveEventRuleDeleted        = "RULE_DELETED"
+	LiveEventExceptionSubmitted = "EXCEPTION_SUBMITTED"
+	LiveEventExceptionReviewed  = "EXCEPTION_REVIEWED"
+	LiveEventHolidayChanged     = "HOLIDAY_CHANGED"
+)
+
+const (
+	// LiveEventChannelPattern 所有中心即時事件的 Pub/Sub 頻道
+	LiveEventChannelPattern = CacheKeyPrefix + ":live_events:channel:*"
+	// LiveEventLogSize 每個中心保留可補送的事件數
+	LiveEventLogSize = 500
+	// LiveEventLogTTL 事件紀錄保留時間，超過即需重新同步
+	LiveEventLogTTL = 24 * time.Hour
+)
+
+// LiveEvent 推播給管理後台的排課異動事件
+type LiveEvent struct {
+	Seq        int64          `json:"seq"` // 中心內遞增序號，供斷線重連時補送
+	CenterID   uint           `json:"center_id"`
+	Type       string         `json:"type"`
+	TargetType string         `json:"target_type"`
+	TargetID   uint           `json:"target_id"`
+	Data       map[string]any `json:"data,omitempty"`
+	OccurredAt time.Time      `json:"occurred_at"`
+}
+
+// LiveEventReplay 重連時補送的結果
+type LiveEventReplay struct {
+	Events    []LiveEvent
+	LatestSeq int64
+	// ResyncRequired 要求的序號已超出保留範圍，前端需重新載入整頁資料
+	ResyncRequired bool
+}
+
+// LiveEventService 排課即時事件發佈（Redis 序號 + 事件紀錄 + Pub/Sub）
+type LiveEventService struct {
+	BaseService
+	app   *app.App
+	redis *redis.Client
+}
+
+// NewLiveEventService 建立即時事件服務
+func NewLiveEventService(app *app.App) *LiveEventService {
+	baseSvc := NewBaseService(app, "LiveEventService")
+	svc := &LiveEventService{
+		BaseService: *baseSvc,
+		app:         app,
+	}
+	if app.Redis != nil {
+		svc.redis = app.Redis.DB0
+	}
+	return svc
+}
+
+// LiveEventChannel 中心的 Pub/Sub 頻道
+func LiveEventChannel(centerID uint) string {
+	return fmt.Sprintf("%s:live_events:channel:%d", CacheKeyPrefix, centerID)
+}
+
+func liveEventSeqKey(centerID uint) string {
+	return fmt.Sprintf("%s:live_events:seq:%d", CacheKeyPrefix, centerID)
+}
+
+func liveEventLogKey(centerID uint) string {
+	return fmt.Sprintf("%s:live_events:log:%d", CacheKeyPrefix, centerID)
+}
+
+// Publish 發佈事件；失敗只記錄警告，不影響原本的寫入流程
+func (s *LiveEventService) Publish(ctx context.Context, centerID uint, eventType, targetType string, targetID uint, data map[string]any) {
+	if s == nil || s.redis == nil || centerID == 0 {
+		return
+	}
+
+	seq, err := s.redis.Incr(ctx, liveEventSeqKey(centerID)).Result()
+	if err != nil {
+		s.Logger.Warn("live event sequence failed", "center_id", centerID, "type", eventType, "error", err)
+		return
+	}
+
+	event := LiveEvent{
+		Seq:        seq,
+		CenterID:   centerID,
+		Type:       eventType,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Data:       data,
+		OccurredAt: time.Now(),
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		s.Logger.Warn("live event marshal failed", "center_id", centerID, "type", eventType, "error", err)
+		return
+	}
+
+	logKey := liveEventLogKey(centerID)
+	pipe := s.redis.TxPipeline()
+	pipe.ZAdd(ctx, logKey, redis.Z{Score: float64(seq), Member: payload})
+	pipe.ZRemRangeByRank(ctx, logKey, 0, -LiveEventLogSize-1)
+	pipe.Expire(ctx, logKey, LiveEventLogTTL)
+	pipe.Publish(ctx, LiveEventChannel(centerID), payload)
+	if _, err := pipe.Exec(ctx); err != nil {
+		s.Logger.Warn("live event publish failed", "center_id", centerID, "type", eventType, "seq", seq, "error", err)
+	}
+}
+
+// Replay 取得序號 afterSeq 之後的事件
+func (s *LiveEventService) Replay(ctx context.Context, centerID uint, afterSeq int64) (*LiveEventReplay, error) {
+	if s.redis == nil {
+		return &LiveEventReplay{}, nil
+	}
+
+	latest, err := s.redis.Get(ctx, liveEventSeqKey(centerID)).Int64()
+	if err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	events, err := s.Retained(ctx, centerID, afterSeq)
+	if err != nil {
+		return nil, err
+	}
+
+	return BuildLiveEventReplay(events, afterSeq, latest), nil
+}
+
+// Retained 取得事件紀錄中序號 afterSeq 之後的事件（依序號排序）
+func (s *LiveEventService) Retained(ctx context.Context, centerID uint, afterSeq int64) ([]LiveEvent, error) {
+	if s.redis == nil {
+		return nil, nil
+	}
+
+	members, err := s.redis.ZRangeByScore(ctx, liveEventLogKey(centerID), &redis.ZRangeBy{
+		Min: fmt.Sprintf("(%d", afterSeq),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]LiveEvent, 0, len(members))
+	for _, m := range members {
+		var event LiveEvent
+		if err := json.Unmarshal([]byte(m), &event); err != nil {
+			continue
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// FillLiveEventGap 從保留的事件取出序號介於 afterSeq 與 beforeSeq 之間（不含兩端）的事件
+// 序號必須連續才算補齊；取得序號後發佈失敗的事件不會出現在紀錄中，此時回傳 false
+func FillLiveEventGap(retained []LiveEvent, afterSeq, beforeSeq int64) ([]LiveEvent, bool) {
+	var events []LiveEvent
+	next := afterSeq + 1
+	for _, event := range retained {
+		if event.Seq < next {
+			continue
+		}
+		if event.Seq >= beforeSeq {
+			break
+		}
+		if event.Seq != next {
+			return nil, false
+		}
+		events = append(events, event)
+		next++
+	}
+	return events, next == beforeSeq
+}
+
+// BuildLiveEventReplay 依保留的事件判斷能否從 afterSeq 接續
+// afterSeq < 0 表示新連線，不補送；最早保留的事件已超過 afterSeq+1（被裁切或過期）
+// 或 afterSeq 大於目前序號（Redis 重置）則要求重新同步。
+// 尚在發佈中的事件（已取得序號、未寫入紀錄）會由 Pub/Sub 送達，不視為缺漏。
+func BuildLiveEventReplay(retained []LiveEvent, afterSeq, latestSeq int64) *LiveEventReplay {
+	result := &LiveEventReplay{LatestSeq: latestSeq}
+	if afterSeq > latestSeq {
+		result.ResyncRequired = true
+		return result
+	}
+	if afterSeq < 0 || afterSeq == latestSeq {
+		return result
+	}
+
+	for _, event := range retained {
+		if event.Seq > afterSeq {
+			result.Events = append(result.Events, event)
+		}
+	}
+	if len(result.Events) == 0 || result.Events[0].Seq > afterSeq+1 {
+		return &LiveEventReplay{LatestSeq: latestSeq, ResyncRequired: true}
+	}
+	return result
+}
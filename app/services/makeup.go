@@ -0,0 +1,553 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+	"timeLedger/app"
+	"timeLedger/app/models"
+	"timeLedger/app/repositories"
+	"timeLedger/global/errInfos"
+	"timeLedger/libs"
+
+	"gorm.io/gorm"
+)
+
+// MakeupService 補課與學期堂數追蹤
+type MakeupService struct {
+	BaseService
+	app           *app.App
+	termRepo      *repositories.CenterTermRepository
+	offeringRepo  *repositories.OfferingRepository
+	quotaRepo     *repositories.OfferingTermQuotaRepository
+	makeupRepo    *repositories.MakeupSessionRepository
+	ruleRepo      *repositories.ScheduleRuleRepository
+	exceptionRepo *repositories.ScheduleExceptionRepository
+	expansionSvc  ScheduleExpansionService
+	validationSvc ScheduleValidationService
+}
+
+// NewMakeupService 建立 MakeupService 實例
+func NewMakeupService(app *app.App) *MakeupService {
+	return &MakeupService{
+		BaseService:   *NewBaseService(app, "MakeupService"),
+		app:           app,
+		termRepo:      repositories.NewCenterTermRepository(app),
+		offeringRepo:  repositories.NewOfferingRepository(app),
+		quotaRepo:     repositories.NewOfferingTermQuotaRepository(app),
+		makeupRepo:    repositories.NewMakeupSessionRepository(app),
+		ruleRepo:      repositories.NewScheduleRuleRepository(app),
+		exceptionRepo: repositories.NewScheduleExceptionRepository(app),
+		expansionSvc:  NewScheduleExpansionService(app),
+		validationSvc: NewScheduleValidationService(app),
+	}
+}
+
+// SetPlannedSessionsRequest 設定學期預計堂數請求
+type SetPlannedSessionsRequest struct {
+	PlannedSessions int `json:"planned_sessions" binding:"min=0"`
+}
+
+// ScheduleMakeupRequest 安排補課請求
+type ScheduleMakeupRequest struct {
+	TermID         uint   `json:"term_id" binding:"required"`
+	OriginalRuleID uint   `json:"original_rule_id" binding:"required"`
+	OriginalDate   string `json:"original_date" binding:"required,date_format"`
+	MakeupDate     string `json:"makeup_date" binding:"required,date_format"`
+	StartTime      string `json:"start_time" binding:"required,time_format"`
+	EndTime        string `json:"end_time" binding:"required,time_format"`
+	TeacherID      *uint  `json:"teacher_id"` // 未指定時沿用原規則老師
+	RoomID         *uint  `json:"room_id"`    // 未指定時沿用原規則教室
+	Note           string `json:"note"`
+}
+
+// CancelledSession 被取消的原場次
+type CancelledSession struct {
+	RuleID       uint   `json:"rule_id"`
+	Date         string `json:"date"`
+	StartTime    string `json:"start_time"`
+	EndTime      string `json:"end_time"`
+	Reason       string `json:"reason"`                  // HOLIDAY, SUSPENDED, EXCEPTION
+	ReasonDetail string `json:"reason_detail,omitempty"` // 假日名稱或例外單原因
+	ExceptionID  *uint  `json:"exception_id,omitempty"`
+	MakeupID     *uint  `json:"makeup_id,omitempty"` // 已安排的補課
+	MakeupDate   string `json:"makeup_date,omitempty"`
+}
+
+// SessionQuotaSummary 班別在學期內的堂數統計
+type SessionQuotaSummary struct {
+	OfferingID        uint               `json:"offering_id"`
+	OfferingName      string             `json:"offering_name"`
+	TermID            uint               `json:"term_id"`
+	TermName          string             `json:"term_name"`
+	PlannedSessions   int                `json:"planned_sessions"`   // 學期應上堂數（0 表示未設定）
+	ScheduledSessions int                `json:"scheduled_sessions"` // 規則展開的場次（含被取消）
+	DeliveredSessions int                `json:"delivered_sessions"` // 已上課
+	UpcomingSessions  int                `json:"upcoming_sessions"`  // 尚未上課
+	CancelledSessions int                `json:"cancelled_sessions"`
+	MakeupDelivered   int                `json:"makeup_delivered"`
+	MakeupScheduled   int                `json:"makeup_scheduled"` // 已安排但尚未上課的補課
+	OwedSessions      int                `json:"owed_sessions"`    // 尚欠補課堂數
+	Cancelled         []CancelledSession `json:"cancelled"`
+}
+
+// SessionQuotaInput 堂數計算所需資料
+type SessionQuotaInput struct {
+	TermStart       time.Time
+	TermEnd         time.Time
+	Today           time.Time
+	PlannedSessions int
+	Rules           []models.ScheduleRule
+	Holidays        []models.CenterHoliday
+	Exceptions      map[uint]map[string][]models.ScheduleException
+	Makeups         []models.MakeupSession
+	// MakeupWorkdays 補班日（日期 -> 比照星期），中心未開啟補班設定時為 nil
+	MakeupWorkdays map[string]int
+}
+
+// CalculateSessionQuota 依規則、假日與例外單計算已上、取消與尚欠堂數
+// 判斷方式與 ExpandRules 一致：假日（強制停課或規則跳過假日）、暫停日、已核准的停課例外單視為取消
+// 只計入已開課（CONFIRMED）的規則，預計開課的佔位規則不列入
+func CalculateSessionQuota(input SessionQuotaInput) SessionQuotaSummary {
+	summary := SessionQuotaSummary{
+		PlannedSessions: input.PlannedSessions,
+		Cancelled:       []CancelledSession{},
+	}
+
+	holidayMap := make(map[string]models.CenterHoliday, len(input.Holidays))
+	for _, h := range input.Holidays {
+		holidayMap[h.Date.Format("2006-01-02")] = h
+	}
+
+	makeupBySession := make(map[string]models.MakeupSession)
+	todayStr := input.Today.Format("2006-01-02")
+	for _, m := range input.Makeups {
+		if m.Status != models.MakeupStatusScheduled {
+			continue
+		}
+		makeupBySession[sessionKey(m.OriginalRuleID, m.OriginalDate.Format("2006-01-02"))] = m
+		if m.MakeupDate.Format("2006-01-02") < todayStr {
+			summary.MakeupDelivered++
+		} else {
+			summary.MakeupScheduled++
+		}
+	}
+
+	termStart := dateOnly(input.TermStart)
+	termEnd := dateOnly(input.TermEnd)
+
+	for _, rule := range input.Rules {
+		if rule.Weekday == 0 {
+			continue
+		}
+		if rule.Status != "" && rule.Status != models.RuleStatusConfirmed {
+			continue
+		}
+
+		start := termStart
+		if !rule.EffectiveRange.StartDate.IsZero() && dateOnly(rule.EffectiveRange.StartDate).After(start) {
+			start = dateOnly(rule.EffectiveRange.StartDate)
+		}
+		end := termEnd
+		if !rule.EffectiveRange.EndDate.IsZero() && dateOnly(rule.EffectiveRange.EndDate).Before(end) {
+			end = dateOnly(rule.EffectiveRange.EndDate)
+		}
+
+		suspended := make(map[string]bool, len(rule.SuspendedDates))
+		for _, d := range rule.SuspendedDates {
+			suspended[d.Format("2006-01-02")] = true
+		}
+
+		for date := start; !date.After(end); date = date.AddDate(0, 0, 1) {
+			if ResolveEffectiveWeekday(date, input.MakeupWorkdays) != rule.Weekday {
+				continue
+			}
+
+			dateStr := date.Format("2006-01-02")
+			summary.ScheduledSessions++
+
+			cancelled := CancelledSession{
+				RuleID:    rule.ID,
+				Date:      dateStr,
+				StartTime: rule.StartTime,
+				EndTime:   rule.EndTime,
+			}
+
+			if holiday, ok := holidayMap[dateStr]; ok && (holiday.ForceCancel || rule.SkipHoliday) {
+				cancelled.Reason = models.SessionCancelReasonHoliday
+				cancelled.ReasonDetail = holiday.Name
+			} else if suspended[dateStr] {
+				cancelled.Reason = models.SessionCancelReasonSuspended
+			} else if exc := findApprovedCancel(input.Exceptions[rule.ID][dateStr]); exc != nil {
+				excID := exc.ID
+				cancelled.Reason = models.SessionCancelReasonException
+				cancelled.ReasonDetail = exc.Reason
+				cancelled.ExceptionID = &excID
+			}
+
+			if cancelled.Reason == "" {
+				if dateStr < todayStr {
+					summary.DeliveredSessions++
+				} else {
+					summary.UpcomingSessions++
+				}
+				continue
+			}
+
+			if m, ok := makeupBySession[sessionKey(rule.ID, dateStr)]; ok {
+				makeupID := m.ID
+				cancelled.MakeupID = &makeupID
+				cancelled.MakeupDate = m.MakeupDate.Format("2006-01-02")
+			}
+			summary.CancelledSessions++
+			summary.Cancelled = append(summary.Cancelled, cancelled)
+		}
+	}
+
+	sort.Slice(summary.Cancelled, func(i, j int) bool {
+		if summary.Cancelled[i].Date != summary.Cancelled[j].Date {
+			return summary.Cancelled[i].Date < summary.Cancelled[j].Date
+		}
+		return summary.Cancelled[i].StartTime < summary.Cancelled[j].StartTime
+	})
+
+	makeups := summary.MakeupDelivered + summary.MakeupScheduled
+	if summary.PlannedSessions > 0 {
+		summary.OwedSessions = summary.PlannedSessions - summary.DeliveredSessions - summary.UpcomingSessions - makeups
+	} else {
+		summary.OwedSessions = summary.CancelledSessions - makeups
+	}
+	if summary.OwedSessions < 0 {
+		summary.OwedSessions = 0
+	}
+
+	return summary
+}
+
+// findApprovedCancel 找出已核准的停課例外單
+func findApprovedCancel(exceptions []models.ScheduleException) *models.ScheduleException {
+	for i := range exceptions {
+		if exceptions[i].Status == "APPROVED" && exceptions[i].ExceptionType == "CANCEL" {
+			return &exceptions[i]
+		}
+	}
+	return nil
+}
+
+func sessionKey(ruleID uint, date string) string {
+	return fmt.Sprintf("%d_%s", ruleID, date)
+}
+
+// dateOnly 取日期部分（忽略時區差異）
+func dateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// SetPlannedSessions 設定班別在學期的預計堂數
+func (s *MakeupService) SetPlannedSessions(ctx context.Context, centerID, adminID, offeringID, termID uint, req *SetPlannedSessionsRequest) (*SessionQuotaSummary, *errInfos.Res, error) {
+	if _, err := s.offeringRepo.GetByIDAndCenterID(ctx, offeringID, centerID); err != nil {
+		return nil, s.app.Err.New(errInfos.NOT_FOUND), fmt.Errorf("offering not found")
+	}
+	if _, err := s.termRepo.GetByIDWithCenterScope(ctx, termID, centerID); err != nil {
+		return nil, s.app.Err.New(errInfos.NOT_FOUND), fmt.Errorf("term not found")
+	}
+
+	now := time.Now()
+	quota := models.OfferingTermQuota{
+		CenterID:        centerID,
+		OfferingID:      offeringID,
+		TermID:          termID,
+		PlannedSessions: req.PlannedSessions,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if err := s.quotaRepo.UpsertPlannedSessions(ctx, quota); err != nil {
+		s.Logger.Error("failed to upsert planned sessions", "error", err)
+		return nil, s.app.Err.New(errInfos.SQL_ERROR), err
+	}
+
+	s.app.MySQL.WDB.WithContext(ctx).Create(&models.AuditLog{
+		CenterID:   centerID,
+		ActorType:  "ADMIN",
+		ActorID:    adminID,
+		Action:     "SET_PLANNED_SESSIONS",
+		TargetType: "Offering",
+		TargetID:   offeringID,
+		Payload: models.AuditPayload{
+			After: map[string]interface{}{
+				"term_id":          termID,
+				"planned_sessions": req.PlannedSessions,
+			},
+		},
+	})
+
+	return s.GetSessionQuota(ctx, centerID, offeringID, termID)
+}
+
+// GetSessionQuota 計算班別在學期內的已上、取消與尚欠堂數
+func (s *MakeupService) GetSessionQuota(ctx context.Context, centerID, offeringID, termID uint) (*SessionQuotaSummary, *errInfos.Res, error) {
+	offering, err := s.offeringRepo.GetByIDAndCenterID(ctx, offeringID, centerID)
+	if err != nil {
+		return nil, s.app.Err.New(errInfos.NOT_FOUND), fmt.Errorf("offering not found")
+	}
+	term, err := s.termRepo.GetByIDWithCenterScope(ctx, termID, centerID)
+	if err != nil {
+		return nil, s.app.Err.New(errInfos.NOT_FOUND), fmt.Errorf("term not found")
+	}
+
+	summary, err := s.calculateForOffering(ctx, centerID, offering, term)
+	if err != nil {
+		s.Logger.Error("failed to calculate session quota", "offering_id", offeringID, "term_id", termID, "error", err)
+		return nil, s.app.Err.New(errInfos.SQL_ERROR), err
+	}
+	return summary, nil, nil
+}
+
+// GetOutstandingMakeupReport 取得學期內各班別尚欠補課報表（只列出有取消或尚欠堂數的班別）
+func (s *MakeupService) GetOutstandingMakeupReport(ctx context.Context, centerID, termID uint) ([]SessionQuotaSummary, *errInfos.Res, error) {
+	term, err := s.termRepo.GetByIDWithCenterScope(ctx, termID, centerID)
+	if err != nil {
+		return nil, s.app.Err.New(errInfos.NOT_FOUND), fmt.Errorf("term not found")
+	}
+
+	offerings, err := s.offeringRepo.ListActiveByCenterID(ctx, centerID)
+	if err != nil {
+		return nil, s.app.Err.New(errInfos.SQL_ERROR), err
+	}
+
+	report := make([]SessionQuotaSummary, 0, len(offerings))
+	for _, offering := range offerings {
+		summary, err := s.calculateForOffering(ctx, centerID, offering, term)
+		if err != nil {
+			s.Logger.Error("failed to calculate session quota", "offering_id", offering.ID, "term_id", termID, "error", err)
+			return nil, s.app.Err.New(errInfos.SQL_ERROR), err
+		}
+		if summary.OwedSessions == 0 && summary.CancelledSessions == 0 {
+			continue
+		}
+		report = append(report, *summary)
+	}
+
+	sort.Slice(report, func(i, j int) bool {
+		return report[i].OwedSessions > report[j].OwedSessions
+	})
+
+	return report, nil, nil
+}
+
+func (s *MakeupService) calculateForOffering(ctx context.Context, centerID uint, offering models.Offering, term models.CenterTerm) (*SessionQuotaSummary, error) {
+	rules, err := s.expansionSvc.GetRulesByEffectiveDateRange(ctx, centerID, offering.ID, term.StartDate, term.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	holidays, err := s.expansionSvc.GetHolidaysByDateRange(ctx, centerID, term.StartDate, term.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	ruleIDs := make([]uint, 0, len(rules))
+	for _, rule := range rules {
+		ruleIDs = append(ruleIDs, rule.ID)
+	}
+	exceptions, err := s.exceptionRepo.GetByRuleIDsAndDateRange(ctx, ruleIDs, term.StartDate, term.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	makeups, err := s.makeupRepo.ListByOfferingAndTerm(ctx, offering.ID, term.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	planned := 0
+	quota, err := s.quotaRepo.GetByOfferingAndTerm(ctx, offering.ID, term.ID)
+	if err == nil {
+		planned = quota.PlannedSessions
+	} else if err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	summary := CalculateSessionQuota(SessionQuotaInput{
+		TermStart:       term.StartDate,
+		TermEnd:         term.EndDate,
+		Today:           libs.TodayInTaiwan(),
+		PlannedSessions: planned,
+		Rules:           rules,
+		Holidays:        holidays,
+		Exceptions:      exceptions,
+		Makeups:         makeups,
+		MakeupWorkdays:  s.expansionSvc.GetMakeupWorkdayMap(ctx, centerID, term.StartDate, term.EndDate),
+	})
+	summary.OfferingID = offering.ID
+	summary.OfferingName = offering.Name
+	summary.TermID = term.ID
+	summary.TermName = term.Name
+
+	return &summary, nil
+}
+
+// ScheduleMakeup 為被取消的場次安排補課
+func (s *MakeupService) ScheduleMakeup(ctx context.Context, centerID, adminID uint, req *ScheduleMakeupRequest) (*models.MakeupSession, *ValidationResult, *errInfos.Res, error) {
+	loc := libs.GetTaiwanLocation()
+	originalDate, err := time.ParseInLocation("2006-01-02", req.OriginalDate, loc)
+	if err != nil {
+		return nil, nil, s.app.Err.New(errInfos.PARAMS_VALIDATE_ERROR), fmt.Errorf("invalid original_date format: %w", err)
+	}
+	makeupStart, err := time.ParseInLocation("2006-01-02 15:04", req.MakeupDate+" "+req.StartTime, loc)
+	if err != nil {
+		return nil, nil, s.app.Err.New(errInfos.PARAMS_VALIDATE_ERROR), fmt.Errorf("invalid makeup date/time: %w", err)
+	}
+	makeupEnd, err := time.ParseInLocation("2006-01-02 15:04", req.MakeupDate+" "+req.EndTime, loc)
+	if err != nil {
+		return nil, nil, s.app.Err.New(errInfos.PARAMS_VALIDATE_ERROR), fmt.Errorf("invalid makeup date/time: %w", err)
+	}
+	if !makeupEnd.After(makeupStart) {
+		return nil, nil, s.app.Err.New(errInfos.SCHED_START_AFTER_END), fmt.Errorf("makeup end time must be after start time")
+	}
+
+	rule, err := s.ruleRepo.GetByIDAndCenterID(ctx, req.OriginalRuleID, centerID)
+	if err != nil {
+		return nil, nil, s.app.Err.New(errInfos.NOT_FOUND), fmt.Errorf("rule not found")
+	}
+	offering, err := s.offeringRepo.GetByIDAndCenterID(ctx, rule.OfferingID, centerID)
+	if err != nil {
+		return nil, nil, s.app.Err.New(errInfos.NOT_FOUND), fmt.Errorf("offering not found")
+	}
+	term, err := s.termRepo.GetByIDWithCenterScope(ctx, req.TermID, centerID)
+	if err != nil {
+		return nil, nil, s.app.Err.New(errInfos.NOT_FOUND), fmt.Errorf("term not found")
+	}
+
+	// 確認原場次確實被取消，且尚未安排補課
+	summary, err := s.calculateForOffering(ctx, centerID, offering, term)
+	if err != nil {
+		return nil, nil, s.app.Err.New(errInfos.SQL_ERROR), err
+	}
+	var cancelled *CancelledSession
+	for i := range summary.Cancelled {
+		if summary.Cancelled[i].RuleID == rule.ID && summary.Cancelled[i].Date == req.OriginalDate {
+			cancelled = &summary.Cancelled[i]
+			break
+		}
+	}
+	if cancelled == nil {
+		return nil, nil, s.app.Err.New(errInfos.MAKEUP_SESSION_NOT_CANCELLED), fmt.Errorf("session %s of rule %d is not cancelled", req.OriginalDate, rule.ID)
+	}
+	if cancelled.MakeupID != nil {
+		return nil, nil, s.app.Err.New(errInfos.MAKEUP_ALREADY_SCHEDULED), fmt.Errorf("makeup already scheduled for session %s of rule %d", req.OriginalDate, rule.ID)
+	}
+
+	teacherID := rule.TeacherID
+	if req.TeacherID != nil {
+		teacherID = req.TeacherID
+	}
+	roomID := rule.RoomID
+	if req.RoomID != nil {
+		roomID = *req.RoomID
+	}
+
+	validation, err := s.validationSvc.ValidateFull(ctx, centerID, teacherID, roomID, offering.CourseID, makeupStart, makeupEnd, nil, offering.AllowBufferOverride, nil, nil)
+	if err != nil {
+		return nil, nil, s.app.Err.New(errInfos.SQL_ERROR), fmt.Errorf("failed to validate makeup slot: %w", err)
+	}
+	if !validation.Valid {
+		return nil, &validation, s.app.Err.New(errInfos.SCHED_OVERLAP), fmt.Errorf("makeup slot conflicts with existing schedule")
+	}
+
+	makeup := models.MakeupSession{
+		CenterID:       centerID,
+		OfferingID:     offering.ID,
+		TermID:         term.ID,
+		OriginalRuleID: rule.ID,
+		OriginalDate:   originalDate,
+		CancelReason:   cancelled.Reason,
+		ExceptionID:    cancelled.ExceptionID,
+		MakeupDate:     makeupStart,
+		StartTime:      req.StartTime,
+		EndTime:        req.EndTime,
+		TeacherID:      teacherID,
+		RoomID:         roomID,
+		Status:         models.MakeupStatusScheduled,
+		Note:           req.Note,
+		CreatedBy:      adminID,
+	}
+
+	txErr := s.app.MySQL.WDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&makeup).Error; err != nil {
+			return fmt.Errorf("failed to create makeup session: %w", err)
+		}
+
+		auditLog := models.AuditLog{
+			CenterID:   centerID,
+			ActorType:  "ADMIN",
+			ActorID:    adminID,
+			Action:     "SCHEDULE_MAKEUP",
+			TargetType: "MakeupSession",
+			TargetID:   makeup.ID,
+			Payload:    models.AuditPayload{After: makeup},
+		}
+		return tx.Create(&auditLog).Error
+	})
+	if repositories.IsDuplicateKeyError(txErr) {
+		// 同時送出的另一筆已先建立
+		return nil, nil, s.app.Err.New(errInfos.MAKEUP_ALREADY_SCHEDULED), fmt.Errorf("makeup already scheduled for session %s of rule %d", req.OriginalDate, rule.ID)
+	}
+	if txErr != nil {
+		s.Logger.Error("failed to schedule makeup", "error", txErr)
+		return nil, nil, s.app.Err.New(errInfos.SQL_ERROR), txErr
+	}
+
+	s.Logger.Info("makeup scheduled", "makeup_id", makeup.ID, "rule_id", rule.ID, "original_date", req.OriginalDate)
+	return &makeup, nil, nil, nil
+}
+
+// CancelMakeup 取消已安排的補課（原場次重新列入尚欠）
+func (s *MakeupService) CancelMakeup(ctx context.Context, centerID, adminID, makeupID uint) (*errInfos.Res, error) {
+	makeup, err := s.makeupRepo.GetByIDWithCenterScope(ctx, makeupID, centerID)
+	if err != nil {
+		return s.app.Err.New(errInfos.NOT_FOUND), fmt.Errorf("makeup not found")
+	}
+	if makeup.Status != models.MakeupStatusScheduled {
+		return s.app.Err.New(errInfos.INVALID_STATUS), fmt.Errorf("makeup is not scheduled")
+	}
+
+	txErr := s.app.MySQL.WDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.MakeupSession{}).
+			Where("id = ?", makeupID).
+			Updates(map[string]interface{}{"status": models.MakeupStatusCancelled, "updated_at": time.Now()}).Error; err != nil {
+			return err
+		}
+
+		auditLog := models.AuditLog{
+			CenterID:   centerID,
+			ActorType:  "ADMIN",
+			ActorID:    adminID,
+			Action:     "CANCEL_MAKEUP",
+			TargetType: "MakeupSession",
+			TargetID:   makeupID,
+			Payload:    models.AuditPayload{Before: makeup},
+		}
+		return tx.Create(&auditLog).Error
+	})
+	if txErr != nil {
+		s.Logger.Error("failed to cancel makeup", "makeup_id", makeupID, "error", txErr)
+		return s.app.Err.New(errInfos.SQL_ERROR), txErr
+	}
+
+	return nil, nil
+}
+
+// ListMakeups 取得班別在學期內的補課列表
+func (s *MakeupService) ListMakeups(ctx context.Context, centerID, offeringID, termID uint) ([]models.MakeupSession, *errInfos.Res, error) {
+	if _, err := s.offeringRepo.GetByIDAndCenterID(ctx, offeringID, centerID); err != nil {
+		return nil, s.app.Err.New(errInfos.NOT_FOUND), fmt.Errorf("offering not found")
+	}
+
+	makeups, err := s.makeupRepo.ListByOfferingAndTerm(ctx, offeringID, termID)
+	if err != nil {
+		return nil, s.app.Err.New(errInfos.SQL_ERROR), err
+	}
+	return makeups, nil, nil
+}
@@ -17,6 +17,8 @@ type NotificationServiceImpl struct {
 	scheduleRuleRepo      *repositories.ScheduleRuleRepository
 	scheduleExceptionRepo *repositories.ScheduleExceptionRepository
 	LINENotifyService     LINENotifyService
+	preferenceService     *NotificationPreferenceService
+	emailService          *EmailNotificationService
 }
 
 func NewNotificationService(app *app.App) NotificationService {
@@ -27,27 +29,56 @@ func NewNotificationService(app *app.App) NotificationService {
 		scheduleRuleRepo:      repositories.NewScheduleRuleRepository(app),
 		scheduleExceptionRepo: repositories.NewScheduleExceptionRepository(app),
 		LINENotifyService:     NewLINENotifyService(app),
+		preferenceService:     NewNotificationPreferenceService(app),
+		emailService:          NewEmailNotificationService(app),
 	}
 }
 
+// notificationEventOf 站內通知類型對應的偏好事件，系統通知回傳空字串（不受偏好影響）
+func notificationEventOf(notificationType string) string {
+	switch notificationType {
+	case "REMINDER":
+		return models.NotificationEventReminder
+	case "REVIEW_RESULT":
+		return models.NotificationEventExceptionResult
+	case "BROADCAST":
+		return models.NotificationEventBroadcast
+	case "TALENT_INVITATION":
+		return models.NotificationEventInvitation
+	}
+	return ""
+}
+
 func (s *NotificationServiceImpl) SendTeacherNotification(ctx context.Context, teacherID uint, title, message string) error {
 	return s.SendTeacherNotificationWithType(ctx, teacherID, title, message, "SYSTEM")
 }
 
 func (s *NotificationServiceImpl) SendTeacherNotificationWithType(ctx context.Context, teacherID uint, title, message string, notificationType string) error {
-	notification := models.Notification{
-		UserID:    teacherID,
-		UserType:  "TEACHER",
-		Title:     title,
-		Message:   message,
-		Type:      notificationType,
-		IsRead:    false,
-		CreatedAt: time.Now(),
-	}
+	return s.deliverTeacherNotification(ctx, teacherID, title, message, notificationType, "\n")
+}
 
-	_, err := s.notificationRepo.Create(ctx, notification)
-	if err != nil {
-		return err
+// deliverTeacherNotification 依老師的通知偏好寫入站內通知並發送 LINE Notify 與 Email
+// separator 為 LINE 訊息中標題與內容之間的分隔
+func (s *NotificationServiceImpl) deliverTeacherNotification(ctx context.Context, teacherID uint, title, message, notificationType, separator string) error {
+	event := notificationEventOf(notificationType)
+	pref := s.preferenceService.PreferenceOrDefault(ctx, "TEACHER", teacherID)
+	now := time.Now()
+
+	if PlanNotificationDelivery(pref, event, models.NotificationChannelInApp, now, false).Action != NotificationDeliverSkip {
+		notification := models.Notification{
+			UserID:    teacherID,
+			UserType:  "TEACHER",
+			Title:     title,
+			Message:   message,
+			Type:      notificationType,
+			IsRead:    false,
+			CreatedAt: now,
+		}
+
+		_, err := s.notificationRepo.Create(ctx, notification)
+		if err != nil {
+			return err
+		}
 	}
 
 	teacher, err := s.teacherRepo.GetByID(ctx, teacherID)
@@ -56,7 +87,41 @@ func (s *NotificationServiceImpl) SendTeacherNotificationWithType(ctx context.Co
 	}
 
 	if teacher.LineNotifyToken != "" {
-		go s.LINENotifyService.SendMessage(ctx, teacher.LineNotifyToken, title+"\n"+message)
+		plan := PlanNotificationDelivery(pref, event, models.NotificationChannelLine, now, false)
+		switch {
+		case plan.Held():
+			if err := s.preferenceService.Hold(ctx, "TEACHER", teacherID, event, plan, HeldNotificationPayload{
+				Channel: models.NotificationChannelLine,
+				Title:   title,
+				Message: message,
+			}); err != nil {
+				return err
+			}
+		case plan.Action == NotificationDeliverNow:
+			go s.LINENotifyService.SendMessage(ctx, teacher.LineNotifyToken, title+separator+message)
+		}
+	}
+
+	if teacher.Email != "" && s.emailService.Enabled() {
+		data := EmailTemplateData{RecipientName: teacher.Name, Title: title, Message: message}
+		plan := PlanNotificationDelivery(pref, event, models.NotificationChannelEmail, now, false)
+		switch {
+		case plan.Held():
+			return s.preferenceService.Hold(ctx, "TEACHER", teacherID, event, plan, HeldNotificationPayload{
+				Channel: models.NotificationChannelEmail,
+				Title:   title,
+				Message: message,
+				Email:   &data,
+			})
+		case plan.Action == NotificationDeliverNow:
+			s.emailService.SendAsync(ctx, EmailNotification{
+				RecipientType: "TEACHER",
+				RecipientID:   teacherID,
+				Address:       teacher.Email,
+				TemplateType:  emailTemplateTypeOf(event),
+				Data:          data,
+			})
+		}
 	}
 
 	return nil
@@ -91,7 +156,7 @@ func (s *NotificationServiceImpl) SendScheduleReminder(ctx context.Context, rule
 	title := "課程提醒"
 	message := "您有課程即將開始\n\n時間: " + date.Format("2006-01-02 15:04")
 
-	return s.SendTeacherNotification(ctx, *rule.TeacherID, title, message)
+	return s.SendTeacherNotificationWithType(ctx, *rule.TeacherID, title, message, "REMINDER")
 }
 
 func (s *NotificationServiceImpl) SendExceptionNotification(ctx context.Context, exceptionID uint) error {
@@ -188,34 +253,7 @@ func (s *NotificationServiceImpl) SendTalentInvitationNotification(ctx context.C
 邀請碼：%s
 （如非本人，請忽略此訊息）`, centerName, s.buildInvitationLink(inviteToken), inviteToken)
 
-	// 建立通知記錄
-	notification := models.Notification{
-		UserID:    teacherID,
-		UserType:  "TEACHER",
-		Title:     title,
-		Message:   message,
-		Type:      "TALENT_INVITATION",
-		IsRead:    false,
-		CreatedAt: time.Now(),
-	}
-
-	_, err := s.notificationRepo.Create(ctx, notification)
-	if err != nil {
-		return err
-	}
-
-	// 取得老師資料
-	teacher, err := s.teacherRepo.GetByID(ctx, teacherID)
-	if err != nil {
-		return err
-	}
-
-	// 發送 LINE Notify（如果有的話）
-	if teacher.LineNotifyToken != "" {
-		go s.LINENotifyService.SendMessage(ctx, teacher.LineNotifyToken, title+"\n\n"+message)
-	}
-
-	return nil
+	return s.deliverTeacherNotification(ctx, teacherID, title, message, "TALENT_INVITATION", "\n\n")
 }
 
 // buildInvitationLink 建立邀請連結
@@ -0,0 +1,438 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+	"timeLedger/app"
+	"timeLedger/app/models"
+	"timeLedger/app/repositories"
+	"timeLedger/global/errInfos"
+	"timeLedger/libs"
+
+	"gorm.io/gorm"
+)
+
+// 通知派送後端
+const (
+	NotificationBackendDatabase = "database" // 僅由資料庫輪詢派送
+	NotificationBackendRedis    = "redis"    // Redis list，worker 以 BRPOP 取出
+	NotificationBackendAsynq    = "asynq"    // Asynq 任務
+)
+
+const (
+	// NotificationDispatchBatchSize 每次輪詢派送的通知數
+	NotificationDispatchBatchSize = 100
+	// notificationDeliveryLease 搶占後的保留時間，派送中途停止時通知會在期滿後重新派送
+	notificationDeliveryLease = 2 * time.Minute
+	// notificationBackendGrace 使用佇列後端時，資料庫輪詢只接手寫入超過此時間的通知（佇列遺失或重試）
+	notificationBackendGrace = time.Minute
+)
+
+// ErrNotificationRecipientUnavailable 收件者未綁定或已關閉通知，不需重試
+var ErrNotificationRecipientUnavailable = errors.New("notification recipient unavailable")
+
+// NotificationRetryPolicy 通知類型的重試策略（指數退避）
+type NotificationRetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// Backoff 第 attempts 次失敗後的等待時間
+func (p NotificationRetryPolicy) Backoff(attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+	delay := p.BaseDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= p.MaxDelay {
+			return p.MaxDelay
+		}
+	}
+	return delay
+}
+
+// defaultNotificationRetryPolicy 未指定類型的重試策略
+var defaultNotificationRetryPolicy = NotificationRetryPolicy{MaxAttempts: 5, BaseDelay: time.Minute, MaxDelay: time.Hour}
+
+// notificationRetryPolicies 各通知類型的重試策略
+var notificationRetryPolicies = map[string]NotificationRetryPolicy{
+	// 例外申請與審核結果需確保送達
+	models.NotificationTypeExceptionSubmit: {MaxAttempts: 8, BaseDelay: 30 * time.Second, MaxDelay: 30 * time.Minute},
+	models.NotificationTypeExceptionResult: {MaxAttempts: 8, BaseDelay: 30 * time.Second, MaxDelay: 30 * time.Minute},
+	// 歡迎訊息晚到意義不大
+	models.NotificationTypeWelcomeTeacher: {MaxAttempts: 3, BaseDelay: time.Minute, MaxDelay: 10 * time.Minute},
+	models.NotificationTypeWelcomeAdmin:   {MaxAttempts: 3, BaseDelay: time.Minute, MaxDelay: 10 * time.Minute},
+	// 上課提醒過時即無意義，短間隔快速放棄
+	models.NotificationEventReminder: {MaxAttempts: 4, BaseDelay: 15 * time.Second, MaxDelay: 2 * time.Minute},
+}
+
+// NotificationRetryPolicyFor 取得通知類型的重試策略
+func NotificationRetryPolicyFor(notificationType string) NotificationRetryPolicy {
+	if policy, ok := notificationRetryPolicies[notificationType]; ok {
+		return policy
+	}
+	return defaultNotificationRetryPolicy
+}
+
+// NotificationBackend 通知佇列後端，只負責把已寫入 notification_queues 的通知交給 worker
+type NotificationBackend interface {
+	Name() string
+	Enqueue(ctx context.Context, item *models.NotificationQueue) error
+}
+
+// DatabaseNotificationBackend 不經佇列，由資料庫輪詢派送
+type DatabaseNotificationBackend struct{}
+
+func (DatabaseNotificationBackend) Name() string { return NotificationBackendDatabase }
+
+func (DatabaseNotificationBackend) Enqueue(ctx context.Context, item *models.NotificationQueue) error {
+	return nil
+}
+
+// RedisNotificationBackend 推入 Redis list，由 NotificationQueueService.ProcessQueue 取出
+type RedisNotificationBackend struct {
+	queue *RedisQueueService
+}
+
+func (b *RedisNotificationBackend) Name() string { return NotificationBackendRedis }
+
+func (b *RedisNotificationBackend) Enqueue(ctx context.Context, item *models.NotificationQueue) error {
+	return b.queue.PushNotification(ctx, &NotificationItem{
+		ID:            item.ID,
+		Type:          item.Type,
+		RecipientID:   item.RecipientID,
+		RecipientType: item.RecipientType,
+		CreatedAt:     item.CreatedAt,
+	})
+}
+
+// AsynqNotificationBackend 以 Asynq 任務派送
+type AsynqNotificationBackend struct {
+	asynq *AsynqNotificationService
+}
+
+func (b *AsynqNotificationBackend) Name() string { return NotificationBackendAsynq }
+
+func (b *AsynqNotificationBackend) Enqueue(ctx context.Context, item *models.NotificationQueue) error {
+	return b.asynq.EnqueueDispatch(ctx, item.ID)
+}
+
+// NewNotificationBackend 依設定建立佇列後端，未知或缺少 Redis 時使用資料庫輪詢
+func NewNotificationBackend(app *app.App, name string) NotificationBackend {
+	if app.Redis == nil {
+		return DatabaseNotificationBackend{}
+	}
+	switch name {
+	case NotificationBackendRedis:
+		return &RedisNotificationBackend{queue: NewRedisQueueService(app)}
+	case NotificationBackendAsynq:
+		return &AsynqNotificationBackend{asynq: NewAsynqNotificationService(app, nil)}
+	default:
+		return DatabaseNotificationBackend{}
+	}
+}
+
+// NotificationSender 依管道發送已寫入 notification_queues 的通知
+type NotificationSender interface {
+	Send(ctx context.Context, item *models.NotificationQueue) error
+}
+
+// lineNotificationSender 以 LINE Push 發送，payload 為完整的 LINE 訊息物件
+type lineNotificationSender struct {
+	adminRepo      *repositories.AdminUserRepository
+	teacherRepo    *repositories.TeacherRepository
+	lineBotService LineBotService
+}
+
+func (s *lineNotificationSender) Send(ctx context.Context, item *models.NotificationQueue) error {
+	var message map[string]interface{}
+	if err := json.Unmarshal([]byte(item.Payload), &message); err != nil {
+		return fmt.Errorf("%w: invalid payload: %v", ErrNotificationRecipientUnavailable, err)
+	}
+
+	var lineUserID string
+	switch item.RecipientType {
+	case "ADMIN":
+		admin, err := s.adminRepo.GetByIDPtr(ctx, item.RecipientID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: admin %d not found", ErrNotificationRecipientUnavailable, item.RecipientID)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get admin: %w", err)
+		}
+		if item.Type == models.NotificationTypeExceptionSubmit && !admin.LineNotifyEnabled {
+			return fmt.Errorf("%w: admin %d disabled LINE notifications", ErrNotificationRecipientUnavailable, item.RecipientID)
+		}
+		lineUserID = admin.LineUserID
+	case "TEACHER":
+		teacher, err := s.teacherRepo.GetByID(ctx, item.RecipientID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: teacher %d not found", ErrNotificationRecipientUnavailable, item.RecipientID)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get teacher: %w", err)
+		}
+		lineUserID = teacher.LineUserID
+	default:
+		return fmt.Errorf("%w: unknown recipient type %s", ErrNotificationRecipientUnavailable, item.RecipientType)
+	}
+
+	if lineUserID == "" {
+		return fmt.Errorf("%w: %s %d not bound LINE", ErrNotificationRecipientUnavailable, item.RecipientType, item.RecipientID)
+	}
+	return s.lineBotService.PushMessage(ctx, lineUserID, message)
+}
+
+// emailNotificationSender 依 payload 重新產生郵件並發送
+type emailNotificationSender struct {
+	svc *EmailNotificationService
+}
+
+func (s *emailNotificationSender) Send(ctx context.Context, item *models.NotificationQueue) error {
+	return s.svc.deliverQueued(ctx, item)
+}
+
+// NotificationDispatcher 通知派送器
+// notification_queues 為唯一的真實來源：先寫入資料表再交給佇列後端，
+// 失敗依通知類型的策略退避重試，超過次數移入死信佇列供管理員重送或捨棄
+type NotificationDispatcher struct {
+	BaseService
+	backend   NotificationBackend
+	queueRepo *repositories.NotificationQueueRepository
+	senders   map[string]NotificationSender
+	grace     time.Duration
+}
+
+// NewNotificationDispatcher 建立通知派送器，後端依 NOTIFICATION_BACKEND 設定
+func NewNotificationDispatcher(app *app.App) *NotificationDispatcher {
+	backendName := ""
+	if app.Env != nil {
+		backendName = app.Env.NotificationBackend
+	}
+	return NewNotificationDispatcherWithBackend(app, NewNotificationBackend(app, backendName))
+}
+
+// NewNotificationDispatcherWithBackend 以指定的佇列後端建立通知派送器
+func NewNotificationDispatcherWithBackend(app *app.App, backend NotificationBackend) *NotificationDispatcher {
+	d := &NotificationDispatcher{
+		BaseService: *NewBaseService(app, "NotificationDispatcher"),
+		backend:     backend,
+		senders:     map[string]NotificationSender{},
+	}
+	if backend.Name() != NotificationBackendDatabase {
+		d.grace = notificationBackendGrace
+	}
+	if app.MySQL != nil {
+		d.queueRepo = repositories.NewNotificationQueueRepository(app)
+		d.senders[models.NotificationChannelLine] = &lineNotificationSender{
+			adminRepo:      repositories.NewAdminUserRepository(app),
+			teacherRepo:    repositories.NewTeacherRepository(app),
+			lineBotService: NewLineBotService(app),
+		}
+		d.senders[models.NotificationChannelEmail] = &emailNotificationSender{svc: NewEmailNotificationService(app)}
+	}
+	return d
+}
+
+// Backend 目前使用的佇列後端
+func (d *NotificationDispatcher) Backend() string {
+	return d.backend.Name()
+}
+
+// Dispatch 寫入通知並交給佇列後端；後端失敗時由資料庫輪詢接手
+func (d *NotificationDispatcher) Dispatch(ctx context.Context, item *models.NotificationQueue) error {
+	now := time.Now()
+	if item.Channel == "" {
+		item.Channel = models.NotificationChannelLine
+	}
+	if item.Status == "" {
+		item.Status = models.NotificationStatusPending
+	}
+	if item.ScheduledAt.IsZero() {
+		item.ScheduledAt = now
+	}
+	item.CreatedAt = now
+	item.UpdatedAt = now
+
+	created, err := d.queueRepo.Create(ctx, *item)
+	if err != nil {
+		return fmt.Errorf("failed to record notification: %w", err)
+	}
+	*item = created
+
+	d.Enqueue(ctx, item)
+	return nil
+}
+
+// Enqueue 將已寫入資料表的通知交給佇列後端（例如於交易內寫入、提交後才交付）
+// 尚未到預定時間的通知由資料庫輪詢於到期後派送
+func (d *NotificationDispatcher) Enqueue(ctx context.Context, item *models.NotificationQueue) {
+	if item.ScheduledAt.After(time.Now()) {
+		return
+	}
+	if err := d.backend.Enqueue(ctx, item); err != nil {
+		d.Logger.Warn("failed to enqueue notification, falling back to database polling",
+			"notification_id", item.ID, "backend", d.backend.Name(), "error", err)
+	}
+}
+
+// Deliver 派送單筆通知（由佇列 worker 或資料庫輪詢呼叫），已被其他 worker 搶占時略過
+func (d *NotificationDispatcher) Deliver(ctx context.Context, id uint) error {
+	now := time.Now()
+	claimed, err := d.queueRepo.Claim(ctx, id, now, now.Add(notificationDeliveryLease))
+	if err != nil || !claimed {
+		return err
+	}
+
+	item, err := d.queueRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	channel := item.Channel
+	if channel == "" {
+		channel = models.NotificationChannelLine
+	}
+	sender, ok := d.senders[channel]
+	if !ok {
+		return settleNotification(ctx, d.queueRepo, &item,
+			fmt.Errorf("%w: unsupported channel %s", ErrNotificationRecipientUnavailable, channel), time.Now())
+	}
+
+	sendErr := sender.Send(ctx, &item)
+	if sendErr != nil {
+		d.Logger.Warn("notification delivery failed",
+			"notification_id", item.ID, "type", item.Type, "channel", channel, "attempt", item.RetryCount+1, "error", sendErr)
+	}
+	return settleNotification(ctx, d.queueRepo, &item, sendErr, time.Now())
+}
+
+// RelayDue 派送到期的通知（首次派送或退避重試），回傳處理的筆數
+func (d *NotificationDispatcher) RelayDue(ctx context.Context, limit int) (int, error) {
+	now := time.Now()
+	items, err := d.queueRepo.ListDue(ctx, now, now.Add(-d.grace), limit)
+	if err != nil {
+		return 0, err
+	}
+	for i, item := range items {
+		if err := d.Deliver(ctx, item.ID); err != nil {
+			return i, err
+		}
+	}
+	return len(items), nil
+}
+
+// settleNotification 依發送結果更新通知狀態：成功、略過、退信、退避重試或移入死信佇列
+func settleNotification(ctx context.Context, queueRepo *repositories.NotificationQueueRepository, item *models.NotificationQueue, sendErr error, now time.Time) error {
+	switch {
+	case sendErr == nil:
+		return queueRepo.MarkSent(ctx, item.ID, now)
+	case errors.Is(sendErr, ErrNotificationRecipientUnavailable):
+		return queueRepo.MarkSkipped(ctx, item.ID, sendErr.Error(), now)
+	case libs.IsPermanentMailError(sendErr):
+		return queueRepo.MarkBounced(ctx, item.ID, sendErr.Error(), now)
+	}
+
+	attempts := item.RetryCount + 1
+	policy := NotificationRetryPolicyFor(item.Type)
+	if attempts >= policy.MaxAttempts {
+		return queueRepo.MarkDead(ctx, item.ID, attempts, models.NotificationStatusPending, sendErr.Error(), now)
+	}
+	return queueRepo.ScheduleRetry(ctx, item.ID, attempts, sendErr.Error(), now.Add(policy.Backoff(attempts)), now)
+}
+
+// NotificationDeadLetter 死信通知（含最後錯誤與原始內容）
+type NotificationDeadLetter struct {
+	models.NotificationQueue
+	LastError string          `json:"last_error"`
+	Payload   json.RawMessage `json:"payload,omitempty"`
+}
+
+func newNotificationDeadLetter(item models.NotificationQueue, withPayload bool) NotificationDeadLetter {
+	letter := NotificationDeadLetter{NotificationQueue: item, LastError: item.ErrorMsg}
+	if withPayload && json.Valid([]byte(item.Payload)) {
+		letter.Payload = json.RawMessage(item.Payload)
+	}
+	return letter
+}
+
+// ListDeadLetters 分頁取得中心的死信通知
+func (d *NotificationDispatcher) ListDeadLetters(ctx context.Context, centerID uint, notificationType, channel string, page, limit int) ([]NotificationDeadLetter, int64, *errInfos.Res, error) {
+	items, total, err := d.queueRepo.ListDeadLetters(ctx, centerID, notificationType, channel, page, limit)
+	if err != nil {
+		return nil, 0, d.App.Err.New(errInfos.SQL_ERROR), err
+	}
+	letters := make([]NotificationDeadLetter, 0, len(items))
+	for _, item := range items {
+		letters = append(letters, newNotificationDeadLetter(item, false))
+	}
+	return letters, total, nil, nil
+}
+
+// GetDeadLetter 取得單筆死信通知
+func (d *NotificationDispatcher) GetDeadLetter(ctx context.Context, centerID, id uint) (*NotificationDeadLetter, *errInfos.Res, error) {
+	item, err := d.queueRepo.First(ctx, "id = ? AND center_id = ? AND status = ?", id, centerID, models.NotificationStatusDead)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, d.App.Err.New(errInfos.NOTIFICATION_NOT_FOUND), err
+	}
+	if err != nil {
+		return nil, d.App.Err.New(errInfos.SQL_ERROR), err
+	}
+	letter := newNotificationDeadLetter(item, true)
+	return &letter, nil, nil
+}
+
+// ReplayDeadLetter 重送死信通知：重設重試次數並重新交給佇列後端（暫緩通知交回勿擾排程）
+func (d *NotificationDispatcher) ReplayDeadLetter(ctx context.Context, centerID, id uint) (*NotificationDeadLetter, *errInfos.Res, error) {
+	letter, errInfo, err := d.GetDeadLetter(ctx, centerID, id)
+	if err != nil {
+		return nil, errInfo, err
+	}
+
+	status := letter.ReplayStatus
+	if status == "" {
+		status = models.NotificationStatusPending
+	}
+	now := time.Now()
+	replayed, err := d.queueRepo.Replay(ctx, id, centerID, status, now)
+	if err != nil {
+		return nil, d.App.Err.New(errInfos.SQL_ERROR), err
+	}
+	if !replayed {
+		return nil, d.App.Err.New(errInfos.NOTIFICATION_NOT_FOUND), fmt.Errorf("notification %d is no longer in dead letter queue", id)
+	}
+
+	item := letter.NotificationQueue
+	item.Status = status
+	item.RetryCount = 0
+	item.ScheduledAt = now
+	item.DeadAt = nil
+	if status == models.NotificationStatusPending {
+		if err := d.backend.Enqueue(ctx, &item); err != nil {
+			d.Logger.Warn("failed to enqueue replayed notification, falling back to database polling",
+				"notification_id", item.ID, "backend", d.backend.Name(), "error", err)
+		}
+	}
+
+	d.Logger.Info("dead letter notification replayed", "notification_id", id, "center_id", centerID, "status", status)
+	replayedLetter := newNotificationDeadLetter(item, false)
+	return &replayedLetter, nil, nil
+}
+
+// DiscardDeadLetter 捨棄死信通知
+func (d *NotificationDispatcher) DiscardDeadLetter(ctx context.Context, centerID, id uint) (*errInfos.Res, error) {
+	discarded, err := d.queueRepo.Discard(ctx, id, centerID, time.Now())
+	if err != nil {
+		return d.App.Err.New(errInfos.SQL_ERROR), err
+	}
+	if !discarded {
+		return d.App.Err.New(errInfos.NOTIFICATION_NOT_FOUND), fmt.Errorf("dead letter notification %d not found", id)
+	}
+	d.Logger.Info("dead letter notification discarded", "notification_id", id, "center_id", centerID)
+	return nil, nil
+}
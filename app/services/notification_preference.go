@@ -0,0 +1,576 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"timeLedger/app"
+	"timeLedger/app/models"
+	"timeLedger/app/repositories"
+	"timeLedger/global/errInfos"
+
+	"gorm.io/gorm"
+)
+
+// 通知發送決策
+const (
+	NotificationDeliverNow    = "NOW"    // 立即發送
+	NotificationDeliverDefer  = "DEFER"  // 勿擾時段，延後至結束時發送
+	NotificationDeliverDigest = "DIGEST" // 併入每日彙整
+	NotificationDeliverSkip   = "SKIP"   // 使用者關閉此管道
+)
+
+const (
+	// HeldNotificationBatchSize 每次處理的暫緩通知筆數
+	HeldNotificationBatchSize = 200
+	// heldNotificationMaxRetry 暫緩通知發送失敗的最大重試次數
+	heldNotificationMaxRetry = 3
+	// heldNotificationRetryDelay 暫緩通知發送失敗後的重試間隔
+	heldNotificationRetryDelay = 5 * time.Minute
+)
+
+// NotificationDeliveryPlan 單一管道的發送決策
+type NotificationDeliveryPlan struct {
+	Action    string
+	DeliverAt time.Time // DEFER / DIGEST 時的預定發送時間
+}
+
+// Held 是否需暫存至排程發送
+func (p NotificationDeliveryPlan) Held() bool {
+	return p.Action == NotificationDeliverDefer || p.Action == NotificationDeliverDigest
+}
+
+// HeldNotificationPayload 暫緩通知的內容，到期後依管道發送
+type HeldNotificationPayload struct {
+	CenterID uint               `json:"-"` // 來源中心，寫入 notification_queues.center_id
+	Channel  string             `json:"channel"`
+	Title    string             `json:"title"`
+	Message  string             `json:"message,omitempty"`
+	AltText  string             `json:"alt_text,omitempty"`
+	Flex     json.RawMessage    `json:"flex,omitempty"`  // LINE Flex 內容，未提供時以文字發送
+	Email    *EmailTemplateData `json:"email,omitempty"` // Email 範本資料，未提供時以標題與內容產生
+}
+
+// Text 文字訊息內容
+func (p HeldNotificationPayload) Text() string {
+	if p.Message == "" {
+		return p.Title
+	}
+	return p.Title + "\n" + p.Message
+}
+
+// UpdateNotificationPreferenceRequest 更新通知偏好，未帶的欄位維持原設定
+type UpdateNotificationPreferenceRequest struct {
+	Channels          map[string][]string `json:"channels"`
+	QuietHoursEnabled *bool               `json:"quiet_hours_enabled"`
+	QuietHoursStart   *string             `json:"quiet_hours_start"`
+	QuietHoursEnd     *string             `json:"quiet_hours_end"`
+	Timezone          *string             `json:"timezone"`
+	DigestEnabled     *bool               `json:"digest_enabled"`
+	DigestTime        *string             `json:"digest_time"`
+	// ReminderOffsets 課前提醒提前分鐘數，空陣列表示不接收課前提醒
+	ReminderOffsets *[]int `json:"reminder_offsets"`
+	// ResetReminderOffsets 清除自訂課前提醒，改回沿用中心設定
+	ResetReminderOffsets bool `json:"reset_reminder_offsets"`
+}
+
+// DefaultNotificationPreference 未設定偏好時的預設值：所有事件透過 LINE 與站內通知
+func DefaultNotificationPreference(userType string, userID uint, timezone string) models.NotificationPreference {
+	if timezone == "" {
+		timezone = "Asia/Taipei"
+	}
+	pref := models.NotificationPreference{
+		UserType:        userType,
+		UserID:          userID,
+		Channels:        models.NotificationChannelMap{},
+		QuietHoursStart: "22:00",
+		QuietHoursEnd:   "08:00",
+		Timezone:        timezone,
+		DigestTime:      "08:00",
+	}
+	fillDefaultChannels(&pref)
+	return pref
+}
+
+// fillDefaultChannels 補上未設定的事件，回應中一律列出所有事件
+func fillDefaultChannels(pref *models.NotificationPreference) {
+	if pref.Channels == nil {
+		pref.Channels = models.NotificationChannelMap{}
+	}
+	for _, event := range models.NotificationEvents {
+		if _, ok := pref.Channels[event]; !ok {
+			pref.Channels[event] = []string{models.NotificationChannelLine, models.NotificationChannelInApp}
+		}
+	}
+}
+
+// PlanNotificationDelivery 依偏好決定事件在指定管道的發送方式
+// event 為空字串表示系統通知，不受偏好影響；站內通知與緊急通知不延後
+// 上課提醒具時效性，不併入每日彙整，但仍遵守勿擾時段
+func PlanNotificationDelivery(pref models.NotificationPreference, event, channel string, now time.Time, urgent bool) NotificationDeliveryPlan {
+	if event == "" {
+		return NotificationDeliveryPlan{Action: NotificationDeliverNow}
+	}
+	if !pref.ChannelEnabled(event, channel) {
+		return NotificationDeliveryPlan{Action: NotificationDeliverSkip}
+	}
+	if channel == models.NotificationChannelInApp || urgent {
+		return NotificationDeliveryPlan{Action: NotificationDeliverNow}
+	}
+	if pref.DigestEnabled && event != models.NotificationEventReminder {
+		if at, ok := nextDigestAt(pref, now); ok {
+			return NotificationDeliveryPlan{Action: NotificationDeliverDigest, DeliverAt: at}
+		}
+	}
+	if until, quiet := quietHoursUntil(pref, now); quiet {
+		return NotificationDeliveryPlan{Action: NotificationDeliverDefer, DeliverAt: until}
+	}
+	return NotificationDeliveryPlan{Action: NotificationDeliverNow}
+}
+
+// quietHoursUntil 目前位於勿擾時段時回傳結束時間（結束時間早於開始時間表示跨日）
+func quietHoursUntil(pref models.NotificationPreference, now time.Time) (time.Time, bool) {
+	if !pref.QuietHoursEnabled {
+		return time.Time{}, false
+	}
+	start, okStart := parseClockMinutes(pref.QuietHoursStart)
+	end, okEnd := parseClockMinutes(pref.QuietHoursEnd)
+	if !okStart || !okEnd || start == end {
+		return time.Time{}, false
+	}
+
+	local := now.In(preferenceLocation(pref))
+	minute := local.Hour()*60 + local.Minute()
+	endToday := time.Date(local.Year(), local.Month(), local.Day(), end/60, end%60, 0, 0, local.Location())
+
+	if start < end {
+		if minute >= start && minute < end {
+			return endToday, true
+		}
+		return time.Time{}, false
+	}
+	if minute >= start {
+		return endToday.AddDate(0, 0, 1), true
+	}
+	if minute < end {
+		return endToday, true
+	}
+	return time.Time{}, false
+}
+
+// nextDigestAt 下一次每日彙整的發送時間
+func nextDigestAt(pref models.NotificationPreference, now time.Time) (time.Time, bool) {
+	clock, ok := parseClockMinutes(pref.DigestTime)
+	if !ok {
+		return time.Time{}, false
+	}
+	local := now.In(preferenceLocation(pref))
+	at := time.Date(local.Year(), local.Month(), local.Day(), clock/60, clock%60, 0, 0, local.Location())
+	if !at.After(local) {
+		at = at.AddDate(0, 0, 1)
+	}
+	return at, true
+}
+
+func preferenceLocation(pref models.NotificationPreference) *time.Location {
+	if pref.Timezone != "" {
+		if loc, err := time.LoadLocation(pref.Timezone); err == nil {
+			return loc
+		}
+	}
+	return time.Local
+}
+
+// parseClockMinutes 解析 HH:MM，回傳當日分鐘數
+func parseClockMinutes(value string) (int, bool) {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, false
+	}
+	return t.Hour()*60 + t.Minute(), true
+}
+
+// NotificationPreferenceService 通知偏好中心
+type NotificationPreferenceService struct {
+	BaseService
+	app             *app.App
+	prefRepo        *repositories.NotificationPreferenceRepository
+	queueRepo       *repositories.NotificationQueueRepository
+	teacherRepo     *repositories.TeacherRepository
+	adminRepo       *repositories.AdminUserRepository
+	lineBotService  LineBotService
+	lineNotify      LINENotifyService
+	emailService    *EmailNotificationService
+	defaultTimezone string
+}
+
+// NewNotificationPreferenceService 建立通知偏好服務
+func NewNotificationPreferenceService(app *app.App) *NotificationPreferenceService {
+	svc := &NotificationPreferenceService{
+		BaseService: *NewBaseService(app, "NotificationPreferenceService"),
+		app:         app,
+		lineNotify:  NewLINENotifyService(app),
+	}
+	svc.emailService = NewEmailNotificationService(app)
+	if app.Env != nil {
+		svc.defaultTimezone = app.Env.AppTimezone
+	}
+	if app.MySQL != nil {
+		svc.prefRepo = repositories.NewNotificationPreferenceRepository(app)
+		svc.queueRepo = repositories.NewNotificationQueueRepository(app)
+		svc.teacherRepo = repositories.NewTeacherRepository(app)
+		svc.adminRepo = repositories.NewAdminUserRepository(app)
+		svc.lineBotService = NewLineBotService(app)
+	}
+	return svc
+}
+
+// GetPreference 取得使用者的通知偏好，尚未設定時回傳預設值
+func (s *NotificationPreferenceService) GetPreference(ctx context.Context, userType string, userID uint) (models.NotificationPreference, error) {
+	if s.prefRepo == nil {
+		return DefaultNotificationPreference(userType, userID, s.defaultTimezone), nil
+	}
+	pref, err := s.prefRepo.GetByUser(ctx, userType, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return DefaultNotificationPreference(userType, userID, s.defaultTimezone), nil
+	}
+	if err != nil {
+		return models.NotificationPreference{}, err
+	}
+	fillDefaultChannels(&pref)
+	return pref, nil
+}
+
+// GetPreferences 批次取得多位使用者的通知偏好（廣播使用）
+func (s *NotificationPreferenceService) GetPreferences(ctx context.Context, userType string, userIDs []uint) (map[uint]models.NotificationPreference, error) {
+	result := make(map[uint]models.NotificationPreference, len(userIDs))
+	stored := map[uint]models.NotificationPreference{}
+	if s.prefRepo != nil {
+		var err error
+		if stored, err = s.prefRepo.ListByUsers(ctx, userType, userIDs); err != nil {
+			return nil, err
+		}
+	}
+	for _, id := range userIDs {
+		pref, ok := stored[id]
+		if !ok {
+			pref = DefaultNotificationPreference(userType, id, s.defaultTimezone)
+		}
+		fillDefaultChannels(&pref)
+		result[id] = pref
+	}
+	return result, nil
+}
+
+// UpdatePreference 更新使用者的通知偏好
+func (s *NotificationPreferenceService) UpdatePreference(ctx context.Context, userType string, userID uint, req *UpdateNotificationPreferenceRequest) (*models.NotificationPreference, *errInfos.Res, error) {
+	pref, err := s.GetPreference(ctx, userType, userID)
+	if err != nil {
+		return nil, s.App.Err.New(errInfos.SQL_ERROR), err
+	}
+
+	if err := applyNotificationPreferenceUpdate(&pref, req); err != nil {
+		return nil, s.App.Err.New(errInfos.PARAMS_VALIDATE_ERROR), err
+	}
+
+	now := time.Now()
+	if pref.CreatedAt.IsZero() {
+		pref.CreatedAt = now
+	}
+	pref.UpdatedAt = now
+	if err := s.prefRepo.Save(ctx, &pref); err != nil {
+		return nil, s.App.Err.New(errInfos.SQL_ERROR), err
+	}
+
+	return &pref, nil, nil
+}
+
+// applyNotificationPreferenceUpdate 驗證並套用更新內容
+func applyNotificationPreferenceUpdate(pref *models.NotificationPreference, req *UpdateNotificationPreferenceRequest) error {
+	for event, channels := range req.Channels {
+		if !slices.Contains(models.NotificationEvents, event) {
+			return fmt.Errorf("unknown notification event: %s", event)
+		}
+		normalized := make([]string, 0, len(channels))
+		for _, channel := range channels {
+			if !slices.Contains(models.NotificationChannels, channel) {
+				return fmt.Errorf("unknown notification channel: %s", channel)
+			}
+			if !slices.Contains(normalized, channel) {
+				normalized = append(normalized, channel)
+			}
+		}
+		pref.Channels[event] = normalized
+	}
+
+	clocks := []struct {
+		value  *string
+		target *string
+		field  string
+	}{
+		{req.QuietHoursStart, &pref.QuietHoursStart, "quiet_hours_start"},
+		{req.QuietHoursEnd, &pref.QuietHoursEnd, "quiet_hours_end"},
+		{req.DigestTime, &pref.DigestTime, "digest_time"},
+	}
+	for _, c := range clocks {
+		if c.value == nil {
+			continue
+		}
+		minutes, ok := parseClockMinutes(*c.value)
+		if !ok {
+			return fmt.Errorf("%s must be HH:MM", c.field)
+		}
+		*c.target = fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+	}
+
+	if req.Timezone != nil {
+		if _, err := time.LoadLocation(*req.Timezone); err != nil || *req.Timezone == "" {
+			return fmt.Errorf("invalid timezone: %s", *req.Timezone)
+		}
+		pref.Timezone = *req.Timezone
+	}
+	if req.ResetReminderOffsets {
+		pref.ReminderOffsets = nil
+	} else if req.ReminderOffsets != nil {
+		offsets, err := NormalizeReminderOffsets(*req.ReminderOffsets)
+		if err != nil {
+			return err
+		}
+		pref.ReminderOffsets = offsets
+	}
+	if req.QuietHoursEnabled != nil {
+		pref.QuietHoursEnabled = *req.QuietHoursEnabled
+	}
+	if req.DigestEnabled != nil {
+		pref.DigestEnabled = *req.DigestEnabled
+	}
+	if pref.QuietHoursEnabled && pref.QuietHoursStart == pref.QuietHoursEnd {
+		return fmt.Errorf("quiet hours start and end must differ")
+	}
+	return nil
+}
+
+// PreferenceOrDefault 取得偏好供發送端判斷；讀取失敗時以預設值處理，不阻擋通知
+func (s *NotificationPreferenceService) PreferenceOrDefault(ctx context.Context, userType string, userID uint) models.NotificationPreference {
+	pref, err := s.GetPreference(ctx, userType, userID)
+	if err != nil {
+		s.Logger.Warn("failed to load notification preference, using defaults",
+			"user_type", userType, "user_id", userID, "error", err)
+		return DefaultNotificationPreference(userType, userID, s.defaultTimezone)
+	}
+	return pref
+}
+
+// Plan 依使用者偏好決定單一管道的發送方式
+func (s *NotificationPreferenceService) Plan(ctx context.Context, userType string, userID uint, event, channel string, urgent bool) NotificationDeliveryPlan {
+	return PlanNotificationDelivery(s.PreferenceOrDefault(ctx, userType, userID), event, channel, time.Now(), urgent)
+}
+
+// Hold 暫存延後或彙整的通知，由排程到期後發送
+func (s *NotificationPreferenceService) Hold(ctx context.Context, userType string, userID uint, event string, plan NotificationDeliveryPlan, payload HeldNotificationPayload) error {
+	status := models.NotificationStatusDeferred
+	if plan.Action == NotificationDeliverDigest {
+		status = models.NotificationStatusDigest
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal held notification: %w", err)
+	}
+
+	now := time.Now()
+	_, err = s.queueRepo.Create(ctx, models.NotificationQueue{
+		CenterID:      payload.CenterID,
+		Type:          event,
+		Channel:       payload.Channel,
+		RecipientID:   userID,
+		RecipientType: userType,
+		Payload:       string(body),
+		Status:        status,
+		ScheduledAt:   plan.DeliverAt,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	})
+	return err
+}
+
+// DeliverHeld 發送到期的延後通知與每日彙整，回傳發送的則數
+func (s *NotificationPreferenceService) DeliverHeld(ctx context.Context, now time.Time) (int, error) {
+	delivered := 0
+
+	deferred, err := s.queueRepo.ListHeldDue(ctx, models.NotificationStatusDeferred, now, HeldNotificationBatchSize)
+	if err != nil {
+		return 0, err
+	}
+	for _, item := range deferred {
+		claimed, err := s.queueRepo.ClaimHeld(ctx, item.ID, models.NotificationStatusDeferred, now)
+		if err != nil {
+			return delivered, err
+		}
+		if !claimed {
+			continue
+		}
+		var payload HeldNotificationPayload
+		if err := json.Unmarshal([]byte(item.Payload), &payload); err != nil {
+			s.releaseHeld(ctx, []uint{item.ID}, models.NotificationStatusDeferred, err, now)
+			continue
+		}
+		if err := s.deliverHeld(ctx, item.RecipientType, item.RecipientID, item.Type, payload); err != nil {
+			s.releaseHeld(ctx, []uint{item.ID}, models.NotificationStatusDeferred, err, now)
+			continue
+		}
+		delivered++
+	}
+
+	digests, err := s.queueRepo.ListHeldDue(ctx, models.NotificationStatusDigest, now, HeldNotificationBatchSize)
+	if err != nil {
+		return delivered, err
+	}
+	for _, group := range groupHeldByRecipient(digests) {
+		var ids []uint
+		var payloads []HeldNotificationPayload
+		for _, item := range group {
+			claimed, err := s.queueRepo.ClaimHeld(ctx, item.ID, models.NotificationStatusDigest, now)
+			if err != nil {
+				return delivered, err
+			}
+			if !claimed {
+				continue
+			}
+			var payload HeldNotificationPayload
+			if err := json.Unmarshal([]byte(item.Payload), &payload); err != nil {
+				s.releaseHeld(ctx, []uint{item.ID}, models.NotificationStatusDigest, err, now)
+				continue
+			}
+			ids = append(ids, item.ID)
+			payloads = append(payloads, payload)
+		}
+		if len(payloads) == 0 {
+			continue
+		}
+		title := fmt.Sprintf("📬 通知彙整（%d 則）", len(payloads))
+		digest := HeldNotificationPayload{
+			Channel: group[0].Channel,
+			Title:   title,
+			Message: BuildNotificationDigest(payloads),
+			Email:   &EmailTemplateData{Title: title, Items: notificationDigestItems(payloads)},
+		}
+		if err := s.deliverHeld(ctx, group[0].RecipientType, group[0].RecipientID, EmailTemplateDigest, digest); err != nil {
+			s.releaseHeld(ctx, ids, models.NotificationStatusDigest, err, now)
+			continue
+		}
+		delivered++
+	}
+
+	return delivered, nil
+}
+
+func (s *NotificationPreferenceService) releaseHeld(ctx context.Context, ids []uint, status string, cause error, now time.Time) {
+	s.Logger.Warn("held notification delivery failed", "ids", ids, "status", status, "error", cause)
+	if err := s.queueRepo.ReleaseHeld(ctx, ids, status, now.Add(heldNotificationRetryDelay), cause.Error(), heldNotificationMaxRetry, now); err != nil {
+		s.Logger.Error("failed to release held notification", "ids", ids, "error", err)
+	}
+}
+
+// deliverHeld 依管道發送暫緩通知，templateType 為 Email 使用的範本
+func (s *NotificationPreferenceService) deliverHeld(ctx context.Context, userType string, userID uint, templateType string, payload HeldNotificationPayload) error {
+	var lineUserID, notifyToken, email, name string
+	switch userType {
+	case "ADMIN":
+		admin, err := s.adminRepo.GetByIDPtr(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("failed to get admin: %w", err)
+		}
+		if admin.LineNotifyEnabled {
+			lineUserID = admin.LineUserID
+		}
+		email, name = admin.Email, admin.Name
+	case "TEACHER":
+		teacher, err := s.teacherRepo.GetByID(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("failed to get teacher: %w", err)
+		}
+		lineUserID = teacher.LineUserID
+		notifyToken = teacher.LineNotifyToken
+		email, name = teacher.Email, teacher.Name
+	default:
+		return fmt.Errorf("unknown recipient type: %s", userType)
+	}
+
+	switch payload.Channel {
+	case models.NotificationChannelLine:
+	case models.NotificationChannelEmail:
+		data := EmailTemplateData{Title: payload.Title, Message: payload.Message}
+		if payload.Email != nil {
+			data = *payload.Email
+		}
+		data.RecipientName = name
+		return s.emailService.Send(ctx, EmailNotification{
+			RecipientType: userType,
+			RecipientID:   userID,
+			Address:       email,
+			TemplateType:  emailTemplateTypeOf(templateType),
+			Data:          data,
+		})
+	default:
+		return fmt.Errorf("unsupported held notification channel: %s", payload.Channel)
+	}
+
+	switch {
+	case lineUserID != "" && len(payload.Flex) > 0:
+		altText := payload.AltText
+		if altText == "" {
+			altText = payload.Title
+		}
+		return s.lineBotService.PushFlexMessage(ctx, lineUserID, altText, payload.Flex)
+	case lineUserID != "":
+		return s.lineBotService.PushMessage(ctx, lineUserID, map[string]interface{}{
+			"type": "text",
+			"text": payload.Text(),
+		})
+	case notifyToken != "":
+		return s.lineNotify.SendMessage(ctx, notifyToken, payload.Text())
+	}
+	// 已解除綁定或關閉 LINE 通知，不再重試
+	return nil
+}
+
+// BuildNotificationDigest 組合每日彙整內容
+func BuildNotificationDigest(payloads []HeldNotificationPayload) string {
+	items := notificationDigestItems(payloads)
+	for i, item := range items {
+		items[i] = "• " + item
+	}
+	return strings.Join(items, "\n")
+}
+
+func notificationDigestItems(payloads []HeldNotificationPayload) []string {
+	items := make([]string, 0, len(payloads))
+	for _, p := range payloads {
+		title := p.Title
+		if title == "" {
+			title = p.AltText
+		}
+		items = append(items, title)
+	}
+	return items
+}
+
+// groupHeldByRecipient 依收件者與管道分組（輸入已依收件者與管道排序）
+func groupHeldByRecipient(items []models.NotificationQueue) [][]models.NotificationQueue {
+	var groups [][]models.NotificationQueue
+	for i, item := range items {
+		prev := models.NotificationQueue{}
+		if i > 0 {
+			prev = items[i-1]
+		}
+		if i == 0 || item.RecipientType != prev.RecipientType || item.RecipientID != prev.RecipientID || item.Channel != prev.Channel {
+			groups = append(groups, nil)
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], item)
+	}
+	return groups
+}
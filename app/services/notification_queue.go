@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 	"timeLedger/app"
@@ -13,6 +14,9 @@ import (
 
 // NotificationQueueService 通知佇列服務
 type NotificationQueueService interface {
+	// 通知派送（寫入 notification_queues 後交給設定的佇列後端）
+	Dispatch(ctx context.Context, item *models.NotificationQueue) error
+
 	// 佇列管理（Redis）
 	PushNotification(ctx context.Context, item *models.NotificationQueue) error
 	ProcessQueue(ctx context.Context) error
@@ -26,14 +30,25 @@ type NotificationQueueService interface {
 	NotifyExceptionResult(ctx context.Context, exception *models.ScheduleException, teacher *models.Teacher, approved bool, reason string) error
 
 	// 同步發送方法（直接發送，不經佇列）
-	NotifyExceptionSubmittedSync(ctx context.Context, exception *models.ScheduleException, teacherName string, centerName string) error
-	NotifyExceptionResultSync(ctx context.Context, exception *models.ScheduleException, teacher *models.Teacher, approved bool, reason string) error
+	NotifyExceptionSubmittedSync(ctx context.Context, exception *models.ScheduleException, teacherName string, centerName string, once DeliveryOnce) error
+	NotifyExceptionResultSync(ctx context.Context, exception *models.ScheduleException, teacher *models.Teacher, approved bool, reason string, once DeliveryOnce) error
 
 	// 便捷方法 - 發送歡迎訊息
 	NotifyWelcomeTeacher(ctx context.Context, teacher *models.Teacher, centerName string) error
 	NotifyWelcomeAdmin(ctx context.Context, admin *models.AdminUser, centerName string) error
 }
 
+// DeliveryOnce 依收件者去重發送：已送達的收件者略過，send 失敗時釋放以便重試
+type DeliveryOnce func(ctx context.Context, recipient string, send func() error) error
+
+// Do 未設定去重時直接發送
+func (once DeliveryOnce) Do(ctx context.Context, recipient string, send func() error) error {
+	if once == nil {
+		return send()
+	}
+	return once(ctx, recipient, send)
+}
+
 // NotificationQueueServiceImpl 通知佇列服務實現
 type NotificationQueueServiceImpl struct {
 	BaseService
@@ -42,8 +57,12 @@ type NotificationQueueServiceImpl struct {
 	teacherRepo     *repositories.TeacherRepository
 	lineBotService  LineBotService
 	templateService LineBotTemplateService
+	templates       *NotificationTemplateService
 	redisQueue      *RedisQueueService
 	asynqService    *AsynqNotificationService
+	preferenceSvc   *NotificationPreferenceService
+	dispatcher      *NotificationDispatcher
+	emailSvc        *EmailNotificationService
 	log             *logger.Logger
 }
 
@@ -59,8 +78,9 @@ func NewNotificationQueueService(app *app.App) NotificationQueueService {
 	log = logger.GetLogger()
 
 	svc := &NotificationQueueServiceImpl{
-		app: app,
-		log: log,
+		app:      app,
+		log:      log,
+		emailSvc: NewEmailNotificationService(app),
 	}
 
 	if app.Env != nil {
@@ -71,6 +91,9 @@ func NewNotificationQueueService(app *app.App) NotificationQueueService {
 		svc.adminRepo = repositories.NewAdminUserRepository(app)
 		svc.teacherRepo = repositories.NewTeacherRepository(app)
 		svc.lineBotService = NewLineBotService(app)
+		svc.templates = NewNotificationTemplateService(app)
+		svc.preferenceSvc = NewNotificationPreferenceService(app)
+		svc.dispatcher = NewNotificationDispatcher(app)
 	}
 
 	if app.Redis != nil {
@@ -105,24 +128,124 @@ func (s *NotificationQueueServiceImpl) logError(msg string, keysAndValues ...int
 	}
 }
 
-// PushToAsynq 將通知加入 Asynq 佇列（異步處理）
-func (s *NotificationQueueServiceImpl) PushToAsynq(ctx context.Context, item *models.NotificationQueue) error {
-	return s.asynqService.EnqueueNotification(ctx, item)
+// planLine 依收件者的通知偏好決定 LINE 的發送方式
+func (s *NotificationQueueServiceImpl) planLine(ctx context.Context, userType string, userID uint, event string) NotificationDeliveryPlan {
+	if s.preferenceSvc == nil {
+		return NotificationDeliveryPlan{Action: NotificationDeliverNow}
+	}
+	return s.preferenceSvc.Plan(ctx, userType, userID, event, models.NotificationChannelLine, false)
 }
 
-// PushNotification 將通知加入 Redis 佇列（保留向後兼容）
-func (s *NotificationQueueServiceImpl) PushNotification(ctx context.Context, item *models.NotificationQueue) error {
-	queueItem := &NotificationItem{
-		ID:            item.ID,
-		Type:          item.Type,
-		RecipientID:   item.RecipientID,
-		RecipientType: item.RecipientType,
-		Payload:       item.Payload,
-		CreatedAt:     item.ScheduledAt,
-		RetryCount:    0,
+// holdLine 暫存勿擾時段或每日彙整的 LINE Flex 通知
+func (s *NotificationQueueServiceImpl) holdLine(ctx context.Context, centerID uint, userType string, userID uint, event string, plan NotificationDeliveryPlan, altText string, flexContent interface{}) error {
+	flex, err := json.Marshal(flexContent)
+	if err != nil {
+		return fmt.Errorf("failed to marshal flex content: %w", err)
+	}
+	return s.preferenceSvc.Hold(ctx, userType, userID, event, plan, HeldNotificationPayload{
+		CenterID: centerID,
+		Channel:  models.NotificationChannelLine,
+		Title:    altText,
+		AltText:  altText,
+		Flex:     flex,
+	})
+}
+
+// notifyEmail 依收件者的 Email 通知偏好發送、暫存或略過；sync 為 true 時同步發送並回傳錯誤
+func (s *NotificationQueueServiceImpl) notifyEmail(ctx context.Context, centerID uint, userType string, userID uint, address string, event string, data EmailTemplateData, sync bool) error {
+	if address == "" || !s.emailSvc.Enabled() || s.preferenceSvc == nil {
+		return nil
+	}
+
+	plan := s.preferenceSvc.Plan(ctx, userType, userID, event, models.NotificationChannelEmail, false)
+	switch {
+	case plan.Action == NotificationDeliverSkip:
+		return nil
+	case plan.Held():
+		return s.preferenceSvc.Hold(ctx, userType, userID, event, plan, HeldNotificationPayload{
+			CenterID: centerID,
+			Channel:  models.NotificationChannelEmail,
+			Title:    data.Title,
+			Message:  data.Message,
+			Email:    &data,
+		})
+	}
+
+	notification := EmailNotification{
+		CenterID:      centerID,
+		RecipientType: userType,
+		RecipientID:   userID,
+		Address:       address,
+		TemplateType:  event,
+		Data:          data,
+	}
+	if sync {
+		return s.emailSvc.Send(ctx, notification)
+	}
+	s.emailSvc.SendAsync(ctx, notification)
+	return nil
+}
+
+// frontendURL 組合前端網址
+func (s *NotificationQueueServiceImpl) frontendURL(format string, args ...interface{}) string {
+	if s.app == nil || s.app.Env == nil || s.app.Env.FrontendBaseURL == "" {
+		return ""
+	}
+	return s.app.Env.FrontendBaseURL + fmt.Sprintf(format, args...)
+}
+
+// exceptionSubmitEmail 例外申請通知的郵件內容
+func (s *NotificationQueueServiceImpl) exceptionSubmitEmail(exception *models.ScheduleException, teacherName, centerName, recipientName string) EmailTemplateData {
+	return EmailTemplateData{
+		RecipientName: recipientName,
+		CenterName:    centerName,
+		Title:         fmt.Sprintf("新的例外申請 - %s 老師", teacherName),
+		Details: []EmailDetail{
+			{Label: "申請人", Value: teacherName},
+			{Label: "類型", Value: exception.ExceptionType},
+			{Label: "日期", Value: exception.GetDate().Format("2006/01/02")},
+			{Label: "時間", Value: exception.GetTimeRange()},
+			{Label: "原因", Value: exception.Reason},
+		},
+		ActionLabel: "前往審核",
+		ActionURL:   s.frontendURL("/admin/exceptions/%d", exception.ID),
+	}
+}
+
+// exceptionResultEmail 例外審核結果的郵件內容
+func (s *NotificationQueueServiceImpl) exceptionResultEmail(exception *models.ScheduleException, teacher *models.Teacher, approved bool, reason string) EmailTemplateData {
+	data := EmailTemplateData{
+		RecipientName: teacher.Name,
+		Details: []EmailDetail{
+			{Label: "日期", Value: exception.GetDate().Format("2006/01/02")},
+			{Label: "時間", Value: exception.GetTimeRange()},
+		},
+		ActionURL: s.frontendURL("/teacher/exceptions/%d", exception.ID),
+	}
+	if approved {
+		data.Title = fmt.Sprintf("✅ 您的例外申請已核准 - %s", exception.GetDate().Format("2006/01/02"))
+	} else {
+		data.Title = fmt.Sprintf("❌ 您的例外申請已拒絕 - %s", exception.GetDate().Format("2006/01/02"))
+		if reason != "" {
+			data.Details = append(data.Details, EmailDetail{Label: "拒絕原因", Value: reason})
+		}
 	}
+	return data
+}
 
-	return s.redisQueue.PushNotification(ctx, queueItem)
+// Dispatch 寫入通知並交給通知派送器，失敗依類型策略重試，超過次數移入死信佇列
+func (s *NotificationQueueServiceImpl) Dispatch(ctx context.Context, item *models.NotificationQueue) error {
+	return s.dispatcher.Dispatch(ctx, item)
+}
+
+// PushToAsynq 交給通知派送器（保留向後兼容，後端依 NOTIFICATION_BACKEND 設定）
+func (s *NotificationQueueServiceImpl) PushToAsynq(ctx context.Context, item *models.NotificationQueue) error {
+	return s.Dispatch(ctx, item)
+}
+
+// PushNotification 交給通知派送器（保留向後兼容，後端依 NOTIFICATION_BACKEND 設定）
+func (s *NotificationQueueServiceImpl) PushNotification(ctx context.Context, item *models.NotificationQueue) error {
+	return s.Dispatch(ctx, item)
 }
 
 // ProcessQueue 處理佇列（從 Redis 取出並發送）
@@ -147,8 +270,13 @@ func (s *NotificationQueueServiceImpl) ProcessQueue(ctx context.Context) error {
 				return nil
 			}
 
-			// 處理通知
-			if err := s.processRedisNotification(ctx, item); err != nil {
+			// 處理通知（派送器寫入的項目只帶 ID，舊格式直接發送 payload）
+			if item.ID != 0 && s.dispatcher != nil {
+				err = s.dispatcher.Deliver(ctx, item.ID)
+			} else {
+				err = s.processRedisNotification(ctx, item)
+			}
+			if err != nil {
 				s.logError("Failed to process notification",
 					"notification_id", item.ID,
 					"type", item.Type,
@@ -201,7 +329,7 @@ func (s *NotificationQueueServiceImpl) processRedisNotification(ctx context.Cont
 	return s.lineBotService.PushMessage(ctx, lineUserID, payload)
 }
 
-// GetQueueStats 取得佇列統計（包含 Redis 和 Asynq）
+// GetQueueStats 取得佇列統計（包含 Redis、Asynq 與 notification_queues 各狀態筆數）
 func (s *NotificationQueueServiceImpl) GetQueueStats(ctx context.Context) map[string]string {
 	stats := s.redisQueue.GetStats(ctx)
 
@@ -211,10 +339,21 @@ func (s *NotificationQueueServiceImpl) GetQueueStats(ctx context.Context) map[st
 		stats["asynq_"+k] = v
 	}
 
+	if s.dispatcher != nil {
+		stats["backend"] = s.dispatcher.Backend()
+		counts, err := s.dispatcher.queueRepo.CountByStatus(ctx)
+		if err != nil {
+			s.logError("Failed to count notifications by status", "error", err)
+		}
+		for status, count := range counts {
+			stats["db_"+status] = fmt.Sprintf("%d", count)
+		}
+	}
+
 	return stats
 }
 
-// NotifyExceptionSubmitted 通知管理員有新的例外申請（使用 Asynq 異步處理）
+// NotifyExceptionSubmitted 通知管理員有新的例外申請（經通知派送器異步處理）
 func (s *NotificationQueueServiceImpl) NotifyExceptionSubmitted(ctx context.Context, exception *models.ScheduleException, teacherName string, centerName string) error {
 	// 取得中心的所有管理員
 	admins, err := s.adminRepo.GetByCenterID(ctx, exception.CenterID)
@@ -223,20 +362,46 @@ func (s *NotificationQueueServiceImpl) NotifyExceptionSubmitted(ctx context.Cont
 	}
 
 	// 建立 Flex Message 範本
-	flexContent := s.templateService.GetExceptionSubmitTemplate(exception, teacherName, centerName)
+	templates := s.lineTemplates(ctx, exception.CenterID)
+	flexContent := templates.GetExceptionSubmitTemplate(exception, teacherName, centerName)
+	altText := templates.GetText(models.NotificationTemplateExceptionSubmit, "alt_text",
+		templates.ExceptionVariables(exception, teacherName, centerName, exception.Reason))
 	payload, _ := json.Marshal(map[string]interface{}{
 		"type":     "flex",
-		"altText":  fmt.Sprintf("新的例外申請 - %s 老師", teacherName),
+		"altText":  altText,
 		"contents": flexContent,
 	})
 
-	// 為每個已綁定的管理員加入 Asynq 佇列
+	// 為每個已綁定的管理員交給通知派送器（依通知偏好略過或延後）
 	for _, admin := range admins {
+		if err := s.notifyEmail(ctx, exception.CenterID, "ADMIN", admin.ID, admin.Email, models.NotificationEventExceptionSubmit,
+			s.exceptionSubmitEmail(exception, teacherName, centerName, admin.Name), false); err != nil {
+			s.logError("Failed to notify admin by email",
+				"admin_id", admin.ID,
+				"error", err,
+			)
+		}
+
 		if !admin.LineNotifyEnabled || admin.LineUserID == "" {
 			continue
 		}
 
+		plan := s.planLine(ctx, "ADMIN", admin.ID, models.NotificationEventExceptionSubmit)
+		if plan.Action == NotificationDeliverSkip {
+			continue
+		}
+		if plan.Held() {
+			if err := s.holdLine(ctx, exception.CenterID, "ADMIN", admin.ID, models.NotificationEventExceptionSubmit, plan, altText, flexContent); err != nil {
+				s.logError("Failed to hold notification for admin",
+					"admin_id", admin.ID,
+					"error", err,
+				)
+			}
+			continue
+		}
+
 		queueItem := &models.NotificationQueue{
+			CenterID:      exception.CenterID,
 			Type:          models.NotificationTypeExceptionSubmit,
 			RecipientID:   admin.ID,
 			RecipientType: "ADMIN",
@@ -245,8 +410,8 @@ func (s *NotificationQueueServiceImpl) NotifyExceptionSubmitted(ctx context.Cont
 			ScheduledAt:   time.Now(),
 		}
 
-		if err := s.PushToAsynq(ctx, queueItem); err != nil {
-			s.logError("Failed to enqueue notification to Asynq for admin",
+		if err := s.Dispatch(ctx, queueItem); err != nil {
+			s.logError("Failed to dispatch notification for admin",
 				"admin_id", admin.ID,
 				"error", err,
 			)
@@ -257,7 +422,8 @@ func (s *NotificationQueueServiceImpl) NotifyExceptionSubmitted(ctx context.Cont
 }
 
 // NotifyExceptionSubmittedSync 同步發送例外申請通知給所有管理員（直接發送，不經佇列）
-func (s *NotificationQueueServiceImpl) NotifyExceptionSubmittedSync(ctx context.Context, exception *models.ScheduleException, teacherName string, centerName string) error {
+// 個別管理員發送失敗時記錄並繼續，回傳合併的錯誤供重試；已送達者由 once 略過
+func (s *NotificationQueueServiceImpl) NotifyExceptionSubmittedSync(ctx context.Context, exception *models.ScheduleException, teacherName string, centerName string, once DeliveryOnce) error {
 	// 取得中心的所有管理員
 	admins, err := s.adminRepo.GetByCenterID(ctx, exception.CenterID)
 	if err != nil {
@@ -265,25 +431,60 @@ func (s *NotificationQueueServiceImpl) NotifyExceptionSubmittedSync(ctx context.
 	}
 
 	// 建立 Flex Message 範本
-	flexContent := s.templateService.GetExceptionSubmitTemplate(exception, teacherName, centerName)
-	altText := fmt.Sprintf("新的例外申請 - %s 老師", teacherName)
+	templates := s.lineTemplates(ctx, exception.CenterID)
+	flexContent := templates.GetExceptionSubmitTemplate(exception, teacherName, centerName)
+	altText := templates.GetText(models.NotificationTemplateExceptionSubmit, "alt_text",
+		templates.ExceptionVariables(exception, teacherName, centerName, exception.Reason))
 
-	// 直接發送給每個已綁定的管理員
+	// 直接發送給每個已綁定的管理員（依通知偏好略過或延後）
+	var errs []error
 	for _, admin := range admins {
+		if err := once.Do(ctx, fmt.Sprintf("admin:%d:email", admin.ID), func() error {
+			return s.notifyEmail(ctx, exception.CenterID, "ADMIN", admin.ID, admin.Email, models.NotificationEventExceptionSubmit,
+				s.exceptionSubmitEmail(exception, teacherName, centerName, admin.Name), true)
+		}); err != nil {
+			s.logError("Failed to email admin",
+				"admin_id", admin.ID,
+				"error", err,
+			)
+			errs = append(errs, fmt.Errorf("failed to email admin %d: %w", admin.ID, err))
+		}
+
 		if !admin.LineNotifyEnabled || admin.LineUserID == "" {
 			continue
 		}
 
-		if err := s.lineBotService.PushFlexMessage(ctx, admin.LineUserID, altText, flexContent); err != nil {
-			return fmt.Errorf("failed to send to admin %d: %w", admin.ID, err)
+		plan := s.planLine(ctx, "ADMIN", admin.ID, models.NotificationEventExceptionSubmit)
+		if plan.Action == NotificationDeliverSkip {
+			continue
+		}
+		if err := once.Do(ctx, fmt.Sprintf("admin:%d:line", admin.ID), func() error {
+			if plan.Held() {
+				return s.holdLine(ctx, exception.CenterID, "ADMIN", admin.ID, models.NotificationEventExceptionSubmit, plan, altText, flexContent)
+			}
+			return s.lineBotService.PushFlexMessage(ctx, admin.LineUserID, altText, flexContent)
+		}); err != nil {
+			s.logError("Failed to send LINE notification to admin",
+				"admin_id", admin.ID,
+				"error", err,
+			)
+			errs = append(errs, fmt.Errorf("failed to send to admin %d: %w", admin.ID, err))
 		}
 	}
 
-	return nil
+	return errors.Join(errs...)
 }
 
-// NotifyExceptionResult 通知老師例外審核結果（使用 Asynq 異步處理）
+// NotifyExceptionResult 通知老師例外審核結果（經通知派送器異步處理）
 func (s *NotificationQueueServiceImpl) NotifyExceptionResult(ctx context.Context, exception *models.ScheduleException, teacher *models.Teacher, approved bool, reason string) error {
+	if err := s.notifyEmail(ctx, exception.CenterID, "TEACHER", teacher.ID, teacher.Email, models.NotificationEventExceptionResult,
+		s.exceptionResultEmail(exception, teacher, approved, reason), false); err != nil {
+		s.logError("Failed to notify teacher by email",
+			"teacher_id", teacher.ID,
+			"error", err,
+		)
+	}
+
 	if teacher.LineUserID == "" {
 		return nil
 	}
@@ -291,12 +492,22 @@ func (s *NotificationQueueServiceImpl) NotifyExceptionResult(ctx context.Context
 	var flexContent interface{}
 	var altText string
 
+	templates := s.lineTemplates(ctx, exception.CenterID)
+	vars := templates.ExceptionVariables(exception, teacher.Name, "", reason)
 	if approved {
-		flexContent = s.templateService.GetExceptionApproveTemplate(exception, teacher.Name)
-		altText = fmt.Sprintf("✅ 您的例外申請已核准 - %s", exception.GetDate().Format("2006/01/02"))
+		flexContent = templates.GetExceptionApproveTemplate(exception, teacher.Name)
+		altText = templates.GetText(models.NotificationTemplateExceptionApproved, "alt_text", vars)
 	} else {
-		flexContent = s.templateService.GetExceptionRejectTemplate(exception, teacher.Name, reason)
-		altText = fmt.Sprintf("❌ 您的例外申請已拒絕 - %s", exception.GetDate().Format("2006/01/02"))
+		flexContent = templates.GetExceptionRejectTemplate(exception, teacher.Name, reason)
+		altText = templates.GetText(models.NotificationTemplateExceptionRejected, "alt_text", vars)
+	}
+
+	plan := s.planLine(ctx, "TEACHER", teacher.ID, models.NotificationEventExceptionResult)
+	if plan.Action == NotificationDeliverSkip {
+		return nil
+	}
+	if plan.Held() {
+		return s.holdLine(ctx, exception.CenterID, "TEACHER", teacher.ID, models.NotificationEventExceptionResult, plan, altText, flexContent)
 	}
 
 	payload, _ := json.Marshal(map[string]interface{}{
@@ -306,6 +517,7 @@ func (s *NotificationQueueServiceImpl) NotifyExceptionResult(ctx context.Context
 	})
 
 	queueItem := &models.NotificationQueue{
+		CenterID:      exception.CenterID,
 		Type:          models.NotificationTypeExceptionResult,
 		RecipientID:   teacher.ID,
 		RecipientType: "TEACHER",
@@ -314,31 +526,54 @@ func (s *NotificationQueueServiceImpl) NotifyExceptionResult(ctx context.Context
 		ScheduledAt:   time.Now(),
 	}
 
-	return s.PushToAsynq(ctx, queueItem)
+	return s.Dispatch(ctx, queueItem)
 }
 
 // NotifyExceptionResultSync 同步發送例外審核結果給老師（直接發送，不經佇列）
-func (s *NotificationQueueServiceImpl) NotifyExceptionResultSync(ctx context.Context, exception *models.ScheduleException, teacher *models.Teacher, approved bool, reason string) error {
+// Email 與 LINE 各自去重，其中一個失敗不影響另一個
+func (s *NotificationQueueServiceImpl) NotifyExceptionResultSync(ctx context.Context, exception *models.ScheduleException, teacher *models.Teacher, approved bool, reason string, once DeliveryOnce) error {
+	var errs []error
+	if err := once.Do(ctx, fmt.Sprintf("teacher:%d:email", teacher.ID), func() error {
+		return s.notifyEmail(ctx, exception.CenterID, "TEACHER", teacher.ID, teacher.Email, models.NotificationEventExceptionResult,
+			s.exceptionResultEmail(exception, teacher, approved, reason), true)
+	}); err != nil {
+		errs = append(errs, fmt.Errorf("failed to email teacher %d: %w", teacher.ID, err))
+	}
+
 	if teacher.LineUserID == "" {
-		return nil
+		return errors.Join(errs...)
 	}
 
 	var flexContent interface{}
 	var altText string
 
+	templates := s.lineTemplates(ctx, exception.CenterID)
+	vars := templates.ExceptionVariables(exception, teacher.Name, "", reason)
 	if approved {
-		flexContent = s.templateService.GetExceptionApproveTemplate(exception, teacher.Name)
-		altText = fmt.Sprintf("✅ 您的例外申請已核准 - %s", exception.GetDate().Format("2006/01/02"))
+		flexContent = templates.GetExceptionApproveTemplate(exception, teacher.Name)
+		altText = templates.GetText(models.NotificationTemplateExceptionApproved, "alt_text", vars)
 	} else {
-		flexContent = s.templateService.GetExceptionRejectTemplate(exception, teacher.Name, reason)
-		altText = fmt.Sprintf("❌ 您的例外申請已拒絕 - %s", exception.GetDate().Format("2006/01/02"))
+		flexContent = templates.GetExceptionRejectTemplate(exception, teacher.Name, reason)
+		altText = templates.GetText(models.NotificationTemplateExceptionRejected, "alt_text", vars)
 	}
 
-	// 直接發送給老師
-	return s.lineBotService.PushFlexMessage(ctx, teacher.LineUserID, altText, flexContent)
+	plan := s.planLine(ctx, "TEACHER", teacher.ID, models.NotificationEventExceptionResult)
+	if plan.Action == NotificationDeliverSkip {
+		return errors.Join(errs...)
+	}
+	if err := once.Do(ctx, fmt.Sprintf("teacher:%d:line", teacher.ID), func() error {
+		if plan.Held() {
+			return s.holdLine(ctx, exception.CenterID, "TEACHER", teacher.ID, models.NotificationEventExceptionResult, plan, altText, flexContent)
+		}
+		// 直接發送給老師
+		return s.lineBotService.PushFlexMessage(ctx, teacher.LineUserID, altText, flexContent)
+	}); err != nil {
+		errs = append(errs, fmt.Errorf("failed to send to teacher %d: %w", teacher.ID, err))
+	}
+	return errors.Join(errs...)
 }
 
-// NotifyWelcomeTeacher 發送老師歡迎訊息（使用 Asynq 異步處理）
+// NotifyWelcomeTeacher 發送老師歡迎訊息（經通知派送器異步處理）
 func (s *NotificationQueueServiceImpl) NotifyWelcomeTeacher(ctx context.Context, teacher *models.Teacher, centerName string) error {
 	if teacher.LineUserID == "" {
 		return nil
@@ -360,23 +595,26 @@ func (s *NotificationQueueServiceImpl) NotifyWelcomeTeacher(ctx context.Context,
 		ScheduledAt:   time.Now(),
 	}
 
-	return s.PushToAsynq(ctx, queueItem)
+	return s.Dispatch(ctx, queueItem)
 }
 
-// NotifyWelcomeAdmin 發送管理員歡迎訊息（使用 Asynq 異步處理）
+// NotifyWelcomeAdmin 發送管理員歡迎訊息（經通知派送器異步處理）
 func (s *NotificationQueueServiceImpl) NotifyWelcomeAdmin(ctx context.Context, admin *models.AdminUser, centerName string) error {
 	if admin.LineUserID == "" {
 		return nil
 	}
 
-	flexContent := s.templateService.GetWelcomeAdminTemplate(admin, centerName)
+	templates := s.lineTemplates(ctx, admin.CenterID)
+	flexContent := templates.GetWelcomeAdminTemplate(admin, centerName)
 	payload, _ := json.Marshal(map[string]interface{}{
-		"type":     "flex",
-		"altText":  "🎉 歡迎使用 TimeLedger！",
+		"type": "flex",
+		"altText": templates.GetText(models.NotificationTemplateWelcomeAdmin, "alt_text",
+			map[string]string{"admin_name": admin.Name, "center_name": centerName}),
 		"contents": flexContent,
 	})
 
 	queueItem := &models.NotificationQueue{
+		CenterID:      admin.CenterID,
 		Type:          models.NotificationTypeWelcomeAdmin,
 		RecipientID:   admin.ID,
 		RecipientType: "ADMIN",
@@ -385,7 +623,15 @@ func (s *NotificationQueueServiceImpl) NotifyWelcomeAdmin(ctx context.Context, a
 		ScheduledAt:   time.Now(),
 	}
 
-	return s.PushToAsynq(ctx, queueItem)
+	return s.Dispatch(ctx, queueItem)
+}
+
+// lineTemplates 取得套用中心語系與自訂內容的 Flex Message 範本
+func (s *NotificationQueueServiceImpl) lineTemplates(ctx context.Context, centerID uint) LineBotTemplateService {
+	if s.templates == nil {
+		return s.templateService
+	}
+	return s.templates.LineTemplates(ctx, centerID)
 }
 
 // ProcessQueueHandler 處理佇列的定時任務（可由 cron 或 worker 呼叫）
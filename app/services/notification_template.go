@@ -0,0 +1,656 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"timeLedger/app"
+	"timeLedger/app/models"
+	"timeLedger/app/repositories"
+	"timeLedger/global/errInfos"
+	"timeLedger/libs"
+
+	"gorm.io/gorm"
+)
+
+// notificationTemplateTextMaxLen 自訂文字欄位的長度上限（LINE Flex 文字與按鈕標籤）
+const notificationTemplateTextMaxLen = 300
+
+var notificationTemplateColorPattern = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
+// NotificationTemplateDefinition 內建通知範本：可用變數、各語系預設文字與預設顏色
+type NotificationTemplateDefinition struct {
+	Type      string                       `json:"type"`
+	Channel   string                       `json:"channel"`
+	Name      string                       `json:"name"`
+	Variables []string                     `json:"variables"`
+	Texts     map[string]map[string]string `json:"-"` // 語系 → 欄位 → 預設文字
+	Colors    map[string]string            `json:"-"` // 欄位 → 預設顏色，空字串表示使用 LINE 預設
+}
+
+// notificationTemplateDefinitions 可由中心自訂的通知範本
+var notificationTemplateDefinitions = []NotificationTemplateDefinition{
+	{
+		Type:      models.NotificationTemplateExceptionSubmit,
+		Channel:   models.NotificationChannelLine,
+		Name:      "例外申請（發給管理員）",
+		Variables: []string{"teacher_name", "center_name", "exception_type", "date", "time_range", "reason"},
+		Texts: map[string]map[string]string{
+			models.LocaleZhTW: {
+				"alt_text":  "新的例外申請 - {{teacher_name}} 老師",
+				"title":     "🔔 新的{{exception_type}}",
+				"applicant": "👤 申請人：{{teacher_name}} 老師",
+				"date":      "📅 日期：{{date}}",
+				"time":      "🕐 時間：{{time_range}}",
+				"reason":    "📝 原因：{{reason}}",
+				"button":    "前往處理",
+			},
+			models.LocaleEn: {
+				"alt_text":  "New request from {{teacher_name}}",
+				"title":     "🔔 New request: {{exception_type}}",
+				"applicant": "👤 Teacher: {{teacher_name}}",
+				"date":      "📅 Date: {{date}}",
+				"time":      "🕐 Time: {{time_range}}",
+				"reason":    "📝 Reason: {{reason}}",
+				"button":    "Review",
+			},
+		},
+		Colors: map[string]string{"title": "", "divider": "#CCCCCC", "button": ""},
+	},
+	{
+		Type:      models.NotificationTemplateExceptionApproved,
+		Channel:   models.NotificationChannelLine,
+		Name:      "例外核准（發給老師）",
+		Variables: []string{"teacher_name", "exception_type", "date", "time_range"},
+		Texts: map[string]map[string]string{
+			models.LocaleZhTW: {
+				"alt_text": "✅ 您的例外申請已核准 - {{date}}",
+				"title":    "✅ {{exception_type}}已核准",
+				"date":     "📅 日期：{{date}}",
+				"time":     "🕐 時間：{{time_range}}",
+				"button":   "查看詳情",
+			},
+			models.LocaleEn: {
+				"alt_text": "✅ Your request was approved - {{date}}",
+				"title":    "✅ Approved: {{exception_type}}",
+				"date":     "📅 Date: {{date}}",
+				"time":     "🕐 Time: {{time_range}}",
+				"button":   "View details",
+			},
+		},
+		Colors: map[string]string{"title": "#4CAF50", "divider": "#CCCCCC", "button": ""},
+	},
+	{
+		Type:      models.NotificationTemplateExceptionRejected,
+		Channel:   models.NotificationChannelLine,
+		Name:      "例外拒絕（發給老師）",
+		Variables: []string{"teacher_name", "exception_type", "date", "time_range", "reason"},
+		Texts: map[string]map[string]string{
+			models.LocaleZhTW: {
+				"alt_text": "❌ 您的例外申請已拒絕 - {{date}}",
+				"title":    "❌ {{exception_type}}已拒絕",
+				"date":     "📅 日期：{{date}}",
+				"time":     "🕐 時間：{{time_range}}",
+				"reason":   "📝 拒絕原因：{{reason}}",
+				"button":   "查看詳情",
+			},
+			models.LocaleEn: {
+				"alt_text": "❌ Your request was declined - {{date}}",
+				"title":    "❌ Declined: {{exception_type}}",
+				"date":     "📅 Date: {{date}}",
+				"time":     "🕐 Time: {{time_range}}",
+				"reason":   "📝 Reason: {{reason}}",
+				"button":   "View details",
+			},
+		},
+		Colors: map[string]string{"title": "#F44336", "divider": "#CCCCCC", "button": ""},
+	},
+	{
+		Type:      models.NotificationTemplateInvitationAccepted,
+		Channel:   models.NotificationChannelLine,
+		Name:      "新成員加入（發給管理員）",
+		Variables: []string{"teacher_name", "center_name", "role"},
+		Texts: map[string]map[string]string{
+			models.LocaleZhTW: {
+				"title":   "🎉 新成員加入！",
+				"member":  "👤 新成員：{{teacher_name}}",
+				"center":  "🏢 中心：{{center_name}}",
+				"role":    "📋 角色：{{role}}",
+				"closing": "✅ 歡迎新老師加入！",
+				"button":  "查看成員",
+			},
+			models.LocaleEn: {
+				"title":   "🎉 A new member has joined!",
+				"member":  "👤 Member: {{teacher_name}}",
+				"center":  "🏢 Center: {{center_name}}",
+				"role":    "📋 Role: {{role}}",
+				"closing": "✅ Welcome aboard!",
+				"button":  "View members",
+			},
+		},
+		Colors: map[string]string{"title": "#4CAF50", "divider": "#CCCCCC", "closing": "#666666", "button": ""},
+	},
+	{
+		Type:      models.NotificationTemplateBroadcast,
+		Channel:   models.NotificationChannelLine,
+		Name:      "中心廣播",
+		Variables: []string{"center_name", "title", "warning"},
+		Texts: map[string]map[string]string{
+			models.LocaleZhTW: {
+				"alt_text":        "🔔 廣播通知 - {{title}}",
+				"alt_text_urgent": "🚨 緊急通知 - {{title}}",
+				"from":            "🏢 來自：{{center_name}}",
+				"warning":         "⚠️ {{warning}}",
+			},
+			models.LocaleEn: {
+				"alt_text":        "🔔 Announcement - {{title}}",
+				"alt_text_urgent": "🚨 Urgent - {{title}}",
+				"from":            "🏢 From: {{center_name}}",
+				"warning":         "⚠️ {{warning}}",
+			},
+		},
+		Colors: map[string]string{"divider": "#CCCCCC", "from": "#666666", "warning": "#F57C00", "warning_background": "#FFF8E1", "button": ""},
+	},
+	{
+		Type:      models.NotificationTemplateWelcomeAdmin,
+		Channel:   models.NotificationChannelLine,
+		Name:      "管理員歡迎訊息",
+		Variables: []string{"admin_name", "center_name", "role"},
+		Texts: map[string]map[string]string{
+			models.LocaleZhTW: {
+				"alt_text":      "🎉 歡迎使用 TimeLedger！",
+				"title":         "🎉 歡迎使用 TimeLedger！",
+				"center":        "您的中心：{{center_name}}",
+				"role":          "您的角色：{{role}}",
+				"feature_title": "🔔 及時通知功能",
+				"feature_body":  "綁定 LINE 帳號後，當老師提交例外申請時，\n您會立即收到通知！",
+				"bind_button":   "立即綁定",
+				"later_button":  "稍後再說",
+				"quick_bind":    "🔗 前往綁定",
+				"quick_more":    "❓ 了解更多",
+			},
+			models.LocaleEn: {
+				"alt_text":      "🎉 Welcome to TimeLedger!",
+				"title":         "🎉 Welcome to TimeLedger!",
+				"center":        "Center: {{center_name}}",
+				"role":          "Role: {{role}}",
+				"feature_title": "🔔 Instant notifications",
+				"feature_body":  "Link your LINE account to be notified\nas soon as a teacher submits a request.",
+				"bind_button":   "Link now",
+				"later_button":  "Later",
+				"quick_bind":    "🔗 Link account",
+				"quick_more":    "❓ Learn more",
+			},
+		},
+		Colors: map[string]string{"info": "#666666", "feature_body": "#999999", "button": ""},
+	},
+}
+
+// notificationLocaleLabels 範本變數使用的語系文字（例外類型、角色等）
+var notificationLocaleLabels = map[string]map[string]string{
+	models.LocaleZhTW: {
+		"exception.LEAVE":      "請假申請",
+		"exception.RESCHEDULE": "調課申請",
+		"exception.SWAP":       "代課申請",
+		"exception.CANCEL":     "取消課程",
+		"exception.default":    "例外申請",
+		"role.SUBSTITUTE":      "代課老師",
+		"role.TEACHER":         "正職老師",
+		"role.default":         "老師",
+		"admin_role.OWNER":     "中心擁有者",
+		"admin_role.default":   "中心管理員",
+		"reason.none":          "未說明原因",
+	},
+	models.LocaleEn: {
+		"exception.LEAVE":      "Leave",
+		"exception.RESCHEDULE": "Reschedule",
+		"exception.SWAP":       "Substitution",
+		"exception.CANCEL":     "Class cancellation",
+		"exception.default":    "Schedule change",
+		"role.SUBSTITUTE":      "Substitute teacher",
+		"role.TEACHER":         "Teacher",
+		"role.default":         "Teacher",
+		"admin_role.OWNER":     "Owner",
+		"admin_role.default":   "Center admin",
+		"reason.none":          "No reason given",
+	},
+}
+
+// NotificationTemplateDefinitions 取得所有可自訂的通知範本
+func NotificationTemplateDefinitions() []NotificationTemplateDefinition {
+	return notificationTemplateDefinitions
+}
+
+// FindNotificationTemplateDefinition 依類型與管道取得內建範本
+func FindNotificationTemplateDefinition(templateType, channel string) (*NotificationTemplateDefinition, bool) {
+	for i := range notificationTemplateDefinitions {
+		def := &notificationTemplateDefinitions[i]
+		if def.Type == templateType && def.Channel == channel {
+			return def, true
+		}
+	}
+	return nil, false
+}
+
+// NormalizeNotificationLocale 將中心語系設定對應到支援的通知語系，無法對應時使用繁體中文
+func NormalizeNotificationLocale(language string) string {
+	lang := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(language), "_", "-"))
+	if lang == "en" || strings.HasPrefix(lang, "en-") {
+		return models.LocaleEn
+	}
+	return models.LocaleZhTW
+}
+
+// ResolvedNotificationTemplate 套用中心自訂後的範本
+type ResolvedNotificationTemplate struct {
+	Locale string
+	texts  map[string]string
+	colors map[string]string
+}
+
+// ResolveNotificationTemplate 依序套用繁體中文預設、語系預設與中心自訂，未自訂的欄位使用內建預設
+// 未知類型回傳空範本，取用欄位時得到空字串
+func ResolveNotificationTemplate(templateType, channel, locale string, override *models.NotificationTemplate) *ResolvedNotificationTemplate {
+	resolved := &ResolvedNotificationTemplate{
+		Locale: locale,
+		texts:  map[string]string{},
+		colors: map[string]string{},
+	}
+	def, ok := FindNotificationTemplateDefinition(templateType, channel)
+	if !ok {
+		return resolved
+	}
+
+	for _, source := range []map[string]string{def.Texts[models.LocaleZhTW], def.Texts[locale]} {
+		for field, text := range source {
+			resolved.texts[field] = text
+		}
+	}
+	for field, color := range def.Colors {
+		resolved.colors[field] = color
+	}
+	if override != nil && override.IsActive {
+		for field, text := range override.Texts {
+			if text != "" {
+				resolved.texts[field] = text
+			}
+		}
+		for field, color := range override.Colors {
+			if color != "" {
+				resolved.colors[field] = color
+			}
+		}
+	}
+	return resolved
+}
+
+// Text 取得欄位文字並代入變數
+func (t *ResolvedNotificationTemplate) Text(field string, vars map[string]string) string {
+	return libs.Interpolate(t.texts[field], vars)
+}
+
+// Color 取得欄位顏色，空字串表示使用 LINE 預設
+func (t *ResolvedNotificationTemplate) Color(field string) string {
+	return t.colors[field]
+}
+
+// notificationLabel 取得語系文字，找不到時依序使用 <prefix>.default 與繁體中文
+func notificationLabel(locale, key string) string {
+	for _, loc := range []string{locale, models.LocaleZhTW} {
+		if label, ok := notificationLocaleLabels[loc][key]; ok {
+			return label
+		}
+	}
+	if i := strings.LastIndex(key, "."); i > 0 && !strings.HasSuffix(key, ".default") {
+		return notificationLabel(locale, key[:i]+".default")
+	}
+	return ""
+}
+
+// ValidateNotificationTemplate 檢查自訂範本：欄位須為內建範本的欄位，變數須為可用變數，顏色須為 #RRGGBB
+func ValidateNotificationTemplate(def *NotificationTemplateDefinition, texts, colors map[string]string) error {
+	defaults := def.Texts[models.LocaleZhTW]
+	for field, text := range texts {
+		if _, ok := defaults[field]; !ok {
+			return fmt.Errorf("不支援的文字欄位：%s", field)
+		}
+		if len([]rune(text)) > notificationTemplateTextMaxLen {
+			return fmt.Errorf("文字欄位 %s 超過 %d 字", field, notificationTemplateTextMaxLen)
+		}
+		names, err := libs.TemplateVariables(text)
+		if err != nil {
+			return fmt.Errorf("文字欄位 %s：%w", field, err)
+		}
+		for _, name := range names {
+			if !slices.Contains(def.Variables, name) {
+				return fmt.Errorf("文字欄位 %s 使用了不支援的變數：%s", field, name)
+			}
+		}
+	}
+	for field, color := range colors {
+		if _, ok := def.Colors[field]; !ok {
+			return fmt.Errorf("不支援的顏色欄位：%s", field)
+		}
+		if color != "" && !notificationTemplateColorPattern.MatchString(color) {
+			return fmt.Errorf("顏色欄位 %s 格式錯誤，應為 #RRGGBB", field)
+		}
+	}
+	return nil
+}
+
+// NotificationTemplateRequest 自訂範本內容，僅需填寫要覆寫的欄位
+type NotificationTemplateRequest struct {
+	Texts    map[string]string `json:"texts"`
+	Colors   map[string]string `json:"colors"`
+	IsActive *bool             `json:"is_active"`
+}
+
+// NotificationTemplatePreviewRequest 預覽範本，texts / colors 為尚未儲存的草稿，未填寫時使用目前設定
+type NotificationTemplatePreviewRequest struct {
+	Type    string            `json:"type" binding:"required"`
+	Channel string            `json:"channel"`
+	Locale  string            `json:"locale"`
+	Texts   map[string]string `json:"texts"`
+	Colors  map[string]string `json:"colors"`
+}
+
+// NotificationTemplateView 範本的預設值與中心自訂內容
+type NotificationTemplateView struct {
+	Type          string                       `json:"type"`
+	Channel       string                       `json:"channel"`
+	Locale        string                       `json:"locale"`
+	Name          string                       `json:"name"`
+	Variables     []string                     `json:"variables"`
+	DefaultTexts  map[string]string            `json:"default_texts"`
+	DefaultColors map[string]string            `json:"default_colors"`
+	Override      *models.NotificationTemplate `json:"override,omitempty"`
+}
+
+// NotificationTemplatePreview 以範例資料產生的訊息
+type NotificationTemplatePreview struct {
+	AltText  string      `json:"alt_text"`
+	Contents interface{} `json:"contents"`
+}
+
+// NotificationTemplateService 中心通知範本管理與套用
+type NotificationTemplateService struct {
+	BaseService
+	app          *app.App
+	templateRepo *repositories.NotificationTemplateRepository
+	centerRepo   *repositories.CenterRepository
+}
+
+// NewNotificationTemplateService 建立通知範本服務
+func NewNotificationTemplateService(app *app.App) *NotificationTemplateService {
+	svc := &NotificationTemplateService{
+		BaseService: *NewBaseService(app, "NotificationTemplateService"),
+		app:         app,
+	}
+	if app.MySQL != nil {
+		svc.templateRepo = repositories.NewNotificationTemplateRepository(app)
+		svc.centerRepo = repositories.NewCenterRepository(app)
+	}
+	return svc
+}
+
+func (s *NotificationTemplateService) baseURL() string {
+	if s.app.Env == nil {
+		return ""
+	}
+	return s.app.Env.FrontendBaseURL
+}
+
+// LineTemplates 取得套用中心語系與自訂內容的 Flex Message 範本；讀取失敗時使用內建預設
+func (s *NotificationTemplateService) LineTemplates(ctx context.Context, centerID uint) LineBotTemplateService {
+	if s.templateRepo == nil || centerID == 0 {
+		return NewLineBotTemplateService(s.baseURL())
+	}
+
+	locale := models.LocaleZhTW
+	center, err := s.centerRepo.GetByID(ctx, centerID)
+	if err != nil {
+		s.Logger.Warn("failed to load center for notification templates", "center_id", centerID, "error", err)
+	} else {
+		locale = NormalizeNotificationLocale(center.Settings.DefaultLanguage)
+	}
+
+	overrides, err := s.templateRepo.ListActive(ctx, centerID, models.NotificationChannelLine, locale)
+	if err != nil {
+		s.Logger.Warn("failed to load notification templates", "center_id", centerID, "error", err)
+		overrides = nil
+	}
+	return NewLocalizedLineBotTemplateService(s.baseURL(), locale, overrides)
+}
+
+// ListTemplates 取得指定語系所有範本的預設值與中心自訂內容
+func (s *NotificationTemplateService) ListTemplates(ctx context.Context, centerID uint, locale string) ([]NotificationTemplateView, *errInfos.Res, error) {
+	if locale == "" {
+		locale = s.centerLocale(ctx, centerID)
+	}
+	if !slices.Contains(models.NotificationLocales, locale) {
+		return nil, s.App.Err.New(errInfos.PARAMS_VALIDATE_ERROR), fmt.Errorf("不支援的語系：%s", locale)
+	}
+
+	overrides, err := s.templateRepo.ListByCenterID(ctx, centerID)
+	if err != nil {
+		return nil, s.App.Err.New(errInfos.SQL_ERROR), err
+	}
+
+	views := make([]NotificationTemplateView, 0, len(notificationTemplateDefinitions))
+	for i := range notificationTemplateDefinitions {
+		def := &notificationTemplateDefinitions[i]
+		view := newNotificationTemplateView(def, locale)
+		for j := range overrides {
+			if overrides[j].Type == def.Type && overrides[j].Channel == def.Channel && overrides[j].Locale == locale {
+				view.Override = &overrides[j]
+				break
+			}
+		}
+		views = append(views, view)
+	}
+	return views, nil, nil
+}
+
+// SaveTemplate 新增或更新中心的自訂範本
+func (s *NotificationTemplateService) SaveTemplate(ctx context.Context, centerID, adminID uint, templateType, channel, locale string, req *NotificationTemplateRequest) (*NotificationTemplateView, *errInfos.Res, error) {
+	def, errInfo, err := s.definition(templateType, channel, locale)
+	if err != nil {
+		return nil, errInfo, err
+	}
+	if err := ValidateNotificationTemplate(def, req.Texts, req.Colors); err != nil {
+		return nil, s.App.Err.New(errInfos.PARAMS_VALIDATE_ERROR), err
+	}
+
+	now := time.Now()
+	template, err := s.templateRepo.GetByKey(ctx, centerID, def.Type, def.Channel, locale)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, s.App.Err.New(errInfos.SQL_ERROR), err
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		template = models.NotificationTemplate{
+			CenterID:  centerID,
+			Type:      def.Type,
+			Channel:   def.Channel,
+			Locale:    locale,
+			IsActive:  true,
+			CreatedAt: now,
+		}
+	}
+	template.Texts = models.NotificationTemplateValues(req.Texts)
+	template.Colors = models.NotificationTemplateValues(req.Colors)
+	if req.IsActive != nil {
+		template.IsActive = *req.IsActive
+	}
+	template.UpdatedBy = adminID
+	template.UpdatedAt = now
+
+	if template.ID == 0 {
+		template, err = s.templateRepo.Create(ctx, template)
+	} else {
+		err = s.templateRepo.UpdateFields(ctx, template.ID, map[string]interface{}{
+			"texts":      template.Texts,
+			"colors":     template.Colors,
+			"is_active":  template.IsActive,
+			"updated_by": template.UpdatedBy,
+			"updated_at": template.UpdatedAt,
+		})
+	}
+	if err != nil {
+		return nil, s.App.Err.New(errInfos.SQL_ERROR), err
+	}
+
+	view := newNotificationTemplateView(def, locale)
+	view.Override = &template
+	return &view, nil, nil
+}
+
+// ResetTemplate 刪除中心的自訂範本，恢復內建預設
+func (s *NotificationTemplateService) ResetTemplate(ctx context.Context, centerID uint, templateType, channel, locale string) (*errInfos.Res, error) {
+	def, errInfo, err := s.definition(templateType, channel, locale)
+	if err != nil {
+		return errInfo, err
+	}
+
+	template, err := s.templateRepo.GetByKey(ctx, centerID, def.Type, def.Channel, locale)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return s.App.Err.New(errInfos.NOTIFICATION_TEMPLATE_NOT_FOUND), err
+		}
+		return s.App.Err.New(errInfos.SQL_ERROR), err
+	}
+	if err := s.templateRepo.DeleteByIDWithCenterScope(ctx, template.ID, centerID); err != nil {
+		return s.App.Err.New(errInfos.SQL_ERROR), err
+	}
+	return nil, nil
+}
+
+// Preview 以範例資料產生訊息；草稿未填寫的欄位使用中心目前的自訂內容與內建預設
+func (s *NotificationTemplateService) Preview(ctx context.Context, centerID uint, req *NotificationTemplatePreviewRequest) (*NotificationTemplatePreview, *errInfos.Res, error) {
+	channel := req.Channel
+	if channel == "" {
+		channel = models.NotificationChannelLine
+	}
+	locale := req.Locale
+	if locale == "" {
+		locale = s.centerLocale(ctx, centerID)
+	}
+	def, errInfo, err := s.definition(req.Type, channel, locale)
+	if err != nil {
+		return nil, errInfo, err
+	}
+	if err := ValidateNotificationTemplate(def, req.Texts, req.Colors); err != nil {
+		return nil, s.App.Err.New(errInfos.PARAMS_VALIDATE_ERROR), err
+	}
+
+	draft := models.NotificationTemplate{
+		CenterID: centerID,
+		Type:     def.Type,
+		Channel:  def.Channel,
+		Locale:   locale,
+		Texts:    models.NotificationTemplateValues{},
+		Colors:   models.NotificationTemplateValues{},
+		IsActive: true,
+	}
+	saved, err := s.templateRepo.GetByKey(ctx, centerID, def.Type, def.Channel, locale)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, s.App.Err.New(errInfos.SQL_ERROR), err
+	}
+	if err == nil && saved.IsActive {
+		for field, text := range saved.Texts {
+			draft.Texts[field] = text
+		}
+		for field, color := range saved.Colors {
+			draft.Colors[field] = color
+		}
+	}
+	for field, text := range req.Texts {
+		draft.Texts[field] = text
+	}
+	for field, color := range req.Colors {
+		draft.Colors[field] = color
+	}
+
+	centerName := "TimeLedger Yoga"
+	if center, err := s.centerRepo.GetByID(ctx, centerID); err == nil {
+		centerName = center.Name
+	}
+
+	templates := NewLocalizedLineBotTemplateService(s.baseURL(), locale, []models.NotificationTemplate{draft})
+	alt, contents := PreviewLineTemplate(templates, def.Type, centerName)
+	return &NotificationTemplatePreview{AltText: alt, Contents: contents}, nil, nil
+}
+
+// PreviewLineTemplate 以範例資料產生指定類型的 Flex Message 與替代文字
+func PreviewLineTemplate(templates LineBotTemplateService, templateType, centerName string) (string, interface{}) {
+	date := time.Date(2026, 3, 2, 0, 0, 0, 0, app.GetTaiwanLocation())
+	start := date.Add(10 * time.Hour)
+	end := date.Add(11 * time.Hour)
+	exception := &models.ScheduleException{
+		ID:            1,
+		ExceptionType: "LEAVE",
+		OriginalDate:  date,
+		NewStartAt:    &start,
+		NewEndAt:      &end,
+		Reason:        "家中有事",
+	}
+	teacher := &models.Teacher{ID: 1, Name: "王小明"}
+
+	switch templateType {
+	case models.NotificationTemplateExceptionSubmit:
+		vars := templates.ExceptionVariables(exception, teacher.Name, centerName, exception.Reason)
+		return templates.GetText(templateType, "alt_text", vars), templates.GetExceptionSubmitTemplate(exception, teacher.Name, centerName)
+	case models.NotificationTemplateExceptionApproved:
+		vars := templates.ExceptionVariables(exception, teacher.Name, centerName, "")
+		return templates.GetText(templateType, "alt_text", vars), templates.GetExceptionApproveTemplate(exception, teacher.Name)
+	case models.NotificationTemplateExceptionRejected:
+		vars := templates.ExceptionVariables(exception, teacher.Name, centerName, "課程無法調整")
+		return templates.GetText(templateType, "alt_text", vars), templates.GetExceptionRejectTemplate(exception, teacher.Name, "課程無法調整")
+	case models.NotificationTemplateInvitationAccepted:
+		return "", templates.GetInvitationAcceptedTemplate(teacher, centerName, "TEACHER")
+	case models.NotificationTemplateBroadcast:
+		title := "三月課表異動"
+		alt := templates.GetText(templateType, "alt_text", map[string]string{"title": title, "center_name": centerName})
+		return alt, templates.GetBroadcastTemplate(centerName, title, "下週一起 A 教室整修，課程改至 B 教室。", "請提早 10 分鐘到場", "查看課表", "https://timeledger.example.com")
+	case models.NotificationTemplateWelcomeAdmin:
+		admin := &models.AdminUser{ID: 1, Name: "林主任", Role: "ADMIN"}
+		alt := templates.GetText(templateType, "alt_text", map[string]string{"admin_name": admin.Name, "center_name": centerName})
+		return alt, templates.GetWelcomeAdminTemplate(admin, centerName)
+	}
+	return "", nil
+}
+
+func (s *NotificationTemplateService) definition(templateType, channel, locale string) (*NotificationTemplateDefinition, *errInfos.Res, error) {
+	def, ok := FindNotificationTemplateDefinition(templateType, channel)
+	if !ok {
+		return nil, s.App.Err.New(errInfos.NOTIFICATION_TEMPLATE_NOT_FOUND), fmt.Errorf("unknown notification template %s/%s", templateType, channel)
+	}
+	if !slices.Contains(models.NotificationLocales, locale) {
+		return nil, s.App.Err.New(errInfos.PARAMS_VALIDATE_ERROR), fmt.Errorf("不支援的語系：%s", locale)
+	}
+	return def, nil, nil
+}
+
+func (s *NotificationTemplateService) centerLocale(ctx context.Context, centerID uint) string {
+	center, err := s.centerRepo.GetByID(ctx, centerID)
+	if err != nil {
+		return models.LocaleZhTW
+	}
+	return NormalizeNotificationLocale(center.Settings.DefaultLanguage)
+}
+
+func newNotificationTemplateView(def *NotificationTemplateDefinition, locale string) NotificationTemplateView {
+	resolved := ResolveNotificationTemplate(def.Type, def.Channel, locale, nil)
+	return NotificationTemplateView{
+		Type:          def.Type,
+		Channel:       def.Channel,
+		Locale:        locale,
+		Name:          def.Name,
+		Variables:     def.Variables,
+		DefaultTexts:  resolved.texts,
+		DefaultColors: resolved.colors,
+	}
+}
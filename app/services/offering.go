@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 	"timeLedger/app"
@@ -145,6 +146,7 @@ type UpdateOfferingInput struct {
 	DefaultRoomID       *uint
 	DefaultTeacherID    *uint
 	AllowBufferOverride bool
+	Version             *uint // 前端讀取時的班別版本（If-Match），nil 表示不比對
 }
 
 // UpdateOffering 更新班別
@@ -160,10 +162,25 @@ func (s *OfferingService) UpdateOffering(ctx context.Context, input *UpdateOffer
 		return nil, s.app.Err.New(errInfos.FORBIDDEN), nil
 	}
 
+	expectedVersion := expectedVersionOf(input.Version, existingOffering.Version)
+	if expectedVersion != existingOffering.Version {
+		return nil, s.app.Err.New(errInfos.ERR_CONCURRENT_MODIFIED),
+			NewVersionConflictError("Offering", input.OfferingID, expectedVersion, existingOffering.Version, existingOffering)
+	}
+
 	// 使用交易確保更新 offering 和稽核日誌的原子性
 	var updatedOffering models.Offering
 
 	txErr := s.app.MySQL.WDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		bumped, err := s.offeringRepo.BumpVersionWithDB(ctx, tx, input.OfferingID, expectedVersion)
+		if err != nil {
+			return fmt.Errorf("failed to bump offering version: %w", err)
+		}
+		if !bumped {
+			return ErrVersionConflict
+		}
+		existingOffering.Version = expectedVersion + 1
+
 		// 更新字段
 		existingOffering.DefaultRoomID = input.DefaultRoomID
 		existingOffering.DefaultTeacherID = input.DefaultTeacherID
@@ -204,6 +221,14 @@ func (s *OfferingService) UpdateOffering(ctx context.Context, input *UpdateOffer
 	})
 
 	if txErr != nil {
+		if errors.Is(txErr, ErrVersionConflict) {
+			current, err := s.offeringRepo.GetByID(ctx, input.OfferingID)
+			if err != nil {
+				return nil, s.app.Err.New(errInfos.NOT_FOUND), err
+			}
+			return nil, s.app.Err.New(errInfos.ERR_CONCURRENT_MODIFIED),
+				NewVersionConflictError("Offering", input.OfferingID, expectedVersion, current.Version, current)
+		}
 		return nil, s.app.Err.New(errInfos.ERR_TX_FAILED), txErr
 	}
 
@@ -0,0 +1,338 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"timeLedger/app"
+	"timeLedger/app/models"
+	"timeLedger/app/repositories"
+	"timeLedger/global/errInfos"
+)
+
+// 營業時間來源
+const (
+	OperatingHoursSourceDefault  = "DEFAULT"  // 中心預設營業時間
+	OperatingHoursSourceWeekly   = "WEEKLY"   // 每週營業時間
+	OperatingHoursSourceOverride = "OVERRIDE" // 日期區間調整
+)
+
+// OperatingHoursService 中心營業時間與日期區間調整
+type OperatingHoursService struct {
+	BaseService
+	app          *app.App
+	centerRepo   *repositories.CenterRepository
+	overrideRepo *repositories.CenterOperatingHoursOverrideRepository
+	auditLogRepo *repositories.AuditLogRepository
+}
+
+// NewOperatingHoursService 建立 OperatingHoursService 實例
+func NewOperatingHoursService(app *app.App) *OperatingHoursService {
+	return &OperatingHoursService{
+		BaseService:  *NewBaseService(app, "OperatingHoursService"),
+		app:          app,
+		centerRepo:   repositories.NewCenterRepository(app),
+		overrideRepo: repositories.NewCenterOperatingHoursOverrideRepository(app),
+		auditLogRepo: repositories.NewAuditLogRepository(app),
+	}
+}
+
+// DailyOperatingHours 單日實際營業時間
+// OpenTime、CloseTime 皆為空表示未設定營業時間（不限制）
+type DailyOperatingHours struct {
+	Date      string `json:"date,omitempty"`
+	Weekday   int    `json:"weekday"`
+	OpenTime  string `json:"open_time"`
+	CloseTime string `json:"close_time"`
+	Closed    bool   `json:"closed"`
+	Source    string `json:"source"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// CreateOperatingHoursOverrideRequest 新增營業時間調整請求
+type CreateOperatingHoursOverrideRequest struct {
+	StartDate string `json:"start_date" binding:"required"`
+	EndDate   string `json:"end_date" binding:"required"`
+	OpenTime  string `json:"open_time"`
+	CloseTime string `json:"close_time"`
+	Closed    bool   `json:"closed"`
+	Reason    string `json:"reason"`
+}
+
+// GetOperatingHours 取得日期區間內每天的實際營業時間
+func (s *OperatingHoursService) GetOperatingHours(ctx context.Context, centerID uint, startDate, endDate time.Time) ([]DailyOperatingHours, *errInfos.Res, error) {
+	if endDate.Before(startDate) {
+		return nil, s.app.Err.New(errInfos.SCHED_END_BEFORE_START), fmt.Errorf("end_date before start_date")
+	}
+
+	center, err := s.centerRepo.GetByID(ctx, centerID)
+	if err != nil {
+		return nil, s.app.Err.New(errInfos.NOT_FOUND), err
+	}
+
+	overrides, err := s.overrideRepo.ListOverlapping(ctx, centerID, startDate, endDate)
+	if err != nil {
+		return nil, s.app.Err.New(errInfos.SQL_ERROR), err
+	}
+
+	days := make([]DailyOperatingHours, 0)
+	for date := startDate; !date.After(endDate); date = date.AddDate(0, 0, 1) {
+		days = append(days, ResolveOperatingHours(&center.Settings, overrides, date))
+	}
+	return days, nil, nil
+}
+
+// ListOverrides 取得中心的營業時間調整列表
+func (s *OperatingHoursService) ListOverrides(ctx context.Context, centerID uint) ([]models.CenterOperatingHoursOverride, *errInfos.Res, error) {
+	overrides, err := s.overrideRepo.ListByCenterID(ctx, centerID)
+	if err != nil {
+		return nil, s.app.Err.New(errInfos.SQL_ERROR), err
+	}
+	return overrides, nil, nil
+}
+
+// CreateOverride 新增營業時間調整
+func (s *OperatingHoursService) CreateOverride(ctx context.Context, centerID, adminID uint, req *CreateOperatingHoursOverrideRequest) (*models.CenterOperatingHoursOverride, *errInfos.Res, error) {
+	startDate, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		return nil, s.app.Err.New(errInfos.SCHED_INVALID_DATE_FORMAT), fmt.Errorf("invalid start_date format")
+	}
+	endDate, err := time.Parse("2006-01-02", req.EndDate)
+	if err != nil {
+		return nil, s.app.Err.New(errInfos.SCHED_INVALID_DATE_FORMAT), fmt.Errorf("invalid end_date format")
+	}
+	if endDate.Before(startDate) {
+		return nil, s.app.Err.New(errInfos.SCHED_END_BEFORE_START), fmt.Errorf("end_date before start_date")
+	}
+	if !req.Closed {
+		if err := ValidateOpeningWindow(req.OpenTime, req.CloseTime); err != nil {
+			return nil, s.app.Err.New(errInfos.PARAMS_VALIDATE_ERROR), err
+		}
+	}
+
+	override := models.CenterOperatingHoursOverride{
+		CenterID:  centerID,
+		StartDate: startDate,
+		EndDate:   endDate,
+		OpenTime:  req.OpenTime,
+		CloseTime: req.CloseTime,
+		Closed:    req.Closed,
+		Reason:    req.Reason,
+	}
+
+	var created models.CenterOperatingHoursOverride
+	err = s.overrideRepo.Transaction(ctx, func(txRepo *repositories.GenericRepository[models.CenterOperatingHoursOverride]) error {
+		var txErr error
+		created, txErr = txRepo.Create(ctx, override)
+		if txErr != nil {
+			return txErr
+		}
+
+		// 記錄稽核日誌
+		auditLog := models.AuditLog{
+			CenterID:   centerID,
+			ActorType:  "ADMIN",
+			ActorID:    adminID,
+			Action:     "CREATE_OPERATING_HOURS_OVERRIDE",
+			TargetType: "CenterOperatingHoursOverride",
+			TargetID:   created.ID,
+			Payload: models.AuditPayload{
+				After: created,
+			},
+		}
+		return txRepo.GetDBWrite().Create(&auditLog).Error
+	})
+	if err != nil {
+		return nil, s.app.Err.New(errInfos.SQL_ERROR), err
+	}
+
+	return &created, nil, nil
+}
+
+// DeleteOverride 刪除營業時間調整
+func (s *OperatingHoursService) DeleteOverride(ctx context.Context, centerID, adminID, overrideID uint) (*errInfos.Res, error) {
+	override, err := s.overrideRepo.GetByIDWithCenterScope(ctx, overrideID, centerID)
+	if err != nil {
+		return s.app.Err.New(errInfos.NOT_FOUND), err
+	}
+
+	if err := s.overrideRepo.DeleteByIDWithCenterScope(ctx, overrideID, centerID); err != nil {
+		return s.app.Err.New(errInfos.SQL_ERROR), err
+	}
+
+	// 記錄稽核日誌
+	s.auditLogRepo.Create(ctx, models.AuditLog{
+		CenterID:   centerID,
+		ActorType:  "ADMIN",
+		ActorID:    adminID,
+		Action:     "DELETE_OPERATING_HOURS_OVERRIDE",
+		TargetType: "CenterOperatingHoursOverride",
+		TargetID:   overrideID,
+		Payload: models.AuditPayload{
+			Before: override,
+		},
+	})
+
+	return nil, nil
+}
+
+// ValidateWeeklyOperatingHours 檢查每週營業時間設定
+func ValidateWeeklyOperatingHours(hours []models.WeekdayOperatingHours) error {
+	seen := make(map[int]bool, len(hours))
+	for _, h := range hours {
+		if h.Weekday < 1 || h.Weekday > 7 {
+			return fmt.Errorf("weekday must be between 1 and 7")
+		}
+		if seen[h.Weekday] {
+			return fmt.Errorf("duplicate weekday %d", h.Weekday)
+		}
+		seen[h.Weekday] = true
+
+		if h.Closed {
+			continue
+		}
+		if err := ValidateOpeningWindow(h.OpenTime, h.CloseTime); err != nil {
+			return fmt.Errorf("weekday %d: %w", h.Weekday, err)
+		}
+	}
+	return nil
+}
+
+// ValidateOpeningWindow 檢查開門、打烊時間格式與先後順序
+func ValidateOpeningWindow(openTime, closeTime string) error {
+	if !isValidClockTime(openTime) || !isValidClockTime(closeTime) {
+		return fmt.Errorf("open_time and close_time must be HH:MM")
+	}
+	if ParseTimeToMinutes(closeTime) <= ParseTimeToMinutes(openTime) {
+		return fmt.Errorf("close_time must be after open_time")
+	}
+	return nil
+}
+
+// isValidClockTime 檢查 HH:MM 格式，允許 24:00 表示營業到午夜
+func isValidClockTime(value string) bool {
+	if value == "24:00" {
+		return true
+	}
+	_, err := time.Parse("15:04", value)
+	return err == nil
+}
+
+// ResolveWeeklyOperatingHours 取得指定星期的營業時間（1=週一 … 7=週日）
+// 優先使用每週營業時間，未設定的星期沿用中心預設營業時間
+func ResolveWeeklyOperatingHours(settings *models.CenterSettings, weekday int) DailyOperatingHours {
+	hours := DailyOperatingHours{Weekday: weekday, Source: OperatingHoursSourceDefault}
+	if settings == nil {
+		return hours
+	}
+
+	for _, h := range settings.WeeklyOperatingHours {
+		if h.Weekday == weekday {
+			hours.OpenTime = h.OpenTime
+			hours.CloseTime = h.CloseTime
+			hours.Closed = h.Closed
+			hours.Source = OperatingHoursSourceWeekly
+			return hours
+		}
+	}
+
+	hours.OpenTime = settings.OperatingStartTime
+	hours.CloseTime = settings.OperatingEndTime
+	return hours
+}
+
+// ResolveOperatingHours 取得指定日期的實際營業時間
+// 日期區間調整優先於每週營業時間，多筆重疊時以 ID 較大（較晚建立）者為準
+func ResolveOperatingHours(settings *models.CenterSettings, overrides []models.CenterOperatingHoursOverride, date time.Time) DailyOperatingHours {
+	weekday := int(date.Weekday())
+	if weekday == 0 {
+		weekday = 7
+	}
+
+	dateStr := date.Format("2006-01-02")
+	var matched *models.CenterOperatingHoursOverride
+	for i := range overrides {
+		o := &overrides[i]
+		if o.StartDate.Format("2006-01-02") > dateStr || o.EndDate.Format("2006-01-02") < dateStr {
+			continue
+		}
+		if matched == nil || o.ID > matched.ID {
+			matched = o
+		}
+	}
+
+	if matched != nil {
+		return DailyOperatingHours{
+			Date:      dateStr,
+			Weekday:   weekday,
+			OpenTime:  matched.OpenTime,
+			CloseTime: matched.CloseTime,
+			Closed:    matched.Closed,
+			Source:    OperatingHoursSourceOverride,
+			Reason:    matched.Reason,
+		}
+	}
+
+	hours := ResolveWeeklyOperatingHours(settings, weekday)
+	hours.Date = dateStr
+	return hours
+}
+
+// IsWithinOperatingHours 檢查時段（HH:MM）是否落在營業時間內
+// 未設定營業時間視為不限制；公休日一律不符合；跨日時段需營業到 24:00 且隔天部分不檢查
+func IsWithinOperatingHours(hours DailyOperatingHours, startTime, endTime string) bool {
+	if hours.Closed {
+		return false
+	}
+	if hours.OpenTime == "" && hours.CloseTime == "" {
+		return true
+	}
+
+	open := 0
+	if hours.OpenTime != "" {
+		open = ParseTimeToMinutes(hours.OpenTime)
+	}
+	closeAt := 24 * 60
+	if hours.CloseTime != "" {
+		closeAt = ParseTimeToMinutes(hours.CloseTime)
+	}
+
+	start := ParseTimeToMinutes(startTime)
+	end := ParseTimeToMinutes(endTime)
+	if end <= start {
+		end = 24 * 60
+	}
+
+	return start >= open && end <= closeAt
+}
+
+// GenerateTimeSlotsForHours 依營業時間產生時段列表（小時），打烊時間所在的整點不列入；公休日回傳空列表
+// 未設定營業時間時使用 00 - 23，打烊早於開門的設定無效，不產生時段
+func GenerateTimeSlotsForHours(hours DailyOperatingHours) []int {
+	if hours.Closed {
+		return []int{}
+	}
+
+	openMinutes, closeMinutes := 0, 24*60
+	if hours.OpenTime != "" {
+		openMinutes = ParseTimeToMinutes(hours.OpenTime)
+	}
+	if hours.CloseTime != "" {
+		closeMinutes = ParseTimeToMinutes(hours.CloseTime)
+	}
+	if closeMinutes > 24*60 {
+		closeMinutes = 24 * 60
+	}
+	if closeMinutes <= openMinutes {
+		return []int{}
+	}
+
+	// 打烊時間不在整點時，最後一個時段仍有營業
+	startHour := openMinutes / 60
+	endHour := (closeMinutes + 59) / 60
+	slots := make([]int, 0, endHour-startHour)
+	for i := startHour; i < endHour; i++ {
+		slots = append(slots, i)
+	}
+	return slots
+}
@@ -33,18 +33,9 @@ type RedisQueueService struct {
 
 // NewRedisQueueService 建立 Redis Queue Service
 func NewRedisQueueService(app *app.App) *RedisQueueService {
-	// 嘗試取得 logger，如果未初始化則使用 nil
-	var log *logger.Logger
-	defer func() {
-		if r := recover(); r != nil {
-			// logger 未初始化，繼續使用 nil
-		}
-	}()
-	log = logger.GetLogger()
-
 	svc := &RedisQueueService{
 		app: app,
-		log: log,
+		log: queueLogger(),
 	}
 
 	if app.Redis != nil {
@@ -54,6 +45,17 @@ func NewRedisQueueService(app *app.App) *RedisQueueService {
 	return svc
 }
 
+// queueLogger 嘗試取得 logger，如果未初始化則使用 nil
+func queueLogger() (log *logger.Logger) {
+	defer func() {
+		if r := recover(); r != nil {
+			// logger 未初始化，繼續使用 nil
+			log = nil
+		}
+	}()
+	return logger.GetLogger()
+}
+
 // NotificationItem 通知佇列項目
 type NotificationItem struct {
 	ID            uint       `json:"id"`
@@ -30,14 +30,16 @@ func NewRoomService(app *app.App) *RoomService {
 
 // CreateRoomRequest 建立教室請求
 type CreateRoomRequest struct {
-	Name     string `json:"name" binding:"required"`
-	Capacity int    `json:"capacity" binding:"required"`
+	Name     string              `json:"name" binding:"required"`
+	Capacity int                 `json:"capacity" binding:"required"`
+	Features models.RoomFeatures `json:"features"`
 }
 
 // UpdateRoomRequest 更新教室請求
 type UpdateRoomRequest struct {
-	Name     string `json:"name" binding:"required"`
-	Capacity int    `json:"capacity" binding:"required"`
+	Name     string               `json:"name" binding:"required"`
+	Capacity int                  `json:"capacity" binding:"required"`
+	Features *models.RoomFeatures `json:"features"` // 可選，如果為 nil 不更新
 }
 
 // ToggleActiveRequest 啟用/停用請求
@@ -68,6 +70,7 @@ func (s *RoomService) GetRooms(ctx context.Context, centerID uint, query string,
 				CenterID: centerID,
 				Name:     item.Name,
 				Capacity: item.Capacity,
+				Features: item.Features,
 				IsActive: item.IsActive,
 			})
 		}
@@ -87,6 +90,7 @@ func (s *RoomService) GetRooms(ctx context.Context, centerID uint, query string,
 			ID:       r.ID,
 			Name:     r.Name,
 			Capacity: r.Capacity,
+			Features: r.Features,
 			IsActive: r.IsActive,
 		})
 	}
@@ -106,10 +110,15 @@ func (s *RoomService) GetActiveRooms(ctx context.Context, centerID uint) ([]mode
 
 // CreateRoom 新增教室
 func (s *RoomService) CreateRoom(ctx context.Context, centerID, adminID uint, req *CreateRoomRequest) (*models.Room, *errInfos.Res, error) {
+	if err := ValidateRoomFeatures(req.Features); err != nil {
+		return nil, s.app.Err.New(errInfos.PARAMS_VALIDATE_ERROR), err
+	}
+
 	room := models.Room{
 		CenterID:  centerID,
 		Name:      req.Name,
 		Capacity:  req.Capacity,
+		Features:  req.Features,
 		CreatedAt: time.Now(),
 	}
 
@@ -153,6 +162,12 @@ func (s *RoomService) UpdateRoom(ctx context.Context, centerID, adminID, roomID
 	// 更新
 	room.Name = req.Name
 	room.Capacity = req.Capacity
+	if req.Features != nil {
+		if err := ValidateRoomFeatures(*req.Features); err != nil {
+			return nil, s.app.Err.New(errInfos.PARAMS_VALIDATE_ERROR), err
+		}
+		room.Features = *req.Features
+	}
 
 	if err := s.roomRepository.Update(ctx, room); err != nil {
 		return nil, s.app.Err.New(errInfos.SQL_ERROR), err
@@ -0,0 +1,551 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+	"timeLedger/app"
+	"timeLedger/app/models"
+	"timeLedger/app/repositories"
+	"timeLedger/global/errInfos"
+	"timeLedger/libs"
+
+	"gorm.io/gorm"
+)
+
+// RoomBookingService 教室非課程佔用（會議、租借、維修）管理
+type RoomBookingService struct {
+	BaseService
+	app           *app.App
+	bookingRepo   *repositories.RoomBookingRepository
+	roomRepo      *repositories.RoomRepository
+	ruleRepo      *repositories.ScheduleRuleRepository
+	makeupRepo    *repositories.MakeupSessionRepository
+	exceptionRepo *repositories.ScheduleExceptionRepository
+}
+
+// NewRoomBookingService 建立 RoomBookingService 實例
+func NewRoomBookingService(app *app.App) *RoomBookingService {
+	return &RoomBookingService{
+		BaseService:   *NewBaseService(app, "RoomBookingService"),
+		app:           app,
+		bookingRepo:   repositories.NewRoomBookingRepository(app),
+		roomRepo:      repositories.NewRoomRepository(app),
+		ruleRepo:      repositories.NewScheduleRuleRepository(app),
+		makeupRepo:    repositories.NewMakeupSessionRepository(app),
+		exceptionRepo: repositories.NewScheduleExceptionRepository(app),
+	}
+}
+
+// RoomBookingRequest 建立或更新教室預約請求
+type RoomBookingRequest struct {
+	RoomID        uint    `json:"room_id" binding:"required"`
+	BookingType   string  `json:"booking_type" binding:"required"`
+	Title         string  `json:"title" binding:"required"`
+	RenterName    string  `json:"renter_name"`
+	RenterContact string  `json:"renter_contact"`
+	Recurrence    string  `json:"recurrence"` // NONE（預設）或 WEEKLY
+	StartDate     string  `json:"start_date" binding:"required,date_format"`
+	EndDate       *string `json:"end_date"` // 僅每週預約使用，空值表示沒有結束日
+	Weekday       int     `json:"weekday"`  // 僅每週預約使用，未填寫時依 start_date 推算
+	StartTime     string  `json:"start_time" binding:"required,time_format"`
+	EndTime       string  `json:"end_time" binding:"required,time_format"`
+	Note          string  `json:"note"`
+}
+
+// RoomBookingOccurrence 教室預約在某一天的佔用
+type RoomBookingOccurrence struct {
+	BookingID   uint   `json:"booking_id"`
+	RoomID      uint   `json:"room_id"`
+	BookingType string `json:"booking_type"`
+	Title       string `json:"title"`
+	RenterName  string `json:"renter_name,omitempty"`
+	Date        string `json:"date"`
+	StartTime   string `json:"start_time"`
+	EndTime     string `json:"end_time"`
+}
+
+// BuildRoomBooking 驗證請求並組成預約資料（不含 ID 與中心）
+func BuildRoomBooking(req *RoomBookingRequest) (models.RoomBooking, error) {
+	booking := models.RoomBooking{
+		RoomID:        req.RoomID,
+		BookingType:   req.BookingType,
+		Title:         req.Title,
+		RenterName:    req.RenterName,
+		RenterContact: req.RenterContact,
+		Recurrence:    req.Recurrence,
+		StartTime:     req.StartTime,
+		EndTime:       req.EndTime,
+		Note:          req.Note,
+	}
+
+	if !models.IsValidRoomBookingType(booking.BookingType) {
+		return booking, fmt.Errorf("invalid booking_type: %s", booking.BookingType)
+	}
+	if booking.Recurrence == "" {
+		booking.Recurrence = models.RoomBookingRecurrenceNone
+	}
+	if booking.Recurrence != models.RoomBookingRecurrenceNone && booking.Recurrence != models.RoomBookingRecurrenceWeekly {
+		return booking, fmt.Errorf("invalid recurrence: %s", booking.Recurrence)
+	}
+	if !isValidClockTime(booking.StartTime) || !isValidClockTime(booking.EndTime) {
+		return booking, fmt.Errorf("invalid time format")
+	}
+	if ParseTimeToMinutes(booking.EndTime) <= ParseTimeToMinutes(booking.StartTime) {
+		return booking, fmt.Errorf("end_time must be after start_time")
+	}
+
+	loc := libs.GetTaiwanLocation()
+	startDate, err := time.ParseInLocation("2006-01-02", req.StartDate, loc)
+	if err != nil {
+		return booking, fmt.Errorf("invalid start_date format: %w", err)
+	}
+	booking.StartDate = startDate
+
+	if booking.Recurrence == models.RoomBookingRecurrenceNone {
+		booking.Weekday = isoWeekday(startDate)
+		return booking, nil
+	}
+
+	booking.Weekday = req.Weekday
+	if booking.Weekday == 0 {
+		booking.Weekday = isoWeekday(startDate)
+	}
+	if booking.Weekday < 1 || booking.Weekday > 7 {
+		return booking, fmt.Errorf("weekday must be between 1 and 7")
+	}
+	if req.EndDate != nil && *req.EndDate != "" {
+		endDate, err := time.ParseInLocation("2006-01-02", *req.EndDate, loc)
+		if err != nil {
+			return booking, fmt.Errorf("invalid end_date format: %w", err)
+		}
+		if endDate.Before(startDate) {
+			return booking, fmt.Errorf("end_date must not be before start_date")
+		}
+		booking.EndDate = &endDate
+	}
+	return booking, nil
+}
+
+// isoWeekday 轉換為 1=週一 … 7=週日
+func isoWeekday(date time.Time) int {
+	weekday := int(date.Weekday())
+	if weekday == 0 {
+		weekday = 7
+	}
+	return weekday
+}
+
+// bookingDateRange 預約佔用的日期區間（YYYY-MM-DD），每週預約沒有結束日時到 9999-12-31
+func bookingDateRange(b models.RoomBooking) (string, string) {
+	start := b.StartDate.Format("2006-01-02")
+	if b.Recurrence != models.RoomBookingRecurrenceWeekly {
+		return start, start
+	}
+	if b.EndDate == nil {
+		return start, "9999-12-31"
+	}
+	return start, b.EndDate.Format("2006-01-02")
+}
+
+// clockRangesOverlap 比較兩段 HH:MM 時間是否重疊，結束時間不晚於開始時間時視為跨日到 24:00
+func clockRangesOverlap(start1, end1, start2, end2 string) bool {
+	s1, e1 := ParseTimeToMinutes(start1), ParseTimeToMinutes(end1)
+	s2, e2 := ParseTimeToMinutes(start2), ParseTimeToMinutes(end2)
+	if e1 <= s1 {
+		e1 = 24 * 60
+	}
+	if e2 <= s2 {
+		e2 = 24 * 60
+	}
+	return s1 < e2 && e1 > s2
+}
+
+// RoomBookingsCollide 檢查兩筆預約是否在同一教室的同一時段重疊
+func RoomBookingsCollide(a, b models.RoomBooking) bool {
+	if a.RoomID != b.RoomID || !clockRangesOverlap(a.StartTime, a.EndTime, b.StartTime, b.EndTime) {
+		return false
+	}
+	if a.Recurrence != models.RoomBookingRecurrenceWeekly {
+		return b.OccursOn(a.StartDate)
+	}
+	if b.Recurrence != models.RoomBookingRecurrenceWeekly {
+		return a.OccursOn(b.StartDate)
+	}
+	if a.Weekday != b.Weekday {
+		return false
+	}
+	aStart, aEnd := bookingDateRange(a)
+	bStart, bEnd := bookingDateRange(b)
+	return aStart <= bEnd && bStart <= aEnd
+}
+
+// RuleCollidesWithBooking 檢查排課規則是否與預約佔用同一教室的同一時段
+func RuleCollidesWithBooking(rule models.ScheduleRule, b models.RoomBooking) bool {
+	if !rule.HasRoom(b.RoomID) || !clockRangesOverlap(rule.StartTime, rule.EndTime, b.StartTime, b.EndTime) {
+		return false
+	}
+
+	ruleStart := "0001-01-01"
+	if !rule.EffectiveRange.StartDate.IsZero() {
+		ruleStart = rule.EffectiveRange.StartDate.Format("2006-01-02")
+	}
+	ruleEnd := "9999-12-31"
+	if !rule.EffectiveRange.EndDate.IsZero() {
+		ruleEnd = rule.EffectiveRange.EndDate.Format("2006-01-02")
+	}
+
+	if b.Recurrence != models.RoomBookingRecurrenceWeekly {
+		date := b.StartDate.Format("2006-01-02")
+		if rule.Weekday != isoWeekday(b.StartDate) || date < ruleStart || date > ruleEnd {
+			return false
+		}
+		for _, suspended := range rule.SuspendedDates {
+			if suspended.Format("2006-01-02") == date {
+				return false
+			}
+		}
+		return true
+	}
+
+	if rule.Weekday != b.Weekday {
+		return false
+	}
+	bStart, bEnd := bookingDateRange(b)
+	return ruleStart <= bEnd && bStart <= ruleEnd
+}
+
+// MakeupCollidesWithBooking 檢查已安排的補課是否與預約佔用同一教室的同一時段
+func MakeupCollidesWithBooking(makeup models.MakeupSession, b models.RoomBooking) bool {
+	return makeup.RoomID == b.RoomID && b.OccursOn(makeup.MakeupDate) &&
+		clockRangesOverlap(makeup.StartTime, makeup.EndTime, b.StartTime, b.EndTime)
+}
+
+// RescheduleCollidesWithBooking 檢查調課後的時段是否與預約佔用同一教室的同一時段
+// 未指定新教室的調課沿用原規則的教室（需預先載入 Rule）
+func RescheduleCollidesWithBooking(exception models.ScheduleException, b models.RoomBooking) bool {
+	if exception.NewStartAt == nil || exception.NewEndAt == nil {
+		return false
+	}
+	roomID := exception.Rule.RoomID
+	if exception.NewRoomID != nil {
+		roomID = *exception.NewRoomID
+	}
+	return roomID == b.RoomID && b.OccursOn(*exception.NewStartAt) &&
+		clockRangesOverlap(exception.NewStartAt.Format("15:04"), exception.NewEndAt.Format("15:04"), b.StartTime, b.EndTime)
+}
+
+// RoomBookingConflicts 比對預約與待排時段，回傳教室已被預約的衝突
+// date 為 nil 時表示每週規則，只比對同星期的每週預約
+func RoomBookingConflicts(bookings []models.RoomBooking, roomIDs []uint, date *time.Time, weekday int, startTime, endTime string) []ValidationConflict {
+	var conflicts []ValidationConflict
+	rooms := make(map[uint]bool, len(roomIDs))
+	for _, id := range roomIDs {
+		rooms[id] = true
+	}
+
+	for _, b := range bookings {
+		if !rooms[b.RoomID] || !clockRangesOverlap(startTime, endTime, b.StartTime, b.EndTime) {
+			continue
+		}
+		if date != nil {
+			if !b.OccursOn(*date) {
+				continue
+			}
+		} else if b.Recurrence != models.RoomBookingRecurrenceWeekly || b.Weekday != weekday {
+			continue
+		}
+		conflicts = append(conflicts, roomBookedConflict(b))
+	}
+	return conflicts
+}
+
+// WeeklyRuleBookingConflicts 比對預約與每週規則（各星期、各教室），回傳教室已被預約的衝突
+// 單次預約落在規則星期且在 startDate ~ endDate 之間、每週預約與期間重疊都視為衝突；日期為零值表示不限
+func WeeklyRuleBookingConflicts(bookings []models.RoomBooking, roomIDs []uint, weekdays []int, startTime, endTime string, startDate, endDate time.Time) []ValidationConflict {
+	var conflicts []ValidationConflict
+	for _, b := range bookings {
+		if !slices.Contains(roomIDs, b.RoomID) {
+			continue
+		}
+		for _, weekday := range weekdays {
+			rule := models.ScheduleRule{
+				RoomID:         b.RoomID,
+				Weekday:        weekday,
+				StartTime:      startTime,
+				EndTime:        endTime,
+				EffectiveRange: models.DateRange{StartDate: startDate, EndDate: endDate},
+			}
+			if RuleCollidesWithBooking(rule, b) {
+				conflicts = append(conflicts, roomBookedConflict(b))
+				break
+			}
+		}
+	}
+	return conflicts
+}
+
+func roomBookedConflict(b models.RoomBooking) ValidationConflict {
+	return ValidationConflict{
+		Type:             "ROOM_BOOKED",
+		Message:          fmt.Sprintf("教室在該時段已有預約：%s", b.Title),
+		ConflictSource:   "BOOKING",
+		ConflictSourceID: b.ID,
+		Details:          fmt.Sprintf("booking_id:%d, booking_type:%s, room_id:%d", b.ID, b.BookingType, b.RoomID),
+	}
+}
+
+// ExpandRoomBookings 將預約展開為日期區間內每天的佔用
+func ExpandRoomBookings(bookings []models.RoomBooking, startDate, endDate time.Time) []RoomBookingOccurrence {
+	occurrences := []RoomBookingOccurrence{}
+	start := dateOnly(startDate)
+	end := dateOnly(endDate)
+
+	for _, b := range bookings {
+		for date := start; !date.After(end); date = date.AddDate(0, 0, 1) {
+			if !b.OccursOn(date) {
+				continue
+			}
+			occurrences = append(occurrences, RoomBookingOccurrence{
+				BookingID:   b.ID,
+				RoomID:      b.RoomID,
+				BookingType: b.BookingType,
+				Title:       b.Title,
+				RenterName:  b.RenterName,
+				Date:        date.Format("2006-01-02"),
+				StartTime:   b.StartTime,
+				EndTime:     b.EndTime,
+			})
+		}
+	}
+
+	sort.SliceStable(occurrences, func(i, j int) bool {
+		if occurrences[i].Date != occurrences[j].Date {
+			return occurrences[i].Date < occurrences[j].Date
+		}
+		return occurrences[i].StartTime < occurrences[j].StartTime
+	})
+	return occurrences
+}
+
+// ListBookings 取得中心所有教室預約
+func (s *RoomBookingService) ListBookings(ctx context.Context, centerID uint) ([]models.RoomBooking, *errInfos.Res, error) {
+	bookings, err := s.bookingRepo.ListByCenterID(ctx, centerID)
+	if err != nil {
+		return nil, s.app.Err.New(errInfos.SQL_ERROR), err
+	}
+	return bookings, nil, nil
+}
+
+// ListOccurrences 取得日期區間內每天的教室預約
+func (s *RoomBookingService) ListOccurrences(ctx context.Context, centerID uint, startDate, endDate time.Time) ([]RoomBookingOccurrence, *errInfos.Res, error) {
+	if endDate.Before(startDate) {
+		return nil, s.app.Err.New(errInfos.PARAMS_VALIDATE_ERROR), fmt.Errorf("end_date must not be before start_date")
+	}
+	bookings, err := s.bookingRepo.ListOverlapping(ctx, centerID, nil, startDate, endDate)
+	if err != nil {
+		return nil, s.app.Err.New(errInfos.SQL_ERROR), err
+	}
+	return ExpandRoomBookings(bookings, startDate, endDate), nil, nil
+}
+
+// CreateBooking 建立教室預約，與既有排課或其他預約衝突時回傳衝突清單
+func (s *RoomBookingService) CreateBooking(ctx context.Context, centerID, adminID uint, req *RoomBookingRequest) (*models.RoomBooking, *ValidationResult, *errInfos.Res, error) {
+	booking, err := BuildRoomBooking(req)
+	if err != nil {
+		return nil, nil, s.app.Err.New(errInfos.PARAMS_VALIDATE_ERROR), err
+	}
+	booking.CenterID = centerID
+	booking.CreatedBy = adminID
+
+	if _, err := s.roomRepo.GetByIDWithCenterScope(ctx, booking.RoomID, centerID); err != nil {
+		return nil, nil, s.app.Err.New(errInfos.NOT_FOUND), fmt.Errorf("room not found")
+	}
+
+	validation, err := s.findConflicts(ctx, centerID, booking)
+	if err != nil {
+		return nil, nil, s.app.Err.New(errInfos.SQL_ERROR), err
+	}
+	if !validation.Valid {
+		return nil, &validation, s.app.Err.New(errInfos.SCHED_OVERLAP), fmt.Errorf("room booking conflicts with existing schedule")
+	}
+
+	txErr := s.app.MySQL.WDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&booking).Error; err != nil {
+			return fmt.Errorf("failed to create room booking: %w", err)
+		}
+
+		auditLog := models.AuditLog{
+			CenterID:   centerID,
+			ActorType:  "ADMIN",
+			ActorID:    adminID,
+			Action:     "CREATE_ROOM_BOOKING",
+			TargetType: "RoomBooking",
+			TargetID:   booking.ID,
+			Payload:    models.AuditPayload{After: booking},
+		}
+		return tx.Create(&auditLog).Error
+	})
+	if txErr != nil {
+		s.Logger.Error("failed to create room booking", "error", txErr)
+		return nil, nil, s.app.Err.New(errInfos.SQL_ERROR), txErr
+	}
+
+	s.Logger.Info("room booking created", "booking_id", booking.ID, "room_id", booking.RoomID, "type", booking.BookingType)
+	return &booking, nil, nil, nil
+}
+
+// UpdateBooking 更新教室預約
+func (s *RoomBookingService) UpdateBooking(ctx context.Context, centerID, adminID, bookingID uint, req *RoomBookingRequest) (*models.RoomBooking, *ValidationResult, *errInfos.Res, error) {
+	existing, err := s.bookingRepo.GetByIDWithCenterScope(ctx, bookingID, centerID)
+	if err != nil {
+		return nil, nil, s.app.Err.New(errInfos.NOT_FOUND), fmt.Errorf("room booking not found")
+	}
+
+	booking, err := BuildRoomBooking(req)
+	if err != nil {
+		return nil, nil, s.app.Err.New(errInfos.PARAMS_VALIDATE_ERROR), err
+	}
+	booking.ID = existing.ID
+	booking.CenterID = existing.CenterID
+	booking.CreatedBy = existing.CreatedBy
+	booking.CreatedAt = existing.CreatedAt
+
+	if _, err := s.roomRepo.GetByIDWithCenterScope(ctx, booking.RoomID, centerID); err != nil {
+		return nil, nil, s.app.Err.New(errInfos.NOT_FOUND), fmt.Errorf("room not found")
+	}
+
+	validation, err := s.findConflicts(ctx, centerID, booking)
+	if err != nil {
+		return nil, nil, s.app.Err.New(errInfos.SQL_ERROR), err
+	}
+	if !validation.Valid {
+		return nil, &validation, s.app.Err.New(errInfos.SCHED_OVERLAP), fmt.Errorf("room booking conflicts with existing schedule")
+	}
+
+	txErr := s.app.MySQL.WDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 使用 Save 以便清空結束日等欄位
+		if err := tx.Omit("Room").Save(&booking).Error; err != nil {
+			return fmt.Errorf("failed to update room booking: %w", err)
+		}
+
+		auditLog := models.AuditLog{
+			CenterID:   centerID,
+			ActorType:  "ADMIN",
+			ActorID:    adminID,
+			Action:     "UPDATE_ROOM_BOOKING",
+			TargetType: "RoomBooking",
+			TargetID:   booking.ID,
+			Payload:    models.AuditPayload{Before: existing, After: booking},
+		}
+		return tx.Create(&auditLog).Error
+	})
+	if txErr != nil {
+		s.Logger.Error("failed to update room booking", "error", txErr)
+		return nil, nil, s.app.Err.New(errInfos.SQL_ERROR), txErr
+	}
+
+	return &booking, nil, nil, nil
+}
+
+// DeleteBooking 刪除教室預約
+func (s *RoomBookingService) DeleteBooking(ctx context.Context, centerID, adminID, bookingID uint) (*errInfos.Res, error) {
+	existing, err := s.bookingRepo.GetByIDWithCenterScope(ctx, bookingID, centerID)
+	if err != nil {
+		return s.app.Err.New(errInfos.NOT_FOUND), fmt.Errorf("room booking not found")
+	}
+
+	txErr := s.app.MySQL.WDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.RoomBooking{}, bookingID).Error; err != nil {
+			return err
+		}
+
+		auditLog := models.AuditLog{
+			CenterID:   centerID,
+			ActorType:  "ADMIN",
+			ActorID:    adminID,
+			Action:     "DELETE_ROOM_BOOKING",
+			TargetType: "RoomBooking",
+			TargetID:   bookingID,
+			Payload:    models.AuditPayload{Before: existing},
+		}
+		return tx.Create(&auditLog).Error
+	})
+	if txErr != nil {
+		s.Logger.Error("failed to delete room booking", "error", txErr)
+		return s.app.Err.New(errInfos.SQL_ERROR), txErr
+	}
+	return nil, nil
+}
+
+// findConflicts 檢查預約與同教室的排課規則、已安排的補課、已核准的調課及其他預約是否重疊
+func (s *RoomBookingService) findConflicts(ctx context.Context, centerID uint, booking models.RoomBooking) (ValidationResult, error) {
+	result := ValidationResult{Valid: true}
+
+	rules, err := s.ruleRepo.ListByRoomID(ctx, booking.RoomID, centerID)
+	if err != nil {
+		return result, err
+	}
+	for _, rule := range rules {
+		if !RuleCollidesWithBooking(rule, booking) {
+			continue
+		}
+		result.Valid = false
+		result.Conflicts = append(result.Conflicts, ValidationConflict{
+			Type:             "ROOM_OVERLAP",
+			Message:          "教室在該時段已有課程安排",
+			ConflictSource:   "RULE",
+			ConflictSourceID: rule.ID,
+			Details:          fmt.Sprintf("rule_id:%d, offering_id:%d, room_id:%d", rule.ID, rule.OfferingID, booking.RoomID),
+		})
+	}
+
+	_, endStr := bookingDateRange(booking)
+	end, _ := time.Parse("2006-01-02", endStr)
+
+	makeups, err := s.makeupRepo.ListScheduledByRoomAndDateRange(ctx, centerID, booking.RoomID, booking.StartDate, end)
+	if err != nil {
+		return result, err
+	}
+	for _, makeup := range makeups {
+		if !MakeupCollidesWithBooking(makeup, booking) {
+			continue
+		}
+		result.Valid = false
+		result.Conflicts = append(result.Conflicts, MakeupOverlapConflicts(makeup, nil, []uint{booking.RoomID})...)
+	}
+
+	reschedules, err := s.exceptionRepo.ListApprovedReschedulesByRoom(ctx, centerID, booking.RoomID, booking.StartDate, end)
+	if err != nil {
+		return result, err
+	}
+	for _, exception := range reschedules {
+		if !RescheduleCollidesWithBooking(exception, booking) {
+			continue
+		}
+		result.Valid = false
+		result.Conflicts = append(result.Conflicts, ValidationConflict{
+			Type:             "ROOM_OVERLAP",
+			Message:          "教室在該時段已有調課安排",
+			ConflictSource:   "EXCEPTION",
+			ConflictSourceID: exception.ID,
+			Details:          fmt.Sprintf("exception_id:%d, rule_id:%d, room_id:%d", exception.ID, exception.RuleID, booking.RoomID),
+		})
+	}
+
+	others, err := s.bookingRepo.ListOverlapping(ctx, centerID, []uint{booking.RoomID}, booking.StartDate, end)
+	if err != nil {
+		return result, err
+	}
+	for _, other := range others {
+		if other.ID == booking.ID || !RoomBookingsCollide(booking, other) {
+			continue
+		}
+		result.Valid = false
+		result.Conflicts = append(result.Conflicts, roomBookedConflict(other))
+	}
+
+	return result, nil
+}
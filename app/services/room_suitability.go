@@ -0,0 +1,57 @@
+package services
+
+import (
+	"fmt"
+	"timeLedger/app/models"
+)
+
+// EvaluateRoomSuitability 檢查教室是否符合課程需求（容納人數、設備）
+// 回傳不符合的原因，空列表表示教室適用
+func EvaluateRoomSuitability(room models.Room, course models.Course) []string {
+	reasons := make([]string, 0)
+
+	if course.MinCapacity > 0 && room.Capacity < course.MinCapacity {
+		reasons = append(reasons, fmt.Sprintf("容納人數 %d 人，課程需要 %d 人", room.Capacity, course.MinCapacity))
+	}
+
+	for _, required := range course.RequiredFeatures {
+		need := required.Quantity
+		if need <= 0 {
+			need = 1
+		}
+		if have := room.Features.Quantity(required.Tag); have < need {
+			reasons = append(reasons, fmt.Sprintf("設備 %s 數量 %d，課程需要 %d", required.Tag, have, need))
+		}
+	}
+
+	return reasons
+}
+
+// FilterSuitableRooms 過濾出符合課程需求的教室
+func FilterSuitableRooms(rooms []models.Room, course models.Course) []models.Room {
+	suitable := make([]models.Room, 0, len(rooms))
+	for _, room := range rooms {
+		if len(EvaluateRoomSuitability(room, course)) == 0 {
+			suitable = append(suitable, room)
+		}
+	}
+	return suitable
+}
+
+// ValidateRoomFeatures 檢查設備標籤設定
+func ValidateRoomFeatures(features models.RoomFeatures) error {
+	seen := make(map[string]bool, len(features))
+	for _, f := range features {
+		if f.Tag == "" {
+			return fmt.Errorf("feature tag is required")
+		}
+		if f.Quantity < 0 {
+			return fmt.Errorf("feature %s quantity must not be negative", f.Tag)
+		}
+		if seen[f.Tag] {
+			return fmt.Errorf("duplicate feature tag %s", f.Tag)
+		}
+		seen[f.Tag] = true
+	}
+	return nil
+}
@@ -0,0 +1,562 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+	"timeLedger/app"
+	"timeLedger/app/models"
+	"timeLedger/app/repositories"
+	"timeLedger/global/errInfos"
+	"timeLedger/libs"
+)
+
+// 教室佔用來源
+const (
+	OccupancySourceSession = "SESSION" // 排課規則展開的場次
+	OccupancySourceMakeup  = "MAKEUP"  // 補課
+	OccupancySourceBooking = "BOOKING" // 會議、租借、維修等教室預約
+)
+
+// 使用率分析限制
+const (
+	DefaultUtilizationBandMinutes = 60
+	MaxUtilizationRangeDays       = 366
+)
+
+// RoomUtilizationService 教室使用率與容量分析
+type RoomUtilizationService struct {
+	BaseService
+	app               *app.App
+	centerRepo        *repositories.CenterRepository
+	roomRepo          *repositories.RoomRepository
+	ruleRepo          *repositories.ScheduleRuleRepository
+	termRepo          *repositories.CenterTermRepository
+	bookingRepo       *repositories.RoomBookingRepository
+	hoursOverrideRepo *repositories.CenterOperatingHoursOverrideRepository
+	expansionSvc      ScheduleExpansionService
+}
+
+// NewRoomUtilizationService 建立 RoomUtilizationService 實例
+func NewRoomUtilizationService(app *app.App) *RoomUtilizationService {
+	return &RoomUtilizationService{
+		BaseService:       *NewBaseService(app, "RoomUtilizationService"),
+		app:               app,
+		centerRepo:        repositories.NewCenterRepository(app),
+		roomRepo:          repositories.NewRoomRepository(app),
+		ruleRepo:          repositories.NewScheduleRuleRepository(app),
+		termRepo:          repositories.NewCenterTermRepository(app),
+		bookingRepo:       repositories.NewRoomBookingRepository(app),
+		hoursOverrideRepo: repositories.NewCenterOperatingHoursOverrideRepository(app),
+		expansionSvc:      NewScheduleExpansionService(app),
+	}
+}
+
+// RoomUtilizationQuery 使用率查詢條件，指定學期時以學期起訖日為區間
+type RoomUtilizationQuery struct {
+	StartDate   time.Time
+	EndDate     time.Time
+	TermID      uint
+	BandMinutes int
+}
+
+// RoomOccupancy 教室在某一天的一段佔用
+type RoomOccupancy struct {
+	RoomID    uint
+	Date      string // YYYY-MM-DD
+	StartTime string // HH:MM，24:00 表示午夜
+	EndTime   string
+	Source    string // SESSION, MAKEUP, BOOKING
+}
+
+// UtilizationStat 可用與佔用分鐘數
+type UtilizationStat struct {
+	AvailableMinutes int     `json:"available_minutes"`
+	OccupiedMinutes  int     `json:"occupied_minutes"`
+	Rate             float64 `json:"rate"` // 0 - 1
+}
+
+// UtilizationBand 時段的使用率
+type UtilizationBand struct {
+	StartTime string `json:"start_time"`
+	EndTime   string `json:"end_time"`
+	UtilizationStat
+}
+
+// UtilizationCell 熱度圖格子（星期 × 時段）
+type UtilizationCell struct {
+	Weekday   int    `json:"weekday"` // 1=週一 … 7=週日
+	StartTime string `json:"start_time"`
+	EndTime   string `json:"end_time"`
+	UtilizationStat
+}
+
+// RoomUtilization 單一教室的使用率
+type RoomUtilization struct {
+	RoomID         uint              `json:"room_id"`
+	RoomName       string            `json:"room_name"`
+	Capacity       int               `json:"capacity"`
+	SessionMinutes int               `json:"session_minutes"` // 課程與補課佔用
+	BookingMinutes int               `json:"booking_minutes"` // 教室預約佔用
+	Bands          []UtilizationBand `json:"bands"`
+	Heatmap        []UtilizationCell `json:"heatmap"`
+	UtilizationStat
+}
+
+// RoomUtilizationTrend 教室與前一學期的使用率比較
+type RoomUtilizationTrend struct {
+	RoomID       uint    `json:"room_id"`
+	RoomName     string  `json:"room_name"`
+	Rate         float64 `json:"rate"`
+	PreviousRate float64 `json:"previous_rate"`
+	Delta        float64 `json:"delta"`
+}
+
+// UtilizationComparison 與前一學期的比較
+type UtilizationComparison struct {
+	TermID    uint                   `json:"term_id"`
+	TermName  string                 `json:"term_name"`
+	StartDate string                 `json:"start_date"`
+	EndDate   string                 `json:"end_date"`
+	Summary   UtilizationStat        `json:"summary"`
+	RateDelta float64                `json:"rate_delta"`
+	Rooms     []RoomUtilizationTrend `json:"rooms"`
+}
+
+// RoomUtilizationReport 教室使用率報表
+type RoomUtilizationReport struct {
+	StartDate        string                 `json:"start_date"`
+	EndDate          string                 `json:"end_date"`
+	TermID           uint                   `json:"term_id,omitempty"`
+	TermName         string                 `json:"term_name,omitempty"`
+	BandMinutes      int                    `json:"band_minutes"`
+	OpenDays         int                    `json:"open_days"`
+	ClosedDays       int                    `json:"closed_days"`  // 公休或營業時間調整為不營業
+	HolidayDays      int                    `json:"holiday_days"` // 強制停課的假日
+	Summary          UtilizationStat        `json:"summary"`
+	SeatWeightedRate float64                `json:"seat_weighted_rate"` // 依教室容納人數加權的使用率
+	Rooms            []RoomUtilization      `json:"rooms"`
+	Bands            []UtilizationBand      `json:"bands"`
+	Heatmap          []UtilizationCell      `json:"heatmap"`
+	Comparison       *UtilizationComparison `json:"comparison,omitempty"`
+}
+
+// RoomUtilizationInput 使用率計算所需資料
+type RoomUtilizationInput struct {
+	StartDate   time.Time
+	EndDate     time.Time
+	BandMinutes int
+	Rooms       []models.Room
+	// DailyHours 每天的實際營業時間（以日期為 key）
+	DailyHours  map[string]DailyOperatingHours
+	Holidays    []models.CenterHoliday
+	Occupancies []RoomOccupancy
+}
+
+// ValidateUtilizationBand 檢查時段長度，必須能整除一天
+func ValidateUtilizationBand(bandMinutes int) error {
+	if bandMinutes < 15 || bandMinutes > 240 || (24*60)%bandMinutes != 0 {
+		return fmt.Errorf("band_minutes must divide a day and be between 15 and 240")
+	}
+	return nil
+}
+
+// utilizationRate 計算使用率，取到小數第四位
+func utilizationRate(occupied, available int) float64 {
+	if available <= 0 {
+		return 0
+	}
+	return math.Round(float64(occupied)/float64(available)*10000) / 10000
+}
+
+func (u *UtilizationStat) add(available, occupied int) {
+	u.AvailableMinutes += available
+	u.OccupiedMinutes += occupied
+}
+
+func (u *UtilizationStat) finalize() {
+	u.Rate = utilizationRate(u.OccupiedMinutes, u.AvailableMinutes)
+}
+
+// minutesToClock 分鐘數轉為 HH:MM
+func minutesToClock(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+// operatingWindow 取得營業時間的分鐘區間，未設定時視為全天
+func operatingWindow(hours DailyOperatingHours) (int, int) {
+	open, closeAt := 0, 24*60
+	if hours.OpenTime != "" {
+		open = ParseTimeToMinutes(hours.OpenTime)
+	}
+	if hours.CloseTime != "" {
+		closeAt = ParseTimeToMinutes(hours.CloseTime)
+	}
+	return open, closeAt
+}
+
+// CalculateRoomUtilization 依營業時間計算各教室、各時段的使用率
+// 公休日與強制停課的假日不計入可用時間；同一教室重疊的佔用只計算一次
+func CalculateRoomUtilization(input RoomUtilizationInput) RoomUtilizationReport {
+	bandMinutes := input.BandMinutes
+	if bandMinutes <= 0 {
+		bandMinutes = DefaultUtilizationBandMinutes
+	}
+	bandCount := 24 * 60 / bandMinutes
+
+	report := RoomUtilizationReport{
+		StartDate:   input.StartDate.Format("2006-01-02"),
+		EndDate:     input.EndDate.Format("2006-01-02"),
+		BandMinutes: bandMinutes,
+		Rooms:       []RoomUtilization{},
+		Bands:       []UtilizationBand{},
+		Heatmap:     []UtilizationCell{},
+	}
+
+	forceCancel := make(map[string]bool)
+	for _, h := range input.Holidays {
+		if h.ForceCancel {
+			forceCancel[h.Date.Format("2006-01-02")] = true
+		}
+	}
+
+	byRoomDate := make(map[uint]map[string][]RoomOccupancy)
+	for _, occ := range input.Occupancies {
+		if byRoomDate[occ.RoomID] == nil {
+			byRoomDate[occ.RoomID] = make(map[string][]RoomOccupancy)
+		}
+		byRoomDate[occ.RoomID][occ.Date] = append(byRoomDate[occ.RoomID][occ.Date], occ)
+	}
+
+	type openDay struct {
+		date    string
+		weekday int
+		open    int
+		closeAt int
+	}
+	var days []openDay
+	for date := dateOnly(input.StartDate); !date.After(dateOnly(input.EndDate)); date = date.AddDate(0, 0, 1) {
+		dateStr := date.Format("2006-01-02")
+		if forceCancel[dateStr] {
+			report.HolidayDays++
+			continue
+		}
+		hours, ok := input.DailyHours[dateStr]
+		if ok && hours.Closed {
+			report.ClosedDays++
+			continue
+		}
+		open, closeAt := operatingWindow(hours)
+		if closeAt <= open {
+			report.ClosedDays++
+			continue
+		}
+		days = append(days, openDay{date: dateStr, weekday: isoWeekday(date), open: open, closeAt: closeAt})
+	}
+	report.OpenDays = len(days)
+
+	centerBands := make([]UtilizationStat, bandCount)
+	centerHeatmap := make([][]UtilizationStat, 8)
+	for wd := 1; wd <= 7; wd++ {
+		centerHeatmap[wd] = make([]UtilizationStat, bandCount)
+	}
+	var seatAvailable, seatOccupied float64
+
+	rooms := append([]models.Room(nil), input.Rooms...)
+	sort.Slice(rooms, func(i, j int) bool { return rooms[i].ID < rooms[j].ID })
+
+	for _, room := range rooms {
+		ru := RoomUtilization{RoomID: room.ID, RoomName: room.Name, Capacity: room.Capacity}
+		roomBands := make([]UtilizationStat, bandCount)
+		roomHeatmap := make([][]UtilizationStat, 8)
+		for wd := 1; wd <= 7; wd++ {
+			roomHeatmap[wd] = make([]UtilizationStat, bandCount)
+		}
+
+		for _, day := range days {
+			// 每分鐘的佔用旗標：1 = 課程或補課，2 = 教室預約
+			var minutes [24 * 60]uint8
+			for _, occ := range byRoomDate[room.ID][day.date] {
+				start := ParseTimeToMinutes(occ.StartTime)
+				end := ParseTimeToMinutes(occ.EndTime)
+				if end <= start {
+					end = 24 * 60
+				}
+				start = max(start, day.open)
+				end = min(end, day.closeAt)
+				flag := uint8(1)
+				if occ.Source == OccupancySourceBooking {
+					flag = 2
+				}
+				for m := start; m < end; m++ {
+					minutes[m] |= flag
+				}
+			}
+
+			for b := 0; b < bandCount; b++ {
+				from := max(b*bandMinutes, day.open)
+				to := min((b+1)*bandMinutes, day.closeAt)
+				if to <= from {
+					continue
+				}
+				occupied := 0
+				for m := from; m < to; m++ {
+					if minutes[m] != 0 {
+						occupied++
+					}
+					if minutes[m]&1 != 0 {
+						ru.SessionMinutes++
+					}
+					if minutes[m]&2 != 0 {
+						ru.BookingMinutes++
+					}
+				}
+				available := to - from
+				ru.add(available, occupied)
+				roomBands[b].add(available, occupied)
+				roomHeatmap[day.weekday][b].add(available, occupied)
+				centerBands[b].add(available, occupied)
+				centerHeatmap[day.weekday][b].add(available, occupied)
+				report.Summary.add(available, occupied)
+			}
+		}
+
+		ru.finalize()
+		ru.Bands = buildUtilizationBands(roomBands, bandMinutes)
+		ru.Heatmap = buildUtilizationHeatmap(roomHeatmap, bandMinutes)
+		seatAvailable += float64(ru.AvailableMinutes * room.Capacity)
+		seatOccupied += float64(ru.OccupiedMinutes * room.Capacity)
+		report.Rooms = append(report.Rooms, ru)
+	}
+
+	report.Summary.finalize()
+	if seatAvailable > 0 {
+		report.SeatWeightedRate = math.Round(seatOccupied/seatAvailable*10000) / 10000
+	}
+	report.Bands = buildUtilizationBands(centerBands, bandMinutes)
+	report.Heatmap = buildUtilizationHeatmap(centerHeatmap, bandMinutes)
+	return report
+}
+
+// buildUtilizationBands 只輸出有營業的時段
+func buildUtilizationBands(stats []UtilizationStat, bandMinutes int) []UtilizationBand {
+	bands := []UtilizationBand{}
+	for b, stat := range stats {
+		if stat.AvailableMinutes == 0 {
+			continue
+		}
+		stat.finalize()
+		bands = append(bands, UtilizationBand{
+			StartTime:       minutesToClock(b * bandMinutes),
+			EndTime:         minutesToClock((b + 1) * bandMinutes),
+			UtilizationStat: stat,
+		})
+	}
+	return bands
+}
+
+// buildUtilizationHeatmap 依星期、時段排序輸出熱度圖，略過沒有營業的格子
+func buildUtilizationHeatmap(stats [][]UtilizationStat, bandMinutes int) []UtilizationCell {
+	cells := []UtilizationCell{}
+	for wd := 1; wd <= 7; wd++ {
+		for b, stat := range stats[wd] {
+			if stat.AvailableMinutes == 0 {
+				continue
+			}
+			stat.finalize()
+			cells = append(cells, UtilizationCell{
+				Weekday:         wd,
+				StartTime:       minutesToClock(b * bandMinutes),
+				EndTime:         minutesToClock((b + 1) * bandMinutes),
+				UtilizationStat: stat,
+			})
+		}
+	}
+	return cells
+}
+
+// FindPreviousTerm 找出在指定日期前結束的最近一個學期
+func FindPreviousTerm(terms []models.CenterTerm, before time.Time) *models.CenterTerm {
+	beforeStr := before.Format("2006-01-02")
+	var previous *models.CenterTerm
+	for i := range terms {
+		t := &terms[i]
+		if t.EndDate.Format("2006-01-02") >= beforeStr {
+			continue
+		}
+		if previous == nil || t.EndDate.After(previous.EndDate) {
+			previous = t
+		}
+	}
+	return previous
+}
+
+// CompareRoomUtilization 比較本期與前一學期的使用率
+func CompareRoomUtilization(current, previous RoomUtilizationReport, term models.CenterTerm) *UtilizationComparison {
+	comparison := &UtilizationComparison{
+		TermID:    term.ID,
+		TermName:  term.Name,
+		StartDate: previous.StartDate,
+		EndDate:   previous.EndDate,
+		Summary:   previous.Summary,
+		RateDelta: math.Round((current.Summary.Rate-previous.Summary.Rate)*10000) / 10000,
+		Rooms:     []RoomUtilizationTrend{},
+	}
+
+	previousRates := make(map[uint]float64, len(previous.Rooms))
+	for _, r := range previous.Rooms {
+		previousRates[r.RoomID] = r.Rate
+	}
+	for _, r := range current.Rooms {
+		prev := previousRates[r.RoomID]
+		comparison.Rooms = append(comparison.Rooms, RoomUtilizationTrend{
+			RoomID:       r.RoomID,
+			RoomName:     r.RoomName,
+			Rate:         r.Rate,
+			PreviousRate: prev,
+			Delta:        math.Round((r.Rate-prev)*10000) / 10000,
+		})
+	}
+	return comparison
+}
+
+// GetRoomUtilization 取得教室使用率報表，並與前一學期比較
+func (s *RoomUtilizationService) GetRoomUtilization(ctx context.Context, centerID uint, query RoomUtilizationQuery) (*RoomUtilizationReport, *errInfos.Res, error) {
+	loc := libs.GetTaiwanLocation()
+	startDate := time.Date(query.StartDate.Year(), query.StartDate.Month(), query.StartDate.Day(), 0, 0, 0, 0, loc)
+	endDate := time.Date(query.EndDate.Year(), query.EndDate.Month(), query.EndDate.Day(), 0, 0, 0, 0, loc)
+
+	var currentTerm *models.CenterTerm
+	if query.TermID > 0 {
+		term, err := s.termRepo.GetByIDWithCenterScope(ctx, query.TermID, centerID)
+		if err != nil {
+			return nil, s.app.Err.New(errInfos.NOT_FOUND), fmt.Errorf("term not found")
+		}
+		currentTerm = &term
+		startDate, endDate = term.StartDate, term.EndDate
+	}
+
+	if endDate.Before(startDate) {
+		return nil, s.app.Err.New(errInfos.PARAMS_VALIDATE_ERROR), fmt.Errorf("end_date must not be before start_date")
+	}
+	if endDate.Sub(startDate) > time.Duration(MaxUtilizationRangeDays)*24*time.Hour {
+		return nil, s.app.Err.New(errInfos.PARAMS_VALIDATE_ERROR), fmt.Errorf("date range must not exceed %d days", MaxUtilizationRangeDays)
+	}
+
+	bandMinutes := query.BandMinutes
+	if bandMinutes == 0 {
+		bandMinutes = DefaultUtilizationBandMinutes
+	}
+	if err := ValidateUtilizationBand(bandMinutes); err != nil {
+		return nil, s.app.Err.New(errInfos.PARAMS_VALIDATE_ERROR), err
+	}
+
+	allRooms, err := s.roomRepo.ListByCenterID(ctx, centerID)
+	if err != nil {
+		return nil, s.app.Err.New(errInfos.SQL_ERROR), err
+	}
+	rooms := make([]models.Room, 0, len(allRooms))
+	for _, r := range allRooms {
+		if r.IsActive {
+			rooms = append(rooms, r)
+		}
+	}
+
+	report, err := s.buildReport(ctx, centerID, rooms, startDate, endDate, bandMinutes)
+	if err != nil {
+		return nil, s.app.Err.New(errInfos.SQL_ERROR), err
+	}
+	if currentTerm != nil {
+		report.TermID = currentTerm.ID
+		report.TermName = currentTerm.Name
+	}
+
+	terms, err := s.termRepo.ListByCenterID(ctx, centerID)
+	if err != nil {
+		return nil, s.app.Err.New(errInfos.SQL_ERROR), err
+	}
+	if previous := FindPreviousTerm(terms, startDate); previous != nil {
+		previousReport, err := s.buildReport(ctx, centerID, rooms, previous.StartDate, previous.EndDate, bandMinutes)
+		if err != nil {
+			return nil, s.app.Err.New(errInfos.SQL_ERROR), err
+		}
+		report.Comparison = CompareRoomUtilization(report, previousReport, *previous)
+	}
+
+	return &report, nil, nil
+}
+
+// buildReport 載入區間內的營業時間、假日與各類佔用後計算使用率
+func (s *RoomUtilizationService) buildReport(ctx context.Context, centerID uint, rooms []models.Room, startDate, endDate time.Time, bandMinutes int) (RoomUtilizationReport, error) {
+	var settings *models.CenterSettings
+	if center, err := s.centerRepo.GetByID(ctx, centerID); err == nil {
+		settings = &center.Settings
+	}
+
+	overrides, err := s.hoursOverrideRepo.ListOverlapping(ctx, centerID, startDate, endDate)
+	if err != nil {
+		return RoomUtilizationReport{}, err
+	}
+	dailyHours := make(map[string]DailyOperatingHours)
+	for date := startDate; !date.After(endDate); date = date.AddDate(0, 0, 1) {
+		hours := ResolveOperatingHours(settings, overrides, date)
+		dailyHours[hours.Date] = hours
+	}
+
+	holidays, err := s.expansionSvc.GetHolidaysByDateRange(ctx, centerID, startDate, endDate)
+	if err != nil {
+		return RoomUtilizationReport{}, err
+	}
+
+	rules, err := s.ruleRepo.ListByCenterID(ctx, centerID)
+	if err != nil {
+		return RoomUtilizationReport{}, err
+	}
+
+	var occupancies []RoomOccupancy
+	// 停課（假日、暫停日、停課例外單）的場次在展開時已排除，已安排的補課一併展開
+	for _, schedule := range s.expansionSvc.ExpandRules(ctx, rules, startDate, endDate, centerID) {
+		if schedule.Status == models.RuleStatusSuspended || schedule.Status == models.RuleStatusArchived {
+			continue
+		}
+		source := OccupancySourceSession
+		if schedule.MakeupID != nil {
+			source = OccupancySourceMakeup
+		}
+		for _, roomID := range schedule.AllRoomIDs() {
+			occupancies = append(occupancies, RoomOccupancy{
+				RoomID:    roomID,
+				Date:      schedule.Date.Format("2006-01-02"),
+				StartTime: schedule.StartTime,
+				EndTime:   schedule.EndTime,
+				Source:    source,
+			})
+		}
+	}
+
+	bookings, err := s.bookingRepo.ListOverlapping(ctx, centerID, nil, startDate, endDate)
+	if err != nil {
+		return RoomUtilizationReport{}, err
+	}
+	for _, b := range ExpandRoomBookings(bookings, startDate, endDate) {
+		occupancies = append(occupancies, RoomOccupancy{
+			RoomID:    b.RoomID,
+			Date:      b.Date,
+			StartTime: b.StartTime,
+			EndTime:   b.EndTime,
+			Source:    OccupancySourceBooking,
+		})
+	}
+
+	return CalculateRoomUtilization(RoomUtilizationInput{
+		StartDate:   startDate,
+		EndDate:     endDate,
+		BandMinutes: bandMinutes,
+		Rooms:       rooms,
+		DailyHours:  dailyHours,
+		Holidays:    holidays,
+		Occupancies: occupancies,
+	}), nil
+}
@@ -129,6 +129,7 @@ func (s *scheduleQueryService) GetTeacherSchedule(ctx context.Context, teacherID
 					Status:         status,
 					RuleID:         item.RuleID,
 					IsCrossDayPart: item.IsCrossDayPart,
+					Role:           item.TeacherRole(teacherID),
 				})
 			}
 		}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"timeLedger/app/models"
+)
+
+// NormalizeTeacherAssignments 檢查協同老師設定，未填寫角色時預設為助教
+// 重複的老師只保留第一筆
+func NormalizeTeacherAssignments(assignments []models.TeacherAssignment) ([]models.TeacherAssignment, error) {
+	result := make([]models.TeacherAssignment, 0, len(assignments))
+	seen := make(map[uint]bool, len(assignments))
+	for _, a := range assignments {
+		if a.TeacherID == 0 {
+			return nil, fmt.Errorf("teacher_id is required for additional teachers")
+		}
+		if a.Role == "" {
+			a.Role = models.TeacherRoleAssistant
+		}
+		if !models.IsValidTeacherRole(a.Role) {
+			return nil, fmt.Errorf("invalid teacher role: %s", a.Role)
+		}
+		if seen[a.TeacherID] {
+			continue
+		}
+		seen[a.TeacherID] = true
+		result = append(result, a)
+	}
+	return result, nil
+}
+
+// ruleAssigneeIDs 合併主教老師、主要教室與額外指派，回傳不重複的老師與教室 ID
+func ruleAssigneeIDs(leadTeacherID *uint, primaryRoomID uint, teachers []models.TeacherAssignment, roomIDs []uint) ([]uint, []uint) {
+	rule := models.ScheduleRule{TeacherID: leadTeacherID, RoomID: primaryRoomID}
+	for _, t := range teachers {
+		rule.AdditionalTeachers = append(rule.AdditionalTeachers, models.ScheduleRuleTeacher{TeacherID: t.TeacherID, Role: t.Role})
+	}
+	seen := make(map[uint]bool, len(roomIDs))
+	for _, id := range roomIDs {
+		if id == 0 || seen[id] {
+			continue
+		}
+		seen[id] = true
+		rule.AdditionalRooms = append(rule.AdditionalRooms, models.ScheduleRuleRoom{RoomID: id})
+	}
+	return rule.AllTeacherIDs(), rule.AllRoomIDs()
+}
+
+// additionalTeachersOf 取得規則現有的協同老師設定
+func additionalTeachersOf(rule models.ScheduleRule) []models.TeacherAssignment {
+	assignments := make([]models.TeacherAssignment, 0, len(rule.AdditionalTeachers))
+	for _, t := range rule.AdditionalTeachers {
+		assignments = append(assignments, models.TeacherAssignment{TeacherID: t.TeacherID, Role: t.Role})
+	}
+	return assignments
+}
+
+// additionalRoomIDsOf 取得規則現有的其他教室
+func additionalRoomIDsOf(rule models.ScheduleRule) []uint {
+	ids := make([]uint, 0, len(rule.AdditionalRooms))
+	for _, r := range rule.AdditionalRooms {
+		ids = append(ids, r.RoomID)
+	}
+	return ids
+}
+
+// checkRoomsSuitability 檢查多間教室是否都符合課程需求
+func (s *ScheduleService) checkRoomsSuitability(ctx context.Context, centerID uint, roomIDs []uint, courseID uint) (bool, error) {
+	for _, roomID := range roomIDs {
+		result, err := s.validationSvc.CheckRoomSuitability(ctx, centerID, roomID, courseID)
+		if err != nil {
+			return false, err
+		}
+		if !result.Valid {
+			return false, nil
+		}
+	}
+	return true, nil
+}
@@ -16,10 +16,12 @@ import (
 // ScheduleRuleValidator 統一的排課規則驗證服務
 // 整合所有檢查邏輯（重疊、緩衝、個人行程）
 type ScheduleRuleValidator struct {
-	app                *app.App
-	validationService  ScheduleValidationService
-	scheduleRuleRepo   *models.ScheduleRule
-	offeringRepo       *repositories.OfferingRepository
+	app               *app.App
+	validationService ScheduleValidationService
+	scheduleRuleRepo  *models.ScheduleRule
+	offeringRepo      *repositories.OfferingRepository
+	roomRepo          *repositories.RoomRepository
+	courseRepo        *repositories.CourseRepository
 }
 
 // NewScheduleRuleValidator 建立統一的驗證服務
@@ -28,39 +30,41 @@ func NewScheduleRuleValidator(app *app.App) *ScheduleRuleValidator {
 		app:               app,
 		validationService: NewScheduleValidationService(app),
 		offeringRepo:      repositories.NewOfferingRepository(app),
+		roomRepo:          repositories.NewRoomRepository(app),
+		courseRepo:        repositories.NewCourseRepository(app),
 	}
 }
 
 // ValidationSummary 驗證結果摘要
 type ValidationSummary struct {
-	Valid             bool                  `json:"valid"`
-	OverlapConflicts  []OverlapInfo         `json:"overlap_conflicts,omitempty"`
-	BufferConflicts   []BufferInfo          `json:"buffer_conflicts,omitempty"`
-	AllConflicts      []ConflictInfo        `json:"all_conflicts,omitempty"`
+	Valid            bool           `json:"valid"`
+	OverlapConflicts []OverlapInfo  `json:"overlap_conflicts,omitempty"`
+	BufferConflicts  []BufferInfo   `json:"buffer_conflicts,omitempty"`
+	AllConflicts     []ConflictInfo `json:"all_conflicts,omitempty"`
 }
 
 // OverlapInfo 重疊衝突資訊
 type OverlapInfo struct {
-	Weekday   int    `json:"weekday"`
-	StartTime string `json:"start_time"`
-	EndTime   string `json:"end_time"`
-	RuleID    uint   `json:"rule_id,omitempty"`
-	RuleWeekday int  `json:"rule_weekday,omitempty"`
+	Weekday      int    `json:"weekday"`
+	StartTime    string `json:"start_time"`
+	EndTime      string `json:"end_time"`
+	RuleID       uint   `json:"rule_id,omitempty"`
+	RuleWeekday  int    `json:"rule_weekday,omitempty"`
 	ConflictType string `json:"conflict_type"` // "ROOM_OVERLAP", "TEACHER_OVERLAP"
-	Message   string `json:"message"`
+	Message      string `json:"message"`
 }
 
 // BufferInfo 緩衝衝突資訊
 type BufferInfo struct {
-	Weekday        int    `json:"weekday"`
-	StartTime      string `json:"start_time"`
-	EndTime        string `json:"end_time"`
-	PrevEndTime    string `json:"prev_end_time,omitempty"`
-	RequiredMinutes int   `json:"required_minutes"`
-	GapMinutes     int    `json:"gap_minutes"`
-	ConflictType   string `json:"conflict_type"` // "TEACHER_BUFFER", "ROOM_BUFFER"
-	Message        string `json:"message"`
-	CanOverride    bool   `json:"can_override"`
+	Weekday         int    `json:"weekday"`
+	StartTime       string `json:"start_time"`
+	EndTime         string `json:"end_time"`
+	PrevEndTime     string `json:"prev_end_time,omitempty"`
+	RequiredMinutes int    `json:"required_minutes"`
+	GapMinutes      int    `json:"gap_minutes"`
+	ConflictType    string `json:"conflict_type"` // "TEACHER_BUFFER", "ROOM_BUFFER"
+	Message         string `json:"message"`
+	CanOverride     bool   `json:"can_override"`
 }
 
 // ConflictInfo 完整衝突資訊（用於 ApplyTemplate）
@@ -68,10 +72,12 @@ type ConflictInfo struct {
 	Weekday      int    `json:"weekday"`
 	StartTime    string `json:"start_time"`
 	EndTime      string `json:"end_time"`
-	ConflictType string `json:"conflict_type"` // "ROOM_OVERLAP", "TEACHER_OVERLAP", "PERSONAL_EVENT", "TEACHER_BUFFER", "ROOM_BUFFER"
+	ConflictType string `json:"conflict_type"` // "ROOM_OVERLAP", "TEACHER_OVERLAP", "PERSONAL_EVENT", "TEACHER_BUFFER", "ROOM_BUFFER", "ROOM_UNSUITABLE"
 	Message      string `json:"message"`
 	RuleID       uint   `json:"rule_id,omitempty"`
 	CanOverride  bool   `json:"can_override,omitempty"`
+	// SuggestedRooms 教室不適用時，該時段符合課程需求且未被佔用的教室
+	SuggestedRooms []RoomInfo `json:"suggested_rooms,omitempty"`
 }
 
 // ValidateForApplyTemplate 驗證模板套用的衝突
@@ -99,6 +105,9 @@ func (v *ScheduleRuleValidator) ValidateForApplyTemplate(ctx context.Context, ce
 		}
 	}
 
+	// 教室適用性與星期無關，同一教室只檢查一次
+	suitabilityByRoom := make(map[uint]ValidationResult)
+
 	for _, weekday := range weekdays {
 		// 找到該 weekday 的第一個日期（使用中央時區計算）
 		current := parsedStartDate
@@ -114,6 +123,35 @@ func (v *ScheduleRuleValidator) ValidateForApplyTemplate(ctx context.Context, ce
 				roomID = *cell.RoomID
 			}
 
+			// 檢查教室是否符合課程需求
+			suitability, checked := suitabilityByRoom[roomID]
+			if !checked {
+				var err error
+				suitability, err = v.validationService.CheckRoomSuitability(ctx, centerID, roomID, courseID)
+				if err != nil {
+					return nil, fmt.Errorf("failed to check room suitability: %w", err)
+				}
+				suitabilityByRoom[roomID] = suitability
+			}
+			if !suitability.Valid {
+				suggestedRooms, err := v.suggestSuitableRooms(ctx, centerID, courseID, weekday, cell.StartTime, cell.EndTime, targetDate)
+				if err != nil {
+					return nil, fmt.Errorf("failed to suggest rooms: %w", err)
+				}
+				for _, sc := range suitability.Conflicts {
+					summary.AllConflicts = append(summary.AllConflicts, ConflictInfo{
+						Weekday:        weekday,
+						StartTime:      cell.StartTime,
+						EndTime:        cell.EndTime,
+						ConflictType:   "ROOM_UNSUITABLE",
+						Message:        fmt.Sprintf("%s %s-%s %s", dayNames[weekday], cell.StartTime, cell.EndTime, sc.Message),
+						CanOverride:    false, // 教室不適用不可覆蓋
+						SuggestedRooms: suggestedRooms,
+					})
+				}
+				summary.Valid = false
+			}
+
 			// 檢查 Room 和 Teacher Overlap
 			overlappingRules, personalEventConflicts, err := v.checkOverlap(ctx, centerID, roomID, cell.TeacherID, weekday, cell.StartTime, cell.EndTime, targetDate)
 			if err != nil {
@@ -123,7 +161,7 @@ func (v *ScheduleRuleValidator) ValidateForApplyTemplate(ctx context.Context, ce
 			// 處理 ScheduleRule 衝突
 			for _, rule := range overlappingRules {
 				conflictType := "ROOM_OVERLAP"
-				if rule.TeacherID != nil && cell.TeacherID != nil && *rule.TeacherID == *cell.TeacherID {
+				if cell.TeacherID != nil && rule.HasTeacher(*cell.TeacherID) {
 					conflictType = "TEACHER_OVERLAP"
 				}
 
@@ -284,7 +322,7 @@ func (v *ScheduleRuleValidator) ValidateForCreateRule(ctx context.Context, cente
 		// 處理 ScheduleRule 衝突
 		for _, rule := range overlappingRules {
 			conflictType := "ROOM_OVERLAP"
-			if rule.TeacherID != nil && teacherID != nil && *rule.TeacherID == *teacherID {
+			if teacherID != nil && rule.HasTeacher(*teacherID) {
 				conflictType = "TEACHER_OVERLAP"
 			}
 
@@ -296,12 +334,12 @@ func (v *ScheduleRuleValidator) ValidateForCreateRule(ctx context.Context, cente
 			}
 
 			summary.OverlapConflicts = append(summary.OverlapConflicts, OverlapInfo{
-				Weekday:     weekday,
-				StartTime:   startTime,
-				EndTime:     endTime,
-				RuleID:      rule.ID,
+				Weekday:      weekday,
+				StartTime:    startTime,
+				EndTime:      endTime,
+				RuleID:       rule.ID,
 				ConflictType: conflictType,
-				Message:     msg,
+				Message:      msg,
 			})
 			summary.Valid = false
 		}
@@ -315,11 +353,11 @@ func (v *ScheduleRuleValidator) ValidateForCreateRule(ctx context.Context, cente
 				event.Title)
 
 			summary.OverlapConflicts = append(summary.OverlapConflicts, OverlapInfo{
-				Weekday:     weekday,
-				StartTime:   startTime,
-				EndTime:     endTime,
+				Weekday:      weekday,
+				StartTime:    startTime,
+				EndTime:      endTime,
 				ConflictType: "PERSONAL_EVENT",
-				Message:     msg,
+				Message:      msg,
 			})
 			summary.Valid = false
 		}
@@ -346,15 +384,15 @@ func (v *ScheduleRuleValidator) ValidateForCreateRule(ctx context.Context, cente
 					if !bufferResult.Valid {
 						for _, bc := range bufferResult.Conflicts {
 							summary.BufferConflicts = append(summary.BufferConflicts, BufferInfo{
-								Weekday:        weekday,
-								StartTime:      startTime,
-								EndTime:        endTime,
-								PrevEndTime:    prevEndTime.Format("15:04"),
+								Weekday:         weekday,
+								StartTime:       startTime,
+								EndTime:         endTime,
+								PrevEndTime:     prevEndTime.Format("15:04"),
 								RequiredMinutes: bc.RequiredMinutes,
-								GapMinutes:     bc.DiffMinutes,
-								ConflictType:   "TEACHER_BUFFER",
-								Message:        bc.Message,
-								CanOverride:    bc.CanOverride && allowOverride,
+								GapMinutes:      bc.DiffMinutes,
+								ConflictType:    "TEACHER_BUFFER",
+								Message:         bc.Message,
+								CanOverride:     bc.CanOverride && allowOverride,
 							})
 							// 如果不允許覆蓋，則標記為無效
 							if !allowOverride || !bc.CanOverride {
@@ -377,15 +415,15 @@ func (v *ScheduleRuleValidator) ValidateForCreateRule(ctx context.Context, cente
 					if !bufferResult.Valid {
 						for _, bc := range bufferResult.Conflicts {
 							summary.BufferConflicts = append(summary.BufferConflicts, BufferInfo{
-								Weekday:        weekday,
-								StartTime:      startTime,
-								EndTime:        endTime,
-								PrevEndTime:    prevRoomEndTime.Format("15:04"),
+								Weekday:         weekday,
+								StartTime:       startTime,
+								EndTime:         endTime,
+								PrevEndTime:     prevRoomEndTime.Format("15:04"),
 								RequiredMinutes: bc.RequiredMinutes,
-								GapMinutes:     bc.DiffMinutes,
-								ConflictType:   "ROOM_BUFFER",
-								Message:        bc.Message,
-								CanOverride:    bc.CanOverride && allowOverride,
+								GapMinutes:      bc.DiffMinutes,
+								ConflictType:    "ROOM_BUFFER",
+								Message:         bc.Message,
+								CanOverride:     bc.CanOverride && allowOverride,
 							})
 							// 如果不允許覆蓋，則標記為無效
 							if !allowOverride || !bc.CanOverride {
@@ -401,57 +439,57 @@ func (v *ScheduleRuleValidator) ValidateForCreateRule(ctx context.Context, cente
 	return summary, nil
 }
 
+// suggestSuitableRooms 取得符合課程需求且該時段未被佔用的教室
+func (v *ScheduleRuleValidator) suggestSuitableRooms(ctx context.Context, centerID, courseID uint, weekday int, startTime, endTime string, checkDate time.Time) ([]RoomInfo, error) {
+	course, err := v.courseRepo.GetByIDWithCenterScope(ctx, courseID, centerID)
+	if err != nil {
+		return nil, err
+	}
+	rooms, err := v.roomRepo.ListActiveByCenterID(ctx, centerID)
+	if err != nil {
+		return nil, err
+	}
+
+	suggested := make([]RoomInfo, 0)
+	for _, room := range FilterSuitableRooms(rooms, course) {
+		overlapping, _, err := v.checkOverlap(ctx, centerID, room.ID, nil, weekday, startTime, endTime, checkDate)
+		if err != nil {
+			return nil, err
+		}
+		if len(overlapping) == 0 {
+			suggested = append(suggested, RoomInfo{ID: room.ID, Name: room.Name})
+		}
+	}
+	return suggested, nil
+}
+
 // checkOverlap 檢查時間重疊（封裝 Repository 的 CheckOverlap）
 func (v *ScheduleRuleValidator) checkOverlap(ctx context.Context, centerID uint, roomID uint, teacherID *uint, weekday int, startTime, endTime string, checkDate time.Time) ([]models.ScheduleRule, []models.PersonalEvent, error) {
 	// 使用 ScheduleRuleRepository 的 CheckOverlap 方法
 	// 這裡需要從 app 取得 repository
 	// 由於 validator 不直接持有 repository，我們使用 validationService 的方式來處理
-	
+
 	// 簡化實作：直接使用資料庫查詢
 	var overlappingRules []models.ScheduleRule
 	var personalEventConflicts []models.PersonalEvent
 
-	// 查詢教室重疊
-	roomQuery := v.app.MySQL.RDB.WithContext(ctx).
+	// 查詢教室或老師重疊（含協同授課老師與同時使用的其他教室）
+	var teacherIDs []uint
+	if teacherID != nil && *teacherID > 0 {
+		teacherIDs = []uint{*teacherID}
+	}
+	if err := v.app.MySQL.RDB.WithContext(ctx).
+		Scopes(repositories.PreloadRuleAssignees, repositories.RuleHasAnyAssignee(teacherIDs, []uint{roomID})).
 		Where("center_id = ?", centerID).
-		Where("room_id = ?", roomID).
 		Where("weekday = ?", weekday).
 		Where("deleted_at IS NULL").
 		Where("start_time < ?", endTime).
-		Where("end_time > ?", startTime)
-
-	var roomRules []models.ScheduleRule
-	if err := roomQuery.Find(&roomRules).Error; err != nil {
+		Where("end_time > ?", startTime).
+		Find(&overlappingRules).Error; err != nil {
 		return nil, nil, err
 	}
-	overlappingRules = append(overlappingRules, roomRules...)
 
-	// 查詢老師重疊
 	if teacherID != nil && *teacherID > 0 {
-		teacherQuery := v.app.MySQL.RDB.WithContext(ctx).
-			Where("center_id = ?", centerID).
-			Where("teacher_id = ?", *teacherID).
-			Where("weekday = ?", weekday).
-			Where("deleted_at IS NULL").
-			Where("start_time < ?", endTime).
-			Where("end_time > ?", startTime)
-
-		var teacherRules []models.ScheduleRule
-		if err := teacherQuery.Find(&teacherRules).Error; err != nil {
-			return nil, nil, err
-		}
-		
-		// 避免重複（如果 room 和 teacher 相同）
-		existingIDs := make(map[uint]bool)
-		for _, r := range overlappingRules {
-			existingIDs[r.ID] = true
-		}
-		for _, r := range teacherRules {
-			if !existingIDs[r.ID] {
-				overlappingRules = append(overlappingRules, r)
-			}
-		}
-
 		// 查詢個人行程衝突
 		personalEventRepo := repositories.NewPersonalEventRepository(v.app)
 		events, err := personalEventRepo.CheckPersonalEventConflict(ctx, *teacherID, weekday, startTime, endTime, checkDate)
@@ -475,11 +513,11 @@ func (v *ScheduleRuleValidator) getPreviousSessionEndTime(ctx context.Context, c
 	var rule models.ScheduleRule
 	query := v.app.MySQL.RDB.WithContext(ctx).
 		Where("center_id = ?", centerID).
-		Where("teacher_id = ?", teacherID).
+		Scopes(repositories.RuleHasTeacher(teacherID)).
 		Where("weekday = ?", weekdayVal).
 		Where("end_time <= ?", beforeTimeStr).
 		Order("end_time DESC")
-	
+
 	err := query.First(&rule).Error
 
 	if err != nil {
@@ -528,7 +566,7 @@ func (v *ScheduleRuleValidator) getPreviousRoomSessionEndTime(ctx context.Contex
 	var rule models.ScheduleRule
 	err := v.app.MySQL.RDB.WithContext(ctx).
 		Where("center_id = ?", centerID).
-		Where("room_id = ?", roomID).
+		Scopes(repositories.RuleHasRoom(roomID)).
 		Where("weekday = ?", weekdayVal).
 		Where("end_time <= ?", beforeTimeStr).
 		Order("end_time DESC").
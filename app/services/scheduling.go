@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -70,6 +71,9 @@ type CreateScheduleRuleRequest struct {
 	EndDate        *string `json:"end_date"`
 	Status         string  `json:"status"`
 	OverrideBuffer bool    `json:"override_buffer"`
+	// 協同授課老師與同時使用的其他教室
+	AdditionalTeachers []models.TeacherAssignment `json:"additional_teachers"`
+	AdditionalRoomIDs  []uint                     `json:"additional_room_ids"`
 }
 
 // UpdateScheduleRuleRequest 更新排課規則請求
@@ -88,6 +92,11 @@ type UpdateScheduleRuleRequest struct {
 	Status         string   `json:"status"`
 	UpdateMode     string   `json:"update_mode"`
 	ExcludeRuleID  *uint    `json:"exclude_rule_id"` // 更新時排除自己，避免與自己衝突
+	// 協同授課老師與其他教室，nil 表示維持原設定
+	AdditionalTeachers *[]models.TeacherAssignment `json:"additional_teachers"`
+	AdditionalRoomIDs  *[]uint                     `json:"additional_room_ids"`
+	// Version 前端讀取時的規則版本（If-Match），nil 表示不比對
+	Version *uint `json:"version"`
 }
 
 // CreateExceptionRequest 建立例外請求
@@ -199,12 +208,16 @@ type ScheduleService struct {
 	auditLogRepo      *repositories.AuditLogRepository
 	holidayRepo       *repositories.CenterHolidayRepository
 	teacherRepo       *repositories.TeacherRepository
+	hoursOverrideRepo *repositories.CenterOperatingHoursOverrideRepository
+	roomBookingRepo   *repositories.RoomBookingRepository
 	validationSvc     ScheduleValidationService
 	expansionSvc      ScheduleExpansionService
 	exceptionSvc      ScheduleExceptionService
 	notificationSvc   NotificationService
 	notificationQueue NotificationQueueService
 	cacheSvc          *CacheService
+	liveEvents        *LiveEventService
+	domainEvents      *DomainEventService
 }
 
 // NewScheduleService 建立排課服務
@@ -221,12 +234,16 @@ func NewScheduleService(app *app.App) ScheduleServiceInterface {
 		auditLogRepo:      repositories.NewAuditLogRepository(app),
 		holidayRepo:       repositories.NewCenterHolidayRepository(app),
 		teacherRepo:       repositories.NewTeacherRepository(app),
+		hoursOverrideRepo: repositories.NewCenterOperatingHoursOverrideRepository(app),
+		roomBookingRepo:   repositories.NewRoomBookingRepository(app),
 		validationSvc:     NewScheduleValidationService(app),
 		expansionSvc:      NewScheduleExpansionService(app),
 		exceptionSvc:      NewScheduleExceptionService(app),
 		notificationSvc:   NewNotificationService(app),
 		notificationQueue: NewNotificationQueueService(app),
 		cacheSvc:          NewCacheService(app),
+		liveEvents:        NewLiveEventService(app),
+		domainEvents:      NewDomainEventService(app),
 	}
 	return svc
 }
@@ -268,6 +285,14 @@ func (s *ScheduleService) CreateRule(ctx context.Context, centerID, adminID uint
 		return nil, s.App.Err.New(errInfos.PARAMS_VALIDATE_ERROR), fmt.Errorf("invalid status: %s", req.Status)
 	}
 
+	// 驗證協同老師角色
+	additionalTeachers, err := NormalizeTeacherAssignments(req.AdditionalTeachers)
+	if err != nil {
+		return nil, s.App.Err.New(errInfos.PARAMS_VALIDATE_ERROR), err
+	}
+	req.AdditionalTeachers = additionalTeachers
+	teacherIDs, roomIDs := ruleAssigneeIDs(req.TeacherID, req.RoomID, req.AdditionalTeachers, req.AdditionalRoomIDs)
+
 	// 使用請求中的第一個 weekday 進行重疊檢查
 	var checkWeekday int
 	if len(req.Weekdays) > 0 {
@@ -279,7 +304,7 @@ func (s *ScheduleService) CreateRule(ctx context.Context, centerID, adminID uint
 		}
 	}
 
-	validationResult, err := s.validationSvc.CheckOverlap(ctx, centerID, req.TeacherID, req.RoomID, startTimeParsed, endTimeParsed, checkWeekday, nil)
+	validationResult, err := s.validationSvc.CheckAssigneesOverlap(ctx, centerID, teacherIDs, roomIDs, startTimeParsed, endTimeParsed, checkWeekday, nil)
 	if err != nil {
 		return nil, s.App.Err.New(errInfos.SQL_ERROR), fmt.Errorf("failed to check overlap: %w", err)
 	}
@@ -288,12 +313,39 @@ func (s *ScheduleService) CreateRule(ctx context.Context, centerID, adminID uint
 		return nil, s.App.Err.New(errInfos.SCHED_OVERLAP), fmt.Errorf("time slot conflict with existing rules or personal events")
 	}
 
+	// 有效期間內每個上課星期的教室預約（含單次預約）
+	bookingResult, err := s.validationSvc.CheckWeeklyRoomBookings(ctx, centerID, roomIDs, req.Weekdays, req.StartTime, req.EndTime, startDate, endDate)
+	if err != nil {
+		return nil, s.App.Err.New(errInfos.SQL_ERROR), fmt.Errorf("failed to check room bookings: %w", err)
+	}
+	if !bookingResult.Valid {
+		return nil, s.App.Err.New(errInfos.SCHED_OVERLAP), fmt.Errorf("time slot conflicts with room bookings")
+	}
+
+	// 檢查是否在營業時間內
+	hoursResult, err := s.validationSvc.CheckWeeklyOperatingHours(ctx, centerID, req.Weekdays, req.StartTime, req.EndTime)
+	if err != nil {
+		return nil, s.App.Err.New(errInfos.SQL_ERROR), fmt.Errorf("failed to check operating hours: %w", err)
+	}
+	if !hoursResult.Valid {
+		return nil, s.App.Err.New(errInfos.SCHED_CLOSED), fmt.Errorf("time slot outside operating hours")
+	}
+
 	// 取得課程設定
 	offering, err := s.offeringRepo.GetByID(ctx, req.OfferingID)
 	if err != nil {
 		return nil, s.App.Err.New(errInfos.NOT_FOUND), fmt.Errorf("failed to get offering: %w", err)
 	}
 
+	// 檢查教室是否符合課程需求（含同時使用的其他教室）
+	suitable, err := s.checkRoomsSuitability(ctx, centerID, roomIDs, offering.CourseID)
+	if err != nil {
+		return nil, s.App.Err.New(errInfos.SQL_ERROR), fmt.Errorf("failed to check room suitability: %w", err)
+	}
+	if !suitable {
+		return nil, s.App.Err.New(errInfos.SCHED_ROOM_UNSUITABLE), fmt.Errorf("room does not meet course requirements")
+	}
+
 	// 檢查 Buffer
 	bufferConflicts, err := s.checkBufferConflicts(ctx, centerID, req, &offering, startDate)
 	if err != nil {
@@ -344,10 +396,14 @@ func (s *ScheduleService) CreateRule(ctx context.Context, centerID, adminID uint
 			}(),
 			}
 
-			if _, err := txRepo.Create(ctx, rule); err != nil {
+			created, err := txRepo.Create(ctx, rule)
+			if err != nil {
 				return fmt.Errorf("failed to create schedule rule: %w", err)
 			}
-			createdRules = append(createdRules, rule)
+			if err := txRepo.ReplaceAssignees(ctx, &created, req.AdditionalTeachers, req.AdditionalRoomIDs); err != nil {
+				return fmt.Errorf("failed to save rule assignees: %w", err)
+			}
+			createdRules = append(createdRules, created)
 		}
 
 		// 在交易中記錄審核日誌
@@ -369,7 +425,26 @@ func (s *ScheduleService) CreateRule(ctx context.Context, centerID, adminID uint
 			return fmt.Errorf("failed to create audit log: %w", err)
 		}
 
-		return nil
+		createdIDs := make([]uint, 0, len(createdRules))
+		for _, rule := range createdRules {
+			createdIDs = append(createdIDs, rule.ID)
+		}
+		if len(createdIDs) == 0 {
+			return nil
+		}
+		return s.domainEvents.RecordWithTx(ctx, txDB, DomainEventInput{
+			CenterID:      centerID,
+			EventType:     models.DomainEventRuleChanged,
+			AggregateType: "ScheduleRule",
+			AggregateID:   createdIDs[0],
+			ActorType:     "ADMIN",
+			ActorID:       adminID,
+			Payload: RuleChangedPayload{
+				Action:     RuleChangeCreated,
+				RuleIDs:    createdIDs,
+				TeacherIDs: teacherIDs,
+			},
+		})
 	})
 
 	if txErr != nil {
@@ -379,6 +454,10 @@ func (s *ScheduleService) CreateRule(ctx context.Context, centerID, adminID uint
 	// 建立規則後，使相關課表快取失效
 	_ = s.InvalidateCenterScheduleCache(ctx, centerID)
 
+	for _, rule := range createdRules {
+		s.liveEvents.Publish(ctx, centerID, LiveEventRuleCreated, "ScheduleRule", rule.ID, nil)
+	}
+
 	return createdRules, nil, nil
 }
 
@@ -386,6 +465,8 @@ func (s *ScheduleService) CreateRule(ctx context.Context, centerID, adminID uint
 func (s *ScheduleService) checkBufferConflicts(ctx context.Context, centerID uint, req *CreateScheduleRuleRequest, offering *models.Offering, startDate time.Time) ([]BufferConflictDetail, error) {
 	var conflicts []BufferConflictDetail
 
+	teacherIDs, roomIDs := ruleAssigneeIDs(req.TeacherID, req.RoomID, req.AdditionalTeachers, req.AdditionalRoomIDs)
+
 	// 計算檢查日期
 	loc := app.GetTaiwanLocation()
 	checkDate := time.Date(startDate.Year(), startDate.Month(), startDate.Day(), 0, 0, 0, 0, loc)
@@ -405,11 +486,11 @@ func (s *ScheduleService) checkBufferConflicts(ctx context.Context, centerID uin
 		)
 		newStartTime, _ := time.ParseInLocation("2006-01-02 15:04", targetDateLocal.Format("2006-01-02")+" "+req.StartTime, loc)
 
-		// 檢查 Teacher Buffer
-		if req.TeacherID != nil && *req.TeacherID > 0 {
-			prevEndTime, _ := s.getTeacherPreviousSessionEndTime(ctx, centerID, *req.TeacherID, weekday, req.StartTime, newStartTime)
+		// 檢查 Teacher Buffer（主教與協同老師）
+		for _, teacherID := range teacherIDs {
+			prevEndTime, _ := s.getTeacherPreviousSessionEndTime(ctx, centerID, teacherID, weekday, req.StartTime, newStartTime)
 			if !prevEndTime.IsZero() {
-				result, err := s.validationSvc.CheckTeacherBuffer(ctx, centerID, *req.TeacherID, prevEndTime, newStartTime, offering.CourseID)
+				result, err := s.validationSvc.CheckTeacherBuffer(ctx, centerID, teacherID, prevEndTime, newStartTime, offering.CourseID)
 				if err != nil {
 					return nil, err
 				}
@@ -426,11 +507,11 @@ func (s *ScheduleService) checkBufferConflicts(ctx context.Context, centerID uin
 			}
 		}
 
-		// 檢查 Room Buffer
-		if req.RoomID > 0 {
-			prevRoomEndTime, _ := s.getRoomPreviousSessionEndTime(ctx, centerID, req.RoomID, weekday, req.StartTime, newStartTime)
+		// 檢查 Room Buffer（所有使用的教室）
+		for _, roomID := range roomIDs {
+			prevRoomEndTime, _ := s.getRoomPreviousSessionEndTime(ctx, centerID, roomID, weekday, req.StartTime, newStartTime)
 			if !prevRoomEndTime.IsZero() {
-				result, err := s.validationSvc.CheckRoomBuffer(ctx, centerID, req.RoomID, prevRoomEndTime, newStartTime, offering.CourseID)
+				result, err := s.validationSvc.CheckRoomBuffer(ctx, centerID, roomID, prevRoomEndTime, newStartTime, offering.CourseID)
 				if err != nil {
 					return nil, err
 				}
@@ -513,11 +594,43 @@ func (s *ScheduleService) UpdateRule(ctx context.Context, centerID, adminID, rul
 		return nil, s.App.Err.New(errInfos.NOT_FOUND), fmt.Errorf("rule not found")
 	}
 
+	// 版本不符代表前端看到的是舊資料，直接回傳目前狀態
+	expectedVersion := expectedVersionOf(req.Version, existingRule.Version)
+	if expectedVersion != existingRule.Version {
+		return nil, s.App.Err.New(errInfos.ERR_CONCURRENT_MODIFIED),
+			NewVersionConflictError("ScheduleRule", ruleID, expectedVersion, existingRule.Version, existingRule)
+	}
+
 	// 驗證 Status 欄位
 	if req.Status != "" && !models.IsValidRuleStatus(req.Status) {
 		return nil, s.App.Err.New(errInfos.PARAMS_VALIDATE_ERROR), fmt.Errorf("invalid status: %s", req.Status)
 	}
 
+	// 協同老師與其他教室：未提供時沿用原規則的設定
+	additionalTeachers := additionalTeachersOf(existingRule)
+	if req.AdditionalTeachers != nil {
+		additionalTeachers, err = NormalizeTeacherAssignments(*req.AdditionalTeachers)
+		if err != nil {
+			return nil, s.App.Err.New(errInfos.PARAMS_VALIDATE_ERROR), err
+		}
+		req.AdditionalTeachers = &additionalTeachers
+	}
+	additionalRoomIDs := additionalRoomIDsOf(existingRule)
+	if req.AdditionalRoomIDs != nil {
+		additionalRoomIDs = *req.AdditionalRoomIDs
+	}
+	assigneesChanged := req.AdditionalTeachers != nil || req.AdditionalRoomIDs != nil
+
+	leadTeacherID := existingRule.TeacherID
+	if req.TeacherID != nil {
+		leadTeacherID = req.TeacherID
+	}
+	primaryRoomID := existingRule.RoomID
+	if req.RoomID != 0 {
+		primaryRoomID = req.RoomID
+	}
+	teacherIDs, roomIDs := ruleAssigneeIDs(leadTeacherID, primaryRoomID, additionalTeachers, additionalRoomIDs)
+
 	// 解析日期
 	var startDate, endDate time.Time
 	if req.StartDate != "" {
@@ -535,9 +648,14 @@ func (s *ScheduleService) UpdateRule(ctx context.Context, centerID, adminID, rul
 	}
 
 	// 衝突檢查（使用 req.ExcludeRuleID 排除自己）
-	if req.StartTime != "" && req.EndTime != "" && req.RoomID != 0 {
-		startTimeParsed, _ := time.Parse("15:04", req.StartTime)
-		endTimeParsed, _ := time.Parse("15:04", req.EndTime)
+	// 只調整協同老師或其他教室時，沿用原規則的時段檢查
+	checkStartTime, checkEndTime, checkRoomID := req.StartTime, req.EndTime, req.RoomID
+	if assigneesChanged && (checkStartTime == "" || checkEndTime == "" || checkRoomID == 0) {
+		checkStartTime, checkEndTime, checkRoomID = existingRule.StartTime, existingRule.EndTime, primaryRoomID
+	}
+	if checkStartTime != "" && checkEndTime != "" && checkRoomID != 0 {
+		startTimeParsed, _ := time.Parse("15:04", checkStartTime)
+		endTimeParsed, _ := time.Parse("15:04", checkEndTime)
 
 		// 決定要檢查的星期幾
 		var checkWeekday int
@@ -553,7 +671,7 @@ func (s *ScheduleService) UpdateRule(ctx context.Context, centerID, adminID, rul
 		}
 
 		// 使用 ExcludeRuleID 排除自己，避免與自己衝突
-		validationResult, err := s.validationSvc.CheckOverlap(ctx, centerID, req.TeacherID, req.RoomID, startTimeParsed, endTimeParsed, checkWeekday, req.ExcludeRuleID)
+		validationResult, err := s.validationSvc.CheckAssigneesOverlap(ctx, centerID, teacherIDs, roomIDs, startTimeParsed, endTimeParsed, checkWeekday, req.ExcludeRuleID)
 		if err != nil {
 			return nil, s.App.Err.New(errInfos.SQL_ERROR), fmt.Errorf("failed to check overlap: %w", err)
 		}
@@ -563,6 +681,80 @@ func (s *ScheduleService) UpdateRule(ctx context.Context, centerID, adminID, rul
 		}
 	}
 
+	// 調整時段、星期、期間或教室時，檢查有效期間內的教室預約（未更新的欄位沿用原規則）
+	if req.StartTime != "" || req.EndTime != "" || req.RoomID != 0 || req.AdditionalRoomIDs != nil ||
+		len(req.Weekdays) > 0 || !startDate.IsZero() || !endDate.IsZero() {
+		checkStartTime, checkEndTime := existingRule.StartTime, existingRule.EndTime
+		if req.StartTime != "" {
+			checkStartTime = req.StartTime
+		}
+		if req.EndTime != "" {
+			checkEndTime = req.EndTime
+		}
+		checkWeekdays := req.Weekdays
+		if len(checkWeekdays) == 0 {
+			checkWeekdays = []int{existingRule.Weekday}
+		}
+		checkStartDate, checkEndDate := existingRule.EffectiveRange.StartDate, existingRule.EffectiveRange.EndDate
+		if !startDate.IsZero() {
+			checkStartDate = startDate
+		}
+		if !endDate.IsZero() {
+			checkEndDate = endDate
+		}
+
+		bookingResult, err := s.validationSvc.CheckWeeklyRoomBookings(ctx, centerID, roomIDs, checkWeekdays, checkStartTime, checkEndTime, checkStartDate, checkEndDate)
+		if err != nil {
+			return nil, s.App.Err.New(errInfos.SQL_ERROR), fmt.Errorf("failed to check room bookings: %w", err)
+		}
+		if !bookingResult.Valid {
+			return nil, s.App.Err.New(errInfos.SCHED_OVERLAP), fmt.Errorf("time slot conflicts with room bookings")
+		}
+	}
+
+	// 檢查是否在營業時間內（未更新的欄位沿用原規則，改星期或只改單邊時間也要檢查）
+	{
+		checkStartTime, checkEndTime := existingRule.StartTime, existingRule.EndTime
+		if req.StartTime != "" {
+			checkStartTime = req.StartTime
+		}
+		if req.EndTime != "" {
+			checkEndTime = req.EndTime
+		}
+		checkWeekdays := req.Weekdays
+		if len(checkWeekdays) == 0 {
+			checkWeekdays = []int{existingRule.Weekday}
+		}
+
+		hoursResult, err := s.validationSvc.CheckWeeklyOperatingHours(ctx, centerID, checkWeekdays, checkStartTime, checkEndTime)
+		if err != nil {
+			return nil, s.App.Err.New(errInfos.SQL_ERROR), fmt.Errorf("failed to check operating hours: %w", err)
+		}
+		if !hoursResult.Valid {
+			return nil, s.App.Err.New(errInfos.SCHED_CLOSED), fmt.Errorf("time slot outside operating hours")
+		}
+	}
+
+	// 更換教室或班別時檢查教室是否符合課程需求
+	if req.RoomID != 0 || req.OfferingID != 0 || req.AdditionalRoomIDs != nil {
+		checkOfferingID := existingRule.OfferingID
+		if req.OfferingID != 0 {
+			checkOfferingID = req.OfferingID
+		}
+
+		offering, err := s.offeringRepo.GetByID(ctx, checkOfferingID)
+		if err != nil {
+			return nil, s.App.Err.New(errInfos.NOT_FOUND), fmt.Errorf("failed to get offering: %w", err)
+		}
+		suitable, err := s.checkRoomsSuitability(ctx, centerID, roomIDs, offering.CourseID)
+		if err != nil {
+			return nil, s.App.Err.New(errInfos.SQL_ERROR), fmt.Errorf("failed to check room suitability: %w", err)
+		}
+		if !suitable {
+			return nil, s.App.Err.New(errInfos.SCHED_ROOM_UNSUITABLE), fmt.Errorf("room does not meet course requirements")
+		}
+	}
+
 	// 取得所有相關規則
 	allRules, err := s.ruleRepo.ListByCenterID(ctx, centerID)
 	if err != nil {
@@ -587,14 +779,19 @@ func (s *ScheduleService) UpdateRule(ctx context.Context, centerID, adminID, rul
 		var handlerErr error
 		var rules []models.ScheduleRule
 
+		// 先遞增版本號，讀取後已被其他請求修改時中止交易
+		if err := s.bumpRuleVersions(ctx, txRepo, &existingRule, relatedRules); err != nil {
+			return err
+		}
+
 		// 根據 update_mode 處理
 		switch req.UpdateMode {
 		case UpdateModeFuture:
-			rules, handlerErr = s.handleFutureUpdateWithTx(txRepo, ctx, centerID, existingRule, relatedRules, req, startDate, endDate)
+			rules, handlerErr = s.handleFutureUpdateWithTx(txRepo, ctx, centerID, existingRule, relatedRules, req, startDate, endDate, additionalTeachers, additionalRoomIDs)
 		case UpdateModeSingle:
-			rules, handlerErr = s.handleSingleUpdateWithTx(txRepo, ctx, centerID, existingRule, req, startDate)
+			rules, handlerErr = s.handleSingleUpdateWithTx(txRepo, ctx, centerID, existingRule, req, startDate, additionalTeachers, additionalRoomIDs)
 		default:
-			rules, handlerErr = s.handleAllUpdateWithTx(txRepo, ctx, centerID, existingRule, relatedRules, req, startDate, endDate)
+			rules, handlerErr = s.handleAllUpdateWithTx(txRepo, ctx, centerID, existingRule, relatedRules, req, startDate, endDate, additionalTeachers, additionalRoomIDs, assigneesChanged)
 		}
 
 		if handlerErr != nil {
@@ -622,21 +819,81 @@ func (s *ScheduleService) UpdateRule(ctx context.Context, centerID, adminID, rul
 			return fmt.Errorf("failed to create audit log: %w", err)
 		}
 
-		return nil
+		// 原規則與更新後規則的老師課表都需更新
+		ruleIDs := make([]uint, 0, len(resultRules))
+		teacherIDs := existingRule.AllTeacherIDs()
+		for _, rule := range resultRules {
+			ruleIDs = append(ruleIDs, rule.ID)
+			teacherIDs = append(teacherIDs, rule.AllTeacherIDs()...)
+		}
+		return s.domainEvents.RecordWithTx(ctx, txDB, DomainEventInput{
+			CenterID:      centerID,
+			EventType:     models.DomainEventRuleChanged,
+			AggregateType: "ScheduleRule",
+			AggregateID:   ruleID,
+			ActorType:     "ADMIN",
+			ActorID:       adminID,
+			Payload: RuleChangedPayload{
+				Action:     RuleChangeUpdated,
+				RuleIDs:    ruleIDs,
+				TeacherIDs: uniqueIDs(teacherIDs),
+				UpdateMode: req.UpdateMode,
+			},
+		})
 	})
 
 	if txErr != nil {
+		if errors.Is(txErr, ErrVersionConflict) {
+			current, err := s.ruleRepo.GetByIDAndCenterID(ctx, ruleID, centerID)
+			if err != nil {
+				return nil, s.App.Err.New(errInfos.NOT_FOUND), fmt.Errorf("rule not found")
+			}
+			return nil, s.App.Err.New(errInfos.ERR_CONCURRENT_MODIFIED),
+				NewVersionConflictError("ScheduleRule", ruleID, expectedVersion, current.Version, current)
+		}
 		return nil, s.App.Err.New(errInfos.ERR_TX_FAILED), txErr
 	}
 
 	// 更新規則後，使相關課表快取失效
 	_ = s.InvalidateCenterScheduleCache(ctx, centerID)
 
+	resultIDs := make([]uint, 0, len(resultRules))
+	for _, rule := range resultRules {
+		resultIDs = append(resultIDs, rule.ID)
+	}
+	s.liveEvents.Publish(ctx, centerID, LiveEventRuleUpdated, "ScheduleRule", ruleID, map[string]any{
+		"update_mode": req.UpdateMode,
+		"rule_ids":    resultIDs,
+	})
+
 	return resultRules, nil, nil
 }
 
+// bumpRuleVersions 遞增本次會一併修改的規則版本號（同班別同時段的其他星期也會被覆寫）
+// 任一規則版本已變動即回傳 ErrVersionConflict
+func (s *ScheduleService) bumpRuleVersions(ctx context.Context, txRepo *repositories.ScheduleRuleRepository, existingRule *models.ScheduleRule, relatedRules []models.ScheduleRule) error {
+	txDB := txRepo.GetDBWrite()
+	rules := make([]*models.ScheduleRule, 0, len(relatedRules)+1)
+	rules = append(rules, existingRule)
+	for i := range relatedRules {
+		rules = append(rules, &relatedRules[i])
+	}
+
+	for _, rule := range rules {
+		bumped, err := txRepo.BumpVersionWithDB(ctx, txDB, rule.ID, rule.Version)
+		if err != nil {
+			return fmt.Errorf("failed to bump rule version: %w", err)
+		}
+		if !bumped {
+			return ErrVersionConflict
+		}
+		rule.Version++
+	}
+	return nil
+}
+
 // handleFutureUpdateWithTx 處理 FUTURE 模式（交易版本）
-func (s *ScheduleService) handleFutureUpdateWithTx(txRepo *repositories.ScheduleRuleRepository, ctx context.Context, centerID uint, existingRule models.ScheduleRule, relatedRules []models.ScheduleRule, req *UpdateScheduleRuleRequest, startDate, endDate time.Time) ([]models.ScheduleRule, error) {
+func (s *ScheduleService) handleFutureUpdateWithTx(txRepo *repositories.ScheduleRuleRepository, ctx context.Context, centerID uint, existingRule models.ScheduleRule, relatedRules []models.ScheduleRule, req *UpdateScheduleRuleRequest, startDate, endDate time.Time, additionalTeachers []models.TeacherAssignment, additionalRoomIDs []uint) ([]models.ScheduleRule, error) {
 	if startDate.IsZero() {
 		return nil, fmt.Errorf("start_date is required for FUTURE update mode")
 	}
@@ -656,17 +913,21 @@ func (s *ScheduleService) handleFutureUpdateWithTx(txRepo *repositories.Schedule
 	// 建立新規則段
 	newRules := s.createNewRuleSegment(centerID, existingRule, relatedRules, req, startDate, endDate)
 	for _, newRule := range newRules {
-		if _, err := txRepo.Create(ctx, newRule); err != nil {
+		created, err := txRepo.Create(ctx, newRule)
+		if err != nil {
 			return nil, err
 		}
-		result = append(result, newRule)
+		if err := txRepo.ReplaceAssignees(ctx, &created, additionalTeachers, additionalRoomIDs); err != nil {
+			return nil, err
+		}
+		result = append(result, created)
 	}
 
 	return result, nil
 }
 
 // handleSingleUpdateWithTx 處理 SINGLE 模式（交易版本）
-func (s *ScheduleService) handleSingleUpdateWithTx(txRepo *repositories.ScheduleRuleRepository, ctx context.Context, centerID uint, existingRule models.ScheduleRule, req *UpdateScheduleRuleRequest, targetDate time.Time) ([]models.ScheduleRule, error) {
+func (s *ScheduleService) handleSingleUpdateWithTx(txRepo *repositories.ScheduleRuleRepository, ctx context.Context, centerID uint, existingRule models.ScheduleRule, req *UpdateScheduleRuleRequest, targetDate time.Time, additionalTeachers []models.TeacherAssignment, additionalRoomIDs []uint) ([]models.ScheduleRule, error) {
 	now := time.Now()
 
 	// 取得交易連接以在交易中建立例外單
@@ -709,15 +970,19 @@ func (s *ScheduleService) handleSingleUpdateWithTx(txRepo *repositories.Schedule
 		},
 	}
 
-	if _, err := txRepo.Create(ctx, newRule); err != nil {
+	created, err := txRepo.Create(ctx, newRule)
+	if err != nil {
 		return nil, fmt.Errorf("failed to create new rule: %w", err)
 	}
+	if err := txRepo.ReplaceAssignees(ctx, &created, additionalTeachers, additionalRoomIDs); err != nil {
+		return nil, fmt.Errorf("failed to save rule assignees: %w", err)
+	}
 
-	return []models.ScheduleRule{existingRule, newRule}, nil
+	return []models.ScheduleRule{existingRule, created}, nil
 }
 
 // handleAllUpdateWithTx 處理 ALL 模式（交易版本）
-func (s *ScheduleService) handleAllUpdateWithTx(txRepo *repositories.ScheduleRuleRepository, ctx context.Context, centerID uint, existingRule models.ScheduleRule, relatedRules []models.ScheduleRule, req *UpdateScheduleRuleRequest, startDate, endDate time.Time) ([]models.ScheduleRule, error) {
+func (s *ScheduleService) handleAllUpdateWithTx(txRepo *repositories.ScheduleRuleRepository, ctx context.Context, centerID uint, existingRule models.ScheduleRule, relatedRules []models.ScheduleRule, req *UpdateScheduleRuleRequest, startDate, endDate time.Time, additionalTeachers []models.TeacherAssignment, additionalRoomIDs []uint, assigneesChanged bool) ([]models.ScheduleRule, error) {
 	newWeekdayMap := make(map[int]bool)
 	for _, w := range req.Weekdays {
 		newWeekdayMap[w] = true
@@ -733,6 +998,12 @@ func (s *ScheduleService) handleAllUpdateWithTx(txRepo *repositories.ScheduleRul
 			if err := txRepo.Update(ctx, updatedRule); err != nil {
 				return nil, err
 			}
+			// 有指定協同老師或其他教室時才覆寫，否則保留各規則原本的設定
+			if assigneesChanged {
+				if err := txRepo.ReplaceAssignees(ctx, &updatedRule, additionalTeachers, additionalRoomIDs); err != nil {
+					return nil, err
+				}
+			}
 			updatedRules = append(updatedRules, updatedRule)
 			delete(newWeekdayMap, rule.Weekday)
 		} else {
@@ -744,10 +1015,14 @@ func (s *ScheduleService) handleAllUpdateWithTx(txRepo *repositories.ScheduleRul
 	var createdRules []models.ScheduleRule
 	for weekday := range newWeekdayMap {
 		newRule := s.createSingleRule(centerID, existingRule, req, weekday, startDate, endDate)
-		if _, err := txRepo.Create(ctx, *newRule); err != nil {
+		created, err := txRepo.Create(ctx, *newRule)
+		if err != nil {
+			return nil, err
+		}
+		if err := txRepo.ReplaceAssignees(ctx, &created, additionalTeachers, additionalRoomIDs); err != nil {
 			return nil, err
 		}
-		createdRules = append(createdRules, *newRule)
+		createdRules = append(createdRules, created)
 	}
 
 	// 執行硬刪除（使用交易連接）
@@ -758,6 +1033,9 @@ func (s *ScheduleService) handleAllUpdateWithTx(txRepo *repositories.ScheduleRul
 		if err := txDB.WithContext(ctx).Where("rule_id = ?", id).Delete(&models.ScheduleException{}).Error; err != nil {
 			return nil, fmt.Errorf("failed to delete associated exceptions: %w", err)
 		}
+		if err := txRepo.DeleteAssignees(ctx, id); err != nil {
+			return nil, fmt.Errorf("failed to delete rule assignees: %w", err)
+		}
 		// 再刪除規則
 		if err := txDB.WithContext(ctx).Unscoped().Where("id = ?", id).Delete(&models.ScheduleRule{}).Error; err != nil {
 			return nil, err
@@ -908,32 +1186,59 @@ func (s *ScheduleService) createSingleRule(centerID uint, existingRule models.Sc
 // DeleteRule 刪除排課規則
 func (s *ScheduleService) DeleteRule(ctx context.Context, centerID, adminID, ruleID uint) error {
 	// 取得規則以獲取老師ID（用於清除老師課表快取）
-	rule, _ := s.ruleRepo.GetByID(ctx, ruleID)
+	rule, _ := s.ruleRepo.GetByIDAndCenterID(ctx, ruleID, centerID)
 
-	if err := s.ruleRepo.DeleteByIDAndCenterID(ctx, ruleID, centerID); err != nil {
-		return fmt.Errorf("failed to delete rule: %w", err)
-	}
+	txErr := s.ruleRepo.Transaction(ctx, func(txRepo *repositories.ScheduleRuleRepository) error {
+		if err := txRepo.DeleteByIDAndCenterID(ctx, ruleID, centerID); err != nil {
+			return fmt.Errorf("failed to delete rule: %w", err)
+		}
 
-	s.auditLogRepo.Create(ctx, models.AuditLog{
-		CenterID:   centerID,
-		ActorType:  "ADMIN",
-		ActorID:    adminID,
-		Action:     "DELETE_SCHEDULE_RULE",
-		TargetType: "ScheduleRule",
-		TargetID:   ruleID,
-		Payload: models.AuditPayload{
-			After: map[string]interface{}{
-				"status": "DELETED",
+		txDB := txRepo.GetDBWrite()
+		auditLog := models.AuditLog{
+			CenterID:   centerID,
+			ActorType:  "ADMIN",
+			ActorID:    adminID,
+			Action:     "DELETE_SCHEDULE_RULE",
+			TargetType: "ScheduleRule",
+			TargetID:   ruleID,
+			Payload: models.AuditPayload{
+				After: map[string]interface{}{
+					"status": "DELETED",
+				},
 			},
-		},
+		}
+		if _, err := s.auditLogRepo.CreateWithTxDB(ctx, txDB, auditLog); err != nil {
+			return fmt.Errorf("failed to create audit log: %w", err)
+		}
+
+		return s.domainEvents.RecordWithTx(ctx, txDB, DomainEventInput{
+			CenterID:      centerID,
+			EventType:     models.DomainEventRuleChanged,
+			AggregateType: "ScheduleRule",
+			AggregateID:   ruleID,
+			ActorType:     "ADMIN",
+			ActorID:       adminID,
+			Payload: RuleChangedPayload{
+				Action:     RuleChangeDeleted,
+				RuleIDs:    []uint{ruleID},
+				TeacherIDs: rule.AllTeacherIDs(),
+			},
+		})
 	})
+	if txErr != nil {
+		return txErr
+	}
 
 	// 刪除規則後，使相關課表快取失效
 	_ = s.InvalidateCenterScheduleCache(ctx, centerID)
-	if rule.ID != 0 && rule.TeacherID != nil {
-		_ = s.InvalidateTeacherScheduleCache(ctx, *rule.TeacherID, centerID)
+	if rule.ID != 0 {
+		for _, teacherID := range rule.AllTeacherIDs() {
+			_ = s.InvalidateTeacherScheduleCache(ctx, teacherID, centerID)
+		}
 	}
 
+	s.liveEvents.Publish(ctx, centerID, LiveEventRuleDeleted, "ScheduleRule", ruleID, nil)
+
 	return nil
 }
 
@@ -1302,7 +1607,7 @@ func (s *ScheduleService) GetCachedTeacherSchedule(ctx context.Context, teacherI
 
 	var teacherRules []models.ScheduleRule
 	for _, rule := range rules {
-		if rule.TeacherID != nil && *rule.TeacherID == teacherID {
+		if rule.HasTeacher(teacherID) {
 			teacherRules = append(teacherRules, rule)
 		}
 	}
@@ -1335,8 +1640,7 @@ func (s *ScheduleService) InvalidateScheduleCache(ctx context.Context, centerID
 // InvalidateCenterScheduleCache 使中心所有課表快取失效
 // 用於大規模異動（如刪除課程、調整時間表）
 func (s *ScheduleService) InvalidateCenterScheduleCache(ctx context.Context, centerID uint) error {
-	pattern := fmt.Sprintf("schedule:expand:center:%d:*", centerID)
-	if err := s.cacheSvc.DeleteByPattern(ctx, CacheCategorySchedule, pattern); err != nil {
+	if err := s.cacheSvc.DeleteByPattern(ctx, CacheCategorySchedule, centerScheduleCachePattern(centerID)); err != nil {
 		s.Logger.Warn("failed to delete center schedule cache pattern", "error", err)
 	}
 
@@ -1347,8 +1651,7 @@ func (s *ScheduleService) InvalidateCenterScheduleCache(ctx context.Context, cen
 // InvalidateTeacherScheduleCache 使老師課表快取失效
 // 當老師代課或異動時呼叫
 func (s *ScheduleService) InvalidateTeacherScheduleCache(ctx context.Context, teacherID, centerID uint) error {
-	pattern := fmt.Sprintf("schedule:expand:teacher:%d:center:%d:*", teacherID, centerID)
-	if err := s.cacheSvc.DeleteByPattern(ctx, CacheCategorySchedule, pattern); err != nil {
+	if err := s.cacheSvc.DeleteByPattern(ctx, CacheCategorySchedule, teacherScheduleCachePattern(teacherID, centerID)); err != nil {
 		s.Logger.Warn("failed to delete teacher schedule cache pattern", "error", err)
 	}
 
@@ -1356,11 +1659,19 @@ func (s *ScheduleService) InvalidateTeacherScheduleCache(ctx context.Context, te
 	return nil
 }
 
+func centerScheduleCachePattern(centerID uint) string {
+	return fmt.Sprintf("schedule:expand:center:%d:*", centerID)
+}
+
+func teacherScheduleCachePattern(teacherID, centerID uint) string {
+	return fmt.Sprintf("schedule:expand:teacher:%d:center:%d:*", teacherID, centerID)
+}
+
 // InvalidateExceptionRelatedCache 使例外相關快取失效
 // 當 ScheduleException 異動時呼叫
 func (s *ScheduleService) InvalidateExceptionRelatedCache(ctx context.Context, centerID uint, exception *models.ScheduleException) error {
 	// 取得例外相關的規則
-	rule, err := s.ruleRepo.GetByID(ctx, exception.RuleID)
+	rule, err := s.ruleRepo.GetByIDAndCenterID(ctx, exception.RuleID, centerID)
 	if err != nil {
 		s.Logger.Warn("failed to get rule for cache invalidation", "error", err)
 		return nil
@@ -1369,9 +1680,9 @@ func (s *ScheduleService) InvalidateExceptionRelatedCache(ctx context.Context, c
 	// 清除中心課表快取
 	_ = s.InvalidateCenterScheduleCache(ctx, centerID)
 
-	// 清除老師課表快取
-	if rule.ID != 0 && rule.TeacherID != nil {
-		_ = s.InvalidateTeacherScheduleCache(ctx, *rule.TeacherID, centerID)
+	// 清除老師課表快取（含協同授課老師）
+	for _, teacherID := range rule.AllTeacherIDs() {
+		_ = s.InvalidateTeacherScheduleCache(ctx, teacherID, centerID)
 	}
 
 	s.Logger.Info("exception-related cache invalidated", "center_id", centerID, "exception_id", exception.ID, "rule_id", exception.RuleID)
@@ -1402,6 +1713,8 @@ func (r *ScheduleResource) ToRuleResponse(rule models.ScheduleRule) *resources.S
 		EffectiveFrom: rule.EffectiveRange.StartDate.Format("2006-01-02"),
 		EffectiveTo:   rule.EffectiveRange.EndDate.Format("2006-01-02"),
 		Status:        rule.Status,
+		Teachers:      rule.TeacherAssignments(),
+		RoomIDs:       rule.AllRoomIDs(),
 	}
 }
 
@@ -1445,17 +1758,35 @@ func (s *ScheduleService) GetMatrixViewData(ctx context.Context, centerID uint,
 	roomMap := make(map[uint]*models.Room)
 
 	// 收集所有資源 ID
+	// 包含協同授課老師與同時使用的其他教室
 	teacherIDs := make([]uint, 0)
 	roomIDs := make([]uint, 0)
+	seenTeachers := make(map[uint]bool)
+	seenRooms := make(map[uint]bool)
 	for _, schedule := range expandedSchedules {
-		if schedule.TeacherID != nil && *schedule.TeacherID > 0 {
-			if _, exists := teacherMap[*schedule.TeacherID]; !exists {
-				teacherIDs = append(teacherIDs, *schedule.TeacherID)
+		for _, id := range schedule.AllTeacherIDs() {
+			if !seenTeachers[id] {
+				seenTeachers[id] = true
+				teacherIDs = append(teacherIDs, id)
 			}
 		}
-		if schedule.RoomID > 0 {
-			if _, exists := roomMap[schedule.RoomID]; !exists {
-				roomIDs = append(roomIDs, schedule.RoomID)
+		for _, id := range schedule.AllRoomIDs() {
+			if !seenRooms[id] {
+				seenRooms[id] = true
+				roomIDs = append(roomIDs, id)
+			}
+		}
+	}
+
+	// 取得教室預約（會議、租借、維修），只在教室視角顯示
+	var bookingOccurrences []RoomBookingOccurrence
+	if req.Type == "room" || req.Type == "all" {
+		bookings, _ := s.roomBookingRepo.ListOverlapping(ctx, centerID, nil, startDate, endDate)
+		bookingOccurrences = ExpandRoomBookings(bookings, startDate, endDate)
+		for _, occ := range bookingOccurrences {
+			if !seenRooms[occ.RoomID] {
+				seenRooms[occ.RoomID] = true
+				roomIDs = append(roomIDs, occ.RoomID)
 			}
 		}
 	}
@@ -1505,7 +1836,7 @@ func (s *ScheduleService) GetMatrixViewData(ctx context.Context, centerID uint,
 		matrixResources, usedResourceIDs = s.groupByTeacher(expandedSchedules, teacherMap, roomMap, filterResourceIDs, includeSuspended)
 	}
 	if req.Type == "room" || req.Type == "all" {
-		roomResources, _ := s.groupByRoom(expandedSchedules, teacherMap, roomMap, filterResourceIDs, includeSuspended)
+		roomResources, _ := s.groupByRoom(expandedSchedules, bookingOccurrences, teacherMap, roomMap, filterResourceIDs, includeSuspended)
 		// 如果是 all 類型，過濾掉已重複的資源
 		if req.Type == "all" && usedResourceIDs != nil {
 			var filteredRoomResources []resources.MatrixResource
@@ -1530,13 +1861,32 @@ func (s *ScheduleService) GetMatrixViewData(ctx context.Context, centerID uint,
 		}
 	}
 
+	// 取得每天的實際營業時間（每週營業時間 + 日期區間調整）
+	overrides, _ := s.hoursOverrideRepo.ListOverlapping(ctx, centerID, startDate, endDate)
+	dailyHours := make([]DailyOperatingHours, 0)
+	for date := startDate; !date.After(endDate); date = date.AddDate(0, 0, 1) {
+		dailyHours = append(dailyHours, ResolveOperatingHours(centerSettings, overrides, date))
+	}
+
 	// 生成 time_slots（使用動態營業時間）
-	timeSlots := s.generateTimeSlots(expandedSchedules, centerSettings)
+	timeSlots := s.generateTimeSlots(expandedSchedules, dailyHours)
+
+	matrixDays := make([]resources.MatrixDailyHours, 0, len(dailyHours))
+	for _, hours := range dailyHours {
+		matrixDays = append(matrixDays, resources.MatrixDailyHours{
+			Date:      hours.Date,
+			OpenTime:  hours.OpenTime,
+			CloseTime: hours.CloseTime,
+			Closed:    hours.Closed,
+			TimeSlots: GenerateTimeSlotsForHours(hours),
+		})
+	}
 
 	// 構建響應
 	response := &resources.MatrixViewResponse{
-		TimeSlots: timeSlots,
-		Resources: matrixResources,
+		TimeSlots:  timeSlots,
+		DailyHours: matrixDays,
+		Resources:  matrixResources,
 		DateRange: resources.MatrixDateRange{
 			StartDate: req.StartDate,
 			EndDate:   req.EndDate,
@@ -1558,22 +1908,21 @@ func (s *ScheduleService) groupByTeacher(
 	teacherSchedules := make(map[uint][]ExpandedSchedule)
 
 	for _, schedule := range expandedSchedules {
-		if schedule.TeacherID == nil || *schedule.TeacherID == 0 {
-			continue
-		}
-
-		// 過濾資源
-		if len(filterResourceIDs) > 0 && !filterResourceIDs[*schedule.TeacherID] {
-			continue
-		}
-
 		// 過濾停課
 		if !includeSuspended && schedule.IsHoliday {
 			continue
 		}
 
-		teacherSchedules[*schedule.TeacherID] = append(teacherSchedules[*schedule.TeacherID], schedule)
-		usedResourceIDs[*schedule.TeacherID] = true
+		// 協同授課的場次會出現在每位授課老師的列中
+		for _, teacherID := range schedule.AllTeacherIDs() {
+			// 過濾資源
+			if len(filterResourceIDs) > 0 && !filterResourceIDs[teacherID] {
+				continue
+			}
+
+			teacherSchedules[teacherID] = append(teacherSchedules[teacherID], schedule)
+			usedResourceIDs[teacherID] = true
+		}
 	}
 
 	var matrixResources []resources.MatrixResource
@@ -1600,6 +1949,7 @@ func (s *ScheduleService) groupByTeacher(
 // groupByRoom 將課表按教室分組
 func (s *ScheduleService) groupByRoom(
 	expandedSchedules []ExpandedSchedule,
+	bookings []RoomBookingOccurrence,
 	teacherMap map[uint]*models.Teacher,
 	roomMap map[uint]*models.Room,
 	filterResourceIDs map[uint]bool,
@@ -1609,22 +1959,33 @@ func (s *ScheduleService) groupByRoom(
 	roomSchedules := make(map[uint][]ExpandedSchedule)
 
 	for _, schedule := range expandedSchedules {
-		if schedule.RoomID == 0 {
+		// 過濾停課
+		if !includeSuspended && schedule.IsHoliday {
 			continue
 		}
 
-		// 過濾資源
-		if len(filterResourceIDs) > 0 && !filterResourceIDs[schedule.RoomID] {
-			continue
+		// 同時使用多間教室的場次會出現在每間教室的列中
+		for _, roomID := range schedule.AllRoomIDs() {
+			// 過濾資源
+			if len(filterResourceIDs) > 0 && !filterResourceIDs[roomID] {
+				continue
+			}
+
+			roomSchedules[roomID] = append(roomSchedules[roomID], schedule)
+			usedResourceIDs[roomID] = true
 		}
+	}
 
-		// 過濾停課
-		if !includeSuspended && schedule.IsHoliday {
+	roomBookings := make(map[uint][]RoomBookingOccurrence)
+	for _, booking := range bookings {
+		if len(filterResourceIDs) > 0 && !filterResourceIDs[booking.RoomID] {
 			continue
 		}
-
-		roomSchedules[schedule.RoomID] = append(roomSchedules[schedule.RoomID], schedule)
-		usedResourceIDs[schedule.RoomID] = true
+		roomBookings[booking.RoomID] = append(roomBookings[booking.RoomID], booking)
+		if _, ok := roomSchedules[booking.RoomID]; !ok {
+			roomSchedules[booking.RoomID] = nil
+		}
+		usedResourceIDs[booking.RoomID] = true
 	}
 
 	var matrixResources []resources.MatrixResource
@@ -1636,6 +1997,7 @@ func (s *ScheduleService) groupByRoom(
 		}
 
 		items := s.buildMatrixItems(schedules, teacherMap, roomMap)
+		items = append(items, s.buildBookingMatrixItems(roomBookings[roomID], name)...)
 
 		matrixResources = append(matrixResources, resources.MatrixResource{
 			ID:    roomID,
@@ -1709,6 +2071,7 @@ func (s *ScheduleService) buildMatrixItems(
 			HasException:  schedule.HasException,
 			IsSuspended:   isSuspended,
 			Status:        schedule.Status,
+			ItemType:      resources.MatrixItemTypeSession,
 		}
 
 		items = append(items, item)
@@ -1717,6 +2080,37 @@ func (s *ScheduleService) buildMatrixItems(
 	return items
 }
 
+// buildBookingMatrixItems 構建教室預約的矩陣項目
+func (s *ScheduleService) buildBookingMatrixItems(bookings []RoomBookingOccurrence, roomName string) []resources.MatrixItem {
+	items := make([]resources.MatrixItem, 0, len(bookings))
+
+	for _, booking := range bookings {
+		startHour, startMinute := s.parseTimeToHourMinute(booking.StartTime)
+		duration := s.calculateDuration(booking.StartTime, booking.EndTime)
+
+		items = append(items, resources.MatrixItem{
+			ID:            booking.BookingID,
+			Title:         booking.Title,
+			Date:          booking.Date,
+			StartTime:     booking.StartTime,
+			EndTime:       booking.EndTime,
+			StartHour:     startHour,
+			StartMinute:   startMinute,
+			Duration:      duration,
+			TopOffset:     float64(startHour*60+startMinute) / 1440 * 100,
+			HeightPercent: float64(duration) / 1440 * 100,
+			RoomID:        booking.RoomID,
+			RoomName:      roomName,
+			ItemType:      resources.MatrixItemTypeBooking,
+			BookingID:     booking.BookingID,
+			BookingType:   booking.BookingType,
+			RenterName:    booking.RenterName,
+		})
+	}
+
+	return items
+}
+
 // parseTimeToHourMinute 解析時間字串為小時和分鐘
 func (s *ScheduleService) parseTimeToHourMinute(timeStr string) (int, int) {
 	parts := strings.Split(timeStr, ":")
@@ -1754,33 +2148,39 @@ func (s *ScheduleService) calculateDuration(startTime, endTime string) int {
 }
 
 // generateTimeSlots 生成時段列表
-// 根據中心的營業時間生成動態時段列表
-// 如果 settings 為空或沒有設定，則使用預設值 (00:00 - 23:00)
-func (s *ScheduleService) generateTimeSlots(schedules []ExpandedSchedule, settings *models.CenterSettings) []int {
-	// 解析營業時間
-	var startHour, endHour int
-
-	if settings != nil && settings.OperatingStartTime != "" {
-		startParts := strings.Split(settings.OperatingStartTime, ":")
-		if len(startParts) >= 1 {
-			fmt.Sscanf(startParts[0], "%d", &startHour)
+// 取區間內各營業日時段的聯集，若有課程落在營業時段外也一併納入，避免矩陣裁掉既有課程
+// 如果整個區間都沒有營業日，則使用預設值 (00:00 - 23:00)
+func (s *ScheduleService) generateTimeSlots(schedules []ExpandedSchedule, dailyHours []DailyOperatingHours) []int {
+	startHour, endHour := -1, -1
+	for _, hours := range dailyHours {
+		slots := GenerateTimeSlotsForHours(hours)
+		if len(slots) == 0 {
+			continue
+		}
+		if startHour == -1 || slots[0] < startHour {
+			startHour = slots[0]
+		}
+		if slots[len(slots)-1] > endHour {
+			endHour = slots[len(slots)-1]
 		}
-	} else {
-		startHour = 0 // 預設開始時間
 	}
 
-	if settings != nil && settings.OperatingEndTime != "" {
-		endParts := strings.Split(settings.OperatingEndTime, ":")
-		if len(endParts) >= 1 {
-			fmt.Sscanf(endParts[0], "%d", &endHour)
-		}
-	} else {
-		endHour = 23 // 預設結束時間
+	if startHour == -1 {
+		startHour, endHour = 0, 23
 	}
 
-	// 確保結束時間大於開始時間
-	if endHour <= startHour {
-		endHour = 23
+	for _, schedule := range schedules {
+		scheduleStart, _ := s.parseTimeToHourMinute(schedule.StartTime)
+		scheduleEnd, endMinute := s.parseTimeToHourMinute(schedule.EndTime)
+		if endMinute == 0 && scheduleEnd > scheduleStart {
+			scheduleEnd--
+		}
+		if scheduleStart < startHour {
+			startHour = scheduleStart
+		}
+		if scheduleEnd > endHour && scheduleEnd <= 23 {
+			endHour = scheduleEnd
+		}
 	}
 
 	// 生成時段列表
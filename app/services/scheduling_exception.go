@@ -17,13 +17,15 @@ type ScheduleExceptionServiceImpl struct {
 	BaseService
 	exceptionRepo     *repositories.ScheduleExceptionRepository
 	ruleRepo          *repositories.ScheduleRuleRepository
+	offeringRepo      *repositories.OfferingRepository
 	auditLogRepo      *repositories.AuditLogRepository
 	centerRepo        *repositories.CenterRepository
-	teacherRepo       *repositories.TeacherRepository
 	validationService ScheduleValidationService
 	notificationSvc   NotificationService
-	notificationQueue NotificationQueueService
 	cacheSvc          *CacheService
+	liveEvents        *LiveEventService
+	domainEvents      *DomainEventService
+	kpiSvc            *DashboardKPIService
 }
 
 func NewScheduleExceptionService(app *app.App) ScheduleExceptionService {
@@ -35,13 +37,15 @@ func NewScheduleExceptionService(app *app.App) ScheduleExceptionService {
 	if app.MySQL != nil {
 		svc.exceptionRepo = repositories.NewScheduleExceptionRepository(app)
 		svc.ruleRepo = repositories.NewScheduleRuleRepository(app)
+		svc.offeringRepo = repositories.NewOfferingRepository(app)
 		svc.auditLogRepo = repositories.NewAuditLogRepository(app)
 		svc.centerRepo = repositories.NewCenterRepository(app)
-		svc.teacherRepo = repositories.NewTeacherRepository(app)
 		svc.validationService = NewScheduleValidationService(app)
 		svc.notificationSvc = NewNotificationService(app)
-		svc.notificationQueue = NewNotificationQueueService(app)
 		svc.cacheSvc = NewCacheService(app)
+		svc.liveEvents = NewLiveEventService(app)
+		svc.domainEvents = NewDomainEventService(app)
+		svc.kpiSvc = NewDashboardKPIService(app)
 	}
 
 	return svc
@@ -97,6 +101,17 @@ func (s *ScheduleExceptionServiceImpl) CreateException(ctx context.Context, cent
 		return models.ScheduleException{}, errInfo, nil
 	}
 
+	// 調課的新時段需在營業時間內
+	if req.Type == "RESCHEDULE" && req.NewStartAt != nil && req.NewEndAt != nil {
+		hoursResult, err := s.validationService.CheckOperatingHours(ctx, centerID, *req.NewStartAt, *req.NewEndAt)
+		if err != nil {
+			return models.ScheduleException{}, nil, err
+		}
+		if !hoursResult.Valid {
+			return models.ScheduleException{}, s.App.Err.New(errInfos.SCHED_CLOSED), nil
+		}
+	}
+
 	exception := models.ScheduleException{
 		CenterID:      centerID,
 		RuleID:        ruleID,
@@ -114,7 +129,6 @@ func (s *ScheduleExceptionServiceImpl) CreateException(ctx context.Context, cent
 	}
 
 	var createdException models.ScheduleException
-	var teacherName, centerName string
 
 	txErr := s.App.MySQL.WDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		var createErr error
@@ -136,30 +150,37 @@ func (s *ScheduleExceptionServiceImpl) CreateException(ctx context.Context, cent
 			return fmt.Errorf("failed to create audit log: %w", err)
 		}
 
-		return nil
+		// 通知管理員由領域事件消費端處理
+		return s.domainEvents.RecordWithTx(ctx, tx, DomainEventInput{
+			CenterID:      centerID,
+			EventType:     models.DomainEventExceptionSubmitted,
+			AggregateType: "ScheduleException",
+			AggregateID:   createdException.ID,
+			ActorType:     "TEACHER",
+			ActorID:       teacherID,
+			Payload: ExceptionSubmittedPayload{
+				RuleID:        ruleID,
+				TeacherID:     teacherID,
+				ExceptionType: req.Type,
+				OriginalDate:  req.OriginalDate.Format("2006-01-02"),
+			},
+		})
 	})
 
 	if txErr != nil {
 		return models.ScheduleException{}, nil, txErr
 	}
 
-	teacher, _ := s.teacherRepo.GetByID(ctx, teacherID)
-	teacherName = teacher.Name
-	if teacherName == "" {
-		teacherName = "老師"
-	}
-
-	center, _ := s.centerRepo.GetByID(ctx, centerID)
-	centerName = center.Name
-
 	createdException.ExceptionType = req.Type
 
-	if s.notificationQueue != nil {
-		_ = s.notificationQueue.NotifyExceptionSubmittedSync(ctx, &createdException, teacherName, centerName)
-	}
-
 	s.invalidateRelatedCaches(ctx, &createdException)
 
+	s.liveEvents.Publish(ctx, centerID, LiveEventExceptionSubmitted, "ScheduleException", createdException.ID, map[string]any{
+		"rule_id":        createdException.RuleID,
+		"exception_type": createdException.ExceptionType,
+		"original_date":  createdException.OriginalDate.Format("2006-01-02"),
+	})
+
 	return createdException, nil, nil
 }
 
@@ -190,6 +211,12 @@ func (s *ScheduleExceptionServiceImpl) RevokeException(ctx context.Context, exce
 
 	s.invalidateRelatedCaches(ctx, &exception)
 
+	// 撤回後不再待審，同樣通知管理後台更新待審列表
+	s.liveEvents.Publish(ctx, exception.CenterID, LiveEventExceptionReviewed, "ScheduleException", exceptionID, map[string]any{
+		"rule_id": exception.RuleID,
+		"status":  exception.Status,
+	})
+
 	return nil
 }
 
@@ -220,11 +247,17 @@ func (s *ScheduleExceptionServiceImpl) ReviewException(ctx context.Context, exce
 
 	txErr := s.App.MySQL.WDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		if status == "APPROVED" {
-			rule, err := s.ruleRepo.GetByID(ctx, exception.RuleID)
+			rule, err := s.ruleRepo.GetByIDAndCenterID(ctx, exception.RuleID, exception.CenterID)
 			if err != nil {
 				return fmt.Errorf("failed to get rule: %w", err)
 			}
 
+			// 教室適用性與緩衝時間依課程判斷，規則只記錄班別
+			offering, err := s.offeringRepo.GetByIDAndCenterID(ctx, rule.OfferingID, exception.CenterID)
+			if err != nil {
+				return fmt.Errorf("failed to get offering: %w", err)
+			}
+
 			var startAt, endAt time.Time
 			if exception.ExceptionType == "RESCHEDULE" && exception.NewStartAt != nil {
 				startAt = *exception.NewStartAt
@@ -239,7 +272,7 @@ func (s *ScheduleExceptionServiceImpl) ReviewException(ctx context.Context, exce
 				exception.CenterID,
 				exception.NewTeacherID,
 				rule.RoomID,
-				rule.OfferingID,
+				offering.CourseID,
 				startAt,
 				endAt,
 				nil,
@@ -255,7 +288,7 @@ func (s *ScheduleExceptionServiceImpl) ReviewException(ctx context.Context, exce
 				var hasHardOverlap bool
 				var hasBufferConflict bool
 				for _, c := range validateResult.Conflicts {
-					if c.Type == "OVERLAP" || c.Type == "TEACHER_OVERLAP" || c.Type == "ROOM_OVERLAP" {
+					if c.Type == "OVERLAP" || c.Type == "TEACHER_OVERLAP" || c.Type == "ROOM_OVERLAP" || c.Type == "OUTSIDE_OPERATING_HOURS" || c.Type == "ROOM_UNSUITABLE" {
 						hasHardOverlap = true
 					}
 					if c.Type == "TEACHER_BUFFER" || c.Type == "ROOM_BUFFER" {
@@ -272,6 +305,27 @@ func (s *ScheduleExceptionServiceImpl) ReviewException(ctx context.Context, exce
 				}
 			}
 
+			// 調課時協同老師與其他教室也一起移動，需確認新時段沒有重疊
+			if exception.ExceptionType == "RESCHEDULE" && exception.NewStartAt != nil &&
+				(len(rule.AdditionalTeachers) > 0 || len(rule.AdditionalRooms) > 0) {
+				allTeacherIDs := rule.AllTeacherIDs()
+				allRoomIDs := rule.AllRoomIDs()
+				var teacherIDs, roomIDs []uint
+				if len(allTeacherIDs) > 1 {
+					teacherIDs = allTeacherIDs[1:]
+				}
+				if len(allRoomIDs) > 1 {
+					roomIDs = allRoomIDs[1:]
+				}
+				assigneeResult, err := s.validationService.CheckAssigneesOverlap(ctx, exception.CenterID, teacherIDs, roomIDs, startAt, endAt, int(startAt.Weekday()), &rule.ID)
+				if err != nil {
+					return fmt.Errorf("validation failed: %w", err)
+				}
+				if !assigneeResult.Valid {
+					return errors.New("approval rejected: co-teachers or additional rooms overlap with existing schedule")
+				}
+			}
+
 			if err := s.applyExceptionChangesWithTx(ctx, tx, &exception, &rule); err != nil {
 				return fmt.Errorf("failed to apply exception changes: %w", err)
 			}
@@ -294,26 +348,41 @@ func (s *ScheduleExceptionServiceImpl) ReviewException(ctx context.Context, exce
 			return fmt.Errorf("failed to create audit log: %w", err)
 		}
 
-		return nil
+		// 通知老師審核結果由領域事件消費端處理
+		return s.domainEvents.RecordWithTx(ctx, tx, DomainEventInput{
+			CenterID:      exception.CenterID,
+			EventType:     models.DomainEventExceptionReviewed,
+			AggregateType: "ScheduleException",
+			AggregateID:   exceptionID,
+			ActorType:     "ADMIN",
+			ActorID:       adminID,
+			Payload: ExceptionReviewedPayload{
+				RuleID: exception.RuleID,
+				Status: status,
+				Reason: reason,
+			},
+		})
 	})
 
 	if txErr != nil {
 		return txErr
 	}
 
-	if s.notificationQueue != nil {
-		rule, _ := s.ruleRepo.GetByID(ctx, exception.RuleID)
-		if rule.ID > 0 && rule.TeacherID != nil {
-			teacher, _ := s.teacherRepo.GetByID(ctx, *rule.TeacherID)
+	s.invalidateRelatedCaches(ctx, &exception)
 
-			approved := status == "APPROVED"
-			if teacher.ID > 0 {
-				_ = s.notificationQueue.NotifyExceptionResultSync(ctx, &exception, &teacher, approved, reason)
-			}
-		}
-	}
+	s.liveEvents.Publish(ctx, exception.CenterID, LiveEventExceptionReviewed, "ScheduleException", exceptionID, map[string]any{
+		"rule_id": exception.RuleID,
+		"status":  exception.Status,
+	})
 
-	s.invalidateRelatedCaches(ctx, &exception)
+	// 事後審核會改變已彙總日期的場次統計，夜間排程只重算最近幾天
+	kpiDates := []time.Time{exception.OriginalDate}
+	if exception.NewStartAt != nil {
+		kpiDates = append(kpiDates, *exception.NewStartAt)
+	}
+	if err := s.kpiSvc.RefreshDates(ctx, exception.CenterID, kpiDates...); err != nil {
+		s.Logger.Warn("failed to refresh center KPIs after exception review", "exception_id", exceptionID, "error", err)
+	}
 
 	return nil
 }
@@ -399,6 +468,20 @@ func (s *ScheduleExceptionServiceImpl) applyExceptionChangesWithTx(ctx context.C
 			CreatedAt: time.Now(),
 			UpdatedAt: time.Now(),
 		}
+		// 協同老師與其他教室沿用原規則設定，隨新規則一併建立
+		for _, t := range rule.AdditionalTeachers {
+			newRule.AdditionalTeachers = append(newRule.AdditionalTeachers, models.ScheduleRuleTeacher{
+				CenterID:  rule.CenterID,
+				TeacherID: t.TeacherID,
+				Role:      t.Role,
+			})
+		}
+		for _, r := range rule.AdditionalRooms {
+			newRule.AdditionalRooms = append(newRule.AdditionalRooms, models.ScheduleRuleRoom{
+				CenterID: rule.CenterID,
+				RoomID:   r.RoomID,
+			})
+		}
 		if err := tx.Create(&newRule).Error; err != nil {
 			return fmt.Errorf("failed to create reschedule rule: %w", err)
 		}
@@ -12,11 +12,14 @@ import (
 
 type ScheduleExpansionServiceImpl struct {
 	BaseService
-	scheduleRuleRepo *repositories.ScheduleRuleRepository
-	exceptionRepo    *repositories.ScheduleExceptionRepository
-	auditLogRepo     *repositories.AuditLogRepository
-	centerRepo       *repositories.CenterRepository
-	holidayRepo      *repositories.CenterHolidayRepository
+	scheduleRuleRepo  *repositories.ScheduleRuleRepository
+	exceptionRepo     *repositories.ScheduleExceptionRepository
+	auditLogRepo      *repositories.AuditLogRepository
+	centerRepo        *repositories.CenterRepository
+	holidayRepo       *repositories.CenterHolidayRepository
+	makeupWorkdayRepo *repositories.CenterMakeupWorkdayRepository
+	recurringRepo     *repositories.RecurringHolidayRepository
+	makeupRepo        *repositories.MakeupSessionRepository
 }
 
 func NewScheduleExpansionService(app *app.App) ScheduleExpansionService {
@@ -31,6 +34,9 @@ func NewScheduleExpansionService(app *app.App) ScheduleExpansionService {
 		svc.auditLogRepo = repositories.NewAuditLogRepository(app)
 		svc.centerRepo = repositories.NewCenterRepository(app)
 		svc.holidayRepo = repositories.NewCenterHolidayRepository(app)
+		svc.makeupWorkdayRepo = repositories.NewCenterMakeupWorkdayRepository(app)
+		svc.recurringRepo = repositories.NewRecurringHolidayRepository(app)
+		svc.makeupRepo = repositories.NewMakeupSessionRepository(app)
 	}
 
 	return svc
@@ -39,12 +45,14 @@ func NewScheduleExpansionService(app *app.App) ScheduleExpansionService {
 func (s *ScheduleExpansionServiceImpl) ExpandRules(ctx context.Context, rules []models.ScheduleRule, startDate, endDate time.Time, centerID uint) []ExpandedSchedule {
 	var schedules []ExpandedSchedule
 
-	holidays, _ := s.holidayRepo.ListByDateRange(ctx, centerID, startDate, endDate)
+	holidays, _ := s.GetHolidaysByDateRange(ctx, centerID, startDate, endDate)
 	holidayMap := make(map[string]models.CenterHoliday)
 	for _, h := range holidays {
 		holidayMap[h.Date.Format("2006-01-02")] = h
 	}
 
+	makeupWorkdayMap := s.GetMakeupWorkdayMap(ctx, centerID, startDate, endDate)
+
 	ruleIDs := make([]uint, 0, len(rules))
 	for _, rule := range rules {
 		if rule.Weekday != 0 {
@@ -64,15 +72,15 @@ func (s *ScheduleExpansionServiceImpl) ExpandRules(ctx context.Context, rules []
 
 		ruleStartDate := rule.EffectiveRange.StartDate
 		ruleEndDate := rule.EffectiveRange.EndDate
+		// 代課只套用在例外當天，每個日期都從規則原本的老師開始
+		baseTeacherID := rule.TeacherID
 
 		date := startDate
 		for date.Before(endDate) || date.Equal(endDate) {
-			weekday := int(date.Weekday())
-			if weekday == 0 {
-				weekday = 7
-			}
+			weekday := ResolveEffectiveWeekday(date, makeupWorkdayMap)
 
 			if weekday == int(rule.Weekday) {
+				rule.TeacherID = baseTeacherID
 				isWithinEffectiveRange := true
 				if !ruleStartDate.IsZero() && date.Before(ruleStartDate) {
 					isWithinEffectiveRange = false
@@ -177,6 +185,8 @@ func (s *ScheduleExpansionServiceImpl) ExpandRules(ctx context.Context, rules []
 							OfferingID:     rule.OfferingID,
 							EffectiveRange: &rule.EffectiveRange,
 							IsCrossDayPart: isCrossDay,
+							Teachers:       rule.TeacherAssignments(),
+							RoomIDs:        rule.AllRoomIDs(),
 						}
 
 						if pendingException != nil {
@@ -207,9 +217,153 @@ func (s *ScheduleExpansionServiceImpl) ExpandRules(ctx context.Context, rules []
 		}
 	}
 
+	if s.makeupRepo != nil && len(ruleIDs) > 0 {
+		// 規則的補課與這些老師代為授課的其他規則補課
+		teacherIDSet := make(map[uint]bool)
+		var teacherIDs []uint
+		for _, rule := range rules {
+			if rule.Weekday == 0 {
+				continue
+			}
+			for _, teacherID := range rule.AllTeacherIDs() {
+				if !teacherIDSet[teacherID] {
+					teacherIDSet[teacherID] = true
+					teacherIDs = append(teacherIDs, teacherID)
+				}
+			}
+		}
+		makeups, err := s.makeupRepo.ListScheduledByRulesOrTeachersAndDateRange(ctx, centerID, ruleIDs, teacherIDs, startDate, endDate)
+		if err != nil {
+			s.Logger.Warn("failed to load makeup sessions", "center_id", centerID, "error", err)
+		}
+		schedules = append(schedules, ExpandMakeupSessions(s.withMakeupParentRules(ctx, rules, makeups), makeups)...)
+	}
+
+	return schedules
+}
+
+// withMakeupParentRules 補課的原規則不在 rules 中時（代課老師補課、原規則已刪除）另外載入
+func (s *ScheduleExpansionServiceImpl) withMakeupParentRules(ctx context.Context, rules []models.ScheduleRule, makeups []models.MakeupSession) []models.ScheduleRule {
+	known := make(map[uint]bool, len(rules))
+	for _, rule := range rules {
+		known[rule.ID] = true
+	}
+	var missing []uint
+	for _, makeup := range makeups {
+		if !known[makeup.OriginalRuleID] {
+			known[makeup.OriginalRuleID] = true
+			missing = append(missing, makeup.OriginalRuleID)
+		}
+	}
+	if len(missing) == 0 || s.scheduleRuleRepo == nil {
+		return rules
+	}
+
+	parents, err := s.scheduleRuleRepo.ListByIDsWithDeleted(ctx, missing)
+	if err != nil {
+		s.Logger.Warn("failed to load makeup parent rules", "rule_ids", missing, "error", err)
+		return rules
+	}
+	return append(append(make([]models.ScheduleRule, 0, len(rules)+len(parents)), rules...), parents...)
+}
+
+// ExpandMakeupSessions 將已安排的補課轉為場次，沿用原規則的班別資訊
+// rules 需包含補課的原規則（見 withMakeupParentRules），找不到原規則的補課略過
+func ExpandMakeupSessions(rules []models.ScheduleRule, makeups []models.MakeupSession) []ExpandedSchedule {
+	ruleMap := make(map[uint]*models.ScheduleRule, len(rules))
+	for i := range rules {
+		ruleMap[rules[i].ID] = &rules[i]
+	}
+
+	var schedules []ExpandedSchedule
+	for _, makeup := range makeups {
+		rule, ok := ruleMap[makeup.OriginalRuleID]
+		if !ok {
+			continue
+		}
+		makeupID := makeup.ID
+		schedule := ExpandedSchedule{
+			RuleID:         rule.ID,
+			MakeupID:       &makeupID,
+			Date:           makeup.MakeupDate,
+			StartTime:      makeup.StartTime,
+			EndTime:        makeup.EndTime,
+			RoomID:         makeup.RoomID,
+			TeacherID:      makeup.TeacherID,
+			Status:         rule.Status,
+			OfferingName:   rule.Offering.Name,
+			OfferingID:     rule.OfferingID,
+			EffectiveRange: &rule.EffectiveRange,
+		}
+		if makeup.TeacherID != nil && rule.TeacherID != nil && *makeup.TeacherID == *rule.TeacherID {
+			schedule.TeacherName = rule.Teacher.Name
+		}
+		if makeup.RoomID == rule.RoomID {
+			schedule.RoomName = rule.Room.Name
+		}
+		schedules = append(schedules, schedule)
+	}
 	return schedules
 }
 
+// GetHolidaysByDateRange 取得區間內的假日，包含週期假日展開後的日期（同日以單日假日為準）
+func (s *ScheduleExpansionServiceImpl) GetHolidaysByDateRange(ctx context.Context, centerID uint, startDate, endDate time.Time) ([]models.CenterHoliday, error) {
+	holidays, err := s.holidayRepo.ListByDateRange(ctx, centerID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.recurringRepo == nil {
+		return holidays, nil
+	}
+	defs, err := s.recurringRepo.ListByCenterID(ctx, centerID)
+	if err != nil {
+		return nil, err
+	}
+	if len(defs) == 0 {
+		return holidays, nil
+	}
+
+	return MergeHolidays(holidays, MaterializeRecurringHolidays(defs, startDate, endDate)), nil
+}
+
+// GetMakeupWorkdayMap 取得區間內的補班日（日期 -> 比照星期），中心未開啟補班設定時回傳 nil
+func (s *ScheduleExpansionServiceImpl) GetMakeupWorkdayMap(ctx context.Context, centerID uint, startDate, endDate time.Time) map[string]int {
+	if s.centerRepo == nil || s.makeupWorkdayRepo == nil {
+		return nil
+	}
+
+	center, err := s.centerRepo.GetByID(ctx, centerID)
+	if err != nil || !center.Settings.FollowMakeupWorkdays {
+		return nil
+	}
+
+	workdays, err := s.makeupWorkdayRepo.ListByDateRange(ctx, centerID, startDate, endDate)
+	if err != nil {
+		s.Logger.Warn("failed to load makeup workdays", "center_id", centerID, "error", err)
+		return nil
+	}
+
+	workdayMap := make(map[string]int, len(workdays))
+	for _, w := range workdays {
+		workdayMap[w.Date.Format("2006-01-02")] = w.FollowsWeekday
+	}
+	return workdayMap
+}
+
+// ResolveEffectiveWeekday 取得日期實際套用的星期課表（1=週一 … 7=週日）
+// 補班日比照 makeupWorkdays 指定的星期上課，當天原本星期的規則不展開
+func ResolveEffectiveWeekday(date time.Time, makeupWorkdays map[string]int) int {
+	if follows, ok := makeupWorkdays[date.Format("2006-01-02")]; ok && follows >= 1 && follows <= 7 {
+		return follows
+	}
+	weekday := int(date.Weekday())
+	if weekday == 0 {
+		weekday = 7
+	}
+	return weekday
+}
+
 func (s *ScheduleExpansionServiceImpl) GetEffectiveRuleForDate(ctx context.Context, offeringID uint, date time.Time) (*models.ScheduleRule, error) {
 	var rules []models.ScheduleRule
 	// 處理零值時間
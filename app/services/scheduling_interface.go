@@ -14,13 +14,13 @@ type ValidationResult struct {
 }
 
 type ValidationConflict struct {
-	Type             string `json:"type"` // TEACHER_OVERLAP, ROOM_OVERLAP, TEACHER_BUFFER, ROOM_BUFFER
+	Type             string `json:"type"` // TEACHER_OVERLAP, ROOM_OVERLAP, TEACHER_BUFFER, ROOM_BUFFER, OUTSIDE_OPERATING_HOURS, ROOM_UNSUITABLE, ROOM_BOOKED
 	Message          string `json:"message"`
 	CanOverride      bool   `json:"can_override"`
 	RequireApproval  bool   `json:"require_approval,omitempty"`
 	RequiredMinutes  int    `json:"required_minutes,omitempty"`
 	DiffMinutes      int    `json:"diff_minutes,omitempty"`
-	ConflictSource   string `json:"conflict_source,omitempty"` // RULE, SESSION, PERSONAL
+	ConflictSource   string `json:"conflict_source,omitempty"` // RULE, SESSION, PERSONAL, BOOKING, EXCEPTION
 	ConflictSourceID uint   `json:"conflict_source_id,omitempty"`
 	Details          string `json:"details,omitempty"`
 }
@@ -30,6 +30,10 @@ type ScheduleValidationService interface {
 	// 檢查指定時段是否與現有排課衝突
 	CheckOverlap(ctx context.Context, centerID uint, teacherID *uint, roomID uint, startTime, endTime time.Time, weekday int, excludeRuleID *uint) (ValidationResult, error)
 
+	// CheckAssigneesOverlap 檢查多位老師、多間教室的時段重疊
+	// 用於協同授課或同時使用多間教室的規則
+	CheckAssigneesOverlap(ctx context.Context, centerID uint, teacherIDs []uint, roomIDs []uint, startTime, endTime time.Time, weekday int, excludeRuleID *uint) (ValidationResult, error)
+
 	// CheckTeacherBuffer 檢查老師轉場緩衝
 	// 檢查老師連續課程是否滿足課程的轉場緩衝時間
 	CheckTeacherBuffer(ctx context.Context, centerID uint, teacherID uint, prevEndTime, nextStartTime time.Time, courseID uint) (ValidationResult, error)
@@ -42,6 +46,22 @@ type ScheduleValidationService interface {
 	// 執行所有檢查（重疊 + 緩衝）
 	// 如果 prevEndTime 和 nextStartTime 為 nil，系統會自動計算上一堂課的結束時間
 	ValidateFull(ctx context.Context, centerID uint, teacherID *uint, roomID uint, courseID uint, startTime, endTime time.Time, excludeRuleID *uint, allowBufferOverride bool, prevEndTime, nextStartTime *time.Time) (ValidationResult, error)
+
+	// CheckOperatingHours 檢查營業時間
+	// 檢查指定日期時段是否落在當天營業時間內（含日期區間調整）
+	CheckOperatingHours(ctx context.Context, centerID uint, startTime, endTime time.Time) (ValidationResult, error)
+
+	// CheckWeeklyOperatingHours 檢查每週營業時間
+	// 檢查週期規則的時段是否落在各星期的營業時間內
+	CheckWeeklyOperatingHours(ctx context.Context, centerID uint, weekdays []int, startTime, endTime string) (ValidationResult, error)
+
+	// CheckWeeklyRoomBookings 檢查每週規則的教室預約
+	// 檢查規則有效期間內（今天起）落在各星期的單次預約與每週預約
+	CheckWeeklyRoomBookings(ctx context.Context, centerID uint, roomIDs []uint, weekdays []int, startTime, endTime string, startDate, endDate time.Time) (ValidationResult, error)
+
+	// CheckRoomSuitability 檢查教室適用性
+	// 檢查教室容納人數與設備是否符合課程需求
+	CheckRoomSuitability(ctx context.Context, centerID uint, roomID uint, courseID uint) (ValidationResult, error)
 }
 
 type ScheduleExpansionService interface {
@@ -59,6 +79,13 @@ type ScheduleExpansionService interface {
 
 	// GetRulesByEffectiveDateRange 取得指定 effective 日期範圍內的規則
 	GetRulesByEffectiveDateRange(ctx context.Context, centerID uint, offeringID uint, startDate, endDate time.Time) ([]models.ScheduleRule, error)
+
+	// GetHolidaysByDateRange 取得區間內的假日（含週期假日展開結果）
+	GetHolidaysByDateRange(ctx context.Context, centerID uint, startDate, endDate time.Time) ([]models.CenterHoliday, error)
+
+	// GetMakeupWorkdayMap 取得區間內的補班日與比照星期
+	// 中心未開啟補班設定時回傳 nil
+	GetMakeupWorkdayMap(ctx context.Context, centerID uint, startDate, endDate time.Time) map[string]int
 }
 
 type ScheduleExceptionService interface {
@@ -93,6 +120,7 @@ type ScheduleExceptionService interface {
 
 type ExpandedSchedule struct {
 	RuleID        uint               `json:"rule_id"`
+	MakeupID      *uint              `json:"makeup_id,omitempty"` // 補課場次，RuleID 為原規則
 	Date          time.Time          `json:"date"`
 	StartTime     string             `json:"start_time"`
 	EndTime       string             `json:"end_time"`
@@ -109,6 +137,48 @@ type ExpandedSchedule struct {
 	OfferingID     uint              `json:"offering_id,omitempty"`
 	EffectiveRange *models.DateRange `json:"effective_range,omitempty"`
 	IsCrossDayPart bool              `json:"is_cross_day_part,omitempty"` // 跨日課程的一部分
+	// 協同授課與多教室：包含主教老師與主要教室
+	Teachers []models.TeacherAssignment `json:"teachers,omitempty"`
+	RoomIDs  []uint                     `json:"room_ids,omitempty"`
+}
+
+// AllTeacherIDs 取得場次的所有授課老師，未帶協同資訊時使用 TeacherID
+func (es ExpandedSchedule) AllTeacherIDs() []uint {
+	if len(es.Teachers) > 0 {
+		ids := make([]uint, 0, len(es.Teachers))
+		for _, t := range es.Teachers {
+			ids = append(ids, t.TeacherID)
+		}
+		return ids
+	}
+	if es.TeacherID != nil && *es.TeacherID > 0 {
+		return []uint{*es.TeacherID}
+	}
+	return nil
+}
+
+// AllRoomIDs 取得場次使用的所有教室，未帶多教室資訊時使用 RoomID
+func (es ExpandedSchedule) AllRoomIDs() []uint {
+	if len(es.RoomIDs) > 0 {
+		return es.RoomIDs
+	}
+	if es.RoomID > 0 {
+		return []uint{es.RoomID}
+	}
+	return nil
+}
+
+// TeacherRole 取得老師在場次中的角色，未參與時回傳空字串
+func (es ExpandedSchedule) TeacherRole(teacherID uint) string {
+	for _, t := range es.Teachers {
+		if t.TeacherID == teacherID {
+			return t.Role
+		}
+	}
+	if es.TeacherID != nil && *es.TeacherID == teacherID {
+		return models.TeacherRoleLead
+	}
+	return ""
 }
 
 // MarshalJSON 確保 Date 欄位以 ISO 8601 日期格式序列化
@@ -229,6 +299,7 @@ type TeacherScheduleItem struct {
 	Status         string `json:"status"` // 狀態: PLANNED(預計), CONFIRMED(已開課), SUSPENDED(停課), ARCHIVED(歸檔)
 	RuleID         uint   `json:"rule_id"`
 	IsCrossDayPart bool   `json:"is_cross_day_part,omitempty"`
+	Role           string `json:"role,omitempty"` // 老師在此場次的授課角色：LEAD、ASSISTANT、TA
 }
 
 type ScheduleQueryService interface {
@@ -9,6 +9,7 @@ import (
 	"timeLedger/app"
 	"timeLedger/app/models"
 	"timeLedger/app/repositories"
+	"timeLedger/libs"
 )
 
 type ScheduleValidationServiceImpl struct {
@@ -16,6 +17,10 @@ type ScheduleValidationServiceImpl struct {
 	scheduleRuleRepo *repositories.ScheduleRuleRepository
 	roomRepo         *repositories.RoomRepository
 	courseRepo       *repositories.CourseRepository
+	centerRepo       *repositories.CenterRepository
+	overrideRepo     *repositories.CenterOperatingHoursOverrideRepository
+	bookingRepo      *repositories.RoomBookingRepository
+	makeupRepo       *repositories.MakeupSessionRepository
 }
 
 func NewScheduleValidationService(app *app.App) ScheduleValidationService {
@@ -28,12 +33,29 @@ func NewScheduleValidationService(app *app.App) ScheduleValidationService {
 		svc.scheduleRuleRepo = repositories.NewScheduleRuleRepository(app)
 		svc.roomRepo = repositories.NewRoomRepository(app)
 		svc.courseRepo = repositories.NewCourseRepository(app)
+		svc.centerRepo = repositories.NewCenterRepository(app)
+		svc.overrideRepo = repositories.NewCenterOperatingHoursOverrideRepository(app)
+		svc.bookingRepo = repositories.NewRoomBookingRepository(app)
+		svc.makeupRepo = repositories.NewMakeupSessionRepository(app)
 	}
 
 	return svc
 }
 
 func (s *ScheduleValidationServiceImpl) CheckOverlap(ctx context.Context, centerID uint, teacherID *uint, roomID uint, startTime, endTime time.Time, weekday int, excludeRuleID *uint) (ValidationResult, error) {
+	var teacherIDs, roomIDs []uint
+	if teacherID != nil {
+		teacherIDs = []uint{*teacherID}
+	}
+	if roomID > 0 {
+		roomIDs = []uint{roomID}
+	}
+	return s.CheckAssigneesOverlap(ctx, centerID, teacherIDs, roomIDs, startTime, endTime, weekday, excludeRuleID)
+}
+
+// CheckAssigneesOverlap 檢查多位老師、多間教室在該時段是否已有排課
+// 既有規則的主教、協同老師與所有使用中的教室都會納入比對，已安排的補課與教室的會議、租借、維修預約也視為衝突
+func (s *ScheduleValidationServiceImpl) CheckAssigneesOverlap(ctx context.Context, centerID uint, teacherIDs []uint, roomIDs []uint, startTime, endTime time.Time, weekday int, excludeRuleID *uint) (ValidationResult, error) {
 	result := ValidationResult{Valid: true}
 
 	// 確保 weekday 正確轉換（Go 的 Weekday: Sunday=0, Monday=1, ..., Saturday=6）
@@ -52,6 +74,7 @@ func (s *ScheduleValidationServiceImpl) CheckOverlap(ctx context.Context, center
 	}
 
 	query := s.App.MySQL.RDB.WithContext(ctx).Model(&models.ScheduleRule{}).
+		Scopes(repositories.PreloadRuleAssignees, repositories.RuleHasAnyAssignee(teacherIDs, roomIDs)).
 		Where("center_id = ?", centerID).
 		Where("weekday = ?", weekday).
 		Where("start_time < ?", endTimeStr).
@@ -59,10 +82,6 @@ func (s *ScheduleValidationServiceImpl) CheckOverlap(ctx context.Context, center
 		Where("COALESCE(NULLIF(NULLIF(JSON_UNQUOTE(JSON_EXTRACT(effective_range, '$.start_date')), ''), 'null'), '0001-01-01') <= ?", startDateStr).
 		Where("COALESCE(NULLIF(NULLIF(NULLIF(JSON_UNQUOTE(JSON_EXTRACT(effective_range, '$.end_date')), ''), 'null'), '0001-01-01 00:00:00'), '9999-12-31') >= ?", startDateStr)
 
-	if teacherID != nil {
-		query = query.Where("teacher_id = ?", *teacherID)
-	}
-
 	if excludeRuleID != nil {
 		query = query.Where("id != ?", *excludeRuleID)
 	}
@@ -73,32 +92,140 @@ func (s *ScheduleValidationServiceImpl) CheckOverlap(ctx context.Context, center
 	}
 
 	for _, rule := range rules {
-		var conflictTypes []string
-		var messages []string
-
-		if teacherID != nil && rule.TeacherID != nil && *rule.TeacherID == *teacherID {
-			conflictTypes = append(conflictTypes, "TEACHER_OVERLAP")
-			messages = append(messages, "老師在該時段已有課程安排")
+		for _, conflict := range AssigneeOverlapConflicts(rule, teacherIDs, roomIDs) {
+			result.Valid = false
+			result.Conflicts = append(result.Conflicts, conflict)
 		}
+	}
+
+	makeupConflicts, err := s.checkMakeups(ctx, centerID, teacherIDs, roomIDs, startTime, endTime, weekday, excludeRuleID)
+	if err != nil {
+		return ValidationResult{}, err
+	}
+	if len(makeupConflicts) > 0 {
+		result.Valid = false
+		result.Conflicts = append(result.Conflicts, makeupConflicts...)
+	}
+
+	bookingConflicts, err := s.checkRoomBookings(ctx, centerID, roomIDs, startTime, endTime, weekday)
+	if err != nil {
+		return ValidationResult{}, err
+	}
+	if len(bookingConflicts) > 0 {
+		result.Valid = false
+		result.Conflicts = append(result.Conflicts, bookingConflicts...)
+	}
+
+	return result, nil
+}
+
+// checkRoomBookings 檢查教室預約
+// 帶有實際日期的時段（例外單、補課）比對當天的所有預約；只有時間的每週規則比對同星期仍有效的每週預約
+func (s *ScheduleValidationServiceImpl) checkRoomBookings(ctx context.Context, centerID uint, roomIDs []uint, startTime, endTime time.Time, weekday int) ([]ValidationConflict, error) {
+	if s.bookingRepo == nil || len(roomIDs) == 0 {
+		return nil, nil
+	}
+
+	startClock := startTime.Format("15:04")
+	endClock := endTime.Format("15:04")
 
-		if rule.RoomID == roomID {
-			conflictTypes = append(conflictTypes, "ROOM_OVERLAP")
-			messages = append(messages, "教室在該時段已被佔用")
+	if startTime.Year() > 1 {
+		date := dateOnly(startTime)
+		bookings, err := s.bookingRepo.ListOverlapping(ctx, centerID, roomIDs, date, date)
+		if err != nil {
+			return nil, err
 		}
+		return RoomBookingConflicts(bookings, roomIDs, &date, weekday, startClock, endClock), nil
+	}
 
-		if len(conflictTypes) > 0 {
-			result.Valid = false
-			for i, ct := range conflictTypes {
-				result.Conflicts = append(result.Conflicts, ValidationConflict{
-					Type:    ct,
-					Message: messages[i],
-					Details: fmt.Sprintf("rule_id:%d, offering_id:%d", rule.ID, rule.OfferingID),
+	bookings, err := s.bookingRepo.ListWeeklyByRooms(ctx, centerID, roomIDs, weekday, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return RoomBookingConflicts(bookings, roomIDs, nil, weekday, startClock, endClock), nil
+}
+
+// checkMakeups 檢查已安排的補課
+// 帶有實際日期的時段比對當天的補課；只有時間的每週規則比對今天起落在同星期的補課
+// 更新規則時排除該規則本身的補課（excludeRuleID），補課是規則的一部分而非衝突
+func (s *ScheduleValidationServiceImpl) checkMakeups(ctx context.Context, centerID uint, teacherIDs []uint, roomIDs []uint, startTime, endTime time.Time, weekday int, excludeRuleID *uint) ([]ValidationConflict, error) {
+	if s.makeupRepo == nil || (len(teacherIDs) == 0 && len(roomIDs) == 0) {
+		return nil, nil
+	}
+
+	var date *time.Time
+	if startTime.Year() > 1 {
+		d := dateOnly(startTime)
+		date = &d
+	}
+	makeups, err := s.makeupRepo.ListScheduledOverlapping(ctx, centerID, date, weekday, libs.TodayInTaiwan(), startTime.Format("15:04"), endTime.Format("15:04"), excludeRuleID)
+	if err != nil {
+		return nil, err
+	}
+
+	var conflicts []ValidationConflict
+	for _, makeup := range makeups {
+		conflicts = append(conflicts, MakeupOverlapConflicts(makeup, teacherIDs, roomIDs)...)
+	}
+	return conflicts, nil
+}
+
+// MakeupOverlapConflicts 比對已安排的補課與待排的老師、教室，回傳重疊的衝突
+func MakeupOverlapConflicts(makeup models.MakeupSession, teacherIDs []uint, roomIDs []uint) []ValidationConflict {
+	var conflicts []ValidationConflict
+	details := fmt.Sprintf("makeup_id:%d, offering_id:%d, date:%s", makeup.ID, makeup.OfferingID, makeup.MakeupDate.Format("2006-01-02"))
+
+	if makeup.TeacherID != nil {
+		for _, teacherID := range teacherIDs {
+			if teacherID == *makeup.TeacherID {
+				conflicts = append(conflicts, ValidationConflict{
+					Type:    "TEACHER_OVERLAP",
+					Message: "老師在該時段已有補課安排",
+					Details: fmt.Sprintf("%s, teacher_id:%d", details, teacherID),
 				})
 			}
 		}
 	}
 
-	return result, nil
+	for _, roomID := range roomIDs {
+		if roomID == makeup.RoomID {
+			conflicts = append(conflicts, ValidationConflict{
+				Type:    "ROOM_OVERLAP",
+				Message: "教室在該時段已安排補課",
+				Details: fmt.Sprintf("%s, room_id:%d", details, roomID),
+			})
+		}
+	}
+
+	return conflicts
+}
+
+// AssigneeOverlapConflicts 比對既有規則與待排的老師、教室，回傳重疊的衝突
+func AssigneeOverlapConflicts(rule models.ScheduleRule, teacherIDs []uint, roomIDs []uint) []ValidationConflict {
+	var conflicts []ValidationConflict
+	details := fmt.Sprintf("rule_id:%d, offering_id:%d", rule.ID, rule.OfferingID)
+
+	for _, teacherID := range teacherIDs {
+		if rule.HasTeacher(teacherID) {
+			conflicts = append(conflicts, ValidationConflict{
+				Type:    "TEACHER_OVERLAP",
+				Message: "老師在該時段已有課程安排",
+				Details: fmt.Sprintf("%s, teacher_id:%d", details, teacherID),
+			})
+		}
+	}
+
+	for _, roomID := range roomIDs {
+		if rule.HasRoom(roomID) {
+			conflicts = append(conflicts, ValidationConflict{
+				Type:    "ROOM_OVERLAP",
+				Message: "教室在該時段已被佔用",
+				Details: fmt.Sprintf("%s, room_id:%d", details, roomID),
+			})
+		}
+	}
+
+	return conflicts
 }
 
 func (s *ScheduleValidationServiceImpl) CheckTeacherBuffer(ctx context.Context, centerID uint, teacherID uint, prevEndTime, nextStartTime time.Time, courseID uint) (ValidationResult, error) {
@@ -189,6 +316,27 @@ func (s *ScheduleValidationServiceImpl) ValidateFull(ctx context.Context, center
 		}
 	}
 
+	// 營業時間只檢查有具體日期與時段的調課；停課、代課以 00:00-00:00 表示整天，HH:mm 則沒有日期
+	if startTime.Year() > 1 && startTime.Before(endTime) {
+		hoursResult, err := s.CheckOperatingHours(ctx, centerID, startTime, endTime)
+		if err != nil {
+			return ValidationResult{}, err
+		}
+		if !hoursResult.Valid {
+			result.Valid = false
+			result.Conflicts = append(result.Conflicts, hoursResult.Conflicts...)
+		}
+	}
+
+	suitabilityResult, err := s.CheckRoomSuitability(ctx, centerID, roomID, courseID)
+	if err != nil {
+		return ValidationResult{}, err
+	}
+	if !suitabilityResult.Valid {
+		result.Valid = false
+		result.Conflicts = append(result.Conflicts, suitabilityResult.Conflicts...)
+	}
+
 	// 處理教師緩衝時間
 	// 如果提供了 prevEndTime 和 nextStartTime，直接使用
 	// 否則自動計算上一堂課的結束時間
@@ -266,6 +414,134 @@ func (s *ScheduleValidationServiceImpl) ValidateFull(ctx context.Context, center
 	return result, nil
 }
 
+// CheckOperatingHours 檢查指定日期時段是否在營業時間內（含日期區間調整）
+func (s *ScheduleValidationServiceImpl) CheckOperatingHours(ctx context.Context, centerID uint, startTime, endTime time.Time) (ValidationResult, error) {
+	result := ValidationResult{Valid: true}
+
+	center, err := s.centerRepo.GetByID(ctx, centerID)
+	if err != nil {
+		return ValidationResult{}, err
+	}
+
+	overrides, err := s.overrideRepo.ListOverlapping(ctx, centerID, startTime, startTime)
+	if err != nil {
+		return ValidationResult{}, err
+	}
+
+	hours := ResolveOperatingHours(&center.Settings, overrides, startTime)
+	endStr := endTime.Format("15:04")
+	if endTime.Format("2006-01-02") != startTime.Format("2006-01-02") && endStr == "00:00" {
+		endStr = "24:00"
+	}
+	if !IsWithinOperatingHours(hours, startTime.Format("15:04"), endStr) {
+		result.Valid = false
+		result.Conflicts = append(result.Conflicts, operatingHoursConflict(hours))
+	}
+
+	return result, nil
+}
+
+// CheckWeeklyRoomBookings 檢查每週規則在有效期間內是否與教室預約衝突
+// 已過去的日期不再檢查；endDate 為零值表示未設定結束日
+func (s *ScheduleValidationServiceImpl) CheckWeeklyRoomBookings(ctx context.Context, centerID uint, roomIDs []uint, weekdays []int, startTime, endTime string, startDate, endDate time.Time) (ValidationResult, error) {
+	result := ValidationResult{Valid: true}
+	if s.bookingRepo == nil || len(roomIDs) == 0 || len(weekdays) == 0 {
+		return result, nil
+	}
+
+	today := dateOnly(libs.TodayInTaiwan())
+	from := dateOnly(startDate)
+	if from.Before(today) {
+		from = today
+	}
+	to := time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+	if !endDate.IsZero() {
+		to = dateOnly(endDate)
+	}
+	if to.Before(from) {
+		return result, nil
+	}
+
+	bookings, err := s.bookingRepo.ListOverlapping(ctx, centerID, roomIDs, from, to)
+	if err != nil {
+		return ValidationResult{}, err
+	}
+	if conflicts := WeeklyRuleBookingConflicts(bookings, roomIDs, weekdays, startTime, endTime, from, to); len(conflicts) > 0 {
+		result.Valid = false
+		result.Conflicts = conflicts
+	}
+	return result, nil
+}
+
+// CheckWeeklyOperatingHours 檢查週期規則時段是否在每週營業時間內
+// 日期區間調整屬於暫時性安排，不影響週期規則的建立
+func (s *ScheduleValidationServiceImpl) CheckWeeklyOperatingHours(ctx context.Context, centerID uint, weekdays []int, startTime, endTime string) (ValidationResult, error) {
+	result := ValidationResult{Valid: true}
+
+	center, err := s.centerRepo.GetByID(ctx, centerID)
+	if err != nil {
+		return ValidationResult{}, err
+	}
+
+	for _, weekday := range weekdays {
+		hours := ResolveWeeklyOperatingHours(&center.Settings, weekday)
+		if !IsWithinOperatingHours(hours, startTime, endTime) {
+			result.Valid = false
+			result.Conflicts = append(result.Conflicts, operatingHoursConflict(hours))
+		}
+	}
+
+	return result, nil
+}
+
+// operatingHoursConflict 建立非營業時間衝突
+func operatingHoursConflict(hours DailyOperatingHours) ValidationConflict {
+	message := fmt.Sprintf("時段超出營業時間 %s-%s", hours.OpenTime, hours.CloseTime)
+	if hours.Closed {
+		message = "該日為公休日"
+	}
+	details := fmt.Sprintf("weekday:%d, source:%s", hours.Weekday, hours.Source)
+	if hours.Date != "" {
+		details = fmt.Sprintf("date:%s, %s", hours.Date, details)
+	}
+	return ValidationConflict{
+		Type:        "OUTSIDE_OPERATING_HOURS",
+		Message:     message,
+		CanOverride: false,
+		Details:     details,
+	}
+}
+
+// CheckRoomSuitability 檢查教室容納人數與設備是否符合課程需求
+// 未指定教室或課程時不檢查
+func (s *ScheduleValidationServiceImpl) CheckRoomSuitability(ctx context.Context, centerID uint, roomID uint, courseID uint) (ValidationResult, error) {
+	result := ValidationResult{Valid: true}
+	if roomID == 0 || courseID == 0 {
+		return result, nil
+	}
+
+	room, err := s.roomRepo.GetByIDWithCenterScope(ctx, roomID, centerID)
+	if err != nil {
+		return ValidationResult{}, err
+	}
+	course, err := s.courseRepo.GetByIDWithCenterScope(ctx, courseID, centerID)
+	if err != nil {
+		return ValidationResult{}, err
+	}
+
+	if reasons := EvaluateRoomSuitability(room, course); len(reasons) > 0 {
+		result.Valid = false
+		result.Conflicts = append(result.Conflicts, ValidationConflict{
+			Type:        "ROOM_UNSUITABLE",
+			Message:     fmt.Sprintf("教室「%s」不符合課程「%s」需求：%s", room.Name, course.Name, strings.Join(reasons, "；")),
+			CanOverride: false,
+			Details:     fmt.Sprintf("room_id:%d, course_id:%d", room.ID, course.ID),
+		})
+	}
+
+	return result, nil
+}
+
 func (s *ScheduleValidationServiceImpl) getPreviousSessionEndTime(ctx context.Context, centerID uint, teacherID uint, beforeTime time.Time) (time.Time, error) {
 	weekday := beforeTime.Weekday()
 	if weekday == 0 {
@@ -282,7 +558,7 @@ func (s *ScheduleValidationServiceImpl) getPreviousSessionEndTime(ctx context.Co
 	var rule models.ScheduleRule
 	err := s.App.MySQL.RDB.WithContext(ctx).
 		Where("center_id = ?", centerID).
-		Where("teacher_id = ?", teacherID).
+		Scopes(repositories.RuleHasTeacher(teacherID)).
 		Where("weekday = ?", weekday).
 		Where("COALESCE(NULLIF(NULLIF(JSON_UNQUOTE(JSON_EXTRACT(effective_range, '$.start_date')), ''), 'null'), '0001-01-01') <= ?", startDateStr).
 		Where("COALESCE(NULLIF(NULLIF(NULLIF(JSON_UNQUOTE(JSON_EXTRACT(effective_range, '$.end_date')), ''), 'null'), '0001-01-01 00:00:00'), '9999-12-31') >= ?", startDateStr).
@@ -335,7 +611,7 @@ func (s *ScheduleValidationServiceImpl) getPreviousSessionEndTimeByRoom(ctx cont
 	var rule models.ScheduleRule
 	err := s.App.MySQL.RDB.WithContext(ctx).
 		Where("center_id = ?", centerID).
-		Where("room_id = ?", roomID).
+		Scopes(repositories.RuleHasRoom(roomID)).
 		Where("weekday = ?", weekday).
 		Where("COALESCE(NULLIF(NULLIF(JSON_UNQUOTE(JSON_EXTRACT(effective_range, '$.start_date')), ''), 'null'), '0001-01-01') <= ?", startDateStr).
 		Where("COALESCE(NULLIF(NULLIF(NULLIF(JSON_UNQUOTE(JSON_EXTRACT(effective_range, '$.end_date')), ''), 'null'), '0001-01-01 00:00:00'), '9999-12-31') >= ?", startDateStr).
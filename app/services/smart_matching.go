@@ -84,6 +84,8 @@ type SmartMatchingServiceImpl struct {
 	teacherCertificateRepo *repositories.TeacherCertificateRepository
 	centerTeacherNoteRepo  *repositories.CenterTeacherNoteRepository
 	centerInvitationRepo   *repositories.CenterInvitationRepository
+	roomRepo               *repositories.RoomRepository
+	courseRepo             *repositories.CourseRepository
 	notificationService    NotificationService
 }
 
@@ -98,6 +100,8 @@ func NewSmartMatchingService(app *app.App) SmartMatchingService {
 		teacherCertificateRepo: repositories.NewTeacherCertificateRepository(app),
 		centerTeacherNoteRepo:  repositories.NewCenterTeacherNoteRepository(app),
 		centerInvitationRepo:   repositories.NewCenterInvitationRepository(app),
+		roomRepo:               repositories.NewRoomRepository(app),
+		courseRepo:             repositories.NewCourseRepository(app),
 		notificationService:    NewNotificationService(app),
 	}
 }
@@ -957,7 +961,8 @@ func (s *SmartMatchingServiceImpl) GetSearchSuggestions(ctx context.Context, que
 }
 
 // GetAlternativeSlots - 取得替代時段建議
-func (s *SmartMatchingServiceImpl) GetAlternativeSlots(ctx context.Context, centerID uint, teacherID uint, originalStart, originalEnd time.Time, duration int) ([]AlternativeSlot, error) {
+// courseID 不為 0 時，只建議符合課程需求（容納人數、設備）的空教室
+func (s *SmartMatchingServiceImpl) GetAlternativeSlots(ctx context.Context, centerID uint, teacherID uint, originalStart, originalEnd time.Time, duration int, courseID uint) ([]AlternativeSlot, error) {
 	if duration == 0 {
 		duration = 90 // 預設 90 分鐘
 	}
@@ -970,10 +975,27 @@ func (s *SmartMatchingServiceImpl) GetAlternativeSlots(ctx context.Context, cent
 		return nil, err
 	}
 
+	// 取得可建議的教室
+	rooms, err := s.roomRepo.ListActiveByCenterID(ctx, centerID)
+	if err != nil {
+		return nil, err
+	}
+	if courseID > 0 {
+		course, err := s.courseRepo.GetByIDWithCenterScope(ctx, courseID, centerID)
+		if err != nil {
+			return nil, err
+		}
+		rooms = FilterSuitableRooms(rooms, course)
+	}
+
 	// 產生未來 7 天的替代時段
 	for day := 1; day <= 7; day++ {
 		date := originalStart.AddDate(0, 0, day)
 		dateStr := date.Format("2006-01-02")
+		weekday := int(date.Weekday())
+		if weekday == 0 {
+			weekday = 7
+		}
 
 		// 產生三個時段：上午、下午、晚上
 		timeSlots := []string{"09:00", "14:00", "16:00"}
@@ -985,34 +1007,46 @@ func (s *SmartMatchingServiceImpl) GetAlternativeSlots(ctx context.Context, cent
 
 			// 檢查是否與現有課表衝突
 			hasConflict := false
+			busyRooms := make(map[uint]bool)
 			for _, rule := range rules {
-				if rule.TeacherID == nil || *rule.TeacherID != teacherID {
+				if weekday != rule.Weekday {
 					continue
 				}
 
-				// 檢查日期和時間是否衝突
-				if int(date.Weekday()) == rule.Weekday {
-					ruleStart := rule.StartTime
-					ruleEnd := rule.EndTime
+				// 簡單的時間衝突檢查
+				if startTime >= rule.EndTime || endTime <= rule.StartTime {
+					continue
+				}
 
-					// 簡單的時間衝突檢查
-					if startTime < ruleEnd && endTime > ruleStart {
-						hasConflict = true
-						break
-					}
+				for _, roomID := range rule.AllRoomIDs() {
+					busyRooms[roomID] = true
+				}
+				if rule.HasTeacher(teacherID) {
+					hasConflict = true
+				}
+			}
+
+			availableRooms := make([]RoomInfo, 0, len(rooms))
+			for _, room := range rooms {
+				if !busyRooms[room.ID] {
+					availableRooms = append(availableRooms, RoomInfo{ID: room.ID, Name: room.Name})
 				}
 			}
 
 			slot := AlternativeSlot{
-				Date:      dateStr,
-				DateLabel: fmt.Sprintf("%d/%d", date.Month(), date.Day()),
-				Start:     startTime,
-				End:       endTime,
-				Available: !hasConflict,
+				Date:           dateStr,
+				DateLabel:      fmt.Sprintf("%d/%d", date.Month(), date.Day()),
+				Start:          startTime,
+				End:            endTime,
+				Available:      !hasConflict,
+				AvailableRooms: availableRooms,
 			}
 
 			if hasConflict {
 				slot.ConflictReason = "與現有課程衝突"
+			} else if courseID > 0 && len(availableRooms) == 0 {
+				slot.Available = false
+				slot.ConflictReason = "沒有符合課程需求的空教室"
 			}
 
 			slots = append(slots, slot)
@@ -1043,7 +1077,7 @@ func (s *SmartMatchingServiceImpl) GetTeacherSessions(ctx context.Context, cente
 	sessions := make([]SessionInfo, 0)
 
 	for _, rule := range rules {
-		if rule.TeacherID == nil || *rule.TeacherID != teacherID {
+		if !rule.HasTeacher(teacherID) {
 			continue
 		}
 
@@ -11,7 +11,7 @@ type SmartMatchingService interface {
 	GetTalentStats(ctx context.Context, centerID uint) (*TalentStats, error)
 	InviteTalent(ctx context.Context, centerID uint, adminID uint, teacherIDs []uint, message string) (*InviteResult, error)
 	GetSearchSuggestions(ctx context.Context, query string) (*SearchSuggestions, error)
-	GetAlternativeSlots(ctx context.Context, centerID uint, teacherID uint, originalStart, originalEnd time.Time, duration int) ([]AlternativeSlot, error)
+	GetAlternativeSlots(ctx context.Context, centerID uint, teacherID uint, originalStart, originalEnd time.Time, duration int, courseID uint) ([]AlternativeSlot, error)
 	GetTeacherSessions(ctx context.Context, centerID uint, teacherID uint, startDate, endDate string) (*TeacherSessions, error)
 }
 
@@ -28,7 +28,9 @@ type TeacherService struct {
 	invitationRepo *repositories.CenterInvitationRepository
 	adminUserRepo  *repositories.AdminUserRepository
 	auditLogRepo   *repositories.AuditLogRepository
+	domainEvents   *DomainEventService
 	lineBotService LineBotService
+	richMenuSvc    *LineRichMenuService
 	authService    *authService
 	redisClient    *redis.Redis
 }
@@ -43,6 +45,7 @@ func NewTeacherService(app *app.App) *TeacherService {
 		svc.redisClient = app.Redis
 		svc.authService = NewAuthService(app)
 		svc.lineBotService = NewLineBotService(app)
+		svc.richMenuSvc = NewLineRichMenuService(app)
 	}
 
 	if app.MySQL != nil {
@@ -52,6 +55,7 @@ func NewTeacherService(app *app.App) *TeacherService {
 		svc.invitationRepo = repositories.NewCenterInvitationRepository(app)
 		svc.adminUserRepo = repositories.NewAdminUserRepository(app)
 		svc.auditLogRepo = repositories.NewAuditLogRepository(app)
+		svc.domainEvents = NewDomainEventService(app)
 	}
 
 	return svc
@@ -133,6 +137,9 @@ func (s *TeacherService) RespondToInvitation(ctx context.Context, req *RespondTo
 						},
 					},
 				})
+
+				teacher, _ := s.teacherRepo.GetByID(ctx, req.TeacherID)
+				s.domainEvents.Record(ctx, teacherJoinedEvent(invitation.CenterID, teacher, "INVITATION"))
 			}
 		}
 
@@ -184,6 +191,40 @@ func (s *TeacherService) RespondToInvitation(ctx context.Context, req *RespondTo
 	}, nil, nil
 }
 
+// teacherJoinedEvent 組成老師加入中心的領域事件
+func teacherJoinedEvent(centerID uint, teacher models.Teacher, source string) DomainEventInput {
+	return DomainEventInput{
+		CenterID:      centerID,
+		EventType:     models.DomainEventTeacherJoined,
+		AggregateType: "Teacher",
+		AggregateID:   teacher.ID,
+		ActorType:     "TEACHER",
+		ActorID:       teacher.ID,
+		Payload: TeacherMembershipPayload{
+			TeacherID:   teacher.ID,
+			TeacherName: teacher.Name,
+			Source:      source,
+		},
+	}
+}
+
+// TeacherLeftEvent 組成老師離開中心的領域事件
+func TeacherLeftEvent(centerID, adminID uint, teacher models.Teacher, source string) DomainEventInput {
+	return DomainEventInput{
+		CenterID:      centerID,
+		EventType:     models.DomainEventTeacherLeft,
+		AggregateType: "Teacher",
+		AggregateID:   teacher.ID,
+		ActorType:     "ADMIN",
+		ActorID:       adminID,
+		Payload: TeacherMembershipPayload{
+			TeacherID:   teacher.ID,
+			TeacherName: teacher.Name,
+			Source:      source,
+		},
+	}
+}
+
 // InviteTeacherRequest 邀請老師請求
 type InviteTeacherRequest struct {
 	CenterID    uint
@@ -800,7 +841,7 @@ func (s *TeacherService) AcceptInvitationByLink(ctx context.Context, req *Accept
 			},
 		})
 
-		return nil
+		return s.domainEvents.RecordWithTx(ctx, tx, teacherJoinedEvent(invitation.CenterID, teacher, "INVITE_LINK"))
 	})
 
 	if err != nil {
@@ -814,6 +855,9 @@ func (s *TeacherService) AcceptInvitationByLink(ctx context.Context, req *Accept
 		centerName = center.Name
 	}
 
+	// 切換為老師選單
+	s.richMenuSvc.LinkUserAsync(teacher.LineUserID)
+
 	// 發送 LINE 通知（異步）
 	// 建立 teacher 副本以確保 goroutine 執行時資料仍然有效
 	teacherForNotification := teacher
@@ -944,6 +988,9 @@ func (s *TeacherService) RegisterPublic(ctx context.Context, req *PublicRegister
 		return nil, s.app.Err.New(errInfos.SQL_ERROR), fmt.Errorf("failed to create teacher: %w", err)
 	}
 
+	// 切換為老師選單
+	s.richMenuSvc.LinkUserAsync(createdTeacher.LineUserID)
+
 	// 產生 JWT token
 	claims := jwt.Claims{
 		UserType:   "TEACHER",
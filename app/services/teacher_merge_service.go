@@ -16,13 +16,15 @@ import (
 // TeacherMergeService 教師合併服務
 type TeacherMergeService struct {
 	BaseService
+	domainEvents *DomainEventService
 }
 
 // NewTeacherMergeService 建立教師合併服務實例
 func NewTeacherMergeService(app *app.App) *TeacherMergeService {
 	baseSvc := NewBaseService(app, "TeacherMergeService")
 	return &TeacherMergeService{
-		BaseService: *baseSvc,
+		BaseService:  *baseSvc,
+		domainEvents: NewDomainEventService(app),
 	}
 }
 
@@ -131,6 +133,21 @@ func (s *TeacherMergeService) MergeTeacher(ctx context.Context, sourceID, target
 			return err
 		}
 
+		// 13. 記錄領域事件（課表快取由消費端清除）
+		if err := s.domainEvents.RecordWithTx(ctx, tx, DomainEventInput{
+			CenterID:      centerID,
+			EventType:     models.DomainEventTeacherMerged,
+			AggregateType: "Teacher",
+			AggregateID:   targetID,
+			ActorType:     "ADMIN",
+			Payload: TeacherMergedPayload{
+				SourceTeacherID: sourceID,
+				TargetTeacherID: targetID,
+			},
+		}); err != nil {
+			return err
+		}
+
 		s.Logger.Info("教師合併完成",
 			"source_teacher_id", sourceID,
 			"target_teacher_id", targetID)
@@ -171,6 +188,21 @@ func (s *TeacherMergeService) migrateScheduleRules(tx *gorm.DB, sourceID, target
 			"target_teacher_id", targetID)
 	}
 
+	// 遷移協同授課紀錄，目標教師已在同一規則授課時直接移除來源教師的紀錄
+	if err := tx.Where("teacher_id = ? AND center_id = ?", sourceID, centerID).
+		Where("(rule_id IN (SELECT id FROM schedule_rules WHERE teacher_id = ?) OR rule_id IN (SELECT rule_id FROM (SELECT rule_id FROM schedule_rule_teachers WHERE teacher_id = ?) AS target_rules))", targetID, targetID).
+		Delete(&models.ScheduleRuleTeacher{}).Error; err != nil {
+		s.Logger.Error("遷移協同授課紀錄失敗", "error", err)
+		return fmt.Errorf("遷移協同授課紀錄失敗: %w", err)
+	}
+
+	if err := tx.Model(&models.ScheduleRuleTeacher{}).
+		Where("teacher_id = ? AND center_id = ?", sourceID, centerID).
+		Update("teacher_id", targetID).Error; err != nil {
+		s.Logger.Error("遷移協同授課紀錄失敗", "error", err)
+		return fmt.Errorf("遷移協同授課紀錄失敗: %w", err)
+	}
+
 	return nil
 }
 
@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 	"timeLedger/app"
@@ -35,17 +36,250 @@ func NewTimetableTemplateService(appInstance *app.App) *TimetableTemplateService
 	}
 }
 
+// UpdateTemplateInput 更新模板的輸入參數
+type UpdateTemplateInput struct {
+	CenterID   uint
+	AdminID    uint
+	TemplateID uint
+	Name       string
+	Version    *uint // 前端讀取時的模板版本（If-Match），nil 表示不比對
+}
+
+// UpdateTemplate 更新課表模板
+func (s *TimetableTemplateService) UpdateTemplate(ctx context.Context, input *UpdateTemplateInput) (*models.TimetableTemplate, *errInfos.Res, error) {
+	return s.mutateTemplate(ctx, input.CenterID, input.TemplateID, input.Version, func(tx *gorm.DB, template *models.TimetableTemplate) error {
+		template.UpdatedAt = time.Now()
+		if input.Name != "" {
+			template.Name = input.Name
+		}
+		if err := tx.Model(&models.TimetableTemplate{}).Where("id = ?", input.TemplateID).Updates(map[string]interface{}{
+			"name":       template.Name,
+			"updated_at": template.UpdatedAt,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to update template: %w", err)
+		}
+
+		_, err := s.auditLogRepo.CreateWithTxDB(ctx, tx, models.AuditLog{
+			CenterID:   input.CenterID,
+			ActorType:  "ADMIN",
+			ActorID:    input.AdminID,
+			Action:     "TEMPLATE_UPDATE",
+			TargetType: "TimetableTemplate",
+			TargetID:   input.TemplateID,
+			Payload: models.AuditPayload{
+				After: map[string]interface{}{
+					"name":    template.Name,
+					"version": template.Version,
+				},
+			},
+		})
+		return err
+	})
+}
+
+// mutateTemplate 在同一交易中遞增模板版本並執行 fn
+// 模板名稱與格子的變更都經過此處，並發編輯同一模板時後到者回傳 ERR_CONCURRENT_MODIFIED
+func (s *TimetableTemplateService) mutateTemplate(ctx context.Context, centerID, templateID uint, version *uint, fn func(tx *gorm.DB, template *models.TimetableTemplate) error) (*models.TimetableTemplate, *errInfos.Res, error) {
+	template, err := s.templateRepo.GetByIDAndCenterID(ctx, templateID, centerID)
+	if err != nil {
+		return nil, s.app.Err.New(errInfos.NOT_FOUND), err
+	}
+
+	expectedVersion := expectedVersionOf(version, template.Version)
+	if expectedVersion != template.Version {
+		return nil, s.app.Err.New(errInfos.ERR_CONCURRENT_MODIFIED),
+			NewVersionConflictError("TimetableTemplate", templateID, expectedVersion, template.Version, template)
+	}
+
+	txErr := s.app.MySQL.WDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		bumped, err := s.templateRepo.BumpVersionWithDB(ctx, tx, templateID, expectedVersion)
+		if err != nil {
+			return fmt.Errorf("failed to bump template version: %w", err)
+		}
+		if !bumped {
+			return ErrVersionConflict
+		}
+
+		template.Version = expectedVersion + 1
+		return fn(tx, &template)
+	})
+
+	if txErr != nil {
+		if errors.Is(txErr, ErrVersionConflict) {
+			current, err := s.templateRepo.GetByIDAndCenterID(ctx, templateID, centerID)
+			if err != nil {
+				return nil, s.app.Err.New(errInfos.NOT_FOUND), err
+			}
+			return nil, s.app.Err.New(errInfos.ERR_CONCURRENT_MODIFIED),
+				NewVersionConflictError("TimetableTemplate", templateID, expectedVersion, current.Version, current)
+		}
+		return nil, s.app.Err.New(errInfos.ERR_TX_FAILED), txErr
+	}
+
+	return &template, nil, nil
+}
+
+// CreateCellsInput 新增模板格子的輸入參數
+type CreateCellsInput struct {
+	CenterID   uint
+	AdminID    uint
+	TemplateID uint
+	Cells      []models.TimetableCell
+	Version    *uint // 前端讀取時的模板版本（If-Match），nil 表示不比對
+}
+
+// CreateCells 新增模板格子，並遞增模板版本
+func (s *TimetableTemplateService) CreateCells(ctx context.Context, input *CreateCellsInput) ([]models.TimetableCell, *models.TimetableTemplate, *errInfos.Res, error) {
+	cells := make([]models.TimetableCell, 0, len(input.Cells))
+	now := time.Now()
+	for _, cell := range input.Cells {
+		cell.ID = 0
+		cell.TemplateID = input.TemplateID
+		cell.CreatedAt = now
+		cell.UpdatedAt = now
+		cells = append(cells, cell)
+	}
+
+	template, errInfo, err := s.mutateTemplate(ctx, input.CenterID, input.TemplateID, input.Version, func(tx *gorm.DB, template *models.TimetableTemplate) error {
+		if len(cells) > 0 {
+			if err := tx.Create(&cells).Error; err != nil {
+				return fmt.Errorf("failed to create cells: %w", err)
+			}
+		}
+
+		_, err := s.auditLogRepo.CreateWithTxDB(ctx, tx, models.AuditLog{
+			CenterID:   input.CenterID,
+			ActorType:  "ADMIN",
+			ActorID:    input.AdminID,
+			Action:     "TIMETABLE_CELLS_CREATE",
+			TargetType: "TimetableCell",
+			TargetID:   0,
+			Payload: models.AuditPayload{
+				After: map[string]interface{}{
+					"template_id": input.TemplateID,
+					"cells_count": len(cells),
+					"version":     template.Version,
+				},
+			},
+		})
+		return err
+	})
+	if err != nil {
+		return nil, nil, errInfo, err
+	}
+	return cells, template, nil, nil
+}
+
+// ReorderCellsInput 重新排序模板格子的輸入參數
+type ReorderCellsInput struct {
+	CenterID   uint
+	AdminID    uint
+	TemplateID uint
+	Cells      []repositories.ReorderCellRequest
+	Version    *uint // 前端讀取時的模板版本（If-Match），nil 表示不比對
+}
+
+// ReorderCells 更新模板格子的排序，並遞增模板版本；只能排序屬於該模板的格子
+func (s *TimetableTemplateService) ReorderCells(ctx context.Context, input *ReorderCellsInput) (*models.TimetableTemplate, *errInfos.Res, error) {
+	existing, err := s.cellRepo.ListByTemplateID(ctx, input.TemplateID)
+	if err != nil {
+		return nil, s.app.Err.New(errInfos.SQL_ERROR), err
+	}
+	owned := make(map[uint]bool, len(existing))
+	for _, cell := range existing {
+		owned[cell.ID] = true
+	}
+	for _, cell := range input.Cells {
+		if !owned[cell.ID] {
+			return nil, s.app.Err.New(errInfos.NOT_FOUND), fmt.Errorf("cell %d not found in template %d", cell.ID, input.TemplateID)
+		}
+	}
+
+	return s.mutateTemplate(ctx, input.CenterID, input.TemplateID, input.Version, func(tx *gorm.DB, template *models.TimetableTemplate) error {
+		now := time.Now()
+		for _, cell := range input.Cells {
+			if err := tx.Model(&models.TimetableCell{}).
+				Where("id = ? AND template_id = ?", cell.ID, input.TemplateID).
+				Updates(map[string]interface{}{
+					"sort_order": cell.SortOrder,
+					"updated_at": now,
+				}).Error; err != nil {
+				return fmt.Errorf("failed to reorder cell %d: %w", cell.ID, err)
+			}
+		}
+
+		_, err := s.auditLogRepo.CreateWithTxDB(ctx, tx, models.AuditLog{
+			CenterID:   input.CenterID,
+			ActorType:  "ADMIN",
+			ActorID:    input.AdminID,
+			Action:     "TIMETABLE_CELLS_REORDER",
+			TargetType: "TimetableCell",
+			TargetID:   input.TemplateID,
+			Payload: models.AuditPayload{
+				After: map[string]interface{}{
+					"template_id": input.TemplateID,
+					"cells_count": len(input.Cells),
+					"version":     template.Version,
+				},
+			},
+		})
+		return err
+	})
+}
+
+// DeleteCellInput 刪除模板格子的輸入參數
+type DeleteCellInput struct {
+	CenterID uint
+	AdminID  uint
+	CellID   uint
+	Version  *uint // 前端讀取時的模板版本（If-Match），nil 表示不比對
+}
+
+// DeleteCell 刪除模板格子，並遞增模板版本
+func (s *TimetableTemplateService) DeleteCell(ctx context.Context, input *DeleteCellInput) (*models.TimetableTemplate, *errInfos.Res, error) {
+	cell, err := s.cellRepo.GetByID(ctx, input.CellID)
+	if err != nil {
+		return nil, s.app.Err.New(errInfos.NOT_FOUND), err
+	}
+
+	return s.mutateTemplate(ctx, input.CenterID, cell.TemplateID, input.Version, func(tx *gorm.DB, template *models.TimetableTemplate) error {
+		if err := tx.Where("id = ?", input.CellID).Delete(&models.TimetableCell{}).Error; err != nil {
+			return fmt.Errorf("failed to delete cell: %w", err)
+		}
+
+		_, err := s.auditLogRepo.CreateWithTxDB(ctx, tx, models.AuditLog{
+			CenterID:   input.CenterID,
+			ActorType:  "ADMIN",
+			ActorID:    input.AdminID,
+			Action:     "TIMETABLE_CELL_DELETE",
+			TargetType: "TimetableCell",
+			TargetID:   input.CellID,
+			Payload: models.AuditPayload{
+				Before: map[string]interface{}{
+					"template_id": cell.TemplateID,
+					"row_no":      cell.RowNo,
+					"col_no":      cell.ColNo,
+				},
+				After: map[string]interface{}{
+					"version": template.Version,
+				},
+			},
+		})
+		return err
+	})
+}
+
 // ApplyTemplateInput 套用模板的輸入參數
 type ApplyTemplateInput struct {
-	TemplateID      uint
-	CenterID        uint
-	AdminID         uint
-	OfferingID      uint
-	StartDate       string
-	EndDate         string
-	Weekdays        []int
-	Duration        int
-	OverrideBuffer  bool
+	TemplateID     uint
+	CenterID       uint
+	AdminID        uint
+	OfferingID     uint
+	StartDate      string
+	EndDate        string
+	Weekdays       []int
+	Duration       int
+	OverrideBuffer bool
 }
 
 // ApplyTemplateConflictInfo 套用模板衝突資訊
@@ -57,6 +291,8 @@ type ApplyTemplateConflictInfo struct {
 	Message      string `json:"message"`
 	RuleID       uint   `json:"rule_id,omitempty"`
 	CanOverride  bool   `json:"can_override,omitempty"`
+	// SuggestedRooms 教室不符合課程需求時可改用的教室
+	SuggestedRooms []RoomInfo `json:"suggested_rooms,omitempty"`
 }
 
 // ApplyTemplateOutput 套用模板的輸出資料
@@ -121,13 +357,14 @@ func (s *TimetableTemplateService) ApplyTemplate(ctx context.Context, input *App
 	var allConflicts []ApplyTemplateConflictInfo
 	for _, conflict := range validationSummary.AllConflicts {
 		allConflicts = append(allConflicts, ApplyTemplateConflictInfo{
-			Weekday:      conflict.Weekday,
-			StartTime:    conflict.StartTime,
-			EndTime:      conflict.EndTime,
-			ConflictType: conflict.ConflictType,
-			Message:      conflict.Message,
-			RuleID:       conflict.RuleID,
-			CanOverride:  conflict.CanOverride,
+			Weekday:        conflict.Weekday,
+			StartTime:      conflict.StartTime,
+			EndTime:        conflict.EndTime,
+			ConflictType:   conflict.ConflictType,
+			Message:        conflict.Message,
+			RuleID:         conflict.RuleID,
+			CanOverride:    conflict.CanOverride,
+			SuggestedRooms: conflict.SuggestedRooms,
 		})
 		if !conflict.CanOverride {
 			nonOverrideConflicts++
@@ -217,13 +454,13 @@ func (s *TimetableTemplateService) generateScheduleRules(
 	for _, weekday := range weekdays {
 		for _, cell := range cells {
 			rule := models.ScheduleRule{
-				CenterID:    centerID,
-				OfferingID:  offeringID,
-				TeacherID:   cell.TeacherID,
-				RoomID:      *cell.RoomID,
-				Weekday:     weekday,
-				StartTime:   cell.StartTime,
-				EndTime:     cell.EndTime,
+				CenterID:   centerID,
+				OfferingID: offeringID,
+				TeacherID:  cell.TeacherID,
+				RoomID:     *cell.RoomID,
+				Weekday:    weekday,
+				StartTime:  cell.StartTime,
+				EndTime:    cell.EndTime,
 				EffectiveRange: models.DateRange{
 					StartDate: startDate,
 					EndDate:   endDate,
@@ -291,13 +528,14 @@ func (s *TimetableTemplateService) ValidateApplyTemplate(ctx context.Context, in
 	nonOverrideConflicts := 0
 	for _, conflict := range validationSummary.AllConflicts {
 		conflicts = append(conflicts, ApplyTemplateConflictInfo{
-			Weekday:      conflict.Weekday,
-			StartTime:    conflict.StartTime,
-			EndTime:      conflict.EndTime,
-			ConflictType: conflict.ConflictType,
-			Message:      conflict.Message,
-			RuleID:       conflict.RuleID,
-			CanOverride:  conflict.CanOverride,
+			Weekday:        conflict.Weekday,
+			StartTime:      conflict.StartTime,
+			EndTime:        conflict.EndTime,
+			ConflictType:   conflict.ConflictType,
+			Message:        conflict.Message,
+			RuleID:         conflict.RuleID,
+			CanOverride:    conflict.CanOverride,
+			SuggestedRooms: conflict.SuggestedRooms,
 		})
 		if !conflict.CanOverride {
 			nonOverrideConflicts++
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrVersionConflict 樂觀鎖版本不符，資料已被其他請求修改
+var ErrVersionConflict = errors.New("version conflict")
+
+// VersionConflict 版本衝突明細，附上伺服器目前的資料供前端比對差異
+type VersionConflict struct {
+	Resource        string `json:"resource"`
+	ResourceID      uint   `json:"resource_id"`
+	ExpectedVersion uint   `json:"expected_version"`
+	CurrentVersion  uint   `json:"current_version"`
+	Current         any    `json:"current"`
+}
+
+// VersionConflictError 帶有衝突明細的錯誤，可用 errors.Is(err, ErrVersionConflict) 判斷
+type VersionConflictError struct {
+	Conflict VersionConflict
+}
+
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("%s %d version conflict: expected %d, current %d",
+		e.Conflict.Resource, e.Conflict.ResourceID, e.Conflict.ExpectedVersion, e.Conflict.CurrentVersion)
+}
+
+func (e *VersionConflictError) Unwrap() error {
+	return ErrVersionConflict
+}
+
+// NewVersionConflictError 建立版本衝突錯誤
+func NewVersionConflictError(resource string, resourceID, expectedVersion, currentVersion uint, current any) *VersionConflictError {
+	return &VersionConflictError{Conflict: VersionConflict{
+		Resource:        resource,
+		ResourceID:      resourceID,
+		ExpectedVersion: expectedVersion,
+		CurrentVersion:  currentVersion,
+		Current:         current,
+	}}
+}
+
+// expectedVersionOf 請求未帶版本時以讀取當下的版本為準，仍可防止讀寫之間的並發修改
+func expectedVersionOf(requested *uint, loaded uint) uint {
+	if requested != nil {
+		return *requested
+	}
+	return loaded
+}
@@ -0,0 +1,530 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+	"timeLedger/app"
+	"timeLedger/app/models"
+	"timeLedger/app/repositories"
+	"timeLedger/global/errInfos"
+	"timeLedger/libs"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// WebhookRelayBatchSize 每次投遞的筆數
+	WebhookRelayBatchSize = 50
+	// webhookRequestTimeout 單次投遞的逾時時間
+	webhookRequestTimeout = 10 * time.Second
+	// webhookDeliveryLease 搶占後的保留時間，worker 中途停止時會在期滿後重新投遞
+	webhookDeliveryLease = time.Minute
+)
+
+// webhookRetryPolicy 投遞失敗的重試策略（約 2 小時內重試 8 次）
+var webhookRetryPolicy = NotificationRetryPolicy{MaxAttempts: 8, BaseDelay: 30 * time.Second, MaxDelay: time.Hour}
+
+// webhookDomainEvents 領域事件對應的 webhook 事件類型
+var webhookDomainEvents = map[string]string{
+	models.DomainEventRuleChanged:        models.WebhookEventRuleChanged,
+	models.DomainEventExceptionSubmitted: models.WebhookEventExceptionSubmitted,
+	models.DomainEventExceptionReviewed:  models.WebhookEventExceptionReviewed,
+	models.DomainEventTeacherJoined:      models.WebhookEventTeacherJoined,
+	models.DomainEventTeacherLeft:        models.WebhookEventTeacherLeft,
+	models.DomainEventHolidayCreated:     models.WebhookEventHolidayCreated,
+}
+
+// WebhookEventForDomainEvent 取得領域事件對應的 webhook 事件類型，不對外公開的事件回傳空字串
+func WebhookEventForDomainEvent(eventType string) string {
+	return webhookDomainEvents[eventType]
+}
+
+// WebhookEnvelope 送往接收端的 JSON 內容
+type WebhookEnvelope struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	CenterID   uint            `json:"center_id"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// WebhookSubscriptionRequest 新增或更新訂閱
+type WebhookSubscriptionRequest struct {
+	Name       string   `json:"name" binding:"required,max=100"`
+	URL        string   `json:"url" binding:"required,max=500"`
+	EventTypes []string `json:"event_types"`
+	IsActive   *bool    `json:"is_active"`
+}
+
+// WebhookSubscriptionWithSecret 新增訂閱或重設密鑰時回傳，密鑰僅於此時顯示
+type WebhookSubscriptionWithSecret struct {
+	models.WebhookSubscription
+	Secret string `json:"secret"`
+}
+
+// WebhookDeliveryDetail 投遞紀錄（含送出內容）
+type WebhookDeliveryDetail struct {
+	models.WebhookDelivery
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+func newWebhookDeliveryDetail(delivery models.WebhookDelivery) WebhookDeliveryDetail {
+	detail := WebhookDeliveryDetail{WebhookDelivery: delivery}
+	if json.Valid([]byte(delivery.Payload)) {
+		detail.Payload = json.RawMessage(delivery.Payload)
+	}
+	return detail
+}
+
+// ValidateWebhookURL 僅接受絕對網址；非本機環境（allowInsecure 為 false）只接受 https，且不得指向內網位址
+// 網域實際解析到的位址於投遞連線時再檢查
+func ValidateWebhookURL(raw string, allowInsecure bool) error {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return fmt.Errorf("invalid url: %w", err)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("url host is required")
+	}
+	if allowInsecure {
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return fmt.Errorf("url scheme must be http or https")
+		}
+		return nil
+	}
+	if u.Scheme != "https" {
+		return fmt.Errorf("url scheme must be https")
+	}
+	host := strings.ToLower(u.Hostname())
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("url host is not allowed")
+	}
+	if ip := net.ParseIP(host); ip != nil && libs.IsRestrictedWebhookIP(ip) {
+		return fmt.Errorf("url host is not allowed")
+	}
+	return nil
+}
+
+// NormalizeWebhookEventTypes 去除重複並檢查事件類型，空陣列表示訂閱全部
+func NormalizeWebhookEventTypes(eventTypes []string) (models.WebhookEventTypes, error) {
+	result := models.WebhookEventTypes{}
+	for _, t := range eventTypes {
+		if !slices.Contains(models.WebhookEvents, t) {
+			return nil, fmt.Errorf("unsupported event type: %s", t)
+		}
+		if !slices.Contains(result, t) {
+			result = append(result, t)
+		}
+	}
+	return result, nil
+}
+
+// WebhookService 中心對外 webhook：訂閱管理、投遞與重試
+type WebhookService struct {
+	BaseService
+	subscriptionRepo *repositories.WebhookSubscriptionRepository
+	deliveryRepo     *repositories.WebhookDeliveryRepository
+	client           *libs.WebhookClient
+}
+
+// NewWebhookService 建立 webhook 服務
+func NewWebhookService(app *app.App) *WebhookService {
+	svc := &WebhookService{
+		BaseService: *NewBaseService(app, "WebhookService"),
+	}
+	svc.client = libs.NewWebhookClient(webhookRequestTimeout, svc.allowInsecureTargets())
+	if app.MySQL != nil {
+		svc.subscriptionRepo = repositories.NewWebhookSubscriptionRepository(app)
+		svc.deliveryRepo = repositories.NewWebhookDeliveryRepository(app)
+	}
+	return svc
+}
+
+// ListSubscriptions 取得中心的訂閱
+func (s *WebhookService) ListSubscriptions(ctx context.Context, centerID uint) ([]models.WebhookSubscription, *errInfos.Res, error) {
+	subscriptions, err := s.subscriptionRepo.ListByCenterID(ctx, centerID)
+	if err != nil {
+		return nil, s.App.Err.New(errInfos.SQL_ERROR), err
+	}
+	return subscriptions, nil, nil
+}
+
+// GetSubscription 取得中心的訂閱
+func (s *WebhookService) GetSubscription(ctx context.Context, centerID, id uint) (*models.WebhookSubscription, *errInfos.Res, error) {
+	subscription, err := s.subscriptionRepo.GetByIDAndCenterID(ctx, id, centerID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, s.App.Err.New(errInfos.WEBHOOK_NOT_FOUND), err
+	}
+	if err != nil {
+		return nil, s.App.Err.New(errInfos.SQL_ERROR), err
+	}
+	return &subscription, nil, nil
+}
+
+// CreateSubscription 新增訂閱並產生簽章密鑰
+func (s *WebhookService) CreateSubscription(ctx context.Context, centerID, adminID uint, req *WebhookSubscriptionRequest) (*WebhookSubscriptionWithSecret, *errInfos.Res, error) {
+	eventTypes, err := s.validateRequest(req)
+	if err != nil {
+		return nil, s.App.Err.New(errInfos.PARAMS_VALIDATE_ERROR), err
+	}
+	secret, err := libs.NewWebhookSecret()
+	if err != nil {
+		return nil, s.App.Err.New(errInfos.SYSTEM_ERROR), err
+	}
+
+	now := time.Now()
+	subscription := models.WebhookSubscription{
+		CenterID:   centerID,
+		Name:       strings.TrimSpace(req.Name),
+		URL:        strings.TrimSpace(req.URL),
+		Secret:     secret,
+		EventTypes: eventTypes,
+		IsActive:   req.IsActive == nil || *req.IsActive,
+		CreatedBy:  adminID,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	created, err := s.subscriptionRepo.Create(ctx, subscription)
+	if err != nil {
+		return nil, s.App.Err.New(errInfos.SQL_ERROR), err
+	}
+	return &WebhookSubscriptionWithSecret{WebhookSubscription: created, Secret: secret}, nil, nil
+}
+
+// UpdateSubscription 更新訂閱的名稱、網址、事件類型與啟用狀態
+func (s *WebhookService) UpdateSubscription(ctx context.Context, centerID, id uint, req *WebhookSubscriptionRequest) (*models.WebhookSubscription, *errInfos.Res, error) {
+	subscription, errInfo, err := s.GetSubscription(ctx, centerID, id)
+	if err != nil {
+		return nil, errInfo, err
+	}
+	eventTypes, err := s.validateRequest(req)
+	if err != nil {
+		return nil, s.App.Err.New(errInfos.PARAMS_VALIDATE_ERROR), err
+	}
+
+	subscription.Name = strings.TrimSpace(req.Name)
+	subscription.URL = strings.TrimSpace(req.URL)
+	subscription.EventTypes = eventTypes
+	if req.IsActive != nil {
+		subscription.IsActive = *req.IsActive
+	}
+	subscription.UpdatedAt = time.Now()
+	if err := s.subscriptionRepo.UpdateFields(ctx, id, map[string]interface{}{
+		"name":        subscription.Name,
+		"url":         subscription.URL,
+		"event_types": subscription.EventTypes,
+		"is_active":   subscription.IsActive,
+		"updated_at":  subscription.UpdatedAt,
+	}); err != nil {
+		return nil, s.App.Err.New(errInfos.SQL_ERROR), err
+	}
+	return subscription, nil, nil
+}
+
+// DeleteSubscription 刪除訂閱，尚未送出的投遞不再重試
+func (s *WebhookService) DeleteSubscription(ctx context.Context, centerID, id uint) (*errInfos.Res, error) {
+	if _, errInfo, err := s.GetSubscription(ctx, centerID, id); err != nil {
+		return errInfo, err
+	}
+	if err := s.subscriptionRepo.DeleteByIDWithCenterScope(ctx, id, centerID); err != nil {
+		return s.App.Err.New(errInfos.SQL_ERROR), err
+	}
+	return nil, nil
+}
+
+// RotateSecret 重設簽章密鑰，舊密鑰立即失效
+func (s *WebhookService) RotateSecret(ctx context.Context, centerID, id uint) (*WebhookSubscriptionWithSecret, *errInfos.Res, error) {
+	subscription, errInfo, err := s.GetSubscription(ctx, centerID, id)
+	if err != nil {
+		return nil, errInfo, err
+	}
+	secret, err := libs.NewWebhookSecret()
+	if err != nil {
+		return nil, s.App.Err.New(errInfos.SYSTEM_ERROR), err
+	}
+	subscription.UpdatedAt = time.Now()
+	if err := s.subscriptionRepo.UpdateFields(ctx, id, map[string]interface{}{
+		"secret":     secret,
+		"updated_at": subscription.UpdatedAt,
+	}); err != nil {
+		return nil, s.App.Err.New(errInfos.SQL_ERROR), err
+	}
+	return &WebhookSubscriptionWithSecret{WebhookSubscription: *subscription, Secret: secret}, nil, nil
+}
+
+// allowInsecureTargets 本機環境允許 http 與內網接收端，方便開發時對 localhost 測試
+func (s *WebhookService) allowInsecureTargets() bool {
+	return s.App.Env != nil && s.App.Env.AppEnv == "local"
+}
+
+func (s *WebhookService) validateRequest(req *WebhookSubscriptionRequest) (models.WebhookEventTypes, error) {
+	if strings.TrimSpace(req.Name) == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if err := ValidateWebhookURL(req.URL, s.allowInsecureTargets()); err != nil {
+		return nil, err
+	}
+	return NormalizeWebhookEventTypes(req.EventTypes)
+}
+
+// ListDeliveries 分頁取得訂閱的投遞紀錄
+func (s *WebhookService) ListDeliveries(ctx context.Context, centerID, subscriptionID uint, status string, page, limit int) ([]models.WebhookDelivery, int64, *errInfos.Res, error) {
+	if _, errInfo, err := s.GetSubscription(ctx, centerID, subscriptionID); err != nil {
+		return nil, 0, errInfo, err
+	}
+	deliveries, total, err := s.deliveryRepo.ListBySubscription(ctx, centerID, subscriptionID, status, page, limit)
+	if err != nil {
+		return nil, 0, s.App.Err.New(errInfos.SQL_ERROR), err
+	}
+	return deliveries, total, nil, nil
+}
+
+// GetDelivery 取得單筆投遞紀錄（含送出內容）
+func (s *WebhookService) GetDelivery(ctx context.Context, centerID, id uint) (*WebhookDeliveryDetail, *errInfos.Res, error) {
+	delivery, err := s.deliveryRepo.GetByIDAndCenterID(ctx, id, centerID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, s.App.Err.New(errInfos.WEBHOOK_NOT_FOUND), err
+	}
+	if err != nil {
+		return nil, s.App.Err.New(errInfos.SQL_ERROR), err
+	}
+	detail := newWebhookDeliveryDetail(delivery)
+	return &detail, nil, nil
+}
+
+// Redeliver 重送失敗的投遞：重設重試次數並立即送出一次
+func (s *WebhookService) Redeliver(ctx context.Context, centerID, id uint) (*WebhookDeliveryDetail, *errInfos.Res, error) {
+	if _, errInfo, err := s.GetDelivery(ctx, centerID, id); err != nil {
+		return nil, errInfo, err
+	}
+	requeued, err := s.deliveryRepo.Requeue(ctx, id, centerID, time.Now())
+	if err != nil {
+		return nil, s.App.Err.New(errInfos.SQL_ERROR), err
+	}
+	if !requeued {
+		return nil, s.App.Err.New(errInfos.WEBHOOK_DELIVERY_NOT_FAILED), fmt.Errorf("webhook delivery %d is not failed", id)
+	}
+	if err := s.Deliver(ctx, id); err != nil {
+		return nil, s.App.Err.New(errInfos.SQL_ERROR), err
+	}
+	s.Logger.Info("webhook delivery redelivered", "delivery_id", id, "center_id", centerID)
+	return s.GetDelivery(ctx, centerID, id)
+}
+
+// SendTestEvent 對訂閱送出測試事件（不受事件篩選與啟用狀態影響），同步回傳投遞結果
+func (s *WebhookService) SendTestEvent(ctx context.Context, centerID, subscriptionID uint) (*WebhookDeliveryDetail, *errInfos.Res, error) {
+	subscription, errInfo, err := s.GetSubscription(ctx, centerID, subscriptionID)
+	if err != nil {
+		return nil, errInfo, err
+	}
+
+	data, _ := json.Marshal(map[string]any{
+		"message":         "This is a test event from TimeLedger.",
+		"subscription_id": subscription.ID,
+	})
+	delivery, err := s.enqueue(ctx, *subscription, WebhookEnvelope{
+		ID:         uuid.New().String(),
+		Type:       models.WebhookEventTest,
+		CenterID:   centerID,
+		OccurredAt: time.Now(),
+		Data:       data,
+	})
+	if err != nil {
+		return nil, s.App.Err.New(errInfos.SQL_ERROR), err
+	}
+	if err := s.Deliver(ctx, delivery.ID); err != nil {
+		return nil, s.App.Err.New(errInfos.SQL_ERROR), err
+	}
+	return s.GetDelivery(ctx, centerID, delivery.ID)
+}
+
+// Fanout 為訂閱該事件的啟用中訂閱建立投遞紀錄，重複呼叫不會重複建立，回傳新建立的筆數
+func (s *WebhookService) Fanout(ctx context.Context, envelope WebhookEnvelope) (int, error) {
+	subscriptions, err := s.subscriptionRepo.ListActiveByCenterID(ctx, envelope.CenterID)
+	if err != nil {
+		return 0, err
+	}
+	created := 0
+	for _, subscription := range subscriptions {
+		if !subscription.Subscribes(envelope.Type) {
+			continue
+		}
+		delivery, err := s.enqueue(ctx, subscription, envelope)
+		if err != nil {
+			return created, err
+		}
+		if delivery != nil {
+			created++
+		}
+	}
+	return created, nil
+}
+
+// enqueue 寫入待投遞紀錄，已存在時回傳 nil
+func (s *WebhookService) enqueue(ctx context.Context, subscription models.WebhookSubscription, envelope WebhookEnvelope) (*models.WebhookDelivery, error) {
+	body, err := json.Marshal(envelope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+	now := time.Now()
+	delivery := models.WebhookDelivery{
+		CenterID:       subscription.CenterID,
+		SubscriptionID: subscription.ID,
+		EventID:        envelope.ID,
+		EventType:      envelope.Type,
+		Payload:        string(body),
+		Status:         models.WebhookDeliveryPending,
+		NextAttemptAt:  now,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	created, err := s.deliveryRepo.CreateIfAbsent(ctx, &delivery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record webhook delivery: %w", err)
+	}
+	if !created {
+		return nil, nil
+	}
+	return &delivery, nil
+}
+
+// RelayDue 投遞到期的紀錄（首次投遞或退避重試），回傳處理的筆數
+func (s *WebhookService) RelayDue(ctx context.Context, limit int) (int, error) {
+	deliveries, err := s.deliveryRepo.ListDue(ctx, time.Now(), limit)
+	if err != nil {
+		return 0, err
+	}
+	for i, delivery := range deliveries {
+		if err := s.Deliver(ctx, delivery.ID); err != nil {
+			return i, err
+		}
+	}
+	return len(deliveries), nil
+}
+
+// Deliver 投遞單筆紀錄，已被其他 worker 搶占時略過
+func (s *WebhookService) Deliver(ctx context.Context, id uint) error {
+	now := time.Now()
+	claimed, err := s.deliveryRepo.Claim(ctx, id, now, now.Add(webhookDeliveryLease))
+	if err != nil || !claimed {
+		return err
+	}
+	delivery, err := s.deliveryRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// 訂閱已刪除時不再投遞
+	subscription, err := s.subscriptionRepo.GetByID(ctx, delivery.SubscriptionID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		delivery.Status = models.WebhookDeliveryFailed
+		delivery.LastError = "subscription deleted"
+		delivery.UpdatedAt = time.Now()
+		return s.deliveryRepo.RecordAttempt(ctx, delivery)
+	}
+	if err != nil {
+		return err
+	}
+
+	resp, sendErr := s.client.Send(ctx, libs.WebhookRequest{
+		URL:        subscription.URL,
+		Secret:     subscription.Secret,
+		EventType:  delivery.EventType,
+		EventID:    delivery.EventID,
+		DeliveryID: delivery.ID,
+		Body:       []byte(delivery.Payload),
+	})
+	settleWebhookDelivery(&delivery, resp, sendErr, time.Now())
+	if delivery.Status != models.WebhookDeliveryDelivered {
+		s.Logger.Warn("webhook delivery failed",
+			"delivery_id", delivery.ID, "subscription_id", subscription.ID, "event_type", delivery.EventType,
+			"attempt", delivery.Attempts, "status_code", resp.StatusCode, "error", delivery.LastError, "send_error", sendErr)
+	}
+	return s.deliveryRepo.RecordAttempt(ctx, delivery)
+}
+
+// settleWebhookDelivery 依投遞結果更新紀錄：成功、退避重試或標記失敗
+func settleWebhookDelivery(delivery *models.WebhookDelivery, resp libs.WebhookResponse, sendErr error, now time.Time) {
+	delivery.Attempts++
+	delivery.ResponseStatus = resp.StatusCode
+	delivery.DurationMs = int(resp.Duration.Milliseconds())
+	delivery.UpdatedAt = now
+
+	switch {
+	case sendErr != nil:
+		delivery.LastError = webhookSendErrorMessage(sendErr)
+	case !resp.Success():
+		delivery.LastError = fmt.Sprintf("unexpected status code %d", resp.StatusCode)
+	default:
+		delivery.Status = models.WebhookDeliveryDelivered
+		delivery.LastError = ""
+		delivery.DeliveredAt = &now
+		return
+	}
+
+	if delivery.Attempts >= webhookRetryPolicy.MaxAttempts {
+		delivery.Status = models.WebhookDeliveryFailed
+		return
+	}
+	delivery.Status = models.WebhookDeliveryPending
+	delivery.NextAttemptAt = now.Add(webhookRetryPolicy.Backoff(delivery.Attempts))
+}
+
+// webhookSendErrorMessage 投遞紀錄對中心公開，只記錄錯誤類別，完整錯誤僅寫入 log
+func webhookSendErrorMessage(err error) string {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, libs.ErrWebhookRestrictedAddress):
+		return "target address is not allowed"
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return "request timed out"
+	default:
+		return "request failed"
+	}
+}
+
+// DomainEventWebhookHandler 將領域事件轉為中心訂閱的 webhook 投遞
+type DomainEventWebhookHandler struct {
+	BaseService
+	webhooks *WebhookService
+}
+
+// NewDomainEventWebhookHandler 建立 webhook 消費端
+func NewDomainEventWebhookHandler(app *app.App) *DomainEventWebhookHandler {
+	return &DomainEventWebhookHandler{
+		BaseService: *NewBaseService(app, "DomainEventWebhookHandler"),
+		webhooks:    NewWebhookService(app),
+	}
+}
+
+func (h *DomainEventWebhookHandler) Name() string {
+	return "webhook"
+}
+
+// Handle 建立投遞紀錄即完成，實際送出由 WebhookService.RelayDue 處理；以事件 ID 去重，重複消費不會重複投遞
+func (h *DomainEventWebhookHandler) Handle(ctx context.Context, msg DomainEventMessage) error {
+	webhookEvent := WebhookEventForDomainEvent(msg.EventType)
+	if webhookEvent == "" {
+		return nil
+	}
+	data := msg.Payload
+	if len(data) == 0 {
+		data = json.RawMessage("{}")
+	}
+	_, err := h.webhooks.Fanout(ctx, WebhookEnvelope{
+		ID:         msg.EventID,
+		Type:       webhookEvent,
+		CenterID:   msg.CenterID,
+		OccurredAt: msg.OccurredAt,
+		Data:       data,
+	})
+	return err
+}
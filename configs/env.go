@@ -52,6 +52,10 @@ type Env struct {
 	LineChannelSecret      string
 	LineChannelAccessToken string
 	LineLiffID             string
+	LineAPIBaseURL         string // 測試時可指向 stub server
+	LineAPIDataBaseURL     string // 圖片上傳等資料 API
+	LineRichMenuEnabled    bool
+	LineRichMenuConfigPath string // 未設定時使用內建的 configs/line_rich_menus.json
 
 	// Frontend
 	FrontendBaseURL       string
@@ -79,6 +83,19 @@ type Env struct {
 	CloudflareR2SecretKey  string
 	CloudflareR2BucketName string
 	CloudflareR2PublicURL  string
+
+	// SMTP Email
+	SmtpEnabled     bool
+	SmtpHost        string
+	SmtpPort        string
+	SmtpUsername    string
+	SmtpPassword    string
+	SmtpFromAddress string
+	SmtpFromName    string
+	SmtpTLSMode     string // none, starttls, tls
+
+	// Notification Dispatcher
+	NotificationBackend string // database, redis, asynq
 }
 
 func LoadEnv() *Env {
@@ -130,6 +147,10 @@ func LoadEnv() *Env {
 		LineChannelSecret:      os.Getenv("LINE_CHANNEL_SECRET"),
 		LineChannelAccessToken: os.Getenv("LINE_CHANNEL_ACCESS_TOKEN"),
 		LineLiffID:             os.Getenv("LIFF_ID"),
+		LineAPIBaseURL:         getEnvAsString("LINE_API_BASE_URL", "https://api.line.me"),
+		LineAPIDataBaseURL:     getEnvAsString("LINE_API_DATA_BASE_URL", "https://api-data.line.me"),
+		LineRichMenuEnabled:    getEnvAsBool("LINE_RICH_MENU_ENABLED", false),
+		LineRichMenuConfigPath: os.Getenv("LINE_RICH_MENU_CONFIG_PATH"),
 
 		// Frontend
 		FrontendBaseURL:       os.Getenv("FRONTEND_BASE_URL"),
@@ -157,6 +178,19 @@ func LoadEnv() *Env {
 		CloudflareR2SecretKey:  os.Getenv("CLOUDFLARE_R2_SECRET_KEY"),
 		CloudflareR2BucketName: os.Getenv("CLOUDFLARE_R2_BUCKET_NAME"),
 		CloudflareR2PublicURL:  os.Getenv("CLOUDFLARE_R2_PUBLIC_URL"),
+
+		// SMTP Email
+		SmtpEnabled:     getEnvAsBool("SMTP_ENABLED", false),
+		SmtpHost:        os.Getenv("SMTP_HOST"),
+		SmtpPort:        getEnvAsString("SMTP_PORT", "587"),
+		SmtpUsername:    os.Getenv("SMTP_USERNAME"),
+		SmtpPassword:    os.Getenv("SMTP_PASSWORD"),
+		SmtpFromAddress: os.Getenv("SMTP_FROM_ADDRESS"),
+		SmtpFromName:    getEnvAsString("SMTP_FROM_NAME", "TimeLedger"),
+		SmtpTLSMode:     getEnvAsString("SMTP_TLS_MODE", "starttls"),
+
+		// Notification Dispatcher
+		NotificationBackend: getEnvAsString("NOTIFICATION_BACKEND", "database"),
 	}
 }
 
@@ -0,0 +1,189 @@
+package configs
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+)
+
+//go:embed line_rich_menus.json
+var defaultLineRichMenuConfig []byte
+
+// LINE Rich Menu 限制
+const (
+	lineRichMenuMaxAreas     = 20
+	lineRichMenuMaxWidth     = 2500
+	lineRichMenuMinWidth     = 800
+	lineRichMenuMinHeight    = 250
+	lineRichMenuMaxChatBar   = 14
+	lineRichMenuMaxNameRunes = 300
+)
+
+var lineRichMenuColorPattern = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
+// LineRichMenuConfig LINE Rich Menu 設定，每個角色對應一個選單
+type LineRichMenuConfig struct {
+	Menus []LineRichMenuDefinition `json:"menus"`
+}
+
+// LineRichMenuDefinition 單一 Rich Menu
+// Roles 可為 GUEST（未綁定）、TEACHER 或管理員角色（OWNER / ADMIN / STAFF）
+// Default 為頻道預設選單（未個別連結的使用者皆顯示此選單）
+// Image 為選單圖片路徑，未設定時依各區塊的 Color 產生純色圖片
+type LineRichMenuDefinition struct {
+	Key         string             `json:"key"`
+	Roles       []string           `json:"roles"`
+	Default     bool               `json:"default,omitempty"`
+	Name        string             `json:"name"`
+	ChatBarText string             `json:"chat_bar_text"`
+	Selected    bool               `json:"selected"`
+	Size        LineRichMenuSize   `json:"size"`
+	Image       string             `json:"image,omitempty"`
+	Areas       []LineRichMenuArea `json:"areas"`
+}
+
+// LineRichMenuSize 選單尺寸（像素）
+type LineRichMenuSize struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// LineRichMenuBounds 點擊區塊範圍
+type LineRichMenuBounds struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// LineRichMenuAction 點擊動作，uri 可使用 {{frontend_base_url}}、{{liff_url}} 變數
+type LineRichMenuAction struct {
+	Type        string `json:"type"`
+	Label       string `json:"label,omitempty"`
+	Text        string `json:"text,omitempty"`
+	URI         string `json:"uri,omitempty"`
+	Data        string `json:"data,omitempty"`
+	DisplayText string `json:"displayText,omitempty"`
+}
+
+// LineRichMenuArea 點擊區塊
+type LineRichMenuArea struct {
+	Bounds LineRichMenuBounds `json:"bounds"`
+	Action LineRichMenuAction `json:"action"`
+	Color  string             `json:"color,omitempty"` // 產生圖片時的區塊底色
+}
+
+// LoadLineRichMenuConfig 讀取 Rich Menu 設定，path 為空時使用內建設定
+func LoadLineRichMenuConfig(path string) (*LineRichMenuConfig, error) {
+	raw := defaultLineRichMenuConfig
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("讀取 Rich Menu 設定失敗: %w", err)
+		}
+		raw = data
+	}
+
+	var config LineRichMenuConfig
+	if err := json.Unmarshal(raw, &config); err != nil {
+		return nil, fmt.Errorf("解析 Rich Menu 設定失敗: %w", err)
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// Validate 檢查設定是否符合 LINE 的限制，且每個角色只對應一個選單
+func (c *LineRichMenuConfig) Validate() error {
+	keys := map[string]bool{}
+	roles := map[string]string{}
+	hasDefault := false
+
+	for _, menu := range c.Menus {
+		if menu.Key == "" {
+			return fmt.Errorf("rich menu key 不可為空")
+		}
+		if keys[menu.Key] {
+			return fmt.Errorf("rich menu key 重複: %s", menu.Key)
+		}
+		keys[menu.Key] = true
+
+		for _, role := range menu.Roles {
+			if other, ok := roles[role]; ok {
+				return fmt.Errorf("角色 %s 同時對應 rich menu %s 與 %s", role, other, menu.Key)
+			}
+			roles[role] = menu.Key
+		}
+		if menu.Default {
+			if hasDefault {
+				return fmt.Errorf("只能有一個預設 rich menu")
+			}
+			hasDefault = true
+		}
+
+		if menu.Name == "" || len([]rune(menu.Name)) > lineRichMenuMaxNameRunes {
+			return fmt.Errorf("rich menu %s 名稱需為 1～%d 字", menu.Key, lineRichMenuMaxNameRunes)
+		}
+		if menu.ChatBarText == "" || len([]rune(menu.ChatBarText)) > lineRichMenuMaxChatBar {
+			return fmt.Errorf("rich menu %s 選單列文字需為 1～%d 字", menu.Key, lineRichMenuMaxChatBar)
+		}
+
+		size := menu.Size
+		if size.Width < lineRichMenuMinWidth || size.Width > lineRichMenuMaxWidth || size.Height < lineRichMenuMinHeight ||
+			float64(size.Width)/float64(size.Height) < 1.45 {
+			return fmt.Errorf("rich menu %s 尺寸不符 LINE 規範: %dx%d", menu.Key, size.Width, size.Height)
+		}
+
+		if len(menu.Areas) == 0 || len(menu.Areas) > lineRichMenuMaxAreas {
+			return fmt.Errorf("rich menu %s 區塊數需為 1～%d", menu.Key, lineRichMenuMaxAreas)
+		}
+		for i, area := range menu.Areas {
+			b := area.Bounds
+			if b.X < 0 || b.Y < 0 || b.Width <= 0 || b.Height <= 0 || b.X+b.Width > size.Width || b.Y+b.Height > size.Height {
+				return fmt.Errorf("rich menu %s 第 %d 個區塊超出範圍", menu.Key, i+1)
+			}
+			if area.Color != "" && !lineRichMenuColorPattern.MatchString(area.Color) {
+				return fmt.Errorf("rich menu %s 第 %d 個區塊顏色格式錯誤: %s", menu.Key, i+1, area.Color)
+			}
+			if err := validateLineRichMenuAction(area.Action); err != nil {
+				return fmt.Errorf("rich menu %s 第 %d 個區塊: %w", menu.Key, i+1, err)
+			}
+		}
+	}
+	return nil
+}
+
+func validateLineRichMenuAction(action LineRichMenuAction) error {
+	switch action.Type {
+	case "message":
+		if action.Text == "" {
+			return fmt.Errorf("message 動作需設定 text")
+		}
+	case "uri":
+		if action.URI == "" {
+			return fmt.Errorf("uri 動作需設定 uri")
+		}
+	case "postback":
+		if action.Data == "" {
+			return fmt.Errorf("postback 動作需設定 data")
+		}
+	default:
+		return fmt.Errorf("不支援的動作類型: %s", action.Type)
+	}
+	return nil
+}
+
+// MenuForRole 取得角色對應的選單，未設定時回傳 nil
+func (c *LineRichMenuConfig) MenuForRole(role string) *LineRichMenuDefinition {
+	for i := range c.Menus {
+		for _, r := range c.Menus[i].Roles {
+			if r == role {
+				return &c.Menus[i]
+			}
+		}
+	}
+	return nil
+}
@@ -26,6 +26,8 @@ func (db *DB) AutoMigrate() {
 		&models.TimetableTemplate{},
 		&models.TimetableCell{},
 		&models.ScheduleRule{},
+		&models.ScheduleRuleTeacher{},
+		&models.ScheduleRuleRoom{},
 		&models.ScheduleException{},
 		&models.PersonalEvent{},
 		&models.TeacherSkill{},
@@ -36,10 +38,28 @@ func (db *DB) AutoMigrate() {
 		&models.TeacherBackground{},
 		&models.CenterTeacherNote{},
 		&models.CenterHoliday{},
+		&models.CenterMakeupWorkday{},
+		&models.RecurringHoliday{},
+		&models.CenterOperatingHoursOverride{},
 		&models.SessionNote{},
 		&models.AuditLog{},
 		&models.Notification{},
 		&models.NotificationQueue{},
+		&models.NotificationPreference{},
+		&models.OfferingTermQuota{},
+		&models.MakeupSession{},
+		&models.RoomBooking{},
+		&models.CenterDailyKPI{},
+		&models.DomainEvent{},
+		&models.ClassReminder{},
+		&models.WebhookSubscription{},
+		&models.WebhookDelivery{},
+		&models.Broadcast{},
+		&models.BroadcastRecipient{},
+		&models.NotificationTemplate{},
+		&models.LineRichMenu{},
+		&models.LineGroup{},
+		&models.LineWebhookEvent{},
 	); err != nil {
 		panic(fmt.Errorf("MySQL autoMigrate failed: %v", err))
 	}
@@ -56,6 +76,9 @@ func (db *DB) AutoMigrate() {
 
 	// 遷移 schedule_rules status 欄位
 	db.MigrateScheduleRulesStatus()
+
+	// 同一原場次只能有一筆安排中的補課
+	db.MigrateMakeupSessionsUnique()
 }
 
 // dropTablesForReschema 刪除需要重建的資料表
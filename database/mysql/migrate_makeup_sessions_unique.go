@@ -0,0 +1,50 @@
+package mysql
+
+import (
+	"log"
+)
+
+// MigrateMakeupSessionsUnique 限制同一原場次（規則 + 原日期）只能有一筆安排中的補課
+//
+// MySQL 不支援部分索引，改以 Generated Column 標記安排中的補課：
+// 安排中且未刪除時為 1，其餘為 NULL；唯一索引不比對 NULL，已取消的補課不受限制
+func (db *DB) MigrateMakeupSessionsUnique() {
+	if err := db.addGeneratedColumnIfNotExists(
+		"makeup_sessions",
+		"scheduled_flag",
+		"TINYINT GENERATED ALWAYS AS (IF(status = 'SCHEDULED' AND deleted_at IS NULL, 1, NULL)) STORED",
+	); err != nil {
+		log.Printf("Warning: Failed to add scheduled_flag column: %v", err)
+		return
+	}
+
+	if err := db.addUniqueIndexIfNotExists("makeup_sessions", "idx_makeup_scheduled_session", "original_rule_id, original_date, scheduled_flag"); err != nil {
+		log.Printf("Warning: Failed to add unique index on scheduled makeup sessions: %v", err)
+	}
+}
+
+// addUniqueIndexIfNotExists 檢查並新增唯一索引
+func (db *DB) addUniqueIndexIfNotExists(tableName, indexName, columns string) error {
+	var count int
+	result := db.WDB.Raw(`
+		SELECT COUNT(*)
+		FROM information_schema.STATISTICS
+		WHERE TABLE_SCHEMA = DATABASE()
+		AND TABLE_NAME = ?
+		AND INDEX_NAME = ?
+	`, tableName, indexName).Scan(&count)
+	if result.Error != nil {
+		return result.Error
+	}
+	if count > 0 {
+		return nil
+	}
+
+	addSQL := "ALTER TABLE " + tableName + " ADD UNIQUE INDEX " + indexName + " (" + columns + ")"
+	if err := db.WDB.Exec(addSQL).Error; err != nil {
+		return err
+	}
+
+	log.Printf("Added unique index %s on %s(%s)", indexName, tableName, columns)
+	return nil
+}
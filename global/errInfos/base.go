@@ -33,6 +33,20 @@ func Initialize(appID int) *ErrInfo {
 type Res struct {
 	Code ErrCode
 	Msg  string
+	base ErrCode // 未加 app ID 前綴的錯誤碼
+}
+
+// BaseCode 未加 app ID 前綴的錯誤碼；直接建立的 Res 沒有前綴，回傳 Code
+func (r *Res) BaseCode() ErrCode {
+	if r.base != 0 {
+		return r.base
+	}
+	return r.Code
+}
+
+// Is 判斷是否為指定錯誤碼（Code 帶有 app ID 前綴，不能直接與常數比較）
+func (r *Res) Is(code ErrCode) bool {
+	return r != nil && r.BaseCode() == code
 }
 
 // 透過錯誤代碼取得對應的訊息
@@ -50,19 +64,20 @@ func (e *ErrInfo) New(code ErrCode, lang ...string) *Res {
 		selectedLang = lang[0]
 	}
 
+	base := code
 	codeStr := fmt.Sprintf("%d%d", e.appID, code)
 	codeInt, _ := strconv.Atoi(codeStr)
 	code = ErrCode(codeInt)
 
 	switch selectedLang {
 	case LANG_EN:
-		return &Res{Code: code, Msg: msgs.EN}
+		return &Res{Code: code, Msg: msgs.EN, base: base}
 	case LANG_TW:
-		return &Res{Code: code, Msg: msgs.TW}
+		return &Res{Code: code, Msg: msgs.TW, base: base}
 	case LANG_CN:
-		return &Res{Code: code, Msg: msgs.CN}
+		return &Res{Code: code, Msg: msgs.CN, base: base}
 	default:
-		return &Res{Code: code, Msg: msgs.EN}
+		return &Res{Code: code, Msg: msgs.EN, base: base}
 	}
 }
 
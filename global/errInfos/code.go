@@ -19,6 +19,13 @@ const (
 	RATE_LIMIT_EXCEEDED   ErrCode = 10009
 )
 
+// 冪等請求相關 (1) - 用於 Idempotency-Key 重送保護
+const (
+	IDEMPOTENCY_KEY_INVALID     ErrCode = 10010 // Idempotency-Key 格式錯誤
+	IDEMPOTENCY_KEY_REUSED      ErrCode = 10011 // 同一 Idempotency-Key 搭配不同請求內容
+	IDEMPOTENCY_REQUEST_PENDING ErrCode = 10012 // 同一 Idempotency-Key 的請求仍在處理中
+)
+
 // 資料庫、快取相關 (2)
 const (
 	SQL_ERROR ErrCode = 20001
@@ -84,11 +91,15 @@ const (
 
 // LINE Bot 與通知類 (9)
 const (
-	LINE_ALREADY_BOUND        ErrCode = 90001
-	LINE_NOT_BOUND            ErrCode = 90002
-	LINE_BINDING_CODE_INVALID ErrCode = 90003
-	LINE_BINDING_EXPIRED      ErrCode = 90004
-	LINE_NOTIFY_FAILED        ErrCode = 90005
+	LINE_ALREADY_BOUND          ErrCode = 90001
+	LINE_NOT_BOUND              ErrCode = 90002
+	LINE_BINDING_CODE_INVALID   ErrCode = 90003
+	LINE_BINDING_EXPIRED        ErrCode = 90004
+	LINE_NOTIFY_FAILED          ErrCode = 90005
+	LINE_GROUP_NOT_FOUND        ErrCode = 90006 // LINE 群組不存在或未連結此中心
+	LINE_GROUP_LINKED_ELSEWHERE ErrCode = 90007 // LINE 群組已連結其他中心
+	LINE_EVENT_NOT_FOUND        ErrCode = 90008 // LINE webhook 事件紀錄不存在
+	LINE_EVENT_NOT_FAILED       ErrCode = 90009 // 僅能重新處理失敗的 LINE webhook 事件
 )
 
 // 管理員類 (10)
@@ -134,6 +145,7 @@ const (
 	SCHED_INVALID_DATE_FORMAT    ErrCode = 50016 // 無效的日期格式
 	SCHED_END_BEFORE_START       ErrCode = 50017 // 結束日期早於開始日期
 	SCHED_DURATION_EXCEEDS_LIMIT ErrCode = 50018 // 課程時長超過限制
+	SCHED_ROOM_UNSUITABLE        ErrCode = 50019 // 教室不符合課程需求
 )
 
 // 例外審核業務類錯誤 (60)
@@ -156,3 +168,32 @@ const (
 	RECURRENCE_DELETE_CONFIRM       ErrCode = 60017 // 刪除操作需要確認
 	RECURRENCE_BATCH_LIMIT_EXCEEDED ErrCode = 60018 // 批量操作超過限制
 )
+
+// 補課類 (13)
+const (
+	MAKEUP_SESSION_NOT_CANCELLED ErrCode = 130001 // 原場次未被取消，無需補課
+	MAKEUP_ALREADY_SCHEDULED     ErrCode = 130002 // 原場次已安排補課
+)
+
+// 假日行事曆類 (14)
+const (
+	HOLIDAY_DATASET_YEAR_UNAVAILABLE ErrCode = 140001 // 內建假日資料集未收錄該年度
+)
+
+// 通知佇列類 (15)
+const (
+	NOTIFICATION_NOT_FOUND          ErrCode = 150001 // 死信通知不存在或已處理
+	NOTIFICATION_TEMPLATE_NOT_FOUND ErrCode = 150002 // 通知範本不存在或尚未自訂
+)
+
+// Webhook 類 (16)
+const (
+	WEBHOOK_NOT_FOUND           ErrCode = 160001 // Webhook 訂閱或投遞紀錄不存在
+	WEBHOOK_DELIVERY_NOT_FAILED ErrCode = 160002 // 僅能重送失敗的投遞
+)
+
+// 廣播類 (17)
+const (
+	BROADCAST_NOT_FOUND       ErrCode = 170001 // 廣播不存在
+	BROADCAST_NOT_CANCELLABLE ErrCode = 170002 // 僅能取消尚未發送的排程廣播
+)
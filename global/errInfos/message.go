@@ -12,6 +12,11 @@ var messagesMap = map[ErrCode]message{
 	NOT_IMPLEMENTED:       {EN: "Feature not implemented", TW: "功能尚未實作", CN: "功能尚未实现"},
 	RATE_LIMIT_EXCEEDED:   {EN: "Rate limit exceeded", TW: "請求頻率過高，請稍後再試", CN: "请求频率过高，请稍后再试"},
 
+	// 冪等請求相關
+	IDEMPOTENCY_KEY_INVALID:     {EN: "Invalid Idempotency-Key header", TW: "Idempotency-Key 格式錯誤", CN: "Idempotency-Key 格式错误"},
+	IDEMPOTENCY_KEY_REUSED:      {EN: "Idempotency-Key was already used with a different request", TW: "Idempotency-Key 已用於不同的請求內容", CN: "Idempotency-Key 已用于不同的请求内容"},
+	IDEMPOTENCY_REQUEST_PENDING: {EN: "A request with this Idempotency-Key is still in progress", TW: "相同 Idempotency-Key 的請求仍在處理中，請稍後再試", CN: "相同 Idempotency-Key 的请求仍在处理中，请稍后再试"},
+
 	// 資料庫、快取相關
 	SQL_ERROR: {EN: "Database operation failed", TW: "資料庫操作失敗", CN: "数据库操作失败"},
 	TX_ERROR:  {EN: "SQL transaction error.", TW: "交易錯誤", CN: "交易错误"},
@@ -43,6 +48,7 @@ var messagesMap = map[ErrCode]message{
 	SCHED_INVALID_RANGE:    {EN: "Invalid date range", TW: "日期範圍錯誤", CN: "日期范围错误"},
 	SCHED_RULE_CONFLICT:    {EN: "Rule conflict detected", TW: "規則衝突", CN: "规则冲突"},
 	SCHED_EXCEPTION_EXISTS: {EN: "Exception already exists", TW: "該日期已有例外單", CN: "该日期已有例外单"},
+	SCHED_ROOM_UNSUITABLE:  {EN: "Room does not meet course requirements", TW: "教室不符合課程需求", CN: "教室不符合课程需求"},
 
 	// 例外與審核類
 	EXCEPTION_NOT_FOUND:           {EN: "Exception request not found", TW: "例外申請不存在", CN: "例外申请不存在"},
@@ -65,11 +71,15 @@ var messagesMap = map[ErrCode]message{
 	TALENT_NOT_OPEN: {EN: "Talent search not available", TW: "該老師未開放搜尋", CN: "该老师未开放搜索"},
 
 	// LINE Bot 與通知類
-	LINE_ALREADY_BOUND:        {EN: "LINE account already bound", TW: "LINE 帳號已綁定", CN: "LINE 账号已绑定"},
-	LINE_NOT_BOUND:            {EN: "LINE account not bound", TW: "LINE 帳號未綁定", CN: "LINE 账号未绑定"},
-	LINE_BINDING_CODE_INVALID: {EN: "Invalid binding code", TW: "驗證碼無效", CN: "验证码无效"},
-	LINE_BINDING_EXPIRED:      {EN: "Binding code expired", TW: "驗證碼已過期，請重新產生", CN: "验证码已过期，请重新产生"},
-	LINE_NOTIFY_FAILED:        {EN: "Failed to send LINE notification", TW: "LINE 通知發送失敗", CN: "LINE 通知发送失败"},
+	LINE_ALREADY_BOUND:          {EN: "LINE account already bound", TW: "LINE 帳號已綁定", CN: "LINE 账号已绑定"},
+	LINE_NOT_BOUND:              {EN: "LINE account not bound", TW: "LINE 帳號未綁定", CN: "LINE 账号未绑定"},
+	LINE_BINDING_CODE_INVALID:   {EN: "Invalid binding code", TW: "驗證碼無效", CN: "验证码无效"},
+	LINE_BINDING_EXPIRED:        {EN: "Binding code expired", TW: "驗證碼已過期，請重新產生", CN: "验证码已过期，请重新产生"},
+	LINE_NOTIFY_FAILED:          {EN: "Failed to send LINE notification", TW: "LINE 通知發送失敗", CN: "LINE 通知发送失败"},
+	LINE_GROUP_NOT_FOUND:        {EN: "LINE group not found", TW: "LINE 群組不存在", CN: "LINE 群组不存在"},
+	LINE_GROUP_LINKED_ELSEWHERE: {EN: "LINE group is linked to another center", TW: "此 LINE 群組已連結其他中心", CN: "此 LINE 群组已连结其他中心"},
+	LINE_EVENT_NOT_FOUND:        {EN: "LINE webhook event not found", TW: "LINE 事件紀錄不存在", CN: "LINE 事件纪录不存在"},
+	LINE_EVENT_NOT_FAILED:       {EN: "Only failed LINE webhook events can be replayed", TW: "僅能重新處理失敗的 LINE 事件", CN: "仅能重新处理失败的 LINE 事件"},
 
 	// 管理員類
 	ADMIN_NOT_FOUND:           {EN: "Admin user not found", TW: "管理員不存在", CN: "管理员不存在"},
@@ -81,4 +91,23 @@ var messagesMap = map[ErrCode]message{
 	ERR_RESOURCE_LOCKED:     {EN: "Resource is locked by another operation", TW: "資源正在被其他操作修改，請稍後再試", CN: "资源正在被其他操作修改，请稍后再试"},
 	ERR_CONCURRENT_MODIFIED: {EN: "Resource was modified by another request", TW: "資源已被其他請求修改，請重新整理後再試", CN: "资源已被其他请求修改，请重新整理后再试"},
 	ERR_TX_FAILED:           {EN: "Transaction failed", TW: "交易執行失敗，請稍後再試", CN: "交易执行失败，请稍后再试"},
+
+	// 補課類
+	MAKEUP_SESSION_NOT_CANCELLED: {EN: "Original session is not cancelled", TW: "原場次未取消，無需補課", CN: "原场次未取消，无需补课"},
+	MAKEUP_ALREADY_SCHEDULED:     {EN: "Makeup already scheduled for this session", TW: "該場次已安排補課", CN: "该场次已安排补课"},
+
+	// 假日行事曆類
+	HOLIDAY_DATASET_YEAR_UNAVAILABLE: {EN: "Holiday dataset does not cover this year", TW: "內建假日資料尚未收錄該年度", CN: "内建假日资料尚未收录该年度"},
+
+	// 通知佇列類
+	NOTIFICATION_NOT_FOUND:          {EN: "Notification not found or already processed", TW: "通知不存在或已處理", CN: "通知不存在或已处理"},
+	NOTIFICATION_TEMPLATE_NOT_FOUND: {EN: "Notification template not found", TW: "通知範本不存在或尚未自訂", CN: "通知模板不存在或尚未自订"},
+
+	// Webhook 類
+	WEBHOOK_NOT_FOUND:           {EN: "Webhook not found", TW: "Webhook 不存在", CN: "Webhook 不存在"},
+	WEBHOOK_DELIVERY_NOT_FAILED: {EN: "Only failed deliveries can be redelivered", TW: "僅能重送失敗的投遞", CN: "仅能重送失败的投递"},
+
+	// 廣播類
+	BROADCAST_NOT_FOUND:       {EN: "Broadcast not found", TW: "廣播不存在", CN: "广播不存在"},
+	BROADCAST_NOT_CANCELLABLE: {EN: "Only scheduled broadcasts can be cancelled", TW: "僅能取消尚未發送的排程廣播", CN: "仅能取消尚未发送的排程广播"},
 }
@@ -3,8 +3,29 @@ package rabbitmq
 const (
 	N_NORMAL string = "Normal"
 	N_DELAY  string = "Delay"
+
+	// 領域事件消費端（各自獨立的 queue，綁定 E_DOMAIN_EVENT）
+	N_DOMAIN_EVENT_CACHE         string = "DomainEvent.Cache"
+	N_DOMAIN_EVENT_CACHE_RETRY   string = "DomainEvent.Cache.Retry"
+	N_DOMAIN_EVENT_NOTIFY        string = "DomainEvent.Notification"
+	N_DOMAIN_EVENT_NOTIFY_RETRY  string = "DomainEvent.Notification.Retry"
+	N_DOMAIN_EVENT_WEBHOOK       string = "DomainEvent.Webhook"
+	N_DOMAIN_EVENT_WEBHOOK_RETRY string = "DomainEvent.Webhook.Retry"
+	N_DOMAIN_EVENT_DEAD          string = "DomainEvent.Dead" // 超過重試次數的訊息
+)
+
+const (
+	// E_DOMAIN_EVENT 領域事件 fanout exchange
+	E_DOMAIN_EVENT string = "DomainEvent"
 )
 
 const (
 	T_DEMO string = "Demo"
 )
+
+const (
+	// H_RETRY_COUNT 消費失敗的重試次數
+	H_RETRY_COUNT string = "x-retry-count"
+	// H_LAST_ERROR 最後一次消費失敗的錯誤
+	H_LAST_ERROR string = "x-last-error"
+)
@@ -0,0 +1,311 @@
+package libs
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DateQueryMaxDays 日期區間上限，避免一次查詢過多天數
+const DateQueryMaxDays = 31
+
+// DateQuery 日期查詢結果，Start 與 End 皆為當日 00:00 且包含 End 當天
+type DateQuery struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Days 查詢涵蓋的天數
+func (q DateQuery) Days() int {
+	return int(q.End.Sub(q.Start).Hours()/24+0.5) + 1
+}
+
+// IsSingleDay 是否只查詢單日
+func (q DateQuery) IsSingleDay() bool {
+	return q.Days() == 1
+}
+
+var (
+	// 查詢課表時常見的贅字，去除後其餘部分需完整為日期描述
+	dateQueryFillerPattern        = regexp.MustCompile(`(我的|的|有沒有|有|幾堂課|幾堂|幾節課|多少堂課|多少堂|課表|課程|行程|上課|課|嗎|呢|查詢|查|\?|？|!|！|。|,|，)`)
+	dateQueryEnglishFillerPattern = regexp.MustCompile(`('s|’s)\b|\b(what's|whats|what|how many|classes|class|lessons|lesson|schedule|agenda|do i|have|my|on|for|is|are|the|any|in the)\b`)
+	dateQueryRangeSeparators      = []string{"到", "至", "~", "～", "－", "—", "to", "until", "-"}
+
+	datePatternFull       = regexp.MustCompile(`^(?:(\d{4})[/\-.年])?(\d{1,2})[/\-.月](\d{1,2})[日號号]?$`)
+	datePatternDayOnly    = regexp.MustCompile(`^(\d{1,2})[日號号]$`)
+	datePatternDaysAfter  = regexp.MustCompile(`^(\d{1,3})天(?:後|后|之後)$`)
+	datePatternInDays     = regexp.MustCompile(`^in(\d{1,3})days?$`)
+	datePatternWeek       = regexp.MustCompile(`^(這|这|本|下下|下|上)?個?(?:週|周|禮拜|礼拜|星期)([一二三四五六日天1-7])?$`)
+	datePatternMonth      = regexp.MustCompile(`^(這|这|本|下|上)個?月$`)
+	datePatternMonthNum   = regexp.MustCompile(`^(\d{1,2})月(?:份)?$`)
+	datePatternEnWeekday  = regexp.MustCompile(`^(this|next|last)?(mon|tue|tues|wed|thu|thur|thurs|fri|sat|sun)(?:day|nesday|rsday|urday|sday)?$`)
+	datePatternEnMonthDay = regexp.MustCompile(`^([a-z]{3,9})(\d{1,2})(?:st|nd|rd|th)?$`)
+	datePatternEnDayMonth = regexp.MustCompile(`^(\d{1,2})(?:st|nd|rd|th)?(?:of)?([a-z]{3,9})$`)
+)
+
+var dateQueryFixedDays = map[string]int{
+	"今天": 0, "今日": 0, "today": 0,
+	"明天": 1, "明日": 1, "tomorrow": 1,
+	"後天": 2, "后天": 2, "thedayaftertomorrow": 2, "dayaftertomorrow": 2,
+	"大後天": 3, "大后天": 3,
+	"昨天": -1, "昨日": -1, "yesterday": -1,
+	"前天": -2,
+}
+
+var dateQueryFixedWeeks = map[string]int{
+	"thisweek": 0, "nextweek": 1, "lastweek": -1, "week": 0,
+}
+
+var dateQueryFixedMonths = map[string]int{
+	"thismonth": 0, "nextmonth": 1, "lastmonth": -1, "month": 0,
+}
+
+var chineseWeekdays = map[string]time.Weekday{
+	"一": time.Monday, "二": time.Tuesday, "三": time.Wednesday, "四": time.Thursday,
+	"五": time.Friday, "六": time.Saturday, "日": time.Sunday, "天": time.Sunday,
+	"1": time.Monday, "2": time.Tuesday, "3": time.Wednesday, "4": time.Thursday,
+	"5": time.Friday, "6": time.Saturday, "7": time.Sunday,
+}
+
+var englishWeekdays = map[string]time.Weekday{
+	"mon": time.Monday, "tue": time.Tuesday, "tues": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday, "fri": time.Friday,
+	"sat": time.Saturday, "sun": time.Sunday,
+}
+
+var englishMonths = map[string]time.Month{
+	"jan": time.January, "feb": time.February, "mar": time.March, "apr": time.April,
+	"may": time.May, "jun": time.June, "jul": time.July, "aug": time.August,
+	"sep": time.September, "sept": time.September, "oct": time.October, "nov": time.November, "dec": time.December,
+}
+
+// ParseDateQuery 解析中英文日期描述（今天、下週三、這週、3/15、3/15-3/20、下個月、next friday、march 15 …）
+// now 決定相對日期的基準與時區，週一為一週的第一天；無法完整解析時回傳 false
+func ParseDateQuery(text string, now time.Time) (DateQuery, bool) {
+	phrase := normalizeDateQuery(text)
+	if phrase == "" {
+		return DateQuery{}, false
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	if start, end, ok := parseDateExpression(phrase, today); ok {
+		return DateQuery{Start: start, End: end}, true
+	}
+
+	// 區間：兩端各自解析，取前者起日與後者迄日
+	for _, sep := range dateQueryRangeSeparators {
+		for offset := 0; ; {
+			idx := strings.Index(phrase[offset:], sep)
+			if idx < 0 {
+				break
+			}
+			idx += offset
+			offset = idx + len(sep)
+			if idx == 0 || offset >= len(phrase) {
+				continue
+			}
+
+			from, _, ok := parseDateExpression(phrase[:idx], today)
+			if !ok {
+				continue
+			}
+			rest := phrase[offset:]
+			// 「3/15-20」視為同月
+			if day, err := strconv.Atoi(rest); err == nil {
+				rest = strconv.Itoa(int(from.Month())) + "/" + strconv.Itoa(day)
+			}
+			_, to, ok := parseDateExpression(rest, today)
+			if !ok || to.Before(from) {
+				continue
+			}
+
+			query := DateQuery{Start: from, End: to}
+			if query.Days() > DateQueryMaxDays {
+				return DateQuery{}, false
+			}
+			return query, true
+		}
+	}
+
+	return DateQuery{}, false
+}
+
+// normalizeDateQuery 轉為小寫半形、去除贅字與空白
+func normalizeDateQuery(text string) string {
+	text = strings.ToLower(strings.TrimSpace(text))
+	text = strings.Map(func(r rune) rune {
+		switch {
+		case r >= '０' && r <= '９':
+			return '0' + (r - '０')
+		case r == '／':
+			return '/'
+		case r == '　':
+			return ' '
+		}
+		return r
+	}, text)
+	text = dateQueryFillerPattern.ReplaceAllString(text, "")
+	text = dateQueryEnglishFillerPattern.ReplaceAllString(text, "")
+	return strings.Join(strings.Fields(text), "")
+}
+
+// parseDateExpression 解析單一日期描述，回傳起訖日；base 為相對日期與推算年份的基準日
+func parseDateExpression(phrase string, base time.Time) (time.Time, time.Time, bool) {
+	if offset, ok := dateQueryFixedDays[phrase]; ok {
+		day := base.AddDate(0, 0, offset)
+		return day, day, true
+	}
+	if offset, ok := dateQueryFixedWeeks[phrase]; ok {
+		start := weekStart(base).AddDate(0, 0, 7*offset)
+		return start, start.AddDate(0, 0, 6), true
+	}
+	if offset, ok := dateQueryFixedMonths[phrase]; ok {
+		return monthRange(base.Year(), base.Month()+time.Month(offset), base.Location())
+	}
+
+	if m := datePatternDaysAfter.FindStringSubmatch(phrase); m != nil {
+		return daysAfter(base, m[1])
+	}
+	if m := datePatternInDays.FindStringSubmatch(phrase); m != nil {
+		return daysAfter(base, m[1])
+	}
+
+	if m := datePatternWeek.FindStringSubmatch(phrase); m != nil {
+		prefix, weekdayText := m[1], m[2]
+		if prefix == "" && weekdayText == "" {
+			return time.Time{}, time.Time{}, false
+		}
+		weekOffset := map[string]int{"": 0, "這": 0, "这": 0, "本": 0, "下": 1, "下下": 2, "上": -1}[prefix]
+		if weekdayText == "" {
+			start := weekStart(base).AddDate(0, 0, 7*weekOffset)
+			return start, start.AddDate(0, 0, 6), true
+		}
+		day := weekdayDate(base, chineseWeekdays[weekdayText], prefix != "", weekOffset)
+		return day, day, true
+	}
+
+	if m := datePatternEnWeekday.FindStringSubmatch(phrase); m != nil {
+		weekday, ok := englishWeekdays[m[2]]
+		if !ok {
+			return time.Time{}, time.Time{}, false
+		}
+		weekOffset := map[string]int{"": 0, "this": 0, "next": 1, "last": -1}[m[1]]
+		day := weekdayDate(base, weekday, m[1] != "", weekOffset)
+		return day, day, true
+	}
+
+	if m := datePatternMonth.FindStringSubmatch(phrase); m != nil {
+		offset := map[string]int{"這": 0, "这": 0, "本": 0, "下": 1, "上": -1}[m[1]]
+		return monthRange(base.Year(), base.Month()+time.Month(offset), base.Location())
+	}
+	if m := datePatternMonthNum.FindStringSubmatch(phrase); m != nil {
+		month, _ := strconv.Atoi(m[1])
+		if month < 1 || month > 12 {
+			return time.Time{}, time.Time{}, false
+		}
+		year := nearestYear(base, time.Month(month), 1)
+		return monthRange(year, time.Month(month), base.Location())
+	}
+
+	if m := datePatternFull.FindStringSubmatch(phrase); m != nil {
+		month, _ := strconv.Atoi(m[2])
+		day, _ := strconv.Atoi(m[3])
+		year := 0
+		if m[1] != "" {
+			year, _ = strconv.Atoi(m[1])
+		}
+		return exactDate(base, year, month, day)
+	}
+	if m := datePatternDayOnly.FindStringSubmatch(phrase); m != nil {
+		day, _ := strconv.Atoi(m[1])
+		return exactDate(base, base.Year(), int(base.Month()), day)
+	}
+
+	if m := datePatternEnMonthDay.FindStringSubmatch(phrase); m != nil {
+		if month, ok := parseEnglishMonth(m[1]); ok {
+			day, _ := strconv.Atoi(m[2])
+			return exactDate(base, 0, int(month), day)
+		}
+	}
+	if m := datePatternEnDayMonth.FindStringSubmatch(phrase); m != nil {
+		if month, ok := parseEnglishMonth(m[2]); ok {
+			day, _ := strconv.Atoi(m[1])
+			return exactDate(base, 0, int(month), day)
+		}
+	}
+
+	return time.Time{}, time.Time{}, false
+}
+
+// weekStart 取得該週週一
+func weekStart(day time.Time) time.Time {
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+// weekdayDate 有指定週別（這/下/上）時取該週的星期；未指定時取今天起最近的一天
+func weekdayDate(base time.Time, weekday time.Weekday, explicitWeek bool, weekOffset int) time.Time {
+	if explicitWeek {
+		return weekStart(base).AddDate(0, 0, 7*weekOffset+(int(weekday)+6)%7)
+	}
+	diff := (int(weekday) - int(base.Weekday()) + 7) % 7
+	return base.AddDate(0, 0, diff)
+}
+
+func monthRange(year int, month time.Month, loc *time.Location) (time.Time, time.Time, bool) {
+	start := time.Date(year, month, 1, 0, 0, 0, 0, loc)
+	return start, start.AddDate(0, 1, -1), true
+}
+
+func daysAfter(base time.Time, value string) (time.Time, time.Time, bool) {
+	days, err := strconv.Atoi(value)
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	day := base.AddDate(0, 0, days)
+	return day, day, true
+}
+
+// exactDate 建立指定日期，未指定年份時取最接近基準日的年份；日期不存在時回傳 false
+func exactDate(base time.Time, year, month, day int) (time.Time, time.Time, bool) {
+	if month < 1 || month > 12 || day < 1 || day > 31 {
+		return time.Time{}, time.Time{}, false
+	}
+	if year == 0 {
+		year = nearestYear(base, time.Month(month), day)
+	}
+	date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, base.Location())
+	if date.Month() != time.Month(month) || date.Day() != day {
+		return time.Time{}, time.Time{}, false
+	}
+	return date, date, true
+}
+
+// nearestYear 未寫年份時，取與基準日最接近的年份（年底問 1/5 視為明年）
+func nearestYear(base time.Time, month time.Month, day int) int {
+	best := base.Year()
+	bestDiff := time.Duration(-1)
+	for _, year := range []int{base.Year() - 1, base.Year(), base.Year() + 1} {
+		diff := time.Date(year, month, day, 0, 0, 0, 0, base.Location()).Sub(base)
+		if diff < 0 {
+			diff = -diff
+		}
+		if bestDiff < 0 || diff < bestDiff {
+			best, bestDiff = year, diff
+		}
+	}
+	return best
+}
+
+func parseEnglishMonth(name string) (time.Month, bool) {
+	if len(name) < 3 {
+		return 0, false
+	}
+	month, ok := englishMonths[name[:3]]
+	if name == "sept" {
+		month, ok = time.September, true
+	}
+	return month, ok
+}
@@ -0,0 +1,32 @@
+package libs
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// 範本變數以 {{name}} 表示，名稱限英數與底線；不支援條件、迴圈或函式呼叫
+var templateVariablePattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// TemplateVariables 取得範本使用的變數名稱，括號不成對或名稱不合法時回傳錯誤
+func TemplateVariables(tpl string) ([]string, error) {
+	var names []string
+	rest := templateVariablePattern.ReplaceAllStringFunc(tpl, func(match string) string {
+		name := templateVariablePattern.FindStringSubmatch(match)[1]
+		names = append(names, name)
+		return ""
+	})
+	if strings.Contains(rest, "{{") || strings.Contains(rest, "}}") {
+		return nil, fmt.Errorf("範本變數格式錯誤，請使用 {{變數名稱}}")
+	}
+	return names, nil
+}
+
+// Interpolate 將範本中的 {{name}} 替換為對應的值，未提供的變數替換為空字串
+// 替換結果不會再被解析，值中的 {{ }} 會原樣保留
+func Interpolate(tpl string, vars map[string]string) string {
+	return templateVariablePattern.ReplaceAllStringFunc(tpl, func(match string) string {
+		return vars[templateVariablePattern.FindStringSubmatch(match)[1]]
+	})
+}
@@ -0,0 +1,239 @@
+package libs
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"timeLedger/configs"
+)
+
+// SMTP 連線加密方式
+const (
+	SMTPTLSNone     = "none"     // 明文（本機 SMTP catcher 使用）
+	SMTPTLSStartTLS = "starttls" // 587 port
+	SMTPTLSImplicit = "tls"      // 465 port
+)
+
+// MailMessage 郵件內容（HTML 與純文字兩種版本）
+type MailMessage struct {
+	To       string
+	ToName   string
+	Subject  string
+	HTMLBody string
+	TextBody string
+	Headers  map[string]string // 額外 header，例如 X-Notification-ID
+}
+
+// MailError SMTP 發送錯誤，Permanent 為 5xx（收件者不存在、被拒收等退信）
+type MailError struct {
+	Code      int
+	Permanent bool
+	Err       error
+}
+
+func (e *MailError) Error() string {
+	if e.Code > 0 {
+		return fmt.Sprintf("smtp %d: %v", e.Code, e.Err)
+	}
+	return e.Err.Error()
+}
+
+func (e *MailError) Unwrap() error {
+	return e.Err
+}
+
+// IsPermanentMailError 是否為永久性失敗（退信），不應重試
+func IsPermanentMailError(err error) bool {
+	var mailErr *MailError
+	return errors.As(err, &mailErr) && mailErr.Permanent
+}
+
+// SMTPMailer SMTP 郵件發送
+type SMTPMailer struct {
+	host     string
+	port     string
+	username string
+	password string
+	from     mail.Address
+	tlsMode  string
+	timeout  time.Duration
+}
+
+// NewSMTPMailer 建立 SMTP 郵件發送；未啟用時回傳 nil
+func NewSMTPMailer(env *configs.Env) (*SMTPMailer, error) {
+	if env == nil || !env.SmtpEnabled {
+		return nil, nil
+	}
+	if env.SmtpHost == "" || env.SmtpPort == "" || env.SmtpFromAddress == "" {
+		return nil, fmt.Errorf("SMTP configuration is incomplete")
+	}
+
+	tlsMode := strings.ToLower(env.SmtpTLSMode)
+	switch tlsMode {
+	case "":
+		tlsMode = SMTPTLSStartTLS
+	case SMTPTLSNone, SMTPTLSStartTLS, SMTPTLSImplicit:
+	default:
+		return nil, fmt.Errorf("unsupported SMTP TLS mode: %s", env.SmtpTLSMode)
+	}
+
+	return &SMTPMailer{
+		host:     env.SmtpHost,
+		port:     env.SmtpPort,
+		username: env.SmtpUsername,
+		password: env.SmtpPassword,
+		from:     mail.Address{Name: env.SmtpFromName, Address: env.SmtpFromAddress},
+		tlsMode:  tlsMode,
+		timeout:  30 * time.Second,
+	}, nil
+}
+
+// Send 發送郵件
+func (m *SMTPMailer) Send(ctx context.Context, msg MailMessage) error {
+	if m == nil {
+		return fmt.Errorf("SMTP mailer is not initialized")
+	}
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return &MailError{Permanent: true, Err: fmt.Errorf("invalid recipient address: %w", err)}
+	}
+
+	body, err := buildMIMEMessage(m.from, mail.Address{Name: msg.ToName, Address: to.Address}, msg)
+	if err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(m.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+
+	addr := net.JoinHostPort(m.host, m.port)
+	dialer := &net.Dialer{Deadline: deadline}
+	var conn net.Conn
+	if m.tlsMode == SMTPTLSImplicit {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, &tls.Config{ServerName: m.host})
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return &MailError{Err: fmt.Errorf("failed to connect SMTP server: %w", err)}
+	}
+	_ = conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, m.host)
+	if err != nil {
+		conn.Close()
+		return classifySMTPError(err)
+	}
+	defer client.Close()
+
+	if m.tlsMode == SMTPTLSStartTLS {
+		if err := client.StartTLS(&tls.Config{ServerName: m.host}); err != nil {
+			return classifySMTPError(err)
+		}
+	}
+	if m.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", m.username, m.password, m.host)); err != nil {
+			return classifySMTPError(err)
+		}
+	}
+
+	if err := client.Mail(m.from.Address); err != nil {
+		return classifySMTPError(err)
+	}
+	if err := client.Rcpt(to.Address); err != nil {
+		return classifySMTPError(err)
+	}
+	w, err := client.Data()
+	if err != nil {
+		return classifySMTPError(err)
+	}
+	if _, err := w.Write(body); err != nil {
+		return classifySMTPError(err)
+	}
+	if err := w.Close(); err != nil {
+		return classifySMTPError(err)
+	}
+	return client.Quit()
+}
+
+// classifySMTPError 依 SMTP 回應碼區分退信（5xx）與暫時性錯誤
+func classifySMTPError(err error) error {
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) {
+		return &MailError{Code: protoErr.Code, Permanent: protoErr.Code >= 500, Err: err}
+	}
+	return &MailError{Err: err}
+}
+
+// buildMIMEMessage 組合 multipart/alternative 郵件
+func buildMIMEMessage(from, to mail.Address, msg MailMessage) ([]byte, error) {
+	boundary, err := mimeBoundary()
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	headers := []string{
+		"From: " + from.String(),
+		"To: " + to.String(),
+		"Subject: " + mime.QEncoding.Encode("utf-8", msg.Subject),
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		fmt.Sprintf("Content-Type: multipart/alternative; boundary=%q", boundary),
+	}
+	for name, value := range msg.Headers {
+		headers = append(headers, textproto.CanonicalMIMEHeaderKey(name)+": "+value)
+	}
+	buf.WriteString(strings.Join(headers, "\r\n"))
+	buf.WriteString("\r\n\r\n")
+
+	parts := []struct {
+		contentType string
+		body        string
+	}{
+		{"text/plain; charset=utf-8", msg.TextBody},
+		{"text/html; charset=utf-8", msg.HTMLBody},
+	}
+	for _, part := range parts {
+		if part.body == "" {
+			continue
+		}
+		fmt.Fprintf(&buf, "--%s\r\n", boundary)
+		fmt.Fprintf(&buf, "Content-Type: %s\r\n", part.contentType)
+		buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+		qp := quotedprintable.NewWriter(&buf)
+		if _, err := qp.Write([]byte(part.body)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+		buf.WriteString("\r\n")
+	}
+	fmt.Fprintf(&buf, "--%s--\r\n", boundary)
+
+	return buf.Bytes(), nil
+}
+
+func mimeBoundary() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "timeledger-" + hex.EncodeToString(b), nil
+}
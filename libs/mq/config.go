@@ -17,8 +17,15 @@ type QueueConfig struct {
 	Qos   Qos
 	Args  amqp091.Table
 	Start bool
+
+	Exchange   string // 綁定的 fanout exchange
+	ManualAck  bool   // 處理完成才 Ack，失敗轉送 RetryQueue
+	RetryQueue string // 失敗重試用的延遲 queue
 }
 
+// DomainEventMaxRetries 領域事件消費失敗的重試次數，超過即轉入 N_DOMAIN_EVENT_DEAD
+const DomainEventMaxRetries = 5
+
 var QueuesConfig = []QueueConfig{
 	{
 		Name:  rabbitmq.N_NORMAL,
@@ -36,4 +43,81 @@ var QueuesConfig = []QueueConfig{
 		},
 		Start: false, // 不啟動此 Consumer
 	},
+	{
+		Name:       rabbitmq.N_DOMAIN_EVENT_CACHE,
+		Qos:        Qos{PrefetchCount: 10, PrefetchSize: 0, Global: false},
+		Args:       nil,
+		Start:      true,
+		Exchange:   rabbitmq.E_DOMAIN_EVENT,
+		ManualAck:  true,
+		RetryQueue: rabbitmq.N_DOMAIN_EVENT_CACHE_RETRY,
+	},
+	{
+		Name: rabbitmq.N_DOMAIN_EVENT_CACHE_RETRY,
+		Qos:  Qos{PrefetchCount: 1, PrefetchSize: 0, Global: false},
+		Args: amqp091.Table{
+			"x-dead-letter-exchange":    "",
+			"x-dead-letter-routing-key": rabbitmq.N_DOMAIN_EVENT_CACHE,
+			"x-message-ttl":             int32(10 * 1000), // 延遲10秒後重試
+		},
+		Start: false,
+	},
+	{
+		Name:       rabbitmq.N_DOMAIN_EVENT_NOTIFY,
+		Qos:        Qos{PrefetchCount: 5, PrefetchSize: 0, Global: false},
+		Args:       nil,
+		Start:      true,
+		Exchange:   rabbitmq.E_DOMAIN_EVENT,
+		ManualAck:  true,
+		RetryQueue: rabbitmq.N_DOMAIN_EVENT_NOTIFY_RETRY,
+	},
+	{
+		Name: rabbitmq.N_DOMAIN_EVENT_NOTIFY_RETRY,
+		Qos:  Qos{PrefetchCount: 1, PrefetchSize: 0, Global: false},
+		Args: amqp091.Table{
+			"x-dead-letter-exchange":    "",
+			"x-dead-letter-routing-key": rabbitmq.N_DOMAIN_EVENT_NOTIFY,
+			"x-message-ttl":             int32(30 * 1000), // 延遲30秒後重試
+		},
+		Start: false,
+	},
+	{
+		Name:       rabbitmq.N_DOMAIN_EVENT_WEBHOOK,
+		Qos:        Qos{PrefetchCount: 10, PrefetchSize: 0, Global: false},
+		Args:       nil,
+		Start:      true,
+		Exchange:   rabbitmq.E_DOMAIN_EVENT,
+		ManualAck:  true,
+		RetryQueue: rabbitmq.N_DOMAIN_EVENT_WEBHOOK_RETRY,
+	},
+	{
+		Name: rabbitmq.N_DOMAIN_EVENT_WEBHOOK_RETRY,
+		Qos:  Qos{PrefetchCount: 1, PrefetchSize: 0, Global: false},
+		Args: amqp091.Table{
+			"x-dead-letter-exchange":    "",
+			"x-dead-letter-routing-key": rabbitmq.N_DOMAIN_EVENT_WEBHOOK,
+			"x-message-ttl":             int32(10 * 1000), // 延遲10秒後重試
+		},
+		Start: false,
+	},
+	{
+		Name:  rabbitmq.N_DOMAIN_EVENT_DEAD,
+		Qos:   Qos{PrefetchCount: 1, PrefetchSize: 0, Global: false},
+		Args:  nil,
+		Start: false, // 人工檢查後再重送
+	},
+}
+
+// ExchangesConfig 需宣告的 exchange（名稱 -> 類型）
+var ExchangesConfig = map[string]string{
+	rabbitmq.E_DOMAIN_EVENT: "fanout",
+}
+
+func queueConfig(name string) (QueueConfig, bool) {
+	for _, cfg := range QueuesConfig {
+		if cfg.Name == name {
+			return cfg, true
+		}
+	}
+	return QueueConfig{}, false
 }
@@ -3,6 +3,7 @@ package mq
 import (
 	"fmt"
 	"timeLedger/app"
+	"timeLedger/app/services"
 	rabbitmq "timeLedger/global/rabbitMQ"
 
 	"github.com/rabbitmq/amqp091-go"
@@ -20,6 +21,11 @@ func NewConsumer(r *RabbitMQ, app *app.App, queues []string) *Consumer {
 		handler: &Handler{
 			app: app,
 			r:   r,
+			domainHandlers: map[string]services.DomainEventHandler{
+				rabbitmq.N_DOMAIN_EVENT_CACHE:   services.NewDomainEventCacheHandler(app),
+				rabbitmq.N_DOMAIN_EVENT_NOTIFY:  services.NewDomainEventNotificationHandler(app),
+				rabbitmq.N_DOMAIN_EVENT_WEBHOOK: services.NewDomainEventWebhookHandler(app),
+			},
 		},
 	}
 
@@ -38,7 +44,8 @@ func (c *Consumer) consume(queue string) error {
 		return fmt.Errorf("RabbitMQ consumer consume get channel error, Queue: %s, Err: %v", queue, err)
 	}
 
-	msgs, err := ch.Consume(queue, "", true, false, false, false, nil)
+	cfg, _ := queueConfig(queue)
+	msgs, err := ch.Consume(queue, "", !cfg.ManualAck, false, false, false, nil)
 	if err != nil {
 		return fmt.Errorf("RabbitMQ consumer consume error, Queue: %s, Err: %v", queue, err)
 	}
@@ -74,6 +81,12 @@ func (c *Consumer) consume(queue string) error {
 					default:
 						err = fmt.Errorf("RabbitMQ unknown message type [%s]", msg.Type)
 					}
+				case rabbitmq.N_DOMAIN_EVENT_CACHE, rabbitmq.N_DOMAIN_EVENT_NOTIFY, rabbitmq.N_DOMAIN_EVENT_WEBHOOK:
+					err = c.handler.handleDomainEvent(queue, m)
+				}
+
+				if cfg.ManualAck {
+					c.settle(cfg, m, err)
 				}
 			}(msg)
 		}
@@ -81,3 +94,53 @@ func (c *Consumer) consume(queue string) error {
 
 	return nil
 }
+
+// settle 手動 Ack：失敗時轉入重試 queue，超過重試次數轉入 dead letter queue
+func (c *Consumer) settle(cfg QueueConfig, m amqp091.Delivery, handleErr error) {
+	if handleErr != nil {
+		if err := c.retryOrDeadLetter(cfg, m, handleErr); err != nil {
+			// 無法轉送時放回原 queue，避免遺失
+			_ = m.Nack(false, true)
+			return
+		}
+	}
+	_ = m.Ack(false)
+}
+
+func (c *Consumer) retryOrDeadLetter(cfg QueueConfig, m amqp091.Delivery, handleErr error) error {
+	headers := amqp091.Table{}
+	for k, v := range m.Headers {
+		headers[k] = v
+	}
+	retries := retryCount(m.Headers) + 1
+	headers[rabbitmq.H_RETRY_COUNT] = int32(retries)
+	headers[rabbitmq.H_LAST_ERROR] = handleErr.Error()
+
+	target := cfg.RetryQueue
+	if target == "" || retries > DomainEventMaxRetries {
+		headers["x-origin-queue"] = cfg.Name
+		target = rabbitmq.N_DOMAIN_EVENT_DEAD
+	}
+
+	return c.r.Producer.Publish(target, amqp091.Publishing{
+		Headers:      headers,
+		ContentType:  m.ContentType,
+		DeliveryMode: amqp091.Persistent,
+		MessageId:    m.MessageId,
+		Timestamp:    m.Timestamp,
+		Type:         m.Type,
+		Body:         m.Body,
+	})
+}
+
+func retryCount(headers amqp091.Table) int {
+	switch v := headers[rabbitmq.H_RETRY_COUNT].(type) {
+	case int32:
+		return int(v)
+	case int64:
+		return int(v)
+	case int:
+		return v
+	}
+	return 0
+}
@@ -0,0 +1,34 @@
+package mq
+
+import (
+	"context"
+	"encoding/json"
+	"timeLedger/app/services"
+	rabbitmq "timeLedger/global/rabbitMQ"
+
+	"github.com/rabbitmq/amqp091-go"
+)
+
+// DomainEventPublisher 將 outbox 事件發佈至 E_DOMAIN_EVENT
+type DomainEventPublisher struct {
+	r *RabbitMQ
+}
+
+func NewDomainEventPublisher(r *RabbitMQ) *DomainEventPublisher {
+	return &DomainEventPublisher{r: r}
+}
+
+func (p *DomainEventPublisher) Publish(ctx context.Context, msg services.DomainEventMessage) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	return p.r.Producer.PublishExchange(rabbitmq.E_DOMAIN_EVENT, amqp091.Publishing{
+		DeliveryMode: amqp091.Persistent,
+		MessageId:    msg.EventID,
+		Timestamp:    msg.OccurredAt,
+		Type:         msg.EventType,
+		Body:         body,
+	})
+}
@@ -1,9 +1,11 @@
 package mq
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"timeLedger/app"
+	"timeLedger/app/services"
 	rabbitmq "timeLedger/global/rabbitMQ"
 
 	"github.com/rabbitmq/amqp091-go"
@@ -12,6 +14,9 @@ import (
 type Handler struct {
 	app *app.App
 	r   *RabbitMQ
+
+	// 領域事件消費端（queue 名稱 -> handler）
+	domainHandlers map[string]services.DomainEventHandler
 }
 
 func (h *Handler) handleNormal(msg amqp091.Delivery) error {
@@ -23,3 +28,17 @@ func (h *Handler) handleNormal(msg amqp091.Delivery) error {
 
 	return nil
 }
+
+func (h *Handler) handleDomainEvent(queue string, msg amqp091.Delivery) error {
+	handler, ok := h.domainHandlers[queue]
+	if !ok {
+		return fmt.Errorf("RabbitMQ no domain event handler for queue [%s]", queue)
+	}
+
+	var event services.DomainEventMessage
+	if err := json.Unmarshal(msg.Body, &event); err != nil {
+		return err
+	}
+
+	return handler.Handle(context.Background(), event)
+}
@@ -32,3 +32,23 @@ func (p *Producer) Publish(queue string, payload amqp091.Publishing) error {
 		payload,
 	)
 }
+
+// PublishExchange 發佈至 exchange（fanout 時所有綁定的 queue 都會收到）
+func (p *Producer) PublishExchange(exchange string, payload amqp091.Publishing) error {
+	ch, err := p.r.getChannel(exchange)
+	if err != nil {
+		return fmt.Errorf("RabbitMQ producer publish get channel error, Exchange: %s, Err: %v", exchange, err)
+	}
+
+	if payload.ContentType == "" {
+		payload.ContentType = "application/json"
+	}
+
+	return ch.Publish(
+		exchange, // exchange
+		"",       // routing key
+		false,
+		false,
+		payload,
+	)
+}
@@ -50,6 +50,20 @@ func Initialize(app *app.App) *RabbitMQ {
 		wg:     sync.WaitGroup{},
 	}
 
+	// 宣告 exchange，producer 以 exchange 名稱取得 channel
+	for name, kind := range ExchangesConfig {
+		ch, err := conn.Channel()
+		if err != nil {
+			panic(fmt.Errorf("RabbitMQ create channel for exchange %s failed: %w", name, err))
+		}
+
+		if err := ch.ExchangeDeclare(name, kind, true, false, false, false, nil); err != nil {
+			panic(fmt.Errorf("RabbitMQ exchange declare error for %s: %w", name, err))
+		}
+
+		r.chPool[name] = ch
+	}
+
 	// 要監聽的 Queue
 	queues := []string{}
 
@@ -69,6 +83,12 @@ func Initialize(app *app.App) *RabbitMQ {
 			panic(fmt.Errorf("RabbitMQ queue declare error for %s: %w", cfg.Name, err))
 		}
 
+		if cfg.Exchange != "" {
+			if err := ch.QueueBind(cfg.Name, "", cfg.Exchange, false, nil); err != nil {
+				panic(fmt.Errorf("RabbitMQ queue bind error for %s: %w", cfg.Name, err))
+			}
+		}
+
 		r.chPool[cfg.Name] = ch
 
 		if cfg.Start {
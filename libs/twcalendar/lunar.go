@@ -0,0 +1,62 @@
+package twcalendar
+
+import (
+	"fmt"
+	"time"
+)
+
+// 農曆節日代碼
+const (
+	LunarNewYear = "LUNAR_NEW_YEAR" // 春節（正月初一）
+	DragonBoat   = "DRAGON_BOAT"    // 端午節（五月初五）
+	MidAutumn    = "MID_AUTUMN"     // 中秋節（八月十五）
+)
+
+// lunarFestivals 農曆節日對應的國曆日期（離線計算後寫入，格式 MM-DD）
+// 除夕等相鄰日期以偏移天數表示，例如除夕 = 春節 -1
+var lunarFestivals = map[int]map[string]string{
+	2024: {LunarNewYear: "02-10", DragonBoat: "06-10", MidAutumn: "09-17"},
+	2025: {LunarNewYear: "01-29", DragonBoat: "05-31", MidAutumn: "10-06"},
+	2026: {LunarNewYear: "02-17", DragonBoat: "06-19", MidAutumn: "09-25"},
+	2027: {LunarNewYear: "02-06", DragonBoat: "06-09", MidAutumn: "09-15"},
+	2028: {LunarNewYear: "01-26", DragonBoat: "05-28", MidAutumn: "10-03"},
+	2029: {LunarNewYear: "02-13", DragonBoat: "06-16", MidAutumn: "09-22"},
+	2030: {LunarNewYear: "02-03", DragonBoat: "06-05", MidAutumn: "09-12"},
+	2031: {LunarNewYear: "01-23", DragonBoat: "06-24", MidAutumn: "10-01"},
+	2032: {LunarNewYear: "02-11", DragonBoat: "06-12", MidAutumn: "09-19"},
+	2033: {LunarNewYear: "01-31", DragonBoat: "06-01", MidAutumn: "09-08"},
+	2034: {LunarNewYear: "02-19", DragonBoat: "06-20", MidAutumn: "09-27"},
+	2035: {LunarNewYear: "02-08", DragonBoat: "06-10", MidAutumn: "09-16"},
+}
+
+// IsLunarFestival 檢查是否為支援的農曆節日代碼
+func IsLunarFestival(festival string) bool {
+	switch festival {
+	case LunarNewYear, DragonBoat, MidAutumn:
+		return true
+	}
+	return false
+}
+
+// LunarFestivalDate 取得農曆節日在指定國曆年度的日期（UTC 日期），未收錄的年度回傳 false
+func LunarFestivalDate(festival string, year int) (time.Time, bool) {
+	dates, ok := lunarFestivals[year]
+	if !ok {
+		return time.Time{}, false
+	}
+	md, ok := dates[festival]
+	if !ok {
+		return time.Time{}, false
+	}
+	date, err := time.Parse("2006-01-02", fmt.Sprintf("%04d-%s", year, md))
+	if err != nil {
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+// HasLunarYear 檢查農曆節日表是否收錄指定年度
+func HasLunarYear(year int) bool {
+	_, ok := lunarFestivals[year]
+	return ok
+}
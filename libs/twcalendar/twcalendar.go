@@ -0,0 +1,131 @@
+package twcalendar
+
+import "sort"
+
+// DatasetVersion 內建資料集版本，依行政院人事行政總處公告的「政府行政機關辦公日曆表」整理
+// 新增年度或公告異動時需同步調整版本號
+const DatasetVersion = "2026.1"
+
+// Holiday 國定假日（含補假、調整放假）
+type Holiday struct {
+	Date string `json:"date"`
+	Name string `json:"name"`
+}
+
+// MakeupWorkday 補行上班日
+// FollowsWeekday 為當天比照的星期課表（1=週一 … 7=週日），通常對應被調整放假的那一天
+type MakeupWorkday struct {
+	Date           string `json:"date"`
+	Name           string `json:"name"`
+	FollowsWeekday int    `json:"follows_weekday"`
+}
+
+// YearCalendar 單一年度的假日與補班日
+type YearCalendar struct {
+	Year           int             `json:"year"`
+	Version        string          `json:"version"`
+	Holidays       []Holiday       `json:"holidays"`
+	MakeupWorkdays []MakeupWorkday `json:"makeup_workdays"`
+}
+
+var calendars = map[int]YearCalendar{
+	2024: {
+		Year: 2024,
+		Holidays: []Holiday{
+			{Date: "2024-01-01", Name: "開國紀念日"},
+			{Date: "2024-02-08", Name: "春節調整放假"},
+			{Date: "2024-02-09", Name: "農曆除夕"},
+			{Date: "2024-02-10", Name: "春節"},
+			{Date: "2024-02-11", Name: "春節"},
+			{Date: "2024-02-12", Name: "春節"},
+			{Date: "2024-02-13", Name: "春節補假"},
+			{Date: "2024-02-14", Name: "春節補假"},
+			{Date: "2024-02-28", Name: "和平紀念日"},
+			{Date: "2024-04-04", Name: "兒童節及民族掃墓節"},
+			{Date: "2024-04-05", Name: "兒童節補假"},
+			{Date: "2024-06-10", Name: "端午節"},
+			{Date: "2024-09-17", Name: "中秋節"},
+			{Date: "2024-10-10", Name: "國慶日"},
+		},
+		MakeupWorkdays: []MakeupWorkday{
+			{Date: "2024-02-17", Name: "補行上班（2/8 調整放假）", FollowsWeekday: 4},
+		},
+	},
+	2025: {
+		Year: 2025,
+		Holidays: []Holiday{
+			{Date: "2025-01-01", Name: "開國紀念日"},
+			{Date: "2025-01-27", Name: "農曆除夕前一日"},
+			{Date: "2025-01-28", Name: "農曆除夕"},
+			{Date: "2025-01-29", Name: "春節"},
+			{Date: "2025-01-30", Name: "春節"},
+			{Date: "2025-01-31", Name: "春節"},
+			{Date: "2025-02-28", Name: "和平紀念日"},
+			{Date: "2025-04-03", Name: "兒童節補假"},
+			{Date: "2025-04-04", Name: "兒童節及民族掃墓節"},
+			{Date: "2025-05-01", Name: "勞動節"},
+			{Date: "2025-05-30", Name: "端午節補假"},
+			{Date: "2025-05-31", Name: "端午節"},
+			{Date: "2025-09-28", Name: "教師節"},
+			{Date: "2025-09-29", Name: "教師節補假"},
+			{Date: "2025-10-06", Name: "中秋節"},
+			{Date: "2025-10-10", Name: "國慶日"},
+			{Date: "2025-10-24", Name: "臺灣光復暨金門古寧頭大捷紀念日補假"},
+			{Date: "2025-10-25", Name: "臺灣光復暨金門古寧頭大捷紀念日"},
+			{Date: "2025-12-25", Name: "行憲紀念日"},
+		},
+	},
+	2026: {
+		Year: 2026,
+		Holidays: []Holiday{
+			{Date: "2026-01-01", Name: "開國紀念日"},
+			{Date: "2026-02-15", Name: "農曆除夕前一日"},
+			{Date: "2026-02-16", Name: "農曆除夕"},
+			{Date: "2026-02-17", Name: "春節"},
+			{Date: "2026-02-18", Name: "春節"},
+			{Date: "2026-02-19", Name: "春節"},
+			{Date: "2026-02-20", Name: "春節補假"},
+			{Date: "2026-02-27", Name: "和平紀念日補假"},
+			{Date: "2026-02-28", Name: "和平紀念日"},
+			{Date: "2026-04-03", Name: "兒童節補假"},
+			{Date: "2026-04-04", Name: "兒童節"},
+			{Date: "2026-04-05", Name: "民族掃墓節"},
+			{Date: "2026-04-06", Name: "民族掃墓節補假"},
+			{Date: "2026-05-01", Name: "勞動節"},
+			{Date: "2026-06-19", Name: "端午節"},
+			{Date: "2026-09-25", Name: "中秋節"},
+			{Date: "2026-09-28", Name: "教師節"},
+			{Date: "2026-10-09", Name: "國慶日補假"},
+			{Date: "2026-10-10", Name: "國慶日"},
+			{Date: "2026-10-25", Name: "臺灣光復暨金門古寧頭大捷紀念日"},
+			{Date: "2026-10-26", Name: "臺灣光復暨金門古寧頭大捷紀念日補假"},
+			{Date: "2026-12-25", Name: "行憲紀念日"},
+		},
+	},
+}
+
+// ForYear 取得指定年度的假日資料，資料集未收錄該年度時回傳 false
+func ForYear(year int) (YearCalendar, bool) {
+	cal, ok := calendars[year]
+	if !ok {
+		return YearCalendar{}, false
+	}
+	cal.Version = DatasetVersion
+	cal.Holidays = append([]Holiday(nil), cal.Holidays...)
+	if cal.MakeupWorkdays == nil {
+		cal.MakeupWorkdays = []MakeupWorkday{}
+	} else {
+		cal.MakeupWorkdays = append([]MakeupWorkday(nil), cal.MakeupWorkdays...)
+	}
+	return cal, true
+}
+
+// Years 取得資料集收錄的年度（由小到大）
+func Years() []int {
+	years := make([]int, 0, len(calendars))
+	for year := range calendars {
+		years = append(years, year)
+	}
+	sort.Ints(years)
+	return years
+}
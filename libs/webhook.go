@@ -0,0 +1,202 @@
+package libs
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Webhook 請求 header
+const (
+	WebhookHeaderEvent     = "X-TimeLedger-Event"
+	WebhookHeaderEventID   = "X-TimeLedger-Event-ID"
+	WebhookHeaderDelivery  = "X-TimeLedger-Delivery"
+	WebhookHeaderSignature = "X-TimeLedger-Signature" // t=<unix 秒>,v1=<hex HMAC-SHA256>
+)
+
+// webhookResponseLimit 讀取（並丟棄）回應內容的上限，讀完以重用連線
+const webhookResponseLimit = 2048
+
+// ErrWebhookRestrictedAddress 目標解析到內網、回環等不允許的位址
+var ErrWebhookRestrictedAddress = errors.New("webhook target resolves to a restricted address")
+
+// webhookRestrictedPrefixes net.IP 判斷之外仍不允許的網段
+var webhookRestrictedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"), // CGNAT
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+}
+
+// IsRestrictedWebhookIP 回環、私有、鏈路本地、未指定與多播等位址不可作為 webhook 目標
+func IsRestrictedWebhookIP(ip net.IP) bool {
+	if ip == nil {
+		return true
+	}
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return true
+	}
+	addr, ok := netip.AddrFromSlice(ip)
+	if !ok {
+		return true
+	}
+	addr = addr.Unmap()
+	for _, prefix := range webhookRestrictedPrefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewWebhookSecret 產生簽章密鑰
+func NewWebhookSecret() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(buf), nil
+}
+
+// SignWebhookPayload 以 HMAC-SHA256 簽署「timestamp.body」，回傳 signature header 值
+// 接收端以相同密鑰重算 v1 並比對，同時檢查 t 避免重放
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+	return fmt.Sprintf("t=%d,v1=%s", timestamp, webhookMAC(secret, timestamp, body))
+}
+
+func webhookMAC(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhookSignature 驗證 signature header，tolerance 為允許的時間差（0 表示不檢查）
+func VerifyWebhookSignature(secret, header string, body []byte, now time.Time, tolerance time.Duration) bool {
+	var timestamp int64
+	var signature string
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "t":
+			timestamp, _ = strconv.ParseInt(value, 10, 64)
+		case "v1":
+			signature = value
+		}
+	}
+	if timestamp == 0 || signature == "" {
+		return false
+	}
+	if tolerance > 0 {
+		diff := now.Sub(time.Unix(timestamp, 0))
+		if diff > tolerance || diff < -tolerance {
+			return false
+		}
+	}
+	return hmac.Equal([]byte(webhookMAC(secret, timestamp, body)), []byte(signature))
+}
+
+// WebhookRequest 單次 webhook 投遞
+type WebhookRequest struct {
+	URL        string
+	Secret     string
+	EventType  string
+	EventID    string
+	DeliveryID uint
+	Body       []byte
+}
+
+// WebhookResponse 投遞結果；StatusCode 為 0 表示未取得回應（連線失敗、逾時）
+// 回應內容不保留，避免訂閱端位址被用來讀取內部服務的回應
+type WebhookResponse struct {
+	StatusCode int
+	Duration   time.Duration
+}
+
+// Success 接收端回應 2xx
+func (r WebhookResponse) Success() bool {
+	return r.StatusCode >= 200 && r.StatusCode < 300
+}
+
+// WebhookClient 發送簽章 webhook
+type WebhookClient struct {
+	client *http.Client
+}
+
+// NewWebhookClient 建立 webhook 發送端，timeout 為單次請求上限
+// allowPrivate 為 false 時於連線當下檢查實際 IP，DNS 解析到內網位址（含 DNS rebinding）一律拒絕
+func NewWebhookClient(timeout time.Duration, allowPrivate bool) *WebhookClient {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivate {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if IsRestrictedWebhookIP(net.ParseIP(host)) {
+				return fmt.Errorf("%w: %s", ErrWebhookRestrictedAddress, host)
+			}
+			return nil
+		}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// 不走環境變數的 proxy，否則檢查的會是 proxy 位址而非實際目標
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &WebhookClient{
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: transport,
+			// 不跟隨轉址，避免簽章內容被轉送到非訂閱的位址
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// Send 發送 webhook，非 2xx 回應不視為錯誤，由呼叫端依 StatusCode 判斷
+func (c *WebhookClient) Send(ctx context.Context, r WebhookRequest) (WebhookResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.URL, bytes.NewReader(r.Body))
+	if err != nil {
+		return WebhookResponse{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "TimeLedger-Webhook/1.0")
+	req.Header.Set(WebhookHeaderEvent, r.EventType)
+	req.Header.Set(WebhookHeaderEventID, r.EventID)
+	req.Header.Set(WebhookHeaderDelivery, strconv.FormatUint(uint64(r.DeliveryID), 10))
+	req.Header.Set(WebhookHeaderSignature, SignWebhookPayload(r.Secret, time.Now().Unix(), r.Body))
+
+	start := time.Now()
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return WebhookResponse{Duration: time.Since(start)}, err
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, webhookResponseLimit))
+	return WebhookResponse{
+		StatusCode: resp.StatusCode,
+		Duration:   time.Since(start),
+	}, nil
+}
@@ -0,0 +1,376 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"timeLedger/app/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"gitlab.en.mcbwvx.com/frame/zilean/logs"
+)
+
+// 推送給管理後台的訊息類型
+const (
+	LiveMessageReady  = "ready"  // 連線完成（含補送後的最新序號）
+	LiveMessageEvent  = "event"  // 排課異動事件
+	LiveMessageResync = "resync" // 無法從 last_seq 接續，需重新載入資料
+)
+
+// LiveMessage 管理後台即時訊息
+type LiveMessage struct {
+	Type      string              `json:"type"`
+	LatestSeq int64               `json:"latest_seq,omitempty"`
+	Event     *services.LiveEvent `json:"event,omitempty"`
+}
+
+const (
+	// liveSendBuffer 每個連線待送出的事件數，滿了代表前端跟不上，直接斷線讓前端重連補送
+	liveSendBuffer = 64
+	// liveWriteTimeout 單次寫入的逾時時間，避免卡住的連線阻塞推送
+	liveWriteTimeout = 10 * time.Second
+	// liveGapRetries 序號不連續時重新讀取事件紀錄的次數（並行發佈的事件可能晚一點送達）
+	liveGapRetries    = 3
+	liveGapRetryDelay = 100 * time.Millisecond
+)
+
+// liveClient 管理後台的即時事件訂閱連線
+type liveClient struct {
+	Uuid      string
+	CenterID  uint
+	UserID    uint
+	IP        string
+	Conn      *websocket.Conn
+	writeMu   sync.Mutex
+	lastSeq   int64 // 已送出的最大序號，避免補送與即時推播重複
+	send      chan services.LiveEvent
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// enqueue 排入待送出的事件，佇列已滿時回傳 false
+func (c *liveClient) enqueue(event services.LiveEvent) bool {
+	select {
+	case c.send <- event:
+		return true
+	case <-c.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// close 關閉連線並停止送出，可重複呼叫
+func (c *liveClient) close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		_ = c.Conn.Close()
+	})
+}
+
+// writeJSON 呼叫前需持有 writeMu
+func (c *liveClient) writeJSON(msg LiveMessage) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return c.writeMessage(payload)
+}
+
+// writeMessage 呼叫前需持有 writeMu
+func (c *liveClient) writeMessage(payload []byte) error {
+	if err := c.Conn.SetWriteDeadline(time.Now().Add(liveWriteTimeout)); err != nil {
+		return err
+	}
+	return c.Conn.WriteMessage(websocket.TextMessage, payload)
+}
+
+// requestToken 優先使用 query token（瀏覽器 WebSocket 無法自訂 header），其次為 Authorization header
+func requestToken(c *gin.Context) string {
+	if token := c.Query("token"); token != "" {
+		return token
+	}
+	parts := strings.Split(c.GetHeader("Authorization"), " ")
+	if len(parts) == 2 && parts[0] == "Bearer" {
+		return parts[1]
+	}
+	return ""
+}
+
+// 處理管理後台即時事件訂閱
+// GET /ws/admin/live?token=<jwt>&last_seq=<n>
+// 重連時帶上最後收到的 seq，伺服器會補送缺漏事件；無法補送時回傳 resync
+func (w *WebSocketServer) handleLiveConnection(c *gin.Context) {
+	claims, err := w.auth.ValidateToken(requestToken(c))
+	if err != nil {
+		http.Error(c.Writer, "invalid or expired token", http.StatusUnauthorized)
+		return
+	}
+	if (claims.UserType != "ADMIN" && claims.UserType != "OWNER") || claims.CenterID == 0 {
+		http.Error(c.Writer, "admin access required", http.StatusForbidden)
+		return
+	}
+
+	afterSeq := int64(-1)
+	if raw := c.Query("last_seq"); raw != "" {
+		afterSeq, err = strconv.ParseInt(raw, 10, 64)
+		if err != nil || afterSeq < 0 {
+			http.Error(c.Writer, "invalid parameter: last_seq", http.StatusBadRequest)
+			return
+		}
+	}
+
+	uuid := c.Query("uuid")
+	if uuid == "" {
+		uuid = strconv.FormatUint(uint64(claims.UserID), 10) + "-" + strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+
+	conn, err := w.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		logs.WsLogInit().SetTopic(logs.WS_TOPIC_SRV).SetEvent(logs.WS_EVENT_SRV_UPGRADE_ERR).
+			SetUuid(uuid).SetClientIP(c.ClientIP()).SetError(err).
+			PrintError("Live upgrade error")
+		return
+	}
+
+	client := &liveClient{
+		Uuid:     uuid,
+		CenterID: claims.CenterID,
+		UserID:   claims.UserID,
+		IP:       c.ClientIP(),
+		Conn:     conn,
+		lastSeq:  afterSeq,
+		send:     make(chan services.LiveEvent, liveSendBuffer),
+		done:     make(chan struct{}),
+	}
+
+	// 先鎖住寫入再加入訂閱，補送完成前的即時事件會等待並依序號去重
+	client.writeMu.Lock()
+	w.addLiveClient(client)
+	if err := w.replayLive(c.Request.Context(), client, afterSeq); err != nil {
+		client.writeMu.Unlock()
+		logs.WsLogInit().SetTopic(logs.WS_TOPIC_SRV).SetEvent(logs.WS_EVENT_SRV_BROADCAST_ERR).
+			SetUuid(uuid).SetClientIP(client.IP).SetError(err).
+			PrintError("Live replay error")
+		w.removeLiveClient(client)
+		return
+	}
+	client.writeMu.Unlock()
+
+	logs.WsLogInit().SetTopic(logs.WS_TOPIC_SRV).SetEvent(logs.WS_EVENT_SRV_CLIENT_CONN).
+		SetUuid(uuid).SetClientIP(client.IP).
+		SetExtraInfo(map[string]any{"centerID": client.CenterID, "lastSeq": afterSeq}).
+		PrintInfo("Live client connected")
+
+	w.wg.Add(1)
+	go w.handleLiveClient(client)
+	go w.writeLiveClient(client)
+}
+
+// replayLive 補送 afterSeq 之後的事件，呼叫前需持有 writeMu
+func (w *WebSocketServer) replayLive(ctx context.Context, client *liveClient, afterSeq int64) error {
+	replay, err := w.liveEvents.Replay(ctx, client.CenterID, afterSeq)
+	if err != nil {
+		return err
+	}
+
+	if replay.ResyncRequired {
+		// 前端重新載入後會以 latest_seq 重新連線
+		client.lastSeq = replay.LatestSeq
+		return client.writeJSON(LiveMessage{Type: LiveMessageResync, LatestSeq: replay.LatestSeq})
+	}
+
+	for i := range replay.Events {
+		event := replay.Events[i]
+		if err := client.writeJSON(LiveMessage{Type: LiveMessageEvent, Event: &event}); err != nil {
+			return err
+		}
+		client.lastSeq = event.Seq
+	}
+
+	return client.writeJSON(LiveMessage{Type: LiveMessageReady, LatestSeq: replay.LatestSeq})
+}
+
+// handleLiveClient 只處理心跳，事件由 forwardLiveEvents 推送
+func (w *WebSocketServer) handleLiveClient(client *liveClient) {
+	defer func() {
+		w.removeLiveClient(client)
+		w.wg.Done()
+	}()
+
+	conn := client.Conn
+	conn.SetReadLimit(512)
+	_ = conn.SetReadDeadline(time.Now().Add(60 * time.Second))
+
+	for {
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		if string(msg) == "ping" {
+			_ = conn.SetReadDeadline(time.Now().Add(60 * time.Second))
+			client.writeMu.Lock()
+			_ = client.writeMessage([]byte("pong"))
+			client.writeMu.Unlock()
+		}
+	}
+}
+
+func (w *WebSocketServer) addLiveClient(client *liveClient) {
+	w.liveMu.Lock()
+	defer w.liveMu.Unlock()
+
+	if w.liveClients[client.CenterID] == nil {
+		w.liveClients[client.CenterID] = make(map[*liveClient]struct{})
+	}
+	w.liveClients[client.CenterID][client] = struct{}{}
+}
+
+func (w *WebSocketServer) removeLiveClient(client *liveClient) {
+	w.liveMu.Lock()
+	if clients, ok := w.liveClients[client.CenterID]; ok {
+		delete(clients, client)
+		if len(clients) == 0 {
+			delete(w.liveClients, client.CenterID)
+		}
+	}
+	w.liveMu.Unlock()
+	client.close()
+
+	logs.WsLogInit().SetTopic(logs.WS_TOPIC_SRV).SetEvent(logs.WS_EVENT_SRV_CLIENT_DIS_CONN).
+		SetUuid(client.Uuid).SetClientIP(client.IP).
+		SetExtraInfo(map[string]any{"centerID": client.CenterID}).
+		PrintInfo("Live client disconnected")
+}
+
+// BroadcastLiveEvent 將事件排入同中心所有訂閱連線的佇列，不等待寫出
+func (w *WebSocketServer) BroadcastLiveEvent(event services.LiveEvent) {
+	w.liveMu.Lock()
+	clients := make([]*liveClient, 0, len(w.liveClients[event.CenterID]))
+	for c := range w.liveClients[event.CenterID] {
+		clients = append(clients, c)
+	}
+	w.liveMu.Unlock()
+
+	for _, c := range clients {
+		if !c.enqueue(event) {
+			logs.WsLogInit().SetTopic(logs.WS_TOPIC_SRV).SetEvent(logs.WS_EVENT_SRV_BROADCAST_ERR).
+				SetUuid(c.Uuid).SetClientIP(c.IP).
+				PrintError("Live client too slow, disconnected")
+			// 關閉連線讓 handleLiveClient 結束並移除，前端重連時會補送
+			c.close()
+		}
+	}
+}
+
+// writeLiveClient 依序寫出佇列中的事件，寫入失敗時關閉連線
+func (w *WebSocketServer) writeLiveClient(client *liveClient) {
+	for {
+		select {
+		case event := <-client.send:
+			if err := w.deliverLiveEvent(client, event); err != nil {
+				logs.WsLogInit().SetTopic(logs.WS_TOPIC_SRV).SetEvent(logs.WS_EVENT_SRV_BROADCAST_ERR).
+					SetUuid(client.Uuid).SetClientIP(client.IP).SetError(err).
+					PrintError("Failed to push live event")
+				client.close()
+				return
+			}
+		case <-client.done:
+			return
+		}
+	}
+}
+
+// deliverLiveEvent 依序號送出事件，已送過的序號略過
+// 序號不連續時先從事件紀錄補送缺漏，仍無法補齊則要求前端重新同步
+func (w *WebSocketServer) deliverLiveEvent(client *liveClient, event services.LiveEvent) error {
+	client.writeMu.Lock()
+	defer client.writeMu.Unlock()
+
+	if event.Seq <= client.lastSeq {
+		return nil
+	}
+	if event.Seq > client.lastSeq+1 {
+		missing, ok := w.fetchLiveGap(client, event.Seq)
+		if !ok {
+			// 重新載入的資料已包含此事件
+			client.lastSeq = event.Seq
+			return client.writeJSON(LiveMessage{Type: LiveMessageResync, LatestSeq: event.Seq})
+		}
+		for i := range missing {
+			if err := client.writeJSON(LiveMessage{Type: LiveMessageEvent, Event: &missing[i]}); err != nil {
+				return err
+			}
+			client.lastSeq = missing[i].Seq
+		}
+	}
+
+	if err := client.writeJSON(LiveMessage{Type: LiveMessageEvent, Event: &event}); err != nil {
+		return err
+	}
+	client.lastSeq = event.Seq
+	return nil
+}
+
+// fetchLiveGap 從事件紀錄取得 lastSeq 與 beforeSeq 之間缺漏的事件，呼叫前需持有 writeMu
+func (w *WebSocketServer) fetchLiveGap(client *liveClient, beforeSeq int64) ([]services.LiveEvent, bool) {
+	for attempt := 0; attempt < liveGapRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(liveGapRetryDelay):
+			case <-client.done:
+				return nil, false
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), liveWriteTimeout)
+		retained, err := w.liveEvents.Retained(ctx, client.CenterID, client.lastSeq)
+		cancel()
+		if err != nil {
+			continue
+		}
+		if events, ok := services.FillLiveEventGap(retained, client.lastSeq, beforeSeq); ok {
+			return events, true
+		}
+	}
+	return nil, false
+}
+
+// forwardLiveEvents 訂閱 Redis 即時事件頻道並轉送，多個實例都能收到所有中心的事件
+func (w *WebSocketServer) forwardLiveEvents() {
+	if w.app.Redis == nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pubsub := w.app.Redis.DB0.PSubscribe(ctx, services.LiveEventChannelPattern)
+	defer pubsub.Close()
+
+	ch := pubsub.Channel()
+	for {
+		select {
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+			var event services.LiveEvent
+			if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+				logs.WsLogInit().SetTopic(logs.WS_TOPIC_SRV).SetEvent(logs.WS_EVENT_SRV_READ_ERR).
+					SetError(err).
+					PrintError("Live event decode error")
+				continue
+			}
+			w.BroadcastLiveEvent(event)
+		case <-w.closeChan:
+			return
+		}
+	}
+}
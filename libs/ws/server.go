@@ -10,6 +10,7 @@ import (
 	"sync"
 	"time"
 	"timeLedger/app"
+	"timeLedger/app/services"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
@@ -25,6 +26,12 @@ type WebSocketServer struct {
 	clientsMu sync.Mutex
 	closeChan chan struct{}
 	wg        sync.WaitGroup
+
+	// 管理後台即時事件訂閱（依中心分組）
+	auth        services.AuthService
+	liveEvents  *services.LiveEventService
+	liveClients map[uint]map[*liveClient]struct{}
+	liveMu      sync.Mutex
 }
 
 type clientInfo struct {
@@ -44,9 +51,12 @@ func InitializeServer(app *app.App) *WebSocketServer {
 				return true
 			},
 		},
-		router:    gin.New(),
-		clients:   make(map[string]*clientInfo),
-		closeChan: make(chan struct{}),
+		router:      gin.New(),
+		clients:     make(map[string]*clientInfo),
+		closeChan:   make(chan struct{}),
+		auth:        services.NewAuthService(app),
+		liveEvents:  services.NewLiveEventService(app),
+		liveClients: make(map[uint]map[*liveClient]struct{}),
 	}
 
 	gin.SetMode(gin.ReleaseMode)
@@ -57,6 +67,7 @@ func InitializeServer(app *app.App) *WebSocketServer {
 		c.String(http.StatusOK, "Healthy")
 	})
 	w.router.GET("/ws", w.handleConnection)
+	w.router.GET("/ws/admin/live", w.handleLiveConnection)
 
 	w.server = &http.Server{
 		Addr:    ":" + app.Env.WsServerPort,
@@ -71,6 +82,9 @@ func (w *WebSocketServer) Start() {
 	// 監控心跳
 	go w.monitorHeartbeats()
 
+	// 轉送排課即時事件
+	go w.forwardLiveEvents()
+
 	// 監聽Http服務
 	log.Println("Websocket server started")
 	go func() {
@@ -106,8 +120,13 @@ func (w *WebSocketServer) monitorHeartbeats() {
 	}
 }
 
-// 處理新的連線
+// 處理新的連線，需帶有效的 JWT（同 /ws/admin/live）
 func (w *WebSocketServer) handleConnection(c *gin.Context) {
+	if _, err := w.auth.ValidateToken(requestToken(c)); err != nil {
+		http.Error(c.Writer, "invalid or expired token", http.StatusUnauthorized)
+		return
+	}
+
 	uuid := c.Query("uuid")
 	if uuid == "" {
 		// 沒有 uuid，直接拒絕
@@ -327,6 +346,23 @@ func (w *WebSocketServer) Stop() {
 	}
 	w.clientsMu.Unlock()
 
+	// 關閉即時事件訂閱連線，前端會帶 last_seq 重連
+	w.liveMu.Lock()
+	var liveClients []*liveClient
+	for _, clients := range w.liveClients {
+		for c := range clients {
+			liveClients = append(liveClients, c)
+		}
+	}
+	w.liveMu.Unlock()
+	for _, c := range liveClients {
+		c.writeMu.Lock()
+		_ = c.Conn.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down"), time.Now().Add(time.Second))
+		c.writeMu.Unlock()
+		c.close()
+	}
+
 	// 等待 handleClient goroutine 完成
 	w.wg.Wait()
 
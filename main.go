@@ -14,6 +14,8 @@ import (
 	"timeLedger/app/servers"
 	"timeLedger/app/services"
 	"timeLedger/global/logger"
+	"timeLedger/libs/mq"
+	"timeLedger/libs/ws"
 
 	"github.com/hibiken/asynq"
 
@@ -63,24 +65,44 @@ func main() {
 	scheduler := console.Initialize(appInstance)
 	scheduler.Start()
 
-	// 通知佇列 Worker（按環境變量開關，預設關閉以節省資源）
-	if os.Getenv("NOTIFICATION_WORKER_ENABLED") == "true" {
+	// 通知派送 Worker（按環境變量開關，預設關閉以節省資源；ASYNQ_WORKER_ENABLED 為舊設定）
+	if os.Getenv("NOTIFICATION_WORKER_ENABLED") == "true" || os.Getenv("ASYNQ_WORKER_ENABLED") == "true" {
 		go startNotificationWorker(appInstance, ctx, zapLog)
 	} else {
 		zapLog.Info("Notification worker disabled (set NOTIFICATION_WORKER_ENABLED=true to enable)")
 	}
 
-	// Asynq Worker（按環境變量開關，預設關閉以節省資源）
-	if os.Getenv("ASYNQ_WORKER_ENABLED") == "true" {
-		go startAsynqWorker(appInstance, ctx, zapLog)
-	} else {
-		zapLog.Info("Asynq worker disabled (set ASYNQ_WORKER_ENABLED=true to enable)")
+	// 領域事件 relay（outbox -> RabbitMQ；DOMAIN_EVENT_MQ_ENABLED 未開啟時於程序內直接處理）
+	var rabbitMQ *mq.RabbitMQ
+	if os.Getenv("DOMAIN_EVENT_MQ_ENABLED") == "true" {
+		rabbitMQ = mq.Initialize(appInstance)
+	}
+	go startDomainEventRelay(appInstance, rabbitMQ, ctx, zapLog)
+
+	// 中心對外 webhook 投遞
+	go startWebhookDelivery(appInstance, ctx, zapLog)
+
+	// LINE webhook 事件補處理（其他實例中斷或管理員重新處理的事件）
+	go startLineWebhookEventWorker(appInstance, ctx, zapLog)
+
+	// LINE Rich Menu 同步（LINE_RICH_MENU_ENABLED 開啟時，僅上傳有變動的選單）
+	if appInstance.Env.LineRichMenuEnabled {
+		go syncLineRichMenus(appInstance, ctx, zapLog)
 	}
 
 	// 啟動 API server（主要服務）
 	gin := servers.Initialize(appInstance)
 	gin.Start()
 
+	// WebSocket server（管理後台即時排課異動，未設定 WS_SERVER_PORT 則不啟動）
+	var wsServer *ws.WebSocketServer
+	if appInstance.Env.WsServerPort != "" {
+		wsServer = ws.InitializeServer(appInstance)
+		wsServer.Start()
+	} else {
+		zapLog.Info("WebSocket server disabled (set WS_SERVER_PORT to enable)")
+	}
+
 	// 優雅退出
 	signals := make(chan os.Signal, 1)
 	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
@@ -92,7 +114,13 @@ func main() {
 		cancel()
 		scheduler.Stop()
 		stopAsynqWorker()
+		if wsServer != nil {
+			wsServer.Stop()
+		}
 		gin.Stop()
+		if rabbitMQ != nil {
+			rabbitMQ.Stop()
+		}
 		zapLog.Info("Application shutting down gracefully")
 		exit <- struct{}{}
 	}()
@@ -110,8 +138,45 @@ func getLogLevel() string {
 	return level
 }
 
-// startNotificationWorker 啟動通知佇列背景 worker
+// startNotificationWorker 啟動通知派送 worker
+// 資料庫輪詢負責到期、重試與佇列遺失的通知；佇列後端為 redis / asynq 時另外啟動對應的 consumer
 func startNotificationWorker(appInstance *app.App, ctx context.Context, zapLog *logger.Logger) {
+	dispatcher := services.NewNotificationDispatcher(appInstance)
+	zapLog.Infow("Notification dispatcher started", "backend", dispatcher.Backend())
+
+	switch dispatcher.Backend() {
+	case services.NotificationBackendRedis:
+		go startRedisNotificationConsumer(appInstance, ctx, zapLog)
+	case services.NotificationBackendAsynq:
+		go startAsynqWorker(appInstance, ctx, zapLog)
+	}
+
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			zapLog.Info("Notification dispatcher stopped")
+			return
+		case <-ticker.C:
+			// 一次派送直到沒有到期通知
+			for {
+				delivered, err := dispatcher.RelayDue(ctx, services.NotificationDispatchBatchSize)
+				if err != nil {
+					zapLog.Errorw("Notification dispatch error", "error", err)
+					break
+				}
+				if delivered < services.NotificationDispatchBatchSize {
+					break
+				}
+			}
+		}
+	}
+}
+
+// startRedisNotificationConsumer 啟動 Redis 佇列 consumer
+func startRedisNotificationConsumer(appInstance *app.App, ctx context.Context, zapLog *logger.Logger) {
 	zapLog.Info("Starting notification queue worker...")
 
 	queueService := services.NewNotificationQueueService(appInstance)
@@ -162,6 +227,115 @@ func startNotificationWorker(appInstance *app.App, ctx context.Context, zapLog *
 	}
 }
 
+// startDomainEventRelay 定時將 outbox 中的領域事件轉送給消費端
+func startDomainEventRelay(appInstance *app.App, rabbitMQ *mq.RabbitMQ, ctx context.Context, zapLog *logger.Logger) {
+	domainEvents := services.NewDomainEventService(appInstance)
+
+	var publisher services.DomainEventPublisher
+	if rabbitMQ != nil {
+		publisher = mq.NewDomainEventPublisher(rabbitMQ)
+		zapLog.Info("Domain event relay started, publishing to RabbitMQ")
+	} else {
+		publisher = services.NewLocalDomainEventPublisher(
+			services.NewDomainEventCacheHandler(appInstance),
+			services.NewDomainEventNotificationHandler(appInstance),
+			services.NewDomainEventWebhookHandler(appInstance),
+		)
+		zapLog.Info("Domain event relay started, handling events in-process (set DOMAIN_EVENT_MQ_ENABLED=true to use RabbitMQ)")
+	}
+
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			zapLog.Info("Domain event relay stopped")
+			return
+		case <-ticker.C:
+			// 一次轉送直到沒有到期事件
+			for {
+				published, err := domainEvents.RelayPending(ctx, publisher, services.DomainEventRelayBatchSize)
+				if err != nil {
+					zapLog.Errorw("Domain event relay error", "error", err)
+					break
+				}
+				if published < services.DomainEventRelayBatchSize {
+					break
+				}
+			}
+		}
+	}
+}
+
+// startWebhookDelivery 定時投遞到期的 webhook（首次投遞與退避重試）
+func startWebhookDelivery(appInstance *app.App, ctx context.Context, zapLog *logger.Logger) {
+	webhooks := services.NewWebhookService(appInstance)
+	zapLog.Info("Webhook delivery worker started")
+
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			zapLog.Info("Webhook delivery worker stopped")
+			return
+		case <-ticker.C:
+			// 一次投遞直到沒有到期紀錄
+			for {
+				delivered, err := webhooks.RelayDue(ctx, services.WebhookRelayBatchSize)
+				if err != nil {
+					zapLog.Errorw("Webhook delivery error", "error", err)
+					break
+				}
+				if delivered < services.WebhookRelayBatchSize {
+					break
+				}
+			}
+		}
+	}
+}
+
+// startLineWebhookEventWorker 定時處理尚未完成的 LINE webhook 事件；新事件由 webhook 收到後立即處理
+func startLineWebhookEventWorker(appInstance *app.App, ctx context.Context, zapLog *logger.Logger) {
+	botEvents := services.NewLineBotEventService(appInstance)
+	zapLog.Info("LINE webhook event worker started")
+
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			zapLog.Info("LINE webhook event worker stopped")
+			return
+		case <-ticker.C:
+			for {
+				processed, err := botEvents.ProcessPending(ctx)
+				if err != nil {
+					zapLog.Errorw("LINE webhook event worker error", "error", err)
+					break
+				}
+				if processed < services.LineWebhookEventBatchSize {
+					break
+				}
+			}
+		}
+	}
+}
+
+func syncLineRichMenus(appInstance *app.App, ctx context.Context, zapLog *logger.Logger) {
+	syncCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	if err := services.NewLineRichMenuService(appInstance).Sync(syncCtx); err != nil {
+		zapLog.Errorw("LINE rich menu sync error", "error", err)
+		return
+	}
+	zapLog.Info("LINE rich menu synced")
+}
+
 // asynqServer 用於控制 Asynq Server 的生命週期
 var asynqServer *asynq.Server
 
@@ -0,0 +1,98 @@
+package test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"timeLedger/app"
+	"timeLedger/app/controllers"
+	"timeLedger/app/models"
+	"timeLedger/app/services"
+	"timeLedger/configs"
+	"timeLedger/database/mysql"
+	"timeLedger/global/errInfos"
+
+	"github.com/stretchr/testify/assert"
+	gormMysql "gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func broadcastRecipientIDs(teachers []models.Teacher) []uint {
+	ids := make([]uint, 0, len(teachers))
+	for _, teacher := range teachers {
+		ids = append(ids, teacher.ID)
+	}
+	return ids
+}
+
+// TestSelectBroadcastRecipients 對象條件同時成立才會收到，佔位老師一律排除
+func TestSelectBroadcastRecipients(t *testing.T) {
+	teachers := []models.Teacher{
+		{ID: 1, Name: "王老師"},
+		{ID: 2, Name: "李老師"},
+		{ID: 3, Name: "代課老師"},
+		{ID: 4, Name: "待聘老師", IsPlaceholder: true},
+	}
+	roles := map[uint]string{1: "TEACHER", 2: "TEACHER", 3: "SUBSTITUTE", 4: "TEACHER"}
+
+	all := services.SelectBroadcastRecipients(teachers, roles, models.BroadcastSegment{}, nil, nil)
+	assert.Equal(t, []uint{1, 2, 3}, broadcastRecipientIDs(all))
+
+	specified := services.SelectBroadcastRecipients(teachers, roles, models.BroadcastSegment{TeacherIDs: []uint{2, 4}}, nil, nil)
+	assert.Equal(t, []uint{2}, broadcastRecipientIDs(specified))
+
+	substitutes := services.SelectBroadcastRecipients(teachers, roles, models.BroadcastSegment{Roles: []string{"SUBSTITUTE"}}, nil, nil)
+	assert.Equal(t, []uint{3}, broadcastRecipientIDs(substitutes))
+
+	offering := services.SelectBroadcastRecipients(teachers, roles, models.BroadcastSegment{OfferingIDs: []uint{10}}, []uint{1, 4}, nil)
+	assert.Equal(t, []uint{1}, broadcastRecipientIDs(offering))
+
+	// 班別與上課日期同時指定時取交集
+	combined := services.SelectBroadcastRecipients(teachers, roles,
+		models.BroadcastSegment{OfferingIDs: []uint{10}, SessionDate: "2026-03-02"}, []uint{1, 2}, []uint{2, 3})
+	assert.Equal(t, []uint{2}, broadcastRecipientIDs(combined))
+
+	noSessions := services.SelectBroadcastRecipients(teachers, roles, models.BroadcastSegment{SessionDate: "2026-03-02"}, nil, nil)
+	assert.Empty(t, noSessions)
+}
+
+// TestValidateBroadcastSegment 日期格式與角色檢查
+func TestValidateBroadcastSegment(t *testing.T) {
+	assert.NoError(t, services.ValidateBroadcastSegment(models.BroadcastSegment{SessionDate: "2026-03-02", Roles: []string{"TEACHER", "SUBSTITUTE"}}))
+	assert.Error(t, services.ValidateBroadcastSegment(models.BroadcastSegment{SessionDate: "2026/03/02"}))
+	assert.Error(t, services.ValidateBroadcastSegment(models.BroadcastSegment{Roles: []string{"ADMIN"}}))
+}
+
+// TestIsLineRecipientBlocked 僅將無法送達的收件者歸為封鎖，其餘錯誤仍為失敗
+func TestIsLineRecipientBlocked(t *testing.T) {
+	blocked := &services.LineAPIError{StatusCode: http.StatusBadRequest, Message: "Failed to send messages"}
+	assert.True(t, services.IsLineRecipientBlocked(blocked))
+	assert.True(t, services.IsLineRecipientBlocked(fmt.Errorf("push: %w", blocked)))
+
+	assert.False(t, services.IsLineRecipientBlocked(&services.LineAPIError{StatusCode: http.StatusTooManyRequests, Message: "The API rate limit has been exceeded."}))
+	assert.False(t, services.IsLineRecipientBlocked(&services.LineAPIError{StatusCode: http.StatusBadRequest, Message: "The request body has 1 error(s)"}))
+	assert.False(t, services.IsLineRecipientBlocked(fmt.Errorf("timeout")))
+	assert.False(t, services.IsLineRecipientBlocked(nil))
+}
+
+// newOfflineTestApp 建立不連線資料庫的 app，供請求在存取資料庫前即被拒絕的測試使用
+func newOfflineTestApp() *app.App {
+	db, _ := gorm.Open(gormMysql.New(gormMysql.Config{DSN: "offline:offline@tcp(127.0.0.1:1)/offline", SkipInitializeWithVersion: true}),
+		&gorm.Config{DisableAutomaticPing: true})
+	return &app.App{
+		Env:   &configs.Env{AppEnv: "production"},
+		Err:   errInfos.Initialize(1),
+		MySQL: &mysql.DB{WDB: db, RDB: db},
+	}
+}
+
+// TestAdminBroadcastInvalidSegment 對象條件錯誤時回應 400 與具體原因
+func TestAdminBroadcastInvalidSegment(t *testing.T) {
+	ctl := controllers.NewAdminNotificationController(newOfflineTestApp())
+
+	w := serveAdminJSON(ctl.Broadcast, http.MethodPost, "/admin/notifications/broadcast", "/admin/notifications/broadcast",
+		`{"message_type":"GENERAL","title":"停課通知","message":"明日停課","roles":["OWNER"]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "OWNER")
+}
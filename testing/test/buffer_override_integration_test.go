@@ -50,6 +50,7 @@ func setupBufferOverrideTestApp() (*app.App, *gorm.DB, func()) {
 		&models.Course{},
 		&models.Offering{},
 		&models.ScheduleRule{},
+		&models.DomainEvent{},
 	); err != nil {
 		panic(fmt.Sprintf("AutoMigrate error: %s", err.Error()))
 	}
@@ -0,0 +1,103 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"timeLedger/app/models"
+	"timeLedger/app/services"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestNormalizeReminderOffsets 去除重複、由早到晚排序並檢查範圍
+func TestNormalizeReminderOffsets(t *testing.T) {
+	offsets, err := services.NormalizeReminderOffsets([]int{60, 1440, 60})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1440, 60}, offsets)
+
+	offsets, err = services.NormalizeReminderOffsets([]int{})
+	assert.NoError(t, err)
+	assert.Empty(t, offsets)
+
+	_, err = services.NormalizeReminderOffsets([]int{0})
+	assert.Error(t, err)
+	_, err = services.NormalizeReminderOffsets([]int{services.MaxReminderOffsetMinutes + 1})
+	assert.Error(t, err)
+
+	// 老師未自訂時沿用中心設定，中心未設定時為前一天
+	assert.Equal(t, []int{1440}, services.TeacherReminderOffsets(models.CenterSettings{}, models.NotificationPreference{}))
+	assert.Equal(t, []int{30}, services.TeacherReminderOffsets(
+		models.CenterSettings{ReminderOffsets: []int{1440, 60}},
+		models.NotificationPreference{ReminderOffsets: models.ReminderOffsets{30}},
+	))
+	assert.Empty(t, services.TeacherReminderOffsets(
+		models.CenterSettings{ReminderOffsets: []int{1440}},
+		models.NotificationPreference{ReminderOffsets: models.ReminderOffsets{}},
+	))
+}
+
+// TestPlanDueClassReminders 依場次開始時間與提前時間計算到期的提醒
+func TestPlanDueClassReminders(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Taipei")
+	if !assert.NoError(t, err) {
+		return
+	}
+	lead, coTeacher, otherTeacher := uint(1), uint(2), uint(3)
+	day := time.Date(2026, 10, 20, 0, 0, 0, 0, loc)
+
+	sessions := []services.ExpandedSchedule{
+		{
+			RuleID: 10, Date: day, StartTime: "14:00", EndTime: "15:00", TeacherID: &lead,
+			Status:   models.RuleStatusConfirmed,
+			Teachers: []models.TeacherAssignment{{TeacherID: lead, Role: models.TeacherRoleLead}, {TeacherID: coTeacher}},
+		},
+		// 預計開課的場次不提醒
+		{RuleID: 11, Date: day, StartTime: "14:00", EndTime: "15:00", TeacherID: &otherTeacher, Status: models.RuleStatusPlanned},
+		// 跨日課程的後半段不另外提醒
+		{RuleID: 12, Date: day, StartTime: "00:00", EndTime: "01:00", TeacherID: &otherTeacher, IsCrossDayPart: true},
+	}
+	offsetsFor := func(teacherID uint) []int {
+		if teacherID == coTeacher {
+			return []int{30}
+		}
+		return []int{1440, 60}
+	}
+
+	// 13:00 為主教老師的一小時前提醒
+	due := services.PlanDueClassReminders(sessions, offsetsFor, time.Date(2026, 10, 20, 13, 0, 0, 0, loc), loc)
+	if assert.Len(t, due, 1) {
+		assert.Equal(t, lead, due[0].TeacherID)
+		assert.Equal(t, 60, due[0].OffsetMinutes)
+		assert.Equal(t, time.Date(2026, 10, 20, 14, 0, 0, 0, loc), due[0].StartAt)
+	}
+
+	// 排程中斷 10 分鐘後仍會補發
+	due = services.PlanDueClassReminders(sessions, offsetsFor, time.Date(2026, 10, 20, 13, 10, 0, 0, loc), loc)
+	if assert.Len(t, due, 1) {
+		assert.Equal(t, lead, due[0].TeacherID)
+	}
+
+	// 協同老師依自己的設定在 30 分鐘前提醒；主教老師的提醒已超過補發期限
+	due = services.PlanDueClassReminders(sessions, offsetsFor, time.Date(2026, 10, 20, 13, 35, 0, 0, loc), loc)
+	if assert.Len(t, due, 1) {
+		assert.Equal(t, coTeacher, due[0].TeacherID)
+		assert.Equal(t, 30, due[0].OffsetMinutes)
+	}
+
+	// 前一天的提醒時間已過超過補發期限，不再發送
+	due = services.PlanDueClassReminders(sessions, offsetsFor, time.Date(2026, 10, 19, 15, 0, 0, 0, loc), loc)
+	assert.Empty(t, due)
+
+	// 已開始的場次不提醒
+	due = services.PlanDueClassReminders(sessions, offsetsFor, time.Date(2026, 10, 20, 14, 0, 0, 0, loc), loc)
+	assert.Empty(t, due)
+}
+
+// TestReminderOffsetLabel 提前時間的顯示文字
+func TestReminderOffsetLabel(t *testing.T) {
+	assert.Equal(t, "明天", services.ReminderOffsetLabel(1440))
+	assert.Equal(t, "2天後", services.ReminderOffsetLabel(2880))
+	assert.Equal(t, "1小時後", services.ReminderOffsetLabel(60))
+	assert.Equal(t, "45分鐘後", services.ReminderOffsetLabel(45))
+}
@@ -0,0 +1,116 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"timeLedger/app/models"
+	"timeLedger/app/services"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// TestBuildDailyKPIs 每日 KPI 計算：停課原因、改期、代課、補課、審核時效與新進老師
+func TestBuildDailyKPIs(t *testing.T) {
+	parse := func(v string) time.Time {
+		d, _ := time.Parse("2006-01-02", v)
+		return d
+	}
+	lead := uint(10)
+	substitute := uint(20)
+	makeupTeacher := uint(30)
+
+	// 週一 10:00 - 11:00，2026-03-02 起每週上課
+	rule := models.ScheduleRule{
+		ID: 1, TeacherID: &lead, Weekday: 1, StartTime: "10:00", EndTime: "11:00",
+		Status:         models.RuleStatusConfirmed,
+		EffectiveRange: models.DateRange{StartDate: parse("2026-03-02")},
+		SuspendedDates: models.SuspendedDates{parse("2026-03-23")},
+	}
+	planned := rule
+	planned.ID = 2
+	planned.Status = models.RuleStatusPlanned
+
+	created := time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC)
+	reviewed := created.Add(6 * time.Hour)
+	responded := time.Date(2026, 3, 10, 3, 0, 0, 0, time.UTC)
+
+	rows := services.BuildDailyKPIs(services.DailyKPIInput{
+		CenterID:  1,
+		StartDate: parse("2026-03-01"),
+		EndDate:   parse("2026-03-31"),
+		Rules:     []models.ScheduleRule{rule, planned},
+		Holidays:  []models.CenterHoliday{{Date: parse("2026-03-16"), ForceCancel: true}},
+		Exceptions: map[uint]map[string][]models.ScheduleException{
+			1: {
+				"2026-03-02": {{ID: 1, ExceptionType: "REPLACE_TEACHER", Status: "APPROVED", NewTeacherID: &substitute}},
+				"2026-03-09": {{ID: 2, ExceptionType: "CANCEL", Status: "APPROVED"}},
+				"2026-03-30": {{ID: 3, ExceptionType: "RESCHEDULE", Status: "APPROVED"}, {ID: 4, ExceptionType: "CANCEL", Status: "REJECTED"}},
+			},
+		},
+		Reviewed:    []models.ScheduleException{{ID: 2, CreatedAt: created, ReviewedAt: &reviewed}},
+		Makeups:     []models.MakeupSession{{MakeupDate: parse("2026-03-11"), StartTime: "14:00", EndTime: "15:30", TeacherID: &makeupTeacher, Status: models.MakeupStatusScheduled}},
+		Invitations: []models.CenterInvitation{{Status: models.InvitationStatusAccepted, RespondedAt: &responded}},
+		Location:    time.UTC,
+	})
+
+	assert.Len(t, rows, 31)
+	report := services.SummarizeKPIs(rows, parse("2026-03-01"), parse("2026-03-31"))
+
+	// 3/2、3/9、3/16、3/23、3/30 共五堂；3/9 例外單停課、3/16 假日、3/23 暫停
+	assert.Equal(t, 5, report.Sessions.Planned)
+	assert.Equal(t, 3, report.Sessions.Cancelled)
+	assert.Equal(t, 3, report.Sessions.Delivered) // 2 堂正課 + 1 堂補課
+	assert.Equal(t, 1, report.Sessions.Makeup)
+	assert.Equal(t, 1, report.Sessions.Rescheduled)
+	assert.Equal(t, 0.4, report.Sessions.DeliveryRate)
+	assert.Equal(t, 0.6, report.Sessions.CancellationRate)
+	assert.Len(t, report.CancellationReasons, 3)
+
+	assert.Equal(t, 1, report.Substitutes.Sessions)
+	assert.Equal(t, 1, report.ApprovalLeadTime.Reviewed)
+	assert.Equal(t, 6.0, report.ApprovalLeadTime.AverageHours)
+	assert.Equal(t, 1, report.NewTeachers)
+
+	// 代課老師 60 分鐘、主教 60 分鐘、補課老師 90 分鐘
+	assert.Equal(t, 3, report.TeacherUtilization.ActiveTeachers)
+	assert.Equal(t, 210, report.TeacherUtilization.TotalMinutes)
+	if assert.Len(t, report.TeacherUtilization.Teachers, 3) {
+		assert.Equal(t, makeupTeacher, report.TeacherUtilization.Teachers[0].TeacherID)
+	}
+
+	types := map[string]int{}
+	for _, b := range report.ExceptionTypes {
+		types[b.Key] = b.Count
+	}
+	assert.Equal(t, map[string]int{"REPLACE_TEACHER": 1, "CANCEL": 1, "RESCHEDULE": 1}, types)
+}
+
+// TestBuildDailyKPIsDeletedRule 已刪除的規則只計入刪除日之前的場次
+func TestBuildDailyKPIsDeletedRule(t *testing.T) {
+	parse := func(v string) time.Time {
+		d, _ := time.Parse("2006-01-02", v)
+		return d
+	}
+	lead := uint(10)
+	rule := models.ScheduleRule{
+		ID: 1, TeacherID: &lead, Weekday: 1, StartTime: "10:00", EndTime: "11:00",
+		Status:         models.RuleStatusConfirmed,
+		EffectiveRange: models.DateRange{StartDate: parse("2026-03-02"), EndDate: parse("2026-12-31")},
+		DeletedAt:      gorm.DeletedAt{Time: time.Date(2026, 3, 16, 9, 0, 0, 0, time.UTC), Valid: true},
+	}
+
+	rows := services.BuildDailyKPIs(services.DailyKPIInput{
+		CenterID:  1,
+		StartDate: parse("2026-03-01"),
+		EndDate:   parse("2026-03-31"),
+		Rules:     []models.ScheduleRule{rule},
+		Location:  time.UTC,
+	})
+	report := services.SummarizeKPIs(rows, parse("2026-03-01"), parse("2026-03-31"))
+
+	// 3/2、3/9 計入；3/16 當天刪除之後不再計入
+	assert.Equal(t, 2, report.Sessions.Planned)
+	assert.Equal(t, 2, report.Sessions.Delivered)
+}
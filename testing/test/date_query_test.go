@@ -0,0 +1,110 @@
+package test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"timeLedger/app/services"
+	"timeLedger/libs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParseDateQuery 中英文相對與絕對日期描述，基準日為 2026/10/19（週一）台灣時間
+func TestParseDateQuery(t *testing.T) {
+	now := time.Date(2026, 10, 19, 15, 0, 0, 0, libs.GetTaiwanLocation())
+
+	cases := []struct {
+		text  string
+		start string
+		end   string
+	}{
+		{"今天", "2026-10-19", "2026-10-19"},
+		{"明天課表", "2026-10-20", "2026-10-20"},
+		{"後天", "2026-10-21", "2026-10-21"},
+		{"下週三課表", "2026-10-28", "2026-10-28"},
+		{"週五", "2026-10-23", "2026-10-23"},
+		{"上週五", "2026-10-16", "2026-10-16"},
+		{"星期日有課嗎", "2026-10-25", "2026-10-25"},
+		{"這週", "2026-10-19", "2026-10-25"},
+		{"下個禮拜", "2026-10-26", "2026-11-01"},
+		{"下下週", "2026-11-02", "2026-11-08"},
+		{"3/15 有課嗎", "2027-03-15", "2027-03-15"},
+		{"12/25", "2026-12-25", "2026-12-25"},
+		{"１２／２５", "2026-12-25", "2026-12-25"},
+		{"3月15號", "2027-03-15", "2027-03-15"},
+		{"2026-03-15", "2026-03-15", "2026-03-15"},
+		{"25號", "2026-10-25", "2026-10-25"},
+		{"3天後", "2026-10-22", "2026-10-22"},
+		{"下個月幾堂課", "2026-11-01", "2026-11-30"},
+		{"12月", "2026-12-01", "2026-12-31"},
+		{"10/20-10/23", "2026-10-20", "2026-10-23"},
+		{"10/20-23", "2026-10-20", "2026-10-23"},
+		{"下週一到下週三", "2026-10-26", "2026-10-28"},
+		{"today's schedule", "2026-10-19", "2026-10-19"},
+		{"what's on monday?", "2026-10-19", "2026-10-19"},
+		{"next friday", "2026-10-30", "2026-10-30"},
+		{"schedule next week", "2026-10-26", "2026-11-01"},
+		{"how many classes next month", "2026-11-01", "2026-11-30"},
+		{"March 15", "2027-03-15", "2027-03-15"},
+		{"in 3 days", "2026-10-22", "2026-10-22"},
+		{"tomorrow to friday", "2026-10-20", "2026-10-23"},
+	}
+
+	for _, tc := range cases {
+		query, ok := libs.ParseDateQuery(tc.text, now)
+		if !assert.True(t, ok, tc.text) {
+			continue
+		}
+		assert.Equal(t, tc.start, query.Start.Format("2006-01-02"), tc.text)
+		assert.Equal(t, tc.end, query.End.Format("2006-01-02"), tc.text)
+		assert.Equal(t, libs.GetTaiwanLocation(), query.Start.Location(), tc.text)
+	}
+
+	// 非日期描述、不存在的日期、超過上限的區間
+	for _, text := range []string{"hello", "幫助", "課表", "1", "週", "2/30", "1/1-3/1"} {
+		_, ok := libs.ParseDateQuery(text, now)
+		assert.False(t, ok, text)
+	}
+
+	query, _ := libs.ParseDateQuery("這週", now)
+	assert.Equal(t, 7, query.Days())
+	assert.False(t, query.IsSingleDay())
+}
+
+// TestGenerateAgendaSummaryFlex 單週為單張卡片，跨週時每週一張並以輪播呈現
+func TestGenerateAgendaSummaryFlex(t *testing.T) {
+	templates := services.NewLineBotTemplateService("https://timeledger.example.com")
+	start := time.Date(2026, 10, 22, 0, 0, 0, 0, libs.GetTaiwanLocation()) // 週四
+
+	var days []services.AgendaDay
+	for i := 0; i < 7; i++ {
+		days = append(days, services.AgendaDay{Date: start.AddDate(0, 0, i)})
+	}
+	days[0].Items = []services.AgendaItem{
+		{Time: "09:00", Title: "瑜珈", SourceName: "A 中心", SourceType: services.AgendaSourceTypeCenter},
+		{Time: "10:00", Title: "皮拉提斯", SourceName: "A 中心", SourceType: services.AgendaSourceTypeCenter},
+		{Time: "11:00", Title: "伸展", SourceName: "B 中心", SourceType: services.AgendaSourceTypeCenter},
+		{Time: "12:00", Title: "午餐", SourceName: "個人", SourceType: services.AgendaSourceTypePersonal},
+	}
+
+	centerCount, personalCount := services.CountAgendaItems(days)
+	assert.Equal(t, 3, centerCount)
+	assert.Equal(t, 1, personalCount)
+
+	flex, ok := templates.GenerateAgendaSummaryFlex(days, "10/22 - 10/28 行程", "陳小美").(map[string]interface{})
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.Equal(t, "carousel", flex["type"])
+	assert.Len(t, flex["contents"], 2) // 10/22-10/25、10/26-10/28
+
+	body, _ := json.Marshal(flex)
+	assert.Contains(t, string(body), "共 3 堂課・1 項個人行程")
+	assert.Contains(t, string(body), "…另 1 項")
+	assert.Contains(t, string(body), "10/26 週一")
+
+	single := templates.GenerateAgendaSummaryFlex(days[4:], "10/26 當週行程", "陳小美").(map[string]interface{})
+	assert.Equal(t, "bubble", single["type"])
+}
@@ -0,0 +1,118 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"timeLedger/app/models"
+	"timeLedger/app/services"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingDomainEventHandler struct {
+	name  string
+	err   error
+	calls *[]string
+}
+
+func (h recordingDomainEventHandler) Name() string {
+	return h.name
+}
+
+func (h recordingDomainEventHandler) Handle(ctx context.Context, msg services.DomainEventMessage) error {
+	*h.calls = append(*h.calls, h.name+":"+msg.EventType)
+	return h.err
+}
+
+// TestDomainEventRetryDelay outbox 轉送失敗的退避時間
+func TestDomainEventRetryDelay(t *testing.T) {
+	assert.Equal(t, 5*time.Second, services.DomainEventRetryDelay(0))
+	assert.Equal(t, 5*time.Second, services.DomainEventRetryDelay(1))
+	assert.Equal(t, 10*time.Second, services.DomainEventRetryDelay(2))
+	assert.Equal(t, 40*time.Second, services.DomainEventRetryDelay(4))
+	assert.Equal(t, 10*time.Minute, services.DomainEventRetryDelay(9))
+	assert.Equal(t, 10*time.Minute, services.DomainEventRetryDelay(100))
+}
+
+// TestDomainEventMessage outbox 紀錄轉為訊息並解析內容
+func TestDomainEventMessage(t *testing.T) {
+	msg := services.NewDomainEventMessage(models.DomainEvent{
+		EventID:       "evt-1",
+		CenterID:      3,
+		EventType:     models.DomainEventRuleChanged,
+		AggregateType: "ScheduleRule",
+		AggregateID:   12,
+		Payload:       `{"action":"UPDATED","rule_ids":[12,13],"teacher_ids":[7],"update_mode":"FUTURE"}`,
+	})
+
+	var payload services.RuleChangedPayload
+	assert.NoError(t, msg.DecodePayload(&payload))
+	assert.Equal(t, services.RuleChangeUpdated, payload.Action)
+	assert.Equal(t, []uint{12, 13}, payload.RuleIDs)
+	assert.Equal(t, []uint{7}, payload.TeacherIDs)
+	assert.Equal(t, "FUTURE", payload.UpdateMode)
+
+	assert.Error(t, services.DomainEventMessage{}.DecodePayload(&payload))
+}
+
+// TestLocalDomainEventPublisher 程序內轉送依序呼叫消費端，單一消費端失敗不影響其他消費端
+func TestLocalDomainEventPublisher(t *testing.T) {
+	msg := services.DomainEventMessage{EventID: "evt-2", EventType: models.DomainEventExceptionReviewed}
+
+	t.Run("全部成功", func(t *testing.T) {
+		var calls []string
+		publisher := services.NewLocalDomainEventPublisher(
+			recordingDomainEventHandler{name: "cache", calls: &calls},
+			recordingDomainEventHandler{name: "notification", calls: &calls},
+		)
+		assert.NoError(t, publisher.Publish(context.Background(), msg))
+		assert.Equal(t, []string{"cache:ExceptionReviewed", "notification:ExceptionReviewed"}, calls)
+	})
+
+	t.Run("失敗後仍呼叫後續消費端並合併錯誤", func(t *testing.T) {
+		var calls []string
+		publisher := services.NewLocalDomainEventPublisher(
+			recordingDomainEventHandler{name: "cache", err: errors.New("redis down"), calls: &calls},
+			recordingDomainEventHandler{name: "notification", err: errors.New("line down"), calls: &calls},
+			recordingDomainEventHandler{name: "webhook", calls: &calls},
+		)
+		err := publisher.Publish(context.Background(), msg)
+		assert.ErrorContains(t, err, "cache: redis down")
+		assert.ErrorContains(t, err, "notification: line down")
+		assert.Equal(t, []string{"cache:ExceptionReviewed", "notification:ExceptionReviewed", "webhook:ExceptionReviewed"}, calls)
+	})
+}
+
+// TestDeliveryOnce 未設定去重時直接發送，設定時由去重函式決定是否發送
+func TestDeliveryOnce(t *testing.T) {
+	sends := 0
+	send := func() error {
+		sends++
+		return nil
+	}
+
+	var none services.DeliveryOnce
+	assert.NoError(t, none.Do(context.Background(), "admin:1:line", send))
+	assert.NoError(t, none.Do(context.Background(), "admin:1:line", send))
+	assert.Equal(t, 2, sends)
+
+	delivered := map[string]bool{}
+	once := services.DeliveryOnce(func(ctx context.Context, recipient string, send func() error) error {
+		if delivered[recipient] {
+			return nil
+		}
+		if err := send(); err != nil {
+			return err
+		}
+		delivered[recipient] = true
+		return nil
+	})
+	sends = 0
+	assert.NoError(t, once.Do(context.Background(), "admin:1:line", send))
+	assert.NoError(t, once.Do(context.Background(), "admin:1:line", send))
+	assert.NoError(t, once.Do(context.Background(), "group:G1:EXCEPTION_SUBMIT", send))
+	assert.Equal(t, 2, sends)
+}
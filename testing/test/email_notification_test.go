@@ -0,0 +1,198 @@
+package test
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"timeLedger/app/models"
+	"timeLedger/app/services"
+	"timeLedger/configs"
+	"timeLedger/libs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// smtpCatcher 本機 SMTP catcher，收下郵件內容；rejectRcpt 中的收件者回應 550
+type smtpCatcher struct {
+	listener   net.Listener
+	rejectRcpt map[string]bool
+
+	mu       sync.Mutex
+	messages []string
+}
+
+func newSMTPCatcher(t *testing.T, rejectRcpt ...string) *smtpCatcher {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+
+	c := &smtpCatcher{listener: ln, rejectRcpt: map[string]bool{}}
+	for _, rcpt := range rejectRcpt {
+		c.rejectRcpt[rcpt] = true
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go c.serve(conn)
+		}
+	}()
+	return c
+}
+
+func (c *smtpCatcher) port() string {
+	return strconv.Itoa(c.listener.Addr().(*net.TCPAddr).Port)
+}
+
+func (c *smtpCatcher) received() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.messages...)
+}
+
+func (c *smtpCatcher) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 catcher ready")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.ToUpper(strings.TrimSpace(line))
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250 catcher")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			addr := strings.Trim(strings.TrimSpace(line)[len("RCPT TO:"):], "<>")
+			if c.rejectRcpt[addr] {
+				reply("550 5.1.1 user unknown")
+			} else {
+				reply("250 ok")
+			}
+		case cmd == "DATA":
+			reply("354 go ahead")
+			var body strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				body.WriteString(l)
+			}
+			c.mu.Lock()
+			c.messages = append(c.messages, body.String())
+			c.mu.Unlock()
+			reply("250 queued")
+		case cmd == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 ok")
+		}
+	}
+}
+
+func newTestMailer(t *testing.T, catcher *smtpCatcher) *libs.SMTPMailer {
+	mailer, err := libs.NewSMTPMailer(&configs.Env{
+		SmtpEnabled:     true,
+		SmtpHost:        "127.0.0.1",
+		SmtpPort:        catcher.port(),
+		SmtpFromAddress: "noreply@timeledger.test",
+		SmtpFromName:    "TimeLedger",
+		SmtpTLSMode:     libs.SMTPTLSNone,
+	})
+	assert.NoError(t, err)
+	assert.NotNil(t, mailer)
+	return mailer
+}
+
+// TestSMTPMailerSendsMultipartMessage HTML 與純文字版本一起送達 SMTP catcher
+func TestSMTPMailerSendsMultipartMessage(t *testing.T) {
+	catcher := newSMTPCatcher(t)
+	mailer := newTestMailer(t, catcher)
+
+	rendered, err := services.RenderEmailTemplate(models.NotificationEventBroadcast, services.EmailTemplateData{
+		RecipientName: "王老師",
+		CenterName:    "快樂音樂教室",
+		Title:         "颱風停課",
+		Message:       "明日全日停課",
+	})
+	assert.NoError(t, err)
+
+	err = mailer.Send(context.Background(), libs.MailMessage{
+		To:       "teacher@example.com",
+		ToName:   "王老師",
+		Subject:  rendered.Subject,
+		HTMLBody: rendered.HTMLBody,
+		TextBody: rendered.TextBody,
+		Headers:  map[string]string{"X-TimeLedger-Notification-ID": "42"},
+	})
+	assert.NoError(t, err)
+
+	messages := catcher.received()
+	if !assert.Len(t, messages, 1) {
+		return
+	}
+	assert.Contains(t, messages[0], "multipart/alternative")
+	assert.Contains(t, messages[0], "Content-Type: text/plain; charset=utf-8")
+	assert.Contains(t, messages[0], "Content-Type: text/html; charset=utf-8")
+	assert.Contains(t, messages[0], "X-Timeledger-Notification-Id: 42")
+}
+
+// TestSMTPMailerClassifiesBounce 5xx 拒收視為退信，不應重試
+func TestSMTPMailerClassifiesBounce(t *testing.T) {
+	catcher := newSMTPCatcher(t, "nobody@example.com")
+	mailer := newTestMailer(t, catcher)
+
+	err := mailer.Send(context.Background(), libs.MailMessage{
+		To:       "nobody@example.com",
+		Subject:  "test",
+		TextBody: "hello",
+	})
+	assert.Error(t, err)
+	assert.True(t, libs.IsPermanentMailError(err))
+	assert.Empty(t, catcher.received())
+
+	assert.False(t, libs.IsPermanentMailError(&libs.MailError{Code: 421}))
+}
+
+// TestRenderEmailTemplate 各通知類型產生主旨、HTML 與純文字內容
+func TestRenderEmailTemplate(t *testing.T) {
+	rendered, err := services.RenderEmailTemplate(models.NotificationEventExceptionSubmit, services.EmailTemplateData{
+		RecipientName: "陳主任",
+		Title:         "新的例外申請 - 王 老師",
+		Details:       []services.EmailDetail{{Label: "原因", Value: "<b>生病</b>"}},
+		ActionURL:     "https://app.example.com/admin/exceptions/7",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "[TimeLedger] 新的例外申請 - 王 老師", rendered.Subject)
+	assert.Contains(t, rendered.HTMLBody, "&lt;b&gt;生病&lt;/b&gt;", "HTML 內容需跳脫")
+	assert.Contains(t, rendered.HTMLBody, `href="https://app.example.com/admin/exceptions/7"`)
+	assert.Contains(t, rendered.TextBody, "原因：<b>生病</b>")
+	assert.Contains(t, rendered.TextBody, "查看詳情：https://app.example.com/admin/exceptions/7")
+
+	digest, err := services.RenderEmailTemplate(services.EmailTemplateDigest, services.EmailTemplateData{
+		Title: "今日通知彙整",
+		Items: []string{"新的例外申請 - 王老師", "新的例外申請 - 李老師"},
+	})
+	assert.NoError(t, err)
+	assert.Contains(t, digest.TextBody, "• 新的例外申請 - 李老師")
+
+	fallback, err := services.RenderEmailTemplate("UNKNOWN", services.EmailTemplateData{Title: "系統通知", Message: "內容"})
+	assert.NoError(t, err)
+	assert.Equal(t, "[TimeLedger] 系統通知", fallback.Subject)
+}
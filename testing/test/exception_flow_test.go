@@ -661,4 +661,91 @@ func TestExceptionFlowE2EReject(t *testing.T) {
 
 	t.Logf("=== E2E Reject Exception Flow Test PASSED ===")
 	t.Logf("流程驗證: 教師申請 -> 管理員拒絕 -> 課程正常產生")
-}
\ No newline at end of file
+}
+// TestApproveCancelExceptionWithOperatingHours 設定營業時間的中心仍可核准停課（停課以整天 00:00-00:00 表示）
+func TestApproveCancelExceptionWithOperatingHours(t *testing.T) {
+	appInstance := setupExceptionTestApp()
+	ctx := context.Background()
+	now := time.Now()
+	db := appInstance.MySQL.WDB.WithContext(ctx)
+
+	center := models.Center{
+		Name: fmt.Sprintf("Operating Hours Center %d", now.UnixNano()),
+		Settings: models.CenterSettings{
+			OperatingStartTime: "09:00",
+			OperatingEndTime:   "21:00",
+		},
+	}
+	if err := db.Create(&center).Error; err != nil {
+		t.Fatalf("Failed to create center: %v", err)
+	}
+	defer db.Delete(&center)
+
+	course := models.Course{CenterID: center.ID, Name: "Test Course", ColorHex: "#123456", CreatedAt: now, UpdatedAt: now}
+	if err := db.Create(&course).Error; err != nil {
+		t.Fatalf("Failed to create course: %v", err)
+	}
+	defer db.Unscoped().Delete(&course)
+
+	room := models.Room{CenterID: center.ID, Name: "Test Room", Capacity: 10, CreatedAt: now, UpdatedAt: now}
+	if err := db.Create(&room).Error; err != nil {
+		t.Fatalf("Failed to create room: %v", err)
+	}
+	defer db.Unscoped().Delete(&room)
+
+	offering := models.Offering{CenterID: center.ID, CourseID: course.ID, CreatedAt: now, UpdatedAt: now}
+	if err := db.Create(&offering).Error; err != nil {
+		t.Fatalf("Failed to create offering: %v", err)
+	}
+	defer db.Unscoped().Delete(&offering)
+
+	rule := models.ScheduleRule{
+		CenterID:   center.ID,
+		OfferingID: offering.ID,
+		RoomID:     room.ID,
+		Name:       fmt.Sprintf("Test Cancel %d", now.UnixNano()),
+		Weekday:    3,
+		StartTime:  "10:00",
+		EndTime:    "11:00",
+		Duration:   60,
+		EffectiveRange: models.DateRange{
+			StartDate: now.AddDate(0, 0, 1),
+			EndDate:   now.AddDate(0, 3, 0),
+		},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := db.Create(&rule).Error; err != nil {
+		t.Fatalf("Failed to create rule: %v", err)
+	}
+	defer db.Unscoped().Delete(&rule)
+
+	target := getNextWeekday(now.AddDate(0, 0, 1), time.Wednesday)
+	exception := models.ScheduleException{
+		CenterID:      center.ID,
+		RuleID:        rule.ID,
+		OriginalDate:  time.Date(target.Year(), target.Month(), target.Day(), 0, 0, 0, 0, time.UTC),
+		ExceptionType: "CANCEL",
+		Status:        "PENDING",
+		Reason:        "Test cancel with operating hours",
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if err := db.Create(&exception).Error; err != nil {
+		t.Fatalf("Failed to create exception: %v", err)
+	}
+	defer db.Unscoped().Delete(&exception)
+
+	exceptionSvc := services.NewScheduleExceptionService(appInstance)
+	if err := exceptionSvc.ReviewException(ctx, exception.ID, 1, "APPROVE", false, "approved"); err != nil {
+		t.Fatalf("停課不應因營業時間被拒絕: %v", err)
+	}
+
+	var updated models.ScheduleException
+	if err := appInstance.MySQL.RDB.WithContext(ctx).First(&updated, exception.ID).Error; err != nil {
+		t.Fatalf("Failed to query updated exception: %v", err)
+	}
+	if updated.Status != "APPROVED" {
+		t.Errorf("例外狀態應該是 APPROVED，但得到 %s", updated.Status)
+	}
+}
@@ -0,0 +1,155 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"timeLedger/app"
+	"timeLedger/app/services"
+	"timeLedger/database/redis"
+	"timeLedger/global"
+	"timeLedger/global/errInfos"
+	mockRedis "timeLedger/testing/redis"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// idempotencyCancelKey 測試用：handler 執行中取消請求 context，模擬用戶端中斷連線
+type idempotencyCancelKey struct{}
+
+func setupIdempotencyRouter(t *testing.T) (*gin.Engine, *int) {
+	rdb, mr, err := mockRedis.Initialize()
+	if err != nil {
+		t.Fatalf("Redis init error: %s", err.Error())
+	}
+	t.Cleanup(mr.Close)
+
+	appInstance := &app.App{
+		Err:   errInfos.Initialize(1),
+		Redis: &redis.Redis{DB0: rdb},
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	calls := 0
+	fakeAuth := func(c *gin.Context) {
+		c.Set(global.UserIDKey, uint(7))
+		if c.GetHeader("X-Test-User") == "other" {
+			c.Set(global.UserIDKey, uint(8))
+		}
+		c.Set(global.UserTypeKey, "TEACHER")
+	}
+	r.POST("/api/v1/teacher/exceptions", fakeAuth, services.IdempotencyMiddleware(services.NewIdempotencyService(appInstance)), func(c *gin.Context) {
+		calls++
+		if cancel, ok := c.Request.Context().Value(idempotencyCancelKey{}).(context.CancelFunc); ok {
+			cancel()
+		}
+		if c.GetHeader("X-Test-Fail") == "true" {
+			c.JSON(http.StatusInternalServerError, global.ApiResponse{Code: errInfos.SYSTEM_ERROR})
+			return
+		}
+		c.Header("ETag", `"1"`)
+		c.JSON(http.StatusCreated, global.ApiResponse{Message: "Created", Datas: map[string]int{"id": calls}})
+	})
+	return r, &calls
+}
+
+func sendIdempotent(r *gin.Engine, key, body string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/teacher/exceptions", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set(services.IdempotencyKeyHeader, key)
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// TestIdempotencyMiddleware 重送時重播第一次回應，不重複執行
+func TestIdempotencyMiddleware(t *testing.T) {
+	t.Run("相同 key 重播第一次回應", func(t *testing.T) {
+		r, calls := setupIdempotencyRouter(t)
+
+		first := sendIdempotent(r, "key-1", `{"rule_id":1,"type":"CANCEL"}`, nil)
+		assert.Equal(t, http.StatusCreated, first.Code)
+		assert.Empty(t, first.Header().Get(services.IdempotencyReplayedHeader))
+
+		// 欄位順序不同視為同一請求
+		second := sendIdempotent(r, "key-1", `{"type":"CANCEL", "rule_id":1}`, nil)
+		assert.Equal(t, http.StatusCreated, second.Code)
+		assert.Equal(t, "true", second.Header().Get(services.IdempotencyReplayedHeader))
+		assert.Equal(t, `"1"`, second.Header().Get("ETag"))
+		assert.JSONEq(t, first.Body.String(), second.Body.String())
+		assert.Equal(t, 1, *calls)
+	})
+
+	t.Run("相同 key 不同內容被拒絕", func(t *testing.T) {
+		r, calls := setupIdempotencyRouter(t)
+
+		sendIdempotent(r, "key-2", `{"rule_id":1}`, nil)
+		w := sendIdempotent(r, "key-2", `{"rule_id":2}`, nil)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Contains(t, w.Body.String(), "Idempotency-Key")
+		assert.Equal(t, 1, *calls)
+	})
+
+	t.Run("不同使用者與未帶 key 的請求各自執行", func(t *testing.T) {
+		r, calls := setupIdempotencyRouter(t)
+
+		sendIdempotent(r, "key-3", `{"rule_id":1}`, nil)
+		other := sendIdempotent(r, "key-3", `{"rule_id":1}`, map[string]string{"X-Test-User": "other"})
+		assert.Empty(t, other.Header().Get(services.IdempotencyReplayedHeader))
+
+		sendIdempotent(r, "", `{"rule_id":1}`, nil)
+		sendIdempotent(r, "", `{"rule_id":1}`, nil)
+		assert.Equal(t, 4, *calls)
+	})
+
+	t.Run("伺服器錯誤後可用同一 key 重試", func(t *testing.T) {
+		r, calls := setupIdempotencyRouter(t)
+
+		failed := sendIdempotent(r, "key-4", `{"rule_id":1}`, map[string]string{"X-Test-Fail": "true"})
+		assert.Equal(t, http.StatusInternalServerError, failed.Code)
+
+		retried := sendIdempotent(r, "key-4", `{"rule_id":1}`, nil)
+		assert.Equal(t, http.StatusCreated, retried.Code)
+		assert.Empty(t, retried.Header().Get(services.IdempotencyReplayedHeader))
+		assert.Equal(t, 2, *calls)
+	})
+
+	t.Run("用戶端中斷連線仍保存結果", func(t *testing.T) {
+		r, calls := setupIdempotencyRouter(t)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		ctx = context.WithValue(ctx, idempotencyCancelKey{}, cancel)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/teacher/exceptions", bytes.NewBufferString(`{"rule_id":1}`)).WithContext(ctx)
+		req.Header.Set(services.IdempotencyKeyHeader, "key-5")
+		r.ServeHTTP(httptest.NewRecorder(), req)
+
+		retried := sendIdempotent(r, "key-5", `{"rule_id":1}`, nil)
+		assert.Equal(t, http.StatusCreated, retried.Code)
+		assert.Equal(t, "true", retried.Header().Get(services.IdempotencyReplayedHeader))
+		assert.Equal(t, 1, *calls)
+	})
+}
+
+// TestIdempotencyFingerprint JSON 正規化後計算指紋
+func TestIdempotencyFingerprint(t *testing.T) {
+	assert.Equal(t,
+		services.IdempotencyFingerprint([]byte(`{"a":1,"b":[1,2]}`)),
+		services.IdempotencyFingerprint([]byte(" {\n \"b\": [1, 2], \"a\": 1 }")))
+	assert.NotEqual(t,
+		services.IdempotencyFingerprint([]byte(`{"b":[2,1],"a":1}`)),
+		services.IdempotencyFingerprint([]byte(`{"a":1,"b":[1,2]}`)))
+	assert.Equal(t,
+		services.IdempotencyFingerprint([]byte(`{"amount":12345678901234567890}`)),
+		services.IdempotencyFingerprint([]byte(`{ "amount": 12345678901234567890 }`)))
+	assert.Equal(t, services.IdempotencyFingerprint(nil), services.IdempotencyFingerprint([]byte("  ")))
+}
@@ -54,6 +54,7 @@ func setupIntegrationTestAppWithMigrations() (*app.App, *gorm.DB, func()) {
 		&models.CenterHoliday{},
 		&models.CenterInvitation{},
 		&models.PersonalEvent{},
+		&models.DomainEvent{},
 	); err != nil {
 		panic(fmt.Sprintf("AutoMigrate error: %s", err.Error()))
 	}
@@ -0,0 +1,176 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"timeLedger/app"
+	"timeLedger/app/controllers"
+	"timeLedger/app/models"
+	"timeLedger/app/services"
+	"timeLedger/database/redis"
+	"timeLedger/global/errInfos"
+	"timeLedger/libs"
+	mockRedis "timeLedger/testing/redis"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestBuildLineGroupAgendaText 課表文字標示停課與審核中場次，超過上限以「另 N 堂」表示
+func TestBuildLineGroupAgendaText(t *testing.T) {
+	loc := libs.GetTaiwanLocation()
+	date := time.Date(2026, 3, 2, 0, 0, 0, 0, loc)
+
+	empty := services.BuildLineGroupAgendaText("📋 A 中心 今日課表", date, nil)
+	assert.Contains(t, empty, "2026/03/02（週一）")
+	assert.Contains(t, empty, "今天沒有課程")
+
+	sessions := []services.ExpandedSchedule{
+		{StartTime: "09:00", EndTime: "10:00", OfferingName: "瑜珈", TeacherName: "王老師", RoomName: "A 教室"},
+		{StartTime: "10:00", EndTime: "11:00", OfferingName: "皮拉提斯", IsHoliday: true},
+		{StartTime: "13:00", EndTime: "14:00", OfferingName: "舞蹈", ExceptionInfo: &services.ExpandedException{Status: "PENDING"}},
+		{StartTime: "15:00", EndTime: "16:00", OfferingName: "拳擊", ExceptionInfo: &services.ExpandedException{Status: "REJECTED"}},
+	}
+	text := services.BuildLineGroupAgendaText("📋 A 中心 今日課表", date, sessions)
+	assert.Contains(t, text, "共 4 堂課")
+	assert.Contains(t, text, "09:00-10:00 瑜珈｜王老師｜A 教室")
+	assert.Contains(t, text, "10:00-11:00 皮拉提斯（停課）")
+	assert.Contains(t, text, "13:00-14:00 舞蹈（異動審核中）")
+	assert.True(t, strings.HasSuffix(text, "15:00-16:00 拳擊"))
+
+	many := make([]services.ExpandedSchedule, 45)
+	for i := range many {
+		many[i] = services.ExpandedSchedule{StartTime: "09:00", EndTime: "10:00", OfferingName: fmt.Sprintf("課程%d", i)}
+	}
+	text = services.BuildLineGroupAgendaText("課表", date, many)
+	assert.Contains(t, text, "課程39")
+	assert.NotContains(t, text, "課程40")
+	assert.True(t, strings.HasSuffix(text, "…另 5 堂"))
+}
+
+// TestIsEmergencyClosure 僅強制停課且落在近期的假日視為緊急停課
+func TestIsEmergencyClosure(t *testing.T) {
+	loc := libs.GetTaiwanLocation()
+	now := time.Date(2026, 3, 2, 23, 30, 0, 0, loc)
+	day := func(offset int) time.Time {
+		return time.Date(2026, 3, 2+offset, 0, 0, 0, 0, time.UTC)
+	}
+
+	assert.True(t, services.IsEmergencyClosure(models.CenterHoliday{Date: day(0), ForceCancel: true}, now))
+	assert.True(t, services.IsEmergencyClosure(models.CenterHoliday{Date: day(services.LineGroupClosureLookaheadDays - 1), ForceCancel: true}, now))
+	assert.False(t, services.IsEmergencyClosure(models.CenterHoliday{Date: day(services.LineGroupClosureLookaheadDays), ForceCancel: true}, now))
+	assert.False(t, services.IsEmergencyClosure(models.CenterHoliday{Date: day(-1), ForceCancel: true}, now))
+	assert.False(t, services.IsEmergencyClosure(models.CenterHoliday{Date: day(0)}, now))
+
+	text := services.BuildLineGroupClosureText("A 中心", models.CenterHoliday{Date: day(1), Name: "颱風停課", ForceCancel: true}, 6)
+	assert.Contains(t, text, "2026/03/03（週二）")
+	assert.Contains(t, text, "颱風停課")
+	assert.Contains(t, text, "6 堂課程全數停課")
+}
+
+// TestLineGroupSummaryDue 依群組設定時間判斷，當日已發送或關閉摘要則不發送
+func TestLineGroupSummaryDue(t *testing.T) {
+	loc := libs.GetTaiwanLocation()
+	now := time.Date(2026, 3, 2, 7, 45, 0, 0, loc)
+	group := models.LineGroup{NotifyDailySummary: true, DailySummaryTime: "07:30"}
+
+	assert.True(t, services.LineGroupSummaryDue(group, now))
+	assert.True(t, services.LineGroupSummaryDue(group, now.UTC()))
+	assert.False(t, services.LineGroupSummaryDue(group, now.Add(-20*time.Minute)))
+
+	sent := group
+	sent.LastSummaryDate = "2026-03-02"
+	assert.False(t, services.LineGroupSummaryDue(sent, now))
+
+	disabled := group
+	disabled.NotifyDailySummary = false
+	assert.False(t, services.LineGroupSummaryDue(disabled, now))
+	assert.False(t, disabled.Notifies(models.LineGroupNotifyDailySummary))
+
+	defaultTime := models.LineGroup{NotifyDailySummary: true}
+	assert.True(t, services.LineGroupSummaryDue(defaultTime, now))
+}
+
+// TestLineGroupGenerateLinkCode 驗證碼存入 Redis 並帶有效期限
+func TestLineGroupGenerateLinkCode(t *testing.T) {
+	rdb, mr, err := mockRedis.Initialize()
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer mr.Close()
+
+	appInstance := &app.App{Err: errInfos.Initialize(1), Redis: &redis.Redis{DB0: rdb}}
+	svc := services.NewLineGroupService(appInstance)
+
+	code, eInfo, err := svc.GenerateLinkCode(context.Background(), 3, 9)
+	if !assert.NoError(t, err) || !assert.Nil(t, eInfo) {
+		return
+	}
+	assert.Len(t, code.Code, 6)
+	assert.True(t, code.ExpiresAt.After(time.Now()))
+
+	raw, err := mr.Get(services.LineGroupLinkCodeKey(strings.ToLower(code.Code)))
+	if !assert.NoError(t, err) {
+		return
+	}
+	var value map[string]uint
+	assert.NoError(t, json.Unmarshal([]byte(raw), &value))
+	assert.Equal(t, uint(3), value["center_id"])
+	assert.Equal(t, uint(9), value["admin_id"])
+	assert.Greater(t, mr.TTL(services.LineGroupLinkCodeKey(code.Code)), time.Duration(0))
+}
+
+// TestLineGroupLinkRateLimit 驗證碼錯誤次數過多時暫停嘗試，且不消耗有效的驗證碼
+func TestLineGroupLinkRateLimit(t *testing.T) {
+	rdb, mr, err := mockRedis.Initialize()
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer mr.Close()
+
+	appInstance := &app.App{Err: errInfos.Initialize(1), Redis: &redis.Redis{DB0: rdb}}
+	svc := services.NewLineGroupService(appInstance)
+	ctx := context.Background()
+	groupID := "C-guess"
+
+	for i := 0; i < services.LineGroupLinkMaxFailures; i++ {
+		_, eInfo, err := svc.LinkGroup(ctx, groupID, "ZZZZZZ")
+		assert.Error(t, err)
+		if assert.NotNil(t, eInfo) {
+			assert.Equal(t, appInstance.Err.New(errInfos.LINE_BINDING_CODE_INVALID).Code, eInfo.Code)
+		}
+	}
+	assert.Greater(t, mr.TTL(services.LineGroupLinkFailuresKey(groupID)), time.Duration(0))
+
+	code, _, err := svc.GenerateLinkCode(ctx, 3, 9)
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, eInfo, err := svc.LinkGroup(ctx, groupID, code.Code)
+	assert.Error(t, err)
+	if assert.NotNil(t, eInfo) {
+		assert.Equal(t, appInstance.Err.New(errInfos.RATE_LIMIT_EXCEEDED).Code, eInfo.Code)
+	}
+	assert.True(t, mr.Exists(services.LineGroupLinkCodeKey(code.Code)))
+
+	// 其他群組不受影響
+	_, eInfo, _ = svc.LinkGroup(ctx, "C-other", "ZZZZZZ")
+	if assert.NotNil(t, eInfo) {
+		assert.Equal(t, appInstance.Err.New(errInfos.LINE_BINDING_CODE_INVALID).Code, eInfo.Code)
+	}
+}
+
+// TestAdminUpdateLineGroupInvalidTime 每日摘要時間格式錯誤時回應 400 與具體原因
+func TestAdminUpdateLineGroupInvalidTime(t *testing.T) {
+	ctl := controllers.NewAdminLineGroupController(newOfflineTestApp())
+
+	w := serveAdminJSON(ctl.UpdateGroup, http.MethodPatch, "/admin/line-groups/1", "/admin/line-groups/:id",
+		`{"daily_summary_time":"25:99"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "daily_summary_time")
+}
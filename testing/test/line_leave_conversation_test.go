@@ -0,0 +1,135 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"timeLedger/app"
+	"timeLedger/app/services"
+	"timeLedger/database/redis"
+	"timeLedger/global/errInfos"
+	mockRedis "timeLedger/testing/redis"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSelectLineLeaveSessions 僅列出尚未開始、未申請例外的中心課程
+func TestSelectLineLeaveSessions(t *testing.T) {
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.Local)
+	items := []services.AgendaItem{
+		{Time: "09:00", Title: "已開始", SourceName: "A 中心", SourceType: services.AgendaSourceTypeCenter, RuleID: 1, CenterID: 1, Date: "2026-03-02"},
+		{Time: "11:00", Title: "個人行程", SourceName: "個人", SourceType: services.AgendaSourceTypePersonal},
+		{Time: "14:00", Title: "已申請", SourceName: "A 中心", SourceType: services.AgendaSourceTypeCenter, RuleID: 2, CenterID: 1, Date: "2026-03-02", HasException: true},
+		{Time: "15:00", Title: "瑜珈", SourceName: "A 中心", SourceType: services.AgendaSourceTypeCenter, RuleID: 3, CenterID: 1, Date: "2026-03-02"},
+		{Time: "08:00", Title: "皮拉提斯", SourceName: "B 中心", SourceType: services.AgendaSourceTypeCenter, RuleID: 4, CenterID: 2, Date: "2026-03-03"},
+		{Time: "09:00", Title: "超過上限", SourceName: "B 中心", SourceType: services.AgendaSourceTypeCenter, RuleID: 5, CenterID: 2, Date: "2026-03-03"},
+	}
+
+	sessions := services.SelectLineLeaveSessions(items, now, 2)
+	if !assert.Len(t, sessions, 2) {
+		return
+	}
+	assert.Equal(t, uint(3), sessions[0].RuleID)
+	assert.Equal(t, "A 中心", sessions[0].CenterName)
+	assert.Equal(t, "3/2(週一) 15:00 瑜珈", sessions[0].Label())
+	assert.Equal(t, uint(2), sessions[1].CenterID)
+}
+
+// TestBuildLineLeavePrompt 各步驟的 Quick Reply 皆為 Postback，並附上取消按鈕
+func TestBuildLineLeavePrompt(t *testing.T) {
+	session := services.LineLeaveSession{CenterID: 1, CenterName: "A 中心", RuleID: 3, Title: "非常非常長的課程名稱需要被截斷", Date: "2026-03-02", Time: "15:00"}
+	conv := &services.LineLeaveConversation{Step: services.LineLeaveStepSelectSession, Sessions: []services.LineLeaveSession{session}}
+
+	actions := quickReplyActions(t, services.BuildLineLeavePrompt(conv))
+	if !assert.Len(t, actions, 2) {
+		return
+	}
+	assert.Equal(t, "postback", actions[0]["type"])
+	assert.Equal(t, "action=leave_session&index=0", actions[0]["data"])
+	assert.LessOrEqual(t, len([]rune(actions[0]["label"].(string))), 20)
+	assert.Equal(t, "action=leave_cancel", actions[1]["data"])
+
+	conv.Step = services.LineLeaveStepSelectType
+	conv.Selected = &session
+	actions = quickReplyActions(t, services.BuildLineLeavePrompt(conv))
+	assert.Len(t, actions, len(services.LineLeaveTypes)+1)
+	assert.Equal(t, "action=leave_type&type=CANCEL", actions[0]["data"])
+
+	conv.Step = services.LineLeaveStepConfirm
+	conv.Type = "REPLACE_TEACHER"
+	conv.Reason = "家中有事"
+	message := services.BuildLineLeavePrompt(conv)
+	assert.Contains(t, message["text"], "找代課")
+	assert.Contains(t, message["text"], "家中有事")
+	assert.Equal(t, "action=leave_confirm", quickReplyActions(t, message)[0]["data"])
+}
+
+// TestLineLeaveConversationText 對話狀態存於 Redis，輸入原因後進入確認步驟，取消後清除
+func TestLineLeaveConversationText(t *testing.T) {
+	rdb, mr, err := mockRedis.Initialize()
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer mr.Close()
+
+	appInstance := &app.App{Err: errInfos.Initialize(1), Redis: &redis.Redis{DB0: rdb}}
+	svc := services.NewLineLeaveConversationService(appInstance)
+	ctx := context.Background()
+	lineUserID := "U-leave-test"
+
+	// 無進行中的對話時不攔截文字
+	_, handled, err := svc.HandleText(ctx, lineUserID, "家中有事")
+	assert.NoError(t, err)
+	assert.False(t, handled)
+
+	session := services.LineLeaveSession{CenterID: 1, CenterName: "A 中心", RuleID: 3, Title: "瑜珈", Date: "2026-03-02", Time: "15:00"}
+	payload, _ := json.Marshal(services.LineLeaveConversation{
+		Step:      services.LineLeaveStepEnterReason,
+		TeacherID: 7,
+		Sessions:  []services.LineLeaveSession{session},
+		Selected:  &session,
+		Type:      "CANCEL",
+	})
+	mr.Set(services.LineLeaveConversationKey(lineUserID), string(payload))
+
+	_, handled, err = svc.HandleText(ctx, lineUserID, "  家中有事  ")
+	assert.NoError(t, err)
+	assert.True(t, handled)
+
+	var conv services.LineLeaveConversation
+	raw, _ := mr.Get(services.LineLeaveConversationKey(lineUserID))
+	assert.NoError(t, json.Unmarshal([]byte(raw), &conv))
+	assert.Equal(t, services.LineLeaveStepConfirm, conv.Step)
+	assert.Equal(t, "家中有事", conv.Reason)
+	assert.Greater(t, mr.TTL(services.LineLeaveConversationKey(lineUserID)), time.Duration(0))
+
+	// 非請假動作的 Postback 不處理
+	_, handled, err = svc.HandlePostback(ctx, lineUserID, "action=other")
+	assert.NoError(t, err)
+	assert.False(t, handled)
+
+	_, handled, err = svc.HandlePostback(ctx, lineUserID, "action=leave_cancel")
+	assert.NoError(t, err)
+	assert.True(t, handled)
+	assert.False(t, mr.Exists(services.LineLeaveConversationKey(lineUserID)))
+
+	// 逾時後點擊按鈕提示重新開始
+	message, handled, err := svc.HandlePostback(ctx, lineUserID, "action=leave_confirm")
+	assert.NoError(t, err)
+	assert.True(t, handled)
+	assert.Contains(t, message.(map[string]interface{})["text"], "逾時")
+}
+
+func quickReplyActions(t *testing.T, message map[string]interface{}) []map[string]interface{} {
+	quickReply, ok := message["quickReply"].(map[string]interface{})
+	if !assert.True(t, ok) {
+		return nil
+	}
+	var actions []map[string]interface{}
+	for _, item := range quickReply["items"].([]interface{}) {
+		actions = append(actions, item.(map[string]interface{})["action"].(map[string]interface{}))
+	}
+	return actions
+}
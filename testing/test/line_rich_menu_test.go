@@ -0,0 +1,239 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"timeLedger/app/services"
+	"timeLedger/configs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestLoadLineRichMenuConfig 內建設定可載入，且各角色皆有對應選單
+func TestLoadLineRichMenuConfig(t *testing.T) {
+	config, err := configs.LoadLineRichMenuConfig("")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "guest", config.MenuForRole(services.LineRichMenuRoleGuest).Key)
+	assert.True(t, config.MenuForRole(services.LineRichMenuRoleGuest).Default)
+	assert.Equal(t, "teacher", config.MenuForRole(services.LineRichMenuRoleTeacher).Key)
+	for _, role := range []string{"OWNER", "ADMIN", "STAFF"} {
+		assert.Equal(t, "admin", config.MenuForRole(role).Key, role)
+	}
+	assert.Nil(t, config.MenuForRole("UNKNOWN"))
+
+	base := func() configs.LineRichMenuDefinition {
+		return configs.LineRichMenuDefinition{
+			Key:         "guest",
+			Roles:       []string{"GUEST"},
+			Name:        "選單",
+			ChatBarText: "選單",
+			Size:        configs.LineRichMenuSize{Width: 2500, Height: 843},
+			Areas: []configs.LineRichMenuArea{{
+				Bounds: configs.LineRichMenuBounds{Width: 2500, Height: 843},
+				Action: configs.LineRichMenuAction{Type: "message", Text: "幫助"},
+			}},
+		}
+	}
+	assert.NoError(t, (&configs.LineRichMenuConfig{Menus: []configs.LineRichMenuDefinition{base()}}).Validate())
+
+	invalid := map[string]func(m *configs.LineRichMenuDefinition){
+		"尺寸比例":   func(m *configs.LineRichMenuDefinition) { m.Size.Height = 2000 },
+		"區塊超出範圍": func(m *configs.LineRichMenuDefinition) { m.Areas[0].Bounds.X = 1 },
+		"選單列文字過長": func(m *configs.LineRichMenuDefinition) {
+			m.ChatBarText = "一二三四五六七八九十一二三四五"
+		},
+		"顏色格式": func(m *configs.LineRichMenuDefinition) { m.Areas[0].Color = "green" },
+		"動作類型": func(m *configs.LineRichMenuDefinition) {
+			m.Areas[0].Action = configs.LineRichMenuAction{Type: "camera"}
+		},
+		"uri 未設定": func(m *configs.LineRichMenuDefinition) { m.Areas[0].Action = configs.LineRichMenuAction{Type: "uri"} },
+	}
+	for name, mutate := range invalid {
+		menu := base()
+		mutate(&menu)
+		assert.Error(t, (&configs.LineRichMenuConfig{Menus: []configs.LineRichMenuDefinition{menu}}).Validate(), name)
+	}
+
+	// 同一角色不可對應兩個選單
+	other := base()
+	other.Key = "other"
+	assert.Error(t, (&configs.LineRichMenuConfig{Menus: []configs.LineRichMenuDefinition{base(), other}}).Validate())
+}
+
+// TestBuildLineRichMenuRequest uri 動作代入變數，且雜湊隨內容變動
+func TestBuildLineRichMenuRequest(t *testing.T) {
+	config, _ := configs.LoadLineRichMenuConfig("")
+	menu := *config.MenuForRole("ADMIN")
+
+	req := services.BuildLineRichMenuRequest(menu, map[string]string{"frontend_base_url": "https://timeledger.example.com"})
+	assert.Equal(t, "管理選單", req.ChatBarText)
+	assert.Len(t, req.Areas, len(menu.Areas))
+	assert.Equal(t, "https://timeledger.example.com/admin/approval", req.Areas[2].Action.URI)
+	assert.Equal(t, "{{frontend_base_url}}/admin/approval", menu.Areas[2].Action.URI) // 不修改原設定
+
+	body, _ := json.Marshal(req)
+	assert.Contains(t, string(body), `"chatBarText":"管理選單"`)
+	assert.NotContains(t, string(body), "color")
+
+	other := services.BuildLineRichMenuRequest(menu, map[string]string{"frontend_base_url": "https://staging.example.com"})
+	assert.NotEqual(t, services.LineRichMenuConfigHash(req, nil), services.LineRichMenuConfigHash(other, nil))
+	assert.Equal(t, services.LineRichMenuConfigHash(req, []byte("a")), services.LineRichMenuConfigHash(req, []byte("a")))
+}
+
+// TestRenderLineRichMenuImage 產生的圖片尺寸與選單一致，各區塊填入設定顏色
+func TestRenderLineRichMenuImage(t *testing.T) {
+	config, _ := configs.LoadLineRichMenuConfig("")
+	menu := *config.MenuForRole(services.LineRichMenuRoleTeacher)
+
+	data, contentType, err := services.LoadLineRichMenuImage(menu)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "image/png", contentType)
+	assert.Less(t, len(data), 1024*1024) // LINE 限制 1MB
+
+	img, err := png.Decode(bytes.NewReader(data))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 2500, img.Bounds().Dx())
+	assert.Equal(t, 1686, img.Bounds().Dy())
+
+	for _, area := range menu.Areas {
+		r, g, b, _ := img.At(area.Bounds.X+area.Bounds.Width/2, area.Bounds.Y+area.Bounds.Height/2).RGBA()
+		assert.Equal(t, area.Color, fmt.Sprintf("#%02X%02X%02X", r>>8, g>>8, b>>8))
+	}
+}
+
+type stubLineRequest struct {
+	Method      string
+	Path        string
+	ContentType string
+	Auth        string
+	Body        []byte
+}
+
+// TestLineRichMenuClient 以 stub server 驗證各端點的路徑、授權與內容
+func TestLineRichMenuClient(t *testing.T) {
+	var mu sync.Mutex
+	var received []stubLineRequest
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		received = append(received, stubLineRequest{
+			Method:      r.Method,
+			Path:        r.URL.Path,
+			ContentType: r.Header.Get("Content-Type"),
+			Auth:        r.Header.Get("Authorization"),
+			Body:        body,
+		})
+		mu.Unlock()
+
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v2/bot/richmenu":
+			_, _ = w.Write([]byte(`{"richMenuId":"richmenu-123"}`))
+		case r.Method == http.MethodDelete && r.URL.Path == "/v2/bot/richmenu/richmenu-gone":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"Not found"}`))
+		case r.URL.Path == "/v2/bot/user/Ubad/richmenu/richmenu-123":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"message":"The user ID is invalid"}`))
+		default:
+			_, _ = w.Write([]byte(`{}`))
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	client := services.NewLineRichMenuClient(server.URL, server.URL+"/data", "test-token")
+
+	config, _ := configs.LoadLineRichMenuConfig("")
+	menu := *config.MenuForRole(services.LineRichMenuRoleTeacher)
+	id, err := client.CreateRichMenu(ctx, services.BuildLineRichMenuRequest(menu, nil))
+	assert.NoError(t, err)
+	assert.Equal(t, "richmenu-123", id)
+
+	assert.NoError(t, client.UploadImage(ctx, id, "image/png", []byte("png")))
+	assert.NoError(t, client.SetDefaultRichMenu(ctx, id))
+	assert.NoError(t, client.LinkUser(ctx, "U1", id))
+	assert.NoError(t, client.UnlinkUser(ctx, "U1"))
+	assert.NoError(t, client.DeleteRichMenu(ctx, "richmenu-gone")) // 已不存在視為成功
+
+	users := make([]string, 501)
+	for i := range users {
+		users[i] = fmt.Sprintf("U%d", i)
+	}
+	assert.NoError(t, client.BulkLink(ctx, id, users))
+	assert.NoError(t, client.BulkUnlink(ctx, []string{"U1", "U2"}))
+	assert.NoError(t, client.BulkUnlink(ctx, nil)) // 無使用者時不送出請求
+
+	err = client.LinkUser(ctx, "Ubad", id)
+	var apiErr *services.LineAPIError
+	if assert.True(t, errors.As(err, &apiErr)) {
+		assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+		assert.Equal(t, "The user ID is invalid", apiErr.Message)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if !assert.Len(t, received, 10) {
+		return
+	}
+	for _, r := range received {
+		assert.Equal(t, "Bearer test-token", r.Auth)
+	}
+
+	assert.Equal(t, "application/json", received[0].ContentType)
+	var created map[string]interface{}
+	_ = json.Unmarshal(received[0].Body, &created)
+	assert.Equal(t, "老師選單", created["chatBarText"])
+	assert.Len(t, created["areas"], 6)
+
+	expected := []struct{ method, path string }{
+		{http.MethodPost, "/v2/bot/richmenu"},
+		{http.MethodPost, "/data/v2/bot/richmenu/richmenu-123/content"},
+		{http.MethodPost, "/v2/bot/user/all/richmenu/richmenu-123"},
+		{http.MethodPost, "/v2/bot/user/U1/richmenu/richmenu-123"},
+		{http.MethodDelete, "/v2/bot/user/U1/richmenu"},
+		{http.MethodDelete, "/v2/bot/richmenu/richmenu-gone"},
+		{http.MethodPost, "/v2/bot/richmenu/bulk/link"},
+		{http.MethodPost, "/v2/bot/richmenu/bulk/link"},
+		{http.MethodPost, "/v2/bot/richmenu/bulk/unlink"},
+		{http.MethodPost, "/v2/bot/user/Ubad/richmenu/richmenu-123"},
+	}
+	for i, e := range expected {
+		assert.Equal(t, e.method, received[i].Method, e.path)
+		assert.Equal(t, e.path, received[i].Path)
+	}
+	assert.Equal(t, "image/png", received[1].ContentType)
+	assert.Equal(t, []byte("png"), received[1].Body)
+
+	// 501 位使用者分兩批送出
+	var first, second struct {
+		RichMenuID string   `json:"richMenuId"`
+		UserIDs    []string `json:"userIds"`
+	}
+	_ = json.Unmarshal(received[6].Body, &first)
+	_ = json.Unmarshal(received[7].Body, &second)
+	assert.Equal(t, "richmenu-123", first.RichMenuID)
+	assert.Len(t, first.UserIDs, 500)
+	assert.Equal(t, []string{"U500"}, second.UserIDs)
+
+	var unlink map[string]interface{}
+	_ = json.Unmarshal(received[8].Body, &unlink)
+	assert.Equal(t, map[string]interface{}{"userIds": []interface{}{"U1", "U2"}}, unlink)
+}
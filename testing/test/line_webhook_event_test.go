@@ -0,0 +1,69 @@
+package test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"timeLedger/app/models"
+	"timeLedger/app/services"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParseLineWebhookEvent 以 webhookEventId 識別事件並保留來源與重送標記
+func TestParseLineWebhookEvent(t *testing.T) {
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	raw := json.RawMessage(`{
+		"type": "follow",
+		"mode": "active",
+		"timestamp": 1772445600000,
+		"webhookEventId": "01HQZ8Y4T3R2M1N0P9Q8R7S6T5",
+		"deliveryContext": {"isRedelivery": true},
+		"source": {"type": "user", "userId": "U123"},
+		"replyToken": "reply-token"
+	}`)
+
+	event, err := services.ParseLineWebhookEvent(raw, now)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "01HQZ8Y4T3R2M1N0P9Q8R7S6T5", event.WebhookEventID)
+	assert.Equal(t, "follow", event.EventType)
+	assert.Equal(t, "user", event.SourceType)
+	assert.Equal(t, "U123", event.SourceUserID)
+	assert.Empty(t, event.SourceGroupID)
+	assert.True(t, event.IsRedelivery)
+	assert.Equal(t, models.LineWebhookEventPending, event.Status)
+	assert.Equal(t, now, event.NextAttemptAt)
+	assert.Equal(t, int64(1772445600000), event.OccurredAt.UnixMilli())
+	assert.NotContains(t, event.Payload, "\n") // 原始事件壓縮後保存
+	assert.True(t, json.Valid([]byte(event.Payload)))
+
+	group, err := services.ParseLineWebhookEvent(json.RawMessage(`{"type":"message","webhookEventId":"E1","source":{"type":"group","groupId":"C1","userId":"U1"}}`), now)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "C1", group.SourceGroupID)
+	assert.Equal(t, "U1", group.SourceUserID)
+	assert.False(t, group.IsRedelivery)
+	assert.Equal(t, now, group.OccurredAt) // 未帶 timestamp 時以收到時間為準
+
+	_, err = services.ParseLineWebhookEvent(json.RawMessage(`{"type":`), now)
+	assert.Error(t, err)
+}
+
+// TestParseLineWebhookEventWithoutID 未帶 webhookEventId 時，相同內容得到相同識別碼
+func TestParseLineWebhookEventWithoutID(t *testing.T) {
+	now := time.Now()
+	first, err := services.ParseLineWebhookEvent(json.RawMessage(`{"type":"follow","timestamp":1,"source":{"type":"user","userId":"U1"}}`), now)
+	if !assert.NoError(t, err) {
+		return
+	}
+	again, _ := services.ParseLineWebhookEvent(json.RawMessage(`{ "type": "follow", "timestamp": 1, "source": {"type": "user", "userId": "U1"} }`), now)
+	other, _ := services.ParseLineWebhookEvent(json.RawMessage(`{"type":"follow","timestamp":2,"source":{"type":"user","userId":"U1"}}`), now)
+
+	assert.Len(t, first.WebhookEventID, 64)
+	assert.Equal(t, first.WebhookEventID, again.WebhookEventID)
+	assert.NotEqual(t, first.WebhookEventID, other.WebhookEventID)
+}
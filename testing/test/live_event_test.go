@@ -0,0 +1,81 @@
+package test
+
+import (
+	"testing"
+
+	"timeLedger/app/services"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestBuildLiveEventReplay 斷線重連：補送缺漏事件，超出保留範圍時要求重新同步
+func TestBuildLiveEventReplay(t *testing.T) {
+	retained := []services.LiveEvent{
+		{Seq: 8, Type: services.LiveEventRuleCreated},
+		{Seq: 9, Type: services.LiveEventExceptionSubmitted},
+		{Seq: 10, Type: services.LiveEventHolidayChanged},
+	}
+
+	t.Run("新連線不補送", func(t *testing.T) {
+		replay := services.BuildLiveEventReplay(retained, -1, 10)
+		assert.Empty(t, replay.Events)
+		assert.False(t, replay.ResyncRequired)
+		assert.Equal(t, int64(10), replay.LatestSeq)
+	})
+
+	t.Run("已是最新序號", func(t *testing.T) {
+		replay := services.BuildLiveEventReplay(retained, 10, 10)
+		assert.Empty(t, replay.Events)
+		assert.False(t, replay.ResyncRequired)
+	})
+
+	t.Run("補送之後的事件", func(t *testing.T) {
+		replay := services.BuildLiveEventReplay(retained, 8, 10)
+		assert.False(t, replay.ResyncRequired)
+		if assert.Len(t, replay.Events, 2) {
+			assert.Equal(t, int64(9), replay.Events[0].Seq)
+			assert.Equal(t, int64(10), replay.Events[1].Seq)
+		}
+	})
+
+	t.Run("事件已被裁切需重新同步", func(t *testing.T) {
+		replay := services.BuildLiveEventReplay(retained, 5, 10)
+		assert.True(t, replay.ResyncRequired)
+		assert.Empty(t, replay.Events)
+	})
+
+	t.Run("紀錄已過期需重新同步", func(t *testing.T) {
+		replay := services.BuildLiveEventReplay(nil, 3, 10)
+		assert.True(t, replay.ResyncRequired)
+	})
+
+	t.Run("序號大於目前值（Redis 重置）需重新同步", func(t *testing.T) {
+		replay := services.BuildLiveEventReplay(retained, 42, 10)
+		assert.True(t, replay.ResyncRequired)
+		assert.Equal(t, int64(10), replay.LatestSeq)
+	})
+}
+
+// TestFillLiveEventGap 序號不連續時從事件紀錄補齊，缺號則無法補齊
+func TestFillLiveEventGap(t *testing.T) {
+	retained := []services.LiveEvent{{Seq: 4}, {Seq: 5}, {Seq: 6}, {Seq: 8}, {Seq: 9}}
+
+	events, ok := services.FillLiveEventGap(retained, 3, 7)
+	assert.True(t, ok)
+	if assert.Len(t, events, 3) {
+		assert.Equal(t, int64(4), events[0].Seq)
+		assert.Equal(t, int64(6), events[2].Seq)
+	}
+
+	// 7 發佈失敗未寫入紀錄
+	_, ok = services.FillLiveEventGap(retained, 6, 9)
+	assert.False(t, ok)
+
+	// 尚未寫入紀錄（並行發佈中）
+	_, ok = services.FillLiveEventGap(retained, 9, 11)
+	assert.False(t, ok)
+
+	events, ok = services.FillLiveEventGap(retained, 5, 6)
+	assert.True(t, ok)
+	assert.Empty(t, events)
+}
@@ -0,0 +1,205 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"timeLedger/app/models"
+	"timeLedger/app/services"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCalculateSessionQuota 學期堂數與尚欠補課計算
+func TestCalculateSessionQuota(t *testing.T) {
+	loc := time.UTC
+	date := func(s string) time.Time {
+		d, _ := time.ParseInLocation("2006-01-02", s, loc)
+		return d
+	}
+
+	// 2026-03-02 ~ 2026-03-29 共 4 個週一
+	rule := models.ScheduleRule{
+		ID:          1,
+		Weekday:     1,
+		StartTime:   "10:00",
+		EndTime:     "11:00",
+		SkipHoliday: true,
+		Status:      models.RuleStatusConfirmed,
+		EffectiveRange: models.DateRange{
+			StartDate: date("2026-01-01"),
+			EndDate:   date("2026-12-31"),
+		},
+	}
+
+	baseInput := func() services.SessionQuotaInput {
+		return services.SessionQuotaInput{
+			TermStart: date("2026-03-01"),
+			TermEnd:   date("2026-03-29"),
+			Today:     date("2026-03-20"),
+			Rules:     []models.ScheduleRule{rule},
+		}
+	}
+
+	t.Run("NoCancellations", func(t *testing.T) {
+		summary := services.CalculateSessionQuota(baseInput())
+
+		assert.Equal(t, 4, summary.ScheduledSessions)
+		assert.Equal(t, 3, summary.DeliveredSessions)
+		assert.Equal(t, 1, summary.UpcomingSessions)
+		assert.Equal(t, 0, summary.CancelledSessions)
+		assert.Equal(t, 0, summary.OwedSessions)
+	})
+
+	t.Run("HolidayAndApprovedCancelAreOwed", func(t *testing.T) {
+		input := baseInput()
+		input.Holidays = []models.CenterHoliday{{Date: date("2026-03-09"), Name: "補假"}}
+		input.Exceptions = map[uint]map[string][]models.ScheduleException{
+			1: {
+				"2026-03-16": {{ID: 7, RuleID: 1, ExceptionType: "CANCEL", Status: "APPROVED", Reason: "老師請假"}},
+				"2026-03-23": {{ID: 8, RuleID: 1, ExceptionType: "CANCEL", Status: "PENDING"}},
+			},
+		}
+
+		summary := services.CalculateSessionQuota(input)
+
+		assert.Equal(t, 2, summary.CancelledSessions)
+		assert.Equal(t, 2, summary.OwedSessions)
+		if assert.Len(t, summary.Cancelled, 2) {
+			assert.Equal(t, models.SessionCancelReasonHoliday, summary.Cancelled[0].Reason)
+			assert.Equal(t, "補假", summary.Cancelled[0].ReasonDetail)
+			assert.Equal(t, models.SessionCancelReasonException, summary.Cancelled[1].Reason)
+			assert.Equal(t, uint(7), *summary.Cancelled[1].ExceptionID)
+		}
+		// 待審核的停課不列入取消
+		assert.Equal(t, 1, summary.UpcomingSessions)
+	})
+
+	t.Run("ScheduledMakeupReducesOwed", func(t *testing.T) {
+		input := baseInput()
+		input.Holidays = []models.CenterHoliday{{Date: date("2026-03-09"), Name: "補假"}}
+		input.Makeups = []models.MakeupSession{
+			{ID: 3, OriginalRuleID: 1, OriginalDate: date("2026-03-09"), MakeupDate: date("2026-03-28"), Status: models.MakeupStatusScheduled},
+			{ID: 4, OriginalRuleID: 1, OriginalDate: date("2026-03-09"), MakeupDate: date("2026-03-14"), Status: models.MakeupStatusCancelled},
+		}
+
+		summary := services.CalculateSessionQuota(input)
+
+		assert.Equal(t, 1, summary.CancelledSessions)
+		assert.Equal(t, 1, summary.MakeupScheduled)
+		assert.Equal(t, 0, summary.MakeupDelivered)
+		assert.Equal(t, 0, summary.OwedSessions)
+		if assert.Len(t, summary.Cancelled, 1) {
+			assert.Equal(t, uint(3), *summary.Cancelled[0].MakeupID)
+		}
+	})
+
+	t.Run("PlannedSessionsDriveOwed", func(t *testing.T) {
+		input := baseInput()
+		input.PlannedSessions = 6
+		input.Rules[0].SuspendedDates = models.SuspendedDates{date("2026-03-02")}
+
+		summary := services.CalculateSessionQuota(input)
+
+		assert.Equal(t, 1, summary.CancelledSessions)
+		assert.Equal(t, models.SessionCancelReasonSuspended, summary.Cancelled[0].Reason)
+		// 預計 6 堂，規則只排得出 3 堂
+		assert.Equal(t, 3, summary.OwedSessions)
+	})
+
+	t.Run("ForceCancelHolidayIgnoresSkipHoliday", func(t *testing.T) {
+		input := baseInput()
+		input.Rules[0].SkipHoliday = false
+		input.Holidays = []models.CenterHoliday{
+			{Date: date("2026-03-02"), Name: "一般假日"},
+			{Date: date("2026-03-09"), Name: "颱風停課", ForceCancel: true},
+		}
+
+		summary := services.CalculateSessionQuota(input)
+
+		assert.Equal(t, 1, summary.CancelledSessions)
+		assert.Equal(t, "颱風停課", summary.Cancelled[0].ReasonDetail)
+	})
+
+	t.Run("PlannedRulesAreIgnored", func(t *testing.T) {
+		input := baseInput()
+		input.Rules[0].Status = models.RuleStatusPlanned
+
+		summary := services.CalculateSessionQuota(input)
+
+		assert.Equal(t, 0, summary.ScheduledSessions)
+	})
+}
+
+// TestMakeupOverlapConflicts 已安排補課的老師與教室視為衝突
+func TestMakeupOverlapConflicts(t *testing.T) {
+	teacherID := uint(5)
+	makeup := models.MakeupSession{
+		ID:         9,
+		OfferingID: 3,
+		MakeupDate: time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC),
+		StartTime:  "10:00",
+		EndTime:    "11:00",
+		TeacherID:  &teacherID,
+		RoomID:     2,
+	}
+
+	conflicts := services.MakeupOverlapConflicts(makeup, []uint{5, 6}, []uint{2})
+	if !assert.Len(t, conflicts, 2) {
+		return
+	}
+	assert.Equal(t, "TEACHER_OVERLAP", conflicts[0].Type)
+	assert.Contains(t, conflicts[0].Details, "makeup_id:9")
+	assert.Equal(t, "ROOM_OVERLAP", conflicts[1].Type)
+
+	assert.Empty(t, services.MakeupOverlapConflicts(makeup, []uint{6}, []uint{3}))
+
+	makeup.TeacherID = nil
+	assert.Len(t, services.MakeupOverlapConflicts(makeup, []uint{5}, []uint{2}), 1)
+}
+
+// TestExpandMakeupSessions 補課以原規則的班別資訊展開為場次
+func TestExpandMakeupSessions(t *testing.T) {
+	leadID := uint(5)
+	substituteID := uint(6)
+	rule := models.ScheduleRule{
+		ID:         1,
+		OfferingID: 3,
+		TeacherID:  &leadID,
+		RoomID:     2,
+		Status:     models.RuleStatusConfirmed,
+		Offering:   models.Offering{Name: "鋼琴初級"},
+		Teacher:    models.Teacher{Name: "王老師"},
+		Room:       models.Room{Name: "A 教室"},
+	}
+	makeupDate := time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC)
+	makeups := []models.MakeupSession{
+		{ID: 9, OriginalRuleID: 1, MakeupDate: makeupDate, StartTime: "14:00", EndTime: "15:00", TeacherID: &leadID, RoomID: 2},
+		{ID: 10, OriginalRuleID: 1, MakeupDate: makeupDate, StartTime: "16:00", EndTime: "17:00", TeacherID: &substituteID, RoomID: 4},
+		{ID: 11, OriginalRuleID: 99, MakeupDate: makeupDate, StartTime: "10:00", EndTime: "11:00", RoomID: 2},
+	}
+
+	schedules := services.ExpandMakeupSessions([]models.ScheduleRule{rule}, makeups)
+	if !assert.Len(t, schedules, 2) {
+		return
+	}
+
+	first := schedules[0]
+	if assert.NotNil(t, first.MakeupID) {
+		assert.Equal(t, uint(9), *first.MakeupID)
+	}
+	assert.Equal(t, uint(1), first.RuleID)
+	assert.Equal(t, makeupDate, first.Date)
+	assert.Equal(t, "14:00", first.StartTime)
+	assert.Equal(t, "鋼琴初級", first.OfferingName)
+	assert.Equal(t, "王老師", first.TeacherName)
+	assert.Equal(t, "A 教室", first.RoomName)
+	assert.Equal(t, []uint{5}, first.AllTeacherIDs())
+
+	// 改由其他老師、教室補課時，不沿用原規則的名稱
+	second := schedules[1]
+	assert.Equal(t, []uint{6}, second.AllTeacherIDs())
+	assert.Equal(t, []uint{4}, second.AllRoomIDs())
+	assert.Empty(t, second.TeacherName)
+	assert.Empty(t, second.RoomName)
+}
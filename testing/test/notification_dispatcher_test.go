@@ -0,0 +1,69 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"timeLedger/app"
+	"timeLedger/app/models"
+	"timeLedger/app/services"
+	"timeLedger/configs"
+	"timeLedger/database/redis"
+	mockRedis "timeLedger/testing/redis"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestNotificationRetryPolicyBackoff 依類型的指數退避與上限
+func TestNotificationRetryPolicyBackoff(t *testing.T) {
+	policy := services.NotificationRetryPolicyFor(models.NotificationTypeExceptionSubmit)
+	assert.Equal(t, 8, policy.MaxAttempts)
+	assert.Equal(t, 30*time.Second, policy.Backoff(1))
+	assert.Equal(t, 60*time.Second, policy.Backoff(2))
+	assert.Equal(t, 4*time.Minute, policy.Backoff(4))
+	assert.Equal(t, 30*time.Minute, policy.Backoff(20), "capped at MaxDelay")
+
+	reminder := services.NotificationRetryPolicyFor(models.NotificationEventReminder)
+	assert.Less(t, reminder.MaxAttempts, policy.MaxAttempts, "stale reminders give up sooner")
+	assert.Equal(t, 2*time.Minute, reminder.Backoff(10))
+
+	fallback := services.NotificationRetryPolicyFor("unknown_type")
+	assert.Equal(t, 5, fallback.MaxAttempts)
+	assert.Equal(t, time.Minute, fallback.Backoff(0))
+}
+
+// TestNewNotificationBackend 依設定選擇佇列後端，無 Redis 時使用資料庫輪詢
+func TestNewNotificationBackend(t *testing.T) {
+	assert.Equal(t, services.NotificationBackendDatabase,
+		services.NewNotificationBackend(&app.App{Env: &configs.Env{}}, services.NotificationBackendRedis).Name())
+
+	rdb, mr, err := mockRedis.Initialize()
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer mr.Close()
+	appInstance := &app.App{Env: &configs.Env{}, Redis: &redis.Redis{DB0: rdb}}
+
+	assert.Equal(t, services.NotificationBackendDatabase, services.NewNotificationBackend(appInstance, "").Name())
+	assert.Equal(t, services.NotificationBackendDatabase, services.NewNotificationBackend(appInstance, "kafka").Name())
+
+	backend := services.NewNotificationBackend(appInstance, services.NotificationBackendRedis)
+	assert.Equal(t, services.NotificationBackendRedis, backend.Name())
+
+	// Redis 佇列只帶 notification_queues 的 ID，內容由派送器從資料表讀取
+	err = backend.Enqueue(context.Background(), &models.NotificationQueue{
+		ID:            42,
+		Type:          models.NotificationTypeWelcomeAdmin,
+		RecipientID:   7,
+		RecipientType: "ADMIN",
+		Payload:       `{"type":"text","text":"hi"}`,
+	})
+	assert.NoError(t, err)
+
+	item, err := services.NewRedisQueueService(appInstance).PopNotification(context.Background())
+	if assert.NoError(t, err) && assert.NotNil(t, item) {
+		assert.Equal(t, uint(42), item.ID)
+		assert.Empty(t, item.Payload)
+	}
+}
@@ -0,0 +1,90 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"timeLedger/app/models"
+	"timeLedger/app/services"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestPlanNotificationDeliveryChannels 預設與自訂的事件管道
+func TestPlanNotificationDeliveryChannels(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Taipei")
+	noon := time.Date(2026, 3, 4, 12, 0, 0, 0, loc)
+
+	pref := services.DefaultNotificationPreference("TEACHER", 1, "Asia/Taipei")
+	assert.Equal(t, services.NotificationDeliverNow,
+		services.PlanNotificationDelivery(pref, models.NotificationEventBroadcast, models.NotificationChannelLine, noon, false).Action)
+	assert.Equal(t, services.NotificationDeliverSkip,
+		services.PlanNotificationDelivery(pref, models.NotificationEventBroadcast, models.NotificationChannelEmail, noon, false).Action,
+		"email is opt-in")
+
+	pref.Channels[models.NotificationEventBroadcast] = []string{models.NotificationChannelInApp}
+	assert.Equal(t, services.NotificationDeliverSkip,
+		services.PlanNotificationDelivery(pref, models.NotificationEventBroadcast, models.NotificationChannelLine, noon, false).Action)
+	assert.Equal(t, services.NotificationDeliverNow,
+		services.PlanNotificationDelivery(pref, models.NotificationEventBroadcast, models.NotificationChannelInApp, noon, false).Action)
+
+	// 系統通知不受偏好影響
+	pref.Channels[models.NotificationEventBroadcast] = []string{}
+	assert.Equal(t, services.NotificationDeliverNow,
+		services.PlanNotificationDelivery(pref, "", models.NotificationChannelLine, noon, false).Action)
+}
+
+// TestPlanNotificationDeliveryQuietHours 跨日勿擾時段延後至結束時間
+func TestPlanNotificationDeliveryQuietHours(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Taipei")
+	pref := services.DefaultNotificationPreference("TEACHER", 1, "Asia/Taipei")
+	pref.QuietHoursEnabled = true
+	pref.QuietHoursStart = "22:00"
+	pref.QuietHoursEnd = "07:30"
+
+	late := time.Date(2026, 3, 4, 23, 15, 0, 0, loc)
+	plan := services.PlanNotificationDelivery(pref, models.NotificationEventReminder, models.NotificationChannelLine, late, false)
+	assert.Equal(t, services.NotificationDeliverDefer, plan.Action)
+	assert.True(t, plan.DeliverAt.Equal(time.Date(2026, 3, 5, 7, 30, 0, 0, loc)))
+
+	early := time.Date(2026, 3, 5, 6, 0, 0, 0, loc)
+	plan = services.PlanNotificationDelivery(pref, models.NotificationEventReminder, models.NotificationChannelLine, early.UTC(), false)
+	assert.Equal(t, services.NotificationDeliverDefer, plan.Action)
+	assert.True(t, plan.DeliverAt.Equal(time.Date(2026, 3, 5, 7, 30, 0, 0, loc)))
+
+	morning := time.Date(2026, 3, 5, 7, 30, 0, 0, loc)
+	assert.Equal(t, services.NotificationDeliverNow,
+		services.PlanNotificationDelivery(pref, models.NotificationEventReminder, models.NotificationChannelLine, morning, false).Action)
+
+	// 站內通知與緊急通知不延後
+	assert.Equal(t, services.NotificationDeliverNow,
+		services.PlanNotificationDelivery(pref, models.NotificationEventReminder, models.NotificationChannelInApp, late, false).Action)
+	assert.Equal(t, services.NotificationDeliverNow,
+		services.PlanNotificationDelivery(pref, models.NotificationEventBroadcast, models.NotificationChannelLine, late, true).Action)
+}
+
+// TestPlanNotificationDeliveryDigest 每日彙整不包含上課提醒
+func TestPlanNotificationDeliveryDigest(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Taipei")
+	pref := services.DefaultNotificationPreference("ADMIN", 2, "Asia/Taipei")
+	pref.DigestEnabled = true
+	pref.DigestTime = "18:00"
+
+	afternoon := time.Date(2026, 3, 4, 15, 0, 0, 0, loc)
+	plan := services.PlanNotificationDelivery(pref, models.NotificationEventExceptionSubmit, models.NotificationChannelLine, afternoon, false)
+	assert.Equal(t, services.NotificationDeliverDigest, plan.Action)
+	assert.True(t, plan.DeliverAt.Equal(time.Date(2026, 3, 4, 18, 0, 0, 0, loc)))
+
+	evening := time.Date(2026, 3, 4, 18, 0, 0, 0, loc)
+	plan = services.PlanNotificationDelivery(pref, models.NotificationEventExceptionSubmit, models.NotificationChannelLine, evening, false)
+	assert.True(t, plan.DeliverAt.Equal(time.Date(2026, 3, 5, 18, 0, 0, 0, loc)))
+
+	assert.Equal(t, services.NotificationDeliverNow,
+		services.PlanNotificationDelivery(pref, models.NotificationEventReminder, models.NotificationChannelLine, afternoon, false).Action)
+
+	digest := services.BuildNotificationDigest([]services.HeldNotificationPayload{
+		{Title: "新的例外申請 - 王老師"},
+		{AltText: "新的例外申請 - 李老師"},
+	})
+	assert.Equal(t, "• 新的例外申請 - 王老師\n• 新的例外申請 - 李老師", digest)
+}
@@ -0,0 +1,120 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"timeLedger/app/controllers"
+	"timeLedger/app/models"
+	"timeLedger/app/services"
+	"timeLedger/libs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestInterpolate 僅替換 {{變數}}，值中的括號不會再被解析
+func TestInterpolate(t *testing.T) {
+	vars := map[string]string{"teacher_name": "{{center_name}}", "center_name": "Yoga"}
+	assert.Equal(t, "老師：{{center_name}} / Yoga / ", libs.Interpolate("老師：{{teacher_name}} / {{ center_name }} / {{unknown}}", vars))
+
+	names, err := libs.TemplateVariables("{{teacher_name}} 於 {{date}}")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"teacher_name", "date"}, names)
+
+	_, err = libs.TemplateVariables("{{teacher_name}")
+	assert.Error(t, err)
+	_, err = libs.TemplateVariables("{{.Teacher.Name}}")
+	assert.Error(t, err)
+}
+
+// TestResolveNotificationTemplate 中心自訂優先，未自訂欄位依語系使用內建預設
+func TestResolveNotificationTemplate(t *testing.T) {
+	builtin := services.ResolveNotificationTemplate(models.NotificationTemplateExceptionApproved, models.NotificationChannelLine, models.LocaleEn, nil)
+	assert.Equal(t, "📅 Date: 2026/03/02", builtin.Text("date", map[string]string{"date": "2026/03/02"}))
+	assert.Equal(t, "#4CAF50", builtin.Color("title"))
+
+	override := &models.NotificationTemplate{
+		Texts:    models.NotificationTemplateValues{"button": "Open app"},
+		Colors:   models.NotificationTemplateValues{"title": "#123456"},
+		IsActive: true,
+	}
+	resolved := services.ResolveNotificationTemplate(models.NotificationTemplateExceptionApproved, models.NotificationChannelLine, models.LocaleEn, override)
+	assert.Equal(t, "Open app", resolved.Text("button", nil))
+	assert.Equal(t, "#123456", resolved.Color("title"))
+	assert.Equal(t, "📅 Date: 2026/03/02", resolved.Text("date", map[string]string{"date": "2026/03/02"}))
+
+	// 停用的自訂範本不套用
+	override.IsActive = false
+	disabled := services.ResolveNotificationTemplate(models.NotificationTemplateExceptionApproved, models.NotificationChannelLine, models.LocaleEn, override)
+	assert.Equal(t, "View details", disabled.Text("button", nil))
+
+	assert.Equal(t, models.LocaleEn, services.NormalizeNotificationLocale("en-US"))
+	assert.Equal(t, models.LocaleZhTW, services.NormalizeNotificationLocale("zh-TW"))
+	assert.Equal(t, models.LocaleZhTW, services.NormalizeNotificationLocale(""))
+}
+
+// TestValidateNotificationTemplate 欄位、變數與顏色檢查
+func TestValidateNotificationTemplate(t *testing.T) {
+	def, ok := services.FindNotificationTemplateDefinition(models.NotificationTemplateExceptionSubmit, models.NotificationChannelLine)
+	if !assert.True(t, ok) {
+		return
+	}
+
+	assert.NoError(t, services.ValidateNotificationTemplate(def,
+		map[string]string{"title": "📣 {{teacher_name}} 的{{exception_type}}"},
+		map[string]string{"title": "#1E88E5", "button": ""}))
+	assert.Error(t, services.ValidateNotificationTemplate(def, map[string]string{"unknown": "x"}, nil))
+	assert.Error(t, services.ValidateNotificationTemplate(def, map[string]string{"title": "{{admin_name}}"}, nil))
+	assert.Error(t, services.ValidateNotificationTemplate(def, map[string]string{"title": "{{teacher_name"}, nil))
+	assert.Error(t, services.ValidateNotificationTemplate(def, nil, map[string]string{"title": "red"}))
+
+	_, ok = services.FindNotificationTemplateDefinition(models.NotificationTemplateExceptionSubmit, models.NotificationChannelEmail)
+	assert.False(t, ok)
+}
+
+// TestLocalizedLineTemplates Flex Message 依語系與中心自訂產生，預設輸出維持原本的繁體中文
+func TestLocalizedLineTemplates(t *testing.T) {
+	date := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	exception := &models.ScheduleException{ID: 5, ExceptionType: "LEAVE", OriginalDate: date}
+
+	builtin := services.NewLineBotTemplateService("https://timeledger.example.com")
+	body, _ := json.Marshal(builtin.GetExceptionSubmitTemplate(exception, "陳小美", "Yoga Space"))
+	assert.Contains(t, string(body), "🔔 新的請假申請")
+	assert.Contains(t, string(body), "👤 申請人：陳小美 老師")
+	assert.Contains(t, string(body), "📝 原因：未說明原因")
+
+	english := services.NewLocalizedLineBotTemplateService("https://timeledger.example.com", models.LocaleEn, []models.NotificationTemplate{{
+		Type:     models.NotificationTemplateExceptionSubmit,
+		Channel:  models.NotificationChannelLine,
+		Locale:   models.LocaleEn,
+		Texts:    models.NotificationTemplateValues{"button": "Open {{center_name}}"},
+		Colors:   models.NotificationTemplateValues{"button": "#1E88E5"},
+		IsActive: true,
+	}})
+	body, _ = json.Marshal(english.GetExceptionSubmitTemplate(exception, "Mei", "Yoga Space"))
+	assert.Contains(t, string(body), "🔔 New request: Leave")
+	assert.Contains(t, string(body), "Open Yoga Space")
+	assert.Contains(t, string(body), "#1E88E5")
+	assert.False(t, strings.Contains(string(body), "申請人"))
+
+	vars := english.ExceptionVariables(exception, "Mei", "Yoga Space", "")
+	assert.Equal(t, "New request from Mei", english.GetText(models.NotificationTemplateExceptionSubmit, "alt_text", vars))
+
+	for _, def := range services.NotificationTemplateDefinitions() {
+		_, contents := services.PreviewLineTemplate(english, def.Type, "Yoga Space")
+		assert.NotNil(t, contents, def.Type)
+	}
+}
+
+// TestAdminSaveNotificationTemplateInvalid 範本內容不符規定時回應 400 與具體原因
+func TestAdminSaveNotificationTemplateInvalid(t *testing.T) {
+	ctl := controllers.NewAdminNotificationTemplateController(newOfflineTestApp())
+
+	w := serveAdminJSON(ctl.SaveTemplate, http.MethodPut, "/admin/notification-templates/LINE/EXCEPTION_SUBMIT/zh-TW",
+		"/admin/notification-templates/:channel/:type/:locale", `{"colors":{"title":"red"}}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "title")
+}
@@ -0,0 +1,107 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"timeLedger/app/models"
+	"timeLedger/app/services"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestResolveOperatingHours 每週營業時間與日期區間調整
+func TestResolveOperatingHours(t *testing.T) {
+	date := func(s string) time.Time {
+		d, _ := time.Parse("2006-01-02", s)
+		return d
+	}
+
+	settings := &models.CenterSettings{
+		OperatingStartTime: "09:00",
+		OperatingEndTime:   "21:00",
+		WeeklyOperatingHours: []models.WeekdayOperatingHours{
+			{Weekday: 1, OpenTime: "14:00", CloseTime: "21:30"},
+			{Weekday: 6, OpenTime: "08:00", CloseTime: "20:00"},
+			{Weekday: 7, Closed: true},
+		},
+	}
+
+	t.Run("WeeklyHours", func(t *testing.T) {
+		// 2026-03-02 為週一
+		hours := services.ResolveOperatingHours(settings, nil, date("2026-03-02"))
+		assert.Equal(t, "14:00", hours.OpenTime)
+		assert.Equal(t, "21:30", hours.CloseTime)
+		assert.Equal(t, services.OperatingHoursSourceWeekly, hours.Source)
+
+		sunday := services.ResolveOperatingHours(settings, nil, date("2026-03-08"))
+		assert.True(t, sunday.Closed)
+	})
+
+	t.Run("FallbackToDefault", func(t *testing.T) {
+		// 週三未設定，沿用預設營業時間
+		hours := services.ResolveOperatingHours(settings, nil, date("2026-03-04"))
+		assert.Equal(t, "09:00", hours.OpenTime)
+		assert.Equal(t, "21:00", hours.CloseTime)
+		assert.Equal(t, services.OperatingHoursSourceDefault, hours.Source)
+	})
+
+	t.Run("LatestOverrideWins", func(t *testing.T) {
+		overrides := []models.CenterOperatingHoursOverride{
+			{ID: 1, StartDate: date("2026-03-01"), EndDate: date("2026-03-07"), OpenTime: "18:00", CloseTime: "22:00", Reason: "考試週"},
+			{ID: 2, StartDate: date("2026-03-04"), EndDate: date("2026-03-04"), Closed: true, Reason: "消毒"},
+		}
+
+		monday := services.ResolveOperatingHours(settings, overrides, date("2026-03-02"))
+		assert.Equal(t, "18:00", monday.OpenTime)
+		assert.Equal(t, services.OperatingHoursSourceOverride, monday.Source)
+		assert.Equal(t, "考試週", monday.Reason)
+
+		wednesday := services.ResolveOperatingHours(settings, overrides, date("2026-03-04"))
+		assert.True(t, wednesday.Closed)
+
+		afterRange := services.ResolveOperatingHours(settings, overrides, date("2026-03-09"))
+		assert.Equal(t, services.OperatingHoursSourceWeekly, afterRange.Source)
+	})
+}
+
+// TestIsWithinOperatingHours 時段是否落在營業時間內
+func TestIsWithinOperatingHours(t *testing.T) {
+	hours := services.DailyOperatingHours{OpenTime: "14:00", CloseTime: "21:30"}
+
+	assert.True(t, services.IsWithinOperatingHours(hours, "14:00", "15:00"))
+	assert.True(t, services.IsWithinOperatingHours(hours, "20:30", "21:30"))
+	assert.False(t, services.IsWithinOperatingHours(hours, "13:30", "14:30"))
+	assert.False(t, services.IsWithinOperatingHours(hours, "21:00", "22:00"))
+	assert.False(t, services.IsWithinOperatingHours(hours, "23:00", "01:00"))
+
+	assert.False(t, services.IsWithinOperatingHours(services.DailyOperatingHours{Closed: true}, "10:00", "11:00"))
+	assert.True(t, services.IsWithinOperatingHours(services.DailyOperatingHours{}, "06:00", "07:00"))
+}
+
+// TestGenerateTimeSlotsForHours 依營業時間產生時段
+func TestGenerateTimeSlotsForHours(t *testing.T) {
+	assert.Equal(t, []int{14, 15, 16, 17, 18, 19, 20, 21}, services.GenerateTimeSlotsForHours(services.DailyOperatingHours{OpenTime: "14:00", CloseTime: "21:30"}))
+	assert.Empty(t, services.GenerateTimeSlotsForHours(services.DailyOperatingHours{Closed: true}))
+	assert.Len(t, services.GenerateTimeSlotsForHours(services.DailyOperatingHours{}), 24)
+	// 打烊整點不列入，打烊早於開門不產生時段
+	assert.Equal(t, []int{9, 10, 11}, services.GenerateTimeSlotsForHours(services.DailyOperatingHours{OpenTime: "09:00", CloseTime: "12:00"}))
+	assert.Equal(t, []int{22, 23}, services.GenerateTimeSlotsForHours(services.DailyOperatingHours{OpenTime: "22:00", CloseTime: "24:00"}))
+	assert.Empty(t, services.GenerateTimeSlotsForHours(services.DailyOperatingHours{OpenTime: "18:00", CloseTime: "09:00"}))
+	assert.Error(t, services.ValidateOpeningWindow("18:00", "09:00"))
+	assert.NoError(t, services.ValidateOpeningWindow("09:00", "24:00"))
+}
+
+// TestValidateWeeklyOperatingHours 每週營業時間設定檢查
+func TestValidateWeeklyOperatingHours(t *testing.T) {
+	assert.NoError(t, services.ValidateWeeklyOperatingHours([]models.WeekdayOperatingHours{
+		{Weekday: 1, OpenTime: "14:00", CloseTime: "24:00"},
+		{Weekday: 7, Closed: true},
+	}))
+	assert.Error(t, services.ValidateWeeklyOperatingHours([]models.WeekdayOperatingHours{{Weekday: 0, OpenTime: "09:00", CloseTime: "18:00"}}))
+	assert.Error(t, services.ValidateWeeklyOperatingHours([]models.WeekdayOperatingHours{{Weekday: 2, OpenTime: "18:00", CloseTime: "09:00"}}))
+	assert.Error(t, services.ValidateWeeklyOperatingHours([]models.WeekdayOperatingHours{
+		{Weekday: 3, OpenTime: "09:00", CloseTime: "18:00"},
+		{Weekday: 3, Closed: true},
+	}))
+}
@@ -0,0 +1,130 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"timeLedger/app/controllers"
+	"timeLedger/app/models"
+	"timeLedger/app/repositories"
+	"timeLedger/app/services"
+	"timeLedger/global/errInfos"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParseIfMatchVersion If-Match header 版本解析
+func TestParseIfMatchVersion(t *testing.T) {
+	valid := map[string]uint{
+		`3`:       3,
+		`"3"`:     3,
+		`W/"12"`:  12,
+		` "7" `:   7,
+		`"40000"`: 40000,
+	}
+	for header, want := range valid {
+		got, err := controllers.ParseIfMatchVersion(header)
+		assert.NoError(t, err, header)
+		assert.Equal(t, want, got, header)
+	}
+
+	for _, header := range []string{`*`, `""`, `"0"`, `"-1"`, `"abc"`, `W/`} {
+		_, err := controllers.ParseIfMatchVersion(header)
+		assert.Error(t, err, header)
+	}
+}
+
+// TestVersionConflictError 版本衝突錯誤可被辨識並保留目前狀態
+func TestVersionConflictError(t *testing.T) {
+	current := map[string]any{"id": 5, "name": "週三鋼琴"}
+	err := fmt.Errorf("update rule: %w",
+		services.NewVersionConflictError("ScheduleRule", 5, 2, 3, current))
+
+	assert.True(t, errors.Is(err, services.ErrVersionConflict))
+
+	var conflictErr *services.VersionConflictError
+	assert.True(t, errors.As(err, &conflictErr))
+	assert.Equal(t, "ScheduleRule", conflictErr.Conflict.Resource)
+	assert.Equal(t, uint(5), conflictErr.Conflict.ResourceID)
+	assert.Equal(t, uint(2), conflictErr.Conflict.ExpectedVersion)
+	assert.Equal(t, uint(3), conflictErr.Conflict.CurrentVersion)
+	assert.Equal(t, current, conflictErr.Conflict.Current)
+
+	assert.False(t, errors.Is(errors.New("other"), services.ErrVersionConflict))
+}
+
+// TestTimetableCellsBumpTemplateVersion 新增、排序、刪除格子都遞增模板版本，帶舊版本的編輯回傳 ERR_CONCURRENT_MODIFIED
+func TestTimetableCellsBumpTemplateVersion(t *testing.T) {
+	appInstance, db, cleanup := setupExpansionTestApp()
+	defer cleanup()
+
+	ctx := context.Background()
+	if err := db.AutoMigrate(&models.TimetableTemplate{}, &models.TimetableCell{}, &models.AuditLog{}); err != nil {
+		t.Fatalf("AutoMigrate error: %v", err)
+	}
+
+	center, err := NewTestDataFactory(db).CreateTestCenter(ctx, "TemplateVersion")
+	if err != nil {
+		t.Fatalf("建立測試中心失敗: %v", err)
+	}
+	template := models.TimetableTemplate{
+		CenterID:  center.ID,
+		Name:      fmt.Sprintf("Version Template %d", time.Now().UnixNano()),
+		RowType:   "WEEKLY",
+		Version:   1,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := db.WithContext(ctx).Create(&template).Error; err != nil {
+		t.Fatalf("建立模板失敗: %v", err)
+	}
+	defer func() {
+		db.WithContext(ctx).Where("template_id = ?", template.ID).Delete(&models.TimetableCell{})
+		db.WithContext(ctx).Unscoped().Where("id = ?", template.ID).Delete(&models.TimetableTemplate{})
+		db.WithContext(ctx).Where("id = ?", center.ID).Delete(&models.Center{})
+	}()
+
+	svc := services.NewTimetableTemplateService(appInstance)
+	version := uint(1)
+
+	cells, updated, _, err := svc.CreateCells(ctx, &services.CreateCellsInput{
+		CenterID:   center.ID,
+		AdminID:    1,
+		TemplateID: template.ID,
+		Cells:      []models.TimetableCell{{RowNo: 1, ColNo: 1, StartTime: "09:00", EndTime: "10:00"}},
+		Version:    &version,
+	})
+	if err != nil {
+		t.Fatalf("新增格子失敗: %v", err)
+	}
+	assert.Equal(t, uint(2), updated.Version)
+	assert.Len(t, cells, 1)
+
+	// 另一位管理員仍以版本 1 編輯
+	_, errInfo, err := svc.DeleteCell(ctx, &services.DeleteCellInput{CenterID: center.ID, AdminID: 2, CellID: cells[0].ID, Version: &version})
+	assert.True(t, errors.Is(err, services.ErrVersionConflict))
+	assert.True(t, errInfo.Is(errInfos.ERR_CONCURRENT_MODIFIED))
+
+	version = updated.Version
+	updated, _, err = svc.ReorderCells(ctx, &services.ReorderCellsInput{
+		CenterID:   center.ID,
+		AdminID:    1,
+		TemplateID: template.ID,
+		Cells:      []repositories.ReorderCellRequest{{ID: cells[0].ID, SortOrder: 3}},
+		Version:    &version,
+	})
+	if err != nil {
+		t.Fatalf("排序格子失敗: %v", err)
+	}
+	assert.Equal(t, uint(3), updated.Version)
+
+	version = updated.Version
+	updated, _, err = svc.DeleteCell(ctx, &services.DeleteCellInput{CenterID: center.ID, AdminID: 1, CellID: cells[0].ID, Version: &version})
+	if err != nil {
+		t.Fatalf("刪除格子失敗: %v", err)
+	}
+	assert.Equal(t, uint(4), updated.Version)
+}
@@ -0,0 +1,113 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"timeLedger/app/models"
+	"timeLedger/app/services"
+	"timeLedger/libs/twcalendar"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMaterializeRecurringHolidays 週期假日展開
+func TestMaterializeRecurringHolidays(t *testing.T) {
+	date := func(s string) time.Time {
+		d, _ := time.Parse("2006-01-02", s)
+		return d
+	}
+	dates := func(holidays []models.CenterHoliday) []string {
+		result := make([]string, 0, len(holidays))
+		for _, h := range holidays {
+			result = append(result, h.Date.Format("2006-01-02"))
+		}
+		return result
+	}
+
+	t.Run("FixedDateAcrossYears", func(t *testing.T) {
+		defs := []models.RecurringHoliday{
+			{Name: "年終休假", RuleType: models.RecurringHolidayTypeFixedDate, Month: 12, Day: 30, DurationDays: 4},
+		}
+
+		holidays := services.MaterializeRecurringHolidays(defs, date("2026-01-01"), date("2026-12-31"))
+
+		// 2025/12/30 開始的連假延伸到 2026/01/01、02
+		assert.Equal(t, []string{"2026-01-01", "2026-01-02", "2026-12-30", "2026-12-31"}, dates(holidays))
+	})
+
+	t.Run("LeapDaySkippedInCommonYear", func(t *testing.T) {
+		defs := []models.RecurringHoliday{
+			{Name: "閏日", RuleType: models.RecurringHolidayTypeFixedDate, Month: 2, Day: 29, DurationDays: 1},
+		}
+
+		assert.Empty(t, services.MaterializeRecurringHolidays(defs, date("2026-01-01"), date("2026-12-31")))
+		assert.Len(t, services.MaterializeRecurringHolidays(defs, date("2028-01-01"), date("2028-12-31")), 1)
+	})
+
+	t.Run("NthWeekday", func(t *testing.T) {
+		defs := []models.RecurringHoliday{
+			// 5 月第二個週日
+			{Name: "母親節", RuleType: models.RecurringHolidayTypeNthWeekday, Month: 5, Weekday: 7, WeekOfMonth: 2, DurationDays: 1},
+			// 8 月最後一個週五
+			{Name: "員工旅遊", RuleType: models.RecurringHolidayTypeNthWeekday, Month: 8, Weekday: 5, WeekOfMonth: -1, DurationDays: 1},
+		}
+
+		holidays := services.MaterializeRecurringHolidays(defs, date("2026-01-01"), date("2026-12-31"))
+
+		assert.Equal(t, []string{"2026-05-10", "2026-08-28"}, dates(holidays))
+	})
+
+	t.Run("LunarWithOffset", func(t *testing.T) {
+		defs := []models.RecurringHoliday{
+			{Name: "農曆年假", RuleType: models.RecurringHolidayTypeLunar, LunarFestival: twcalendar.LunarNewYear, OffsetDays: -1, DurationDays: 4, ForceCancel: true},
+			{Name: "中秋節", RuleType: models.RecurringHolidayTypeLunar, LunarFestival: twcalendar.MidAutumn, DurationDays: 1},
+		}
+
+		holidays := services.MaterializeRecurringHolidays(defs, date("2026-01-01"), date("2026-12-31"))
+
+		assert.Equal(t, []string{"2026-02-16", "2026-02-17", "2026-02-18", "2026-02-19", "2026-09-25"}, dates(holidays))
+		assert.True(t, holidays[0].ForceCancel)
+		assert.False(t, holidays[4].ForceCancel)
+	})
+
+	t.Run("MergePrefersConcreteHoliday", func(t *testing.T) {
+		concrete := []models.CenterHoliday{{ID: 9, Date: date("2026-09-25"), Name: "颱風停課", ForceCancel: true}}
+		recurring := []models.CenterHoliday{{Date: date("2026-09-25"), Name: "中秋節"}, {Date: date("2026-09-28"), Name: "教師節"}}
+
+		merged := services.MergeHolidays(concrete, recurring)
+
+		if assert.Len(t, merged, 2) {
+			assert.Equal(t, uint(9), merged[0].ID)
+			assert.Equal(t, "教師節", merged[1].Name)
+		}
+	})
+}
+
+// TestValidateRecurringHoliday 週期假日定義檢查
+func TestValidateRecurringHoliday(t *testing.T) {
+	valid := []models.RecurringHoliday{
+		{RuleType: models.RecurringHolidayTypeFixedDate, Month: 2, Day: 29, DurationDays: 1},
+		{RuleType: models.RecurringHolidayTypeNthWeekday, Month: 11, Weekday: 4, WeekOfMonth: 4, DurationDays: 1},
+		{RuleType: models.RecurringHolidayTypeLunar, LunarFestival: twcalendar.DragonBoat, DurationDays: 1},
+		{RuleType: models.RecurringHolidayTypeLunar, LunarFestival: twcalendar.DragonBoat, OffsetDays: -31, DurationDays: 1},
+	}
+	for _, def := range valid {
+		assert.NoError(t, services.ValidateRecurringHoliday(def))
+	}
+
+	invalid := []models.RecurringHoliday{
+		{RuleType: models.RecurringHolidayTypeFixedDate, Month: 2, Day: 30, DurationDays: 1},
+		{RuleType: models.RecurringHolidayTypeFixedDate, Month: 13, Day: 1, DurationDays: 1},
+		{RuleType: models.RecurringHolidayTypeNthWeekday, Month: 5, Weekday: 0, WeekOfMonth: 1, DurationDays: 1},
+		{RuleType: models.RecurringHolidayTypeNthWeekday, Month: 5, Weekday: 1, WeekOfMonth: 6, DurationDays: 1},
+		{RuleType: models.RecurringHolidayTypeLunar, LunarFestival: "QINGMING", DurationDays: 1},
+		{RuleType: models.RecurringHolidayTypeLunar, LunarFestival: twcalendar.MidAutumn, DurationDays: 0},
+		{RuleType: "WEEKLY", DurationDays: 1},
+		{RuleType: models.RecurringHolidayTypeFixedDate, Month: 1, Day: 1, OffsetDays: 400, DurationDays: 1},
+		{RuleType: models.RecurringHolidayTypeFixedDate, Month: 1, Day: 1, OffsetDays: -32, DurationDays: 1},
+	}
+	for _, def := range invalid {
+		assert.Error(t, services.ValidateRecurringHoliday(def), "%+v", def)
+	}
+}
@@ -0,0 +1,208 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"timeLedger/app/models"
+	"timeLedger/app/services"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func bookingDate(value string) time.Time {
+	d, _ := time.Parse("2006-01-02", value)
+	return d
+}
+
+// TestRoomBookingOccursOn 單次與每週預約的佔用日期
+func TestRoomBookingOccursOn(t *testing.T) {
+	once := models.RoomBooking{Recurrence: models.RoomBookingRecurrenceNone, StartDate: bookingDate("2026-03-04")}
+	assert.True(t, once.OccursOn(bookingDate("2026-03-04")))
+	assert.False(t, once.OccursOn(bookingDate("2026-03-11")))
+
+	end := bookingDate("2026-03-25")
+	weekly := models.RoomBooking{
+		Recurrence: models.RoomBookingRecurrenceWeekly,
+		StartDate:  bookingDate("2026-03-04"), // 週三
+		EndDate:    &end,
+		Weekday:    3,
+	}
+	assert.False(t, weekly.OccursOn(bookingDate("2026-02-25")))
+	assert.True(t, weekly.OccursOn(bookingDate("2026-03-11")))
+	assert.False(t, weekly.OccursOn(bookingDate("2026-03-12")))
+	assert.False(t, weekly.OccursOn(bookingDate("2026-04-01")))
+}
+
+// TestBuildRoomBooking 預約請求驗證
+func TestBuildRoomBooking(t *testing.T) {
+	req := &services.RoomBookingRequest{
+		RoomID:      1,
+		BookingType: models.RoomBookingTypeRental,
+		Title:       "場地租借",
+		Recurrence:  models.RoomBookingRecurrenceWeekly,
+		StartDate:   "2026-03-08",
+		StartTime:   "18:00",
+		EndTime:     "21:00",
+	}
+	booking, err := services.BuildRoomBooking(req)
+	assert.NoError(t, err)
+	assert.Equal(t, 7, booking.Weekday) // 依開始日期推算為週日
+	assert.Nil(t, booking.EndDate)
+
+	bad := *req
+	bad.BookingType = "PARTY"
+	_, err = services.BuildRoomBooking(&bad)
+	assert.Error(t, err)
+
+	bad = *req
+	bad.EndTime = "17:00"
+	_, err = services.BuildRoomBooking(&bad)
+	assert.Error(t, err)
+
+	endDate := "2026-03-01"
+	bad = *req
+	bad.EndDate = &endDate
+	_, err = services.BuildRoomBooking(&bad)
+	assert.Error(t, err)
+}
+
+// TestRoomBookingConflicts 排課與預約的衝突判斷
+func TestRoomBookingConflicts(t *testing.T) {
+	meeting := models.RoomBooking{
+		ID: 1, RoomID: 1, BookingType: models.RoomBookingTypeMeeting, Title: "教師會議",
+		Recurrence: models.RoomBookingRecurrenceNone, StartDate: bookingDate("2026-03-04"), Weekday: 3,
+		StartTime: "10:00", EndTime: "12:00",
+	}
+	cleaning := models.RoomBooking{
+		ID: 2, RoomID: 2, BookingType: models.RoomBookingTypeMaintenance, Title: "清潔",
+		Recurrence: models.RoomBookingRecurrenceWeekly, StartDate: bookingDate("2026-03-01"), Weekday: 3,
+		StartTime: "12:00", EndTime: "13:00",
+	}
+	bookings := []models.RoomBooking{meeting, cleaning}
+
+	t.Run("DatedSession", func(t *testing.T) {
+		date := bookingDate("2026-03-04")
+		conflicts := services.RoomBookingConflicts(bookings, []uint{1, 2}, &date, 3, "11:00", "12:30")
+		if assert.Len(t, conflicts, 2) {
+			assert.Equal(t, "ROOM_BOOKED", conflicts[0].Type)
+			assert.Equal(t, "BOOKING", conflicts[0].ConflictSource)
+			assert.Equal(t, uint(1), conflicts[0].ConflictSourceID)
+		}
+
+		other := bookingDate("2026-03-11")
+		conflicts = services.RoomBookingConflicts(bookings, []uint{1, 2}, &other, 3, "11:00", "12:30")
+		if assert.Len(t, conflicts, 1) {
+			assert.Equal(t, uint(2), conflicts[0].ConflictSourceID)
+		}
+	})
+
+	t.Run("WeeklyRule", func(t *testing.T) {
+		// 每週規則只與每週預約比對
+		conflicts := services.RoomBookingConflicts(bookings, []uint{1, 2}, nil, 3, "11:00", "12:30")
+		if assert.Len(t, conflicts, 1) {
+			assert.Equal(t, uint(2), conflicts[0].ConflictSourceID)
+		}
+		assert.Empty(t, services.RoomBookingConflicts(bookings, []uint{1, 2}, nil, 3, "13:00", "14:00"))
+		assert.Empty(t, services.RoomBookingConflicts(bookings, []uint{1, 2}, nil, 4, "12:00", "13:00"))
+	})
+
+	t.Run("BookingsCollide", func(t *testing.T) {
+		overlapping := meeting
+		overlapping.ID = 3
+		overlapping.StartTime = "11:30"
+		overlapping.EndTime = "13:00"
+		assert.True(t, services.RoomBookingsCollide(meeting, overlapping))
+
+		overlapping.RoomID = 2
+		assert.True(t, services.RoomBookingsCollide(overlapping, cleaning))
+		assert.False(t, services.RoomBookingsCollide(meeting, cleaning))
+	})
+
+	t.Run("RuleCollides", func(t *testing.T) {
+		rule := models.ScheduleRule{
+			ID: 9, RoomID: 1, Weekday: 3, StartTime: "09:00", EndTime: "10:30",
+			EffectiveRange: models.DateRange{StartDate: bookingDate("2026-02-01"), EndDate: bookingDate("2026-06-30")},
+		}
+		assert.True(t, services.RuleCollidesWithBooking(rule, meeting))
+
+		rule.SuspendedDates = models.SuspendedDates{bookingDate("2026-03-04")}
+		assert.False(t, services.RuleCollidesWithBooking(rule, meeting))
+
+		rule.RoomID = 2
+		rule.StartTime = "12:30"
+		rule.EndTime = "14:00"
+		assert.True(t, services.RuleCollidesWithBooking(rule, cleaning))
+	})
+
+	t.Run("MakeupCollides", func(t *testing.T) {
+		makeup := models.MakeupSession{ID: 5, RoomID: 2, MakeupDate: bookingDate("2026-03-11"), StartTime: "12:30", EndTime: "14:00"}
+		assert.True(t, services.MakeupCollidesWithBooking(makeup, cleaning))
+		assert.False(t, services.MakeupCollidesWithBooking(makeup, meeting))
+
+		makeup.MakeupDate = bookingDate("2026-03-12")
+		assert.False(t, services.MakeupCollidesWithBooking(makeup, cleaning))
+	})
+
+	t.Run("RescheduleCollides", func(t *testing.T) {
+		loc := time.FixedZone("Asia/Taipei", 8*3600)
+		newStart := time.Date(2026, 3, 4, 11, 0, 0, 0, loc)
+		newEnd := time.Date(2026, 3, 4, 12, 0, 0, 0, loc)
+		exception := models.ScheduleException{
+			ID: 7, ExceptionType: "RESCHEDULE", Status: "APPROVED",
+			NewStartAt: &newStart, NewEndAt: &newEnd,
+			Rule: models.ScheduleRule{RoomID: 1},
+		}
+		// 未指定新教室時沿用原規則的教室
+		assert.True(t, services.RescheduleCollidesWithBooking(exception, meeting))
+
+		newRoom := uint(2)
+		exception.NewRoomID = &newRoom
+		assert.False(t, services.RescheduleCollidesWithBooking(exception, meeting))
+
+		exception.NewStartAt = nil
+		exception.NewRoomID = nil
+		assert.False(t, services.RescheduleCollidesWithBooking(exception, meeting))
+	})
+
+	t.Run("WeeklyRuleWithinRange", func(t *testing.T) {
+		// 單次預約落在規則的星期與有效期間內也視為衝突
+		conflicts := services.WeeklyRuleBookingConflicts(bookings, []uint{1, 2}, []int{1, 3}, "11:00", "12:30",
+			bookingDate("2026-03-01"), bookingDate("2026-03-31"))
+		if assert.Len(t, conflicts, 2) {
+			assert.Equal(t, uint(1), conflicts[0].ConflictSourceID)
+			assert.Equal(t, uint(2), conflicts[1].ConflictSourceID)
+		}
+
+		// 期間不含預約日期，或不同星期
+		conflicts = services.WeeklyRuleBookingConflicts(bookings, []uint{1}, []int{3}, "11:00", "12:30",
+			bookingDate("2026-03-05"), time.Time{})
+		assert.Empty(t, conflicts)
+		conflicts = services.WeeklyRuleBookingConflicts(bookings, []uint{1, 2}, []int{4}, "11:00", "12:30",
+			bookingDate("2026-03-01"), bookingDate("2026-03-31"))
+		assert.Empty(t, conflicts)
+
+		// 未使用的教室不比對
+		conflicts = services.WeeklyRuleBookingConflicts(bookings, []uint{2}, []int{3}, "11:00", "12:30",
+			bookingDate("2026-03-01"), bookingDate("2026-03-31"))
+		if assert.Len(t, conflicts, 1) {
+			assert.Equal(t, uint(2), conflicts[0].ConflictSourceID)
+		}
+	})
+}
+
+// TestExpandRoomBookings 預約展開為每日佔用
+func TestExpandRoomBookings(t *testing.T) {
+	bookings := []models.RoomBooking{
+		{ID: 1, RoomID: 1, Title: "清潔", Recurrence: models.RoomBookingRecurrenceWeekly, StartDate: bookingDate("2026-03-01"), Weekday: 1, StartTime: "08:00", EndTime: "09:00"},
+		{ID: 2, RoomID: 1, Title: "會議", Recurrence: models.RoomBookingRecurrenceNone, StartDate: bookingDate("2026-03-03"), StartTime: "10:00", EndTime: "11:00"},
+	}
+
+	occurrences := services.ExpandRoomBookings(bookings, bookingDate("2026-03-01"), bookingDate("2026-03-15"))
+	if assert.Len(t, occurrences, 3) {
+		assert.Equal(t, "2026-03-02", occurrences[0].Date)
+		assert.Equal(t, "2026-03-03", occurrences[1].Date)
+		assert.Equal(t, uint(2), occurrences[1].BookingID)
+		assert.Equal(t, "2026-03-09", occurrences[2].Date)
+	}
+}
@@ -0,0 +1,88 @@
+package test
+
+import (
+	"testing"
+
+	"timeLedger/app/models"
+	"timeLedger/app/services"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestEvaluateRoomSuitability 教室設備與課程需求比對
+func TestEvaluateRoomSuitability(t *testing.T) {
+	danceRoom := models.Room{
+		ID:       1,
+		Name:     "舞蹈教室",
+		Capacity: 20,
+		Features: models.RoomFeatures{
+			{Tag: "mirror_wall", Quantity: 1},
+			{Tag: "mat", Quantity: 20},
+		},
+	}
+	pianoRoom := models.Room{
+		ID:       2,
+		Name:     "琴房",
+		Capacity: 4,
+		Features: models.RoomFeatures{{Tag: "piano", Quantity: 1}},
+	}
+
+	t.Run("NoRequirements", func(t *testing.T) {
+		reasons := services.EvaluateRoomSuitability(pianoRoom, models.Course{Name: "樂理"})
+		assert.Empty(t, reasons)
+	})
+
+	t.Run("CapacityTooSmall", func(t *testing.T) {
+		course := models.Course{Name: "團體芭蕾", MinCapacity: 12}
+		reasons := services.EvaluateRoomSuitability(pianoRoom, course)
+		assert.Len(t, reasons, 1)
+		assert.Empty(t, services.EvaluateRoomSuitability(danceRoom, course))
+	})
+
+	t.Run("MissingOrInsufficientFeature", func(t *testing.T) {
+		course := models.Course{
+			Name: "瑜珈",
+			RequiredFeatures: models.RoomFeatures{
+				{Tag: "mirror_wall"},
+				{Tag: "mat", Quantity: 25},
+				{Tag: "projector", Quantity: 1},
+			},
+		}
+		reasons := services.EvaluateRoomSuitability(danceRoom, course)
+		// 未填數量視為 1 面鏡牆；瑜珈墊與投影機不足
+		assert.Len(t, reasons, 2)
+	})
+
+	t.Run("FilterSuitableRooms", func(t *testing.T) {
+		course := models.Course{
+			Name:             "鋼琴個別課",
+			RequiredFeatures: models.RoomFeatures{{Tag: "piano", Quantity: 1}},
+		}
+		rooms := services.FilterSuitableRooms([]models.Room{danceRoom, pianoRoom}, course)
+		if assert.Len(t, rooms, 1) {
+			assert.Equal(t, uint(2), rooms[0].ID)
+		}
+	})
+}
+
+// TestValidateRoomFeatures 教室設備設定檢查
+func TestValidateRoomFeatures(t *testing.T) {
+	assert.NoError(t, services.ValidateRoomFeatures(nil))
+	assert.NoError(t, services.ValidateRoomFeatures(models.RoomFeatures{{Tag: "piano", Quantity: 2}}))
+	assert.Error(t, services.ValidateRoomFeatures(models.RoomFeatures{{Tag: "", Quantity: 1}}))
+	assert.Error(t, services.ValidateRoomFeatures(models.RoomFeatures{{Tag: "mat", Quantity: -1}}))
+	assert.Error(t, services.ValidateRoomFeatures(models.RoomFeatures{{Tag: "mat"}, {Tag: "mat"}}))
+}
+
+// TestRoomFeaturesJSON 教室設備欄位序列化
+func TestRoomFeaturesJSON(t *testing.T) {
+	var empty models.RoomFeatures
+	value, err := empty.Value()
+	assert.NoError(t, err)
+	assert.Equal(t, "[]", string(value.([]byte)))
+
+	var features models.RoomFeatures
+	assert.NoError(t, features.Scan([]byte(`[{"tag":"mat","quantity":10},{"tag":"mat","quantity":5}]`)))
+	assert.Equal(t, 15, features.Quantity("mat"))
+	assert.Equal(t, 0, features.Quantity("piano"))
+}
@@ -0,0 +1,109 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"timeLedger/app/models"
+	"timeLedger/app/services"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCalculateRoomUtilization 教室使用率依營業時間、假日與重疊佔用計算
+func TestCalculateRoomUtilization(t *testing.T) {
+	start, _ := time.Parse("2006-01-02", "2026-03-02") // 週一
+	end, _ := time.Parse("2006-01-02", "2026-03-04")   // 週三
+	holiday, _ := time.Parse("2006-01-02", "2026-03-04")
+
+	input := services.RoomUtilizationInput{
+		StartDate:   start,
+		EndDate:     end,
+		BandMinutes: 60,
+		Rooms: []models.Room{
+			{ID: 1, Name: "A", Capacity: 10},
+			{ID: 2, Name: "B", Capacity: 30},
+		},
+		DailyHours: map[string]services.DailyOperatingHours{
+			"2026-03-02": {Date: "2026-03-02", OpenTime: "09:00", CloseTime: "12:00"},
+			"2026-03-03": {Date: "2026-03-03", Closed: true},
+			"2026-03-04": {Date: "2026-03-04", OpenTime: "09:00", CloseTime: "12:00"},
+		},
+		Holidays: []models.CenterHoliday{{Date: holiday, ForceCancel: true}},
+		Occupancies: []services.RoomOccupancy{
+			// 08:00 - 10:00 只有營業時間內的一小時計入
+			{RoomID: 1, Date: "2026-03-02", StartTime: "08:00", EndTime: "10:00", Source: services.OccupancySourceSession},
+			// 與上一筆重疊的預約只計算一次
+			{RoomID: 1, Date: "2026-03-02", StartTime: "09:30", EndTime: "10:30", Source: services.OccupancySourceBooking},
+			// 假日當天不計入
+			{RoomID: 2, Date: "2026-03-04", StartTime: "09:00", EndTime: "12:00", Source: services.OccupancySourceSession},
+		},
+	}
+
+	report := services.CalculateRoomUtilization(input)
+
+	assert.Equal(t, 1, report.OpenDays)
+	assert.Equal(t, 1, report.ClosedDays)
+	assert.Equal(t, 1, report.HolidayDays)
+	assert.Equal(t, 360, report.Summary.AvailableMinutes)
+	assert.Equal(t, 90, report.Summary.OccupiedMinutes)
+	assert.Equal(t, 0.25, report.Summary.Rate)
+	assert.Equal(t, 0.125, report.SeatWeightedRate) // (90*10) / (180*10 + 180*30)
+
+	if assert.Len(t, report.Rooms, 2) {
+		room := report.Rooms[0]
+		assert.Equal(t, 90, room.OccupiedMinutes)
+		assert.Equal(t, 60, room.SessionMinutes)
+		assert.Equal(t, 60, room.BookingMinutes)
+		assert.Equal(t, 0.5, room.Rate)
+		if assert.Len(t, room.Bands, 3) {
+			assert.Equal(t, "09:00", room.Bands[0].StartTime)
+			assert.Equal(t, 1.0, room.Bands[0].Rate)
+			assert.Equal(t, 0.5, room.Bands[1].Rate)
+			assert.Equal(t, 0.0, room.Bands[2].Rate)
+		}
+		assert.Equal(t, 0.0, report.Rooms[1].Rate)
+	}
+
+	// 熱度圖只有營業的週一 09:00 - 12:00
+	if assert.Len(t, report.Heatmap, 3) {
+		assert.Equal(t, 1, report.Heatmap[0].Weekday)
+		assert.Equal(t, 0.5, report.Heatmap[0].Rate)
+	}
+}
+
+// TestRoomUtilizationComparison 與前一學期比較
+func TestRoomUtilizationComparison(t *testing.T) {
+	parse := func(v string) time.Time {
+		d, _ := time.Parse("2006-01-02", v)
+		return d
+	}
+	terms := []models.CenterTerm{
+		{ID: 1, Name: "秋季", StartDate: parse("2025-09-01"), EndDate: parse("2025-12-31")},
+		{ID: 2, Name: "冬季", StartDate: parse("2026-01-01"), EndDate: parse("2026-02-28")},
+		{ID: 3, Name: "春季", StartDate: parse("2026-03-01"), EndDate: parse("2026-06-30")},
+	}
+
+	previous := services.FindPreviousTerm(terms, parse("2026-03-01"))
+	if assert.NotNil(t, previous) {
+		assert.Equal(t, uint(2), previous.ID)
+	}
+	assert.Nil(t, services.FindPreviousTerm(terms, parse("2025-09-01")))
+
+	current := services.RoomUtilizationReport{
+		Summary: services.UtilizationStat{Rate: 0.6},
+		Rooms:   []services.RoomUtilization{{RoomID: 1, UtilizationStat: services.UtilizationStat{Rate: 0.6}}},
+	}
+	prev := services.RoomUtilizationReport{
+		Summary: services.UtilizationStat{Rate: 0.45},
+		Rooms:   []services.RoomUtilization{{RoomID: 1, UtilizationStat: services.UtilizationStat{Rate: 0.4}}},
+	}
+	comparison := services.CompareRoomUtilization(current, prev, *previous)
+	assert.Equal(t, 0.15, comparison.RateDelta)
+	if assert.Len(t, comparison.Rooms, 1) {
+		assert.Equal(t, 0.2, comparison.Rooms[0].Delta)
+	}
+
+	assert.Error(t, services.ValidateUtilizationBand(50))
+	assert.NoError(t, services.ValidateUtilizationBand(30))
+}
@@ -198,3 +198,98 @@ func TestScheduleExpansionService_HolidayFiltering(t *testing.T) {
 		}
 	})
 }
+
+// TestScheduleExpansionService_SubstituteMakeup 代課老師的課表包含其代為授課的補課，沿用原規則的班別資訊
+func TestScheduleExpansionService_SubstituteMakeup(t *testing.T) {
+	appInstance, db, cleanup := setupExpansionTestApp()
+	defer cleanup()
+
+	ctx := context.Background()
+	if err := db.AutoMigrate(&models.MakeupSession{}, &models.ScheduleRuleTeacher{}, &models.ScheduleRuleRoom{}); err != nil {
+		t.Fatalf("AutoMigrate error: %v", err)
+	}
+
+	factory := NewTestDataFactory(db)
+	center, err := factory.CreateTestCenter(ctx, "SubstituteMakeup")
+	if err != nil {
+		t.Fatalf("建立測試中心失敗: %v", err)
+	}
+	course, err := factory.CreateTestCourse(ctx, center.ID, "SubstituteMakeup")
+	if err != nil {
+		t.Fatalf("建立測試課程失敗: %v", err)
+	}
+	offering, err := factory.CreateTestOffering(ctx, center.ID, course.ID, "SubstituteMakeup")
+	if err != nil {
+		t.Fatalf("建立測試方案失敗: %v", err)
+	}
+	room, err := factory.CreateTestRoom(ctx, center.ID, "SubstituteMakeup")
+	if err != nil {
+		t.Fatalf("建立測試教室失敗: %v", err)
+	}
+	lead, err := factory.CreateTestTeacher(ctx, "SubstituteMakeupLead")
+	if err != nil {
+		t.Fatalf("建立測試老師失敗: %v", err)
+	}
+	substitute, err := factory.CreateTestTeacher(ctx, "SubstituteMakeupSub")
+	if err != nil {
+		t.Fatalf("建立測試老師失敗: %v", err)
+	}
+
+	// 原規則由 lead 授課，substitute 另有一條自己的規則
+	original, err := factory.CreateTestScheduleRule(ctx, center.ID, offering.ID, room.ID, &lead.ID, "SubstituteMakeup")
+	if err != nil {
+		t.Fatalf("建立排課規則失敗: %v", err)
+	}
+	own, err := factory.CreateTestScheduleRule(ctx, center.ID, offering.ID, room.ID, &substitute.ID, "SubstituteMakeup")
+	if err != nil {
+		t.Fatalf("建立排課規則失敗: %v", err)
+	}
+
+	makeup := models.MakeupSession{
+		CenterID:       center.ID,
+		OfferingID:     offering.ID,
+		TermID:         1,
+		OriginalRuleID: original.ID,
+		OriginalDate:   time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC),
+		CancelReason:   models.SessionCancelReasonHoliday,
+		MakeupDate:     time.Date(2026, 3, 7, 0, 0, 0, 0, time.UTC),
+		StartTime:      "14:00",
+		EndTime:        "15:00",
+		TeacherID:      &substitute.ID,
+		RoomID:         room.ID,
+		Status:         models.MakeupStatusScheduled,
+		CreatedBy:      1,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+	if err := db.WithContext(ctx).Create(&makeup).Error; err != nil {
+		t.Fatalf("建立補課失敗: %v", err)
+	}
+
+	defer func() {
+		db.WithContext(ctx).Where("id = ?", makeup.ID).Delete(&models.MakeupSession{})
+		db.WithContext(ctx).Where("id IN ?", []uint{original.ID, own.ID}).Delete(&models.ScheduleRule{})
+		db.WithContext(ctx).Where("id IN ?", []uint{lead.ID, substitute.ID}).Delete(&models.Teacher{})
+		db.WithContext(ctx).Where("id = ?", center.ID).Delete(&models.Center{})
+	}()
+
+	startDate := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2026, 3, 8, 0, 0, 0, 0, time.UTC)
+	schedules := services.NewScheduleExpansionService(appInstance).ExpandRules(ctx, []models.ScheduleRule{*own}, startDate, endDate, center.ID)
+
+	var found *services.ExpandedSchedule
+	for i := range schedules {
+		if schedules[i].MakeupID != nil && *schedules[i].MakeupID == makeup.ID {
+			found = &schedules[i]
+		}
+	}
+	if found == nil {
+		t.Fatalf("代課老師的課表應包含補課 %d", makeup.ID)
+	}
+	if found.RuleID != original.ID {
+		t.Errorf("補課應沿用原規則 %d，實際為 %d", original.ID, found.RuleID)
+	}
+	if found.OfferingName != offering.Name {
+		t.Errorf("補課應沿用原規則的班別名稱 %q，實際為 %q", offering.Name, found.OfferingName)
+	}
+}
@@ -0,0 +1,125 @@
+package test
+
+import (
+	"testing"
+
+	"timeLedger/app/models"
+	"timeLedger/app/services"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestScheduleRuleAssignees 協同授課與多教室的規則輔助方法
+func TestScheduleRuleAssignees(t *testing.T) {
+	lead := uint(10)
+	rule := models.ScheduleRule{
+		TeacherID: &lead,
+		RoomID:    1,
+		AdditionalTeachers: []models.ScheduleRuleTeacher{
+			{TeacherID: 11, Role: models.TeacherRoleAssistant},
+			{TeacherID: 10, Role: models.TeacherRoleAssistant}, // 與主教重複
+			{TeacherID: 12, Role: models.TeacherRoleTA},
+		},
+		AdditionalRooms: []models.ScheduleRuleRoom{
+			{RoomID: 2},
+			{RoomID: 1}, // 與主要教室重複
+		},
+	}
+
+	t.Run("TeacherAssignments", func(t *testing.T) {
+		assert.Equal(t, []models.TeacherAssignment{
+			{TeacherID: 10, Role: models.TeacherRoleLead},
+			{TeacherID: 11, Role: models.TeacherRoleAssistant},
+			{TeacherID: 12, Role: models.TeacherRoleTA},
+		}, rule.TeacherAssignments())
+		assert.Equal(t, []uint{10, 11, 12}, rule.AllTeacherIDs())
+	})
+
+	t.Run("AllRoomIDs", func(t *testing.T) {
+		assert.Equal(t, []uint{1, 2}, rule.AllRoomIDs())
+		assert.True(t, rule.HasRoom(2))
+		assert.False(t, rule.HasRoom(3))
+	})
+
+	t.Run("TeacherRole", func(t *testing.T) {
+		assert.True(t, rule.HasTeacher(12))
+		assert.False(t, rule.HasTeacher(13))
+		assert.Equal(t, models.TeacherRoleLead, rule.TeacherRole(10))
+		assert.Equal(t, models.TeacherRoleTA, rule.TeacherRole(12))
+		assert.Equal(t, "", rule.TeacherRole(13))
+	})
+
+	t.Run("NoLeadTeacher", func(t *testing.T) {
+		r := models.ScheduleRule{
+			RoomID:             1,
+			AdditionalTeachers: []models.ScheduleRuleTeacher{{TeacherID: 11, Role: models.TeacherRoleAssistant}},
+		}
+		assert.Equal(t, []uint{11}, r.AllTeacherIDs())
+	})
+}
+
+// TestAssigneeOverlapConflicts 既有規則的協同老師與其他教室也要列入衝突
+func TestAssigneeOverlapConflicts(t *testing.T) {
+	lead := uint(10)
+	existing := models.ScheduleRule{
+		ID:                 5,
+		TeacherID:          &lead,
+		RoomID:             1,
+		AdditionalTeachers: []models.ScheduleRuleTeacher{{TeacherID: 11, Role: models.TeacherRoleAssistant}},
+		AdditionalRooms:    []models.ScheduleRuleRoom{{RoomID: 2}},
+	}
+
+	conflicts := services.AssigneeOverlapConflicts(existing, []uint{11}, []uint{3})
+	if assert.Len(t, conflicts, 1) {
+		assert.Equal(t, "TEACHER_OVERLAP", conflicts[0].Type)
+	}
+
+	conflicts = services.AssigneeOverlapConflicts(existing, []uint{20}, []uint{2})
+	if assert.Len(t, conflicts, 1) {
+		assert.Equal(t, "ROOM_OVERLAP", conflicts[0].Type)
+	}
+
+	assert.Empty(t, services.AssigneeOverlapConflicts(existing, []uint{20}, []uint{3}))
+}
+
+// TestNormalizeTeacherAssignments 協同老師角色驗證
+func TestNormalizeTeacherAssignments(t *testing.T) {
+	result, err := services.NormalizeTeacherAssignments([]models.TeacherAssignment{
+		{TeacherID: 11},
+		{TeacherID: 12, Role: models.TeacherRoleTA},
+		{TeacherID: 11, Role: models.TeacherRoleTA},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []models.TeacherAssignment{
+		{TeacherID: 11, Role: models.TeacherRoleAssistant},
+		{TeacherID: 12, Role: models.TeacherRoleTA},
+	}, result)
+
+	_, err = services.NormalizeTeacherAssignments([]models.TeacherAssignment{{TeacherID: 11, Role: "HELPER"}})
+	assert.Error(t, err)
+
+	_, err = services.NormalizeTeacherAssignments([]models.TeacherAssignment{{Role: models.TeacherRoleTA}})
+	assert.Error(t, err)
+}
+
+// TestExpandedScheduleAssignees 展開後的場次保留所有授課老師與教室
+func TestExpandedScheduleAssignees(t *testing.T) {
+	lead := uint(10)
+	single := services.ExpandedSchedule{TeacherID: &lead, RoomID: 1}
+	assert.Equal(t, []uint{10}, single.AllTeacherIDs())
+	assert.Equal(t, []uint{1}, single.AllRoomIDs())
+	assert.Equal(t, models.TeacherRoleLead, single.TeacherRole(10))
+
+	shared := services.ExpandedSchedule{
+		TeacherID: &lead,
+		RoomID:    1,
+		Teachers: []models.TeacherAssignment{
+			{TeacherID: 10, Role: models.TeacherRoleLead},
+			{TeacherID: 11, Role: models.TeacherRoleAssistant},
+		},
+		RoomIDs: []uint{1, 2},
+	}
+	assert.Equal(t, []uint{10, 11}, shared.AllTeacherIDs())
+	assert.Equal(t, []uint{1, 2}, shared.AllRoomIDs())
+	assert.Equal(t, models.TeacherRoleAssistant, shared.TeacherRole(11))
+}
@@ -0,0 +1,89 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"timeLedger/app/models"
+	"timeLedger/app/services"
+	"timeLedger/libs/twcalendar"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestTaiwanCalendarDataset 內建台灣假日資料集格式檢查
+func TestTaiwanCalendarDataset(t *testing.T) {
+	years := twcalendar.Years()
+	assert.NotEmpty(t, years)
+
+	for _, year := range years {
+		cal, ok := twcalendar.ForYear(year)
+		if !assert.True(t, ok) {
+			continue
+		}
+		assert.Equal(t, twcalendar.DatasetVersion, cal.Version)
+
+		seen := make(map[string]bool)
+		for _, h := range cal.Holidays {
+			d, err := time.Parse("2006-01-02", h.Date)
+			if assert.NoError(t, err, h.Date) {
+				assert.Equal(t, year, d.Year(), h.Date)
+			}
+			assert.NotEmpty(t, h.Name, h.Date)
+			assert.False(t, seen[h.Date], "duplicate holiday %s", h.Date)
+			seen[h.Date] = true
+		}
+
+		for _, w := range cal.MakeupWorkdays {
+			d, err := time.Parse("2006-01-02", w.Date)
+			if assert.NoError(t, err, w.Date) {
+				// 補班日必定落在週末，且比照平日課表
+				assert.True(t, d.Weekday() == time.Saturday || d.Weekday() == time.Sunday, w.Date)
+			}
+			assert.True(t, w.FollowsWeekday >= 1 && w.FollowsWeekday <= 5, w.Date)
+			assert.False(t, seen[w.Date], "makeup workday %s is also a holiday", w.Date)
+		}
+	}
+
+	_, ok := twcalendar.ForYear(1999)
+	assert.False(t, ok)
+}
+
+// TestResolveEffectiveWeekday 補班日比照星期
+func TestResolveEffectiveWeekday(t *testing.T) {
+	sat, _ := time.Parse("2006-01-02", "2024-02-17")
+	makeupWorkdays := map[string]int{"2024-02-17": 4}
+
+	assert.Equal(t, 6, services.ResolveEffectiveWeekday(sat, nil))
+	assert.Equal(t, 4, services.ResolveEffectiveWeekday(sat, makeupWorkdays))
+
+	sun := sat.AddDate(0, 0, 1)
+	assert.Equal(t, 7, services.ResolveEffectiveWeekday(sun, makeupWorkdays))
+}
+
+// TestCalculateSessionQuotaWithMakeupWorkday 補班日計入比照星期的堂數
+func TestCalculateSessionQuotaWithMakeupWorkday(t *testing.T) {
+	date := func(s string) time.Time {
+		d, _ := time.Parse("2006-01-02", s)
+		return d
+	}
+
+	input := services.SessionQuotaInput{
+		TermStart: date("2024-02-01"),
+		TermEnd:   date("2024-02-29"),
+		Today:     date("2024-03-01"),
+		Rules: []models.ScheduleRule{
+			{ID: 1, Weekday: 4, StartTime: "10:00", EndTime: "11:00", Status: models.RuleStatusConfirmed},
+		},
+	}
+
+	// 2024/02 有 5 個週四
+	summary := services.CalculateSessionQuota(input)
+	assert.Equal(t, 5, summary.ScheduledSessions)
+
+	// 2/17（六）補班比照週四課表
+	input.MakeupWorkdays = map[string]int{"2024-02-17": 4}
+	summary = services.CalculateSessionQuota(input)
+	assert.Equal(t, 6, summary.ScheduledSessions)
+	assert.Equal(t, 6, summary.DeliveredSessions)
+}
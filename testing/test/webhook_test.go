@@ -0,0 +1,169 @@
+package test
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"timeLedger/app"
+	"timeLedger/app/controllers"
+	"timeLedger/app/models"
+	"timeLedger/app/services"
+	"timeLedger/configs"
+	"timeLedger/global"
+	"timeLedger/global/errInfos"
+	"timeLedger/libs"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestWebhookSignature 簽章可由接收端以相同密鑰驗證，內容或時間不符即失敗
+func TestWebhookSignature(t *testing.T) {
+	secret, err := libs.NewWebhookSecret()
+	if !assert.NoError(t, err) {
+		return
+	}
+	body := []byte(`{"id":"evt-1","type":"holiday.created"}`)
+	now := time.Unix(1760000000, 0)
+	header := libs.SignWebhookPayload(secret, now.Unix(), body)
+
+	assert.True(t, libs.VerifyWebhookSignature(secret, header, body, now, 5*time.Minute))
+	assert.False(t, libs.VerifyWebhookSignature("whsec_other", header, body, now, 5*time.Minute))
+	assert.False(t, libs.VerifyWebhookSignature(secret, header, []byte(`{}`), now, 5*time.Minute))
+	// 超過容許時間差視為重放
+	assert.False(t, libs.VerifyWebhookSignature(secret, header, body, now.Add(10*time.Minute), 5*time.Minute))
+	assert.False(t, libs.VerifyWebhookSignature(secret, "v1=abc", body, now, 0))
+}
+
+// TestWebhookClientSend 送出帶簽章的請求並記錄回應
+func TestWebhookClientSend(t *testing.T) {
+	secret := "whsec_test"
+	var received http.Header
+	var receivedBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+		receivedBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	// httptest 監聽 127.0.0.1，需允許內網位址
+	client := libs.NewWebhookClient(5*time.Second, true)
+	body := []byte(`{"id":"evt-2","type":"webhook.test"}`)
+	resp, err := client.Send(context.Background(), libs.WebhookRequest{
+		URL:        server.URL,
+		Secret:     secret,
+		EventType:  models.WebhookEventTest,
+		EventID:    "evt-2",
+		DeliveryID: 7,
+		Body:       body,
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, resp.Success())
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+	assert.Equal(t, body, receivedBody)
+	assert.Equal(t, models.WebhookEventTest, received.Get(libs.WebhookHeaderEvent))
+	assert.Equal(t, "evt-2", received.Get(libs.WebhookHeaderEventID))
+	assert.Equal(t, "7", received.Get(libs.WebhookHeaderDelivery))
+	assert.True(t, libs.VerifyWebhookSignature(secret, received.Get(libs.WebhookHeaderSignature), receivedBody, time.Now(), time.Minute))
+
+	// 非 2xx 不視為錯誤，由呼叫端安排重試
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+	resp, err = client.Send(context.Background(), libs.WebhookRequest{URL: failing.URL, Secret: secret, Body: body})
+	assert.NoError(t, err)
+	assert.False(t, resp.Success())
+
+	// 未允許內網時，連線當下即拒絕回環位址，請求不會送達
+	hit := false
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hit = true
+	}))
+	defer internal.Close()
+	strict := libs.NewWebhookClient(5*time.Second, false)
+	resp, err = strict.Send(context.Background(), libs.WebhookRequest{URL: internal.URL, Secret: secret, Body: body})
+	assert.ErrorIs(t, err, libs.ErrWebhookRestrictedAddress)
+	assert.Zero(t, resp.StatusCode)
+	assert.False(t, hit)
+}
+
+// TestIsRestrictedWebhookIP 內網、回環、鏈路本地與未指定位址不可作為 webhook 目標
+func TestIsRestrictedWebhookIP(t *testing.T) {
+	for _, raw := range []string{"127.0.0.1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254", "0.0.0.0", "100.64.0.1", "::1", "fd00::1", "fe80::1", "::ffff:127.0.0.1"} {
+		assert.True(t, libs.IsRestrictedWebhookIP(net.ParseIP(raw)), raw)
+	}
+	for _, raw := range []string{"8.8.8.8", "203.0.113.10", "2001:4860:4860::8888"} {
+		assert.False(t, libs.IsRestrictedWebhookIP(net.ParseIP(raw)), raw)
+	}
+}
+
+// TestWebhookSubscriptionFilter 事件類型篩選與檢查
+func TestWebhookSubscriptionFilter(t *testing.T) {
+	all := models.WebhookSubscription{}
+	assert.True(t, all.Subscribes(models.WebhookEventHolidayCreated))
+
+	filtered := models.WebhookSubscription{EventTypes: models.WebhookEventTypes{models.WebhookEventTeacherJoined}}
+	assert.True(t, filtered.Subscribes(models.WebhookEventTeacherJoined))
+	assert.False(t, filtered.Subscribes(models.WebhookEventRuleChanged))
+
+	eventTypes, err := services.NormalizeWebhookEventTypes([]string{models.WebhookEventTeacherLeft, models.WebhookEventTeacherLeft})
+	assert.NoError(t, err)
+	assert.Equal(t, models.WebhookEventTypes{models.WebhookEventTeacherLeft}, eventTypes)
+	_, err = services.NormalizeWebhookEventTypes([]string{models.WebhookEventTest})
+	assert.Error(t, err)
+
+	assert.NoError(t, services.ValidateWebhookURL("https://crm.example.com/hooks/timeledger", false))
+	assert.Error(t, services.ValidateWebhookURL("ftp://crm.example.com", false))
+	assert.Error(t, services.ValidateWebhookURL("/relative", false))
+	// 非本機環境只接受 https，且不得直接指向內網位址
+	assert.Error(t, services.ValidateWebhookURL("http://crm.example.com/hooks", false))
+	assert.Error(t, services.ValidateWebhookURL("https://localhost/hooks", false))
+	assert.Error(t, services.ValidateWebhookURL("https://169.254.169.254/latest/meta-data", false))
+	assert.Error(t, services.ValidateWebhookURL("https://[::1]:8443/hooks", false))
+	assert.NoError(t, services.ValidateWebhookURL("http://localhost:9000/hooks", true))
+	assert.Error(t, services.ValidateWebhookURL("ftp://localhost", true))
+
+	assert.Equal(t, models.WebhookEventExceptionReviewed, services.WebhookEventForDomainEvent(models.DomainEventExceptionReviewed))
+	assert.Empty(t, services.WebhookEventForDomainEvent(models.DomainEventTeacherMerged))
+}
+
+// serveAdminJSON 以管理員身分呼叫單一 handler，回傳回應
+func serveAdminJSON(handler gin.HandlerFunc, method, path, pattern, body string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(global.CenterIDKey, uint(1))
+		c.Set(global.UserIDKey, uint(1))
+		c.Set(global.UserTypeKey, "ADMIN")
+		c.Next()
+	})
+	router.Handle(method, pattern, handler)
+
+	req, _ := http.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// TestAdminWebhookCreateInvalidURL 網址不符規定時回應 400 與具體原因
+func TestAdminWebhookCreateInvalidURL(t *testing.T) {
+	appInstance := &app.App{Env: &configs.Env{AppEnv: "production"}, Err: errInfos.Initialize(1)}
+	ctl := controllers.NewAdminWebhookController(appInstance)
+
+	w := serveAdminJSON(ctl.CreateSubscription, http.MethodPost, "/admin/webhooks", "/admin/webhooks",
+		`{"name":"CRM","url":"http://crm.example.com/hooks"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "https")
+}